@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"golanger.com/framework/session"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A User of the application, as far as authentication is concerned.
+type User interface {
+	AuthID() string
+	PasswordHash() string
+}
+
+// A Provider finds the users of the application, in its database.  Both
+// methods return a nil User, without error, if there is none.
+type Provider interface {
+	UserByID(ctx context.Context, id string) (User, error)
+	UserByLogin(ctx context.Context, login string) (User, error)
+}
+
+// A Provider may implement Rehasher to have the password hashes that
+// NeedsRehash upgraded on login.
+type Rehasher interface {
+	SetPasswordHash(ctx context.Context, user User, hash string) error
+}
+
+type Options struct {
+	// The session key of the id of the logged in user, "auth.user" if
+	// empty.
+	SessionKey string
+
+	// The remember-me cookie, "remember_me" and 30 days if empty.
+	RememberCookie string
+	RememberFor    time.Duration
+
+	// Where RequireLogin sends visitors, "/login" if empty, and where
+	// Login and Logout redirect on success, "/" if empty.
+	LoginPath   string
+	AfterLogin  string
+	AfterLogout string
+}
+
+// An Auth logs users in and out through the session, and recognizes them
+// on later requests.  The session middleware must run before any of its
+// handlers.
+type Auth struct {
+	provider Provider
+	opts     Options
+}
+
+func New(provider Provider, opts ...Options) *Auth {
+	a := &Auth{provider: provider}
+	if len(opts) > 0 {
+		a.opts = opts[0]
+	}
+
+	if a.opts.SessionKey == "" {
+		a.opts.SessionKey = "auth.user"
+	}
+
+	if a.opts.RememberCookie == "" {
+		a.opts.RememberCookie = "remember_me"
+	}
+
+	if a.opts.RememberFor == 0 {
+		a.opts.RememberFor = 30 * 24 * time.Hour
+	}
+
+	if a.opts.LoginPath == "" {
+		a.opts.LoginPath = "/login"
+	}
+
+	if a.opts.AfterLogin == "" {
+		a.opts.AfterLogin = "/"
+	}
+
+	if a.opts.AfterLogout == "" {
+		a.opts.AfterLogout = "/"
+	}
+
+	return a
+}
+
+type contextKey struct{}
+
+// Return the user Middleware found for the request, or nil if it is
+// anonymous.
+func CurrentUser(r *http.Request) User {
+	u, _ := r.Context().Value(contextKey{}).(User)
+	return u
+}
+
+// Report whether a user is logged in.
+func LoggedIn(r *http.Request) bool {
+	return CurrentUser(r) != nil
+}
+
+// Return a middleware that loads the user of the session, or of the
+// remember-me cookie, for CurrentUser, and adds its id to the log lines of
+// the request as user_id.  A user found by the cookie is logged into the
+// session as if by Login.
+func (a *Auth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := session.FromRequest(r)
+		if s == nil {
+			log.FromRequest(r).Error("<auth.Middleware> ", "the session middleware must run first")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		var user User
+		var err error
+		if id := s.GetString(a.opts.SessionKey); id != "" {
+			if user, err = a.provider.UserByID(r.Context(), id); err != nil {
+				log.FromRequest(r).Error("<auth.Middleware> ", err)
+			} else if user == nil {
+				s.Delete(a.opts.SessionKey)
+			}
+		} else if user = a.remembered(w, r); user != nil {
+			s.Regenerate()
+			s.Set(a.opts.SessionKey, user.AuthID())
+		}
+
+		if user != nil {
+			ctx := context.WithValue(r.Context(), contextKey{}, user)
+			ctx = log.NewContext(ctx, log.FromContext(ctx).WithFields(log.Fields{"user_id": user.AuthID()}))
+			r = r.WithContext(ctx)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Report whether a redirect target stays on this site.
+func localPath(target string) bool {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return false
+	}
+
+	u, err := url.Parse(target)
+
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// Return a middleware that lets only logged in users through.  Others are
+// redirected to the login page, with the page they asked for in ?next=,
+// or answered 401 if they asked for JSON.
+func (a *Auth) RequireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if CurrentUser(r) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if wantsJSON(r) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		target := a.opts.LoginPath
+		if r.Method == "GET" {
+			target += "?next=" + url.QueryEscape(r.URL.RequestURI())
+		}
+
+		http.Redirect(w, r, target, http.StatusSeeOther)
+	})
+}
+
+func wantsJSON(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "application/json") ||
+		strings.HasPrefix(r.Header.Get("Content-Type"), "application/json")
+}
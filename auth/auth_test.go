@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"golanger.com/framework/cookie"
+	"golanger.com/framework/session"
+	"golanger.com/framework/testing/webtest"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type user struct {
+	id, login, hash string
+}
+
+func (u *user) AuthID() string       { return u.id }
+func (u *user) PasswordHash() string { return u.hash }
+
+type users map[string]*user
+
+func (us users) UserByID(ctx context.Context, id string) (User, error) {
+	for _, u := range us {
+		if u.id == id {
+			return u, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (us users) UserByLogin(ctx context.Context, login string) (User, error) {
+	if u, ok := us[login]; ok {
+		return u, nil
+	}
+
+	return nil, nil
+}
+
+// Cheap hashes, so that the tests do not spend a second in scrypt.
+var quick = Scrypt{LogN: 4, R: 8, P: 1}
+
+func app(t *testing.T) (http.Handler, users) {
+	cookie.Configure(cookie.Keys{Hash: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}})
+	hash, _ := quick.Hash("secret pw")
+	us := users{"joe": {id: "7", login: "joe", hash: hash}}
+	a := New(us)
+
+	mux := http.NewServeMux()
+	mux.Handle("/login", a.Login())
+	mux.Handle("/logout", a.Logout())
+	mux.Handle("/private", a.RequireLogin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello " + CurrentUser(r).AuthID()))
+	})))
+
+	manager := session.NewManager(session.NewMemoryStore(), "sid", time.Hour)
+
+	return manager.Middleware(a.Middleware(mux)), us
+}
+
+func login(c *webtest.Client, path, password string, remember bool) *webtest.Response {
+	f := webtest.NewForm().Set("login", "joe").Set("password", password)
+	if remember {
+		f.Set("remember", "on")
+	}
+
+	return c.Submit("POST", path, f)
+}
+
+func TestLogin(t *testing.T) {
+	h, _ := app(t)
+	c := webtest.New(t, h)
+	c.Get("/private?a=1").AssertRedirect("/login?next=" + url.QueryEscape("/private?a=1"))
+
+	login(c, "/login", "wrong", false).AssertRedirect("/login")
+	c.Get("/private").AssertStatus(http.StatusSeeOther)
+
+	before := c.Cookie("sid")
+	login(c, "/login?next=/private", "secret pw", false).AssertRedirect("/private")
+	if after := c.Cookie("sid"); before != nil && after.Value == before.Value {
+		t.Error("session id not regenerated on login")
+	}
+
+	c.Get("/private").AssertStatus(http.StatusOK).AssertContains("hello 7")
+	if c.Cookie("remember_me") != nil {
+		t.Error("remembered without asking")
+	}
+
+	c.Submit("POST", "/logout", webtest.NewForm()).AssertRedirect("/")
+	c.Get("/private").AssertStatus(http.StatusSeeOther)
+}
+
+func TestLoginRedirectStaysLocal(t *testing.T) {
+	h, _ := app(t)
+	for _, next := range []string{"https://evil.example/", "//evil.example/", "/\\evil.example", "javascript:alert(1)"} {
+		c := webtest.New(t, h)
+		login(c, "/login?next="+url.QueryEscape(next), "secret pw", false).AssertRedirect("/")
+	}
+}
+
+func TestLoginJSON(t *testing.T) {
+	h, _ := app(t)
+	c := webtest.New(t, h)
+	c.JSON("POST", "/login", map[string]string{"login": "joe", "password": "nope"}).
+		AssertStatus(http.StatusUnauthorized).
+		AssertContains("auth.invalid")
+
+	c.JSON("POST", "/login", map[string]string{"login": "ann", "password": "nope"}).
+		AssertStatus(http.StatusUnauthorized).
+		AssertContains("auth.invalid")
+
+	c.JSON("POST", "/login", map[string]string{"login": "joe", "password": "secret pw"}).
+		AssertStatus(http.StatusNoContent)
+
+	c.Header.Set("Accept", "application/json")
+	c.Get("/private").AssertStatus(http.StatusOK)
+}
+
+func TestRememberMe(t *testing.T) {
+	h, us := app(t)
+	c := webtest.New(t, h)
+	login(c, "/login", "secret pw", true)
+	remember := c.Cookie("remember_me")
+	if remember == nil {
+		t.Fatal("no remember-me cookie")
+	}
+
+	// A new browser session with only the cookie is logged back in.
+	later := webtest.New(t, h)
+	later.Jar.SetCookies(later.BaseURL, []*http.Cookie{remember})
+	later.Get("/private").AssertStatus(http.StatusOK).AssertContains("hello 7")
+
+	forged := webtest.New(t, h)
+	forged.Jar.SetCookies(forged.BaseURL, []*http.Cookie{{Name: "remember_me", Value: "7|9999999999|x"}})
+	forged.Get("/private").AssertStatus(http.StatusSeeOther)
+
+	// Changing the password forgets every remember-me cookie.
+	us["joe"].hash, _ = quick.Hash("new pw")
+	again := webtest.New(t, h)
+	again.Jar.SetCookies(again.BaseURL, []*http.Cookie{remember})
+	again.Get("/private").AssertStatus(http.StatusSeeOther)
+}
+
+func TestRememberMePipeInID(t *testing.T) {
+	h, us := app(t)
+	hash, _ := quick.Hash("ann pw")
+	us["ann"] = &user{id: "oidc|ann@example.com", login: "ann", hash: hash}
+
+	c := webtest.New(t, h)
+	c.Submit("POST", "/login", webtest.NewForm().Set("login", "ann").Set("password", "ann pw").Set("remember", "on"))
+	remember := c.Cookie("remember_me")
+	if remember == nil {
+		t.Fatal("no remember-me cookie")
+	}
+
+	later := webtest.New(t, h)
+	later.Jar.SetCookies(later.BaseURL, []*http.Cookie{remember})
+	later.Get("/private").AssertStatus(http.StatusOK).AssertContains("hello oidc|ann@example.com")
+}
+
+func TestLogoutNeedsPost(t *testing.T) {
+	h, _ := app(t)
+	c := webtest.New(t, h)
+	login(c, "/login", "secret pw", false)
+	c.Get("/logout").AssertStatus(http.StatusMethodNotAllowed)
+	c.Get("/private").AssertStatus(http.StatusOK)
+}
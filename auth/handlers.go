@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"encoding/json"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/session"
+	"golanger.com/framework/validator"
+	"golanger.com/framework/validator/sanitize"
+	"mime"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// The fields of a login form or JSON body.
+type Credentials struct {
+	Login    string `json:"login" sanitize:"trim" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	Remember bool   `json:"remember"`
+}
+
+func credentials(w http.ResponseWriter, r *http.Request) (*Credentials, error) {
+	c := &Credentials{}
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct == "application/json" {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(c); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		c.Login = r.PostForm.Get("login")
+		c.Password = r.PostForm.Get("password")
+		switch r.PostForm.Get("remember") {
+		case "1", "on", "true", "yes":
+			c.Remember = true
+		}
+	}
+
+	sanitize.Struct(c)
+
+	return c, nil
+}
+
+var (
+	dummyOnce sync.Once
+	dummyHash string
+)
+
+// Spend as long checking the password of an unknown login as of a known
+// one, so that response times do not tell which logins exist.
+func checkDummy(password string) {
+	dummyOnce.Do(func() {
+		dummyHash, _ = HashPassword("not a password")
+	})
+
+	CheckPassword(password, dummyHash)
+}
+
+// Check the credentials of the request and return the user they belong
+// to, or nil with the errors recorded in v, keyed by Credentials field.
+func (a *Auth) Authenticate(r *http.Request, v *validator.Validation, c *Credentials) User {
+	v.ValidateStruct(c)
+	if v.HasErrors() {
+		return nil
+	}
+
+	user, err := a.provider.UserByLogin(r.Context(), c.Login)
+	if err != nil {
+		log.FromRequest(r).Error("<auth.Auth.Authenticate> ", err)
+		v.Error("Could not log in, please try again").Key("Login").Code("auth.unavailable")
+		return nil
+	}
+
+	if user == nil {
+		checkDummy(c.Password)
+	}
+
+	if user == nil || !CheckPassword(c.Password, user.PasswordHash()) {
+		v.Error("Invalid login or password").Key("Login").Code("auth.invalid")
+		return nil
+	}
+
+	if rh, ok := a.provider.(Rehasher); ok && NeedsRehash(user.PasswordHash()) {
+		if hash, err := HashPassword(c.Password); err == nil {
+			if err := rh.SetPasswordHash(r.Context(), user, hash); err != nil {
+				log.FromRequest(r).Error("<auth.Auth.Authenticate> ", err)
+			}
+		}
+	}
+
+	return user
+}
+
+// Log user into the session of the request, moving it to a new id, and
+// remember them with a cookie if remember is true.
+func (a *Auth) LogIn(w http.ResponseWriter, r *http.Request, user User, remember bool) {
+	s := session.FromRequest(r)
+	s.Regenerate()
+	s.Set(a.opts.SessionKey, user.AuthID())
+	if remember {
+		a.remember(w, r, user)
+	}
+}
+
+// Log the user of the request out of the session and forget the
+// remember-me cookie.
+func (a *Auth) LogOut(w http.ResponseWriter, r *http.Request) {
+	if s := session.FromRequest(r); s != nil {
+		s.Delete(a.opts.SessionKey)
+		s.Regenerate()
+	}
+
+	a.forget(w)
+}
+
+// Return a handler of POSTed login forms or JSON bodies (login, password
+// and remember).  On success it redirects to the local ?next= page or
+// AfterLogin, or answers 204 to JSON.  On failure it redirects back to
+// LoginPath with the errors kept by the validator flash cookie, or
+// answers 401 to JSON with the errors as validator.Middleware writes them.
+func (a *Auth) Login() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		v := (&validator.Validation{}).SetLocale(i18n.Locale(r)).SetContext(r.Context())
+		c, err := credentials(w, r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		if session.FromRequest(r) == nil {
+			log.FromRequest(r).Error("<auth.Auth.Login> ", "the session middleware must run first")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		user := a.Authenticate(r, v, c)
+		if user == nil {
+			log.FromRequest(r).Info("<auth.Auth.Login> ", "failed login for ", c.Login)
+			a.failed(w, r, v)
+			return
+		}
+
+		a.LogIn(w, r, user, c.Remember)
+		if wantsJSON(r) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		target := a.opts.AfterLogin
+		if next := r.URL.Query().Get("next"); localPath(next) {
+			target = next
+		}
+
+		http.Redirect(w, r, target, http.StatusSeeOther)
+	})
+}
+
+func (a *Auth) failed(w http.ResponseWriter, r *http.Request, v *validator.Validation) {
+	if wantsJSON(r) {
+		b, _ := json.Marshal(map[string]interface{}{"errors": v.ErrorMap()})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write(b)
+		return
+	}
+
+	v.Keep()
+	v.SetCookie(w)
+
+	target := a.opts.LoginPath
+	if next := r.URL.Query().Get("next"); localPath(next) {
+		target += "?next=" + url.QueryEscape(next)
+	}
+
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
+// Return a handler that logs out on POST and redirects to AfterLogout, or
+// answers 204 to JSON.  GET is refused so that other sites cannot log
+// users out with an image.
+func (a *Auth) Logout() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		a.LogOut(w, r)
+		if wantsJSON(r) {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		http.Redirect(w, r, a.opts.AfterLogout, http.StatusSeeOther)
+	})
+}
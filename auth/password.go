@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// A Hasher hashes passwords into self-describing strings, such as those of
+// the PHC string format ("$scrypt$ln=15,r=8,p=1$salt$hash").  bcrypt and
+// argon2 hashers, from golang.org/x/crypto, fit it and can be registered
+// so that existing hashes keep verifying.
+type Hasher interface {
+	Hash(password string) (string, error)
+
+	// Report whether hash was made by this kind of hasher.
+	Recognizes(hash string) bool
+
+	Verify(password, hash string) bool
+
+	// Report whether hash has weaker parameters than the hasher's.
+	NeedsRehash(hash string) bool
+}
+
+var b64 = base64.RawStdEncoding
+
+func salt() ([]byte, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+
+	return b, err
+}
+
+// Hashes passwords with scrypt, memory-hard like argon2: a cost of 1<<LogN
+// with r 8 and p 1 takes 128*8<<LogN bytes, 32 MB for the default of 15.
+type Scrypt struct {
+	LogN, R, P int
+}
+
+func (s Scrypt) Hash(password string) (string, error) {
+	sl, err := salt()
+	if err != nil {
+		return "", err
+	}
+
+	key, err := scryptKey([]byte(password), sl, 1<<uint(s.LogN), s.R, s.P, 32)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s", s.LogN, s.R, s.P, b64.EncodeToString(sl), b64.EncodeToString(key)), nil
+}
+
+func (s Scrypt) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$scrypt$")
+}
+
+func (s Scrypt) parse(hash string) (params Scrypt, sl, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[1] != "scrypt" {
+		return params, nil, nil, errors.New("auth: not a scrypt hash")
+	}
+
+	if _, err = fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &params.LogN, &params.R, &params.P); err != nil || params.LogN < 1 || params.LogN > 30 {
+		return params, nil, nil, errors.New("auth: bad scrypt parameters")
+	}
+
+	if sl, err = b64.DecodeString(parts[3]); err != nil {
+		return
+	}
+
+	key, err = b64.DecodeString(parts[4])
+
+	return
+}
+
+func (s Scrypt) Verify(password, hash string) bool {
+	params, sl, key, err := s.parse(hash)
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	got, err := scryptKey([]byte(password), sl, 1<<uint(params.LogN), params.R, params.P, len(key))
+
+	return err == nil && subtle.ConstantTimeCompare(got, key) == 1
+}
+
+func (s Scrypt) NeedsRehash(hash string) bool {
+	params, _, _, err := s.parse(hash)
+
+	return err != nil || params.LogN < s.LogN || params.R < s.R || params.P < s.P
+}
+
+// Hashes passwords with PBKDF2-SHA256, for where FIPS-approved hashing is
+// required; prefer Scrypt otherwise.
+type PBKDF2 struct {
+	Iterations int
+}
+
+func (p PBKDF2) Hash(password string) (string, error) {
+	sl, err := salt()
+	if err != nil {
+		return "", err
+	}
+
+	key := pbkdf2Key(sha256.New, []byte(password), sl, p.Iterations, 32)
+
+	return fmt.Sprintf("$pbkdf2-sha256$i=%d$%s$%s", p.Iterations, b64.EncodeToString(sl), b64.EncodeToString(key)), nil
+}
+
+func (p PBKDF2) Recognizes(hash string) bool {
+	return strings.HasPrefix(hash, "$pbkdf2-sha256$")
+}
+
+func (p PBKDF2) parse(hash string) (iterations int, sl, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 || parts[1] != "pbkdf2-sha256" {
+		return 0, nil, nil, errors.New("auth: not a pbkdf2-sha256 hash")
+	}
+
+	if _, err = fmt.Sscanf(parts[2], "i=%d", &iterations); err != nil || iterations < 1 {
+		return 0, nil, nil, errors.New("auth: bad pbkdf2 iterations")
+	}
+
+	if sl, err = b64.DecodeString(parts[3]); err != nil {
+		return
+	}
+
+	key, err = b64.DecodeString(parts[4])
+
+	return
+}
+
+func (p PBKDF2) Verify(password, hash string) bool {
+	iterations, sl, key, err := p.parse(hash)
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	got := pbkdf2Key(sha256.New, []byte(password), sl, iterations, len(key))
+
+	return subtle.ConstantTimeCompare(got, key) == 1
+}
+
+func (p PBKDF2) NeedsRehash(hash string) bool {
+	iterations, _, _, err := p.parse(hash)
+
+	return err != nil || iterations < p.Iterations
+}
+
+var (
+	hashersMutex sync.RWMutex
+
+	// The Hasher of HashPassword.
+	DefaultHasher Hasher = Scrypt{LogN: 15, R: 8, P: 1}
+
+	hashers = []Hasher{PBKDF2{Iterations: 600000}}
+)
+
+// Make CheckPassword accept the hashes of h too, such as bcrypt hashes
+// carried over from another application.
+func RegisterHasher(h Hasher) {
+	hashersMutex.Lock()
+	hashers = append(hashers, h)
+	hashersMutex.Unlock()
+}
+
+func hasherFor(hash string) Hasher {
+	hashersMutex.RLock()
+	defer hashersMutex.RUnlock()
+
+	if DefaultHasher.Recognizes(hash) {
+		return DefaultHasher
+	}
+
+	for _, h := range hashers {
+		if h.Recognizes(hash) {
+			return h
+		}
+	}
+
+	return nil
+}
+
+// Hash a password with DefaultHasher.
+func HashPassword(password string) (string, error) {
+	return DefaultHasher.Hash(password)
+}
+
+// Report whether password matches hash, made by DefaultHasher or a
+// registered Hasher.
+func CheckPassword(password, hash string) bool {
+	h := hasherFor(hash)
+
+	return h != nil && h.Verify(password, hash)
+}
+
+// Report whether hash should be replaced by a new HashPassword, once the
+// password has been checked, because it was made by another Hasher than
+// DefaultHasher or with weaker parameters.
+func NeedsRehash(hash string) bool {
+	if h := hasherFor(hash); h != DefaultHasher {
+		return true
+	}
+
+	return DefaultHasher.NeedsRehash(hash)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestScryptKey(t *testing.T) {
+	// RFC 7914, section 12.
+	key, err := scryptKey([]byte("password"), []byte("NaCl"), 1024, 8, 16, 64)
+	want := "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"
+	if err != nil || hex.EncodeToString(key) != want {
+		t.Errorf("scrypt = %x, %v", key, err)
+	}
+}
+
+func TestPBKDF2Key(t *testing.T) {
+	key := pbkdf2Key(sha256.New, []byte("password"), []byte("salt"), 2, 32)
+	want := "ae4d0c95af6b46d32d0adff928f06dd02a303f8ef3c251dfd6e2d85a95474c43"
+	if hex.EncodeToString(key) != want {
+		t.Errorf("pbkdf2 = %x", key)
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil || !strings.HasPrefix(hash, "$scrypt$ln=15,r=8,p=1$") {
+		t.Fatalf("hash %q, %v", hash, err)
+	}
+
+	other, _ := HashPassword("correct horse")
+	if other == hash {
+		t.Error("same hash twice: the salt is not random")
+	}
+
+	if !CheckPassword("correct horse", hash) || CheckPassword("correct horsE", hash) || CheckPassword("", hash) {
+		t.Error("CheckPassword does not tell the password apart")
+	}
+
+	if NeedsRehash(hash) {
+		t.Error("NeedsRehash of a current hash")
+	}
+
+	i := strings.LastIndex(hash, "$")
+	for _, bad := range []string{"", "plain", hash[:i], hash[:i+1] + "AAAA", strings.Replace(hash, "ln=15", "ln=99", 1)} {
+		if CheckPassword("correct horse", bad) {
+			t.Errorf("CheckPassword accepted %q", bad)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	weak, _ := Scrypt{LogN: 10, R: 8, P: 1}.Hash("pw")
+	if !CheckPassword("pw", weak) || !NeedsRehash(weak) {
+		t.Error("weaker scrypt hash not verified and marked for rehash")
+	}
+
+	legacy, _ := PBKDF2{Iterations: 1000}.Hash("pw")
+	if !CheckPassword("pw", legacy) || !NeedsRehash(legacy) {
+		t.Error("PBKDF2 hash not verified and marked for rehash")
+	}
+
+	if !NeedsRehash("$md5$whatever") || CheckPassword("pw", "$md5$whatever") {
+		t.Error("unknown hash accepted or kept")
+	}
+}
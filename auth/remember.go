@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"golanger.com/framework/cookie"
+	"golanger.com/framework/log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A digest of the password hash of a user, so that changing the password
+// invalidates every remember-me cookie without a table of tokens.
+func fingerprint(user User) string {
+	h := sha256.Sum256([]byte("remember|" + user.PasswordHash()))
+	return base64.RawURLEncoding.EncodeToString(h[:16])
+}
+
+// Set the remember-me cookie of user, signed with the keys given to
+// cookie.Configure; without keys, users are not remembered.  The cookie
+// holds the user id, its expiry and the fingerprint.
+func (a *Auth) remember(w http.ResponseWriter, r *http.Request, user User) {
+	expires := time.Now().Add(a.opts.RememberFor)
+	value := user.AuthID() + "|" + strconv.FormatInt(expires.Unix(), 10) + "|" + fingerprint(user)
+	err := cookie.SetSigned(w, &http.Cookie{
+		Name:     a.opts.RememberCookie,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		MaxAge:   int(a.opts.RememberFor / time.Second),
+		Secure:   r.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if err != nil {
+		log.FromRequest(r).Error("<auth.Auth.remember> ", err)
+	}
+}
+
+func (a *Auth) forget(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:   a.opts.RememberCookie,
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
+
+// Return the user of a valid remember-me cookie, or nil, expiring an
+// invalid cookie.
+func (a *Auth) remembered(w http.ResponseWriter, r *http.Request) User {
+	value, err := cookie.GetSigned(r, a.opts.RememberCookie)
+	if err == http.ErrNoCookie {
+		return nil
+	}
+
+	// The id may hold "|" itself, so the other two parts are split off
+	// from the right.
+	parts := strings.Split(value, "|")
+	if n := len(parts); n > 3 {
+		parts = []string{strings.Join(parts[:n-2], "|"), parts[n-2], parts[n-1]}
+	}
+
+	if err != nil || len(parts) != 3 {
+		a.forget(w)
+		return nil
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		a.forget(w)
+		return nil
+	}
+
+	user, err := a.provider.UserByID(r.Context(), parts[0])
+	if err != nil {
+		log.FromRequest(r).Error("<auth.Auth.remembered> ", err)
+		return nil
+	}
+
+	if user == nil || subtle.ConstantTimeCompare([]byte(fingerprint(user)), []byte(parts[2])) != 1 {
+		a.forget(w)
+		return nil
+	}
+
+	return user
+}
@@ -0,0 +1,265 @@
+package authz
+
+import (
+	"golanger.com/framework/auth"
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// A user with roles.  The auth.User of the application implements it to
+// be authorized by role.
+type RoleHolder interface {
+	Roles() []string
+}
+
+// A user granted permissions of its own, besides those of its roles.
+type PermissionHolder interface {
+	Permissions() []string
+}
+
+// A Policy grants permissions, such as "posts.edit", to roles.  A
+// permission ending in ".*" grants every permission under it, and "*"
+// every permission.  It is safe for concurrent use.
+type Policy struct {
+	mutex   sync.RWMutex
+	grants  map[string][]string
+	parents map[string][]string
+
+	// Answer a request Require turned away: 401 for anonymous users and
+	// 403 for others.  http.Error if nil.
+	OnDenied func(w http.ResponseWriter, r *http.Request, status int)
+}
+
+func NewPolicy() *Policy {
+	return &Policy{
+		grants:  map[string][]string{},
+		parents: map[string][]string{},
+	}
+}
+
+// The Policy of the package-level functions and of the can template
+// helper.
+var Default = NewPolicy()
+
+// Grant permissions to role.
+func (p *Policy) Grant(role string, permissions ...string) *Policy {
+	p.mutex.Lock()
+	p.grants[role] = append(p.grants[role], permissions...)
+	p.mutex.Unlock()
+
+	return p
+}
+
+// Give role the permissions of parents too, e.g. Inherit("admin",
+// "editor").  A user with role also has the roles of parents for HasRole.
+func (p *Policy) Inherit(role string, parents ...string) *Policy {
+	p.mutex.Lock()
+	p.parents[role] = append(p.parents[role], parents...)
+	p.mutex.Unlock()
+
+	return p
+}
+
+// Return the roles of user, with those they inherit.
+func (p *Policy) roles(user auth.User) map[string]bool {
+	roles := map[string]bool{}
+	rh, ok := user.(RoleHolder)
+	if !ok {
+		return roles
+	}
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var add func(role string)
+	add = func(role string) {
+		if roles[role] {
+			return
+		}
+
+		roles[role] = true
+		for _, parent := range p.parents[role] {
+			add(parent)
+		}
+	}
+
+	for _, role := range rh.Roles() {
+		add(role)
+	}
+
+	return roles
+}
+
+// Report whether user, which may be nil, has role, directly or by
+// inheritance.
+func (p *Policy) HasRole(user auth.User, role string) bool {
+	if user == nil {
+		return false
+	}
+
+	return p.roles(user)[role]
+}
+
+func matches(granted, permission string) bool {
+	if granted == "*" || granted == permission {
+		return true
+	}
+
+	return strings.HasSuffix(granted, ".*") && strings.HasPrefix(permission, granted[:len(granted)-1])
+}
+
+// Report whether user, which may be nil, has permission, through its
+// roles or as a PermissionHolder.
+func (p *Policy) Can(user auth.User, permission string) bool {
+	if user == nil {
+		return false
+	}
+
+	if ph, ok := user.(PermissionHolder); ok {
+		for _, granted := range ph.Permissions() {
+			if matches(granted, permission) {
+				return true
+			}
+		}
+	}
+
+	roles := p.roles(user)
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	for role := range roles {
+		for _, granted := range p.grants[role] {
+			if matches(granted, permission) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *Policy) deny(w http.ResponseWriter, r *http.Request, status int) {
+	if status == http.StatusForbidden {
+		log.FromRequest(r).Info("<authz.Policy.Require> ", "denied ", auth.CurrentUser(r).AuthID(), " ", r.Method, " ", r.URL.Path)
+	}
+
+	if p.OnDenied != nil {
+		p.OnDenied(w, r, status)
+		return
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
+
+// Return a middleware that lets through the users, found by the auth
+// middleware, that have any of roles.
+func (p *Policy) Require(roles ...string) middleware.Middleware {
+	return p.require(func(user auth.User) bool {
+		for _, role := range roles {
+			if p.HasRole(user, role) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// Return a middleware that lets through the users that have every one of
+// permissions.
+func (p *Policy) RequirePermission(permissions ...string) middleware.Middleware {
+	return p.require(func(user auth.User) bool {
+		for _, permission := range permissions {
+			if !p.Can(user, permission) {
+				return false
+			}
+		}
+
+		return true
+	})
+}
+
+func (p *Policy) require(allowed func(user auth.User) bool) middleware.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := auth.CurrentUser(r)
+			switch {
+			case user == nil:
+				p.deny(w, r, http.StatusUnauthorized)
+			case !allowed(user):
+				p.deny(w, r, http.StatusForbidden)
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// Report whether the user of the request has permission, for checks in
+// handlers that go beyond one route.
+func (p *Policy) Allowed(r *http.Request, permission string) bool {
+	return p.Can(auth.CurrentUser(r), permission)
+}
+
+// Report whether the user of template data has permission:
+// {{can . "posts.edit"}}.  data may be the user itself, the request, or a
+// map holding either under "CurrentUser" or "Request", as controller
+// Render gives it.
+func (p *Policy) TemplateCan(data interface{}, permission string) bool {
+	return p.Can(userOf(data), permission)
+}
+
+func userOf(data interface{}) auth.User {
+	switch d := data.(type) {
+	case auth.User:
+		return d
+	case *http.Request:
+		return auth.CurrentUser(d)
+	case map[string]interface{}:
+		if u, ok := d["CurrentUser"].(auth.User); ok {
+			return u
+		}
+
+		if r, ok := d["Request"].(*http.Request); ok {
+			return auth.CurrentUser(r)
+		}
+	}
+
+	return nil
+}
+
+func Grant(role string, permissions ...string) *Policy {
+	return Default.Grant(role, permissions...)
+}
+
+func Inherit(role string, parents ...string) *Policy {
+	return Default.Inherit(role, parents...)
+}
+
+func HasRole(user auth.User, role string) bool {
+	return Default.HasRole(user, role)
+}
+
+func Can(user auth.User, permission string) bool {
+	return Default.Can(user, permission)
+}
+
+func Require(roles ...string) middleware.Middleware {
+	return Default.Require(roles...)
+}
+
+func RequirePermission(permissions ...string) middleware.Middleware {
+	return Default.RequirePermission(permissions...)
+}
+
+func Allowed(r *http.Request, permission string) bool {
+	return Default.Allowed(r, permission)
+}
+
+func TemplateCan(data interface{}, permission string) bool {
+	return Default.TemplateCan(data, permission)
+}
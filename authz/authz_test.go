@@ -0,0 +1,173 @@
+package authz
+
+import (
+	"context"
+	"golanger.com/framework/auth"
+	"golanger.com/framework/cookie"
+	"golanger.com/framework/session"
+	"golanger.com/framework/testing/webtest"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type user struct {
+	id          string
+	roles       []string
+	permissions []string
+}
+
+func (u *user) AuthID() string        { return u.id }
+func (u *user) PasswordHash() string  { return "" }
+func (u *user) Roles() []string       { return u.roles }
+func (u *user) Permissions() []string { return u.permissions }
+
+type anonymous struct{}
+
+func (anonymous) AuthID() string       { return "0" }
+func (anonymous) PasswordHash() string { return "" }
+
+type users map[string]*user
+
+func (us users) UserByID(ctx context.Context, id string) (auth.User, error) {
+	if u, ok := us[id]; ok {
+		return u, nil
+	}
+
+	return nil, nil
+}
+
+func (us users) UserByLogin(ctx context.Context, login string) (auth.User, error) {
+	return us.UserByID(ctx, login)
+}
+
+func blog() *Policy {
+	return NewPolicy().
+		Grant("reader", "posts.read").
+		Grant("editor", "posts.edit", "comments.*").
+		Grant("root", "*").
+		Inherit("editor", "reader").
+		Inherit("admin", "editor")
+}
+
+func TestCan(t *testing.T) {
+	p := blog()
+	admin := &user{id: "1", roles: []string{"admin"}}
+	cases := []struct {
+		user       auth.User
+		permission string
+		want       bool
+	}{
+		{admin, "posts.read", true},
+		{admin, "posts.edit", true},
+		{admin, "comments.delete", true},
+		{admin, "comments", false},
+		{admin, "posts.delete", false},
+		{&user{roles: []string{"reader"}}, "posts.edit", false},
+		{&user{roles: []string{"root"}}, "anything", true},
+		{&user{permissions: []string{"posts.*"}}, "posts.delete", true},
+		{&user{permissions: []string{"posts.*"}}, "postsx.delete", false},
+		{anonymous{}, "posts.read", false},
+		{nil, "posts.read", false},
+	}
+
+	for _, c := range cases {
+		if got := p.Can(c.user, c.permission); got != c.want {
+			t.Errorf("Can(%v, %s) = %v, want %v", c.user, c.permission, got, c.want)
+		}
+	}
+}
+
+func TestHasRole(t *testing.T) {
+	p := blog()
+	admin := &user{roles: []string{"admin"}}
+	for _, role := range []string{"admin", "editor", "reader"} {
+		if !p.HasRole(admin, role) {
+			t.Errorf("admin does not have role %s", role)
+		}
+	}
+
+	if p.HasRole(admin, "root") || p.HasRole(nil, "admin") || p.HasRole(anonymous{}, "admin") {
+		t.Error("role that was not given")
+	}
+
+	p.Inherit("reader", "admin")
+	if !p.HasRole(&user{roles: []string{"reader"}}, "editor") {
+		t.Error("role lost in an inheritance cycle")
+	}
+}
+
+func TestTemplateCan(t *testing.T) {
+	p := blog()
+	editor := &user{roles: []string{"editor"}}
+	for _, data := range []interface{}{editor, map[string]interface{}{"CurrentUser": editor}} {
+		if !p.TemplateCan(data, "posts.edit") {
+			t.Errorf("TemplateCan(%#v) = false", data)
+		}
+	}
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	for _, data := range []interface{}{nil, "editor", r, map[string]interface{}{"Request": r}} {
+		if p.TemplateCan(data, "posts.read") {
+			t.Errorf("TemplateCan(%#v) = true", data)
+		}
+	}
+}
+
+// Serve p.Require("editor") at /edit and p.RequirePermission at /delete,
+// with /as?id= logging in as the user of that id.
+func app(t *testing.T, p *Policy) http.Handler {
+	cookie.Configure(cookie.Keys{Hash: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}})
+	us := users{
+		"1": {id: "1", roles: []string{"editor"}},
+		"2": {id: "2", roles: []string{"reader"}},
+	}
+	a := auth.New(us)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok " + auth.CurrentUser(r).AuthID()))
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/as", func(w http.ResponseWriter, r *http.Request) {
+		a.LogIn(w, r, us[r.FormValue("id")], false)
+	})
+	mux.Handle("/edit", p.Require("editor", "admin")(ok))
+	mux.Handle("/delete", p.RequirePermission("posts.read", "comments.delete")(ok))
+
+	manager := session.NewManager(session.NewMemoryStore(), "sid", time.Hour)
+
+	return manager.Middleware(a.Middleware(mux))
+}
+
+func TestRequire(t *testing.T) {
+	c := webtest.New(t, app(t, blog()))
+	c.Get("/edit").AssertStatus(http.StatusUnauthorized)
+	c.Get("/delete").AssertStatus(http.StatusUnauthorized)
+
+	c.Get("/as?id=2")
+	c.Get("/edit").AssertStatus(http.StatusForbidden)
+	c.Get("/delete").AssertStatus(http.StatusForbidden)
+
+	c.Get("/as?id=1")
+	c.Get("/edit").AssertStatus(http.StatusOK).AssertContains("ok 1")
+	c.Get("/delete").AssertStatus(http.StatusOK)
+}
+
+func TestOnDenied(t *testing.T) {
+	p := blog()
+	var statuses []int
+	p.OnDenied = func(w http.ResponseWriter, r *http.Request, status int) {
+		statuses = append(statuses, status)
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	c := webtest.New(t, app(t, p))
+	c.Get("/edit").AssertStatus(http.StatusTeapot)
+	c.Get("/as?id=2")
+	c.Get("/edit").AssertStatus(http.StatusTeapot)
+
+	if len(statuses) != 2 || statuses[0] != http.StatusUnauthorized || statuses[1] != http.StatusForbidden {
+		t.Errorf("OnDenied given %v", statuses)
+	}
+}
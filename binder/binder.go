@@ -0,0 +1,338 @@
+package binder
+
+import (
+	"encoding/json"
+	"golanger.com/framework/debug"
+	"golanger.com/framework/decimal"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/validator"
+	"golanger.com/framework/validator/sanitize"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The most memory a multipart form may use before its files are stored on
+// disk.
+var MaxMemory int64 = 32 << 20
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var decimalType = reflect.TypeOf(decimal.Decimal{})
+
+// The layouts tried, in order, to convert a value to a time.Time field that
+// has no `time_format` tag.  An empty value binds the zero time.
+var TimeLayouts = []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"}
+
+// Populate the exported fields of the struct pointed to by dst from the
+// request and return the resulting Validation.
+//
+// Query parameters, form values and multipart files are assigned to the
+// fields named by their `form` tag (or the field name), converting to the
+// field's type; values that cannot be converted are recorded as errors
+// keyed by that name.  time.Time fields are parsed with their
+// `time_format` tag, or else with TimeLayouts.  decimal.Decimal fields,
+// for amounts of money, are parsed exactly, in the number format of the
+// locale of the request.  A JSON body is then decoded over the result with
+// encoding/json.  If every value binds, dst is then normalized by its
+// `sanitize` tags and checked by its `validate` tags.
+func Bind(r *http.Request, dst interface{}) *validator.Validation {
+	v := (&validator.Validation{}).SetContext(r.Context())
+	debug.TrackValidation(r.Context(), v)
+	done := debug.Start(r.Context(), "binding")
+	bind(v, r, dst)
+	done()
+
+	if !v.HasErrors() {
+		sanitize.Struct(dst)
+		defer debug.Start(r.Context(), "validation")()
+		v.ValidateStruct(dst)
+	}
+
+	return v
+}
+
+func bind(v *validator.Validation, r *http.Request, dst interface{}) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		v.Error("Bind target must be a pointer to a struct")
+		return
+	}
+
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch ct {
+	case "application/json":
+		if err := r.ParseForm(); err == nil {
+			bindForm(v, r, rv.Elem())
+		}
+
+		bindJSON(v, r, dst)
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(MaxMemory); err != nil {
+			v.Error("Invalid multipart form")
+			return
+		}
+
+		bindForm(v, r, rv.Elem())
+	default:
+		if err := r.ParseForm(); err != nil {
+			v.Error("Invalid form")
+			return
+		}
+
+		bindForm(v, r, rv.Elem())
+	}
+}
+
+func bindJSON(v *validator.Validation, r *http.Request, dst interface{}) {
+	if r.Body == nil {
+		return
+	}
+
+	err := json.NewDecoder(r.Body).Decode(dst)
+	switch e := err.(type) {
+	case nil:
+	case *json.UnmarshalTypeError:
+		v.Error("Must be of type %s", e.Type).Key(e.Field).Code("validation.type")
+	default:
+		v.Error("Invalid JSON body")
+	}
+}
+
+func bindForm(v *validator.Validation, r *http.Request, rv reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name := FieldName(sf)
+		if name == "-" {
+			continue
+		}
+
+		f := rv.Field(i)
+		if bindFile(r, f, name) {
+			continue
+		}
+
+		values, ok := r.Form[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if layout := sf.Tag.Get("time_format"); layout != "" && (sf.Type == timeType || sf.Type == reflect.PtrTo(timeType)) {
+			if err := setTime(f, values[0], []string{layout}); err != nil {
+				v.Error("%s", err).Key(name).Code("validation.type")
+			}
+
+			continue
+		}
+
+		if sf.Type == decimalType || sf.Type == reflect.PtrTo(decimalType) {
+			if err := setDecimal(f, values[0], i18n.Locale(r)); err != nil {
+				v.Error("%s", err).Key(name).Code("validation.type")
+			}
+
+			continue
+		}
+
+		if err := SetValue(f, values); err != nil {
+			v.Error("%s", err).Key(name).Code("validation.type")
+		}
+	}
+}
+
+// Return the request parameter name of a struct field: its `form` tag, or
+// else the field name.
+func FieldName(sf reflect.StructField) string {
+	if tag := sf.Tag.Get("form"); tag != "" {
+		return strings.Split(tag, ",")[0]
+	}
+
+	return sf.Name
+}
+
+func bindFile(r *http.Request, f reflect.Value, name string) bool {
+	switch {
+	case f.Type() == fileHeaderType:
+	case f.Kind() == reflect.Slice && f.Type().Elem() == fileHeaderType:
+	default:
+		return false
+	}
+
+	if r.MultipartForm == nil {
+		return true
+	}
+
+	files := r.MultipartForm.File[name]
+	if len(files) == 0 {
+		return true
+	}
+
+	if f.Kind() == reflect.Slice {
+		f.Set(reflect.ValueOf(files))
+	} else {
+		f.Set(reflect.ValueOf(files[0]))
+	}
+
+	return true
+}
+
+// Assign the string values to f, converting them to its type.  Slices take
+// every value, other types the first.
+func SetValue(f reflect.Value, values []string) error {
+	if f.Kind() == reflect.Slice && f.Type().Elem().Kind() != reflect.Uint8 {
+		s := reflect.MakeSlice(f.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setScalar(s.Index(i), value); err != nil {
+				return err
+			}
+		}
+
+		f.Set(s)
+
+		return nil
+	}
+
+	return setScalar(f, values[0])
+}
+
+type conversionError string
+
+func (e conversionError) Error() string {
+	return string(e)
+}
+
+// Parse value with the first of layouts that fits into f, a time.Time or
+// a pointer to one.
+func setTime(f reflect.Value, value string, layouts []string) error {
+	if f.Kind() == reflect.Ptr {
+		p := reflect.New(timeType)
+		if err := setTime(p.Elem(), value, layouts); err != nil {
+			return err
+		}
+
+		f.Set(p)
+
+		return nil
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		f.Set(reflect.ValueOf(time.Time{}))
+		return nil
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			f.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+
+	return conversionError("Must be a valid date")
+}
+
+// Parse value, a number in the format of locale, into f, a decimal.Decimal
+// or a pointer to one.  An empty value leaves a pointer nil and binds 0
+// otherwise, which Required refuses.
+func setDecimal(f reflect.Value, value, locale string) error {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		f.Set(reflect.Zero(f.Type()))
+		return nil
+	}
+
+	num, ok := validator.NormalizeNumber(value, locale)
+	if !ok {
+		return conversionError("Must be a number")
+	}
+
+	d, err := decimal.Parse(num)
+	if err != nil {
+		return conversionError("Must be a number")
+	}
+
+	if f.Kind() == reflect.Ptr {
+		f.Set(reflect.ValueOf(&d))
+	} else {
+		f.Set(reflect.ValueOf(d))
+	}
+
+	return nil
+}
+
+func setScalar(f reflect.Value, value string) error {
+	if f.Kind() == reflect.Ptr {
+		p := reflect.New(f.Type().Elem())
+		if err := setScalar(p.Elem(), value); err != nil {
+			return err
+		}
+
+		f.Set(p)
+
+		return nil
+	}
+
+	if f.Kind() == reflect.String {
+		f.SetString(value)
+		return nil
+	}
+
+	if f.Type() == timeType {
+		return setTime(f, value, TimeLayouts)
+	}
+
+	if f.Type() == decimalType {
+		return setDecimal(f, value, "")
+	}
+
+	value = strings.TrimSpace(value)
+	switch f.Kind() {
+	case reflect.Bool:
+		if value == "" || value == "on" {
+			f.SetBool(value == "on")
+			return nil
+		}
+
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return conversionError("Must be true or false")
+		}
+
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return conversionError("Must be an integer")
+		}
+
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return conversionError("Must be a non-negative integer")
+		}
+
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return conversionError("Must be a number")
+		}
+
+		f.SetFloat(n)
+	default:
+		return conversionError("Unsupported field type " + f.Type().String())
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package binder
+
+import (
+	"bytes"
+	"golanger.com/framework/decimal"
+	"golanger.com/framework/i18n"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type order struct {
+	Price    decimal.Decimal  `form:"price"`
+	Discount *decimal.Decimal `form:"discount"`
+	Total    decimal.Decimal  `form:"total" validate:"money=EUR;0.01"`
+}
+
+// Bind form to an order in the negotiated locale of NewBundle(locale).
+func bindOrder(locale string, form url.Values) (*order, map[string]string) {
+	var o order
+	errors := map[string]string{}
+	h := i18n.NewBundle(locale).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, e := range Bind(r, &o).ErrorMap() {
+			errors[key] = e.Message
+		}
+	}))
+
+	r := httptest.NewRequest("POST", "/orders", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	return &o, errors
+}
+
+func TestBindDecimal(t *testing.T) {
+	o, errors := bindOrder("en", url.Values{"price": {"1,234.50"}, "discount": {""}, "total": {"0.10"}})
+	if len(errors) != 0 {
+		t.Fatalf("errors %v", errors)
+	}
+
+	if o.Price.String() != "1234.50" || o.Discount != nil || o.Total.String() != "0.10" {
+		t.Errorf("bound %v, %v, %v", o.Price, o.Discount, o.Total)
+	}
+}
+
+func TestBindDecimalLocale(t *testing.T) {
+	o, errors := bindOrder("de", url.Values{"price": {"1.234,50"}, "discount": {"0,5"}, "total": {"9,99"}})
+	if len(errors) != 0 {
+		t.Fatalf("errors %v", errors)
+	}
+
+	if o.Price.String() != "1234.50" || o.Discount == nil || o.Discount.String() != "0.5" || o.Total.String() != "9.99" {
+		t.Errorf("bound %v, %v, %v", o.Price, o.Discount, o.Total)
+	}
+}
+
+func TestBindDecimalErrors(t *testing.T) {
+	_, errors := bindOrder("en", url.Values{"price": {"12,3.4.5"}, "total": {"1"}})
+	if errors["price"] != "Must be a number" {
+		t.Errorf("errors %v, want price not a number", errors)
+	}
+
+	_, errors = bindOrder("en", url.Values{"price": {"1"}, "total": {"0.001"}})
+	if errors["Total"] == "" {
+		t.Errorf("errors %v, want total refused in EUR", errors)
+	}
+}
+
+type signup struct {
+	Name     string   `form:"name" validate:"required"`
+	Age      int      `form:"age"`
+	Score    *float64 `form:"score"`
+	Admin    bool     `form:"admin"`
+	Tags     []string `form:"tag"`
+	Ignored  string   `form:"-"`
+	Nickname string
+	secret   string
+}
+
+// Bind form, posted to target, to a signup.
+func bindSignup(target string, form url.Values) (*signup, map[string]string) {
+	var s signup
+	r := httptest.NewRequest("POST", target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	errors := map[string]string{}
+	for key, e := range Bind(r, &s).ErrorMap() {
+		errors[key] = e.Message
+	}
+
+	return &s, errors
+}
+
+func TestBindForm(t *testing.T) {
+	s, errors := bindSignup("/?tag=a&tag=b", url.Values{
+		"name": {"Joe"}, "age": {" 42 "}, "score": {"1.5"}, "admin": {"on"},
+		"Nickname": {"jo"}, "-": {"x"}, "Ignored": {"x"}, "secret": {"x"},
+	})
+	if len(errors) != 0 {
+		t.Fatalf("errors %v", errors)
+	}
+
+	if s.Name != "Joe" || s.Age != 42 || s.Score == nil || *s.Score != 1.5 || !s.Admin || s.Nickname != "jo" {
+		t.Errorf("bound %+v", s)
+	}
+
+	if len(s.Tags) != 2 || s.Tags[0] != "a" || s.Tags[1] != "b" {
+		t.Errorf("bound tags %v", s.Tags)
+	}
+
+	if s.Ignored != "" || s.secret != "" {
+		t.Errorf("bound ignored fields %q, %q", s.Ignored, s.secret)
+	}
+}
+
+func TestBindFormErrors(t *testing.T) {
+	s, errors := bindSignup("/", url.Values{"name": {"Joe"}, "age": {"old"}, "admin": {"maybe"}, "score": {"x"}})
+	want := map[string]string{
+		"age":   "Must be an integer",
+		"admin": "Must be true or false",
+		"score": "Must be a number",
+	}
+	for key, message := range want {
+		if errors[key] != message {
+			t.Errorf("error for %s %q, want %q", key, errors[key], message)
+		}
+	}
+
+	if s.Name != "Joe" {
+		t.Errorf("bound name %q", s.Name)
+	}
+
+	// Values that do not bind are not validated.
+	if _, errors := bindSignup("/", url.Values{"age": {"old"}}); errors["Name"] != "" {
+		t.Errorf("errors %v, want name not validated", errors)
+	}
+
+	if _, errors := bindSignup("/", url.Values{}); errors["Name"] == "" {
+		t.Errorf("errors %v, want name required", errors)
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	var s struct {
+		Name string `json:"name"`
+		Page int    `form:"page"`
+		Age  int    `json:"age"`
+	}
+
+	r := httptest.NewRequest("POST", "/?page=2", strings.NewReader(`{"name": "Joe", "age": 42}`))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if v := Bind(r, &s); v.HasErrors() {
+		t.Fatalf("errors %v", v.ErrorMap())
+	}
+
+	if s.Name != "Joe" || s.Page != 2 || s.Age != 42 {
+		t.Errorf("bound %+v", s)
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"age": "old"}`))
+	r.Header.Set("Content-Type", "application/json")
+	if e := Bind(r, &s).ErrorMap()["age"]; e == nil || e.Code != "validation.type" {
+		t.Errorf("error for age %v", e)
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"age":`))
+	r.Header.Set("Content-Type", "application/json")
+	if v := Bind(r, &s); !v.HasErrors() {
+		t.Error("no error for invalid JSON")
+	}
+}
+
+func TestBindMultipart(t *testing.T) {
+	var s struct {
+		Title  string                  `form:"title"`
+		Avatar *multipart.FileHeader   `form:"avatar"`
+		Photos []*multipart.FileHeader `form:"photo"`
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	mw.WriteField("title", "Holiday")
+	for _, name := range []string{"a.jpg", "b.jpg"} {
+		fw, _ := mw.CreateFormFile("photo", name)
+		fw.Write([]byte("jpeg"))
+	}
+	mw.Close()
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	if v := Bind(r, &s); v.HasErrors() {
+		t.Fatalf("errors %v", v.ErrorMap())
+	}
+
+	if s.Title != "Holiday" || s.Avatar != nil || len(s.Photos) != 2 || s.Photos[1].Filename != "b.jpg" {
+		t.Errorf("bound %+v", s)
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader("garbage"))
+	r.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	if v := Bind(r, &s); !v.HasErrors() {
+		t.Error("no error for an invalid multipart form")
+	}
+}
+
+func TestBindTarget(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?name=Joe", nil)
+	var s signup
+	for _, dst := range []interface{}{nil, s, (*signup)(nil), new(int)} {
+		if v := Bind(r, dst); !v.HasErrors() {
+			t.Errorf("no error binding %T", dst)
+		}
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	var n int8
+	if err := SetValue(reflect.ValueOf(&n).Elem(), []string{"300"}); err == nil {
+		t.Errorf("bound 300 to int8 as %d", n)
+	}
+
+	var u uint
+	if err := SetValue(reflect.ValueOf(&u).Elem(), []string{"-1"}); err == nil {
+		t.Error("bound -1 to uint")
+	}
+
+	var b []byte
+	if err := SetValue(reflect.ValueOf(&b).Elem(), []string{"ab"}); err == nil {
+		t.Error("bound a string to []byte")
+	}
+
+	var ids []int
+	if err := SetValue(reflect.ValueOf(&ids).Elem(), []string{"1", "x"}); err == nil {
+		t.Errorf("bound %v to []int", ids)
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	rt := reflect.TypeOf(struct {
+		A string `form:"a,omitempty"`
+		B string
+	}{})
+
+	if FieldName(rt.Field(0)) != "a" || FieldName(rt.Field(1)) != "B" {
+		t.Errorf("names %q, %q", FieldName(rt.Field(0)), FieldName(rt.Field(1)))
+	}
+}
+
+func TestBindTime(t *testing.T) {
+	var e struct {
+		Start time.Time  `form:"start"`
+		End   *time.Time `form:"end"`
+		Day   time.Time  `form:"day" time_format:"02/01/2006"`
+		Empty time.Time  `form:"empty"`
+	}
+
+	r := httptest.NewRequest("GET", "/?start=2024-06-01T09:30&end=2024-06-02&day=15/03/2024&empty=", nil)
+	if v := Bind(r, &e); v.HasErrors() {
+		t.Fatalf("errors %v", v.ErrorMap())
+	}
+
+	if !e.Start.Equal(time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)) || e.End == nil || e.End.Day() != 2 {
+		t.Errorf("bound %v, %v", e.Start, e.End)
+	}
+
+	if !e.Day.Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) || !e.Empty.IsZero() {
+		t.Errorf("bound %v, %v", e.Day, e.Empty)
+	}
+
+	r = httptest.NewRequest("GET", "/?start=tomorrow&day=2024-03-15", nil)
+	errors := Bind(r, &e).ErrorMap()
+	if errors["start"] == nil || errors["day"] == nil || errors["day"].Message != "Must be a valid date" {
+		t.Errorf("errors %v", errors)
+	}
+}
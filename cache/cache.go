@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// A Store keeps encoded values by key.  Get returns found false, without
+// error, for a missing or expired key.  A ttl of zero means no expiry.
+type Store interface {
+	Get(key string) (value []byte, found bool, err error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+// A call of GetOrCompute in progress, which concurrent callers for the
+// same key wait for instead of computing again.
+type call struct {
+	wg    sync.WaitGroup
+	value []byte
+	err   error
+}
+
+// A Cache stores values of any type, encoded as JSON, in a Store.  It is
+// safe for concurrent use.
+type Cache struct {
+	store Store
+	mutex sync.Mutex
+	calls map[string]*call
+}
+
+func New(store Store) *Cache {
+	return &Cache{
+		store: store,
+		calls: map[string]*call{},
+	}
+}
+
+// The Cache of the package-level functions, in memory.
+var Default = New(NewMemoryStore(10000))
+
+// Decode the value of key into v and report whether there was one.
+func (c *Cache) Get(key string, v interface{}) (bool, error) {
+	b, found, err := c.store.Get(key)
+	if err != nil || !found {
+		return false, err
+	}
+
+	return true, json.Unmarshal(b, v)
+}
+
+func (c *Cache) Set(key string, v interface{}, ttl time.Duration) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return c.store.Set(key, b, ttl)
+}
+
+func (c *Cache) Delete(key string) error {
+	return c.store.Delete(key)
+}
+
+// Decode the value of key into v, computing and storing it for ttl first
+// if there is none.  Concurrent calls for one key compute it once, so a
+// popular key expiring does not send a herd of requests to the database.
+// A failing store is treated as a miss; the errors of compute are
+// returned and not cached.
+func (c *Cache) GetOrCompute(key string, v interface{}, ttl time.Duration, compute func() (interface{}, error)) error {
+	if b, found, err := c.store.Get(key); err == nil && found {
+		if json.Unmarshal(b, v) == nil {
+			return nil
+		}
+	}
+
+	c.mutex.Lock()
+	if cl, ok := c.calls[key]; ok {
+		c.mutex.Unlock()
+		cl.wg.Wait()
+		if cl.err != nil {
+			return cl.err
+		}
+
+		return json.Unmarshal(cl.value, v)
+	}
+
+	cl := &call{}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.calls, key)
+		c.mutex.Unlock()
+		cl.wg.Done()
+	}()
+
+	var result interface{}
+	result, cl.err = compute()
+	if cl.err != nil {
+		return cl.err
+	}
+
+	if cl.value, cl.err = json.Marshal(result); cl.err != nil {
+		return cl.err
+	}
+
+	c.store.Set(key, cl.value, ttl)
+
+	return json.Unmarshal(cl.value, v)
+}
+
+func Get(key string, v interface{}) (bool, error) {
+	return Default.Get(key, v)
+}
+
+func Set(key string, v interface{}, ttl time.Duration) error {
+	return Default.Set(key, v, ttl)
+}
+
+func Delete(key string) error {
+	return Default.Delete(key)
+}
+
+func GetOrCompute(key string, v interface{}, ttl time.Duration, compute func() (interface{}, error)) error {
+	return Default.GetOrCompute(key, v, ttl, compute)
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestGetSet(t *testing.T) {
+	c := New(NewMemoryStore(0))
+	var u user
+	if found, err := c.Get("user:1", &u); found || err != nil {
+		t.Errorf("Get of a missing key = %v, %v", found, err)
+	}
+
+	if err := c.Set("user:1", user{"Joe", 42}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, err := c.Get("user:1", &u); !found || err != nil || u != (user{"Joe", 42}) {
+		t.Errorf("Get = %v, %v, %+v", found, err, u)
+	}
+
+	c.Delete("user:1")
+	if found, _ := c.Get("user:1", &u); found {
+		t.Error("deleted key found")
+	}
+
+	if err := c.Set("f", func() {}, 0); err == nil {
+		t.Error("no error for a value that is not JSON")
+	}
+
+	c.Set("n", 1, 0)
+	if _, err := c.Get("n", &u); err == nil {
+		t.Error("no error decoding a number into a struct")
+	}
+}
+
+func TestGetOrCompute(t *testing.T) {
+	c := New(NewMemoryStore(0))
+	var computed int32
+	compute := func() (interface{}, error) {
+		atomic.AddInt32(&computed, 1)
+		time.Sleep(20 * time.Millisecond)
+
+		return user{"Ann", 30}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var u user
+			if err := c.GetOrCompute("user:2", &u, time.Minute, compute); err != nil || u.Name != "Ann" {
+				t.Errorf("GetOrCompute = %v, %+v", err, u)
+			}
+		}()
+	}
+
+	wg.Wait()
+	if n := atomic.LoadInt32(&computed); n != 1 {
+		t.Errorf("computed %d times", n)
+	}
+
+	var u user
+	c.GetOrCompute("user:2", &u, time.Minute, compute)
+	if n := atomic.LoadInt32(&computed); n != 1 {
+		t.Error("computed again once cached")
+	}
+}
+
+func TestGetOrComputeErrors(t *testing.T) {
+	c := New(NewMemoryStore(0))
+	failure := errors.New("database down")
+	var u user
+	if err := c.GetOrCompute("k", &u, 0, func() (interface{}, error) { return nil, failure }); err != failure {
+		t.Errorf("error %v", err)
+	}
+
+	if found, _ := c.Get("k", &u); found {
+		t.Error("error cached")
+	}
+
+	if err := c.GetOrCompute("k", &u, 0, func() (interface{}, error) { return make(chan int), nil }); err == nil {
+		t.Error("no error for a result that is not JSON")
+	}
+
+	// A failing store is a miss.
+	c = New(failingStore{})
+	if err := c.GetOrCompute("k", &u, 0, func() (interface{}, error) { return user{Name: "Bo"}, nil }); err != nil || u.Name != "Bo" {
+		t.Errorf("GetOrCompute = %v, %+v", err, u)
+	}
+}
+
+type failingStore struct{}
+
+var errStore = errors.New("store down")
+
+func (failingStore) Get(key string) ([]byte, bool, error)                  { return nil, false, errStore }
+func (failingStore) Set(key string, value []byte, ttl time.Duration) error { return errStore }
+func (failingStore) Delete(key string) error                               { return errStore }
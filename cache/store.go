@@ -0,0 +1,252 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// A Store in process memory holding up to a number of entries, evicting
+// the least recently used beyond it.
+type MemoryStore struct {
+	mutex    sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+// Return a MemoryStore of capacity entries, unbounded if capacity is 0.
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	el, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	e := el.Value.(*entry)
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		s.order.Remove(el)
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+
+	s.order.MoveToFront(el)
+
+	return e.value, true, nil
+}
+
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	e := &entry{key: key, value: value}
+	if ttl > 0 {
+		e.expiresAt = time.Now().Add(ttl)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if el, ok := s.entries[key]; ok {
+		el.Value = e
+		s.order.MoveToFront(el)
+		return nil
+	}
+
+	s.entries[key] = s.order.PushFront(e)
+	if s.capacity > 0 && s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*entry).key)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mutex.Lock()
+	if el, ok := s.entries[key]; ok {
+		s.order.Remove(el)
+		delete(s.entries, key)
+	}
+	s.mutex.Unlock()
+
+	return nil
+}
+
+func (s *MemoryStore) Len() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.order.Len()
+}
+
+// The few commands RedisStore and MemcacheStore need, so any client can be
+// adapted to them.  Get returns nil, without error, for a missing key.
+type Client interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Del(key string) error
+}
+
+// A Store in Redis under prefix+key, expired by Redis itself.
+type RedisStore struct {
+	client Client
+	prefix string
+}
+
+func NewRedisStore(client Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Get(key string) ([]byte, bool, error) {
+	b, err := s.client.Get(s.prefix + key)
+	if err != nil || b == nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+func (s *RedisStore) Set(key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(s.prefix+key, value, ttl)
+}
+
+func (s *RedisStore) Delete(key string) error {
+	return s.client.Del(s.prefix + key)
+}
+
+// A Store in memcached under prefix+key.  Keys memcached would refuse,
+// longer than 250 bytes or with spaces or control characters, are hashed.
+// The client is given the ttl as is; memcached itself takes seconds, and
+// a timestamp beyond 30 days.
+type MemcacheStore struct {
+	client Client
+	prefix string
+}
+
+func NewMemcacheStore(client Client, prefix string) *MemcacheStore {
+	return &MemcacheStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *MemcacheStore) key(key string) string {
+	key = s.prefix + key
+	valid := len(key) <= 250
+	for i := 0; valid && i < len(key); i++ {
+		valid = key[i] > ' ' && key[i] != 0x7f
+	}
+
+	if valid {
+		return key
+	}
+
+	h := sha1.Sum([]byte(key))
+
+	return s.prefix + "sha1:" + hex.EncodeToString(h[:])
+}
+
+func (s *MemcacheStore) Get(key string) ([]byte, bool, error) {
+	b, err := s.client.Get(s.key(key))
+	if err != nil || b == nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+func (s *MemcacheStore) Set(key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(s.key(key), value, ttl)
+}
+
+func (s *MemcacheStore) Delete(key string) error {
+	return s.client.Del(s.key(key))
+}
+
+// A Store in tiers, such as a small MemoryStore in front of Redis.  Get
+// looks through the tiers in order and copies a value found in a later
+// one to the earlier ones for BackfillTTL; Set and Delete apply to all.
+type TieredStore struct {
+	tiers []Store
+
+	// How long a backfilled value is kept, as the remaining ttl is
+	// unknown: a minute if zero.
+	BackfillTTL time.Duration
+}
+
+func NewTieredStore(tiers ...Store) *TieredStore {
+	return &TieredStore{tiers: tiers}
+}
+
+func (s *TieredStore) Get(key string) ([]byte, bool, error) {
+	var firstErr error
+	for i, tier := range s.tiers {
+		b, found, err := tier.Get(key)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
+		}
+
+		if !found {
+			continue
+		}
+
+		ttl := s.BackfillTTL
+		if ttl <= 0 {
+			ttl = time.Minute
+		}
+
+		for _, earlier := range s.tiers[:i] {
+			earlier.Set(key, b, ttl)
+		}
+
+		return b, true, nil
+	}
+
+	return nil, false, firstErr
+}
+
+func (s *TieredStore) Set(key string, value []byte, ttl time.Duration) error {
+	var firstErr error
+	for _, tier := range s.tiers {
+		if err := tier.Set(key, value, ttl); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func (s *TieredStore) Delete(key string) error {
+	var firstErr error
+	for _, tier := range s.tiers {
+		if err := tier.Delete(key); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreLRU(t *testing.T) {
+	s := NewMemoryStore(2)
+	s.Set("a", []byte("1"), 0)
+	s.Set("b", []byte("2"), 0)
+	s.Get("a")
+	s.Set("c", []byte("3"), 0)
+
+	if _, found, _ := s.Get("b"); found {
+		t.Error("least recently used entry kept")
+	}
+
+	if v, found, _ := s.Get("a"); !found || string(v) != "1" {
+		t.Error("recently used entry evicted")
+	}
+
+	s.Set("a", []byte("new"), 0)
+	if v, _, _ := s.Get("a"); string(v) != "new" || s.Len() != 2 {
+		t.Errorf("a = %s, %d entries", v, s.Len())
+	}
+
+	s.Delete("a")
+	s.Delete("missing")
+	if s.Len() != 1 {
+		t.Errorf("%d entries", s.Len())
+	}
+}
+
+func TestMemoryStoreTTL(t *testing.T) {
+	s := NewMemoryStore(0)
+	s.Set("short", []byte("x"), 10*time.Millisecond)
+	s.Set("forever", []byte("y"), 0)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, found, _ := s.Get("short"); found || s.Len() != 1 {
+		t.Errorf("expired entry found, %d entries", s.Len())
+	}
+
+	if _, found, _ := s.Get("forever"); !found {
+		t.Error("entry without ttl expired")
+	}
+}
+
+// A Client on a map, recording the keys it is given.
+type mapClient struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newMapClient() *mapClient {
+	return &mapClient{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *mapClient) Get(key string) ([]byte, error) {
+	return c.values[key], nil
+}
+
+func (c *mapClient) Set(key string, value []byte, ttl time.Duration) error {
+	c.values[key], c.ttls[key] = value, ttl
+	return nil
+}
+
+func (c *mapClient) Del(key string) error {
+	delete(c.values, key)
+	return nil
+}
+
+func TestRedisStore(t *testing.T) {
+	client := newMapClient()
+	s := NewRedisStore(client, "app:")
+	s.Set("k", []byte("v"), time.Minute)
+	if string(client.values["app:k"]) != "v" || client.ttls["app:k"] != time.Minute {
+		t.Errorf("stored %v", client.values)
+	}
+
+	if v, found, err := s.Get("k"); !found || err != nil || string(v) != "v" {
+		t.Errorf("Get = %s, %v, %v", v, found, err)
+	}
+
+	s.Delete("k")
+	if _, found, _ := s.Get("k"); found {
+		t.Error("deleted key found")
+	}
+}
+
+func TestMemcacheStoreKeys(t *testing.T) {
+	client := newMapClient()
+	s := NewMemcacheStore(client, "app:")
+	long := strings.Repeat("x", 300)
+	for _, key := range []string{"plain", "with space", "tab\there", long} {
+		s.Set(key, []byte(key), 0)
+		if v, found, _ := s.Get(key); !found || string(v) != key {
+			t.Errorf("Get(%.20q) = %.20q, %v", key, v, found)
+		}
+	}
+
+	for key := range client.values {
+		if len(key) > 250 || strings.ContainsAny(key, " \t") || !strings.HasPrefix(key, "app:") {
+			t.Errorf("key %.40q given to memcached", key)
+		}
+	}
+
+	if client.values["app:plain"] == nil {
+		t.Error("valid key hashed")
+	}
+
+	s.Delete(long)
+	if _, found, _ := s.Get(long); found {
+		t.Error("deleted key found")
+	}
+}
+
+func TestTieredStore(t *testing.T) {
+	near, far := NewMemoryStore(0), NewMemoryStore(0)
+	s := NewTieredStore(near, far)
+	far.Set("k", []byte("v"), 0)
+
+	if v, found, err := s.Get("k"); !found || err != nil || string(v) != "v" {
+		t.Fatalf("Get = %s, %v, %v", v, found, err)
+	}
+
+	if _, found, _ := near.Get("k"); !found {
+		t.Error("value not backfilled")
+	}
+
+	s.Set("both", []byte("x"), 0)
+	s.Delete("k")
+	_, inNear, _ := near.Get("both")
+	_, inFar, _ := far.Get("both")
+	_, kept, _ := far.Get("k")
+	if !inNear || !inFar || kept {
+		t.Errorf("Set or Delete not applied to every tier: %v %v %v", inNear, inFar, kept)
+	}
+
+	// A failing tier is skipped, and its error returned only on a miss.
+	s = NewTieredStore(failingStore{}, far)
+	if _, found, err := s.Get("both"); !found || err != nil {
+		t.Errorf("Get past a failing tier = %v, %v", found, err)
+	}
+
+	if _, found, err := s.Get("none"); found || err != errStore {
+		t.Errorf("Get of a missing key = %v, %v", found, err)
+	}
+
+	if err := s.Set("k", nil, 0); err != errStore {
+		t.Errorf("Set error %v", err)
+	}
+
+	if err := s.Delete("k"); err != errStore {
+		t.Errorf("Delete error %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"golanger.com/framework/request"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// The form fields the widgets of the providers post their token in, and
+// the one of Image.
+var TokenFields = []string{"g-recaptcha-response", "h-captcha-response", "captcha"}
+
+// Return the captcha token posted with r, from the first of TokenFields
+// that is set, prefixed by the IDField of an Image challenge and ":" if
+// there is one.
+func Token(r *http.Request) string {
+	for _, name := range TokenFields {
+		if token := r.FormValue(name); token != "" {
+			if id := r.FormValue(IDField); id != "" && name == "captcha" {
+				return id + ":" + token
+			}
+
+			return token
+		}
+	}
+
+	return ""
+}
+
+// Return the IP of the client of r, the request.RealIP behind the trusted
+// proxies.  trustProxy trusts the peer as a proxy when none is
+// configured, see request.ClientIP.
+func RemoteIP(r *http.Request, trustProxy bool) string {
+	return request.ClientIP(r, trustProxy)
+}
+
+// The answer of a siteverify endpoint, common to reCAPTCHA and hCaptcha.
+type Response struct {
+	Success     bool      `json:"success"`
+	Score       float64   `json:"score"`
+	Action      string    `json:"action"`
+	Hostname    string    `json:"hostname"`
+	ChallengeTS time.Time `json:"challenge_ts"`
+	ErrorCodes  []string  `json:"error-codes"`
+}
+
+// Post a token to the siteverify endpoint at verifyURL.
+func siteVerify(ctx context.Context, client *http.Client, verifyURL, secret, token, remoteIP string, extra url.Values) (*Response, error) {
+	form := url.Values{"secret": {secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	for k, v := range extra {
+		form[k] = v
+	}
+
+	req, err := http.NewRequest("POST", verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("captcha: siteverify answered " + resp.Status)
+	}
+
+	result := &Response{}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Google reCAPTCHA, v2 or v3.  For v3, MinScore is the lowest score
+// accepted, 0.5 if zero, and Action, if set, must be the action the token
+// was issued for.
+type ReCAPTCHA struct {
+	Secret    string
+	MinScore  float64
+	Action    string
+	VerifyURL string
+	Client    *http.Client
+}
+
+func NewReCAPTCHA(secret string) *ReCAPTCHA {
+	return &ReCAPTCHA{Secret: secret, VerifyURL: "https://www.google.com/recaptcha/api/siteverify"}
+}
+
+func (c *ReCAPTCHA) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	resp, err := siteVerify(ctx, c.Client, c.VerifyURL, c.Secret, token, remoteIP, nil)
+	if err != nil || !resp.Success {
+		return false, err
+	}
+
+	if c.Action != "" && resp.Action != c.Action {
+		return false, nil
+	}
+
+	// Only v3 tokens have a score.
+	minScore := c.MinScore
+	if minScore == 0 {
+		minScore = 0.5
+	}
+
+	if resp.Action != "" && resp.Score < minScore {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// hCaptcha.  SiteKey, if set, must be that the token was issued for.
+type HCaptcha struct {
+	Secret    string
+	SiteKey   string
+	VerifyURL string
+	Client    *http.Client
+}
+
+func NewHCaptcha(secret string) *HCaptcha {
+	return &HCaptcha{Secret: secret, VerifyURL: "https://api.hcaptcha.com/siteverify"}
+}
+
+func (c *HCaptcha) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	var extra url.Values
+	if c.SiteKey != "" {
+		extra = url.Values{"sitekey": {c.SiteKey}}
+	}
+
+	resp, err := siteVerify(ctx, c.Client, c.VerifyURL, c.Secret, token, remoteIP, extra)
+	if err != nil {
+		return false, err
+	}
+
+	return resp.Success, nil
+}
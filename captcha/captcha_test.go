@@ -0,0 +1,139 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// A siteverify endpoint answering with resp, recording the last form.
+func endpoint(t *testing.T, resp Response, form *url.Values) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		*form = r.PostForm
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestReCAPTCHA(t *testing.T) {
+	var form url.Values
+	s := endpoint(t, Response{Success: true}, &form)
+	c := NewReCAPTCHA("the secret")
+	c.VerifyURL = s.URL
+
+	ok, err := c.Verify(context.Background(), "tok", "192.0.2.1")
+	if !ok || err != nil {
+		t.Errorf("Verify = %v, %v", ok, err)
+	}
+
+	if form.Get("secret") != "the secret" || form.Get("response") != "tok" || form.Get("remoteip") != "192.0.2.1" {
+		t.Errorf("posted %v", form)
+	}
+
+	cases := []struct {
+		resp   Response
+		action string
+		ok     bool
+	}{
+		{Response{Success: false}, "", false},
+		{Response{Success: true, Action: "login", Score: 0.9}, "login", true},
+		{Response{Success: true, Action: "login", Score: 0.3}, "login", false},
+		{Response{Success: true, Action: "signup", Score: 0.9}, "login", false},
+	}
+
+	for _, tc := range cases {
+		c.VerifyURL = endpoint(t, tc.resp, &form).URL
+		c.Action = tc.action
+		if ok, _ := c.Verify(context.Background(), "tok", ""); ok != tc.ok {
+			t.Errorf("Verify with %+v = %v, want %v", tc.resp, ok, tc.ok)
+		}
+	}
+}
+
+func TestHCaptcha(t *testing.T) {
+	var form url.Values
+	c := NewHCaptcha("s")
+	c.SiteKey = "site"
+	c.VerifyURL = endpoint(t, Response{Success: true}, &form).URL
+	if ok, err := c.Verify(context.Background(), "tok", ""); !ok || err != nil || form.Get("sitekey") != "site" {
+		t.Errorf("Verify = %v, %v, posted %v", ok, err, form)
+	}
+}
+
+func TestSiteVerifyError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	c := NewReCAPTCHA("s")
+	c.VerifyURL = s.URL
+	if ok, err := c.Verify(context.Background(), "tok", ""); ok || err == nil {
+		t.Errorf("Verify = %v, %v, want an error", ok, err)
+	}
+}
+
+func TestImage(t *testing.T) {
+	c := NewImage()
+	id, err := c.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answer, _, _ := c.Store.Get(c.Prefix + id)
+	if len(answer) != 6 {
+		t.Fatalf("answer %q", answer)
+	}
+
+	w := httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest("GET", "/captcha?id="+id, nil))
+	if img, err := png.Decode(w.Body); err != nil || img.Bounds().Dx() != 240 {
+		t.Errorf("image %v, %v", img, err)
+	}
+
+	if ok, _ := c.Verify(context.Background(), id+":"+string(answer), ""); !ok {
+		t.Error("right answer rejected")
+	}
+
+	if ok, _ := c.Verify(context.Background(), id+":"+string(answer), ""); ok {
+		t.Error("answer accepted twice")
+	}
+
+	id, _ = c.New()
+	if ok, _ := c.Verify(context.Background(), id+":wrong", ""); ok {
+		t.Error("wrong answer accepted")
+	}
+
+	answer, found, _ := c.Store.Get(c.Prefix + id)
+	if found {
+		t.Errorf("challenge %q left to retry after a wrong answer", answer)
+	}
+
+	w = httptest.NewRecorder()
+	c.ServeHTTP(w, httptest.NewRequest("GET", "/captcha?id=nope", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status %d for an unknown challenge", w.Code)
+	}
+}
+
+func TestToken(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader("captcha_id=abc&captcha=123456"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if tok := Token(r); tok != "abc:123456" {
+		t.Errorf("Token = %q", tok)
+	}
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader("g-recaptcha-response=xyz&captcha_id=abc"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if tok := Token(r); tok != "xyz" {
+		t.Errorf("Token = %q", tok)
+	}
+}
@@ -0,0 +1,200 @@
+package captcha
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"golanger.com/framework/cache"
+	"golanger.com/framework/log"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"math/big"
+	mrand "math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The form field Image posts the id of its challenge in, next to the
+// answer in the "captcha" field.
+const IDField = "captcha_id"
+
+// The digits 0 to 9 in a 5x7 font, one row of bits per byte.
+var digitFont = [10][7]byte{
+	{0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e},
+	{0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e},
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f},
+	{0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e},
+	{0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02},
+	{0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e},
+	{0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e},
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08},
+	{0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e},
+	{0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c},
+}
+
+// A self-hosted captcha asking to read digits off a noisy image.  The
+// answers are kept in Store, so that any server of a cluster sharing it
+// can verify them, and each can be tried once.
+type Image struct {
+	Store  cache.Store
+	Prefix string
+	TTL    time.Duration
+	Length int
+	Width  int
+	Height int
+}
+
+// Return an Image of 6 digits, 240x80 pixels, valid for 10 minutes and
+// kept in process memory.
+func NewImage() *Image {
+	return &Image{
+		Store:  cache.NewMemoryStore(10000),
+		Prefix: "captcha:",
+		TTL:    10 * time.Minute,
+		Length: 6,
+		Width:  240,
+		Height: 80,
+	}
+}
+
+// Create a challenge and return its id.
+func (c *Image) New() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	answer := make([]byte, c.Length)
+	for i := range answer {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+
+		answer[i] = byte('0' + n.Int64())
+	}
+
+	id := hex.EncodeToString(b)
+	if err := c.Store.Set(c.Prefix+id, answer, c.TTL); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Create a challenge and render its image, served at src by c, with the
+// hidden IDField and the text input of the answer.
+func (c *Image) HTML(src string) (template.HTML, error) {
+	id, err := c.New()
+	if err != nil {
+		return "", err
+	}
+
+	sep := "?"
+	if strings.Contains(src, "?") {
+		sep = "&"
+	}
+
+	return template.HTML(`<img class="captcha" src="` + template.HTMLEscapeString(src+sep+"id="+id) + `" alt="">` +
+		`<input type="hidden" name="` + IDField + `" value="` + id + `">` +
+		`<input type="text" name="captcha" inputmode="numeric" autocomplete="off" required>`), nil
+}
+
+// Serve the PNG of the challenge named by the id query parameter, drawn
+// with fresh noise every time.
+func (c *Image) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	answer, found, err := c.Store.Get(c.Prefix + r.URL.Query().Get("id"))
+	if err != nil {
+		log.FromRequest(r).Error("<captcha.Image.ServeHTTP> ", err)
+	}
+
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "no-store")
+	png.Encode(w, c.draw(answer))
+}
+
+func (c *Image) draw(answer []byte) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, c.Width, c.Height))
+	for i := range img.Pix {
+		img.Pix[i] = byte(200 + mrand.Intn(56))
+	}
+
+	cell := c.Width / (len(answer) + 1)
+	scale := c.Height / 10
+	if s := cell / 6; s < scale {
+		scale = s
+	}
+
+	if scale < 1 {
+		scale = 1
+	}
+
+	for i, d := range answer {
+		x0 := cell/2 + i*cell + mrand.Intn(cell/4+1)
+		y0 := (c.Height-7*scale)/2 + mrand.Intn(scale*2+1) - scale
+		shade := byte(mrand.Intn(80))
+		for row, bits := range digitFont[d-'0'] {
+			// Shear each row a little, so that digits do not line up.
+			shift := (3 - row) * mrand.Intn(scale/2+1) / 2
+			for col := 0; col < 5; col++ {
+				if bits&(0x10>>uint(col)) == 0 {
+					continue
+				}
+
+				for dy := 0; dy < scale; dy++ {
+					for dx := 0; dx < scale; dx++ {
+						img.SetGray(x0+shift+col*scale+dx, y0+row*scale+dy, color.Gray{shade})
+					}
+				}
+			}
+		}
+	}
+
+	// Lines across the digits, and speckles.
+	for n := 0; n < 4; n++ {
+		y, dy := mrand.Intn(c.Height), mrand.Intn(5)-2
+		for x := 0; x < c.Width; x++ {
+			if x%8 == 0 {
+				y += dy
+			}
+
+			img.SetGray(x, y, color.Gray{byte(mrand.Intn(100))})
+		}
+	}
+
+	for n := 0; n < c.Width*c.Height/20; n++ {
+		img.SetGray(mrand.Intn(c.Width), mrand.Intn(c.Height), color.Gray{byte(mrand.Intn(256))})
+	}
+
+	return img
+}
+
+// Verify a token of the form id:answer, as Token returns it for a form of
+// HTML.  A challenge can be tried once, right or wrong.
+func (c *Image) Verify(ctx context.Context, token, remoteIP string) (bool, error) {
+	i := strings.Index(token, ":")
+	if i == -1 {
+		return false, nil
+	}
+
+	key := c.Prefix + token[:i]
+	answer, found, err := c.Store.Get(key)
+	if err != nil || !found {
+		return false, err
+	}
+
+	if err := c.Store.Delete(key); err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(answer, []byte(strings.TrimSpace(token[i+1:]))) == 1, nil
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/build"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+func execute(text string, data interface{}) ([]byte, error) {
+	t, err := template.New("").Delims("[[", "]]").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.Execute(buf, data)
+
+	return buf.Bytes(), err
+}
+
+// Write the files under dir, their paths and contents being text/templates
+// with [[ ]] delimiters.  Go files are gofmt'ed.  Existing files are never
+// overwritten.
+func writeFiles(dir string, files []struct{ path, text string }, data interface{}) error {
+	for _, file := range files {
+		name, err := execute(file.path, data)
+		if err != nil {
+			return err
+		}
+
+		b, err := execute(file.text, data)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(string(name), ".go") {
+			if b, err = format.Source(b); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(string(name)))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+
+		_, err = f.Write(b)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("created", path)
+	}
+
+	return nil
+}
+
+var projectName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Create the skeleton of a project in the directory name, which must be
+// under the src directory of a GOPATH workspace, as the framework is
+// built in GOPATH mode.
+func newProject(name string) error {
+	if !projectName.MatchString(name) {
+		return errors.New("project names start with a letter and hold only letters, digits, - and _")
+	}
+
+	if _, err := os.Stat(name); err == nil {
+		return errors.New(name + " already exists")
+	}
+
+	path, err := importPath(name)
+	if err != nil {
+		return err
+	}
+
+	err = writeFiles(name, skeleton, map[string]string{"Name": name, "Import": path})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\nNext:\n\tcd %s\n\tGO111MODULE=off go get -d ./...\n\tframework run\n", name)
+
+	return nil
+}
+
+// Return the import path of the package in dir, from where it is under
+// the src directory of a GOPATH workspace.
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for _, root := range filepath.SplitList(build.Default.GOPATH) {
+		rel, err := filepath.Rel(filepath.Join(root, "src"), abs)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s is not under the src directory of GOPATH (%s); create projects there", abs, build.Default.GOPATH)
+}
+
+// A field of a generated model.
+type field struct {
+	Name     string // Go name, FirstName
+	Form     string // form and column name, first_name
+	Label    string // First name
+	GoType   string
+	Tags     string // validate and time_format tags
+	Input    string // type of the <input>, or "textarea"
+	Checkbox bool
+	Date     bool
+
+	// Whether binding may fail to convert the value, an error binder keys
+	// by Form rather than Name.
+	Convert bool
+}
+
+var fieldSpec = regexp.MustCompile(`^([a-z][a-z0-9_]*):(string|text|email|int|float|bool|date)$`)
+
+func parseField(spec string) (field, error) {
+	m := fieldSpec.FindStringSubmatch(spec)
+	if m == nil {
+		return field{}, fmt.Errorf("bad field %q: want name:type, with type string, text, email, int, float, bool or date", spec)
+	}
+
+	f := field{Name: camel(m[1]), Form: m[1], Label: label(m[1])}
+	switch m[2] {
+	case "string":
+		f.GoType, f.Tags, f.Input = "string", `validate:"required"`, "text"
+	case "text":
+		f.GoType, f.Tags, f.Input = "string", `validate:"required"`, "textarea"
+	case "email":
+		f.GoType, f.Tags, f.Input = "string", `validate:"required,email"`, "email"
+	case "int":
+		f.GoType, f.Input, f.Convert = "int", "number", true
+	case "float":
+		f.GoType, f.Input, f.Convert = "float64", "number", true
+	case "bool":
+		f.GoType, f.Input, f.Checkbox = "bool", "checkbox", true
+	case "date":
+		f.GoType, f.Tags, f.Input, f.Date, f.Convert = "time.Time", `time_format:"2006-01-02"`, "date", true, true
+	}
+
+	return f, nil
+}
+
+// first_name to FirstName.
+func camel(s string) string {
+	parts := strings.Split(s, "_")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+
+	return strings.Join(parts, "")
+}
+
+// first_name to First name.
+func label(s string) string {
+	s = strings.Replace(s, "_", " ", -1)
+
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// BlogPost to blog_post.
+func snake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func plural(s string) string {
+	switch {
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	}
+
+	return s + "s"
+}
+
+var modelName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+// Generate a model with fields, a controller with its CRUD actions and
+// routes, and their views, in the project in the current directory.
+func generateController(name string, specs []string) error {
+	if !modelName.MatchString(name) {
+		return errors.New("controller names hold only letters and digits, e.g. User or BlogPost")
+	}
+
+	path, err := importPath(".")
+	if err != nil {
+		return err
+	}
+
+	name = strings.ToUpper(name[:1]) + name[1:]
+	fields := []field{}
+	if len(specs) == 0 {
+		specs = []string{"name:string"}
+	}
+
+	hasDate := false
+	for _, spec := range specs {
+		f, err := parseField(spec)
+		if err != nil {
+			return err
+		}
+
+		hasDate = hasDate || f.Date
+		fields = append(fields, f)
+	}
+
+	lower := strings.ToLower(name[:1]) + name[1:]
+	data := map[string]interface{}{
+		"Import":  path,
+		"Model":   name,
+		"Models":  plural(name),
+		"Var":     lower,
+		"Vars":    plural(lower),
+		"Path":    plural(snake(name)),
+		"File":    snake(name),
+		"Label":   label(snake(name)),
+		"Labels":  label(plural(snake(name))),
+		"Fields":  fields,
+		"HasDate": hasDate,
+	}
+
+	return writeFiles(".", controllerFiles, data)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Run f in a new directory src/example.com of a temporary GOPATH.
+func inGopath(t *testing.T, f func()) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "src", "example.com")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+	gopath := build.Default.GOPATH
+	build.Default.GOPATH = root
+	defer func() {
+		build.Default.GOPATH = gopath
+		os.Chdir(wd)
+	}()
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	f()
+}
+
+func TestImportPath(t *testing.T) {
+	inGopath(t, func() {
+		if path, err := importPath("shop/controllers"); err != nil || path != "example.com/shop/controllers" {
+			t.Errorf("importPath = %q, %v", path, err)
+		}
+
+		if _, err := importPath("../.."); err == nil {
+			t.Error("no error for the GOPATH itself")
+		}
+
+		if _, err := importPath(os.TempDir()); err == nil {
+			t.Error("no error outside GOPATH")
+		}
+	})
+}
+
+func TestNewProject(t *testing.T) {
+	inGopath(t, func() {
+		if err := newProject("shop"); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := os.Stat(filepath.Join("shop", "go.mod")); err == nil {
+			t.Error("go.mod created")
+		}
+
+		main, err := os.ReadFile(filepath.Join("shop", "main.go"))
+		if err != nil || !strings.Contains(string(main), `"example.com/shop/controllers"`) {
+			t.Errorf("main.go does not import the controllers by their GOPATH path: %v", err)
+		}
+
+		os.Chdir("shop")
+		if err := generateController("BlogPost", []string{"title:string", "published:date"}); err != nil {
+			t.Fatal(err)
+		}
+
+		controller, err := os.ReadFile(filepath.Join("controllers", "blog_posts.go"))
+		if err != nil || !strings.Contains(string(controller), `"example.com/shop/models"`) {
+			t.Errorf("controller does not import the models by their GOPATH path: %v", err)
+		}
+	})
+}
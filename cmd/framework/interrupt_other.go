@@ -0,0 +1,13 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package main
+
+import (
+	"os"
+)
+
+// There is no signal to ask for a graceful shutdown.
+func interrupt(p *os.Process) {
+	p.Kill()
+}
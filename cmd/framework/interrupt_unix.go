@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// Ask the application to shut down gracefully.
+func interrupt(p *os.Process) {
+	p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,143 @@
+// Command framework is the development tool of Golanger applications.
+//
+//	framework run [-listen :3000] [-app http://127.0.0.1:8080] [-dir .] [package] [-- args]
+//
+// run builds the application, starts it and serves it through a proxy on
+// the listen address.  Whenever a Go file under dir changes, it rebuilds
+// and restarts the application; meanwhile the proxy answers with a
+// "compiling" page that reloads itself, or with the compiler errors.  The
+// application is started with GOLANGER_DEV=1, under which render reparses
+// templates on every request, so template changes need no restart.
+//
+//	framework migrate [-dir migrations] [package] up|down [n]|status
+//	framework migrate [-dir migrations] create name
+//
+// migrate runs the application with the command in GOLANGER_MIGRATE, for
+// db.HandleMigrate to apply or revert the migrations of dir once the
+// application has opened its database.  create adds an empty pair of
+// migration files.
+//
+//	framework new name
+//	framework generate controller Name [field:type ...]
+//
+// new creates the skeleton of a project: main.go, config, controllers,
+// views, locale and migrations.  Projects are built in GOPATH mode, like
+// the framework, so new must be run under $GOPATH/src, the import paths
+// of the project following from where it is; run and migrate build with
+// GO111MODULE=off.  generate controller, run in the root of
+// a project, adds a model with `validate` tags, an in-memory store for
+// it, a controller with its CRUD routes, and their views.  Field types
+// are string, text, email, int, float, bool and date; the default is
+// name:string.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: framework run [-listen :3000] [-app http://127.0.0.1:8080] [-dir .] [package] [-- args]")
+	fmt.Fprintln(os.Stderr, "       framework migrate [-dir migrations] [package] up|down [n]|status")
+	fmt.Fprintln(os.Stderr, "       framework migrate [-dir migrations] create name")
+	fmt.Fprintln(os.Stderr, "       framework new name")
+	fmt.Fprintln(os.Stderr, "       framework generate controller Name [field:type ...]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "run":
+		fs := flag.NewFlagSet("run", flag.ExitOnError)
+		listen := fs.String("listen", ":3000", "address the proxy listens on")
+		app := fs.String("app", "http://127.0.0.1:8080", "URL the application listens on")
+		dir := fs.String("dir", ".", "directory watched for changes")
+		fs.Parse(os.Args[2:])
+
+		pkg, args := ".", []string{}
+		rest := fs.Args()
+		if len(rest) > 0 && rest[0] != "--" {
+			pkg, rest = rest[0], rest[1:]
+		}
+
+		if len(rest) > 0 && rest[0] == "--" {
+			args = rest[1:]
+		}
+
+		r, err := newRunner(*dir, pkg, *app, args)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "framework:", err)
+			os.Exit(1)
+		}
+
+		if err := r.run(*listen); err != nil {
+			fmt.Fprintln(os.Stderr, "framework:", err)
+			os.Exit(1)
+		}
+	case "migrate":
+		fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+		dir := fs.String("dir", "migrations", "directory of the migration files")
+		fs.Parse(os.Args[2:])
+
+		pkg, rest := ".", fs.Args()
+		if len(rest) > 0 && !isMigrateCommand(rest[0]) {
+			pkg, rest = rest[0], rest[1:]
+		}
+
+		if len(rest) == 0 || !isMigrateCommand(rest[0]) {
+			usage()
+		}
+
+		var err error
+		if rest[0] == "create" {
+			if len(rest) != 2 {
+				usage()
+			}
+
+			err = createMigration(*dir, rest[1])
+		} else {
+			err = migrate(*dir, pkg, rest)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "framework:", err)
+			os.Exit(1)
+		}
+	case "new":
+		fs := flag.NewFlagSet("new", flag.ExitOnError)
+		fs.Parse(os.Args[2:])
+		if fs.NArg() != 1 {
+			usage()
+		}
+
+		if err := newProject(fs.Arg(0)); err != nil {
+			fmt.Fprintln(os.Stderr, "framework:", err)
+			os.Exit(1)
+		}
+	case "generate":
+		if len(os.Args) < 4 || os.Args[2] != "controller" {
+			usage()
+		}
+
+		if err := generateController(os.Args[3], os.Args[4:]); err != nil {
+			fmt.Fprintln(os.Stderr, "framework:", err)
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+func isMigrateCommand(arg string) bool {
+	switch arg {
+	case "up", "down", "status", "create":
+		return true
+	}
+
+	return false
+}
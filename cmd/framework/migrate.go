@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var migrationName = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// Create an empty pair of migration files named after the current time.
+func createMigration(dir, name string) error {
+	if !migrationName.MatchString(name) {
+		return errors.New("migration names may only hold letters, digits and _")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	base := filepath.Join(dir, time.Now().UTC().Format("20060102150405")+"_"+strings.ToLower(name))
+	for _, suffix := range []string{".up.sql", ".down.sql"} {
+		f, err := os.OpenFile(base+suffix, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+
+		f.Close()
+		fmt.Println("created", base+suffix)
+	}
+
+	return nil
+}
+
+// Run the application with the migration command in its environment, for
+// db.HandleMigrate to carry out once the application has opened its
+// database.
+func migrate(dir, pkg string, command []string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", pkg)
+	cmd.Env = append(goEnv(),
+		"GOLANGER_MIGRATE="+strings.Join(command, " "),
+		"GOLANGER_MIGRATIONS="+abs,
+	)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
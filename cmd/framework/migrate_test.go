@@ -0,0 +1,25 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateMigration(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	if err := createMigration(dir, "Add_Users"); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 2 || !strings.HasSuffix(files[0], "_add_users.down.sql") || !strings.HasSuffix(files[1], "_add_users.up.sql") {
+		t.Errorf("created %v", files)
+	}
+
+	for _, name := range []string{"add users", "../escape", ""} {
+		if err := createMigration(dir, name); err == nil {
+			t.Errorf("no error for %q", name)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How often the sources are checked for changes.
+var pollInterval = 500 * time.Millisecond
+
+type runner struct {
+	dir    string
+	pkg    string
+	app    *url.URL
+	args   []string
+	binary string
+
+	mutex    sync.Mutex
+	building bool
+	buildErr string
+	cmd      *exec.Cmd
+	exited   chan struct{}
+}
+
+func newRunner(dir, pkg, app string, args []string) (*runner, error) {
+	u, err := url.Parse(app)
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", "framework-run")
+	if err != nil {
+		return nil, err
+	}
+
+	return &runner{
+		dir:    dir,
+		pkg:    pkg,
+		app:    u,
+		args:   args,
+		binary: filepath.Join(tmp, "app"),
+	}, nil
+}
+
+// Return the latest modification time of the Go sources under the
+// directory, skipping hidden directories and vendor.
+func (r *runner) sourcesModTime() time.Time {
+	var latest time.Time
+	filepath.Walk(r.dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		name := fi.Name()
+		if fi.IsDir() {
+			if path != r.dir && (strings.HasPrefix(name, ".") || name == "vendor") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(name, ".go") && fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+
+		return nil
+	})
+
+	return latest
+}
+
+func (r *runner) run(listen string) error {
+	go r.watch()
+	log.Println("proxying", listen, "to", r.app)
+
+	return http.ListenAndServe(listen, r.handler())
+}
+
+// Return the proxy to the application, which answers with a page of its
+// own while building or after a failed build.
+func (r *runner) handler() http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(r.app)
+	proxy.ErrorHandler = func(w http.ResponseWriter, req *http.Request, err error) {
+		r.page(w, http.StatusBadGateway, "Starting…", "The application is not answering yet: "+err.Error(), true)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mutex.Lock()
+		building, buildErr := r.building, r.buildErr
+		r.mutex.Unlock()
+
+		switch {
+		case building:
+			r.page(w, http.StatusServiceUnavailable, "Compiling…", "", true)
+		case buildErr != "":
+			r.page(w, http.StatusInternalServerError, "Build failed", buildErr, false)
+		default:
+			proxy.ServeHTTP(w, req)
+		}
+	})
+}
+
+// Rebuild and restart whenever the sources change.
+func (r *runner) watch() {
+	var last time.Time
+	for {
+		if mod := r.sourcesModTime(); !mod.Equal(last) {
+			last = mod
+			r.restart()
+		}
+
+		time.Sleep(pollInterval)
+	}
+}
+
+func (r *runner) restart() {
+	r.mutex.Lock()
+	r.building = true
+	r.mutex.Unlock()
+
+	r.stop()
+	log.Println("building", r.pkg)
+
+	var out bytes.Buffer
+	build := exec.Command("go", "build", "-o", r.binary, r.pkg)
+	build.Dir = r.dir
+	build.Env = goEnv()
+	build.Stdout, build.Stderr = &out, &out
+	err := build.Run()
+
+	if err == nil {
+		err = r.start()
+		if err == nil {
+			r.waitReady()
+		}
+	} else {
+		err = errors.New(out.String() + err.Error())
+	}
+
+	r.mutex.Lock()
+	r.building = false
+	r.buildErr = ""
+	if err != nil {
+		r.buildErr = err.Error()
+		log.Print("failed:\n", r.buildErr)
+	}
+	r.mutex.Unlock()
+}
+
+// Return the environment of the go commands, which build in GOPATH mode.
+func goEnv() []string {
+	return append(os.Environ(), "GO111MODULE=off")
+}
+
+func (r *runner) start() error {
+	cmd := exec.Command(r.binary, r.args...)
+	cmd.Dir = r.dir
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), "GOLANGER_DEV=1")
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+
+	r.mutex.Lock()
+	r.cmd, r.exited = cmd, exited
+	r.mutex.Unlock()
+
+	return nil
+}
+
+// Wait, for a few seconds at most, until the application accepts
+// connections, so that the first request after a rebuild reaches it.
+func (r *runner) waitReady() {
+	host := r.app.Host
+	if r.app.Port() == "" {
+		host = net.JoinHostPort(r.app.Hostname(), "80")
+	}
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		if conn, err := net.DialTimeout("tcp", host, 100*time.Millisecond); err == nil {
+			conn.Close()
+			return
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Stop the application, giving it a few seconds to shut down gracefully.
+func (r *runner) stop() {
+	r.mutex.Lock()
+	cmd, exited := r.cmd, r.exited
+	r.cmd, r.exited = nil, nil
+	r.mutex.Unlock()
+	if cmd == nil {
+		return
+	}
+
+	interrupt(cmd.Process)
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		cmd.Process.Kill()
+		<-exited
+	}
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+{{if .Refresh}}<meta http-equiv="refresh" content="1">{{end}}
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Text}}<pre>{{.Text}}</pre>{{end}}
+</body>
+</html>
+`))
+
+func (r *runner) page(w http.ResponseWriter, status int, title, text string, refresh bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	pageTemplate.Execute(w, map[string]interface{}{
+		"Title":   title,
+		"Text":    text,
+		"Refresh": refresh,
+	})
+}
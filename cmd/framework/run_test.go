@@ -0,0 +1,147 @@
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const app = `package main
+
+import (
+	"net/http"
+	"os"
+)
+
+func main() {
+	http.ListenAndServe(os.Args[1], http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("dev=" + os.Getenv("GOLANGER_DEV")))
+	}))
+}
+`
+
+// Return an address nothing listens on yet.
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	return l.Addr().String()
+}
+
+func get(h http.Handler) (int, string) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	return w.Code, w.Body.String()
+}
+
+func TestHandler(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "from the app")
+	}))
+	defer backend.Close()
+
+	r, err := newRunner(".", ".", backend.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := r.handler()
+
+	if code, body := get(h); code != http.StatusOK || body != "from the app" {
+		t.Errorf("proxied %d %q", code, body)
+	}
+
+	r.building = true
+	if code, body := get(h); code != http.StatusServiceUnavailable || !strings.Contains(body, "Compiling…") || !strings.Contains(body, `http-equiv="refresh"`) {
+		t.Errorf("building %d %q", code, body)
+	}
+
+	r.building, r.buildErr = false, "main.go:1: <bad>"
+	if code, body := get(h); code != http.StatusInternalServerError || !strings.Contains(body, "main.go:1: &lt;bad&gt;") || strings.Contains(body, "refresh") {
+		t.Errorf("failed build %d %q", code, body)
+	}
+
+	r.buildErr = ""
+	backend.Close()
+	if code, body := get(h); code != http.StatusBadGateway || !strings.Contains(body, "not answering yet") {
+		t.Errorf("application down %d %q", code, body)
+	}
+}
+
+func TestSourcesModTime(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.go", "views/index.html", ".git/hook.go", "vendor/lib/lib.go", "models/user.go"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, nil, 0644)
+	}
+
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		return os.Chtimes(path, old, old)
+	})
+
+	r := &runner{dir: dir}
+	if got := r.sourcesModTime(); !got.Equal(old) {
+		t.Errorf("sourcesModTime = %v, want %v", got, old)
+	}
+
+	// Only Go files outside hidden directories and vendor count.
+	recent := old.Add(time.Minute)
+	for _, name := range []string{"views/index.html", ".git/hook.go", "vendor/lib/lib.go"} {
+		os.Chtimes(filepath.Join(dir, name), recent, recent)
+	}
+	if got := r.sourcesModTime(); !got.Equal(old) {
+		t.Errorf("changed by other files: %v", got)
+	}
+
+	os.Chtimes(filepath.Join(dir, "models/user.go"), recent, recent)
+	if got := r.sourcesModTime(); !got.Equal(recent) {
+		t.Errorf("sourcesModTime = %v, want %v", got, recent)
+	}
+}
+
+func TestRestart(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {"), 0644)
+
+	addr := freeAddr(t)
+	r, err := newRunner(dir, ".", "http://"+addr, []string{addr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(filepath.Dir(r.binary))
+	defer r.stop()
+
+	r.restart()
+	if r.building || !strings.Contains(r.buildErr, "main.go") || r.cmd != nil {
+		t.Fatalf("after a failed build: building %v, %q", r.building, r.buildErr)
+	}
+
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte(app), 0644)
+	r.restart()
+	if r.buildErr != "" {
+		t.Fatal(r.buildErr)
+	}
+
+	if code, body := get(r.handler()); code != http.StatusOK || body != "dev=1" {
+		t.Errorf("proxied %d %q", code, body)
+	}
+
+	exited := r.exited
+	r.stop()
+	select {
+	case <-exited:
+	default:
+		t.Error("application still running")
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+// The files of `framework generate controller`, in the form of skeleton.
+var controllerFiles = []struct {
+	path, text string
+}{
+	{"models/[[.File]].go", `package models
+
+import (
+	"sort"
+	"sync"
+[[- if .HasDate]]
+	"time"
+[[- end]]
+)
+
+type [[.Model]] struct {
+	ID int64 ` + "`form:\"-\"`" + `
+[[- range .Fields]]
+	[[.Name]] [[.GoType]] ` + "`form:\"[[.Form]]\"[[if .Tags]] [[.Tags]][[end]]`" + `
+[[- end]]
+}
+
+type [[.Var]]Store struct {
+	mutex  sync.RWMutex
+	nextID int64
+	[[.Vars]] map[int64]*[[.Model]]
+}
+
+// The [[.Vars]], kept in memory until a database-backed store replaces
+// this one.
+var [[.Models]] = &[[.Var]]Store{[[.Vars]]: map[int64]*[[.Model]]{}}
+
+// Return every [[.Var]] by ID.
+func (s *[[.Var]]Store) All() []*[[.Model]] {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	all := make([]*[[.Model]], 0, len(s.[[.Vars]]))
+	for _, [[.Var]] := range s.[[.Vars]] {
+		c := *[[.Var]]
+		all = append(all, &c)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].ID < all[j].ID
+	})
+
+	return all
+}
+
+func (s *[[.Var]]Store) Get(id int64) (*[[.Model]], bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	[[.Var]], ok := s.[[.Vars]][id]
+	if !ok {
+		return nil, false
+	}
+
+	c := *[[.Var]]
+
+	return &c, true
+}
+
+// Store a new [[.Var]], setting its ID.
+func (s *[[.Var]]Store) Create([[.Var]] *[[.Model]]) {
+	s.mutex.Lock()
+	s.nextID++
+	[[.Var]].ID = s.nextID
+	c := *[[.Var]]
+	s.[[.Vars]][c.ID] = &c
+	s.mutex.Unlock()
+}
+
+// Replace the stored [[.Var]] of the same ID and report whether there was
+// one.
+func (s *[[.Var]]Store) Update([[.Var]] *[[.Model]]) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.[[.Vars]][ [[- .Var]].ID]; !ok {
+		return false
+	}
+
+	c := *[[.Var]]
+	s.[[.Vars]][c.ID] = &c
+
+	return true
+}
+
+func (s *[[.Var]]Store) Delete(id int64) {
+	s.mutex.Lock()
+	delete(s.[[.Vars]], id)
+	s.mutex.Unlock()
+}
+`},
+	{"controllers/[[.Path]].go", `package controllers
+
+import (
+	"golanger.com/framework/binder"
+	"golanger.com/framework/controller"
+	"golanger.com/framework/router"
+	"net/http"
+	"strconv"
+	"[[.Import]]/models"
+)
+
+func init() {
+	register(func(rt *router.Router) {
+		rt.Handle("GET", "/[[.Path]]", controller.Handler([[.Vars]]Index)).Named("[[.Path]].index")
+		rt.Handle("GET", "/[[.Path]]/new", controller.Handler([[.Vars]]New)).Named("[[.Path]].new")
+		rt.Handle("POST", "/[[.Path]]", controller.Handler([[.Vars]]Create)).Named("[[.Path]].create")
+		rt.Handle("GET", "/[[.Path]]/:id", controller.Handler([[.Vars]]Show)).Named("[[.Path]].show")
+		rt.Handle("GET", "/[[.Path]]/:id/edit", controller.Handler([[.Vars]]Edit)).Named("[[.Path]].edit")
+		rt.Handle("POST", "/[[.Path]]/:id", controller.Handler([[.Vars]]Update)).Named("[[.Path]].update")
+		rt.Handle("POST", "/[[.Path]]/:id/delete", controller.Handler([[.Vars]]Delete)).Named("[[.Path]].delete")
+	})
+}
+
+// Return the [[.Var]] of the :id parameter, or answer 404 and return nil.
+func find[[.Model]](c *controller.Controller) *models.[[.Model]] {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err == nil {
+		if [[.Var]], ok := models.[[.Models]].Get(id); ok {
+			return [[.Var]]
+		}
+	}
+
+	http.NotFound(c.Response, c.Request)
+
+	return nil
+}
+
+func [[.Vars]]Index(c *controller.Controller) {
+	c.Render("[[.Path]]/index", map[string]interface{}{
+		"[[.Models]]": models.[[.Models]].All(),
+	})
+}
+
+func [[.Vars]]New(c *controller.Controller) {
+	c.Render("[[.Path]]/new", map[string]interface{}{
+		"[[.Model]]": &models.[[.Model]]{},
+	})
+}
+
+func [[.Vars]]Create(c *controller.Controller) {
+	[[.Var]] := &models.[[.Model]]{}
+	if v := binder.Bind(c.Request, [[.Var]]); v.HasErrors() {
+		c.Validation.Merge(v)
+		c.Render("[[.Path]]/new", map[string]interface{}{
+			"[[.Model]]": [[.Var]],
+		})
+		return
+	}
+
+	models.[[.Models]].Create([[.Var]])
+	c.Flash.Success("[[.Label]] created")
+	c.Redirect("/[[.Path]]/" + strconv.FormatInt([[.Var]].ID, 10))
+}
+
+func [[.Vars]]Show(c *controller.Controller) {
+	if [[.Var]] := find[[.Model]](c); [[.Var]] != nil {
+		c.Render("[[.Path]]/show", map[string]interface{}{
+			"[[.Model]]": [[.Var]],
+		})
+	}
+}
+
+func [[.Vars]]Edit(c *controller.Controller) {
+	if [[.Var]] := find[[.Model]](c); [[.Var]] != nil {
+		c.Render("[[.Path]]/edit", map[string]interface{}{
+			"[[.Model]]": [[.Var]],
+		})
+	}
+}
+
+func [[.Vars]]Update(c *controller.Controller) {
+	[[.Var]] := find[[.Model]](c)
+	if [[.Var]] == nil {
+		return
+	}
+
+	if v := binder.Bind(c.Request, [[.Var]]); v.HasErrors() {
+		c.Validation.Merge(v)
+		c.Render("[[.Path]]/edit", map[string]interface{}{
+			"[[.Model]]": [[.Var]],
+		})
+		return
+	}
+
+	models.[[.Models]].Update([[.Var]])
+	c.Flash.Success("[[.Label]] updated")
+	c.Redirect("/[[.Path]]/" + strconv.FormatInt([[.Var]].ID, 10))
+}
+
+func [[.Vars]]Delete(c *controller.Controller) {
+	if [[.Var]] := find[[.Model]](c); [[.Var]] != nil {
+		models.[[.Models]].Delete([[.Var]].ID)
+		c.Flash.Success("[[.Label]] deleted")
+		c.Redirect("/[[.Path]]")
+	}
+}
+`},
+	{"view/partials/[[.Path]]_form.html", `{{define "[[.Path]]/form"}}
+{{.CSRFField}}
+[[- range .Fields]]
+<p>
+[[- if .Checkbox]]
+<label><input type="checkbox" name="[[.Form]]" value="true"{{if .[[$.Model]].[[.Name]]}} checked{{end}}> [[.Label]]</label>
+<input type="hidden" name="[[.Form]]" value="">
+[[- else]]
+<label for="[[.Form]]">[[.Label]]</label>
+[[- if eq .Input "textarea"]]
+<textarea id="[[.Form]]" name="[[.Form]]">{{.[[$.Model]].[[.Name]]}}</textarea>
+[[- else if .Date]]
+<input type="date" id="[[.Form]]" name="[[.Form]]" value="{{if not .[[$.Model]].[[.Name]].IsZero}}{{.[[$.Model]].[[.Name]].Format "2006-01-02"}}{{end}}">
+[[- else]]
+<input type="[[.Input]]" id="[[.Form]]" name="[[.Form]]" value="{{.[[$.Model]].[[.Name]]}}"[[if eq .GoType "float64"]] step="any"[[end]]>
+[[- end]]
+[[- end]]
+{{errorFor "[[.Name]]" .Validation}}
+[[- if .Convert]]{{errorFor "[[.Form]]" .Validation}}[[end]]
+</p>
+[[- end]]
+<button type="submit">Save</button>
+{{end}}
+`},
+	{"view/[[.Path]]/index.html", `<h1>[[.Labels]]</h1>
+<p><a href="/[[.Path]]/new">New [[.Label]]</a></p>
+<table>
+<tr>[[range .Fields]]<th>[[.Label]]</th>[[end]]<th></th></tr>
+{{range .[[.Models]]}}
+<tr>[[range .Fields]]<td>[[if .Date]]{{if not .[[.Name]].IsZero}}{{.[[.Name]].Format "2006-01-02"}}{{end}}[[else]]{{.[[.Name]]}}[[end]]</td>[[end]]<td><a href="/[[.Path]]/{{.ID}}">Show</a> <a href="/[[.Path]]/{{.ID}}/edit">Edit</a></td></tr>
+{{end}}
+</table>
+{{if not .[[.Models]]}}<p>None yet.</p>{{end}}
+`},
+	{"view/[[.Path]]/show.html", `<h1>[[.Label]] {{.[[.Model]].ID}}</h1>
+<dl>
+[[- range .Fields]]
+<dt>[[.Label]]</dt><dd>[[if .Date]]{{if not .[[$.Model]].[[.Name]].IsZero}}{{.[[$.Model]].[[.Name]].Format "2006-01-02"}}{{end}}[[else]]{{.[[$.Model]].[[.Name]]}}[[end]]</dd>
+[[- end]]
+</dl>
+<p><a href="/[[.Path]]/{{.[[.Model]].ID}}/edit">Edit</a> <a href="/[[.Path]]">Back</a></p>
+<form method="post" action="/[[.Path]]/{{.[[.Model]].ID}}/delete">
+{{.CSRFField}}
+<button type="submit">Delete</button>
+</form>
+`},
+	{"view/[[.Path]]/new.html", `<h1>New [[.Label]]</h1>
+<form method="post" action="/[[.Path]]">
+{{template "[[.Path]]/form" .}}
+</form>
+<p><a href="/[[.Path]]">Back</a></p>
+`},
+	{"view/[[.Path]]/edit.html", `<h1>Edit [[.Label]] {{.[[.Model]].ID}}</h1>
+<form method="post" action="/[[.Path]]/{{.[[.Model]].ID}}">
+{{template "[[.Path]]/form" .}}
+</form>
+<p><a href="/[[.Path]]/{{.[[.Model]].ID}}">Back</a></p>
+`},
+}
@@ -0,0 +1,172 @@
+package main
+
+// The files of a new project, as text/templates with [[ ]] delimiters so
+// that the html/template actions of the views pass through.
+var skeleton = []struct {
+	path, text string
+}{
+	{".gitignore", `/[[.Name]]
+/tmp/
+`},
+	{"main.go", `package main
+
+import (
+	"golanger.com/framework/compress"
+	"golanger.com/framework/config"
+	"golanger.com/framework/csrf"
+	"golanger.com/framework/debug"
+	"golanger.com/framework/flash"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
+	"golanger.com/framework/recovery"
+	"golanger.com/framework/router"
+	"golanger.com/framework/session"
+	"golanger.com/framework/static"
+	"golanger.com/framework/validator"
+	"net/http"
+	"os"
+	"time"
+	"[[.Import]]/controllers"
+)
+
+// The settings of config/app.json, which APP_* environment variables
+// override, checked before the server starts.
+type settings struct {
+	Listen  string ` + "`validate:\"required\"`" + `
+	Session struct {
+		MaxAge time.Duration ` + "`validate:\"required\"`" + `
+	}
+}
+
+func main() {
+	cfg := config.New("APP")
+	if err := cfg.Load("config/app.json"); err != nil {
+		log.Fatal(err)
+	}
+
+	s := settings{Listen: ":8080"}
+	s.Session.MaxAge = 24 * time.Hour
+	if err := cfg.Validate(&s); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := i18n.LoadDir("locale"); err != nil {
+		log.Fatal(err)
+	}
+
+	if err := validator.Configure(validator.ConfigFrom(cfg)); err != nil {
+		log.Fatal(err)
+	}
+
+	rt := router.New()
+	rt.Handle("GET", "/static/*path", http.StripPrefix("/static/", static.New("static")))
+	controllers.Routes(rt)
+
+	sessions := session.NewManager(session.NewMemoryStore(), "session", s.Session.MaxAge)
+	handler := middleware.Wrap(rt,
+		log.RequestID(),
+		log.RequestLogger(),
+		recovery.New(recovery.Options{Debug: os.Getenv("GOLANGER_DEV") != ""}),
+		compress.New(compress.Options{}),
+		sessions.Middleware,
+		debug.New(debug.Options{Enabled: os.Getenv("GOLANGER_DEV") != ""}).Middleware,
+		i18n.Middleware,
+		csrf.New(csrf.Options{}).Middleware,
+		flash.Middleware,
+	)
+
+	log.Info("listening on ", s.Listen)
+	log.Fatal(http.ListenAndServe(s.Listen, handler))
+}
+`},
+	{"config/app.json", `{
+  "listen": ":8080",
+  "session": {
+    "max_age": "24h"
+  },
+  "prod": {
+    "listen": ":80"
+  }
+}
+`},
+	{"controllers/routes.go", `package controllers
+
+import (
+	"golanger.com/framework/controller"
+	"golanger.com/framework/render"
+	"golanger.com/framework/router"
+)
+
+// The route registrations of the controllers, added from their init so
+// that generated controllers need no edit here.
+var registrations []func(rt *router.Router)
+
+func register(f func(rt *router.Router)) {
+	registrations = append(registrations, f)
+}
+
+func init() {
+	controller.Renderer = render.New(render.Options{
+		Directory: "view",
+		Layout:    "layout",
+		Partials:  []string{"partials/*.html"},
+	})
+}
+
+// Register the routes of every controller.
+func Routes(rt *router.Router) {
+	for _, f := range registrations {
+		f(rt)
+	}
+}
+`},
+	{"controllers/home.go", `package controllers
+
+import (
+	"golanger.com/framework/controller"
+	"golanger.com/framework/router"
+)
+
+func init() {
+	register(func(rt *router.Router) {
+		rt.Handle("GET", "/", controller.Handler(homeIndex)).Named("home")
+	})
+}
+
+func homeIndex(c *controller.Controller) {
+	c.Render("home/index", map[string]interface{}{
+		"Title": "[[.Name]]",
+	})
+}
+`},
+	{"models/doc.go", `// Package models holds the data of the application and its validation
+// rules, as ` + "`validate`" + ` tags.
+package models
+`},
+	{"view/layout.html", `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{block "title" .}}[[.Name]]{{end}}</title>
+<link rel="stylesheet" href="/static/app.css">
+</head>
+<body>
+{{flashMessages .Flash}}
+{{template "content" .}}
+</body>
+</html>
+`},
+	{"view/home/index.html", `<h1>{{.Title}}</h1>
+<p>{{msg "welcome"}}</p>
+`},
+	{"view/partials/.keep", ``},
+	{"static/app.css", `body { font-family: sans-serif; margin: 2em auto; max-width: 48em; }
+.error { color: #b00; }
+`},
+	{"locale/en.json", `{
+  "welcome": "Your application is running."
+}
+`},
+	{"migrations/.keep", ``},
+}
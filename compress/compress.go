@@ -0,0 +1,398 @@
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"golanger.com/framework/middleware"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Writer compresses what is written to it into an underlying writer.
+// Flush sends what was compressed so far, for streamed responses.
+type Writer interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// An Encoder returns a Writer of a content coding into w at level.  Writers
+// with a Reset(io.Writer) method are pooled.
+type Encoder func(w io.Writer, level int) (Writer, error)
+
+var encoders = struct {
+	sync.RWMutex
+	funcs map[string]Encoder
+	pools map[pool]*sync.Pool
+}{
+	funcs: map[string]Encoder{
+		"gzip": func(w io.Writer, level int) (Writer, error) {
+			return gzip.NewWriterLevel(w, level)
+		},
+		// The "deflate" content coding is the zlib format.
+		"deflate": func(w io.Writer, level int) (Writer, error) {
+			return zlib.NewWriterLevel(w, level)
+		},
+	},
+	pools: map[pool]*sync.Pool{},
+}
+
+// Writers are pooled by encoding and level.
+type pool struct {
+	encoding string
+	level    int
+}
+
+// Add or replace the encoder of a content coding.  The standard library
+// has no brotli encoder; register one to use "br":
+//
+//	compress.RegisterEncoder("br", func(w io.Writer, level int) (compress.Writer, error) {
+//		return brotli.NewWriterLevel(w, level), nil
+//	})
+func RegisterEncoder(name string, f Encoder) {
+	encoders.Lock()
+	encoders.funcs[name] = f
+	for p := range encoders.pools {
+		if p.encoding == name {
+			delete(encoders.pools, p)
+		}
+	}
+	encoders.Unlock()
+}
+
+type Options struct {
+	// The content codings offered, in order of preference, by default
+	// "br", "gzip" and "deflate".  Those without an encoder are skipped.
+	Encodings []string
+
+	// The compression level, as of compress/flate, DefaultCompression by
+	// default.  Encoders of other scales should map it to their own.
+	Level int
+
+	// The smallest body compressed, 1024 bytes by default; shorter ones
+	// gain little and cost the client a decompression.
+	MinSize int
+
+	// The media types compressed, without parameters; "text/*" stands for
+	// every text type.  By default, the text formats of pages, styles,
+	// scripts and data.  Event streams and already compressed formats such
+	// as images should be left out.
+	Types []string
+}
+
+var DefaultTypes = []string{
+	"text/html",
+	"text/css",
+	"text/plain",
+	"text/xml",
+	"text/csv",
+	"text/javascript",
+	"application/javascript",
+	"application/json",
+	"application/xml",
+	"application/wasm",
+	"image/svg+xml",
+}
+
+// Return a middleware compressing the responses of a listed type and of at
+// least MinSize bytes with the content coding the client prefers.
+// Responses that already have a Content-Encoding, such as the precompressed
+// files of the static package, and partial responses are left alone.  A
+// strong ETag of a compressed response is made weak, as the bytes differ
+// from those it was computed on; render.WithCaching must therefore run
+// inside it.  Every response of a listed type varies on Accept-Encoding.
+func New(opts Options) middleware.Middleware {
+	if opts.Encodings == nil {
+		opts.Encodings = []string{"br", "gzip", "deflate"}
+	}
+
+	if opts.Level == 0 {
+		opts.Level = gzip.DefaultCompression
+	}
+
+	if opts.MinSize == 0 {
+		opts.MinSize = 1024
+	}
+
+	if opts.Types == nil {
+		opts.Types = DefaultTypes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cw := &compressWriter{
+				ResponseWriter: w,
+				opts:           &opts,
+				encoding:       negotiate(r.Header.Get("Accept-Encoding"), opts.Encodings),
+			}
+			next.ServeHTTP(cw, r)
+			// Not deferred: after a panic, what was held back is dropped
+			// for the recovery middleware to answer.
+			cw.close()
+		})
+	}
+}
+
+// Return the quality an Accept-Encoding header gives to an encoding, or -1
+// if it names neither it nor "*".
+func quality(header, encoding string) float64 {
+	q := -1.0
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.ToLower(strings.TrimSpace(fields[0]))
+		if name != encoding && (name != "*" || q >= 0) {
+			continue
+		}
+
+		pq := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if f, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					pq = f
+				}
+			}
+		}
+
+		if name == encoding {
+			return pq
+		}
+
+		q = pq
+	}
+
+	return q
+}
+
+// Return the encoding with an encoder that the client accepts with the
+// highest quality, the first of encodings on a tie, or "".
+func negotiate(header string, encodings []string) string {
+	encoders.RLock()
+	defer encoders.RUnlock()
+
+	best, bestQ := "", 0.0
+	for _, enc := range encodings {
+		if _, ok := encoders.funcs[enc]; !ok {
+			continue
+		}
+
+		if q := quality(header, enc); q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+
+	return best
+}
+
+// Return a pooled writer of encoding into w.
+func getWriter(encoding string, w io.Writer, level int) (Writer, error) {
+	encoders.Lock()
+	p, ok := encoders.pools[pool{encoding, level}]
+	if !ok {
+		p = &sync.Pool{}
+		encoders.pools[pool{encoding, level}] = p
+	}
+
+	f := encoders.funcs[encoding]
+	encoders.Unlock()
+
+	if zw, ok := p.Get().(Writer); ok {
+		zw.(interface{ Reset(io.Writer) }).Reset(w)
+		return zw, nil
+	}
+
+	return f(w, level)
+}
+
+func putWriter(encoding string, level int, zw Writer) {
+	if _, ok := zw.(interface{ Reset(io.Writer) }); !ok {
+		return
+	}
+
+	encoders.RLock()
+	p := encoders.pools[pool{encoding, level}]
+	encoders.RUnlock()
+	if p != nil {
+		p.Put(zw)
+	}
+}
+
+// A compressWriter holds back the start of a response until it knows
+// whether to compress it.
+type compressWriter struct {
+	http.ResponseWriter
+	opts     *Options
+	encoding string
+	status   int
+	decided  bool
+	buf      []byte
+	zw       Writer
+}
+
+// Report whether the media type of a Content-Type header is listed.
+func (w *compressWriter) listed(contentType string) bool {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	for _, t := range w.opts.Types {
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(t, "*"))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+
+	w.status = code
+	h := w.Header()
+	if code < 200 || code == http.StatusNoContent || code == http.StatusPartialContent || code == http.StatusNotModified ||
+		h.Get("Content-Encoding") != "" || w.encoding == "" {
+		w.decide(false)
+		return
+	}
+
+	if ct := h.Get("Content-Type"); ct != "" && !w.listed(ct) {
+		w.decide(false)
+		return
+	}
+
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < w.opts.MinSize {
+		w.decide(false)
+	}
+}
+
+// Report whether the Vary header, which the static package may have set,
+// names Accept-Encoding.
+func varies(h http.Header) bool {
+	for _, v := range h["Vary"] {
+		for _, name := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), "Accept-Encoding") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Write the header, compressed or not, and what was held back.
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// Sniff now, as net/http would sniff the compressed bytes.
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+
+	if ct := h.Get("Content-Type"); ct == "" || w.listed(ct) {
+		if !varies(h) {
+			h.Add("Vary", "Accept-Encoding")
+		}
+	} else {
+		compress = false
+	}
+
+	if compress && w.encoding != "" && h.Get("Content-Encoding") == "" {
+		zw, err := getWriter(w.encoding, w.ResponseWriter, w.opts.Level)
+		if err == nil {
+			w.zw = zw
+			h.Set("Content-Encoding", w.encoding)
+			h.Del("Content-Length")
+			if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				h.Set("ETag", "W/"+etag)
+			}
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) > 0 {
+		w.write(w.buf)
+		w.buf = nil
+	}
+}
+
+func (w *compressWriter) write(b []byte) (int, error) {
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.decided {
+		return w.write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= w.opts.MinSize {
+		w.decide(true)
+	}
+
+	return len(b), nil
+}
+
+// Send what was written so far, compressing a streamed response whatever
+// its size.
+func (w *compressWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.decided {
+		w.decide(true)
+	}
+
+	if w.zw != nil {
+		w.zw.Flush()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("compress: ResponseWriter does not support Hijack")
+	}
+
+	// Nothing is written through w after a hijack.
+	w.decided = true
+
+	return h.Hijack()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Send a body too short to compress as is, and finish the compressed one.
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 {
+			return
+		}
+
+		w.decide(false)
+	}
+
+	if w.zw != nil {
+		w.zw.Close()
+		putWriter(w.encoding, w.opts.Level, w.zw)
+		w.zw = nil
+	}
+}
@@ -0,0 +1,158 @@
+package compress
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var page = strings.Repeat("<p>hello, compressed world</p>\n", 100)
+
+// Serve body with the headers of header through the middleware of opts to
+// a request accepting acceptEncoding.
+func serve(opts Options, acceptEncoding string, header http.Header, body string) *httptest.ResponseRecorder {
+	h := New(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+
+		w.Write([]byte(body))
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", acceptEncoding)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func gunzip(t *testing.T, b []byte) string {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestGzip(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		w := serve(Options{}, "gzip, deflate", http.Header{"Content-Type": {"text/html; charset=utf-8"}, "Etag": {`"v1"`}}, page)
+		if w.Header().Get("Content-Encoding") != "gzip" || w.Body.Len() >= len(page) {
+			t.Fatalf("encoding %q, %d bytes", w.Header().Get("Content-Encoding"), w.Body.Len())
+		}
+
+		if got := gunzip(t, w.Body.Bytes()); got != page {
+			t.Errorf("decompressed %d bytes, want the page", len(got))
+		}
+
+		if w.Header().Get("Vary") != "Accept-Encoding" || w.Header().Get("ETag") != `W/"v1"` {
+			t.Errorf("Vary %q, ETag %q", w.Header().Get("Vary"), w.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestDeflate(t *testing.T) {
+	w := serve(Options{}, "deflate", nil, page)
+	if w.Header().Get("Content-Encoding") != "deflate" {
+		t.Fatalf("encoding %q", w.Header().Get("Content-Encoding"))
+	}
+
+	zr, err := zlib.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := io.ReadAll(zr); string(data) != page {
+		t.Error("deflate body is not the page in zlib format")
+	}
+}
+
+func TestLeftUncompressed(t *testing.T) {
+	cases := map[string]struct {
+		accept string
+		header http.Header
+		body   string
+	}{
+		"short":           {"gzip", nil, "<p>short</p>"},
+		"not accepted":    {"br", nil, page},
+		"refused":         {"gzip;q=0, deflate;q=0", nil, page},
+		"image":           {"gzip", http.Header{"Content-Type": {"image/png"}}, page},
+		"encoded":         {"gzip", http.Header{"Content-Encoding": {"gzip"}}, page},
+		"short by length": {"gzip", http.Header{"Content-Length": {"10"}}, page[:10]},
+	}
+
+	for name, c := range cases {
+		w := serve(Options{}, c.accept, c.header, c.body)
+		if w.Body.String() != c.body || (w.Header().Get("Content-Encoding") != "") != (name == "encoded") {
+			t.Errorf("%s: encoding %q, %d bytes", name, w.Header().Get("Content-Encoding"), w.Body.Len())
+		}
+	}
+
+	if w := serve(Options{}, "gzip", http.Header{"Content-Type": {"image/png"}}, page); w.Header().Get("Vary") != "" {
+		t.Errorf("Vary %q on a type never compressed", w.Header().Get("Vary"))
+	}
+
+	if w := serve(Options{}, "", nil, "<p>short</p>"); w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Error("no Vary on a short listed response")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"gzip":                    "gzip",
+		"deflate, gzip":           "gzip",
+		"deflate;q=1, gzip;q=0.5": "deflate",
+		"*":                       "gzip",
+		"*;q=0.1, gzip;q=0":       "deflate",
+		"identity":                "",
+		"":                        "",
+		"br":                      "",
+	}
+
+	for header, want := range cases {
+		if got := negotiate(header, []string{"br", "gzip", "deflate"}); got != want {
+			t.Errorf("negotiate(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	h := New(Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("first "))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("second"))
+	}))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if !w.Flushed || w.Header().Get("Content-Encoding") != "gzip" || gunzip(t, w.Body.Bytes()) != "first second" {
+		t.Errorf("flushed %v, encoding %q", w.Flushed, w.Header().Get("Content-Encoding"))
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("x-test", func(w io.Writer, level int) (Writer, error) {
+		return gzip.NewWriterLevel(w, level)
+	})
+
+	w := serve(Options{Encodings: []string{"x-test"}}, "x-test", nil, page)
+	if w.Header().Get("Content-Encoding") != "x-test" || gunzip(t, w.Body.Bytes()) != page {
+		t.Errorf("encoding %q", w.Header().Get("Content-Encoding"))
+	}
+}
@@ -0,0 +1,297 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A Config holds settings under dotted lower-case keys such as "db.host".
+// A value is looked up, in order, in the environment variable named from
+// the prefix and key (APP_DB_HOST), in the section of the current
+// environment ("prod.db.host"), and in the loaded files.  It is safe for
+// concurrent use.
+type Config struct {
+	mutex  sync.RWMutex
+	prefix string
+	env    string
+	values map[string]interface{}
+}
+
+// Return a Config whose environment overrides start with prefix + "_".
+// The environment is taken from <prefix>_ENV.
+func New(prefix string) *Config {
+	c := &Config{
+		prefix: strings.ToUpper(prefix),
+		values: map[string]interface{}{},
+	}
+
+	if prefix != "" {
+		c.env = os.Getenv(c.prefix + "_ENV")
+	}
+
+	return c
+}
+
+// Select the environment section, such as "dev", "test" or "prod", that
+// overrides the top-level settings.
+func (c *Config) SetEnvironment(env string) {
+	c.mutex.Lock()
+	c.env = env
+	c.mutex.Unlock()
+}
+
+func (c *Config) Environment() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.env
+}
+
+// Load JSON (.json), YAML (.yaml, .yml) or INI (.ini, .conf) files, each
+// overriding the settings of the ones before.
+func (c *Config) Load(paths ...string) error {
+	for _, path := range paths {
+		var values map[string]interface{}
+		var err error
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json":
+			values, err = parseJSONFile(path)
+		case ".yaml", ".yml":
+			values, err = parseYAMLFile(path)
+		case ".ini", ".conf":
+			values, err = parseINIFile(path)
+		default:
+			err = fmt.Errorf("config: unknown format of %s", path)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		c.mutex.Lock()
+		flatten("", values, c.values)
+		c.mutex.Unlock()
+	}
+
+	return nil
+}
+
+func (c *Config) Set(key string, value interface{}) {
+	c.mutex.Lock()
+	c.values[strings.ToLower(key)] = value
+	c.mutex.Unlock()
+}
+
+func flatten(prefix string, values map[string]interface{}, dst map[string]interface{}) {
+	for k, v := range values {
+		key := strings.ToLower(k)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		if m, ok := v.(map[string]interface{}); ok {
+			flatten(key, m, dst)
+		} else {
+			dst[key] = v
+		}
+	}
+}
+
+func (c *Config) envName(key string) string {
+	return c.prefix + "_" + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Return the raw value of key and whether it is set.
+func (c *Config) Lookup(key string) (interface{}, bool) {
+	key = strings.ToLower(key)
+	if c.prefix != "" {
+		if value, ok := os.LookupEnv(c.envName(key)); ok {
+			return value, true
+		}
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.env != "" {
+		if value, ok := c.values[c.env+"."+key]; ok {
+			return value, true
+		}
+	}
+
+	value, ok := c.values[key]
+
+	return value, ok
+}
+
+func (c *Config) String(key, def string) string {
+	value, ok := c.Lookup(key)
+	if !ok || value == nil {
+		return def
+	}
+
+	return fmt.Sprint(value)
+}
+
+func (c *Config) Int(key string, def int) int {
+	return int(c.Int64(key, int64(def)))
+}
+
+func (c *Config) Int64(key string, def int64) int64 {
+	value, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+
+	switch n := value.(type) {
+	case float64:
+		return int64(n)
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	}
+
+	n, err := strconv.ParseInt(strings.TrimSpace(fmt.Sprint(value)), 10, 64)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
+
+func (c *Config) Float(key string, def float64) float64 {
+	value, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+
+	if n, ok := value.(float64); ok {
+		return n
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(fmt.Sprint(value)), 64)
+	if err != nil {
+		return def
+	}
+
+	return n
+}
+
+func (c *Config) Bool(key string, def bool) bool {
+	value, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+
+	if b, ok := value.(bool); ok {
+		return b
+	}
+
+	switch strings.ToLower(strings.TrimSpace(fmt.Sprint(value))) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	}
+
+	return def
+}
+
+// Return a duration such as "1h30m".  A bare number is taken as seconds.
+func (c *Config) Duration(key string, def time.Duration) time.Duration {
+	value, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+
+	s := strings.TrimSpace(fmt.Sprint(value))
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return time.Duration(n * float64(time.Second))
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return def
+	}
+
+	return d
+}
+
+// Return a list, given either as a list or a comma-separated string.
+func (c *Config) Strings(key string, def []string) []string {
+	value, ok := c.Lookup(key)
+	if !ok {
+		return def
+	}
+
+	if list, ok := value.([]interface{}); ok {
+		strs := make([]string, len(list))
+		for i, v := range list {
+			strs[i] = fmt.Sprint(v)
+		}
+
+		return strs
+	}
+
+	strs := []string{}
+	for _, s := range strings.Split(fmt.Sprint(value), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			strs = append(strs, s)
+		}
+	}
+
+	return strs
+}
+
+// The Config used by the package-level functions, with the APP prefix.
+var Default = New("APP")
+
+func Load(paths ...string) error {
+	return Default.Load(paths...)
+}
+
+func SetEnvironment(env string) {
+	Default.SetEnvironment(env)
+}
+
+func Set(key string, value interface{}) {
+	Default.Set(key, value)
+}
+
+func Lookup(key string) (interface{}, bool) {
+	return Default.Lookup(key)
+}
+
+func String(key, def string) string {
+	return Default.String(key, def)
+}
+
+func Int(key string, def int) int {
+	return Default.Int(key, def)
+}
+
+func Int64(key string, def int64) int64 {
+	return Default.Int64(key, def)
+}
+
+func Float(key string, def float64) float64 {
+	return Default.Float(key, def)
+}
+
+func Bool(key string, def bool) bool {
+	return Default.Bool(key, def)
+}
+
+func Duration(key string, def time.Duration) time.Duration {
+	return Default.Duration(key, def)
+}
+
+func Strings(key string, def []string) []string {
+	return Default.Strings(key, def)
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Write the files of name to contents in a temporary directory and return
+// their paths, in the order given.
+func writeFiles(t *testing.T, files ...string) []string {
+	dir := t.TempDir()
+	paths := []string{}
+	for i := 0; i < len(files); i += 2 {
+		path := filepath.Join(dir, files[i])
+		if err := os.WriteFile(path, []byte(files[i+1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		paths = append(paths, path)
+	}
+
+	return paths
+}
+
+func TestLoadFormats(t *testing.T) {
+	paths := writeFiles(t,
+		"app.json", `{"db": {"host": "localhost", "port": 5432}, "debug": true}`,
+		"app.yaml", `
+# overrides
+db:
+  host: "db.internal"   # a comment after quotes
+  motto: "a # b" # cut
+  quip: 'it''s # x'
+  pool: 10
+hosts:
+  - a.example
+  - b.example
+tags: [x, y]
+timeout: 1m30s
+`,
+		"app.ini", `
+; ini comments
+[cache]
+ttl = 300
+name: 'it''s'
+`)
+
+	c := New("")
+	if err := c.Load(paths...); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.String("db.host", ""); got != "db.internal" {
+		t.Errorf("db.host = %q, want the YAML value over the JSON one", got)
+	}
+
+	if got := c.String("db.motto", ""); got != "a # b" {
+		t.Errorf("db.motto = %q", got)
+	}
+
+	if got := c.String("db.quip", ""); got != "it's # x" {
+		t.Errorf("db.quip = %q", got)
+	}
+
+	if c.Int("db.port", 0) != 5432 || c.Int("db.pool", 0) != 10 || !c.Bool("debug", false) {
+		t.Errorf("db.port %d, db.pool %d, debug %v", c.Int("db.port", 0), c.Int("db.pool", 0), c.Bool("debug", false))
+	}
+
+	if got := c.Strings("hosts", nil); !reflect.DeepEqual(got, []string{"a.example", "b.example"}) {
+		t.Errorf("hosts = %v", got)
+	}
+
+	if got := c.Strings("tags", nil); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("tags = %v", got)
+	}
+
+	if c.Duration("timeout", 0) != 90*time.Second || c.Duration("cache.ttl", 0) != 300*time.Second {
+		t.Errorf("timeout %v, cache.ttl %v", c.Duration("timeout", 0), c.Duration("cache.ttl", 0))
+	}
+
+	if got := c.String("cache.name", ""); got != "it's" {
+		t.Errorf("cache.name = %q", got)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	paths := writeFiles(t,
+		"bad.json", `{"db": `,
+		"bad.yaml", "db\n",
+		"bad.ini", "[db]\nhost\n",
+		"app.toml", "",
+	)
+
+	for _, path := range append(paths, filepath.Join(t.TempDir(), "missing.json")) {
+		if err := New("").Load(path); err == nil {
+			t.Errorf("no error loading %s", filepath.Base(path))
+		}
+	}
+}
+
+func TestEnvironmentOverrides(t *testing.T) {
+	t.Setenv("TESTCFG_ENV", "prod")
+	t.Setenv("TESTCFG_DB_MAX_CONNS", "50")
+
+	c := New("testcfg")
+	c.Set("db.host", "localhost")
+	c.Set("prod.db.host", "db.prod")
+	c.Set("db.max_conns", 5)
+	c.Set("db.user", "app")
+
+	if c.Environment() != "prod" || c.String("db.host", "") != "db.prod" {
+		t.Errorf("environment %q, db.host %q", c.Environment(), c.String("db.host", ""))
+	}
+
+	if got := c.Int("db.max_conns", 0); got != 50 {
+		t.Errorf("db.max_conns = %d, want the environment variable", got)
+	}
+
+	if got := c.String("DB.User", ""); got != "app" {
+		t.Errorf("db.user = %q, keys are not case-insensitive", got)
+	}
+
+	c.SetEnvironment("dev")
+	if got := c.String("db.host", ""); got != "localhost" {
+		t.Errorf("db.host = %q in dev", got)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	c := New("")
+	c.Set("port", "eighty")
+	c.Set("ratio", "x")
+	c.Set("on", "maybe")
+	c.Set("ttl", "soon")
+
+	if c.Int("port", 80) != 80 || c.Float("ratio", 0.5) != 0.5 || !c.Bool("on", true) ||
+		c.Duration("ttl", time.Minute) != time.Minute || c.String("missing", "def") != "def" ||
+		c.Strings("missing", nil) != nil {
+		t.Error("invalid or missing values not defaulted")
+	}
+}
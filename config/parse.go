@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func parseJSONFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("config: %s: %v", path, err)
+	}
+
+	return values, nil
+}
+
+// Parse "key = value" lines grouped under "[section]" headers, which become
+// the first part of the keys.  Lines starting with ";" or "#" are comments.
+func parseINIFile(path string) (map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := map[string]interface{}{}
+	section := ""
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return nil, fmt.Errorf("config: %s:%d: expected key = value", path, n)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		if section != "" {
+			key = section + "." + key
+		}
+
+		values[key] = unquote(strings.TrimSpace(line[i+1:]))
+	}
+
+	return values, scanner.Err()
+}
+
+type yamlFrame struct {
+	indent int
+	values map[string]interface{}
+	parent map[string]interface{}
+	key    string
+}
+
+// Parse the subset of YAML used for settings: nested mappings by
+// indentation, scalars, "[a, b]" flow lists and "- item" lists of scalars.
+func parseYAMLFile(path string) (map[string]interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	root := map[string]interface{}{}
+	stack := []*yamlFrame{{indent: -1, values: root}}
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		if text == "" || text[0] == '#' || text == "---" {
+			continue
+		}
+
+		indent := len(raw) - len(text)
+		if text == "-" || strings.HasPrefix(text, "- ") {
+			for len(stack) > 1 && stack[len(stack)-1].indent > indent {
+				stack = stack[:len(stack)-1]
+			}
+
+			top := stack[len(stack)-1]
+			if top.parent == nil {
+				return nil, fmt.Errorf("config: %s:%d: list item outside of a key", path, n)
+			}
+
+			list, _ := top.parent[top.key].([]interface{})
+			top.parent[top.key] = append(list, yamlScalar(text[1:]))
+
+			continue
+		}
+
+		for stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		i := strings.Index(text, ":")
+		if i < 0 || (i+1 < len(text) && text[i+1] != ' ') {
+			return nil, fmt.Errorf("config: %s:%d: expected key: value", path, n)
+		}
+
+		key := unquote(strings.TrimSpace(text[:i]))
+		value := strings.TrimSpace(text[i+1:])
+		top := stack[len(stack)-1]
+		if value == "" || value[0] == '#' {
+			child := map[string]interface{}{}
+			top.values[key] = child
+			stack = append(stack, &yamlFrame{indent: indent, values: child, parent: top.values, key: key})
+		} else {
+			top.values[key] = yamlScalar(value)
+		}
+	}
+
+	return root, scanner.Err()
+}
+
+func yamlScalar(s string) interface{} {
+	s = strings.TrimSpace(s)
+	if s != "" {
+		from := 0
+		if s[0] == '"' || s[0] == '\'' {
+			from = closingQuote(s)
+		}
+
+		if i := strings.Index(s[from:], " #"); i >= 0 {
+			s = strings.TrimSpace(s[:from+i])
+		}
+	}
+
+	switch s {
+	case "", "~", "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if s[0] == '[' && s[len(s)-1] == ']' {
+		list := []interface{}{}
+		for _, item := range strings.Split(s[1:len(s)-1], ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, yamlScalar(item))
+			}
+		}
+
+		return list
+	}
+
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n
+	}
+
+	return unquote(s)
+}
+
+// Return the index after the quote closing the one s starts with, or
+// len(s) if it is not closed, so that a comment after it can be cut.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[0] == '"' && s[i] == '\\':
+			i++
+		case s[i] == s[0] && s[0] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == s[0]:
+			return i + 1
+		}
+	}
+
+	return len(s)
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return strings.Replace(s[1:len(s)-1], "''", "'", -1)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,339 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A setting that cannot be used: its key, the environment variable that
+// overrides it, if any, and why.
+type Problem struct {
+	Key     string
+	Env     string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Env != "" {
+		return p.Key + " (" + p.Env + "): " + p.Message
+	}
+
+	return p.Key + ": " + p.Message
+}
+
+// The error of Validate, listing every setting that cannot be used.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{"config: invalid settings:"}
+	for _, p := range e.Problems {
+		lines = append(lines, "  "+p.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// A failed check of a struct field, keyed by its path as in
+// "Session.Secret".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// The function Validate checks structs with, which the validator package
+// sets when it is imported.  The config package cannot import it, as it
+// reads its own settings from a Config.
+var structChecker func(obj interface{}) []FieldError
+
+// Set the check of the `validate` tags of Validate.
+func SetStructChecker(check func(obj interface{}) []FieldError) {
+	structChecker = check
+}
+
+// A field of a settings struct and the key it is read from.
+type setting struct {
+	path, key string
+}
+
+// Fill the struct dst points to from c, then check it by the `validate`
+// tags of its fields, so that an application can refuse to start with
+// bad settings instead of failing on them later.  Every setting that
+// cannot be read or is invalid is reported at once, in a
+// *ValidationError naming its environment variable, e.g.
+//
+//	type Settings struct {
+//		Listen  string         `config:"listen" validate:"required"`
+//		Session struct {
+//			Secret string        `validate:"required,min=32"`
+//			MaxAge time.Duration `config:"max_age"`
+//		}
+//	}
+//
+// reports "session.secret (APP_SESSION_SECRET): Minimum size is 32".  The
+// validator package must be imported, as it does the checks.
+func (c *Config) Validate(dst interface{}) error {
+	if structChecker == nil {
+		return errors.New("config: Validate needs the golanger.com/framework/validator package")
+	}
+
+	settings, problems, err := c.decode(dst)
+	if err != nil {
+		return err
+	}
+
+	failed := map[string]bool{}
+	for _, p := range problems {
+		failed[p.Key] = true
+	}
+
+	index := map[string]int{}
+	for i, s := range settings {
+		index[s.key] = i
+	}
+
+	for _, fe := range structChecker(dst) {
+		p := c.problem(settings, fe.Field, fe.Message)
+		if !failed[p.Key] {
+			problems = append(problems, p)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	position := func(p Problem) int {
+		key := p.Key
+		if i := strings.Index(key, "["); i != -1 {
+			key = key[:i]
+		}
+
+		if i, ok := index[key]; ok {
+			return i
+		}
+
+		return len(settings)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return position(problems[i]) < position(problems[j]) })
+
+	return &ValidationError{Problems: problems}
+}
+
+// Return the problem of the field at path, as "Hosts[1]", by the key of
+// its setting.
+func (c *Config) problem(settings []setting, path, message string) Problem {
+	base, suffix := path, ""
+	if i := strings.Index(path, "["); i != -1 {
+		base, suffix = path[:i], path[i:]
+	}
+
+	key := base
+	for _, s := range settings {
+		if s.path == base {
+			key = s.key
+			break
+		}
+	}
+
+	return Problem{Key: key + suffix, Env: c.envVar(key), Message: message}
+}
+
+// Return the environment variable overriding key, if c has a prefix.
+func (c *Config) envVar(key string) string {
+	if c.prefix == "" {
+		return ""
+	}
+
+	return c.envName(key)
+}
+
+// Fill the struct dst points to from c, leaving the fields whose keys are
+// not set as they are.  A field is read from the key of its `config`
+// tag, or else its name in snake case (MaxAge from "max_age"), under the
+// key of the struct it is in; embedded structs share the keys of the
+// outer one, and a tag of "-" skips a field.  Strings, booleans, numbers,
+// durations and lists of strings are read, converted as String, Bool,
+// Duration and so on do, except that a value that cannot be converted is
+// an error.
+func (c *Config) Decode(dst interface{}) error {
+	_, problems, err := c.decode(dst)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+
+	return nil
+}
+
+func (c *Config) decode(dst interface{}) ([]setting, []Problem, error) {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, nil, errors.New("config: needs a pointer to a struct")
+	}
+
+	settings := []setting{}
+	problems := []Problem{}
+	c.decodeStruct(rv.Elem(), "", "", &settings, &problems)
+
+	return settings, problems, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func (c *Config) decodeStruct(rv reflect.Value, path, prefix string, settings *[]setting, problems *[]Problem) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("config")
+		if tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+
+		f := rv.Field(i)
+		if sf.Anonymous && f.Kind() == reflect.Struct {
+			c.decodeStruct(f, path, prefix, settings, problems)
+			continue
+		}
+
+		key := tag
+		if key == "" {
+			key = snakeCase(sf.Name)
+		}
+
+		key = prefix + strings.ToLower(key)
+		if f.Kind() == reflect.Struct && f.Type() != reflect.TypeOf(time.Time{}) {
+			c.decodeStruct(f, path+sf.Name+".", key+".", settings, problems)
+			continue
+		}
+
+		*settings = append(*settings, setting{path + sf.Name, key})
+		value, ok := c.Lookup(key)
+		if !ok || value == nil {
+			continue
+		}
+
+		if msg := setField(f, value); msg != "" {
+			*problems = append(*problems, Problem{Key: key, Env: c.envVar(key), Message: msg})
+		}
+	}
+}
+
+// Set f to value, or return why it cannot be.
+func setField(f reflect.Value, value interface{}) string {
+	s := strings.TrimSpace(fmt.Sprint(value))
+	switch {
+	case f.Type() == durationType:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			f.SetInt(int64(n * float64(time.Second)))
+			return ""
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return "Must be a duration such as 1h30m"
+		}
+
+		f.SetInt(int64(d))
+	case f.Kind() == reflect.String:
+		f.SetString(fmt.Sprint(value))
+	case f.Kind() == reflect.Bool:
+		switch strings.ToLower(s) {
+		case "1", "true", "yes", "on":
+			f.SetBool(true)
+		case "0", "false", "no", "off":
+			f.SetBool(false)
+		default:
+			return "Must be true or false"
+		}
+	case f.Kind() >= reflect.Int && f.Kind() <= reflect.Int64:
+		if n, ok := value.(float64); ok && n == float64(int64(n)) {
+			s = strconv.FormatInt(int64(n), 10)
+		}
+
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return "Must be an integer"
+		}
+
+		f.SetInt(n)
+	case f.Kind() >= reflect.Uint && f.Kind() <= reflect.Uint64:
+		if n, ok := value.(float64); ok && n >= 0 && n == float64(uint64(n)) {
+			s = strconv.FormatUint(uint64(n), 10)
+		}
+
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return "Must be a positive integer"
+		}
+
+		f.SetUint(n)
+	case f.Kind() == reflect.Float32 || f.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(s, f.Type().Bits())
+		if err != nil {
+			return "Must be a number"
+		}
+
+		f.SetFloat(n)
+	case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.String:
+		strs := []string{}
+		if list, ok := value.([]interface{}); ok {
+			for _, v := range list {
+				strs = append(strs, fmt.Sprint(v))
+			}
+		} else {
+			for _, s := range strings.Split(fmt.Sprint(value), ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					strs = append(strs, s)
+				}
+			}
+		}
+
+		list := reflect.MakeSlice(f.Type(), len(strs), len(strs))
+		for i, s := range strs {
+			list.Index(i).SetString(s)
+		}
+
+		f.Set(list)
+	default:
+		return "Cannot be read into a " + f.Type().String()
+	}
+
+	return ""
+}
+
+// Return a field name in snake case: MaxAge as max_age and TLSCert as
+// tls_cert.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			b.WriteByte('_')
+		}
+
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return b.String()
+}
+
+func Validate(dst interface{}) error {
+	return Default.Validate(dst)
+}
+
+func Decode(dst interface{}) error {
+	return Default.Decode(dst)
+}
@@ -0,0 +1,150 @@
+package config_test
+
+import (
+	"golanger.com/framework/config"
+	_ "golanger.com/framework/validator"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Server struct {
+	Port int
+}
+
+type settings struct {
+	Server
+	Listen  string `config:"listen" validate:"required"`
+	Debug   bool
+	Ratio   float64
+	Hosts   []string `validate:"dive,min=3"`
+	Secret  string   `config:"-"`
+	Session struct {
+		Secret string        `validate:"required,min=32"`
+		MaxAge time.Duration `config:"max_age"`
+	}
+}
+
+func TestDecode(t *testing.T) {
+	c := config.New("")
+	c.Set("port", 8080.0)
+	c.Set("listen", ":80")
+	c.Set("debug", "yes")
+	c.Set("ratio", "0.5")
+	c.Set("hosts", []interface{}{"a.example", "b.example"})
+	c.Set("secret", "not read")
+	c.Set("session.secret", "s3cr3t")
+	c.Set("session.max_age", "90")
+
+	s := settings{Listen: "unchanged"}
+	if err := c.Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Port != 8080 || s.Listen != ":80" || !s.Debug || s.Ratio != 0.5 || s.Secret != "" ||
+		!reflect.DeepEqual(s.Hosts, []string{"a.example", "b.example"}) ||
+		s.Session.Secret != "s3cr3t" || s.Session.MaxAge != 90*time.Second {
+		t.Errorf("decoded %+v", s)
+	}
+}
+
+func TestDecodeKeepsUnset(t *testing.T) {
+	s := settings{Listen: ":8080"}
+	s.Session.MaxAge = time.Hour
+	if err := config.New("").Decode(&s); err != nil || s.Listen != ":8080" || s.Session.MaxAge != time.Hour {
+		t.Errorf("decoded %+v, %v", s, err)
+	}
+
+	if err := config.New("").Decode(s); err == nil {
+		t.Error("no error decoding into a struct value")
+	}
+}
+
+func TestDecodeProblems(t *testing.T) {
+	t.Setenv("TESTAPP_SESSION_MAX_AGE", "soon")
+	c := config.New("testapp")
+	c.Set("port", "eighty")
+	c.Set("debug", "maybe")
+
+	var s settings
+	err, ok := c.Decode(&s).(*config.ValidationError)
+	if !ok {
+		t.Fatalf("error %v, want a *ValidationError", err)
+	}
+
+	want := map[string]config.Problem{
+		"port":            {Key: "port", Env: "TESTAPP_PORT", Message: "Must be an integer"},
+		"debug":           {Key: "debug", Env: "TESTAPP_DEBUG", Message: "Must be true or false"},
+		"session.max_age": {Key: "session.max_age", Env: "TESTAPP_SESSION_MAX_AGE", Message: "Must be a duration such as 1h30m"},
+	}
+
+	if len(err.Problems) != len(want) {
+		t.Fatalf("problems %v", err.Problems)
+	}
+
+	for _, p := range err.Problems {
+		if want[p.Key] != p {
+			t.Errorf("problem %+v, want %+v", p, want[p.Key])
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := config.New("testapp")
+	c.Set("port", "x")
+	c.Set("hosts", "ok.example, no")
+	c.Set("session.secret", "short")
+
+	var s settings
+	err, ok := c.Validate(&s).(*config.ValidationError)
+	if !ok {
+		t.Fatalf("error %v, want a *ValidationError", err)
+	}
+
+	got := []string{}
+	for _, p := range err.Problems {
+		got = append(got, p.Key+" "+p.Env)
+	}
+
+	want := []string{
+		"port TESTAPP_PORT",
+		"listen TESTAPP_LISTEN",
+		"hosts[1] TESTAPP_HOSTS",
+		"session.secret TESTAPP_SESSION_SECRET",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("problems %v, want %v in field order", got, want)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "session.secret (TESTAPP_SESSION_SECRET): Minimum size is 32") {
+		t.Errorf("message %q", msg)
+	}
+}
+
+func TestValidateOk(t *testing.T) {
+	c := config.New("")
+	c.Set("listen", ":80")
+	c.Set("session.secret", strings.Repeat("k", 32))
+
+	var s settings
+	if err := c.Validate(&s); err != nil {
+		t.Errorf("error %v", err)
+	}
+}
+
+func TestSnakeCaseKeys(t *testing.T) {
+	c := config.New("")
+	c.Set("tls_cert", "cert.pem")
+	c.Set("max_open_conns", "7")
+
+	var s struct {
+		TLSCert      string
+		MaxOpenConns int
+	}
+
+	if err := c.Decode(&s); err != nil || s.TLSCert != "cert.pem" || s.MaxOpenConns != 7 {
+		t.Errorf("decoded %+v, %v", s, err)
+	}
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"encoding/json"
+	"golanger.com/framework/auth"
+	"golanger.com/framework/csrf"
+	"golanger.com/framework/debug"
+	"golanger.com/framework/flash"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/render"
+	"golanger.com/framework/router"
+	"golanger.com/framework/session"
+	"golanger.com/framework/validator"
+	"net/http"
+)
+
+// The Render of controllers that do not set their own.
+var Renderer = render.New(render.Options{})
+
+// A Controller carries the state of one request: its session (nil unless
+// the session middleware ran), its flash, its locale (see i18n.Locale),
+// and a Validation in that locale holding the errors kept by the previous
+// request.  The Validation is attached to Request, see
+// validator.FromRequest.
+type Controller struct {
+	Request    *http.Request
+	Response   http.ResponseWriter
+	Session    *session.Session
+	Validation *validator.Validation
+	Flash      *flash.Flash
+	Locale     string
+	Renderer   *render.Render
+}
+
+// Return the Controller of a request, with the flash of flash.Middleware
+// or else one loaded for it.  It must be created before anything is
+// written to w, as it may expire the flash cookie.
+func New(w http.ResponseWriter, r *http.Request) *Controller {
+	f := flash.FromRequest(r)
+	if f == nil {
+		f = flash.Load(w, r)
+	}
+
+	locale := i18n.Locale(r)
+	c := &Controller{
+		Request:    r,
+		Response:   w,
+		Session:    session.FromRequest(r),
+		Validation: (&validator.Validation{}).SetLocale(locale),
+		Flash:      f,
+		Locale:     locale,
+		Renderer:   Renderer,
+	}
+
+	c.Validation.RestoreFromFlash(f)
+	c.Request = validator.WithValidation(r, c.Validation)
+	debug.TrackValidation(r.Context(), c.Validation)
+
+	return c
+}
+
+// Return a handler calling f with the Controller of each request.
+func Handler(f func(c *Controller)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f(New(w, r))
+	})
+}
+
+// Store the flash, with the kept errors, for the next request before the
+// response headers are written.
+func (c *Controller) save() {
+	c.Validation.SaveToFlash(c.Flash)
+	c.Flash.Save(c.Response, c.Request)
+}
+
+// Return a parameter of the route, see router.Param.
+func (c *Controller) Param(name string) string {
+	return router.Param(c.Request, name)
+}
+
+// Translate key into the locale of the request, see i18n.T.
+func (c *Controller) T(key string, args ...interface{}) string {
+	return i18n.T(c.Locale, key, args...)
+}
+
+// Render the named page in the locale of the request with data, plus
+// "Validation", "Flash", "CurrentUser" (see auth.CurrentUser) and the CSRF
+// token and field (see csrf.TemplateData).  On failure the error is logged
+// and a 500 sent.
+func (c *Controller) Render(name string, data map[string]interface{}) error {
+	data = csrf.TemplateData(c.Request, data)
+	data["Validation"] = c.Validation
+	data["Flash"] = c.Flash
+	data["CurrentUser"] = auth.CurrentUser(c.Request)
+	c.save()
+
+	done := debug.Start(c.Request.Context(), "render")
+	err := c.Renderer.HTMLLocale(c.Response, http.StatusOK, name, c.Locale, data)
+	done()
+	if err != nil {
+		log.FromRequest(c.Request).Error("<Controller.Render> ", name, ": ", err)
+		http.Error(c.Response, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+
+	return err
+}
+
+// Write v as a JSON response.
+func (c *Controller) RenderJSON(status int, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		log.FromRequest(c.Request).Error("<Controller.RenderJSON> ", err)
+		http.Error(c.Response, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return err
+	}
+
+	c.save()
+	c.Response.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.Response.WriteHeader(status)
+	_, err = c.Response.Write(b)
+
+	return err
+}
+
+// Write v in the format the client accepts, see render.Negotiate.
+func (c *Controller) Respond(status int, v interface{}) error {
+	c.save()
+	err := render.NegotiateStatus(c.Response, c.Request, status, v)
+	if err != nil {
+		log.FromRequest(c.Request).Error("<Controller.Respond> ", err)
+		http.Error(c.Response, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+
+	return err
+}
+
+// Redirect to url with 303 See Other, carrying the flash and, if Keep was
+// called, the validation errors to it.
+func (c *Controller) Redirect(url string) {
+	c.save()
+	http.Redirect(c.Response, c.Request, url, http.StatusSeeOther)
+}
@@ -0,0 +1,83 @@
+package controller
+
+import (
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/render"
+	"golanger.com/framework/router"
+	"golanger.com/framework/testing/webtest"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+// Serve a form at /posts/:id/edit that a POST to /posts/:id validates,
+// redirecting back with the errors kept.
+func app(t *testing.T) http.Handler {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "edit.html"), []byte(
+		`{{.ID}} {{range .Flash.Messages}}[{{.Text}}]{{end}}{{with .Validation.ErrorMap.Title}}{{.Message}}{{end}} {{msg "controller.test.save"}} {{if .CurrentUser}}user{{else}}anonymous{{end}}`), 0644)
+	os.WriteFile(filepath.Join(dir, "broken.html"), []byte(`{{template "missing"}}`), 0644)
+	i18n.Default.SetMessages("pt", map[string]string{"controller.test.save": "Salvar"})
+
+	rt := router.New()
+	rt.Handle("GET", "/posts/:id/edit", Handler(func(c *Controller) {
+		c.Renderer = render.New(render.Options{Directory: dir})
+		c.Render("edit", map[string]interface{}{"ID": c.Param("id")})
+	}))
+	rt.Handle("POST", "/posts/:id", Handler(func(c *Controller) {
+		c.Validation.Required(c.Request.FormValue("title")).Key("Title")
+		if c.Validation.HasErrors() {
+			c.Validation.Keep()
+			c.Flash.Error("Please fix the errors")
+			c.Redirect("/posts/" + c.Param("id") + "/edit")
+			return
+		}
+
+		c.RenderJSON(http.StatusCreated, map[string]string{"saved": c.T("controller.test.save")})
+	}))
+	rt.Handle("GET", "/broken", Handler(func(c *Controller) {
+		c.Renderer = render.New(render.Options{Directory: dir})
+		c.Render("broken", map[string]interface{}{})
+	}))
+	rt.Handle("GET", "/respond", Handler(func(c *Controller) {
+		c.Respond(http.StatusAccepted, map[string]string{"locale": c.Locale})
+	}))
+
+	return i18n.Middleware(rt)
+}
+
+func TestKeepAcrossRedirect(t *testing.T) {
+	c := webtest.New(t, app(t))
+	c.PostForm("/posts/7?lang=pt", nil).AssertRedirect("/posts/7/edit")
+	c.Get("/posts/7/edit").AssertStatus(http.StatusOK).
+		AssertContains("7 [Please fix the errors]Required").
+		AssertContains(" Salvar anonymous")
+
+	// The errors and the flash are shown once.
+	c.Get("/posts/7/edit").AssertNotContains("Please fix")
+
+	var saved map[string]string
+	c.PostForm("/posts/7", map[string][]string{"title": {"Hello"}}).AssertStatus(http.StatusCreated).DecodeJSON(&saved)
+	if saved["saved"] != "Salvar" {
+		t.Errorf("saved %v", saved)
+	}
+}
+
+func TestRenderFails(t *testing.T) {
+	webtest.New(t, app(t)).Get("/broken").AssertStatus(http.StatusInternalServerError)
+}
+
+func TestRespond(t *testing.T) {
+	c := webtest.New(t, app(t))
+	var got map[string]string
+	c.Get("/respond").AssertStatus(http.StatusAccepted).DecodeJSON(&got)
+	if got["locale"] != i18n.Default.DefaultLocale {
+		t.Errorf("responded %v", got)
+	}
+}
@@ -0,0 +1,314 @@
+package cookie
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoKeys  = errors.New("cookie: no keys configured")
+	ErrInvalid = errors.New("cookie: invalid or tampered value")
+	ErrExpired = errors.New("cookie: value expired")
+)
+
+// The keys of a Codec.  New values are signed or encrypted with the first
+// key of each list and values made with any of them are accepted, so a
+// new key can be put first and the old one removed once its cookies have
+// expired.
+type Keys struct {
+	// HMAC-SHA256 keys of signed cookies, best 32 random bytes or more.
+	Hash [][]byte
+	// AES keys of encrypted cookies, 16, 24 or 32 bytes.
+	Block [][]byte
+}
+
+// A Codec signs and encrypts cookie values.  Signed values are readable
+// by the client but cannot be altered; encrypted ones can be neither read
+// nor altered.  Both are bound to the cookie name, so a value cannot be
+// moved to another cookie.
+type Codec struct {
+	hashKeys [][]byte
+	aeads    []cipher.AEAD
+
+	// Reject values made longer ago than MaxAge, if set, whatever the
+	// expiry the browser was given.
+	MaxAge time.Duration
+}
+
+// Return a Codec of keys.  It panics if a block key is not a valid AES key.
+func New(keys Keys) *Codec {
+	c := &Codec{hashKeys: keys.Hash}
+	for _, key := range keys.Block {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			panic("cookie: " + err.Error())
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic("cookie: " + err.Error())
+		}
+
+		c.aeads = append(c.aeads, aead)
+	}
+
+	return c
+}
+
+// The Codec of the package-level functions, without keys until Configure
+// is called.
+var Default = New(Keys{})
+
+func Configure(keys Keys) {
+	Default = New(keys)
+}
+
+// Report whether the Codec has keys to sign with.
+func (c *Codec) CanSign() bool {
+	return len(c.hashKeys) > 0
+}
+
+// Report whether the Codec has keys to encrypt with.
+func (c *Codec) CanEncrypt() bool {
+	return len(c.aeads) > 0
+}
+
+func (c *Codec) mac(key []byte, name, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name + "|" + payload))
+
+	return mac.Sum(nil)
+}
+
+func (c *Codec) checkAge(ts int64) error {
+	if c.MaxAge > 0 && time.Since(time.Unix(ts, 0)) > c.MaxAge {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// Return the signed form of value for the cookie name.
+func (c *Codec) Sign(name, value string) (string, error) {
+	if !c.CanSign() {
+		return "", ErrNoKeys
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(value)) + "." + strconv.FormatInt(time.Now().Unix(), 10)
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(c.mac(c.hashKeys[0], name, payload)), nil
+}
+
+// Return the value signed by Sign for the cookie name.
+func (c *Codec) Verify(name, signed string) (string, error) {
+	if !c.CanSign() {
+		return "", ErrNoKeys
+	}
+
+	i := strings.LastIndex(signed, ".")
+	if i < 0 {
+		return "", ErrInvalid
+	}
+
+	payload := signed[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(signed[i+1:])
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	valid := false
+	for _, key := range c.hashKeys {
+		if hmac.Equal(c.mac(key, name, payload), sig) {
+			valid = true
+			break
+		}
+	}
+
+	parts := strings.Split(payload, ".")
+	if !valid || len(parts) != 2 {
+		return "", ErrInvalid
+	}
+
+	ts, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	if err := c.checkAge(ts); err != nil {
+		return "", err
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	return string(value), nil
+}
+
+// Return the encrypted form of value for the cookie name.
+func (c *Codec) Encrypt(name, value string) (string, error) {
+	if !c.CanEncrypt() {
+		return "", ErrNoKeys
+	}
+
+	aead := c.aeads[0]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	plain := binary.BigEndian.AppendUint64(nil, uint64(time.Now().Unix()))
+	sealed := aead.Seal(nonce, nonce, append(plain, value...), []byte(name))
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Return the value encrypted by Encrypt for the cookie name.
+func (c *Codec) Decrypt(name, encrypted string) (string, error) {
+	if !c.CanEncrypt() {
+		return "", ErrNoKeys
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	for _, aead := range c.aeads {
+		n := aead.NonceSize()
+		if len(sealed) < n {
+			continue
+		}
+
+		plain, err := aead.Open(nil, sealed[:n], sealed[n:], []byte(name))
+		if err != nil || len(plain) < 8 {
+			continue
+		}
+
+		if err := c.checkAge(int64(binary.BigEndian.Uint64(plain))); err != nil {
+			return "", err
+		}
+
+		return string(plain[8:]), nil
+	}
+
+	return "", ErrInvalid
+}
+
+// Set the cookie with its Value signed.  ck is not modified.
+func (c *Codec) SetSigned(w http.ResponseWriter, ck *http.Cookie) error {
+	value, err := c.Sign(ck.Name, ck.Value)
+	if err != nil {
+		return err
+	}
+
+	signed := *ck
+	signed.Value = value
+	http.SetCookie(w, &signed)
+
+	return nil
+}
+
+// Return the value of the signed cookie name, or http.ErrNoCookie,
+// ErrInvalid or ErrExpired.
+func (c *Codec) GetSigned(r *http.Request, name string) (string, error) {
+	ck, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Verify(name, ck.Value)
+}
+
+// Set the cookie with its Value encrypted.  ck is not modified.
+func (c *Codec) SetEncrypted(w http.ResponseWriter, ck *http.Cookie) error {
+	value, err := c.Encrypt(ck.Name, ck.Value)
+	if err != nil {
+		return err
+	}
+
+	encrypted := *ck
+	encrypted.Value = value
+	http.SetCookie(w, &encrypted)
+
+	return nil
+}
+
+// Return the value of the encrypted cookie name, or http.ErrNoCookie,
+// ErrInvalid or ErrExpired.
+func (c *Codec) GetEncrypted(r *http.Request, name string) (string, error) {
+	ck, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Decrypt(name, ck.Value)
+}
+
+// Set the cookie with its Value signed if the Codec can sign, or else only
+// base64-encoded, for values like flashes that must work unconfigured.
+func (c *Codec) Set(w http.ResponseWriter, ck *http.Cookie) error {
+	if c.CanSign() {
+		return c.SetSigned(w, ck)
+	}
+
+	plain := *ck
+	plain.Value = base64.RawURLEncoding.EncodeToString([]byte(ck.Value))
+	http.SetCookie(w, &plain)
+
+	return nil
+}
+
+// Return the value of a cookie set by Set.
+func (c *Codec) Get(r *http.Request, name string) (string, error) {
+	if c.CanSign() {
+		return c.GetSigned(r, name)
+	}
+
+	ck, err := r.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(ck.Value)
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	return string(value), nil
+}
+
+func Set(w http.ResponseWriter, ck *http.Cookie) error {
+	return Default.Set(w, ck)
+}
+
+func Get(r *http.Request, name string) (string, error) {
+	return Default.Get(r, name)
+}
+
+func SetSigned(w http.ResponseWriter, ck *http.Cookie) error {
+	return Default.SetSigned(w, ck)
+}
+
+func GetSigned(r *http.Request, name string) (string, error) {
+	return Default.GetSigned(r, name)
+}
+
+func SetEncrypted(w http.ResponseWriter, ck *http.Cookie) error {
+	return Default.SetEncrypted(w, ck)
+}
+
+func GetEncrypted(r *http.Request, name string) (string, error) {
+	return Default.GetEncrypted(r, name)
+}
@@ -0,0 +1,169 @@
+package cookie
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	hashKey  = []byte("0123456789abcdef0123456789abcdef")
+	blockKey = []byte("abcdef0123456789")
+)
+
+func TestSignVerify(t *testing.T) {
+	c := New(Keys{Hash: [][]byte{hashKey}})
+	signed, err := c.Sign("user", "joe")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, err := c.Verify("user", signed); err != nil || value != "joe" {
+		t.Errorf("Verify = %q, %v", value, err)
+	}
+
+	if _, err := c.Verify("admin", signed); err != ErrInvalid {
+		t.Errorf("value moved to another cookie: %v", err)
+	}
+
+	i := strings.LastIndex(signed, ".")
+	forged := "YWRtaW4" + signed[strings.Index(signed, "."):]
+	for _, bad := range []string{forged, signed[:i], signed[:i] + ".!!", signed + "x", ""} {
+		if _, err := c.Verify("user", bad); err != ErrInvalid {
+			t.Errorf("Verify(%q) error %v, want ErrInvalid", bad, err)
+		}
+	}
+
+	other := New(Keys{Hash: [][]byte{[]byte("another key of thirty-two bytes!")}})
+	if _, err := other.Verify("user", signed); err != ErrInvalid {
+		t.Errorf("accepted with another key: %v", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := New(Keys{Hash: [][]byte{hashKey}, Block: [][]byte{blockKey}})
+	signed, _ := old.Sign("user", "joe")
+	encrypted, _ := old.Encrypt("user", "joe")
+
+	rotated := New(Keys{
+		Hash:  [][]byte{[]byte("the new hash key, also 32 bytes!"), hashKey},
+		Block: [][]byte{[]byte("fedcba9876543210"), blockKey},
+	})
+
+	if value, err := rotated.Verify("user", signed); err != nil || value != "joe" {
+		t.Errorf("Verify with the old key = %q, %v", value, err)
+	}
+
+	if value, err := rotated.Decrypt("user", encrypted); err != nil || value != "joe" {
+		t.Errorf("Decrypt with the old key = %q, %v", value, err)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	c := New(Keys{Block: [][]byte{blockKey}})
+	a, _ := c.Encrypt("cart", "secret=1")
+	b, _ := c.Encrypt("cart", "secret=1")
+	if a == b {
+		t.Error("same ciphertext twice: the nonce is not random")
+	}
+
+	if strings.Contains(a, "secret") {
+		t.Errorf("value readable in %q", a)
+	}
+
+	if value, err := c.Decrypt("cart", a); err != nil || value != "secret=1" {
+		t.Errorf("Decrypt = %q, %v", value, err)
+	}
+
+	if _, err := c.Decrypt("other", a); err != ErrInvalid {
+		t.Errorf("value moved to another cookie: %v", err)
+	}
+
+	tampered := []byte(a)
+	tampered[len(tampered)/2] ^= 1
+	for _, bad := range []string{string(tampered), a[:10], "", "!!"} {
+		if _, err := c.Decrypt("cart", bad); err != ErrInvalid {
+			t.Errorf("Decrypt(%q) error %v, want ErrInvalid", bad, err)
+		}
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	c := New(Keys{Hash: [][]byte{hashKey}})
+	c.MaxAge = time.Minute
+	payload := "am9l." + strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	stale := payload + "." + base64.RawURLEncoding.EncodeToString(c.mac(hashKey, "user", payload))
+	if _, err := c.Verify("user", stale); err != ErrExpired {
+		t.Errorf("error %v, want ErrExpired", err)
+	}
+
+	fresh, _ := c.Sign("user", "joe")
+	if _, err := c.Verify("user", fresh); err != nil {
+		t.Errorf("fresh value rejected: %v", err)
+	}
+}
+
+func TestNoKeys(t *testing.T) {
+	c := New(Keys{})
+	if _, err := c.Sign("a", "b"); err != ErrNoKeys {
+		t.Errorf("Sign error %v", err)
+	}
+
+	if _, err := c.Encrypt("a", "b"); err != ErrNoKeys {
+		t.Errorf("Encrypt error %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	c.Set(w, &http.Cookie{Name: "flash", Value: "hi there"})
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+	if value, err := c.Get(r, "flash"); err != nil || value != "hi there" {
+		t.Errorf("Get = %q, %v", value, err)
+	}
+}
+
+func TestNewPanicsOnBadKey(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic on a 5-byte AES key")
+		}
+	}()
+
+	New(Keys{Block: [][]byte{[]byte("short")}})
+}
+
+func TestSetGetCookies(t *testing.T) {
+	c := New(Keys{Hash: [][]byte{hashKey}, Block: [][]byte{blockKey}})
+	w := httptest.NewRecorder()
+	ck := &http.Cookie{Name: "prefs", Value: "dark", Path: "/"}
+	c.SetSigned(w, ck)
+	c.SetEncrypted(w, &http.Cookie{Name: "token", Value: "s3cret"})
+	if ck.Value != "dark" {
+		t.Errorf("SetSigned modified the cookie: %q", ck.Value)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, ck := range w.Result().Cookies() {
+		r.AddCookie(ck)
+	}
+
+	if value, err := c.GetSigned(r, "prefs"); err != nil || value != "dark" {
+		t.Errorf("GetSigned = %q, %v", value, err)
+	}
+
+	if value, err := c.GetEncrypted(r, "token"); err != nil || value != "s3cret" {
+		t.Errorf("GetEncrypted = %q, %v", value, err)
+	}
+
+	if _, err := c.GetEncrypted(r, "prefs"); err != ErrInvalid {
+		t.Errorf("signed cookie decrypted: %v", err)
+	}
+
+	if _, err := c.GetSigned(r, "missing"); err != http.ErrNoCookie {
+		t.Errorf("error %v, want http.ErrNoCookie", err)
+	}
+}
@@ -0,0 +1,169 @@
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"golanger.com/framework/session"
+	"html/template"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// Options of a CSRF protector.  Empty fields get the defaults of New.
+type Options struct {
+	// The form field and header a token is read from on unsafe requests.
+	FieldName  string
+	HeaderName string
+	// Where the token is kept: in the request's session when the session
+	// middleware runs first, or else in this cookie.
+	SessionKey string
+	CookieName string
+	Secure     bool
+	// Called instead of a plain 403 when a request has no valid token.
+	FailureHandler http.Handler
+}
+
+type CSRF struct {
+	opts Options
+}
+
+func New(opts Options) *CSRF {
+	if opts.FieldName == "" {
+		opts.FieldName = "csrf_token"
+	}
+
+	if opts.HeaderName == "" {
+		opts.HeaderName = "X-CSRF-Token"
+	}
+
+	if opts.SessionKey == "" {
+		opts.SessionKey = "_csrf"
+	}
+
+	if opts.CookieName == "" {
+		opts.CookieName = "_csrf"
+	}
+
+	if opts.FailureHandler == nil {
+		opts.FailureHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Forbidden - CSRF token invalid", http.StatusForbidden)
+		})
+	}
+
+	return &CSRF{opts: opts}
+}
+
+func newToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("csrf: no random source: " + err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
+	}
+
+	return false
+}
+
+// Return the stored token of the request, issuing a new one if there is none.
+func (c *CSRF) token(w http.ResponseWriter, r *http.Request) string {
+	if s := session.FromRequest(r); s != nil {
+		token := s.GetString(c.opts.SessionKey)
+		if token == "" {
+			token = newToken()
+			s.Set(c.opts.SessionKey, token)
+		}
+
+		return token
+	}
+
+	if ck, err := r.Cookie(c.opts.CookieName); err == nil && ck.Value != "" {
+		return ck.Value
+	}
+
+	token := newToken()
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.opts.CookieName,
+		Value:    token,
+		Path:     "/",
+		Secure:   c.opts.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	return token
+}
+
+func (c *CSRF) submitted(r *http.Request) string {
+	if token := r.Header.Get(c.opts.HeaderName); token != "" {
+		return token
+	}
+
+	return r.FormValue(c.opts.FieldName)
+}
+
+// Return a middleware that makes the token available through Token and
+// rejects POST, PUT, DELETE and other unsafe requests that do not send it
+// back in the form field or header.
+func (c *CSRF) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := c.token(w, r)
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, &tokenInfo{token: token, field: c.opts.FieldName}))
+		if !safeMethod(r.Method) {
+			sent := c.submitted(r)
+			if sent == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				c.opts.FailureHandler.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+type tokenInfo struct {
+	token string
+	field string
+}
+
+// Return the token of a request that went through Middleware, or "".
+func Token(r *http.Request) string {
+	if info, ok := r.Context().Value(contextKey{}).(*tokenInfo); ok {
+		return info.token
+	}
+
+	return ""
+}
+
+// Return a hidden input carrying the token, for use in forms.
+func TemplateField(r *http.Request) template.HTML {
+	info, ok := r.Context().Value(contextKey{}).(*tokenInfo)
+	if !ok {
+		return ""
+	}
+
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(info.field) +
+		`" value="` + template.HTMLEscapeString(info.token) + `">`)
+}
+
+// Add the token to a template context as "CSRFToken" and the hidden input
+// as "CSRFField", returning data (a new map if data is nil).
+func TemplateData(r *http.Request, data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+
+	data["CSRFToken"] = Token(r)
+	data["CSRFField"] = TemplateField(r)
+
+	return data
+}
@@ -0,0 +1,119 @@
+package csrf
+
+import (
+	"golanger.com/framework/session"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("token " + Token(r)))
+})
+
+// Serve r through h, with the cookies of prev, and return the response.
+func serve(h http.Handler, r *http.Request, prev *httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	if prev != nil {
+		for _, ck := range prev.Result().Cookies() {
+			r.AddCookie(ck)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func post(token string) *http.Request {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(url.Values{"csrf_token": {token}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return r
+}
+
+func TestCookieToken(t *testing.T) {
+	h := New(Options{}).Middleware(ok)
+	first := serve(h, httptest.NewRequest("GET", "/", nil), nil)
+	cookies := first.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || cookies[0].SameSite != http.SameSiteLaxMode {
+		t.Fatalf("cookies %v", cookies)
+	}
+
+	token := strings.TrimPrefix(first.Body.String(), "token ")
+	if len(token) < 40 || token != cookies[0].Value {
+		t.Fatalf("token %q, cookie %q", token, cookies[0].Value)
+	}
+
+	if w := serve(h, post(token), first); w.Code != http.StatusOK {
+		t.Errorf("valid form token rejected: %d", w.Code)
+	}
+
+	r := httptest.NewRequest("DELETE", "/", nil)
+	r.Header.Set("X-CSRF-Token", token)
+	if w := serve(h, r, first); w.Code != http.StatusOK {
+		t.Errorf("valid header token rejected: %d", w.Code)
+	}
+
+	for _, bad := range []string{"", "forged", token[:len(token)-1]} {
+		if w := serve(h, post(bad), first); w.Code != http.StatusForbidden {
+			t.Errorf("token %q accepted: %d", bad, w.Code)
+		}
+	}
+
+	if w := serve(h, post(token), nil); w.Code != http.StatusForbidden {
+		t.Errorf("token accepted without its cookie: %d", w.Code)
+	}
+}
+
+func TestSessionToken(t *testing.T) {
+	m := session.NewManager(session.NewMemoryStore(), "sid", time.Hour)
+	h := m.Middleware(New(Options{}).Middleware(ok))
+	first := serve(h, httptest.NewRequest("GET", "/", nil), nil)
+	token := strings.TrimPrefix(first.Body.String(), "token ")
+	for _, ck := range first.Result().Cookies() {
+		if ck.Name == "_csrf" {
+			t.Errorf("cookie %v set with a session", ck)
+		}
+	}
+
+	if w := serve(h, post(token), first); w.Code != http.StatusOK {
+		t.Errorf("session token rejected: %d", w.Code)
+	}
+
+	other := serve(h, httptest.NewRequest("GET", "/", nil), nil)
+	if w := serve(h, post(token), other); w.Code != http.StatusForbidden {
+		t.Errorf("token of another session accepted: %d", w.Code)
+	}
+}
+
+func TestFailureHandler(t *testing.T) {
+	called := false
+	h := New(Options{FailureHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusTeapot)
+	})}).Middleware(ok)
+
+	if w := serve(h, post("x"), nil); w.Code != http.StatusTeapot || !called {
+		t.Errorf("status %d, FailureHandler called %v", w.Code, called)
+	}
+}
+
+func TestTemplateField(t *testing.T) {
+	var field string
+	h := New(Options{FieldName: `a"b`}).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		field = string(TemplateField(r))
+	}))
+
+	serve(h, httptest.NewRequest("GET", "/", nil), nil)
+	if !strings.HasPrefix(field, `<input type="hidden" name="a&#34;b" value="`) {
+		t.Errorf("field %s", field)
+	}
+
+	if TemplateField(httptest.NewRequest("GET", "/", nil)) != "" {
+		t.Error("field outside the middleware")
+	}
+}
@@ -0,0 +1,249 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// The bind parameter syntax of a driver.
+type Placeholder int
+
+const (
+	Question Placeholder = iota // ?, MySQL and SQLite
+	Dollar                      // $1, PostgreSQL
+	AtP                         // @p1, SQL Server
+	Colon                       // :1, Oracle
+)
+
+// Guess the placeholder syntax of a database/sql driver from its name.
+func PlaceholderFor(driver string) Placeholder {
+	switch driver {
+	case "postgres", "pgx", "pq", "cockroach":
+		return Dollar
+	case "sqlserver", "mssql":
+		return AtP
+	case "oracle", "godror", "oci8":
+		return Colon
+	}
+
+	return Question
+}
+
+func (p Placeholder) format(n int) string {
+	switch p {
+	case Dollar:
+		return fmt.Sprint("$", n)
+	case AtP:
+		return fmt.Sprint("@p", n)
+	case Colon:
+		return fmt.Sprint(":", n)
+	}
+
+	return "?"
+}
+
+// Settings of the connection pool; zero values leave the database/sql
+// defaults.
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// Guessed from the driver name if nil.
+	Placeholder *Placeholder
+}
+
+// A DB is a *sql.DB that knows the placeholder syntax of its driver, for
+// named parameters and Rebind.
+type DB struct {
+	*sql.DB
+	Placeholder Placeholder
+}
+
+// The DB of the package-level functions, to be set by the application.
+var Default *DB
+
+// Open a database and check that it can be reached, within ten seconds.
+// The driver must have been registered by importing it.
+func Open(driver, dsn string, opts ...Options) (*DB, error) {
+	sqlDB, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	d := Wrap(sqlDB, PlaceholderFor(driver))
+	if len(opts) > 0 {
+		o := opts[0]
+		if o.MaxOpenConns != 0 {
+			sqlDB.SetMaxOpenConns(o.MaxOpenConns)
+		}
+
+		if o.MaxIdleConns != 0 {
+			sqlDB.SetMaxIdleConns(o.MaxIdleConns)
+		}
+
+		if o.ConnMaxLifetime != 0 {
+			sqlDB.SetConnMaxLifetime(o.ConnMaxLifetime)
+		}
+
+		if o.ConnMaxIdleTime != 0 {
+			sqlDB.SetConnMaxIdleTime(o.ConnMaxIdleTime)
+		}
+
+		if o.Placeholder != nil {
+			d.Placeholder = *o.Placeholder
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Return a DB for a database opened elsewhere.
+func Wrap(sqlDB *sql.DB, placeholder Placeholder) *DB {
+	return &DB{DB: sqlDB, Placeholder: placeholder}
+}
+
+// Rewrite the ? placeholders of query into those of the driver, so that
+// one query serves every database.
+func (d *DB) Rebind(query string) string {
+	return rebind(query, d.Placeholder)
+}
+
+// The methods shared by *sql.DB and *sql.Tx that the helpers build on.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Run a query with :name parameters taken from arg, a map[string]interface{}
+// or a struct.
+func (d *DB) NamedExec(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return namedExec(ctx, d, d.Placeholder, query, arg)
+}
+
+func (d *DB) NamedQuery(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	return namedQuery(ctx, d, d.Placeholder, query, arg)
+}
+
+func namedExec(ctx context.Context, e execer, p Placeholder, query string, arg interface{}) (sql.Result, error) {
+	q, args, err := bindNamed(query, arg, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.ExecContext(ctx, q, args...)
+}
+
+func namedQuery(ctx context.Context, e execer, p Placeholder, query string, arg interface{}) (*sql.Rows, error) {
+	q, args, err := bindNamed(query, arg, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.QueryContext(ctx, q, args...)
+}
+
+// A Tx is a *sql.Tx with the named parameter helpers of its DB.
+type Tx struct {
+	*sql.Tx
+	Placeholder Placeholder
+}
+
+func (tx *Tx) Rebind(query string) string {
+	return rebind(query, tx.Placeholder)
+}
+
+func (tx *Tx) NamedExec(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return namedExec(ctx, tx, tx.Placeholder, query, arg)
+}
+
+func (tx *Tx) NamedQuery(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	return namedQuery(ctx, tx, tx.Placeholder, query, arg)
+}
+
+// Run fn in a transaction, committed if it returns nil and rolled back if
+// it returns an error or panics; the panic is then raised again.
+func (d *DB) InTx(fn func(tx *Tx) error) error {
+	return d.InTxCtx(context.Background(), nil, fn)
+}
+
+func (d *DB) InTxCtx(ctx context.Context, opts *sql.TxOptions, fn func(tx *Tx) error) error {
+	sqlTx, err := d.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			sqlTx.Rollback()
+		}
+	}()
+
+	if err := fn(&Tx{Tx: sqlTx, Placeholder: d.Placeholder}); err != nil {
+		return err
+	}
+
+	committed = true
+
+	return sqlTx.Commit()
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Report whether value exists in column of table, making the DB a
+// validator.Querier for Unique.
+func (d *DB) Exists(table, column string, value interface{}) (bool, error) {
+	return d.ExistsCtx(context.Background(), table, column, value)
+}
+
+func (d *DB) ExistsCtx(ctx context.Context, table, column string, value interface{}) (bool, error) {
+	if !identifierPattern.MatchString(table) || !identifierPattern.MatchString(column) {
+		return false, fmt.Errorf("invalid table or column name: %s.%s", table, column)
+	}
+
+	where := " FROM " + table + " WHERE " + column + " = " + d.Placeholder.format(1)
+	var query string
+	switch d.Placeholder {
+	case AtP:
+		query = "SELECT TOP 1 1" + where
+	case Colon:
+		query = "SELECT 1" + where + " FETCH FIRST 1 ROWS ONLY"
+	default:
+		query = "SELECT 1" + where + " LIMIT 1"
+	}
+
+	var one int
+	switch err := d.QueryRowContext(ctx, query, value).Scan(&one); err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func InTx(fn func(tx *Tx) error) error {
+	return Default.InTx(fn)
+}
+
+func NamedExec(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return Default.NamedExec(ctx, query, arg)
+}
+
+func NamedQuery(ctx context.Context, query string, arg interface{}) (*sql.Rows, error) {
+	return Default.NamedQuery(ctx, query, arg)
+}
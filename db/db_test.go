@@ -0,0 +1,313 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A database/sql driver that records the statements it is given and
+// answers the few queries of this package from an in-memory state, one
+// per DSN.
+type fakeDriver struct{}
+
+type fakeState struct {
+	mutex   sync.Mutex
+	log     []string
+	applied map[int64]interface{}
+	values  map[interface{}]bool
+}
+
+var fakeStates = struct {
+	sync.Mutex
+	m map[string]*fakeState
+}{m: map[string]*fakeState{}}
+
+func init() {
+	sql.Register("fakedb", fakeDriver{})
+}
+
+// Open a DB on a new fake state.
+func openFake(t *testing.T) (*DB, *fakeState) {
+	st := &fakeState{applied: map[int64]interface{}{}, values: map[interface{}]bool{}}
+	fakeStates.Lock()
+	fakeStates.m[t.Name()] = st
+	fakeStates.Unlock()
+
+	d, err := Open("fakedb", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { d.Close() })
+
+	return d, st
+}
+
+// Return the statements run so far, and forget them.
+func (st *fakeState) take() []string {
+	st.mutex.Lock()
+	defer st.mutex.Unlock()
+
+	log := st.log
+	st.log = nil
+
+	return log
+}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeStates.Lock()
+	defer fakeStates.Unlock()
+
+	st, ok := fakeStates.m[dsn]
+	if !ok {
+		return nil, errors.New("no such database")
+	}
+
+	return &fakeConn{st}, nil
+}
+
+type fakeConn struct {
+	st *fakeState
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c.st, query}, nil
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.st.record("BEGIN")
+	return fakeTx{c.st}, nil
+}
+
+type fakeTx struct {
+	st *fakeState
+}
+
+func (tx fakeTx) Commit() error {
+	tx.st.record("COMMIT")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.st.record("ROLLBACK")
+	return nil
+}
+
+func (st *fakeState) record(s string) {
+	st.mutex.Lock()
+	st.log = append(st.log, s)
+	st.mutex.Unlock()
+}
+
+type fakeStmt struct {
+	st    *fakeState
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	return nil
+}
+
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.st.record(s.query)
+	if strings.Contains(s.query, "FAIL") {
+		return nil, errors.New("syntax error")
+	}
+
+	s.st.mutex.Lock()
+	defer s.st.mutex.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "INSERT INTO schema_migrations"):
+		s.st.applied[args[0].(int64)] = args[1]
+	case strings.HasPrefix(s.query, "DELETE FROM schema_migrations"):
+		delete(s.st.applied, args[0].(int64))
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.st.record(s.query)
+	s.st.mutex.Lock()
+	defer s.st.mutex.Unlock()
+
+	rows := &fakeRows{}
+	switch {
+	case strings.HasPrefix(s.query, "SELECT version, applied_at"):
+		rows.columns = []string{"version", "applied_at"}
+		for version, at := range s.st.applied {
+			rows.values = append(rows.values, []driver.Value{version, at})
+		}
+	case strings.HasPrefix(s.query, "SELECT 1") || strings.HasPrefix(s.query, "SELECT TOP 1 1"):
+		rows.columns = []string{"1"}
+		if s.st.values[args[0]] {
+			rows.values = [][]driver.Value{{int64(1)}}
+		}
+	default:
+		return nil, errors.New("unexpected query")
+	}
+
+	return rows, nil
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+
+	return nil
+}
+
+func TestPlaceholderFor(t *testing.T) {
+	cases := map[string]Placeholder{"mysql": Question, "sqlite3": Question, "pgx": Dollar, "sqlserver": AtP, "godror": Colon}
+	for driver, want := range cases {
+		if got := PlaceholderFor(driver); got != want {
+			t.Errorf("PlaceholderFor(%s) = %v, want %v", driver, got, want)
+		}
+	}
+}
+
+func TestOpen(t *testing.T) {
+	d, _ := openFake(t)
+	if d.Placeholder != Question {
+		t.Errorf("placeholder %v", d.Placeholder)
+	}
+
+	dollar := Dollar
+	fakeStates.Lock()
+	fakeStates.m["pool"] = &fakeState{}
+	fakeStates.Unlock()
+	d, err := Open("fakedb", "pool", Options{MaxOpenConns: 3, Placeholder: &dollar})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer d.Close()
+	if d.Placeholder != Dollar || d.Stats().MaxOpenConnections != 3 {
+		t.Errorf("placeholder %v, %d connections", d.Placeholder, d.Stats().MaxOpenConnections)
+	}
+
+	if _, err := Open("fakedb", "nosuch"); err == nil {
+		t.Error("no error for an unreachable database")
+	}
+
+	if _, err := Open("nosuchdriver", ""); err == nil {
+		t.Error("no error for an unknown driver")
+	}
+}
+
+func TestInTx(t *testing.T) {
+	d, st := openFake(t)
+	err := d.InTx(func(tx *Tx) error {
+		_, err := tx.ExecContext(context.Background(), "UPDATE a")
+		return err
+	})
+
+	if got := strings.Join(st.take(), "; "); err != nil || got != "BEGIN; UPDATE a; COMMIT" {
+		t.Errorf("ran %s: %v", got, err)
+	}
+
+	failure := errors.New("no")
+	if err := d.InTx(func(tx *Tx) error { return failure }); err != failure {
+		t.Errorf("error %v", err)
+	}
+
+	if got := strings.Join(st.take(), "; "); got != "BEGIN; ROLLBACK" {
+		t.Errorf("ran %s", got)
+	}
+
+	func() {
+		defer func() {
+			if recover() != "boom" {
+				t.Error("panic not raised again")
+			}
+		}()
+
+		d.InTx(func(tx *Tx) error { panic("boom") })
+	}()
+
+	if got := strings.Join(st.take(), "; "); got != "BEGIN; ROLLBACK" {
+		t.Errorf("ran %s", got)
+	}
+}
+
+func TestNamedExec(t *testing.T) {
+	d, st := openFake(t)
+	d.Placeholder = Dollar
+	_, err := d.NamedExec(context.Background(), "UPDATE users SET name = :name WHERE id = :id", map[string]interface{}{"name": "Joe", "id": 1})
+	if got := st.take(); err != nil || len(got) != 1 || got[0] != "UPDATE users SET name = $1 WHERE id = $2" {
+		t.Errorf("ran %v: %v", got, err)
+	}
+
+	if _, err := d.NamedExec(context.Background(), "UPDATE users SET name = :nmae", map[string]interface{}{"name": "Joe"}); err == nil {
+		t.Error("no error for a missing parameter")
+	}
+
+	err = d.InTx(func(tx *Tx) error {
+		_, err := tx.NamedExec(context.Background(), "DELETE FROM users WHERE id = :id", struct{ ID int }{7})
+		return err
+	})
+
+	if got := st.take(); err != nil || len(got) != 3 || got[1] != "DELETE FROM users WHERE id = $1" {
+		t.Errorf("ran %v: %v", got, err)
+	}
+}
+
+func TestExists(t *testing.T) {
+	d, st := openFake(t)
+	st.values["joe@example.com"] = true
+
+	if found, err := d.Exists("users", "email", "joe@example.com"); !found || err != nil {
+		t.Errorf("Exists = %v, %v", found, err)
+	}
+
+	if found, err := d.Exists("users", "email", "ann@example.com"); found || err != nil {
+		t.Errorf("Exists = %v, %v", found, err)
+	}
+
+	if got := st.take(); got[0] != "SELECT 1 FROM users WHERE email = ? LIMIT 1" {
+		t.Errorf("ran %v", got)
+	}
+
+	d.Placeholder = AtP
+	d.Exists("app.users", "email", "x")
+	if got := st.take(); got[0] != "SELECT TOP 1 1 FROM app.users WHERE email = @p1" {
+		t.Errorf("ran %v", got)
+	}
+
+	for _, bad := range [][2]string{{"users; DROP TABLE users", "email"}, {"users", "email = email OR 1"}, {"", "email"}} {
+		if _, err := d.Exists(bad[0], bad[1], "x"); err == nil {
+			t.Errorf("no error for %q.%q", bad[0], bad[1])
+		}
+	}
+}
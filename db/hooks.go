@@ -0,0 +1,93 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// A query run through a DB or Tx, as passed to the OnQuery hooks.
+type QueryEvent struct {
+	Query    string
+	Args     []interface{}
+	Duration time.Duration
+	Err      error
+}
+
+var queryHooks = struct {
+	sync.RWMutex
+	list []func(ctx context.Context, e QueryEvent)
+}{}
+
+// Call f after every query run with a context through a DB or Tx, e.g. to
+// log slow queries.  f must be safe for concurrent use.
+func OnQuery(f func(ctx context.Context, e QueryEvent)) {
+	queryHooks.Lock()
+	queryHooks.list = append(queryHooks.list, f)
+	queryHooks.Unlock()
+}
+
+func reportQuery(ctx context.Context, query string, args []interface{}, start time.Time, err error) {
+	queryHooks.RLock()
+	list := queryHooks.list
+	queryHooks.RUnlock()
+
+	if len(list) == 0 {
+		return
+	}
+
+	e := QueryEvent{Query: query, Args: args, Duration: time.Since(start), Err: err}
+	for _, f := range list {
+		f(ctx, e)
+	}
+}
+
+// The methods of execer, reporting to the OnQuery hooks.
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := d.DB.ExecContext(ctx, query, args...)
+	reportQuery(ctx, query, args, start, err)
+
+	return result, err
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	reportQuery(ctx, query, args, start, err)
+
+	return rows, err
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	reportQuery(ctx, query, args, start, row.Err())
+
+	return row
+}
+
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	reportQuery(ctx, query, args, start, err)
+
+	return result, err
+}
+
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	reportQuery(ctx, query, args, start, err)
+
+	return rows, err
+}
+
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	reportQuery(ctx, query, args, start, row.Err())
+
+	return row
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestOnQuery(t *testing.T) {
+	d, _ := openFake(t)
+	var mutex sync.Mutex
+	events := []QueryEvent{}
+	OnQuery(func(ctx context.Context, e QueryEvent) {
+		mutex.Lock()
+		events = append(events, e)
+		mutex.Unlock()
+	})
+
+	defer func() {
+		queryHooks.Lock()
+		queryHooks.list = nil
+		queryHooks.Unlock()
+	}()
+
+	ctx := context.Background()
+	d.ExecContext(ctx, "UPDATE a SET b = ?", 1)
+	d.ExecContext(ctx, "FAIL")
+	d.NamedExec(ctx, "UPDATE a SET b = :b", map[string]interface{}{"b": 2})
+	d.InTx(func(tx *Tx) error {
+		tx.QueryRowContext(ctx, "SELECT 1 FROM t WHERE c = ?", 3)
+		return nil
+	})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(events) != 4 {
+		t.Fatalf("events %+v", events)
+	}
+
+	if events[0].Query != "UPDATE a SET b = ?" || events[0].Args[0] != 1 || events[0].Err != nil || events[1].Err == nil {
+		t.Errorf("events %+v", events[:2])
+	}
+
+	if events[2].Args[0] != 2 || events[3].Query != "SELECT 1 FROM t WHERE c = ?" {
+		t.Errorf("events %+v", events[2:])
+	}
+}
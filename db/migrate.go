@@ -0,0 +1,325 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Migration is a pair of files NNN_name.up.sql and NNN_name.down.sql,
+// NNN being its version, often a timestamp.  Each file is run with one
+// Exec, so a driver must accept several statements in one (MySQL needs
+// multiStatements=true).
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+
+	// When the migration was applied, zero if it is pending; only set by
+	// Status.
+	AppliedAt time.Time
+}
+
+var migrationPattern = regexp.MustCompile(`^(\d+)_([^.]+)\.(up|down)\.sql$`)
+
+// A Migrator applies the migrations of a directory to a DB, recording the
+// applied versions in Table.
+type Migrator struct {
+	db   *DB
+	fsys fs.FS
+
+	// "schema_migrations" if empty.
+	Table string
+}
+
+func NewMigrator(d *DB, fsys fs.FS) *Migrator {
+	return &Migrator{db: d, fsys: fsys}
+}
+
+func (m *Migrator) table() string {
+	if m.Table == "" {
+		return "schema_migrations"
+	}
+
+	return m.Table
+}
+
+// Return the migrations of the directory by version.
+func (m *Migrator) Migrations() ([]*Migration, error) {
+	entries, err := fs.ReadDir(m.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		match := migrationPattern.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mg
+		} else if mg.Name != match[2] {
+			return nil, fmt.Errorf("db: migrations %s and %s share version %d", mg.Name, match[2], version)
+		}
+
+		data, err := fs.ReadFile(m.fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] == "up" {
+			mg.Up = string(data)
+		} else {
+			mg.Down = string(data)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		migrations = append(migrations, mg)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	_, err := m.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+m.table()+" (version BIGINT PRIMARY KEY, applied_at TIMESTAMP NOT NULL)")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version, applied_at FROM "+m.table())
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var at interface{}
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt(at)
+	}
+
+	return applied, rows.Err()
+}
+
+// Convert an applied_at column, which drivers without time parsing return
+// as text, to a time, never zero for an applied migration.
+func appliedAt(v interface{}) time.Time {
+	var s string
+	switch at := v.(type) {
+	case time.Time:
+		return at
+	case []byte:
+		s = string(at)
+	case string:
+		s = at
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Unix(0, 0).UTC()
+}
+
+// Return all migrations, with AppliedAt set for the applied ones.
+func (m *Migrator) Status(ctx context.Context) ([]*Migration, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mg := range migrations {
+		mg.AppliedAt = applied[mg.Version]
+	}
+
+	return migrations, nil
+}
+
+// Apply the pending migrations in order, each in a transaction, and return
+// those applied.  It stops at the first that fails.
+func (m *Migrator) Up(ctx context.Context) ([]*Migration, error) {
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for _, mg := range migrations {
+		if !mg.AppliedAt.IsZero() {
+			continue
+		}
+
+		if strings.TrimSpace(mg.Up) == "" {
+			return done, fmt.Errorf("db: migration %d_%s has no up file", mg.Version, mg.Name)
+		}
+
+		err := m.db.InTxCtx(ctx, nil, func(tx *Tx) error {
+			if _, err := tx.ExecContext(ctx, mg.Up); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, tx.Rebind("INSERT INTO "+m.table()+" (version, applied_at) VALUES (?, ?)"), mg.Version, time.Now().UTC())
+
+			return err
+		})
+
+		if err != nil {
+			return done, fmt.Errorf("db: migration %d_%s: %v", mg.Version, mg.Name, err)
+		}
+
+		done = append(done, mg)
+	}
+
+	return done, nil
+}
+
+// Revert the last n applied migrations, newest first, and return those
+// reverted.
+func (m *Migrator) Down(ctx context.Context, n int) ([]*Migration, error) {
+	migrations, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for i := len(migrations) - 1; i >= 0 && len(done) < n; i-- {
+		mg := migrations[i]
+		if mg.AppliedAt.IsZero() {
+			continue
+		}
+
+		if strings.TrimSpace(mg.Down) == "" {
+			return done, fmt.Errorf("db: migration %d_%s has no down file", mg.Version, mg.Name)
+		}
+
+		err := m.db.InTxCtx(ctx, nil, func(tx *Tx) error {
+			if _, err := tx.ExecContext(ctx, mg.Down); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, tx.Rebind("DELETE FROM "+m.table()+" WHERE version = ?"), mg.Version)
+
+			return err
+		})
+
+		if err != nil {
+			return done, fmt.Errorf("db: migration %d_%s: %v", mg.Version, mg.Name, err)
+		}
+
+		mg.AppliedAt = time.Time{}
+		done = append(done, mg)
+	}
+
+	return done, nil
+}
+
+// The environment variables through which `framework migrate` passes its
+// command and directory to the application.
+const (
+	MigrateEnv    = "GOLANGER_MIGRATE"
+	MigrationsEnv = "GOLANGER_MIGRATIONS"
+)
+
+// Run the migration command, if the application was started by `framework
+// migrate`, and exit; return otherwise.  Call it once the DB is open.  The
+// migrations are read from fsys, or the directory given to the command if
+// fsys is nil.
+func HandleMigrate(d *DB, fsys fs.FS) {
+	command := strings.Fields(os.Getenv(MigrateEnv))
+	if len(command) == 0 {
+		return
+	}
+
+	if fsys == nil {
+		dir := os.Getenv(MigrationsEnv)
+		if dir == "" {
+			dir = "migrations"
+		}
+
+		fsys = os.DirFS(dir)
+	}
+
+	if err := runMigrate(NewMigrator(d, fsys), command); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+}
+
+func runMigrate(m *Migrator, command []string) error {
+	ctx := context.Background()
+	switch command[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mg := range done {
+			fmt.Printf("applied  %d_%s\n", mg.Version, mg.Name)
+		}
+
+		if err == nil && len(done) == 0 {
+			fmt.Println("nothing to apply")
+		}
+
+		return err
+	case "down":
+		n := 1
+		if len(command) > 1 {
+			var err error
+			if n, err = strconv.Atoi(command[1]); err != nil || n < 1 {
+				return fmt.Errorf("bad number of migrations: %s", command[1])
+			}
+		}
+
+		done, err := m.Down(ctx, n)
+		for _, mg := range done {
+			fmt.Printf("reverted %d_%s\n", mg.Version, mg.Name)
+		}
+
+		return err
+	case "status":
+		migrations, err := m.Status(ctx)
+		for _, mg := range migrations {
+			at := "pending"
+			if !mg.AppliedAt.IsZero() {
+				at = mg.AppliedAt.Format(time.RFC3339)
+			}
+
+			fmt.Printf("%-25s %d_%s\n", at, mg.Version, mg.Name)
+		}
+
+		return err
+	}
+
+	return fmt.Errorf("unknown command %q", command[0])
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var migrations = fstest.MapFS{
+	"001_users.up.sql":     {Data: []byte("CREATE TABLE users")},
+	"001_users.down.sql":   {Data: []byte("DROP TABLE users")},
+	"002_posts.up.sql":     {Data: []byte("CREATE TABLE posts")},
+	"002_posts.down.sql":   {Data: []byte("DROP TABLE posts")},
+	"010_tags.up.sql":      {Data: []byte("CREATE TABLE tags")},
+	"README.md":            {Data: []byte("not a migration")},
+	"003_notes.sql":        {Data: []byte("not one either")},
+	"004_dir.up.sql/x.sql": {Data: []byte("in a directory")},
+}
+
+// Return the statements of log that are not about schema_migrations.
+func changes(log []string) string {
+	kept := []string{}
+	for _, s := range log {
+		if !strings.Contains(s, "schema_migrations") {
+			kept = append(kept, s)
+		}
+	}
+
+	return strings.Join(kept, "; ")
+}
+
+func TestMigrations(t *testing.T) {
+	got, err := NewMigrator(nil, migrations).Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 || got[0].Name != "users" || got[1].Version != 2 || got[2].Version != 10 || got[2].Down != "" {
+		t.Fatalf("migrations %+v", got)
+	}
+
+	if got[0].Up != "CREATE TABLE users" || got[0].Down != "DROP TABLE users" {
+		t.Errorf("users migration %+v", got[0])
+	}
+
+	clash := fstest.MapFS{"001_a.up.sql": {}, "001_b.up.sql": {}}
+	if _, err := NewMigrator(nil, clash).Migrations(); err == nil {
+		t.Error("no error for two migrations of one version")
+	}
+}
+
+func TestUpDown(t *testing.T) {
+	d, st := openFake(t)
+	m := NewMigrator(d, migrations)
+	ctx := context.Background()
+
+	done, err := m.Up(ctx)
+	if err != nil || len(done) != 3 {
+		t.Fatalf("Up = %v, %v", done, err)
+	}
+
+	if got := changes(st.take()); got != "BEGIN; CREATE TABLE users; COMMIT; BEGIN; CREATE TABLE posts; COMMIT; BEGIN; CREATE TABLE tags; COMMIT" {
+		t.Errorf("Up ran %s", got)
+	}
+
+	if done, err := m.Up(ctx); err != nil || len(done) != 0 {
+		t.Errorf("second Up = %v, %v", done, err)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil || len(status) != 3 || status[0].AppliedAt.IsZero() || time.Since(status[0].AppliedAt) > time.Minute {
+		t.Errorf("Status = %+v, %v", status, err)
+	}
+
+	// 010 has no down file.
+	if done, err := m.Down(ctx, 1); err == nil || len(done) != 0 {
+		t.Errorf("Down of a migration without a down file = %v, %v", done, err)
+	}
+
+	delete(st.applied, 10)
+	st.take()
+	done, err = m.Down(ctx, 5)
+	if err != nil || len(done) != 2 || done[0].Version != 2 || !done[0].AppliedAt.IsZero() {
+		t.Fatalf("Down = %v, %v", done, err)
+	}
+
+	if got := changes(st.take()); got != "BEGIN; DROP TABLE posts; COMMIT; BEGIN; DROP TABLE users; COMMIT" {
+		t.Errorf("Down ran %s", got)
+	}
+
+	if len(st.applied) != 0 {
+		t.Errorf("applied %v", st.applied)
+	}
+}
+
+func TestUpStopsAtFailure(t *testing.T) {
+	d, st := openFake(t)
+	fsys := fstest.MapFS{
+		"1_a.up.sql": {Data: []byte("CREATE TABLE a")},
+		"2_b.up.sql": {Data: []byte("FAIL")},
+		"3_c.up.sql": {Data: []byte("CREATE TABLE c")},
+	}
+
+	done, err := NewMigrator(d, fsys).Up(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "2_b") || len(done) != 1 {
+		t.Errorf("Up = %v, %v", done, err)
+	}
+
+	if got := changes(st.take()); got != "BEGIN; CREATE TABLE a; COMMIT; BEGIN; FAIL; ROLLBACK" {
+		t.Errorf("Up ran %s", got)
+	}
+
+	if _, ok := st.applied[2]; ok || len(st.applied) != 1 {
+		t.Errorf("applied %v", st.applied)
+	}
+}
+
+func TestMigratorTable(t *testing.T) {
+	d, st := openFake(t)
+	m := NewMigrator(d, fstest.MapFS{})
+	m.Table = "versions"
+	m.Status(context.Background())
+	if got := st.take(); len(got) == 0 || !strings.Contains(got[0], "CREATE TABLE IF NOT EXISTS versions") {
+		t.Errorf("ran %v", got)
+	}
+}
+
+func TestAppliedAt(t *testing.T) {
+	want := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	for _, v := range []interface{}{want, "2024-05-01T12:30:00Z", []byte("2024-05-01 12:30:00"), "2024-05-01 12:30:00+00:00"} {
+		if got := appliedAt(v); !got.Equal(want) {
+			t.Errorf("appliedAt(%v) = %v", v, got)
+		}
+	}
+
+	if got := appliedAt(int64(5)); got.IsZero() {
+		t.Error("unparsed applied_at is zero, as if pending")
+	}
+}
+
+func TestRunMigrate(t *testing.T) {
+	d, _ := openFake(t)
+	m := NewMigrator(d, fstest.MapFS{"1_a.up.sql": {Data: []byte("CREATE TABLE a")}, "1_a.down.sql": {Data: []byte("DROP TABLE a")}})
+	for _, command := range [][]string{{"status"}, {"up"}, {"down", "1"}, {"down"}} {
+		if err := runMigrate(m, command); err != nil {
+			t.Errorf("%v: %v", command, err)
+		}
+	}
+
+	for _, command := range [][]string{{"down", "0"}, {"down", "x"}, {"sideways"}} {
+		if err := runMigrate(m, command); err == nil {
+			t.Errorf("no error for %v", command)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package db
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Call f for each parameter of query outside quoted strings and
+// identifiers: a ? (with name "") if named is false, or a :name if it is.
+// The :: of PostgreSQL casts and := are not parameters.  Return the query
+// with each parameter replaced by what f returns.
+func scanParams(query string, named bool, f func(name string) string) string {
+	var b strings.Builder
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(query) && query[j] != c {
+				j++
+			}
+
+			if j >= len(query) {
+				j = len(query) - 1
+			}
+
+			b.WriteString(query[i : j+1])
+			i = j
+		case c == ':' && i+1 < len(query) && (query[i+1] == ':' || query[i+1] == '='):
+			b.WriteString(query[i : i+2])
+			i++
+		case c == ':' && named && i+1 < len(query) && isNameStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && isNameChar(query[j]) {
+				j++
+			}
+
+			b.WriteString(f(query[i+1 : j]))
+			i = j - 1
+		case c == '?' && !named:
+			b.WriteString(f(""))
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || '0' <= c && c <= '9' || c == '.'
+}
+
+func rebind(query string, p Placeholder) string {
+	if p == Question {
+		return query
+	}
+
+	n := 0
+
+	return scanParams(query, false, func(string) string {
+		n++
+		return p.format(n)
+	})
+}
+
+// Replace the :name parameters of query with placeholders and return the
+// values of arg they name, in order.  Struct fields are named by their db
+// tag, or else match regardless of case; a dotted name reaches into nested
+// structs and maps.
+func bindNamed(query string, arg interface{}, p Placeholder) (string, []interface{}, error) {
+	args := []interface{}{}
+	var err error
+	q := scanParams(query, true, func(name string) string {
+		v, ok := lookupName(reflect.ValueOf(arg), name)
+		if !ok && err == nil {
+			err = fmt.Errorf("db: no value for parameter :%s", name)
+		}
+
+		args = append(args, v)
+
+		return p.format(len(args))
+	})
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return q, args, nil
+}
+
+func lookupName(rv reflect.Value, name string) (interface{}, bool) {
+	for _, part := range strings.Split(name, ".") {
+		for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+			if rv.IsNil() {
+				return nil, false
+			}
+
+			rv = rv.Elem()
+		}
+
+		switch rv.Kind() {
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return nil, false
+			}
+
+			rv = rv.MapIndex(reflect.ValueOf(part).Convert(rv.Type().Key()))
+			if !rv.IsValid() {
+				return nil, false
+			}
+		case reflect.Struct:
+			f, ok := structField(rv, part)
+			if !ok {
+				return nil, false
+			}
+
+			rv = f
+		default:
+			return nil, false
+		}
+	}
+
+	return rv.Interface(), true
+}
+
+func structField(rv reflect.Value, name string) (reflect.Value, bool) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(sf.Tag.Get("db"), ",")[0]
+		if tag == "-" {
+			continue
+		}
+
+		if tag == name || tag == "" && strings.EqualFold(sf.Name, name) {
+			return rv.Field(i), true
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if sf := t.Field(i); sf.Anonymous && sf.PkgPath == "" {
+			f := rv.Field(i)
+			for f.Kind() == reflect.Ptr && !f.IsNil() {
+				f = f.Elem()
+			}
+
+			if f.Kind() == reflect.Struct {
+				if v, ok := structField(f, name); ok {
+					return v, true
+				}
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
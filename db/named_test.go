@@ -0,0 +1,82 @@
+package db
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRebind(t *testing.T) {
+	cases := []struct {
+		p           Placeholder
+		query, want string
+	}{
+		{Question, "SELECT * FROM t WHERE a = ? AND b = ?", "SELECT * FROM t WHERE a = ? AND b = ?"},
+		{Dollar, "SELECT * FROM t WHERE a = ? AND b = ?", "SELECT * FROM t WHERE a = $1 AND b = $2"},
+		{AtP, "UPDATE t SET a = ?", "UPDATE t SET a = @p1"},
+		{Colon, "UPDATE t SET a = ?", "UPDATE t SET a = :1"},
+		{Dollar, `SELECT '?', "a?b", ? FROM t`, `SELECT '?', "a?b", $1 FROM t`},
+		{Dollar, "SELECT 'it''s ?', ?", "SELECT 'it''s ?', $1"},
+		{Dollar, "SELECT 'unterminated ?", "SELECT 'unterminated ?"},
+	}
+
+	for _, c := range cases {
+		if got := (&DB{Placeholder: c.p}).Rebind(c.query); got != c.want {
+			t.Errorf("Rebind(%q) = %q, want %q", c.query, got, c.want)
+		}
+	}
+}
+
+type address struct {
+	City string
+}
+
+type Base struct {
+	ID int64 `db:"id"`
+}
+
+type person struct {
+	Base
+	Name    string `db:"full_name"`
+	Email   string
+	Skipped string `db:"-"`
+	Home    *address
+	secret  string
+}
+
+func TestBindNamed(t *testing.T) {
+	p := person{Base: Base{7}, Name: "Joe", Email: "joe@example.com", Home: &address{"Oslo"}}
+	query, args, err := bindNamed("UPDATE people SET full_name = :full_name, email = :EMAIL, city = :home.city WHERE id = :id AND x::int = 1", p, Dollar)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query != "UPDATE people SET full_name = $1, email = $2, city = $3 WHERE id = $4 AND x::int = 1" {
+		t.Errorf("query %q", query)
+	}
+
+	if !reflect.DeepEqual(args, []interface{}{"Joe", "joe@example.com", "Oslo", int64(7)}) {
+		t.Errorf("args %v", args)
+	}
+
+	for _, name := range []string{"name", "Skipped", "secret", "nosuch", "home.street", "email.x"} {
+		if _, _, err := bindNamed("SELECT :"+name, &p, Question); err == nil {
+			t.Errorf("no error for :%s", name)
+		}
+	}
+
+	if _, _, err := bindNamed("SELECT :home.city", person{}, Question); err == nil {
+		t.Error("no error through a nil pointer")
+	}
+}
+
+func TestBindNamedMap(t *testing.T) {
+	arg := map[string]interface{}{"id": 1, "user": map[string]interface{}{"name": "Ann"}}
+	query, args, err := bindNamed("SELECT ':id', :id, :user.name, a := 1", arg, Question)
+	if err != nil || query != "SELECT ':id', ?, ?, a := 1" || !reflect.DeepEqual(args, []interface{}{1, "Ann"}) {
+		t.Errorf("bindNamed = %q, %v, %v", query, args, err)
+	}
+
+	if _, _, err := bindNamed("SELECT :a", map[int]int{1: 1}, Question); err == nil {
+		t.Error("no error for a map without string keys")
+	}
+}
@@ -0,0 +1,99 @@
+package debug
+
+import (
+	"html/template"
+)
+
+var pages = template.Must(template.New("debug").Funcs(template.FuncMap{
+	"ms":  ms,
+	"pct": pct,
+}).Parse(`
+{{define "bar"}}{{with .Data}}
+<div id="golanger-debug" style="position:fixed;bottom:0;left:0;right:0;z-index:99999;background:#222;color:#eee;font:12px/1.8 monospace;padding:0 8px;border-top:2px solid #c33">
+<a href="{{$.Path}}{{.ID}}" style="color:#fc6">{{.Method}} {{.Path}}</a>
+&middot; {{.Status}} &middot; {{ms .Duration}}
+{{range .Breakdown}}&middot; {{.Name}} {{ms .Duration}} {{end}}
+&middot; {{len .Queries}} queries {{ms .QueryTime}}
+&middot; {{len .Session}} session values
+&middot; {{if .Errors}}<span style="color:#f66">{{len .Errors}} validation errors</span>{{else}}no validation errors{{end}}
+&middot; <a href="{{$.Path}}" style="color:#fc6">all requests</a>
+</div>
+{{end}}{{end}}
+
+{{define "head"}}<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.}}</title>
+<style>
+body{font:14px sans-serif;margin:2em}
+table{border-collapse:collapse;margin-bottom:2em}
+td,th{border:1px solid #ccc;padding:4px 8px;text-align:left;vertical-align:top}
+pre{margin:0;white-space:pre-wrap}
+.bar{background:#69c;height:10px}
+.err{color:#c33}
+</style>
+</head>
+<body>
+{{end}}
+
+{{define "list"}}{{template "head" "Requests"}}
+<h1>Requests</h1>
+<table>
+<tr><th>Time</th><th>Request</th><th>Status</th><th>Duration</th><th>Queries</th><th>Errors</th></tr>
+{{range .Data}}
+<tr>
+<td>{{.Start.Format "15:04:05.000"}}</td>
+<td><a href="{{$.Path}}{{.ID}}">{{.Method}} {{.Path}}</a></td>
+<td>{{.Status}}</td>
+<td>{{ms .Duration}}</td>
+<td>{{len .Queries}}</td>
+<td>{{len .Errors}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+{{end}}
+
+{{define "detail"}}{{with .Data}}{{template "head" .Path}}
+<p><a href="{{$.Path}}">All requests</a> &middot; <a href="{{$.Path}}{{.ID}}?format=json">JSON</a></p>
+<h1>{{.Method}} {{.Path}}</h1>
+<p>{{.Status}} in {{ms .Duration}}, at {{.Start.Format "2006-01-02 15:04:05.000"}}</p>
+
+<h2>Timing</h2>
+<table>
+<tr><th>Step</th><th>Start</th><th>Duration</th><th style="width:300px"></th></tr>
+{{$total := .Duration}}
+{{range .Spans}}
+<tr><td>{{.Name}}</td><td>{{ms .At}}</td><td>{{ms .Duration}}</td>
+<td><div class="bar" style="margin-left:{{pct .At $total}};width:{{pct .Duration $total}}"></div></td></tr>
+{{end}}
+</table>
+
+<h2>Queries ({{len .Queries}}, {{ms .QueryTime}})</h2>
+<table>
+<tr><th>Query</th><th>Arguments</th><th>Duration</th></tr>
+{{range .Queries}}
+<tr><td><pre>{{.SQL}}</pre>{{if .Err}}<p class="err">{{.Err}}</p>{{end}}</td><td>{{range .Args}}<pre>{{printf "%#v" .}}</pre>{{end}}</td><td>{{ms .Duration}}</td></tr>
+{{end}}
+</table>
+
+<h2>Session</h2>
+<table>
+{{range $k, $v := .Session}}
+<tr><th>{{$k}}</th><td><pre>{{printf "%#v" $v}}</pre></td></tr>
+{{else}}
+<tr><td>No session, or an empty one</td></tr>
+{{end}}
+</table>
+
+<h2>Validation errors</h2>
+<table>
+<tr><th>Key</th><th>Code</th><th>Message</th></tr>
+{{range .Errors}}
+<tr><td>{{.Key}}</td><td>{{.Code}}</td><td>{{.Message}}</td></tr>
+{{end}}
+</table>
+</body>
+</html>
+{{end}}{{end}}
+`))
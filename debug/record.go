@@ -0,0 +1,142 @@
+package debug
+
+import (
+	"context"
+	"fmt"
+	"golanger.com/framework/validator"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A timed step of a request, such as routing or rendering, starting At
+// after the request did.
+type Span struct {
+	Name     string
+	At       time.Duration
+	Duration time.Duration
+}
+
+// A query run while serving a request.
+type Query struct {
+	SQL      string
+	Args     []interface{}
+	Duration time.Duration
+	Err      string
+}
+
+// What the Toolbar recorded of one request.
+type Record struct {
+	ID       string
+	Method   string
+	Path     string
+	Status   int
+	Start    time.Time
+	Duration time.Duration
+	Spans    []Span
+	Queries  []Query
+	Session  map[string]interface{}
+	Errors   []*validator.ValidationError
+
+	mutex       sync.Mutex
+	validations []*validator.Validation
+}
+
+type contextKey struct{}
+
+var lastID uint64
+
+func newRecord() *Record {
+	return &Record{ID: fmt.Sprintf("%x", atomic.AddUint64(&lastID, 1)), Start: time.Now()}
+}
+
+// Return the Record of the request of ctx, or nil if the Toolbar is not
+// recording it.
+func FromContext(ctx context.Context) *Record {
+	rec, _ := ctx.Value(contextKey{}).(*Record)
+	return rec
+}
+
+// Start timing a step of the request of ctx and return the function that
+// ends it, e.g. defer debug.Start(r.Context(), "binding")().  It does
+// nothing if the request is not recorded.
+func Start(ctx context.Context, name string) func() {
+	rec := FromContext(ctx)
+	if rec == nil {
+		return func() {}
+	}
+
+	start := time.Now()
+
+	return func() {
+		rec.mutex.Lock()
+		rec.Spans = append(rec.Spans, Span{name, start.Sub(rec.Start), time.Since(start)})
+		rec.mutex.Unlock()
+	}
+}
+
+// Add a query to the Record of ctx, if any.
+func AddQuery(ctx context.Context, q Query) {
+	if rec := FromContext(ctx); rec != nil {
+		rec.mutex.Lock()
+		rec.Queries = append(rec.Queries, q)
+		rec.mutex.Unlock()
+	}
+}
+
+// Show the errors of v with the request of ctx, if it is recorded, as
+// they stand when the request ends.
+func TrackValidation(ctx context.Context, v *validator.Validation) {
+	if rec := FromContext(ctx); rec != nil && v != nil {
+		rec.mutex.Lock()
+		rec.validations = append(rec.validations, v)
+		rec.mutex.Unlock()
+	}
+}
+
+// Return the total time of the spans of each name, in the order they
+// first started.
+func (rec *Record) Breakdown() []Span {
+	totals := []Span{}
+	index := map[string]int{}
+	for _, s := range rec.Spans {
+		i, ok := index[s.Name]
+		if !ok {
+			i = len(totals)
+			index[s.Name] = i
+			totals = append(totals, Span{Name: s.Name, At: s.At})
+		}
+
+		totals[i].Duration += s.Duration
+	}
+
+	return totals
+}
+
+// Return the total time of the queries.
+func (rec *Record) QueryTime() time.Duration {
+	var d time.Duration
+	for _, q := range rec.Queries {
+		d += q.Duration
+	}
+
+	return d
+}
+
+// Collect the tracked errors once the request is over.
+func (rec *Record) finish(status int) {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+
+	rec.Status = status
+	rec.Duration = time.Since(rec.Start)
+	seen := map[*validator.Validation]bool{}
+	for _, v := range rec.validations {
+		if !seen[v] {
+			seen[v] = true
+			rec.Errors = append(rec.Errors, v.Errors...)
+		}
+	}
+
+	rec.validations = nil
+}
@@ -0,0 +1,83 @@
+package debug
+
+import (
+	"context"
+	"golanger.com/framework/validator"
+	"testing"
+	"time"
+)
+
+func TestUnrecorded(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) != nil {
+		t.Error("record without a Toolbar")
+	}
+
+	Start(ctx, "binding")()
+	AddQuery(ctx, Query{SQL: "SELECT 1"})
+	TrackValidation(ctx, &validator.Validation{})
+}
+
+func TestRecord(t *testing.T) {
+	rec := newRecord()
+	ctx := context.WithValue(context.Background(), contextKey{}, rec)
+	if FromContext(ctx) != rec {
+		t.Fatal("record not in the context")
+	}
+
+	done := Start(ctx, "binding")
+	time.Sleep(time.Millisecond)
+	done()
+	Start(ctx, "rendering")()
+	Start(ctx, "binding")()
+
+	AddQuery(ctx, Query{SQL: "SELECT 1", Duration: 2 * time.Millisecond})
+	AddQuery(ctx, Query{SQL: "SELECT 2", Duration: 3 * time.Millisecond})
+
+	v := &validator.Validation{}
+	TrackValidation(ctx, v)
+	TrackValidation(ctx, v)
+	TrackValidation(ctx, nil)
+	v.Required("").Key("name")
+
+	rec.finish(422)
+
+	breakdown := rec.Breakdown()
+	if len(breakdown) != 2 || breakdown[0].Name != "binding" || breakdown[1].Name != "rendering" {
+		t.Fatalf("breakdown %+v", breakdown)
+	}
+
+	if breakdown[0].Duration < time.Millisecond || breakdown[0].Duration != rec.Spans[0].Duration+rec.Spans[2].Duration {
+		t.Errorf("binding took %v", breakdown[0].Duration)
+	}
+
+	if rec.QueryTime() != 5*time.Millisecond || rec.Status != 422 || rec.Duration <= 0 {
+		t.Errorf("queries %v, status %d, duration %v", rec.QueryTime(), rec.Status, rec.Duration)
+	}
+
+	// Errors recorded after tracking are collected, once per Validation.
+	if len(rec.Errors) != 1 || rec.Errors[0].Key != "name" {
+		t.Errorf("errors %v", rec.Errors)
+	}
+
+	if newRecord().ID == rec.ID {
+		t.Error("records share an ID")
+	}
+}
+
+func TestPct(t *testing.T) {
+	cases := []struct {
+		d, total time.Duration
+		want     string
+	}{
+		{time.Second, 4 * time.Second, "25.0%"},
+		{time.Second, 0, "0%"},
+		{2 * time.Second, time.Second, "100.0%"},
+	}
+
+	for _, c := range cases {
+		if got := pct(c.d, c.total); got != c.want {
+			t.Errorf("pct(%v, %v) = %s, want %s", c.d, c.total, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package debug
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golanger.com/framework/db"
+	"golanger.com/framework/session"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Options struct {
+	// Record requests and serve the toolbar.  Leave it off in production:
+	// the pages disclose queries and sessions.  Under `framework run`,
+	// which sets GOLANGER_DEV, it is meant to be on.
+	Enabled bool
+
+	// Where the recorded requests are listed, "/_debug/" if empty.
+	Path string
+
+	// The number of requests kept, 50 if zero.
+	Keep int
+}
+
+// A Toolbar records, in development, the timing of the steps of each
+// request (routing, binding, validation, rendering and those timed with
+// Start), the queries it ran through package db, its session and its
+// validation errors.  It adds a bar showing them to HTML pages and lists
+// the recent requests under Path.
+type Toolbar struct {
+	opts    Options
+	mutex   sync.Mutex
+	records []*Record
+}
+
+var hookQueries sync.Once
+
+func New(opts Options) *Toolbar {
+	if opts.Path == "" {
+		opts.Path = "/_debug/"
+	}
+
+	if !strings.HasSuffix(opts.Path, "/") {
+		opts.Path += "/"
+	}
+
+	if opts.Keep <= 0 {
+		opts.Keep = 50
+	}
+
+	if opts.Enabled {
+		hookQueries.Do(func() {
+			db.OnQuery(func(ctx context.Context, e db.QueryEvent) {
+				q := Query{SQL: e.Query, Args: e.Args, Duration: e.Duration}
+				if e.Err != nil {
+					q.Err = e.Err.Error()
+				}
+
+				AddQuery(ctx, q)
+			})
+		})
+	}
+
+	return &Toolbar{opts: opts}
+}
+
+// Return the recorded requests, the most recent first.
+func (t *Toolbar) Records() []*Record {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	records := make([]*Record, len(t.records))
+	for i, rec := range t.records {
+		records[len(t.records)-1-i] = rec
+	}
+
+	return records
+}
+
+func (t *Toolbar) record(rec *Record) {
+	t.mutex.Lock()
+	t.records = append(t.records, rec)
+	if len(t.records) > t.opts.Keep {
+		t.records = t.records[len(t.records)-t.opts.Keep:]
+	}
+	t.mutex.Unlock()
+}
+
+func (t *Toolbar) find(id string) *Record {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, rec := range t.records {
+		if rec.ID == id {
+			return rec
+		}
+	}
+
+	return nil
+}
+
+// Record each request and serve the pages under Path.  It must run inside
+// the session middleware for the session to be shown, and outside the
+// router.  It does nothing unless Enabled.
+func (t *Toolbar) Middleware(next http.Handler) http.Handler {
+	if !t.opts.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, t.opts.Path) {
+			t.serve(w, r, strings.TrimPrefix(r.URL.Path, t.opts.Path))
+			return
+		}
+
+		rec := newRecord()
+		rec.Method = r.Method
+		rec.Path = r.URL.RequestURI()
+		bw := &bufferWriter{ResponseWriter: w}
+		next.ServeHTTP(bw, r.WithContext(context.WithValue(r.Context(), contextKey{}, rec)))
+
+		if s := session.FromRequest(r); s != nil {
+			rec.Session = s.Values()
+		}
+
+		status := bw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		rec.finish(status)
+		t.record(rec)
+		bw.finish(t, rec)
+	})
+}
+
+// Serve the list of requests, or the one of id, as HTML or, with
+// ?format=json, as JSON.
+func (t *Toolbar) serve(w http.ResponseWriter, r *http.Request, id string) {
+	var data interface{}
+	name := "list"
+	if id == "" {
+		data = t.Records()
+	} else if rec := t.find(id); rec != nil {
+		data = rec
+		name = "detail"
+	} else {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pages.ExecuteTemplate(w, name, map[string]interface{}{"Path": t.opts.Path, "Data": data})
+}
+
+// A ResponseWriter holding back HTML bodies so that the bar can be added
+// before </body>.  Other responses, and HTML ones once flushed, go
+// straight through.
+type bufferWriter struct {
+	http.ResponseWriter
+	status    int
+	decided   bool
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (w *bufferWriter) decide() {
+	if w.decided {
+		return
+	}
+
+	w.decided = true
+	h := w.Header()
+	w.buffering = strings.HasPrefix(h.Get("Content-Type"), "text/html") && h.Get("Content-Encoding") == ""
+}
+
+func (w *bufferWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+
+	w.status = code
+	w.decide()
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *bufferWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buffering {
+		return w.buf.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Send what was held back, without the bar, and stop buffering.
+func (w *bufferWriter) Flush() {
+	w.release()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *bufferWriter) release() {
+	if w.buffering {
+		w.buffering = false
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+func (w *bufferWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("debug: ResponseWriter does not support Hijack")
+	}
+
+	return h.Hijack()
+}
+
+func (w *bufferWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Write the held back body with the bar of rec before its last </body>,
+// or at its end if there is none.
+func (w *bufferWriter) finish(t *Toolbar, rec *Record) {
+	if !w.buffering {
+		return
+	}
+
+	var bar bytes.Buffer
+	pages.ExecuteTemplate(&bar, "bar", map[string]interface{}{"Path": t.opts.Path, "Data": rec})
+
+	body := w.buf.Bytes()
+	i := bytes.LastIndex(bytes.ToLower(body), []byte("</body>"))
+	if i < 0 {
+		i = len(body)
+	}
+
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(body[:i])
+	w.ResponseWriter.Write(bar.Bytes())
+	w.ResponseWriter.Write(body[i:])
+}
+
+func ms(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
+
+// Return the share of total taken by d, as a CSS width.
+func pct(d, total time.Duration) string {
+	if total <= 0 {
+		return "0%"
+	}
+
+	return fmt.Sprintf("%.1f%%", math.Min(float64(d)/float64(total)*100, 100))
+}
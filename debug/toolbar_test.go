@@ -0,0 +1,149 @@
+package debug
+
+import (
+	"encoding/json"
+	"golanger.com/framework/session"
+	"golanger.com/framework/validator"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func page(w http.ResponseWriter, r *http.Request) {
+	Start(r.Context(), "rendering")()
+	v := &validator.Validation{}
+	TrackValidation(r.Context(), v)
+	v.Required("").Key("email")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Length", "40")
+	w.Write([]byte("<html><body><p>Hello</p></BODY></html>"))
+}
+
+func serve(h http.Handler, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+
+	return w
+}
+
+func TestToolbarDisabled(t *testing.T) {
+	next := http.HandlerFunc(page)
+	tb := New(Options{})
+	if w := serve(tb.Middleware(next), "/_debug/"); strings.Contains(w.Body.String(), "Requests") {
+		t.Error("pages served while disabled")
+	}
+}
+
+func TestToolbarBar(t *testing.T) {
+	tb := New(Options{Enabled: true})
+	w := serve(tb.Middleware(http.HandlerFunc(page)), "/users?x=<1>")
+	body := w.Body.String()
+
+	bar := strings.Index(body, `<div id="golanger-debug"`)
+	end := strings.Index(body, "</BODY>")
+	if bar < 0 || end < bar || !strings.HasPrefix(body, "<html><body><p>Hello</p>") {
+		t.Fatalf("body %s", body)
+	}
+
+	if w.Header().Get("Content-Length") != "" {
+		t.Error("Content-Length of the page without the bar kept")
+	}
+
+	for _, want := range []string{"GET /users?x=&lt;1&gt;", "200", "rendering", "0 queries", `1 validation errors`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("bar does not show %q: %s", want, body[bar:end])
+		}
+	}
+
+	if strings.Contains(body, "<1>") {
+		t.Error("path not escaped")
+	}
+}
+
+func TestToolbarPassesThrough(t *testing.T) {
+	tb := New(Options{Enabled: true})
+	h := tb.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(201)
+			w.Write([]byte(`{}`))
+		case "/gzip":
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write([]byte("zipped"))
+		case "/stream":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<body>first"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(" second</body>"))
+		}
+	}))
+
+	if w := serve(h, "/json"); w.Code != 201 || w.Body.String() != `{}` {
+		t.Errorf("json answered %d %s", w.Code, w.Body)
+	}
+
+	if w := serve(h, "/gzip"); w.Body.String() != "zipped" {
+		t.Errorf("gzip answered %s", w.Body)
+	}
+
+	if w := serve(h, "/stream"); w.Body.String() != "<body>first second</body>" || !w.Flushed {
+		t.Errorf("stream answered %s", w.Body)
+	}
+
+	if records := tb.Records(); len(records) != 3 || records[0].Path != "/stream" || records[2].Status != 201 {
+		t.Errorf("records %+v", records)
+	}
+}
+
+func TestToolbarPages(t *testing.T) {
+	tb := New(Options{Enabled: true, Path: "/dbg", Keep: 2})
+	h := tb.Middleware(http.HandlerFunc(page))
+	for _, path := range []string{"/a", "/b", "/c"} {
+		serve(h, path)
+	}
+
+	records := tb.Records()
+	if len(records) != 2 || records[0].Path != "/c" || records[1].Path != "/b" {
+		t.Fatalf("records %+v", records)
+	}
+
+	if w := serve(h, "/dbg/"); !strings.Contains(w.Body.String(), `<a href="/dbg/`+records[0].ID+`">GET /c</a>`) || strings.Contains(w.Body.String(), "GET /a") {
+		t.Errorf("list %s", w.Body)
+	}
+
+	w := serve(h, "/dbg/"+records[1].ID)
+	if !strings.Contains(w.Body.String(), "<h1>GET /b</h1>") || !strings.Contains(w.Body.String(), "<td>email</td>") {
+		t.Errorf("detail %s", w.Body)
+	}
+
+	var rec Record
+	w = serve(h, "/dbg/"+records[1].ID+"?format=json")
+	if err := json.Unmarshal(w.Body.Bytes(), &rec); err != nil || rec.Path != "/b" || len(rec.Errors) != 1 {
+		t.Errorf("JSON %s: %v", w.Body, err)
+	}
+
+	if w := serve(h, "/dbg/nosuch"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown record answered %d", w.Code)
+	}
+
+	if len(tb.Records()) != 2 {
+		t.Error("pages recorded")
+	}
+}
+
+func TestToolbarSession(t *testing.T) {
+	tb := New(Options{Enabled: true})
+	m := session.NewManager(session.NewMemoryStore(), "sid", time.Hour)
+	h := m.Middleware(tb.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session.FromRequest(r).Set("user", "joe")
+	})))
+
+	serve(h, "/")
+	if rec := tb.Records()[0]; rec.Session["user"] != "joe" {
+		t.Errorf("session %v", rec.Session)
+	}
+}
@@ -0,0 +1,227 @@
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// A Decimal is an exact decimal number, such as an amount of money: an
+// integer and the number of digits after the point, which it keeps, so
+// that "12.30" stays "12.30" and no cent is lost to float rounding.  The
+// zero value is 0.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// Return unscaled / 10^scale, e.g. New(1230, 2) for 12.30.
+func New(unscaled int64, scale int) Decimal {
+	if scale < 0 {
+		panic("decimal: negative scale")
+	}
+
+	return Decimal{big.NewInt(unscaled), scale}
+}
+
+var ErrSyntax = errors.New("decimal: invalid number")
+
+// Parse a number in Go syntax, with an optional sign and decimal point,
+// such as "-12.30"; exponents are not accepted.  See
+// validator.NormalizeNumber for numbers written in a locale.
+func Parse(s string) (Decimal, error) {
+	s = strings.TrimSpace(s)
+	sign := ""
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		sign, s = s[:1], s[1:]
+	}
+
+	intPart, frac := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		intPart, frac = s[:i], s[i+1:]
+	}
+
+	if intPart == "" && frac == "" || !digits(intPart) || !digits(frac) {
+		return Decimal{}, ErrSyntax
+	}
+
+	n, ok := new(big.Int).SetString(sign+intPart+frac, 10)
+	if !ok {
+		return Decimal{}, ErrSyntax
+	}
+
+	return Decimal{n, len(frac)}, nil
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parse s, panicking if it is not a number, for constants.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d Decimal) int() *big.Int {
+	if d.unscaled == nil {
+		return new(big.Int)
+	}
+
+	return d.unscaled
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// Return the number of digits after the point, including trailing zeros.
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+// Return -1, 0 or +1 as d is negative, zero or positive.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Return the unscaled integers of d and e at their common scale.
+func align(d, e Decimal) (*big.Int, *big.Int, int) {
+	a, b := d.int(), e.int()
+	switch {
+	case d.scale < e.scale:
+		a = new(big.Int).Mul(a, pow10(e.scale-d.scale))
+		return a, b, e.scale
+	case d.scale > e.scale:
+		b = new(big.Int).Mul(b, pow10(d.scale-e.scale))
+	}
+
+	return a, b, d.scale
+}
+
+// Compare d and e: -1 if d < e, 0 if they are equal, whatever their
+// scales, and +1 if d > e.
+func (d Decimal) Cmp(e Decimal) int {
+	a, b, _ := align(d, e)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Add(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return Decimal{new(big.Int).Add(a, b), scale}
+}
+
+func (d Decimal) Sub(e Decimal) Decimal {
+	a, b, scale := align(d, e)
+	return Decimal{new(big.Int).Sub(a, b), scale}
+}
+
+// Return d times n, e.g. a unit price times a quantity.
+func (d Decimal) MulInt(n int64) Decimal {
+	return Decimal{new(big.Int).Mul(d.int(), big.NewInt(n)), d.scale}
+}
+
+// Return the integer d is in units of 10^-scale, e.g. cents for scale 2,
+// and whether d has no more digits than that.
+func (d Decimal) Units(scale int) (*big.Int, bool) {
+	if scale >= d.scale {
+		return new(big.Int).Mul(d.int(), pow10(scale-d.scale)), true
+	}
+
+	q, r := new(big.Int).QuoRem(d.int(), pow10(d.scale-scale), new(big.Int))
+
+	return q, r.Sign() == 0
+}
+
+// Return d as an exact fraction.
+func (d Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(d.int(), pow10(d.scale))
+}
+
+func (d Decimal) String() string {
+	s := new(big.Int).Abs(d.int()).String()
+	if d.scale > 0 {
+		if len(s) <= d.scale {
+			s = strings.Repeat("0", d.scale-len(s)+1) + s
+		}
+
+		s = s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+	}
+
+	if d.Sign() < 0 {
+		return "-" + s
+	}
+
+	return s
+}
+
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalText(b []byte) error {
+	p, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+
+	*d = p
+
+	return nil
+}
+
+// Encode d as a JSON string, which JavaScript does not round to a float.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// Decode a JSON number or string.
+func (d *Decimal) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+
+	return d.UnmarshalText([]byte(strings.Trim(s, `"`)))
+}
+
+// Store d as its text, which NUMERIC and DECIMAL columns accept.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Decimal) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case string:
+		return d.UnmarshalText([]byte(s))
+	case []byte:
+		return d.UnmarshalText(s)
+	case int64:
+		*d = New(s, 0)
+		return nil
+	case float64:
+		return d.UnmarshalText([]byte(strconv.FormatFloat(s, 'f', -1, 64)))
+	}
+
+	return fmt.Errorf("decimal: cannot scan %T", src)
+}
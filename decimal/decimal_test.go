@@ -0,0 +1,213 @@
+package decimal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseKeepsScale(t *testing.T) {
+	cases := map[string]string{
+		"12.30":  "12.30",
+		"-0.5":   "-0.5",
+		"+7":     "7",
+		".25":    "0.25",
+		"3.":     "3",
+		" 0.00 ": "0.00",
+		"-0.001": "-0.001",
+	}
+
+	for in, want := range cases {
+		d, err := Parse(in)
+		if err != nil || d.String() != want {
+			t.Errorf("Parse(%q) = %v, %v, want %s", in, d, err, want)
+		}
+	}
+
+	for _, bad := range []string{"", ".", "-", "1e3", "1.2.3", "1,5", "0x10", "--1", "١"} {
+		if _, err := Parse(bad); err != ErrSyntax {
+			t.Errorf("Parse(%q) error %v, want ErrSyntax", bad, err)
+		}
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var d Decimal
+	if d.String() != "0" || !d.IsZero() || d.Cmp(MustParse("0.00")) != 0 {
+		t.Errorf("zero value %v", d)
+	}
+
+	if got := d.Add(MustParse("1.5")); got.String() != "1.5" {
+		t.Errorf("0 + 1.5 = %v", got)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := MustParse("12.30"), MustParse("0.705")
+	if got := a.Add(b); got.String() != "13.005" {
+		t.Errorf("Add = %v", got)
+	}
+
+	if got := a.Sub(b); got.String() != "11.595" {
+		t.Errorf("Sub = %v", got)
+	}
+
+	if got := b.Sub(a); got.String() != "-11.595" || got.Sign() != -1 {
+		t.Errorf("Sub = %v", got)
+	}
+
+	if got := a.MulInt(3); got.String() != "36.90" {
+		t.Errorf("MulInt = %v", got)
+	}
+
+	if a.String() != "12.30" || b.String() != "0.705" {
+		t.Errorf("operands changed to %v and %v", a, b)
+	}
+
+	sum := New(0, 2)
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(MustParse("0.10"))
+	}
+
+	if sum.Cmp(New(1, 0)) != 0 {
+		t.Errorf("ten times 0.10 = %v", sum)
+	}
+}
+
+func TestCmpAcrossScales(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"0.10", "0.1", 0},
+		{"1", "1.000", 0},
+		{"-0.01", "0", -1},
+		{"2.5", "2.49", 1},
+		{"-3", "-3.01", 1},
+	}
+
+	for _, c := range cases {
+		if got := MustParse(c.a).Cmp(MustParse(c.b)); got != c.want {
+			t.Errorf("Cmp(%s, %s) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestUnits(t *testing.T) {
+	cases := []struct {
+		in    string
+		scale int
+		want  string
+		exact bool
+	}{
+		{"12.30", 2, "1230", true},
+		{"12.3", 2, "1230", true},
+		{"12", 0, "12", true},
+		{"12.300", 2, "1230", true},
+		{"0.001", 2, "0", false},
+		{"-1.005", 2, "-100", false},
+		{"1500", 0, "1500", true},
+	}
+
+	for _, c := range cases {
+		n, exact := MustParse(c.in).Units(c.scale)
+		if n.String() != c.want || exact != c.exact {
+			t.Errorf("Units(%s, %d) = %v, %v, want %s, %v", c.in, c.scale, n, exact, c.want, c.exact)
+		}
+	}
+}
+
+func TestRat(t *testing.T) {
+	if got := MustParse("-1.25").Rat().String(); got != "-5/4" {
+		t.Errorf("Rat = %s", got)
+	}
+}
+
+func TestNewNegativeScalePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a negative scale")
+		}
+	}()
+
+	New(1, -1)
+}
+
+func TestMustParsePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for an invalid number")
+		}
+	}()
+
+	MustParse("twelve")
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Price Decimal
+		Tax   Decimal
+		Total Decimal
+	}
+
+	v.Total = MustParse("9.99")
+	if err := json.Unmarshal([]byte(`{"Price": 12.30, "Tax": "0.70", "Total": null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Price.String() != "12.30" || v.Tax.String() != "0.70" || v.Total.String() != "9.99" {
+		t.Errorf("decoded %v", v)
+	}
+
+	b, _ := json.Marshal(v)
+	if string(b) != `{"Price":"12.30","Tax":"0.70","Total":"9.99"}` {
+		t.Errorf("encoded %s", b)
+	}
+
+	if err := json.Unmarshal([]byte(`{"Price": "12,30"}`), &v); err == nil {
+		t.Error("no error for an invalid number")
+	}
+}
+
+func TestText(t *testing.T) {
+	var d Decimal
+	if err := d.UnmarshalText([]byte("-4.50")); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := d.MarshalText(); string(b) != "-4.50" {
+		t.Errorf("MarshalText = %s", b)
+	}
+
+	if err := d.UnmarshalText([]byte("x")); err != ErrSyntax || d.String() != "-4.50" {
+		t.Errorf("UnmarshalText of x: %v, changed to %v", err, d)
+	}
+}
+
+func TestSQL(t *testing.T) {
+	if v, err := MustParse("0.10").Value(); err != nil || v != "0.10" {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+
+	cases := []struct {
+		src  interface{}
+		want string
+	}{
+		{"12.30", "12.30"},
+		{[]byte("-0.5"), "-0.5"},
+		{int64(42), "42"},
+		{float64(0.1), "0.1"},
+		{nil, "0"},
+	}
+
+	for _, c := range cases {
+		d := MustParse("1")
+		if err := d.Scan(c.src); err != nil || d.String() != c.want {
+			t.Errorf("Scan(%#v) = %v, %v, want %s", c.src, d, err, c.want)
+		}
+	}
+
+	var d Decimal
+	if err := d.Scan(true); err == nil {
+		t.Error("no error scanning a bool")
+	}
+}
@@ -0,0 +1,41 @@
+package decimal
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var number = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)$`)
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{"0", "-0", "12.30", "+.5", "5.", "-0.001", "1e5", "", ".", "-", "1.2.3", " 7 ", "0x10", "1_000", "١٢"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := Parse(s)
+		if valid := number.MatchString(strings.TrimSpace(s)); valid != (err == nil) {
+			t.Fatalf("Parse(%q) error %v, valid %v", s, err, valid)
+		}
+
+		if err != nil {
+			return
+		}
+
+		want, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+		if !ok || d.Rat().Cmp(want) != 0 {
+			t.Fatalf("Parse(%q) = %s, want %v", s, d, want)
+		}
+
+		e, err := Parse(d.String())
+		if err != nil || e.String() != d.String() || e.Scale() != d.Scale() {
+			t.Fatalf("Parse(%q) = %s does not round-trip: %s, %v", s, d, e, err)
+		}
+
+		if sum := d.Add(e).Sub(e); sum.Cmp(d) != 0 {
+			t.Fatalf("%s + %s - %s = %s", d, e, e, sum)
+		}
+	})
+}
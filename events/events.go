@@ -0,0 +1,288 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"golanger.com/framework/log"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An Event is something that happened in the application, such as
+// "user.created", with a payload describing it.
+type Event struct {
+	Name    string
+	Payload interface{}
+	Time    time.Time
+}
+
+// A Handler reacts to an event.
+type Handler func(ctx context.Context, e *Event) error
+
+type subscription struct {
+	pattern string
+	handler Handler
+	async   bool
+}
+
+// A Bus dispatches events to the handlers subscribed to them, so that
+// modules such as mailers, jobs and metrics can react to what controllers
+// do without them knowing.  It is safe for concurrent use.
+//
+// A subscription pattern is an event name, a prefix of names ending in
+// ".*", such as "user.*" for "user.created" and "user.deleted", or "*"
+// for every event.
+type Bus struct {
+	mutex sync.RWMutex
+	subs  []*subscription
+	wg    sync.WaitGroup
+
+	// Called with the error of each handler that failed or panicked, after
+	// it has been logged.
+	OnError func(e *Event, err error)
+}
+
+func New() *Bus {
+	return &Bus{}
+}
+
+// The Bus of the package-level functions.
+var Default = New()
+
+// The error of a handler that panicked.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprint("events: handler panicked: ", e.Value)
+}
+
+func matches(pattern, name string) bool {
+	if pattern == "*" || pattern == name {
+		return true
+	}
+
+	return strings.HasSuffix(pattern, ".*") && strings.HasPrefix(name, pattern[:len(pattern)-1])
+}
+
+func (b *Bus) subscribe(pattern string, h Handler, async bool) func() {
+	s := &subscription{pattern: pattern, handler: h, async: async}
+	b.mutex.Lock()
+	b.subs = append(b.subs, s)
+	b.mutex.Unlock()
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		for i, sub := range b.subs {
+			if sub == s {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Call h, in the goroutine of Emit, for the events matching pattern, and
+// return the function that unsubscribes it.
+func (b *Bus) Subscribe(pattern string, h Handler) func() {
+	return b.subscribe(pattern, h, false)
+}
+
+// Like Subscribe, but h runs in a goroutine of its own, for work such as
+// sending mail that the emitter should not wait for.  Its error is only
+// logged.
+func (b *Bus) SubscribeAsync(pattern string, h Handler) func() {
+	return b.subscribe(pattern, h, true)
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// Return a Handler calling fn, a func(P), func(context.Context, P) or
+// either returning an error, with the payload of the events whose payload
+// is a P.  It panics if fn is not such a func.
+func typed(fn interface{}) Handler {
+	fv := reflect.ValueOf(fn)
+	ft := fv.Type()
+	if ft.Kind() != reflect.Func {
+		panic("events: handler is not a func")
+	}
+
+	withCtx := ft.NumIn() == 2 && ft.In(0) == contextType
+	if !withCtx && ft.NumIn() != 1 || ft.NumOut() > 1 || ft.NumOut() == 1 && ft.Out(0) != errorType {
+		panic("events: handler must be a func(P) or func(context.Context, P), returning nothing or an error")
+	}
+
+	pt := ft.In(ft.NumIn() - 1)
+
+	return func(ctx context.Context, e *Event) error {
+		pv := reflect.ValueOf(e.Payload)
+		if !pv.IsValid() {
+			switch pt.Kind() {
+			case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+				pv = reflect.Zero(pt)
+			default:
+				return nil
+			}
+		}
+
+		if !pv.Type().AssignableTo(pt) {
+			return nil
+		}
+
+		args := []reflect.Value{pv}
+		if withCtx {
+			args = []reflect.Value{reflect.ValueOf(ctx), pv}
+		}
+
+		out := fv.Call(args)
+		if len(out) == 1 && !out[0].IsNil() {
+			return out[0].Interface().(error)
+		}
+
+		return nil
+	}
+}
+
+// Subscribe a typed handler fn, such as func(ctx context.Context, u
+// *User) error, to pattern.  It is only called for events whose payload
+// is of its type, e.g. Emit("user.created", u).
+func (b *Bus) On(pattern string, fn interface{}) func() {
+	return b.Subscribe(pattern, typed(fn))
+}
+
+// Like On, but fn runs in a goroutine of its own, see SubscribeAsync.
+func (b *Bus) OnAsync(pattern string, fn interface{}) func() {
+	return b.SubscribeAsync(pattern, typed(fn))
+}
+
+// Run h for e, turning a panic into a *PanicError.
+func call(ctx context.Context, h Handler, e *Event) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{v, debug.Stack()}
+		}
+	}()
+
+	return h(ctx, e)
+}
+
+func (b *Bus) failed(ctx context.Context, e *Event, err error) {
+	l := log.FromContext(ctx).WithFields(log.Fields{"event": e.Name})
+	if pe, ok := err.(*PanicError); ok {
+		l = l.WithFields(log.Fields{"stack": string(pe.Stack)})
+	}
+
+	l.Error("<events.Bus.Emit> ", err)
+	if b.OnError != nil {
+		b.OnError(e, err)
+	}
+}
+
+// Emit with the background context.
+func (b *Bus) Emit(name string, payload interface{}) error {
+	return b.EmitCtx(context.Background(), name, payload)
+}
+
+// Dispatch an event to its handlers, in the order they subscribed: the
+// synchronous ones are called in turn and the asynchronous ones started.
+// A handler that fails or panics is logged and does not stop the others;
+// the first error of a synchronous handler is returned.  Asynchronous
+// handlers get a context without the deadline or cancellation of ctx,
+// which ends with the request, but with its values.
+func (b *Bus) EmitCtx(ctx context.Context, name string, payload interface{}) error {
+	e := &Event{Name: name, Payload: payload, Time: time.Now()}
+	b.mutex.RLock()
+	subs := make([]*subscription, 0, len(b.subs))
+	for _, s := range b.subs {
+		if matches(s.pattern, name) {
+			subs = append(subs, s)
+		}
+	}
+	b.mutex.RUnlock()
+
+	var first error
+	for _, s := range subs {
+		if s.async {
+			b.wg.Add(1)
+			go func(h Handler) {
+				defer b.wg.Done()
+				actx := detached{ctx}
+				if err := call(actx, h, e); err != nil {
+					b.failed(actx, e, err)
+				}
+			}(s.handler)
+
+			continue
+		}
+
+		if err := call(ctx, s.handler, e); err != nil {
+			b.failed(ctx, e, err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	return first
+}
+
+// Wait for the asynchronous handlers started so far to return, e.g. on
+// shutdown.
+func (b *Bus) Wait() {
+	b.wg.Wait()
+}
+
+// A context with the values of its parent but never done.
+type detached struct {
+	context.Context
+}
+
+func (d detached) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (d detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (d detached) Err() error {
+	return nil
+}
+
+func Subscribe(pattern string, h Handler) func() {
+	return Default.Subscribe(pattern, h)
+}
+
+func SubscribeAsync(pattern string, h Handler) func() {
+	return Default.SubscribeAsync(pattern, h)
+}
+
+func On(pattern string, fn interface{}) func() {
+	return Default.On(pattern, fn)
+}
+
+func OnAsync(pattern string, fn interface{}) func() {
+	return Default.OnAsync(pattern, fn)
+}
+
+func Emit(name string, payload interface{}) error {
+	return Default.Emit(name, payload)
+}
+
+func EmitCtx(ctx context.Context, name string, payload interface{}) error {
+	return Default.EmitCtx(ctx, name, payload)
+}
+
+func Wait() {
+	Default.Wait()
+}
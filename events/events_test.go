@@ -0,0 +1,190 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"golanger.com/framework/log"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+func TestMatches(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"user.created", "user.created", true},
+		{"user.*", "user.created", true},
+		{"user.*", "user.profile.changed", true},
+		{"user.*", "user", false},
+		{"user.*", "users.created", false},
+		{"*", "anything", true},
+		{"user.created", "user.deleted", false},
+	}
+
+	for _, c := range cases {
+		if got := matches(c.pattern, c.name); got != c.want {
+			t.Errorf("matches(%s, %s) = %v", c.pattern, c.name, got)
+		}
+	}
+}
+
+func TestEmitOrderAndErrors(t *testing.T) {
+	b := New()
+	var got []string
+	record := func(tag string) Handler {
+		return func(ctx context.Context, e *Event) error {
+			got = append(got, tag+" "+e.Name)
+			return nil
+		}
+	}
+
+	failure := errors.New("failed")
+	var reported []error
+	b.OnError = func(e *Event, err error) { reported = append(reported, err) }
+
+	b.Subscribe("user.*", record("a"))
+	b.Subscribe("user.created", func(ctx context.Context, e *Event) error { return failure })
+	b.Subscribe("user.created", func(ctx context.Context, e *Event) error { panic("boom") })
+	unsubscribe := b.Subscribe("*", record("b"))
+	b.Subscribe("post.*", record("c"))
+
+	if err := b.Emit("user.created", nil); err != failure {
+		t.Errorf("Emit error %v", err)
+	}
+
+	if !reflect.DeepEqual(got, []string{"a user.created", "b user.created"}) {
+		t.Errorf("handlers called %v", got)
+	}
+
+	if len(reported) != 2 || reported[0] != failure {
+		t.Fatalf("OnError given %v", reported)
+	}
+
+	if pe, ok := reported[1].(*PanicError); !ok || pe.Value != "boom" || len(pe.Stack) == 0 {
+		t.Errorf("panic reported as %v", reported[1])
+	}
+
+	got = nil
+	unsubscribe()
+	unsubscribe()
+	b.Emit("user.deleted", nil)
+	if !reflect.DeepEqual(got, []string{"a user.deleted"}) {
+		t.Errorf("after unsubscribe, handlers called %v", got)
+	}
+}
+
+type user struct{ name string }
+
+type key struct{}
+
+func TestOn(t *testing.T) {
+	b := New()
+	var got []string
+	b.On("user.*", func(u *user) {
+		if u == nil {
+			got = append(got, "nil")
+		} else {
+			got = append(got, "ptr "+u.name)
+		}
+	})
+	b.On("user.*", func(ctx context.Context, u user) error {
+		got = append(got, "value "+u.name+" "+ctx.Value(key{}).(string))
+		return nil
+	})
+	b.On("user.*", func(n int) error { return errors.New("called for another type") })
+
+	ctx := context.WithValue(context.Background(), key{}, "ctx")
+	if err := b.EmitCtx(ctx, "user.created", &user{"joe"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.EmitCtx(ctx, "user.created", user{"ann"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Emit("user.deleted", nil)
+	want := []string{"ptr joe", "value ann ctx", "nil"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("handlers called %v", got)
+	}
+}
+
+func TestOnPanicsForBadFuncs(t *testing.T) {
+	for _, fn := range []interface{}{
+		"not a func",
+		func() {},
+		func(a, b int) {},
+		func(u *user) int { return 0 },
+		func(u *user) (error, error) { return nil, nil },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("no panic for %T", fn)
+				}
+			}()
+
+			New().On("x", fn)
+		}()
+	}
+}
+
+func TestAsync(t *testing.T) {
+	b := New()
+	var mutex sync.Mutex
+	var got []string
+	var reported error
+	b.OnError = func(e *Event, err error) { reported = err }
+
+	release := make(chan struct{})
+	b.SubscribeAsync("mail.*", func(ctx context.Context, e *Event) error {
+		<-release
+		if ctx.Err() != nil || ctx.Value(key{}) != "v" {
+			t.Errorf("async context %v, %v", ctx.Err(), ctx.Value(key{}))
+		}
+
+		mutex.Lock()
+		got = append(got, e.Name)
+		mutex.Unlock()
+
+		return errors.New("smtp down")
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	if err := b.EmitCtx(ctx, "mail.welcome", nil); err != nil {
+		t.Errorf("Emit returned the async error %v", err)
+	}
+
+	cancel()
+	close(release)
+	b.Wait()
+
+	if len(got) != 1 || reported == nil {
+		t.Errorf("async handler ran for %v, reported %v", got, reported)
+	}
+}
+
+func TestOnAsync(t *testing.T) {
+	b := New()
+	done := make(chan string, 1)
+	b.OnAsync("user.created", func(u *user) { done <- u.name })
+	b.Emit("user.created", &user{"joe"})
+
+	select {
+	case name := <-done:
+		if name != "joe" {
+			t.Errorf("handler given %s", name)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("async handler not called")
+	}
+
+	b.Wait()
+}
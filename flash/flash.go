@@ -0,0 +1,243 @@
+package flash
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"golanger.com/framework/cookie"
+	"golanger.com/framework/session"
+	"html/template"
+	"net"
+	"net/http"
+)
+
+// The kinds of message.
+const (
+	Success = "success"
+	Info    = "info"
+	Warning = "warning"
+	Error   = "error"
+)
+
+// The session key under which a flash is carried to the next request, or
+// the cookie name when the request has no session.
+const (
+	SessionKey = "__FLASH"
+	CookieName = "GOLANGER_FLASH"
+)
+
+type contextKey struct{}
+
+type Message struct {
+	Kind string `json:"kind"`
+	Text string `json:"text"`
+}
+
+type payload struct {
+	Messages []Message         `json:"messages,omitempty"`
+	Data     map[string]string `json:"data,omitempty"`
+}
+
+// A Flash carries messages, such as "Profile saved", and other data from
+// one request to the next, typically across a redirect.  What the previous
+// request set is read once and then dropped.
+type Flash struct {
+	current payload
+	next    payload
+	saved   bool
+}
+
+// Return the flash of the previous request, taken out of the session of r
+// if the session middleware ran or else out of the flash cookie, which is
+// expired.  It must be called before anything is written to w.
+func Load(w http.ResponseWriter, r *http.Request) *Flash {
+	f := &Flash{}
+	var b []byte
+	if s := session.FromRequest(r); s != nil {
+		if v, ok := s.Get(SessionKey).(string); ok {
+			b = []byte(v)
+			s.Delete(SessionKey)
+		}
+	} else if v, err := cookie.Get(r, CookieName); err != http.ErrNoCookie {
+		if err == nil {
+			b = []byte(v)
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: CookieName, Path: "/", MaxAge: -1})
+	}
+
+	if b != nil {
+		json.Unmarshal(b, &f.current)
+	}
+
+	return f
+}
+
+// Store what was set for the next request, once, in the session of r or a
+// cookie, signed once cookie.Configure has been given keys.  It must be
+// called before the response headers are written; Middleware does so
+// itself.
+func (f *Flash) Save(w http.ResponseWriter, r *http.Request) error {
+	if f.saved {
+		return nil
+	}
+
+	f.saved = true
+	if len(f.next.Messages) == 0 && len(f.next.Data) == 0 {
+		return nil
+	}
+
+	b, err := json.Marshal(f.next)
+	if err != nil {
+		return err
+	}
+
+	if s := session.FromRequest(r); s != nil {
+		s.Set(SessionKey, string(b))
+		return nil
+	}
+
+	return cookie.Set(w, &http.Cookie{
+		Name:     CookieName,
+		Value:    string(b),
+		Path:     "/",
+		HttpOnly: true,
+	})
+}
+
+// Add a message for the next request.
+func (f *Flash) Add(kind, text string) {
+	f.next.Messages = append(f.next.Messages, Message{kind, text})
+}
+
+// Add a message for this request, to be rendered now.
+func (f *Flash) Now(kind, text string) {
+	f.current.Messages = append(f.current.Messages, Message{kind, text})
+}
+
+func (f *Flash) Success(text string) {
+	f.Add(Success, text)
+}
+
+func (f *Flash) Info(text string) {
+	f.Add(Info, text)
+}
+
+func (f *Flash) Warning(text string) {
+	f.Add(Warning, text)
+}
+
+func (f *Flash) Error(text string) {
+	f.Add(Error, text)
+}
+
+// Return the messages for this request of the given kinds, or of every
+// kind if none is given, in the order they were added.
+func (f *Flash) Messages(kinds ...string) []Message {
+	if f == nil {
+		return nil
+	}
+
+	msgs := []Message{}
+	for _, m := range f.current.Messages {
+		if len(kinds) == 0 {
+			msgs = append(msgs, m)
+			continue
+		}
+
+		for _, kind := range kinds {
+			if m.Kind == kind {
+				msgs = append(msgs, m)
+				break
+			}
+		}
+	}
+
+	return msgs
+}
+
+// Carry a value to the next request, alongside the messages.  This is how
+// a Validation keeps its errors across a redirect.
+func (f *Flash) SetData(key, value string) {
+	if f.next.Data == nil {
+		f.next.Data = map[string]string{}
+	}
+
+	f.next.Data[key] = value
+}
+
+// Return the value the previous request set for key, or "".
+func (f *Flash) Data(key string) string {
+	if f == nil {
+		return ""
+	}
+
+	return f.current.Data[key]
+}
+
+// Render the messages for this request, e.g. {{flashMessages .Flash}}, as
+// <div class="flash flash-success"> elements.
+func HTML(f *Flash) template.HTML {
+	html := ""
+	for _, m := range f.Messages() {
+		html += `<div class="flash flash-` + template.HTMLEscapeString(m.Kind) + `">` +
+			template.HTMLEscapeString(m.Text) + `</div>`
+	}
+
+	return template.HTML(html)
+}
+
+// Return the flash that Middleware attached to the request, or nil.
+func FromRequest(r *http.Request) *Flash {
+	f, _ := r.Context().Value(contextKey{}).(*Flash)
+	return f
+}
+
+// A ResponseWriter that saves the flash before the headers go out.
+type saveWriter struct {
+	http.ResponseWriter
+	flash   *Flash
+	request *http.Request
+}
+
+func (w *saveWriter) WriteHeader(code int) {
+	w.flash.Save(w.ResponseWriter, w.request)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *saveWriter) Write(b []byte) (int, error) {
+	w.flash.Save(w.ResponseWriter, w.request)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *saveWriter) Flush() {
+	w.flash.Save(w.ResponseWriter, w.request)
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *saveWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.flash.Save(w.ResponseWriter, w.request)
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("flash: ResponseWriter does not support Hijack")
+	}
+
+	return h.Hijack()
+}
+
+// Return a middleware that loads the flash of each request, makes it
+// available through FromRequest, and saves it just before the response
+// headers are written.  It must run inside the session middleware, if
+// any, so the flash is stored before the session is.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := Load(w, r)
+		r = r.WithContext(context.WithValue(r.Context(), contextKey{}, f))
+		sw := &saveWriter{ResponseWriter: w, flash: f, request: r}
+		next.ServeHTTP(sw, r)
+		f.Save(w, r)
+	})
+}
@@ -0,0 +1,142 @@
+package flash
+
+import (
+	"golanger.com/framework/session"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Serve a request carrying the cookies of prev, if any, through h and
+// return the response.
+func serve(h http.Handler, prev *httptest.ResponseRecorder) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/", nil)
+	if prev != nil {
+		for _, ck := range prev.Result().Cookies() {
+			if ck.MaxAge >= 0 {
+				r.AddCookie(ck)
+			}
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestCookieRoundTrip(t *testing.T) {
+	var got []Message
+	var data string
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := FromRequest(r)
+		got, data = f.Messages(), f.Data("form")
+		if r.URL.Query().Get("set") == "" {
+			f.Success("Saved")
+			f.Error("But <slowly>")
+			f.SetData("form", "kept")
+		}
+
+		w.Write([]byte("ok"))
+	}))
+
+	first := serve(h, nil)
+	if len(got) != 0 || data != "" {
+		t.Errorf("first request read %v, %q", got, data)
+	}
+
+	second := serve(h, first)
+	if len(got) != 2 || got[0] != (Message{Success, "Saved"}) || got[1].Kind != Error || data != "kept" {
+		t.Errorf("second request read %v, %q", got, data)
+	}
+
+	expired := false
+	for _, ck := range second.Result().Cookies() {
+		if ck.Name == CookieName && ck.MaxAge < 0 {
+			expired = true
+		}
+	}
+
+	if !expired {
+		t.Error("flash cookie not expired once read")
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	var got []Message
+	m := session.NewManager(session.NewMemoryStore(), "sid", time.Hour)
+	h := m.Middleware(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f := FromRequest(r)
+		got = f.Messages()
+		if len(got) == 0 {
+			f.Info("Welcome")
+		}
+	})))
+
+	first := serve(h, nil)
+	for _, ck := range first.Result().Cookies() {
+		if ck.Name == CookieName {
+			t.Error("flash cookie set with a session")
+		}
+	}
+
+	second := serve(h, first)
+	if len(got) != 1 || got[0].Text != "Welcome" {
+		t.Errorf("second request read %v", got)
+	}
+
+	// Dropped once read.
+	serve(h, second)
+	if len(got) != 0 {
+		t.Errorf("third request read %v", got)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	f := &Flash{}
+	f.Now(Info, "a")
+	f.Now(Warning, "b")
+	f.Now(Info, "c")
+	f.Add(Info, "next")
+
+	if got := f.Messages(Info); len(got) != 2 || got[0].Text != "a" || got[1].Text != "c" {
+		t.Errorf("Messages(Info) = %v", got)
+	}
+
+	if got := f.Messages(Warning, Error); len(got) != 1 {
+		t.Errorf("Messages(Warning, Error) = %v", got)
+	}
+
+	if got := f.Messages(); len(got) != 3 {
+		t.Errorf("Messages() = %v", got)
+	}
+
+	var none *Flash
+	if none.Messages() != nil || none.Data("x") != "" || HTML(none) != "" {
+		t.Error("nil Flash not empty")
+	}
+}
+
+func TestHTML(t *testing.T) {
+	f := &Flash{}
+	f.Now(Error, "<b>Failed</b>")
+	want := `<div class="flash flash-error">&lt;b&gt;Failed&lt;/b&gt;</div>`
+	if got := string(HTML(f)); got != want {
+		t.Errorf("HTML = %s, want %s", got, want)
+	}
+}
+
+func TestSaveOnce(t *testing.T) {
+	f := &Flash{}
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	if err := f.Save(w, r); err != nil || len(w.Result().Cookies()) != 0 {
+		t.Errorf("empty flash saved: %v", err)
+	}
+
+	f.Success("late")
+	if f.Save(w, r); len(w.Result().Cookies()) != 0 {
+		t.Error("flash saved twice")
+	}
+}
@@ -0,0 +1,198 @@
+package forms
+
+import (
+	"fmt"
+	"golanger.com/framework/binder"
+	"golanger.com/framework/log"
+	"golanger.com/framework/validator"
+	"html/template"
+	"reflect"
+	"time"
+)
+
+// A FormBuilder renders the inputs of the fields of a struct with the
+// HTML5 attributes equivalent to their `validate` tags, so that the
+// browser enforces the rules ValidateStruct checks, e.g. in a template
+// given {{$f := formFor .User .Validation}}:
+//
+//	{{$f.Label "Email"}} {{$f.Input "Email"}}
+//
+// renders <input type="email" id="Email" name="Email" value="..." required>
+// and, after a failed submission, the error of the field.
+type FormBuilder struct {
+	value      reflect.Value
+	validation *validator.Validation
+	rules      map[string]*validator.FieldRules
+}
+
+// Return a FormBuilder of obj, a struct or pointer to struct whose fields
+// give the values of the inputs, and of v, which gives their errors,
+// labels and scenario, and may be nil.
+func For(obj interface{}, v *validator.Validation) *FormBuilder {
+	b := &FormBuilder{value: reflect.Indirect(reflect.ValueOf(obj)), validation: v}
+	if v == nil {
+		b.validation = &validator.Validation{}
+	}
+
+	rules, err := b.validation.StructRules(obj)
+	if err != nil {
+		log.Error("<forms.For> ", err)
+		rules = map[string]*validator.FieldRules{}
+	}
+
+	b.rules = rules
+
+	return b
+}
+
+// Return the struct field of a key, and its value.
+func (b *FormBuilder) field(key string) (reflect.StructField, reflect.Value, bool) {
+	if b.value.Kind() != reflect.Struct {
+		return reflect.StructField{}, reflect.Value{}, false
+	}
+
+	sf, ok := b.value.Type().FieldByName(key)
+	if !ok || sf.PkgPath != "" {
+		return sf, reflect.Value{}, false
+	}
+
+	return sf, b.value.FieldByIndex(sf.Index), true
+}
+
+// Render the <label> of the input of a field, by its label (see
+// validator.Validation.Label and the `label` tag).
+func (b *FormBuilder) Label(key string) template.HTML {
+	label := b.validation.Label(key)
+	if sf, _, ok := b.field(key); ok && label == key && sf.Tag.Get("label") != "" {
+		label = sf.Tag.Get("label")
+	}
+
+	return template.HTML(`<label for="` + template.HTMLEscapeString(key) + `">` + template.HTMLEscapeString(label) + "</label>")
+}
+
+// Render the input of a field of the struct, named as binder.Bind expects,
+// holding its value and with the HTML5 attributes of its rules, followed by
+// its error as <span class="error"> if it has one.  pairs of attribute
+// names and values override them, e.g. {{$f.Input "Pass" "type" "password"}}.
+// The type is that of the rules, or else number for numbers, checkbox for
+// booleans, date for times and text otherwise.  A password input gets no
+// value.
+func (b *FormBuilder) Input(key string, pairs ...string) template.HTML {
+	m := b.attrs(key, pairs)
+	html := ""
+	switch m["type"] {
+	case "textarea":
+		value := m["value"]
+		delete(m, "type")
+		delete(m, "value")
+		html = "<textarea" + attrs(m) + ">" + template.HTMLEscapeString(value) + "</textarea>"
+	case "checkbox":
+		if m["value"] == "true" {
+			m["checked"] = "checked"
+		}
+
+		m["value"] = "true"
+		html = "<input" + attrs(m) + ">"
+	case "password":
+		delete(m, "value")
+		fallthrough
+	default:
+		html = "<input" + attrs(m) + ">"
+	}
+
+	if err := b.validation.ErrorMap()[key]; err != nil {
+		html += `<span class="error">` + template.HTMLEscapeString(err.Message) + "</span>"
+	}
+
+	return template.HTML(html)
+}
+
+// Render a <textarea> for a field, as Input does.
+func (b *FormBuilder) TextArea(key string, pairs ...string) template.HTML {
+	return b.Input(key, append([]string{"type", "textarea"}, pairs...)...)
+}
+
+// Render a honeypot input named name, to be declared as a field with the
+// honeypot tag, e.g. Website string `validate:"honeypot"`.
+func (b *FormBuilder) Honeypot(name string) template.HTML {
+	return honeypotHTML(name)
+}
+
+// Render the hidden input of the time the form is rendered at, to be
+// declared as a field with the mintime tag and the `form` name of
+// validator.SubmitTimeField, e.g.
+// FormTime string `form:"form_time" validate:"mintime=3s"`.
+func (b *FormBuilder) SubmitTime() template.HTML {
+	return submitTimeHTML()
+}
+
+func (b *FormBuilder) attrs(key string, extra []string) map[string]string {
+	m := map[string]string{"id": key, "name": key, "type": "text"}
+	sf, f, ok := b.field(key)
+	if ok {
+		m["name"] = binder.FieldName(sf)
+		m["value"] = inputValue(f)
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch {
+		case ft == reflect.TypeOf(time.Time{}):
+			m["type"] = "date"
+		case ft.Kind() == reflect.Bool:
+			m["type"] = "checkbox"
+		case isNumber(ft.Kind()):
+			m["type"] = "number"
+		}
+	}
+
+	if fr := b.rules[key]; fr != nil {
+		for k, v := range fr.Attrs {
+			m[k] = v
+		}
+	}
+
+	if _, ok := b.validation.ErrorMap()[key]; ok {
+		m["aria-invalid"] = "true"
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		m[extra[i]] = extra[i+1]
+	}
+
+	return m
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// Format the value of a field for an input: times as dates, and zero
+// times and nil pointers as nothing.
+func inputValue(f reflect.Value) string {
+	for f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return ""
+		}
+
+		f = f.Elem()
+	}
+
+	if t, ok := f.Interface().(time.Time); ok {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.Format("2006-01-02")
+	}
+
+	return fmt.Sprint(f.Interface())
+}
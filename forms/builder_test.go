@@ -0,0 +1,83 @@
+package forms
+
+import (
+	"golanger.com/framework/validator"
+	"strings"
+	"testing"
+	"time"
+)
+
+type profile struct {
+	Name     string `validate:"required,max=40" label:"Full name"`
+	Email    string `form:"email" validate:"email"`
+	Age      int    `validate:"min=18"`
+	Born     *time.Time
+	Joined   time.Time
+	Admin    bool
+	Bio      string `validate:"max=200"`
+	Password string
+	secret   string
+}
+
+func TestBuilderInput(t *testing.T) {
+	born := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	b := For(&profile{Name: `Jo "J"`, Email: "jo@example.com", Age: 30, Born: &born, Admin: true, Password: "hunter2"}, nil)
+
+	cases := map[string]string{
+		"Name":     `<input id="Name" maxlength="40" name="Name" required="required" type="text" value="Jo &#34;J&#34;">`,
+		"Email":    `<input id="Email" name="email" type="email" value="jo@example.com">`,
+		"Age":      `<input id="Age" min="18" name="Age" type="number" value="30">`,
+		"Born":     `<input id="Born" name="Born" type="date" value="1990-05-17">`,
+		"Joined":   `<input id="Joined" name="Joined" type="date" value="">`,
+		"Admin":    `<input checked="checked" id="Admin" name="Admin" type="checkbox" value="true">`,
+		"Password": `<input id="Password" name="Password" type="password">`,
+		"secret":   `<input id="secret" name="secret" type="text">`,
+	}
+
+	for key, want := range cases {
+		pairs := []string{}
+		if key == "Password" {
+			pairs = []string{"type", "password"}
+		}
+
+		if got := string(b.Input(key, pairs...)); got != want {
+			t.Errorf("Input(%s) = %s, want %s", key, got, want)
+		}
+	}
+
+	if got := string(b.TextArea("Bio", "rows", "3")); got != `<textarea id="Bio" maxlength="200" name="Bio" rows="3"></textarea>` {
+		t.Errorf("TextArea = %s", got)
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	p := profile{Age: 12}
+	v := &validator.Validation{}
+	v.ValidateStruct(p)
+
+	b := For(p, v)
+	got := string(b.Input("Age", "class", "wide"))
+	if !strings.Contains(got, `aria-invalid="true"`) || !strings.Contains(got, `class="wide"`) || !strings.HasSuffix(got, `<span class="error">Minimum is 18</span>`) {
+		t.Errorf("Input = %s", got)
+	}
+
+	if strings.Contains(string(b.Input("Admin")), "error") {
+		t.Error("error rendered for a valid field")
+	}
+}
+
+func TestBuilderLabel(t *testing.T) {
+	b := For(profile{}, nil)
+	if got := string(b.Label("Name")); got != `<label for="Name">Full name</label>` {
+		t.Errorf("Label = %s", got)
+	}
+
+	v := (&validator.Validation{}).SetLabel("Age", "Your age")
+	if got := string(For(profile{}, v).Label("Age")); got != `<label for="Age">Your age</label>` {
+		t.Errorf("Label = %s", got)
+	}
+
+	if got := string(For(nil, nil).Input("Name")); got != `<input id="Name" name="Name" type="text">` {
+		t.Errorf("Input without a struct = %s", got)
+	}
+}
@@ -0,0 +1,323 @@
+package forms
+
+import (
+	"golanger.com/framework/binder"
+	"golanger.com/framework/csrf"
+	"golanger.com/framework/log"
+	"golanger.com/framework/validator"
+	"html/template"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Field of a Form: how it is rendered, and the validators its submitted
+// value must satisfy.  The value is checked as an int or float64 for
+// "number" fields, a bool for checkboxes, and a string otherwise.
+type Field struct {
+	Name       string
+	Label      string
+	Type       string
+	Choices    []string
+	Validators []validator.Validator
+	Attrs      map[string]string
+
+	Value string
+	Error *validator.ValidationError
+}
+
+func newField(typ, name, label string, checks []validator.Validator) *Field {
+	return &Field{
+		Name:       name,
+		Label:      label,
+		Type:       typ,
+		Validators: checks,
+		Attrs:      map[string]string{},
+	}
+}
+
+func Text(name, label string, checks ...validator.Validator) *Field {
+	return newField("text", name, label, checks)
+}
+
+func Email(name, label string, checks ...validator.Validator) *Field {
+	return newField("email", name, label, append([]validator.Validator{validator.NewEmail()}, checks...))
+}
+
+func Password(name, label string, checks ...validator.Validator) *Field {
+	return newField("password", name, label, checks)
+}
+
+func Number(name, label string, checks ...validator.Validator) *Field {
+	return newField("number", name, label, checks)
+}
+
+func Hidden(name string, checks ...validator.Validator) *Field {
+	return newField("hidden", name, "", checks)
+}
+
+func TextArea(name, label string, checks ...validator.Validator) *Field {
+	return newField("textarea", name, label, checks)
+}
+
+func Checkbox(name, label string, checks ...validator.Validator) *Field {
+	return newField("checkbox", name, label, checks)
+}
+
+// A honeypot field, hidden from people, whose submission is rejected if a
+// bot fills it in (see validator.Honeypot).
+func Honeypot(name string) *Field {
+	return newField("honeypot", name, "", []validator.Validator{validator.Honeypot{FieldName: name}})
+}
+
+// A hidden field stamped with the time the form is rendered at, whose
+// submission is rejected if it comes back sooner than min (see
+// validator.MinSubmitTime).
+func SubmitTime(min time.Duration) *Field {
+	return newField("submittime", validator.SubmitTimeField, "", []validator.Validator{validator.MinSubmitTime{Duration: min}})
+}
+
+func Select(name, label string, choices []string, checks ...validator.Validator) *Field {
+	f := newField("select", name, label, checks)
+	f.Choices = choices
+
+	return f
+}
+
+// Set an HTML attribute of the input, such as placeholder or class.
+func (f *Field) Attr(name, value string) *Field {
+	f.Attrs[name] = value
+	return f
+}
+
+// Return the value in the type the validators see.
+func (f *Field) typedValue() interface{} {
+	switch f.Type {
+	case "number":
+		if f.Value == "" {
+			return ""
+		}
+
+		if n, err := strconv.Atoi(f.Value); err == nil {
+			return n
+		}
+
+		if n, err := strconv.ParseFloat(f.Value, 64); err == nil {
+			return n
+		}
+	case "checkbox":
+		return f.Value != "" && f.Value != "0" && f.Value != "false"
+	}
+
+	return f.Value
+}
+
+// A Form is declared once and then both renders its fields and handles
+// their submission.  It holds the values of one request, so build a new
+// one for each request, e.g. from a function.
+type Form struct {
+	Action     string
+	Method     string
+	Fields     []*Field
+	Validation *validator.Validation
+	request    *http.Request
+}
+
+func New(fields ...*Field) *Form {
+	return &Form{
+		Method:     "POST",
+		Fields:     fields,
+		Validation: &validator.Validation{},
+	}
+}
+
+func (f *Form) Field(name string) *Field {
+	for _, field := range f.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// Fill the fields from the request and validate them when the request is a
+// submission of the form.  Report whether it was submitted and valid.
+func (f *Form) Handle(r *http.Request) bool {
+	f.request = r
+	if r.Method != strings.ToUpper(f.Method) {
+		return false
+	}
+
+	r.ParseMultipartForm(binder.MaxMemory)
+	f.Validation.Clear()
+	for _, field := range f.Fields {
+		field.Value = r.FormValue(field.Name)
+		field.Error = nil
+		if len(field.Validators) == 0 {
+			continue
+		}
+
+		if field.Type == "number" && field.Value != "" {
+			if _, err := strconv.ParseFloat(field.Value, 64); err != nil {
+				field.Error = f.Validation.Error("Must be a number").Key(field.Name).Code("validation.type").Error
+				continue
+			}
+		}
+
+		if result := f.Validation.CheckKey(field.Name, field.typedValue(), field.Validators...); !result.Ok {
+			field.Error = result.Error
+		}
+	}
+
+	return !f.Validation.HasErrors()
+}
+
+func (f *Form) Valid() bool {
+	return !f.Validation.HasErrors()
+}
+
+// Copy the field values into the struct pointed to by dst, matching them
+// as binder.Bind does, and record conversion errors under the field name.
+func (f *Form) Bind(dst interface{}) *validator.Validation {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		f.Validation.Error("Bind target must be a pointer to a struct").Key("")
+		return f.Validation
+	}
+
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		sf := rv.Type().Field(i)
+		field := f.Field(binder.FieldName(sf))
+		if field == nil || sf.PkgPath != "" {
+			continue
+		}
+
+		if err := binder.SetValue(rv.Field(i), []string{field.Value}); err != nil {
+			f.Validation.Error("%s", err).Key(field.Name).Code("validation.type")
+		}
+	}
+
+	return f.Validation
+}
+
+func attrs(m map[string]string) string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+	s := ""
+	for _, k := range names {
+		s += " " + template.HTMLEscapeString(k) + `="` + template.HTMLEscapeString(m[k]) + `"`
+	}
+
+	return s
+}
+
+// Return the attributes of the input: those set with Attr, over the HTML5
+// attributes equivalent to its validators (see validator.HTML5Attrs).
+func (f *Field) attrs() string {
+	m := map[string]string{}
+	for k, v := range validator.HTML5Attrs(f.Validators...) {
+		if k == "required" || (k != "type" && f.Type != "select" && f.Type != "checkbox") {
+			m[k] = v
+		}
+	}
+
+	for k, v := range f.Attrs {
+		m[k] = v
+	}
+
+	return attrs(m)
+}
+
+// Render the label, the input and, after a failed submission, the error
+// message as <span class="error">.
+func (f *Field) Render() template.HTML {
+	name := template.HTMLEscapeString(f.Name)
+	value := template.HTMLEscapeString(f.Value)
+	html := ""
+	if f.Label != "" && f.Type != "hidden" {
+		html += `<label for="` + name + `">` + template.HTMLEscapeString(f.Label) + "</label>"
+	}
+
+	switch f.Type {
+	case "textarea":
+		html += `<textarea id="` + name + `" name="` + name + `"` + f.attrs() + ">" + value + "</textarea>"
+	case "select":
+		html += `<select id="` + name + `" name="` + name + `"` + f.attrs() + ">"
+		for _, choice := range f.Choices {
+			selected := ""
+			if choice == f.Value {
+				selected = " selected"
+			}
+
+			choice = template.HTMLEscapeString(choice)
+			html += `<option value="` + choice + `"` + selected + ">" + choice + "</option>"
+		}
+
+		html += "</select>"
+	case "checkbox":
+		checked := ""
+		if v, _ := f.typedValue().(bool); v {
+			checked = " checked"
+		}
+
+		html += `<input type="checkbox" id="` + name + `" name="` + name + `" value="1"` + checked + f.attrs() + ">"
+	case "password":
+		html += `<input type="password" id="` + name + `" name="` + name + `"` + f.attrs() + ">"
+	case "honeypot":
+		return honeypotHTML(f.Name)
+	case "submittime":
+		return submitTimeHTML()
+	default:
+		html += `<input type="` + template.HTMLEscapeString(f.Type) + `" id="` + name + `" name="` + name + `" value="` + value + `"` + f.attrs() + ">"
+	}
+
+	if f.Error != nil {
+		html += `<span class="error">` + template.HTMLEscapeString(f.Error.Message) + "</span>"
+	}
+
+	return template.HTML(html)
+}
+
+// Render the whole form, with the CSRF field when the request handled last
+// went through the csrf middleware.
+func (f *Form) Render() template.HTML {
+	html := `<form action="` + template.HTMLEscapeString(f.Action) + `" method="` + template.HTMLEscapeString(strings.ToLower(f.Method)) + `">`
+	if f.request != nil {
+		html += string(csrf.TemplateField(f.request))
+	}
+
+	for _, field := range f.Fields {
+		html += "<div>" + string(field.Render()) + "</div>"
+	}
+
+	return template.HTML(html + "</form>")
+}
+
+// Render a honeypot input, moved off screen rather than hidden, which bots
+// would notice, and out of reach of the keyboard and screen readers.
+func honeypotHTML(name string) template.HTML {
+	name = template.HTMLEscapeString(name)
+
+	return template.HTML(`<div style="position:absolute;left:-10000px" aria-hidden="true">` +
+		`<input type="text" name="` + name + `" value="" tabindex="-1" autocomplete="off"></div>`)
+}
+
+// Render the hidden input of a fresh stamp of validator.SubmitTimeStamp.
+func submitTimeHTML() template.HTML {
+	stamp, err := validator.SubmitTimeStamp(nil)
+	if err != nil {
+		log.Error("<forms.SubmitTime> ", err)
+		return ""
+	}
+
+	return template.HTML(`<input type="hidden" name="` + template.HTMLEscapeString(validator.SubmitTimeField) + `" value="` + template.HTMLEscapeString(stamp) + `">`)
+}
@@ -0,0 +1,195 @@
+package forms
+
+import (
+	"golanger.com/framework/cookie"
+	"golanger.com/framework/validator"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signupForm() *Form {
+	return New(
+		Text("name", "Name", validator.Required{}),
+		Email("email", "Email"),
+		Number("age", "Age", validator.Min{Min: 18}),
+		Checkbox("terms", "Terms", validator.Required{}),
+		Select("plan", "Plan", []string{"free", "pro"}),
+		Hidden("ref"),
+	)
+}
+
+func submit(f *Form, form url.Values) bool {
+	r := httptest.NewRequest("POST", "/signup", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return f.Handle(r)
+}
+
+func TestHandleValid(t *testing.T) {
+	f := signupForm()
+	if !submit(f, url.Values{"name": {"Joe"}, "email": {"joe@example.com"}, "age": {"30"}, "terms": {"1"}, "plan": {"pro"}}) {
+		t.Fatalf("errors %v", f.Validation.ErrorMap())
+	}
+
+	if !f.Valid() || f.Field("name").Value != "Joe" || f.Field("plan").Value != "pro" || f.Field("nosuch") != nil {
+		t.Errorf("fields %+v", f.Fields)
+	}
+}
+
+func TestHandleInvalid(t *testing.T) {
+	f := signupForm()
+	if submit(f, url.Values{"email": {"joe"}, "age": {"12"}, "terms": {"false"}}) {
+		t.Fatal("invalid submission accepted")
+	}
+
+	for _, name := range []string{"name", "email", "age", "terms"} {
+		if f.Field(name).Error == nil {
+			t.Errorf("no error for %s", name)
+		}
+	}
+
+	if f.Field("plan").Error != nil || f.Field("ref").Error != nil {
+		t.Error("errors for fields without validators")
+	}
+
+	// A new submission clears the errors of the previous one.
+	if !submit(f, url.Values{"name": {"Joe"}, "email": {"joe@example.com"}, "age": {"18"}, "terms": {"on"}}) {
+		t.Errorf("errors %v", f.Validation.ErrorMap())
+	}
+
+	if f.Field("name").Error != nil {
+		t.Error("error kept from the previous submission")
+	}
+}
+
+func TestHandleNumber(t *testing.T) {
+	f := New(Number("age", "Age", validator.Min{Min: 18}), Number("price", "Price", validator.Required{}))
+	submit(f, url.Values{"age": {"abc"}, "price": {"9.99"}})
+	if e := f.Field("age").Error; e == nil || e.Message != "Must be a number" || e.Code != "validation.type" {
+		t.Errorf("error for age %v", e)
+	}
+
+	if f.Field("price").Error != nil {
+		t.Errorf("error for a float price %v", f.Field("price").Error)
+	}
+}
+
+func TestHandleMethod(t *testing.T) {
+	f := signupForm()
+	if f.Handle(httptest.NewRequest("GET", "/signup?name=Joe", nil)) || f.Field("name").Value != "" {
+		t.Error("GET handled as a submission")
+	}
+
+	f = New(Text("q", "Search", validator.Required{}))
+	f.Method = "get"
+	if !f.Handle(httptest.NewRequest("GET", "/search?q=go", nil)) || f.Field("q").Value != "go" {
+		t.Error("GET form not handled")
+	}
+}
+
+func TestBind(t *testing.T) {
+	var s struct {
+		Name  string `form:"name"`
+		Age   int    `form:"age"`
+		Terms bool   `form:"terms"`
+		Other string
+	}
+
+	f := New(Text("name", "Name"), Text("age", "Age"), Checkbox("terms", "Terms"))
+	submit(f, url.Values{"name": {"Joe"}, "age": {"30"}, "terms": {"on"}, "Other": {"x"}})
+	if v := f.Bind(&s); v.HasErrors() {
+		t.Fatalf("errors %v", v.ErrorMap())
+	}
+
+	if s.Name != "Joe" || s.Age != 30 || !s.Terms || s.Other != "" {
+		t.Errorf("bound %+v", s)
+	}
+
+	submit(f, url.Values{"age": {"old"}})
+	if e := f.Bind(&s).ErrorMap()["age"]; e == nil || e.Message != "Must be an integer" {
+		t.Errorf("error for age %v", e)
+	}
+
+	if !New().Bind(s).HasErrors() {
+		t.Error("no error binding a struct value")
+	}
+}
+
+func TestFieldRender(t *testing.T) {
+	f := signupForm()
+	submit(f, url.Values{"name": {`<Joe & "Co">`}, "plan": {"pro"}, "terms": {"1"}})
+
+	cases := map[string]string{
+		"name":  `<label for="name">Name</label><input type="text" id="name" name="name" value="&lt;Joe &amp; &#34;Co&#34;&gt;" required="required">`,
+		"plan":  `<select id="plan" name="plan"><option value="free">free</option><option value="pro" selected>pro</option></select>`,
+		"terms": `<input type="checkbox" id="terms" name="terms" value="1" checked required="required">`,
+		"ref":   `<input type="hidden" id="ref" name="ref" value="">`,
+		"age":   `<span class="error">Minimum is 18</span>`,
+	}
+
+	for name, want := range cases {
+		if got := string(f.Field(name).Render()); !strings.Contains(got, want) {
+			t.Errorf("%s rendered %s, want %s", name, got, want)
+		}
+	}
+
+	p := Password("password", "Password").Attr("placeholder", `"secret"`)
+	p.Value = "hunter2"
+	if got := string(p.Render()); strings.Contains(got, "hunter2") || !strings.Contains(got, `placeholder="&#34;secret&#34;"`) {
+		t.Errorf("password rendered %s", got)
+	}
+
+	area := TextArea("bio", "Bio")
+	area.Value = "</textarea>"
+	if got := string(area.Render()); !strings.Contains(got, ">&lt;/textarea&gt;</textarea>") {
+		t.Errorf("textarea rendered %s", got)
+	}
+}
+
+func TestFormRender(t *testing.T) {
+	f := New(Text("name", "Name"))
+	f.Action = "/signup?next=/"
+	got := string(f.Render())
+	want := `<form action="/signup?next=/" method="post"><div><label for="name">Name</label><input type="text" id="name" name="name" value=""></div></form>`
+	if got != want {
+		t.Errorf("rendered %s, want %s", got, want)
+	}
+}
+
+func TestAntispamFields(t *testing.T) {
+	defer func(codec *cookie.Codec) { cookie.Default = codec }(cookie.Default)
+	cookie.Configure(cookie.Keys{Hash: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}})
+
+	f := New(Text("name", "Name"), Honeypot("website"), SubmitTime(0))
+	html := string(f.Render())
+	if !strings.Contains(html, `<input type="text" name="website" value="" tabindex="-1" autocomplete="off">`) || !strings.Contains(html, `aria-hidden="true"`) {
+		t.Errorf("honeypot not rendered in %s", html)
+	}
+
+	i := strings.Index(html, `name="form_time" value="`)
+	if i < 0 {
+		t.Fatalf("submit time not rendered in %s", html)
+	}
+
+	stamp := html[i+len(`name="form_time" value="`):]
+	stamp = stamp[:strings.Index(stamp, `"`)]
+	if !submit(f, url.Values{"name": {"Joe"}, "website": {""}, "form_time": {stamp}}) {
+		t.Errorf("errors %v", f.Validation.ErrorMap())
+	}
+
+	if submit(f, url.Values{"name": {"Joe"}, "website": {"http://spam.example"}, "form_time": {stamp}}) {
+		t.Error("filled honeypot accepted")
+	}
+
+	if submit(f, url.Values{"name": {"Joe"}}) {
+		t.Error("missing submit time accepted")
+	}
+
+	slow := New(SubmitTime(time.Hour))
+	if submit(slow, url.Values{"form_time": {stamp}}) {
+		t.Error("fast submission accepted")
+	}
+}
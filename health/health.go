@@ -0,0 +1,159 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// The timeout of checks that set none.
+var DefaultTimeout = 2 * time.Second
+
+// A Check is one dependency to probe, such as a database or a cache.
+type Check struct {
+	Name string
+	Func func(ctx context.Context) error
+	// DefaultTimeout if zero.  The context of Func ends after it.
+	Timeout time.Duration
+	// A failing optional check is reported but leaves the status ok, for
+	// dependencies the application can run without.
+	Optional bool
+}
+
+// Return a Check running f.
+func Func(name string, f func(ctx context.Context) error) Check {
+	return Check{Name: name, Func: f}
+}
+
+// Something that can be pinged, such as a cache or queue client.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Return a Check pinging p.
+func Ping(name string, p Pinger) Check {
+	return Check{Name: name, Func: p.Ping}
+}
+
+// Return a Check pinging a database.
+func DB(name string, db *sql.DB) Check {
+	return Check{Name: name, Func: db.PingContext}
+}
+
+// The outcome of one check in the response.
+type Result struct {
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration_ms"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// The body of the response.
+type Report struct {
+	Status string             `json:"status"`
+	Checks map[string]*Result `json:"checks,omitempty"`
+}
+
+// Statuses of a Report and its Results.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+	StatusWarn = "warn"
+)
+
+var errTimeout = errors.New("timed out")
+
+// Run a check, giving up when its timeout ends even if Func does not.
+func run(ctx context.Context, c Check) *Result {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				done <- errors.New("check panicked")
+			}
+		}()
+
+		done <- c.Func(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = errTimeout
+	}
+
+	result := &Result{
+		Status:   StatusOK,
+		Duration: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		result.Status = StatusFail
+		if c.Optional {
+			result.Status = StatusWarn
+		}
+
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Run the checks concurrently and report on them.  The status is fail if
+// a check that is not optional failed.
+func Run(ctx context.Context, checks ...Check) *Report {
+	report := &Report{Status: StatusOK, Checks: map[string]*Result{}}
+	results := make([]*Result, len(checks))
+	done := make(chan struct{})
+	for i, c := range checks {
+		go func(i int, c Check) {
+			results[i] = run(ctx, c)
+			done <- struct{}{}
+		}(i, c)
+	}
+
+	for range checks {
+		<-done
+	}
+
+	for i, c := range checks {
+		report.Checks[c.Name] = results[i]
+		if results[i].Status == StatusFail {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+// Return a handler answering with the JSON Report of the checks: 200 OK if
+// its status is ok, 503 Service Unavailable otherwise, so it can serve as
+// a Kubernetes liveness or readiness probe.  Without checks, it reports
+// that the process is up.
+func Handler(checks ...Check) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := Run(r.Context(), checks...)
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+
+		b, _ := json.Marshal(report)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(status)
+		w.Write(b)
+	})
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type pinger struct{ err error }
+
+func (p pinger) Ping(ctx context.Context) error { return p.err }
+
+func ok(ctx context.Context) error { return nil }
+
+func TestRun(t *testing.T) {
+	report := Run(context.Background(),
+		Func("db", ok),
+		Ping("cache", pinger{errors.New("refused")}),
+		Check{Name: "search", Func: func(ctx context.Context) error { return errors.New("down") }, Optional: true},
+	)
+
+	if report.Status != StatusFail {
+		t.Errorf("status %s", report.Status)
+	}
+
+	want := map[string]string{"db": StatusOK, "cache": StatusFail, "search": StatusWarn}
+	for name, status := range want {
+		if r := report.Checks[name]; r == nil || r.Status != status {
+			t.Errorf("%s: %+v, want %s", name, r, status)
+		}
+	}
+
+	if report.Checks["cache"].Error != "refused" || report.Checks["db"].Error != "" {
+		t.Errorf("errors %+v", report.Checks)
+	}
+
+	report = Run(context.Background(), Func("db", ok), Check{Name: "search", Func: func(ctx context.Context) error { return errors.New("down") }, Optional: true})
+	if report.Status != StatusOK {
+		t.Errorf("status %s with only an optional check failing", report.Status)
+	}
+}
+
+func TestRunTimeoutAndPanic(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+
+	start := time.Now()
+	report := Run(context.Background(),
+		Check{Name: "stuck", Func: func(ctx context.Context) error { <-block; return nil }, Timeout: 20 * time.Millisecond},
+		Check{Name: "ctx", Func: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }, Timeout: 20 * time.Millisecond},
+		Func("panics", func(ctx context.Context) error { panic("boom") }),
+	)
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Run took %v", time.Since(start))
+	}
+
+	if r := report.Checks["stuck"]; r.Status != StatusFail || r.Error != "timed out" {
+		t.Errorf("stuck: %+v", r)
+	}
+
+	if r := report.Checks["ctx"]; r.Status != StatusFail {
+		t.Errorf("ctx: %+v", r)
+	}
+
+	if r := report.Checks["panics"]; r.Status != StatusFail || r.Error != "check panicked" {
+		t.Errorf("panics: %+v", r)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	cases := []struct {
+		checks []Check
+		status int
+	}{
+		{nil, http.StatusOK},
+		{[]Check{Func("db", ok)}, http.StatusOK},
+		{[]Check{Ping("db", pinger{errors.New("refused")})}, http.StatusServiceUnavailable},
+	}
+
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		Handler(c.checks...).ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+		if w.Code != c.status || w.Header().Get("Content-Type") != "application/json; charset=utf-8" ||
+			w.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("%d checks: answered %d, %v", len(c.checks), w.Code, w.Header())
+		}
+
+		var report Report
+		if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil || len(report.Checks) != len(c.checks) {
+			t.Errorf("body %s: %v", w.Body, err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/healthz", nil))
+	if w.Body.String() != `{"status":"ok"}` {
+		t.Errorf("body without checks %s", w.Body)
+	}
+}
@@ -0,0 +1,262 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// The forms of a message by plural category ("zero", "one", "two", "few",
+// "many" and "other"); a message without plural forms has only "other".
+type message map[string]string
+
+// A Bundle holds the messages of every locale and negotiates the locale of
+// requests.  It is safe for concurrent use.
+type Bundle struct {
+	mutex    sync.RWMutex
+	messages map[string]map[string]message
+
+	// The locale of requests that ask for none of the loaded ones, and
+	// whose messages stand in for those missing from other locales.
+	DefaultLocale string
+
+	// The query parameter and cookie that choose a locale explicitly,
+	// before Accept-Language.  Either is ignored if "".
+	QueryParam string
+	CookieName string
+}
+
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		messages:      map[string]map[string]message{},
+		DefaultLocale: Normalize(defaultLocale),
+		QueryParam:    "lang",
+		CookieName:    "lang",
+	}
+}
+
+// The Bundle of the package-level functions, the template funcs of render
+// and the messages of validator.
+var Default = NewBundle("en")
+
+// Return a locale in the form the Bundle keys it by, e.g. "pt-br" for
+// "pt_BR".
+func Normalize(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+// Return the language of a locale, "pt" for "pt-br".
+func base(locale string) string {
+	if i := strings.Index(locale, "-"); i >= 0 {
+		return locale[:i]
+	}
+
+	return locale
+}
+
+// Load the messages of every locale file in dir.  Each file is a JSON
+// object named after its locale (e.g. zh-cn.json) mapping keys to
+// messages, or to their plural forms:
+//
+//	{"welcome": "Welcome, %s!", "items": {"one": "%d item", "other": "%d items"}}
+func (b *Bundle) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		raw := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+
+		locale := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		for key, value := range raw {
+			var text string
+			if err := json.Unmarshal(value, &text); err == nil {
+				b.SetPlural(locale, key, map[string]string{"other": text})
+				continue
+			}
+
+			forms := map[string]string{}
+			if err := json.Unmarshal(value, &forms); err != nil {
+				return fmt.Errorf("%s: %s: a message must be a string or an object of plural forms", file, key)
+			}
+
+			b.SetPlural(locale, key, forms)
+		}
+	}
+
+	return nil
+}
+
+// Add or replace messages of a locale.
+func (b *Bundle) SetMessages(locale string, messages map[string]string) {
+	for key, text := range messages {
+		b.SetPlural(locale, key, map[string]string{"other": text})
+	}
+}
+
+// Add or replace the plural forms of a message of a locale.
+func (b *Bundle) SetPlural(locale, key string, forms map[string]string) {
+	locale = Normalize(locale)
+	m := message{}
+	for category, text := range forms {
+		m[category] = text
+	}
+
+	b.mutex.Lock()
+	if b.messages[locale] == nil {
+		b.messages[locale] = map[string]message{}
+	}
+
+	b.messages[locale][key] = m
+	b.mutex.Unlock()
+}
+
+// Return the loaded locales, sorted.
+func (b *Bundle) Locales() []string {
+	b.mutex.RLock()
+	locales := make([]string, 0, len(b.messages))
+	for locale := range b.messages {
+		locales = append(locales, locale)
+	}
+	b.mutex.RUnlock()
+	sort.Strings(locales)
+
+	return locales
+}
+
+func (b *Bundle) hasLocale(locale string) bool {
+	b.mutex.RLock()
+	_, ok := b.messages[locale]
+	b.mutex.RUnlock()
+
+	return ok
+}
+
+// Return the message of key in locale or else in its language, without
+// falling back to the default locale.
+func (b *Bundle) find(locale, key string) (message, string, bool) {
+	locale = Normalize(locale)
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for _, l := range []string{locale, base(locale)} {
+		if m, ok := b.messages[l][key]; ok {
+			return m, l, true
+		}
+	}
+
+	return nil, "", false
+}
+
+// Return the message of key in locale or its language, unformatted and in
+// its "other" form, and whether there is one.  The default locale is not
+// looked at.
+func (b *Bundle) Lookup(locale, key string) (string, bool) {
+	m, _, ok := b.find(locale, key)
+	if !ok {
+		return "", false
+	}
+
+	return m.form("other"), true
+}
+
+func (m message) form(category string) string {
+	if text, ok := m[category]; ok {
+		return text
+	}
+
+	return m["other"]
+}
+
+// Translate key into locale, falling back to its language, then to the
+// default locale, then to key itself.  A message with plural forms takes
+// the form for the first integer argument.  The message is then formatted
+// with args by fmt.Sprintf, so "Welcome, %s!" with "Ann" gives "Welcome,
+// Ann!".
+func (b *Bundle) T(locale, key string, args ...interface{}) string {
+	m, found, ok := b.find(locale, key)
+	if !ok {
+		if m, found, ok = b.find(b.DefaultLocale, key); !ok {
+			return key
+		}
+	}
+
+	text := m["other"]
+	if len(m) > 1 {
+		for _, arg := range args {
+			if n, ok := toInt(arg); ok {
+				text = m.form(PluralCategory(found, n))
+				break
+			}
+		}
+	}
+
+	if len(args) == 0 || !strings.Contains(text, "%") {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+func toInt(arg interface{}) (int64, bool) {
+	switch n := arg.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		return int64(n), true
+	}
+
+	return 0, false
+}
+
+// Return the template funcs translating into locale:
+//
+//	{{msg "welcome" .Name}}
+func (b *Bundle) Funcs(locale string) template.FuncMap {
+	return template.FuncMap{
+		"msg": func(key string, args ...interface{}) string {
+			return b.T(locale, key, args...)
+		},
+	}
+}
+
+func LoadDir(dir string) error {
+	return Default.LoadDir(dir)
+}
+
+func T(locale, key string, args ...interface{}) string {
+	return Default.T(locale, key, args...)
+}
+
+func Funcs(locale string) template.FuncMap {
+	return Default.Funcs(locale)
+}
@@ -0,0 +1,136 @@
+package i18n
+
+import (
+	"bytes"
+	"html/template"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func bundle() *Bundle {
+	b := NewBundle("en")
+	b.SetMessages("en", map[string]string{"welcome": "Welcome, %s!", "bye": "Bye", "percent": "100%"})
+	b.SetMessages("pt", map[string]string{"welcome": "Bem-vindo, %s!"})
+	b.SetMessages("pt_BR", map[string]string{"bye": "Tchau"})
+	b.SetPlural("en", "items", map[string]string{"one": "%d item", "other": "%d items"})
+	b.SetPlural("ru", "items", map[string]string{"one": "%d предмет", "few": "%d предмета", "many": "%d предметов", "other": "%d предмета"})
+
+	return b
+}
+
+func TestT(t *testing.T) {
+	b := bundle()
+	cases := []struct {
+		locale, key string
+		args        []interface{}
+		want        string
+	}{
+		{"en", "welcome", []interface{}{"Ann"}, "Welcome, Ann!"},
+		{"pt-BR", "welcome", []interface{}{"Ana"}, "Bem-vindo, Ana!"},
+		{"pt-br", "bye", nil, "Tchau"},
+		{"pt", "bye", nil, "Bye"},
+		{"de", "welcome", []interface{}{"Udo"}, "Welcome, Udo!"},
+		{"en", "missing", nil, "missing"},
+		{"en", "percent", nil, "100%"},
+		{"en", "items", []interface{}{1}, "1 item"},
+		{"en", "items", []interface{}{int64(3)}, "3 items"},
+		{"ru", "items", []interface{}{21}, "21 предмет"},
+		{"ru", "items", []interface{}{uint8(3)}, "3 предмета"},
+		{"ru", "items", []interface{}{11}, "11 предметов"},
+		{"ru", "items", []interface{}{"many"}, "%!d(string=many) предмета"},
+	}
+
+	for _, c := range cases {
+		if got := b.T(c.locale, c.key, c.args...); got != c.want {
+			t.Errorf("T(%s, %s, %v) = %q, want %q", c.locale, c.key, c.args, got, c.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	b := bundle()
+	if text, ok := b.Lookup("pt-br", "welcome"); !ok || text != "Bem-vindo, %s!" {
+		t.Errorf("Lookup = %q, %v", text, ok)
+	}
+
+	if _, ok := b.Lookup("de", "welcome"); ok {
+		t.Error("Lookup fell back to the default locale")
+	}
+
+	if text, _ := b.Lookup("ru", "items"); text != "%d предмета" {
+		t.Errorf("Lookup of plural forms = %q", text)
+	}
+
+	if got := b.Locales(); !reflect.DeepEqual(got, []string{"en", "pt", "pt-br", "ru"}) {
+		t.Errorf("Locales = %v", got)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "zh-cn.json"), []byte(`{"welcome": "欢迎, %s!", "items": {"other": "%d 件"}}`), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`not a locale`), 0644)
+
+	b := NewBundle("en")
+	if err := b.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := b.T("zh-CN", "welcome", "安"); got != "欢迎, 安!" {
+		t.Errorf("T = %q", got)
+	}
+
+	if got := b.T("zh_cn", "items", 2); got != "2 件" {
+		t.Errorf("T = %q", got)
+	}
+
+	for _, bad := range []string{`{"welcome": `, `{"welcome": 1}`} {
+		os.WriteFile(filepath.Join(dir, "de.json"), []byte(bad), 0644)
+		if err := b.LoadDir(dir); err == nil {
+			t.Errorf("no error loading %s", bad)
+		}
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	tmpl := template.Must(template.New("t").Funcs(bundle().Funcs("pt")).Parse(`{{msg "welcome" .}}`))
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, "<Ana>"); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "Bem-vindo, &lt;Ana&gt;!" {
+		t.Errorf("rendered %s", buf.String())
+	}
+}
+
+func TestPluralCategory(t *testing.T) {
+	cases := []struct {
+		locale string
+		counts map[int64]string
+	}{
+		{"en", map[int64]string{0: "other", 1: "one", 2: "other", -1: "one"}},
+		{"fr", map[int64]string{0: "one", 1: "one", 2: "other"}},
+		{"pt-BR", map[int64]string{0: "one", 5: "other"}},
+		{"ru", map[int64]string{1: "one", 2: "few", 5: "many", 11: "many", 12: "many", 22: "few", 101: "one", 111: "many"}},
+		{"pl", map[int64]string{1: "one", 3: "few", 5: "many", 21: "many", 22: "few", 13: "many"}},
+		{"cs", map[int64]string{1: "one", 4: "few", 5: "other"}},
+		{"ar", map[int64]string{0: "zero", 1: "one", 2: "two", 3: "few", 11: "many", 100: "other", 103: "few"}},
+		{"ja", map[int64]string{1: "other"}},
+	}
+
+	for _, c := range cases {
+		for n, want := range c.counts {
+			if got := PluralCategory(c.locale, n); got != want {
+				t.Errorf("PluralCategory(%s, %d) = %s, want %s", c.locale, n, got, want)
+			}
+		}
+	}
+
+	RegisterPlural("x-test", func(n int64) string { return "many" })
+	if PluralCategory("x-test", 1) != "many" {
+		t.Error("registered rule not used")
+	}
+}
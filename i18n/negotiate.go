@@ -0,0 +1,148 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type contextKey struct{}
+
+// Return the loaded locale matching locale: itself, its language, or
+// another locale of its language, in that order.
+func (b *Bundle) match(locale string) (string, bool) {
+	locale = Normalize(locale)
+	if locale == "" {
+		return "", false
+	}
+
+	if b.hasLocale(locale) {
+		return locale, true
+	}
+
+	lang := base(locale)
+	if b.hasLocale(lang) {
+		return lang, true
+	}
+
+	for _, l := range b.Locales() {
+		if base(l) == lang {
+			return l, true
+		}
+	}
+
+	return "", false
+}
+
+type weighted struct {
+	locale string
+	q      float64
+}
+
+// Return the languages of an Accept-Language header by decreasing
+// quality, leaving out those of quality 0 and "*".
+func parseAcceptLanguage(header string) []string {
+	langs := []weighted{}
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		q := 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			params := strings.TrimSpace(part[i+1:])
+			part = strings.TrimSpace(part[:i])
+			if strings.HasPrefix(params, "q=") {
+				if f, err := strconv.ParseFloat(params[2:], 64); err == nil {
+					q = f
+				}
+			}
+		}
+
+		if part != "" && part != "*" && q > 0 {
+			langs = append(langs, weighted{part, q})
+		}
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+
+	locales := make([]string, len(langs))
+	for i, l := range langs {
+		locales[i] = l.locale
+	}
+
+	return locales
+}
+
+// Return the locale of a request: that of the query parameter, else that
+// of the cookie, else the best of Accept-Language that is loaded, else
+// the default locale.
+func (b *Bundle) Negotiate(r *http.Request) string {
+	if b.QueryParam != "" {
+		if l, ok := b.match(r.URL.Query().Get(b.QueryParam)); ok {
+			return l
+		}
+	}
+
+	if b.CookieName != "" {
+		if ck, err := r.Cookie(b.CookieName); err == nil {
+			if l, ok := b.match(ck.Value); ok {
+				return l
+			}
+		}
+	}
+
+	for _, lang := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if l, ok := b.match(lang); ok {
+			return l
+		}
+	}
+
+	return b.DefaultLocale
+}
+
+// Store the negotiated locale in the request context for FromRequest.  A
+// locale chosen by the query parameter is remembered in the cookie.
+func (b *Bundle) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := b.Negotiate(r)
+		if _, ok := b.match(r.URL.Query().Get(b.QueryParam)); ok && b.QueryParam != "" && b.CookieName != "" {
+			http.SetCookie(w, &http.Cookie{
+				Name:   b.CookieName,
+				Value:  locale,
+				Path:   "/",
+				MaxAge: 365 * 24 * 3600,
+			})
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, locale)))
+	})
+}
+
+// Middleware of Default.
+func Middleware(next http.Handler) http.Handler {
+	return Default.Middleware(next)
+}
+
+// Return the locale stored by Middleware, or "".
+func FromRequest(r *http.Request) string {
+	return FromContext(r.Context())
+}
+
+// Return the locale Middleware stored in the context of a request, or "",
+// for code given only the context, such as GraphQL resolvers.
+func FromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(contextKey{}).(string)
+	return locale
+}
+
+// Return the locale of a request: the one stored by Middleware, or else
+// the one Default negotiates.
+func Locale(r *http.Request) string {
+	if locale := FromRequest(r); locale != "" {
+		return locale
+	}
+
+	return Default.Negotiate(r)
+}
@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := parseAcceptLanguage("fr-CH, fr;q=0.9, en;q=0.8, de;q=0.7, *;q=0.5, it;q=0")
+	if want := []string{"fr-CH", "fr", "en", "de"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed %v, want %v", got, want)
+	}
+
+	got = parseAcceptLanguage("en;q=0.5, pt-BR, ja;q=bad")
+	if want := []string{"pt-BR", "ja", "en"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed %v, want %v", got, want)
+	}
+
+	if got := parseAcceptLanguage(""); len(got) != 0 {
+		t.Errorf("parsed %v", got)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	b := bundle()
+	cases := []struct {
+		query, cookie, accept, want string
+	}{
+		{"", "", "", "en"},
+		{"", "", "de, pt-PT;q=0.8, en;q=0.5", "pt"},
+		{"", "", "pt-BR", "pt-br"},
+		{"", "", "ru-UA", "ru"},
+		{"", "", "de, fr", "en"},
+		{"", "ru", "pt", "ru"},
+		{"?lang=pt_BR", "ru", "en", "pt-br"},
+		{"?lang=xx", "ru", "en", "ru"},
+		{"?lang=xx", "yy", "zz", "en"},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/"+c.query, nil)
+		if c.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: "lang", Value: c.cookie})
+		}
+
+		r.Header.Set("Accept-Language", c.accept)
+		if got := b.Negotiate(r); got != c.want {
+			t.Errorf("Negotiate(%q, cookie %q, %q) = %s, want %s", c.query, c.cookie, c.accept, got, c.want)
+		}
+	}
+
+	// Another locale of the language of the request.
+	b = NewBundle("en")
+	b.SetMessages("zh-tw", map[string]string{"a": "b"})
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "zh-CN")
+	if got := b.Negotiate(r); got != "zh-tw" {
+		t.Errorf("Negotiate = %s", got)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var got, fromContext string
+	h := bundle().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, fromContext = FromRequest(r), FromContext(r.Context())
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?lang=ru", nil))
+	cookies := w.Result().Cookies()
+	if got != "ru" || fromContext != "ru" || len(cookies) != 1 || cookies[0].Value != "ru" || cookies[0].Path != "/" {
+		t.Errorf("locale %s, cookies %v", got, cookies)
+	}
+
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "pt")
+	h.ServeHTTP(w, r)
+	if got != "pt" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("locale %s, cookies %v", got, w.Result().Cookies())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?lang=xx", nil))
+	if got != "en" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("locale %s, cookies %v for an unknown locale", got, w.Result().Cookies())
+	}
+
+	r = httptest.NewRequest("GET", "/", nil)
+	if FromRequest(r) != "" || Locale(r) != Default.DefaultLocale {
+		t.Errorf("outside the middleware: %q, %q", FromRequest(r), Locale(r))
+	}
+}
@@ -0,0 +1,69 @@
+package i18n
+
+import (
+	"sync"
+)
+
+// How a locale writes numbers: its decimal separator, and the characters
+// it accepts between groups of thousands, the first being the usual one.
+type NumberFormat struct {
+	Decimal string
+	Group   string
+}
+
+var englishNumbers = NumberFormat{Decimal: ".", Group: ","}
+
+var numberFormats = struct {
+	sync.RWMutex
+	formats map[string]NumberFormat
+}{
+	formats: map[string]NumberFormat{
+		"be":    {",", "\u00a0 \u202f"},
+		"cs":    {",", "\u00a0 \u202f"},
+		"da":    {",", "."},
+		"de":    {",", "."},
+		"de-ch": {".", "'’"},
+		"el":    {",", "."},
+		"es":    {",", "."},
+		"fi":    {",", "\u00a0 \u202f"},
+		"fr":    {",", "\u202f\u00a0 "},
+		"id":    {",", "."},
+		"it":    {",", "."},
+		"nb":    {",", "\u00a0 \u202f"},
+		"nl":    {",", "."},
+		"pl":    {",", "\u00a0 \u202f"},
+		"pt":    {",", "."},
+		"pt-pt": {",", "\u00a0 \u202f"},
+		"ru":    {",", "\u00a0 \u202f"},
+		"sk":    {",", "\u00a0 \u202f"},
+		"sv":    {",", "\u00a0 \u202f"},
+		"tr":    {",", "."},
+		"uk":    {",", "\u00a0 \u202f"},
+		"vi":    {",", "."},
+	},
+}
+
+// Set the number format of a language or locale.  Languages without one
+// follow English: "1,234.5".
+func RegisterNumberFormat(locale string, f NumberFormat) {
+	numberFormats.Lock()
+	numberFormats.formats[Normalize(locale)] = f
+	numberFormats.Unlock()
+}
+
+// Return the number format of locale.
+func NumberFormatFor(locale string) NumberFormat {
+	locale = Normalize(locale)
+	numberFormats.RLock()
+	defer numberFormats.RUnlock()
+	f, ok := numberFormats.formats[locale]
+	if !ok {
+		f, ok = numberFormats.formats[base(locale)]
+	}
+
+	if !ok {
+		return englishNumbers
+	}
+
+	return f
+}
@@ -0,0 +1,32 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestNumberFormatFor(t *testing.T) {
+	cases := map[string]NumberFormat{
+		"en":    {".", ","},
+		"ja":    {".", ","},
+		"de-AT": {",", "."},
+		"de_CH": {".", "'’"},
+		"pt-BR": {",", "."},
+		"pt-PT": {",", "\u00a0 \u202f"},
+	}
+
+	for locale, want := range cases {
+		if got := NumberFormatFor(locale); got != want {
+			t.Errorf("NumberFormatFor(%s) = %q", locale, got)
+		}
+	}
+
+	RegisterNumberFormat("X_Test", NumberFormat{Decimal: "·", Group: "_"})
+	if got := NumberFormatFor("x-test"); got.Decimal != "·" {
+		t.Errorf("registered format not used: %q", got)
+	}
+
+	RegisterNumberFormat("xb", NumberFormat{Decimal: ",", Group: "."})
+	if got := NumberFormatFor("xb-region"); got.Decimal != "," {
+		t.Errorf("format of the language not used: %q", got)
+	}
+}
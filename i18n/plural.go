@@ -0,0 +1,133 @@
+package i18n
+
+import (
+	"sync"
+)
+
+// A PluralRule returns the plural category of a count in a language, as
+// in the CLDR plural rules for integers.
+type PluralRule func(n int64) string
+
+func pluralOneOther(n int64) string {
+	if n == 1 {
+		return "one"
+	}
+
+	return "other"
+}
+
+func pluralOther(n int64) string {
+	return "other"
+}
+
+// French and Portuguese count 0 as singular.
+func pluralZeroOneOther(n int64) string {
+	if n == 0 || n == 1 {
+		return "one"
+	}
+
+	return "other"
+}
+
+func pluralSlavic(n int64) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return "one"
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return "few"
+	}
+
+	return "many"
+}
+
+func pluralPolish(n int64) string {
+	if n == 1 {
+		return "one"
+	}
+
+	if n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14) {
+		return "few"
+	}
+
+	return "many"
+}
+
+func pluralCzech(n int64) string {
+	switch {
+	case n == 1:
+		return "one"
+	case n >= 2 && n <= 4:
+		return "few"
+	}
+
+	return "other"
+}
+
+func pluralArabic(n int64) string {
+	switch {
+	case n == 0:
+		return "zero"
+	case n == 1:
+		return "one"
+	case n == 2:
+		return "two"
+	case n%100 >= 3 && n%100 <= 10:
+		return "few"
+	case n%100 >= 11:
+		return "many"
+	}
+
+	return "other"
+}
+
+var plurals = struct {
+	sync.RWMutex
+	rules map[string]PluralRule
+}{
+	rules: map[string]PluralRule{
+		"ar": pluralArabic,
+		"be": pluralSlavic,
+		"cs": pluralCzech,
+		"fr": pluralZeroOneOther,
+		"id": pluralOther,
+		"ja": pluralOther,
+		"ko": pluralOther,
+		"pl": pluralPolish,
+		"pt": pluralZeroOneOther,
+		"ru": pluralSlavic,
+		"sk": pluralCzech,
+		"th": pluralOther,
+		"uk": pluralSlavic,
+		"vi": pluralOther,
+		"zh": pluralOther,
+	},
+}
+
+// Set the plural rule of a language or locale.  Languages without one
+// follow English: "one" for 1 and "other" for any other count.
+func RegisterPlural(locale string, rule PluralRule) {
+	plurals.Lock()
+	plurals.rules[Normalize(locale)] = rule
+	plurals.Unlock()
+}
+
+// Return the plural category of n in locale.
+func PluralCategory(locale string, n int64) string {
+	if n < 0 {
+		n = -n
+	}
+
+	locale = Normalize(locale)
+	plurals.RLock()
+	rule, ok := plurals.rules[locale]
+	if !ok {
+		rule, ok = plurals.rules[base(locale)]
+	}
+	plurals.RUnlock()
+
+	if !ok {
+		rule = pluralOneOther
+	}
+
+	return rule(n)
+}
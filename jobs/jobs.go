@@ -0,0 +1,286 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golanger.com/framework/log"
+	mathrand "math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var ErrNoHandler = errors.New("jobs: no handler for the job")
+
+// A Job is a unit of background work, named after the handler that runs
+// it, with a JSON payload.
+type Job struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempts   int             `json:"attempts"`
+	RunAt      time.Time       `json:"run_at"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+	LastError  string          `json:"last_error,omitempty"`
+}
+
+// Decode the payload into v.
+func (j *Job) Decode(v interface{}) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// A Handler runs a job.  A returned error, or a panic, schedules a retry;
+// ctx ends when the queue stops.
+type Handler func(ctx context.Context, job *Job) error
+
+// A Backend stores the jobs waiting to run.  Pop blocks until a job is due
+// or ctx ends, returning ctx.Err() then.  Dead keeps the jobs that ran out
+// of attempts, for inspection or replay.
+type Backend interface {
+	Push(job *Job) error
+	Pop(ctx context.Context) (*Job, error)
+	Dead(job *Job) error
+}
+
+// Wait 1s, 2s, 4s... up to an hour before the next attempt, plus up to a
+// quarter more at random so that failed jobs do not retry in lockstep.
+func DefaultBackoff(attempt int) time.Duration {
+	d := time.Hour
+	if attempt < 13 {
+		d = time.Second << uint(attempt-1)
+	}
+
+	return d + time.Duration(mathrand.Int63n(int64(d/4)+1))
+}
+
+// A Queue runs jobs on a pool of workers.
+type Queue struct {
+	backend  Backend
+	mutex    sync.RWMutex
+	handlers map[string]Handler
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	// The attempts a job gets before it is dead-lettered, 5 if zero.
+	MaxAttempts int
+	// The delay before the next attempt, DefaultBackoff if nil.
+	Backoff func(attempt int) time.Duration
+	// Called with each job that ran out of attempts.
+	OnDead func(job *Job)
+}
+
+func New(backend Backend) *Queue {
+	return &Queue{
+		backend:  backend,
+		handlers: map[string]Handler{},
+	}
+}
+
+// The Queue of the package-level functions, in memory.
+var Default = New(NewMemoryBackend())
+
+// Set the handler of the jobs named name.
+func (q *Queue) Handle(name string, h Handler) {
+	q.mutex.Lock()
+	q.handlers[name] = h
+	q.mutex.Unlock()
+}
+
+func newID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// Queue a job running the handler of name with payload, encoded as JSON,
+// as soon as a worker is free.
+func (q *Queue) Enqueue(name string, payload interface{}) (*Job, error) {
+	return q.EnqueueIn(0, name, payload)
+}
+
+// Queue a job to run after delay.
+func (q *Queue) EnqueueIn(delay time.Duration, name string, payload interface{}) (*Job, error) {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &Job{
+		ID:         newID(),
+		Name:       name,
+		Payload:    b,
+		RunAt:      now.Add(delay),
+		EnqueuedAt: now,
+	}
+
+	if err := q.backend.Push(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Start workers goroutines running the jobs, until Stop.
+func (q *Queue) Start(workers int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	q.mutex.Lock()
+	q.cancel = cancel
+	q.mutex.Unlock()
+
+	for i := 0; i < workers; i++ {
+		q.wg.Add(1)
+		go q.work(ctx)
+	}
+}
+
+// Stop the workers and wait for the jobs they run to return, or for ctx
+// to end.  The handlers see their context end and should return soon;
+// a job that fails because of it is retried.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mutex.RLock()
+	cancel := q.cancel
+	q.mutex.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work(ctx context.Context) {
+	defer q.wg.Done()
+	for {
+		job, err := q.backend.Pop(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.Error("<jobs.Queue.work> ", err)
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return
+			}
+
+			continue
+		}
+
+		q.run(ctx, job)
+	}
+}
+
+// Run a job and retry or dead-letter it if it fails.
+func (q *Queue) run(ctx context.Context, job *Job) {
+	l := log.WithFields(log.Fields{"job": job.ID, "name": job.Name, "attempt": job.Attempts + 1})
+	q.mutex.RLock()
+	h, ok := q.handlers[job.Name]
+	q.mutex.RUnlock()
+
+	var err error
+	if ok {
+		err = call(ctx, h, job)
+	} else {
+		err = ErrNoHandler
+	}
+
+	if err == nil {
+		l.Debug("<jobs.Queue.run> ", "done")
+		return
+	}
+
+	if pe, ok := err.(*panicError); ok {
+		l = l.WithFields(log.Fields{"stack": string(pe.stack)})
+	}
+
+	job.Attempts++
+	job.LastError = err.Error()
+
+	maxAttempts := q.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 5
+	}
+
+	if job.Attempts >= maxAttempts {
+		l.Error("<jobs.Queue.run> ", "dead after ", job.Attempts, " attempts: ", err)
+		if err := q.backend.Dead(job); err != nil {
+			l.Error("<jobs.Queue.run> ", err)
+		}
+
+		if q.OnDead != nil {
+			q.OnDead(job)
+		}
+
+		return
+	}
+
+	backoff := q.Backoff
+	if backoff == nil {
+		backoff = DefaultBackoff
+	}
+
+	delay := backoff(job.Attempts)
+	job.RunAt = time.Now().Add(delay)
+	l.Warn("<jobs.Queue.run> ", "failed, retrying in ", delay, ": ", err)
+	if err := q.backend.Push(job); err != nil {
+		l.Error("<jobs.Queue.run> ", err)
+	}
+}
+
+// The error of a handler that panicked.
+type panicError struct {
+	value interface{}
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprint("panic: ", e.value)
+}
+
+// Call h, turning a panic into a *panicError.
+func call(ctx context.Context, h Handler, job *Job) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = &panicError{e, debug.Stack()}
+		}
+	}()
+
+	return h(ctx, job)
+}
+
+func Handle(name string, h Handler) {
+	Default.Handle(name, h)
+}
+
+func Enqueue(name string, payload interface{}) (*Job, error) {
+	return Default.Enqueue(name, payload)
+}
+
+func EnqueueIn(delay time.Duration, name string, payload interface{}) (*Job, error) {
+	return Default.EnqueueIn(delay, name, payload)
+}
+
+func Start(workers int) {
+	Default.Start(workers)
+}
+
+func Stop(ctx context.Context) error {
+	return Default.Stop(ctx)
+}
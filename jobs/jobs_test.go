@@ -0,0 +1,240 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"golanger.com/framework/log"
+	"sync"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+// Start q with workers and stop it once the test is done.
+func start(t *testing.T, q *Queue, workers int) {
+	q.Start(workers)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := q.Stop(ctx); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func noBackoff(int) time.Duration {
+	return 0
+}
+
+func TestRunJobs(t *testing.T) {
+	q := New(NewMemoryBackend())
+	var mutex sync.Mutex
+	got := map[string]bool{}
+	done := make(chan struct{}, 10)
+	q.Handle("greet", func(ctx context.Context, job *Job) error {
+		var name string
+		if err := job.Decode(&name); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		got[name] = true
+		mutex.Unlock()
+		done <- struct{}{}
+
+		return nil
+	})
+
+	start(t, q, 3)
+	for _, name := range []string{"ann", "bob", "cid"} {
+		if job, err := q.Enqueue("greet", name); err != nil || job.ID == "" || job.Name != "greet" {
+			t.Fatalf("Enqueue = %v, %v", job, err)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("jobs not run")
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !got["ann"] || !got["bob"] || !got["cid"] {
+		t.Errorf("ran %v", got)
+	}
+}
+
+func TestEnqueueIn(t *testing.T) {
+	q := New(NewMemoryBackend())
+	ran := make(chan string, 2)
+	q.Handle("tick", func(ctx context.Context, job *Job) error {
+		var s string
+		job.Decode(&s)
+		ran <- s
+
+		return nil
+	})
+
+	start(t, q, 1)
+	q.EnqueueIn(100*time.Millisecond, "tick", "late")
+	q.Enqueue("tick", "now")
+
+	if first := <-ran; first != "now" {
+		t.Errorf("ran %q first", first)
+	}
+
+	if second := <-ran; second != "late" {
+		t.Errorf("ran %q second", second)
+	}
+
+	if _, err := q.Enqueue("tick", func() {}); err == nil {
+		t.Error("no error for a payload that is not JSON")
+	}
+}
+
+func TestRetryAndDead(t *testing.T) {
+	backend := NewMemoryBackend()
+	q := New(backend)
+	q.MaxAttempts = 3
+	q.Backoff = noBackoff
+	dead := make(chan *Job, 2)
+	q.OnDead = func(job *Job) { dead <- job }
+
+	attempts := 0
+	q.Handle("flaky", func(ctx context.Context, job *Job) error {
+		attempts++
+		if attempts < 2 {
+			return errors.New("try again")
+		}
+
+		return nil
+	})
+
+	q.Handle("broken", func(ctx context.Context, job *Job) error {
+		panic("boom")
+	})
+
+	start(t, q, 1)
+	q.Enqueue("flaky", nil)
+	q.Enqueue("broken", nil)
+	q.Enqueue("unknown", nil)
+
+	got := map[string]*Job{}
+	for i := 0; i < 2; i++ {
+		select {
+		case job := <-dead:
+			got[job.Name] = job
+		case <-time.After(5 * time.Second):
+			t.Fatal("jobs not dead-lettered")
+		}
+	}
+
+	if job := got["broken"]; job == nil || job.Attempts != 3 || job.LastError != "panic: boom" {
+		t.Errorf("broken job %+v", job)
+	}
+
+	if job := got["unknown"]; job == nil || job.LastError != ErrNoHandler.Error() {
+		t.Errorf("unknown job %+v", job)
+	}
+
+	if len(backend.DeadJobs()) != 2 || got["flaky"] != nil {
+		t.Errorf("dead jobs %v", backend.DeadJobs())
+	}
+}
+
+func TestStopWaits(t *testing.T) {
+	q := New(NewMemoryBackend())
+	q.Backoff = func(int) time.Duration { return time.Hour }
+	started := make(chan struct{})
+	q.Handle("slow", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	q.Start(1)
+	q.Enqueue("slow", nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := q.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The interrupted job is retried later.
+	if n := q.backend.(*MemoryBackend).Len(); n != 1 {
+		t.Errorf("%d jobs waiting, want the interrupted one", n)
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	q := New(NewMemoryBackend())
+	release := make(chan struct{})
+	started := make(chan struct{})
+	q.Handle("stuck", func(ctx context.Context, job *Job) error {
+		close(started)
+		<-release
+
+		return nil
+	})
+
+	q.Start(1)
+	q.Enqueue("stuck", nil)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := q.Stop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Stop = %v, want the deadline", err)
+	}
+
+	close(release)
+}
+
+func TestDefaultBackoff(t *testing.T) {
+	for attempt, base := range map[int]time.Duration{1: time.Second, 3: 4 * time.Second, 13: time.Hour, 40: time.Hour} {
+		for i := 0; i < 20; i++ {
+			if d := DefaultBackoff(attempt); d < base || d > base+base/4 {
+				t.Errorf("DefaultBackoff(%d) = %v", attempt, d)
+			}
+		}
+	}
+}
+
+func TestMemoryBackendOrder(t *testing.T) {
+	b := NewMemoryBackend()
+	now := time.Now()
+	b.Push(&Job{ID: "b", RunAt: now.Add(-time.Second)})
+	b.Push(&Job{ID: "a", RunAt: now.Add(-time.Minute)})
+	b.Push(&Job{ID: "c", RunAt: now.Add(time.Hour)})
+
+	for _, want := range []string{"a", "b"} {
+		if job, err := b.Pop(context.Background()); err != nil || job.ID != want {
+			t.Errorf("Pop = %v, %v, want %s", job, err, want)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if job, err := b.Pop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Pop = %v, %v before the job is due", job, err)
+	}
+
+	// A Push wakes a waiting Pop.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		b.Push(&Job{ID: "d", RunAt: time.Now()})
+	}()
+
+	if job, err := b.Pop(context.Background()); err != nil || job.ID != "d" {
+		t.Errorf("Pop = %v, %v, want d", job, err)
+	}
+}
@@ -0,0 +1,102 @@
+package jobs
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Jobs ordered by RunAt.
+type jobHeap []*Job
+
+func (h jobHeap) Len() int            { return len(h) }
+func (h jobHeap) Less(i, j int) bool  { return h[i].RunAt.Before(h[j].RunAt) }
+func (h jobHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x interface{}) { *h = append(*h, x.(*Job)) }
+
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	job := old[len(old)-1]
+	*h = old[:len(old)-1]
+
+	return job
+}
+
+// A Backend in process memory.  Its jobs are lost when the process exits.
+type MemoryBackend struct {
+	mutex sync.Mutex
+	jobs  jobHeap
+	dead  []*Job
+	// Closed and replaced by Push to wake every waiting Pop, which may
+	// now have an earlier job to wait for.
+	wake chan struct{}
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		wake: make(chan struct{}),
+	}
+}
+
+func (b *MemoryBackend) Push(job *Job) error {
+	b.mutex.Lock()
+	heap.Push(&b.jobs, job)
+	close(b.wake)
+	b.wake = make(chan struct{})
+	b.mutex.Unlock()
+
+	return nil
+}
+
+func (b *MemoryBackend) Pop(ctx context.Context) (*Job, error) {
+	for {
+		b.mutex.Lock()
+		wake := b.wake
+		wait := time.Hour
+		if len(b.jobs) > 0 {
+			wait = time.Until(b.jobs[0].RunAt)
+			if wait <= 0 {
+				job := heap.Pop(&b.jobs).(*Job)
+				b.mutex.Unlock()
+				return job, nil
+			}
+		}
+		b.mutex.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-wake:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+	}
+}
+
+func (b *MemoryBackend) Dead(job *Job) error {
+	b.mutex.Lock()
+	b.dead = append(b.dead, job)
+	b.mutex.Unlock()
+
+	return nil
+}
+
+// Return the number of jobs waiting.
+func (b *MemoryBackend) Len() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return len(b.jobs)
+}
+
+// Return the dead-lettered jobs.
+func (b *MemoryBackend) DeadJobs() []*Job {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]*Job(nil), b.dead...)
+}
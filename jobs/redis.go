@@ -0,0 +1,117 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The one command RedisBackend needs, so any Redis client can be adapted
+// to it: EVAL of a Lua script with its keys and arguments, returning its
+// reply.
+type RedisClient interface {
+	Eval(script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+const pushScript = `redis.call("ZADD", KEYS[1], ARGV[1], ARGV[2]) return 1`
+
+// Take the first job of KEYS[1] due by ARGV[1], if any.
+const popScript = `
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, 1)
+if #due == 0 then
+	return false
+end
+redis.call("ZREM", KEYS[1], due[1])
+return due[1]
+`
+
+const deadScript = `redis.call("LPUSH", KEYS[1], ARGV[1]) return 1`
+
+// A Backend keeping the jobs in Redis, so that the processes of an
+// application share them: those waiting in the sorted set prefix+"queue",
+// scored by RunAt, and the dead ones in the list prefix+"dead".  A job is
+// removed when a worker takes it, so it is lost if the process dies while
+// running it.
+type RedisBackend struct {
+	client RedisClient
+	prefix string
+
+	// How often Pop looks for due jobs when there are none, a second if
+	// zero.
+	PollInterval time.Duration
+}
+
+func NewRedisBackend(client RedisClient, prefix string) *RedisBackend {
+	return &RedisBackend{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func millis(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+}
+
+func (b *RedisBackend) Push(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.client.Eval(pushScript, []string{b.prefix + "queue"}, millis(job.RunAt), string(data))
+
+	return err
+}
+
+func (b *RedisBackend) Pop(ctx context.Context) (*Job, error) {
+	interval := b.PollInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	for {
+		reply, err := b.client.Eval(popScript, []string{b.prefix + "queue"}, millis(time.Now()))
+		if err != nil {
+			return nil, err
+		}
+
+		var data []byte
+		switch r := reply.(type) {
+		case string:
+			data = []byte(r)
+		case []byte:
+			data = r
+		case nil:
+		default:
+			return nil, fmt.Errorf("jobs: unexpected reply %v", reply)
+		}
+
+		if data != nil {
+			job := &Job{}
+			if err := json.Unmarshal(data, job); err != nil {
+				return nil, err
+			}
+
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (b *RedisBackend) Dead(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.client.Eval(deadScript, []string{b.prefix + "dead"}, string(data))
+
+	return err
+}
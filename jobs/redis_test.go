@@ -0,0 +1,100 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// A RedisClient running the scripts of RedisBackend on a map.
+type fakeRedis struct {
+	queue map[string]int64
+	dead  []string
+	err   error
+}
+
+func (f *fakeRedis) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	switch script {
+	case pushScript:
+		score, _ := strconv.ParseInt(args[0].(string), 10, 64)
+		f.queue[args[1].(string)] = score
+
+		return int64(1), nil
+	case popScript:
+		now, _ := strconv.ParseInt(args[0].(string), 10, 64)
+		due := []string{}
+		for member, score := range f.queue {
+			if score <= now {
+				due = append(due, member)
+			}
+		}
+
+		if len(due) == 0 {
+			return nil, nil
+		}
+
+		sort.Slice(due, func(i, j int) bool { return f.queue[due[i]] < f.queue[due[j]] })
+		delete(f.queue, due[0])
+
+		return []byte(due[0]), nil
+	case deadScript:
+		f.dead = append([]string{args[0].(string)}, f.dead...)
+		return int64(1), nil
+	}
+
+	return nil, errors.New("unknown script")
+}
+
+func TestRedisBackend(t *testing.T) {
+	client := &fakeRedis{queue: map[string]int64{}}
+	b := NewRedisBackend(client, "app:jobs:")
+	b.PollInterval = 10 * time.Millisecond
+
+	now := time.Now()
+	b.Push(&Job{ID: "late", Name: "x", RunAt: now.Add(time.Hour)})
+	b.Push(&Job{ID: "due", Name: "x", Payload: []byte(`{"n":1}`), RunAt: now.Add(-time.Second)})
+
+	job, err := b.Pop(context.Background())
+	if err != nil || job.ID != "due" || string(job.Payload) != `{"n":1}` {
+		t.Fatalf("Pop = %+v, %v", job, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if job, err := b.Pop(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Pop = %v, %v before the job is due", job, err)
+	}
+
+	if err := b.Dead(job); err != nil || len(client.dead) != 1 {
+		t.Errorf("Dead = %v, %v", err, client.dead)
+	}
+
+	client.err = errors.New("down")
+	if _, err := b.Pop(context.Background()); err != client.err {
+		t.Errorf("Pop error %v", err)
+	}
+}
+
+func TestRedisBackendReplies(t *testing.T) {
+	for _, reply := range []interface{}{int64(1), "not json"} {
+		b := NewRedisBackend(replyClient{reply}, "")
+		if _, err := b.Pop(context.Background()); err == nil {
+			t.Errorf("no error for reply %v", reply)
+		}
+	}
+}
+
+type replyClient struct {
+	reply interface{}
+}
+
+func (c replyClient) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	return c.reply, nil
+}
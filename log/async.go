@@ -0,0 +1,262 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// What an AsyncHandler does with a record when its buffer is full.
+const (
+	// Drop the new record.
+	OverflowDrop = iota
+	// Drop the oldest record queued to make room for the new one.
+	OverflowDropOldest
+	// Wait for room, as a synchronous handler would.
+	OverflowBlock
+)
+
+type AsyncOptions struct {
+	// The records queued before Overflow applies, 1024 if zero.
+	Size int
+
+	// OverflowDrop by default.
+	Overflow int
+
+	// The most records handed to the handler at once, 256 if zero.
+	BatchSize int
+
+	// How long the flusher waits for more records before writing a batch
+	// that is not full, 0 to write as soon as there is one.
+	Interval time.Duration
+}
+
+// A handler that can write several records at once, such as with a single
+// write to its file, which an AsyncHandler prefers to Handle.
+type BatchHandler interface {
+	HandleBatch(records []*Record) error
+}
+
+// A handler that holds back what it writes until Flush.
+type Flusher interface {
+	Flush() error
+}
+
+// An AsyncHandler queues records in a ring buffer and hands them to its
+// handler in batches from a background flusher, so that logging does not
+// wait on the disk or syslog.  Records dropped on overflow are counted and
+// reported by a WARN record once there is room.  Call Flush or Close
+// before exiting, which Fatal does for the handler of its Logger.
+type AsyncHandler struct {
+	handler Handler
+	opts    AsyncOptions
+	mutex   sync.Mutex
+	cond    *sync.Cond
+	ring    []*Record
+	head    int
+	count   int
+	dropped int
+	queued  uint64
+	written uint64
+	closed  bool
+	done    chan struct{}
+}
+
+func NewAsyncHandler(handler Handler, opts AsyncOptions) *AsyncHandler {
+	if opts.Size <= 0 {
+		opts.Size = 1024
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+
+	h := &AsyncHandler{
+		handler: handler,
+		opts:    opts,
+		ring:    make([]*Record, opts.Size),
+		done:    make(chan struct{}),
+	}
+
+	h.cond = sync.NewCond(&h.mutex)
+	go h.flusher()
+
+	return h
+}
+
+// Queue r.  It only fails once the handler is closed.
+func (h *AsyncHandler) Handle(r *Record) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for !h.closed && h.count == len(h.ring) && h.opts.Overflow == OverflowBlock {
+		h.cond.Wait()
+	}
+
+	if h.closed {
+		return os.ErrClosed
+	}
+
+	if h.count == len(h.ring) {
+		h.dropped++
+		if h.opts.Overflow != OverflowDropOldest {
+			return nil
+		}
+
+		h.head = (h.head + 1) % len(h.ring)
+		h.count--
+		h.written++
+	}
+
+	h.ring[(h.head+h.count)%len(h.ring)] = r
+	h.count++
+	h.queued++
+	h.cond.Broadcast()
+
+	return nil
+}
+
+// Take up to BatchSize records off the ring, with h.mutex held, and the
+// number dropped since the last batch.
+func (h *AsyncHandler) take() ([]*Record, int) {
+	n := h.count
+	if n > h.opts.BatchSize {
+		n = h.opts.BatchSize
+	}
+
+	batch := make([]*Record, n)
+	for i := range batch {
+		batch[i] = h.ring[(h.head+i)%len(h.ring)]
+		h.ring[(h.head+i)%len(h.ring)] = nil
+	}
+
+	h.head = (h.head + n) % len(h.ring)
+	h.count -= n
+	dropped := h.dropped
+	h.dropped = 0
+	h.cond.Broadcast()
+
+	return batch, dropped
+}
+
+// Write batches until closed and drained.
+func (h *AsyncHandler) flusher() {
+	defer close(h.done)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for {
+		for h.count == 0 && !h.closed {
+			h.cond.Wait()
+		}
+
+		if h.count == 0 {
+			return
+		}
+
+		if h.opts.Interval > 0 && h.count < h.opts.BatchSize && !h.closed {
+			h.mutex.Unlock()
+			time.Sleep(h.opts.Interval)
+			h.mutex.Lock()
+		}
+
+		batch, dropped := h.take()
+		h.mutex.Unlock()
+
+		n := len(batch)
+		if dropped > 0 {
+			batch = append(batch, &Record{
+				Time:    time.Now(),
+				Level:   LEVEL_WARN,
+				Message: fmt.Sprint("log: dropped ", dropped, " records, the buffer was full"),
+			})
+		}
+
+		h.write(batch)
+
+		h.mutex.Lock()
+		h.written += uint64(n)
+		h.cond.Broadcast()
+	}
+}
+
+func (h *AsyncHandler) write(batch []*Record) {
+	var err error
+	if bh, ok := h.handler.(BatchHandler); ok {
+		err = bh.HandleBatch(batch)
+	} else {
+		for _, r := range batch {
+			if e := h.handler.Handle(r); e != nil {
+				err = e
+			}
+		}
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "log: handler failed:", err)
+	}
+}
+
+// Wait until the records queued so far are written, then flush the
+// handler if it is a Flusher.  It may wait up to Interval longer.
+func (h *AsyncHandler) Flush() error {
+	h.mutex.Lock()
+	target := h.queued
+	for h.written < target {
+		h.cond.Wait()
+	}
+	h.mutex.Unlock()
+
+	if f, ok := h.handler.(Flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// Write the queued records and stop the flusher.  Records handled after
+// Close are refused.
+func (h *AsyncHandler) Close() error {
+	h.mutex.Lock()
+	h.closed = true
+	h.cond.Broadcast()
+	h.mutex.Unlock()
+
+	<-h.done
+	if f, ok := h.handler.(Flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+// Write the records with a single Write.
+func (h *TextHandler) HandleBatch(records []*Record) error {
+	var buf bytes.Buffer
+	for _, r := range records {
+		h.format(&buf, r)
+	}
+
+	_, err := h.w.Write(buf.Bytes())
+
+	return err
+}
+
+func (h *JSONHandler) HandleBatch(records []*Record) error {
+	var buf bytes.Buffer
+	var err error
+	for _, r := range records {
+		if e := h.format(&buf, r); e != nil {
+			err = e
+		}
+	}
+
+	if _, e := h.w.Write(buf.Bytes()); e != nil {
+		err = e
+	}
+
+	return err
+}
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A BatchHandler that blocks on its first batch until release is closed.
+type gate struct {
+	recorder
+	batches int
+	started chan struct{}
+	release chan struct{}
+}
+
+func newGate() *gate {
+	return &gate{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gate) HandleBatch(records []*Record) error {
+	g.mutex.Lock()
+	g.batches++
+	first := g.batches == 1
+	g.mutex.Unlock()
+
+	if first {
+		close(g.started)
+		<-g.release
+	}
+
+	for _, r := range records {
+		g.Handle(r)
+	}
+
+	return nil
+}
+
+func rec(msg string) *Record {
+	return &Record{Level: LEVEL_INFO, Message: msg}
+}
+
+func TestAsyncWritesInOrder(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewAsyncHandler(NewTextHandler(&buf), AsyncOptions{BatchSize: 3, Interval: time.Millisecond})
+	l := New(h, LEVEL_ALL)
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		l.Info(msg)
+	}
+
+	if err := l.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.HasSuffix(lines[0], "[INFO] a") || !strings.HasSuffix(lines[4], "[INFO] e") {
+		t.Errorf("lines %q", lines)
+	}
+
+	h.Close()
+	if err := h.Handle(rec("late")); err == nil {
+		t.Error("no error handling after Close")
+	}
+}
+
+// Queue first, wait for the flusher to block writing it, then queue rest
+// into a ring of size 2 and return the messages written in the end.
+func overflow(t *testing.T, mode int, rest ...string) []string {
+	g := newGate()
+	h := NewAsyncHandler(g, AsyncOptions{Size: 2, Overflow: mode})
+	h.Handle(rec("first"))
+	<-g.started
+	for _, msg := range rest {
+		h.Handle(rec(msg))
+	}
+
+	close(g.release)
+	h.Close()
+
+	return g.messages()
+}
+
+func TestAsyncOverflowDrop(t *testing.T) {
+	got := strings.Join(overflow(t, OverflowDrop, "a", "b", "c", "d"), ", ")
+	if got != "INFO first, INFO a, INFO b, WARN log: dropped 2 records, the buffer was full" {
+		t.Errorf("written %q", got)
+	}
+}
+
+func TestAsyncOverflowDropOldest(t *testing.T) {
+	got := strings.Join(overflow(t, OverflowDropOldest, "a", "b", "c", "d"), ", ")
+	if got != "INFO first, INFO c, INFO d, WARN log: dropped 2 records, the buffer was full" {
+		t.Errorf("written %q", got)
+	}
+}
+
+func TestAsyncOverflowBlock(t *testing.T) {
+	g := newGate()
+	h := NewAsyncHandler(g, AsyncOptions{Size: 1, Overflow: OverflowBlock})
+	h.Handle(rec("first"))
+	<-g.started
+	h.Handle(rec("a"))
+
+	queued := make(chan struct{})
+	go func() {
+		h.Handle(rec("b"))
+		close(queued)
+	}()
+
+	select {
+	case <-queued:
+		t.Fatal("Handle did not wait for room")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(g.release)
+	<-queued
+	h.Flush()
+	if got := strings.Join(g.messages(), ", "); got != "INFO first, INFO a, INFO b" {
+		t.Errorf("written %q", got)
+	}
+
+	h.Close()
+}
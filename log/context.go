@@ -0,0 +1,33 @@
+package log
+
+import (
+	"context"
+	"net/http"
+)
+
+type loggerKey struct{}
+
+// Return a copy of ctx carrying l, which FromContext and FromRequest then
+// return instead of the default logger, e.g. from a middleware adding the
+// user of the request to its lines:
+//
+//	ctx := log.NewContext(r.Context(), log.FromRequest(r).WithFields(log.Fields{"user_id": id}))
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// Return the logger of ctx, or else the default logger, with the request
+// ID of ctx.
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(loggerKey{}).(*Logger)
+	if !ok || l == nil {
+		l = std
+	}
+
+	return l.WithContext(ctx)
+}
+
+// Return the logger of the request, for its log lines, see FromContext.
+func FromRequest(r *http.Request) *Logger {
+	return FromContext(r.Context())
+}
@@ -0,0 +1,27 @@
+package log
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()) != std {
+		t.Error("not the default logger without one in the context")
+	}
+
+	rec := &recorder{}
+	l := New(rec, LEVEL_ALL).WithFields(Fields{"user_id": 7})
+	ctx := WithRequestID(NewContext(context.Background(), l), "r1")
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	FromRequest(r).Info("x")
+
+	if f := rec.records[0].Fields; f["user_id"] != 7 || f["request_id"] != "r1" {
+		t.Errorf("fields %v", f)
+	}
+
+	if FromContext(NewContext(context.Background(), nil)) != std {
+		t.Error("nil logger in the context used")
+	}
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// A Record is one log line as given to a Handler.
+type Record struct {
+	Time    time.Time
+	Level   int
+	Message string
+	Fields  Fields
+}
+
+// A Handler writes Records to a backend.  Loggers call Handle one record at
+// a time.
+type Handler interface {
+	Handle(r *Record) error
+}
+
+func (r *Record) sortedKeys() []string {
+	keys := make([]string, 0, len(r.Fields))
+	for k := range r.Fields {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+var levelColor = map[int]string{
+	LEVEL_DEBUG: "\x1b[90m",
+	LEVEL_INFO:  "\x1b[32m",
+	LEVEL_WARN:  "\x1b[33m",
+	LEVEL_ERROR: "\x1b[31m",
+	LEVEL_FATAL: "\x1b[35m",
+	LEVEL_PANIC: "\x1b[35m",
+}
+
+// Writes "2006/01/02 15:04:05 [LEVEL] message key=value" lines.
+type TextHandler struct {
+	w     io.Writer
+	color bool
+}
+
+func NewTextHandler(w io.Writer) *TextHandler {
+	return &TextHandler{w: w}
+}
+
+// A TextHandler on stderr, with the level colored when color is set.
+func NewConsoleHandler(color bool) *TextHandler {
+	return &TextHandler{w: os.Stderr, color: color}
+}
+
+func (h *TextHandler) Handle(r *Record) error {
+	var buf bytes.Buffer
+	h.format(&buf, r)
+	_, err := h.w.Write(buf.Bytes())
+
+	return err
+}
+
+func (h *TextHandler) format(buf *bytes.Buffer, r *Record) {
+	buf.WriteString(r.Time.Format("2006/01/02 15:04:05"))
+	if h.color {
+		buf.WriteString(" " + levelColor[r.Level] + "[" + levelName[r.Level] + "]\x1b[0m ")
+	} else {
+		buf.WriteString(" [" + levelName[r.Level] + "] ")
+	}
+
+	buf.WriteString(r.Message)
+	for _, k := range r.sortedKeys() {
+		fmt.Fprintf(buf, " %s=%v", k, r.Fields[k])
+	}
+
+	buf.WriteByte('\n')
+}
+
+// Writes one JSON object per line, with "time", "level" and "msg" keys
+// alongside the fields.
+type JSONHandler struct {
+	w io.Writer
+}
+
+func NewJSONHandler(w io.Writer) *JSONHandler {
+	return &JSONHandler{w: w}
+}
+
+func (h *JSONHandler) Handle(r *Record) error {
+	var buf bytes.Buffer
+	if err := h.format(&buf, r); err != nil {
+		return err
+	}
+
+	_, err := h.w.Write(buf.Bytes())
+
+	return err
+}
+
+func (h *JSONHandler) format(buf *bytes.Buffer, r *Record) error {
+	m := make(map[string]interface{}, len(r.Fields)+3)
+	for k, v := range r.Fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+
+		m[k] = v
+	}
+
+	m["time"] = r.Time.Format(time.RFC3339Nano)
+	m["level"] = levelName[r.Level]
+	m["msg"] = r.Message
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(b)
+	buf.WriteByte('\n')
+
+	return nil
+}
+
+// Open path for appending, creating it if needed, for use with a
+// TextHandler or JSONHandler.
+func OpenFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func TestTextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Record{Time: testTime, Level: LEVEL_WARN, Message: "slow query", Fields: Fields{"ms": 1200, "db": "main"}}
+	NewTextHandler(&buf).Handle(r)
+	if got := buf.String(); got != "2020/01/02 03:04:05 [WARN] slow query db=main ms=1200\n" {
+		t.Errorf("text %q", got)
+	}
+
+	buf.Reset()
+	(&TextHandler{w: &buf, color: true}).Handle(r)
+	if got := buf.String(); got != "2020/01/02 03:04:05 \x1b[33m[WARN]\x1b[0m slow query db=main ms=1200\n" {
+		t.Errorf("colored text %q", got)
+	}
+}
+
+func TestJSONHandler(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Record{Time: testTime, Level: LEVEL_ERROR, Message: "failed", Fields: Fields{"err": errors.New("disk full"), "n": 3}}
+	if err := NewJSONHandler(&buf).Handle(r); err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil || buf.Bytes()[buf.Len()-1] != '\n' {
+		t.Fatalf("line %q: %v", buf.String(), err)
+	}
+
+	want := map[string]interface{}{"time": "2020-01-02T03:04:05Z", "level": "ERROR", "msg": "failed", "err": "disk full", "n": 3.0}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+
+	if err := NewJSONHandler(&buf).Handle(&Record{Fields: Fields{"f": func() {}}}); err == nil {
+		t.Error("no error for a field JSON cannot encode")
+	}
+}
+
+func TestOpenFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, msg := range []string{"one", "two"} {
+		f, err := OpenFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		New(NewTextHandler(f), LEVEL_ALL).Info(msg)
+		f.Close()
+	}
+
+	data, _ := os.ReadFile(path)
+	if !bytes.Contains(data, []byte("[INFO] one\n")) || !bytes.Contains(data, []byte("[INFO] two\n")) {
+		t.Errorf("file %q", data)
+	}
+}
@@ -0,0 +1,231 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Levels are bit flags, so any combination of them can be enabled.
+const (
+	LEVEL_DEBUG = 1 << iota
+	LEVEL_INFO
+	LEVEL_WARN
+	LEVEL_ERROR
+	LEVEL_FATAL
+	LEVEL_PANIC
+
+	LEVEL_DISABLE = 0
+	LEVEL_DEFAULT = LEVEL_INFO | LEVEL_WARN | LEVEL_ERROR | LEVEL_FATAL | LEVEL_PANIC
+	LEVEL_ALL     = LEVEL_DEBUG | LEVEL_DEFAULT
+)
+
+var LevelText = map[string]int{
+	"debug":   LEVEL_DEBUG,
+	"info":    LEVEL_INFO,
+	"warn":    LEVEL_WARN,
+	"error":   LEVEL_ERROR,
+	"fatal":   LEVEL_FATAL,
+	"panic":   LEVEL_PANIC,
+	"default": LEVEL_DEFAULT,
+	"all":     LEVEL_ALL,
+}
+
+var levelName = map[int]string{
+	LEVEL_DEBUG: "DEBUG",
+	LEVEL_INFO:  "INFO",
+	LEVEL_WARN:  "WARN",
+	LEVEL_ERROR: "ERROR",
+	LEVEL_FATAL: "FATAL",
+	LEVEL_PANIC: "PANIC",
+}
+
+type Fields map[string]interface{}
+
+// The handler and level shared by a Logger and the loggers derived from it
+// with WithFields.
+type core struct {
+	mutex   sync.Mutex
+	handler Handler
+	level   int
+}
+
+// A Logger writes leveled lines carrying a set of fields.  It is safe for
+// concurrent use.
+type Logger struct {
+	core   *core
+	fields Fields
+}
+
+func New(handler Handler, level int) *Logger {
+	return &Logger{
+		core: &core{
+			handler: handler,
+			level:   level,
+		},
+	}
+}
+
+var std = New(NewTextHandler(os.Stderr), LEVEL_DEFAULT)
+
+// Return the logger used by the package-level functions.
+func Default() *Logger {
+	return std
+}
+
+// Return a logger that adds fields to every line, on top of l's own.
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	merged := Fields{}
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &Logger{
+		core:   l.core,
+		fields: merged,
+	}
+}
+
+func (l *Logger) SetLevel(level int) {
+	l.core.mutex.Lock()
+	l.core.level = level
+	l.core.mutex.Unlock()
+}
+
+func (l *Logger) Level() int {
+	l.core.mutex.Lock()
+	defer l.core.mutex.Unlock()
+
+	return l.core.level
+}
+
+func (l *Logger) SetHandler(handler Handler) {
+	l.core.mutex.Lock()
+	l.core.handler = handler
+	l.core.mutex.Unlock()
+}
+
+// Write plain text lines to out.
+func (l *Logger) SetOutput(out io.Writer) {
+	l.SetHandler(NewTextHandler(out))
+}
+
+func (l *Logger) Enabled(level int) bool {
+	return l.Level()&level != 0
+}
+
+func (l *Logger) output(level int, msg string) {
+	report(level, msg, l.fields)
+
+	l.core.mutex.Lock()
+	defer l.core.mutex.Unlock()
+	if l.core.level&level == 0 || l.core.handler == nil {
+		return
+	}
+
+	r := &Record{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+		Fields:  l.fields,
+	}
+
+	if err := l.core.handler.Handle(r); err != nil {
+		fmt.Fprintln(os.Stderr, "log: handler failed:", err)
+	}
+}
+
+// Wait for the handler to write what it holds back, if it is a Flusher
+// such as an AsyncHandler.  Call it before exiting.
+func (l *Logger) Flush() error {
+	l.core.mutex.Lock()
+	handler := l.core.handler
+	l.core.mutex.Unlock()
+
+	if f, ok := handler.(Flusher); ok {
+		return f.Flush()
+	}
+
+	return nil
+}
+
+func (l *Logger) Debug(v ...interface{}) {
+	l.output(LEVEL_DEBUG, fmt.Sprint(v...))
+}
+
+func (l *Logger) Info(v ...interface{}) {
+	l.output(LEVEL_INFO, fmt.Sprint(v...))
+}
+
+func (l *Logger) Warn(v ...interface{}) {
+	l.output(LEVEL_WARN, fmt.Sprint(v...))
+}
+
+func (l *Logger) Error(v ...interface{}) {
+	l.output(LEVEL_ERROR, fmt.Sprint(v...))
+}
+
+// Log at LEVEL_FATAL and exit, even when the level is disabled.
+func (l *Logger) Fatal(v ...interface{}) {
+	l.output(LEVEL_FATAL, fmt.Sprint(v...))
+	l.Flush()
+	os.Exit(1)
+}
+
+// Log at LEVEL_PANIC and panic, even when the level is disabled.
+func (l *Logger) Panic(v ...interface{}) {
+	msg := fmt.Sprint(v...)
+	l.output(LEVEL_PANIC, msg)
+	l.Flush()
+	panic(msg)
+}
+
+func WithFields(fields map[string]interface{}) *Logger {
+	return std.WithFields(fields)
+}
+
+func SetLevel(level int) {
+	std.SetLevel(level)
+}
+
+func SetHandler(handler Handler) {
+	std.SetHandler(handler)
+}
+
+func SetOutput(out io.Writer) {
+	std.SetOutput(out)
+}
+
+func Flush() error {
+	return std.Flush()
+}
+
+func Debug(v ...interface{}) {
+	std.Debug(v...)
+}
+
+func Info(v ...interface{}) {
+	std.Info(v...)
+}
+
+func Warn(v ...interface{}) {
+	std.Warn(v...)
+}
+
+func Error(v ...interface{}) {
+	std.Error(v...)
+}
+
+func Fatal(v ...interface{}) {
+	std.Fatal(v...)
+}
+
+func Panic(v ...interface{}) {
+	std.Panic(v...)
+}
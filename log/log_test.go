@@ -0,0 +1,114 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A Handler keeping the records it is given.
+type recorder struct {
+	mutex   sync.Mutex
+	records []Record
+}
+
+func (h *recorder) Handle(r *Record) error {
+	h.mutex.Lock()
+	h.records = append(h.records, *r)
+	h.mutex.Unlock()
+
+	return nil
+}
+
+func (h *recorder) messages() []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	msgs := []string{}
+	for _, r := range h.records {
+		msgs = append(msgs, levelName[r.Level]+" "+r.Message)
+	}
+
+	return msgs
+}
+
+func TestLevels(t *testing.T) {
+	h := &recorder{}
+	l := New(h, LEVEL_WARN|LEVEL_DEBUG)
+	l.Debug("a", 1)
+	l.Info("b")
+	l.Warn("c")
+	l.Error("d")
+
+	if got := strings.Join(h.messages(), ", "); got != "DEBUG a1, WARN c" {
+		t.Errorf("logged %q", got)
+	}
+
+	if !l.Enabled(LEVEL_WARN) || l.Enabled(LEVEL_ERROR) {
+		t.Errorf("Enabled wrong for level %d", l.Level())
+	}
+
+	l.SetLevel(LEVEL_DISABLE)
+	l.Error("e")
+	if len(h.records) != 2 {
+		t.Errorf("logged %v with logging disabled", h.messages())
+	}
+
+	if LevelText["all"] != LEVEL_ALL || LevelText["default"]&LEVEL_DEBUG != 0 {
+		t.Error("LevelText levels")
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	h := &recorder{}
+	l := New(h, LEVEL_ALL)
+	req := l.WithFields(Fields{"request_id": "r1", "user": "joe"})
+	sub := req.WithFields(Fields{"user": "ann", "step": 2})
+
+	l.Info("plain")
+	req.Info("req")
+	sub.Info("sub")
+
+	if len(h.records) != 3 || len(h.records[0].Fields) != 0 {
+		t.Fatalf("records %+v", h.records)
+	}
+
+	if f := h.records[1].Fields; f["user"] != "joe" || f["step"] != nil {
+		t.Errorf("parent fields %v changed by the child", f)
+	}
+
+	if f := h.records[2].Fields; f["user"] != "ann" || f["request_id"] != "r1" || f["step"] != 2 {
+		t.Errorf("child fields %v", f)
+	}
+
+	sub.SetLevel(LEVEL_ERROR)
+	l.Info("hidden")
+	if len(h.records) != 3 {
+		t.Error("level not shared with the derived logger")
+	}
+}
+
+func TestPanic(t *testing.T) {
+	h := &recorder{}
+	l := New(h, LEVEL_DISABLE)
+	defer func() {
+		if msg := recover(); msg != "boom 1" {
+			t.Errorf("panic %v", msg)
+		}
+	}()
+
+	l.Panic("boom ", 1)
+}
+
+func TestSetHandler(t *testing.T) {
+	first, second := &recorder{}, &recorder{}
+	l := New(first, LEVEL_ALL)
+	derived := l.WithFields(Fields{"a": 1})
+	l.SetHandler(second)
+	derived.Info("x")
+
+	if len(first.records) != 0 || len(second.records) != 1 {
+		t.Errorf("records %v and %v, want the new handler used by derived loggers", first.messages(), second.messages())
+	}
+
+	New(nil, LEVEL_ALL).Info("no handler")
+}
@@ -0,0 +1,80 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+)
+
+// A Reporter forwards log lines to an error tracker such as Sentry or
+// Rollbar, or by email.  Report is called synchronously, before Fatal
+// exits and Panic panics, so it should not block for long, and must not
+// itself log at ReportLevel.
+type Reporter interface {
+	Report(level int, msg string, fields Fields, stack []byte)
+}
+
+// Adapts a function to a Reporter.
+type ReporterFunc func(level int, msg string, fields Fields, stack []byte)
+
+func (f ReporterFunc) Report(level int, msg string, fields Fields, stack []byte) {
+	f(level, msg, fields, stack)
+}
+
+// The levels passed to the reporters.
+var ReportLevel = LEVEL_ERROR | LEVEL_FATAL | LEVEL_PANIC
+
+var reporters = struct {
+	sync.RWMutex
+	list []Reporter
+}{}
+
+// Pass the lines of ReportLevel of every logger, whatever its own level,
+// to r, with the stack of the goroutine that logged them.
+func AddReporter(r Reporter) {
+	reporters.Lock()
+	reporters.list = append(reporters.list, r)
+	reporters.Unlock()
+}
+
+// Remove every reporter.
+func ClearReporters() {
+	reporters.Lock()
+	reporters.list = nil
+	reporters.Unlock()
+}
+
+func report(level int, msg string, fields Fields) {
+	if ReportLevel&level == 0 {
+		return
+	}
+
+	reporters.RLock()
+	list := reporters.list
+	reporters.RUnlock()
+	if len(list) == 0 {
+		return
+	}
+
+	stack := debug.Stack()
+	for _, r := range list {
+		copied := Fields{}
+		for k, v := range fields {
+			copied[k] = v
+		}
+
+		callReporter(r, level, msg, copied, stack)
+	}
+}
+
+// A reporter that panics must not take the logging goroutine with it.
+func callReporter(r Reporter, level int, msg string, fields Fields, stack []byte) {
+	defer func() {
+		if err := recover(); err != nil {
+			fmt.Fprintln(os.Stderr, "log: reporter panicked:", err)
+		}
+	}()
+
+	r.Report(level, msg, fields, stack)
+}
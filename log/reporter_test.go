@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReporters(t *testing.T) {
+	defer ClearReporters()
+
+	type report struct {
+		level  int
+		msg    string
+		fields Fields
+		stack  []byte
+	}
+
+	reports := []report{}
+	AddReporter(ReporterFunc(func(level int, msg string, fields Fields, stack []byte) {
+		fields["changed"] = true
+		reports = append(reports, report{level, msg, fields, stack})
+	}))
+	AddReporter(ReporterFunc(func(int, string, Fields, []byte) {
+		panic("broken tracker")
+	}))
+
+	l := New(&recorder{}, LEVEL_DISABLE).WithFields(Fields{"user": "joe"})
+	l.Info("not reported")
+	l.Error("payment failed")
+	func() {
+		defer func() { recover() }()
+		l.Panic("boom")
+	}()
+
+	if len(reports) != 2 || reports[0].msg != "payment failed" || reports[0].level != LEVEL_ERROR || reports[1].level != LEVEL_PANIC {
+		t.Fatalf("reports %+v", reports)
+	}
+
+	if reports[0].fields["user"] != "joe" || l.fields["changed"] != nil {
+		t.Errorf("fields %v given to the reporter, logger's own now %v", reports[0].fields, l.fields)
+	}
+
+	if !bytes.Contains(reports[0].stack, []byte("TestReporters")) {
+		t.Errorf("stack does not show the logging goroutine:\n%s", reports[0].stack)
+	}
+
+	ClearReporters()
+	l.Error("after clear")
+	if len(reports) != 2 {
+		t.Error("reported after ClearReporters")
+	}
+}
@@ -0,0 +1,146 @@
+package log
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"golanger.com/framework/middleware"
+	"golanger.com/framework/request"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+type RequestLoggerOptions struct {
+	// The logger written to, Default() if nil.
+	Logger *Logger
+
+	// The fraction of requests answered below 400 that are logged, for
+	// busy servers; 0 logs them all.  Client and server errors are always
+	// logged.
+	SampleRate float64
+
+	// The header carrying the request ID, "X-Request-ID" by default.  A
+	// request without a well-formed one is given a random ID, set on the
+	// request and the response and stored in its context like RequestID
+	// does, unless RequestID ran before.
+	RequestIDHeader string
+
+	// Trust the peer as a proxy when no trusted proxies are configured,
+	// see request.ClientIP.  The remote_ip field is request.RealIP.
+	TrustProxy bool
+}
+
+// Records the status and size of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += int64(n)
+
+	return n, err
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("log: ResponseWriter does not support Hijack")
+	}
+
+	return h.Hijack()
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// Return a middleware that logs one line per request, with the method,
+// path, status, bytes, latency (in milliseconds), remote_ip and
+// request_id fields, at LEVEL_ERROR for server errors, LEVEL_WARN for
+// client errors and LEVEL_INFO otherwise.
+func RequestLogger(opts ...RequestLoggerOptions) middleware.Middleware {
+	var o RequestLoggerOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Logger == nil {
+		o.Logger = std
+	}
+
+	if o.RequestIDHeader == "" {
+		o.RequestIDHeader = "X-Request-ID"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := requestID(r, o.RequestIDHeader, nil)
+			r.Header.Set(o.RequestIDHeader, id)
+			w.Header().Set(o.RequestIDHeader, id)
+			r = r.WithContext(WithRequestID(r.Context(), id))
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if status < 400 && o.SampleRate > 0 && mathrand.Float64() >= o.SampleRate {
+				return
+			}
+
+			l := o.Logger.WithFields(Fields{
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"status":     status,
+				"bytes":      sw.bytes,
+				"latency":    float64(time.Since(start).Microseconds()) / 1000,
+				"remote_ip":  request.ClientIP(r, o.TrustProxy),
+				"request_id": id,
+			})
+
+			msg := r.Method + " " + r.URL.Path
+			switch {
+			case status >= 500:
+				l.Error(msg)
+			case status >= 400:
+				l.Warn(msg)
+			default:
+				l.Info(msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package log
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestLogger(t *testing.T) {
+	h := &recorder{}
+	mw := RequestLogger(RequestLoggerOptions{Logger: New(h, LEVEL_ALL)})
+	statuses := map[string]int{"/ok": 0, "/missing": 404, "/broken": 500}
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status := statuses[r.URL.Path]; status != 0 {
+			w.WriteHeader(status)
+		}
+
+		w.Write([]byte("hello"))
+	}))
+
+	for _, path := range []string{"/ok", "/missing", "/broken"} {
+		r := httptest.NewRequest("GET", path, nil)
+		r.RemoteAddr = "203.0.113.7:5000"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Header().Get("X-Request-ID") == "" {
+			t.Errorf("%s: no request ID on the response", path)
+		}
+	}
+
+	if got := h.messages(); len(got) != 3 || got[0] != "INFO GET /ok" || got[1] != "WARN GET /missing" || got[2] != "ERROR GET /broken" {
+		t.Fatalf("logged %v", got)
+	}
+
+	f := h.records[0].Fields
+	if f["status"] != http.StatusOK || f["bytes"] != int64(5) || f["remote_ip"] != "203.0.113.7" || f["path"] != "/ok" {
+		t.Errorf("fields %v", f)
+	}
+
+	if _, ok := f["latency"].(float64); !ok || len(f["request_id"].(string)) != 16 {
+		t.Errorf("latency %v, request_id %v", f["latency"], f["request_id"])
+	}
+}
+
+func TestRequestLoggerSampling(t *testing.T) {
+	h := &recorder{}
+	handler := RequestLogger(RequestLoggerOptions{Logger: New(h, LEVEL_ALL), SampleRate: 1e-12})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+
+	for i := 0; i < 10; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/missing", nil))
+	if got := h.messages(); len(got) != 1 || got[0] != "WARN GET /missing" {
+		t.Errorf("logged %v, want only the client error", got)
+	}
+}
+
+func TestStatusWriterUnwrap(t *testing.T) {
+	w := httptest.NewRecorder()
+	sw := &statusWriter{ResponseWriter: w}
+	if http.NewResponseController(sw).Flush() != nil || !w.Flushed {
+		t.Error("Flush not passed through")
+	}
+
+	if _, _, err := sw.Hijack(); err == nil {
+		t.Error("Hijack of a writer that cannot")
+	}
+}
@@ -0,0 +1,99 @@
+package log
+
+import (
+	"context"
+	"golanger.com/framework/middleware"
+	"net/http"
+)
+
+type requestIDKey struct{}
+
+type RequestIDOptions struct {
+	// The header carrying the request ID, "X-Request-ID" by default.
+	Header string
+
+	// Return a new request ID, 16 random hex digits if nil.
+	Generate func() string
+}
+
+// Report whether an incoming request ID is safe to log and echo: at most
+// 128 letters, digits and -_.:/+= characters.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':' || c == '/' || c == '+' || c == '=':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// Return the ID of a request: the one of its context if the request ID
+// middleware ran, else that of header if well formed, else a new one.
+func requestID(r *http.Request, header string, generate func() string) string {
+	if id := RequestIDFromContext(r.Context()); id != "" {
+		return id
+	}
+
+	if id := r.Header.Get(header); validRequestID(id) {
+		return id
+	}
+
+	if generate != nil {
+		return generate()
+	}
+
+	return newRequestID()
+}
+
+// Return a middleware giving every request an ID, the one of its header
+// if well formed or else a new one.  The ID is set on the request header
+// and the response header and stored in the request context, where
+// FromRequest adds it to the log lines of the request.
+func RequestID(opts ...RequestIDOptions) middleware.Middleware {
+	var o RequestIDOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Header == "" {
+		o.Header = "X-Request-ID"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := requestID(r, o.Header, o.Generate)
+			r.Header.Set(o.Header, id)
+			w.Header().Set(o.Header, id)
+			next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// Return the request ID stored in ctx, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Return a logger adding the request ID of ctx, if any, to l's lines as
+// the request_id field.
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	id := RequestIDFromContext(ctx)
+	if id == "" {
+		return l
+	}
+
+	return l.WithFields(Fields{"request_id": id})
+}
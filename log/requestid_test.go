@@ -0,0 +1,81 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	h := RequestID()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+		if r.Header.Get("X-Request-ID") != seen {
+			t.Errorf("request header %q, context %q", r.Header.Get("X-Request-ID"), seen)
+		}
+	}))
+
+	cases := map[string]bool{
+		"abc-123":                true,
+		"trace:1/2+3=":           true,
+		"":                       false,
+		"has space":              false,
+		"line\nbreak":            false,
+		"<script>":               false,
+		strings.Repeat("a", 129): false,
+		"ünïcode":                false,
+	}
+
+	for incoming, kept := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Request-ID", incoming)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if got := w.Header().Get("X-Request-ID"); got != seen || (got == incoming) != kept {
+			t.Errorf("incoming %q: response %q, context %q", incoming, got, seen)
+		}
+
+		if !kept && len(seen) != 16 {
+			t.Errorf("incoming %q replaced by %q", incoming, seen)
+		}
+	}
+}
+
+func TestRequestIDOptions(t *testing.T) {
+	h := RequestID(RequestIDOptions{Header: "X-Trace", Generate: func() string { return "fixed" }})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if got := w.Header().Get("X-Trace"); got != "fixed" {
+		t.Errorf("X-Trace %q", got)
+	}
+}
+
+func TestRequestIDKeptByRequestLogger(t *testing.T) {
+	rec := &recorder{}
+	var inner string
+	h := RequestID()(RequestLogger(RequestLoggerOptions{Logger: New(rec, LEVEL_ALL)})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inner = RequestIDFromContext(r.Context())
+	})))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if id := w.Header().Get("X-Request-ID"); id == "" || inner != id || rec.records[0].Fields["request_id"] != id {
+		t.Errorf("IDs %q, %q, %v, want one", id, inner, rec.records[0].Fields["request_id"])
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	rec := &recorder{}
+	l := New(rec, LEVEL_ALL)
+	if l.WithContext(context.Background()) != l {
+		t.Error("new logger without a request ID")
+	}
+
+	l.WithContext(WithRequestID(context.Background(), "r1")).Info("x")
+	if rec.records[0].Fields["request_id"] != "r1" {
+		t.Errorf("fields %v", rec.records[0].Fields)
+	}
+}
@@ -0,0 +1,219 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102-150405.000"
+
+// A RotatingFile is an io.WriteCloser that starts a new file once the
+// current one reaches maxSize or has been written for maxAge.  Old files
+// are renamed to path.<timestamp> (gzipped when Compress is set) and only
+// the newest maxBackups of them younger than maxAge are kept.  A zero limit
+// disables that limit.  It is safe for concurrent use.
+type RotatingFile struct {
+	Compress bool
+
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	file       *os.File
+	size       int64
+	openedAt   time.Time
+}
+
+func NewRotatingFile(path string, maxSizeMB, maxBackups, maxAgeDays int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) << 20,
+		maxBackups: maxBackups,
+		maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	if dir := filepath.Dir(f.path); dir != "" {
+		os.MkdirAll(dir, 0755)
+	}
+
+	file, err := OpenFile(f.path)
+	if err != nil {
+		return err
+	}
+
+	fi, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = fi.Size()
+	f.openedAt = time.Now()
+
+	return nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	tooBig := f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize
+	tooOld := f.maxAge > 0 && time.Since(f.openedAt) >= f.maxAge
+	if tooBig || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Start a new file now, regardless of size and age.
+func (f *RotatingFile) Rotate() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.rotate()
+}
+
+func (f *RotatingFile) rotate() error {
+	if f.file != nil {
+		if err := f.file.Close(); err != nil {
+			return err
+		}
+
+		f.file = nil
+	}
+
+	backup := f.backupName(time.Now())
+	if err := os.Rename(f.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.Compress {
+		if err := compressFile(backup); err != nil {
+			return err
+		}
+	}
+
+	f.removeOldBackups()
+
+	return nil
+}
+
+// Return the name of a backup made at t, a millisecond later for each
+// backup already made in the same millisecond, which it would overwrite.
+func (f *RotatingFile) backupName(t time.Time) string {
+	for {
+		backup := f.path + "." + t.Format(backupTimeFormat)
+		_, err := os.Lstat(backup)
+		_, gzErr := os.Lstat(backup + ".gz")
+		if os.IsNotExist(err) && os.IsNotExist(gzErr) {
+			return backup
+		}
+
+		t = t.Add(time.Millisecond)
+	}
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func (f *RotatingFile) removeOldBackups() {
+	matches, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+
+	backups := []string{}
+	times := map[string]time.Time{}
+	for _, backup := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(backup, f.path+"."), ".gz")
+		if t, err := time.ParseInLocation(backupTimeFormat, name, time.Local); err == nil {
+			backups = append(backups, backup)
+			times[backup] = t
+		}
+	}
+
+	// The timestamp suffix sorts oldest first.
+	sort.Strings(backups)
+	for i, backup := range backups {
+		excess := f.maxBackups > 0 && i < len(backups)-f.maxBackups
+		expired := f.maxAge > 0 && time.Since(times[backup]) > f.maxAge
+		if excess || expired {
+			os.Remove(backup)
+		}
+	}
+}
+
+func (f *RotatingFile) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
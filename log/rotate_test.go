@@ -0,0 +1,106 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Return the contents of the backups of path, oldest first, decompressed.
+func backups(t *testing.T, path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+	sort.Strings(matches)
+	contents := []string{}
+	for _, m := range matches {
+		f, err := os.Open(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var r io.Reader = f
+		if strings.HasSuffix(m, ".gz") {
+			if r, err = gzip.NewReader(f); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		data, _ := io.ReadAll(r)
+		f.Close()
+		contents = append(contents, string(data))
+	}
+
+	return contents
+}
+
+func TestRotateBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	f, err := NewRotatingFile(path, 1, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	line := strings.Repeat("x", 600<<10) + "\n"
+	for _, c := range []string{"a", "b", "c", "d"} {
+		if _, err := f.Write([]byte(c + line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := backups(t, path)
+	if len(got) != 2 || got[0][0] != 'b' || got[1][0] != 'c' {
+		t.Fatalf("%d backups, want b and c kept", len(got))
+	}
+
+	data, _ := os.ReadFile(path)
+	if len(data) == 0 || data[0] != 'd' {
+		t.Error("current file does not hold the last write")
+	}
+}
+
+func TestRotateCompress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := NewRotatingFile(path, 0, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Compress = true
+	f.Write([]byte("first\n"))
+	f.Rotate()
+	f.Write([]byte("second\n"))
+	f.Rotate()
+	f.Close()
+
+	if got := backups(t, path); len(got) != 2 || got[0] != "first\n" || got[1] != "second\n" {
+		t.Errorf("backups %q", got)
+	}
+
+	if matches, _ := filepath.Glob(path + ".*[0-9]"); len(matches) != 0 {
+		t.Errorf("uncompressed backups %v left", matches)
+	}
+
+	if _, err := f.Write([]byte("late")); err != os.ErrClosed {
+		t.Errorf("write after Close: %v", err)
+	}
+}
+
+func TestRotatingFileAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("old\n"), 0644)
+	f, err := NewRotatingFile(path, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.Write([]byte("new\n"))
+	f.Close()
+
+	if data, _ := os.ReadFile(path); string(data) != "old\nnew\n" {
+		t.Errorf("file %q", data)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package log
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// Sends records to the system logger, mapping levels to syslog severities.
+type SyslogHandler struct {
+	w *syslog.Writer
+}
+
+func NewSyslogHandler(tag string) (*SyslogHandler, error) {
+	w, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SyslogHandler{w: w}, nil
+}
+
+func (h *SyslogHandler) Handle(r *Record) error {
+	msg := r.Message
+	for _, k := range r.sortedKeys() {
+		msg += " " + k + "=" + fmt.Sprint(r.Fields[k])
+	}
+
+	switch r.Level {
+	case LEVEL_DEBUG:
+		return h.w.Debug(msg)
+	case LEVEL_INFO:
+		return h.w.Info(msg)
+	case LEVEL_WARN:
+		return h.w.Warning(msg)
+	case LEVEL_ERROR:
+		return h.w.Err(msg)
+	}
+
+	return h.w.Crit(msg)
+}
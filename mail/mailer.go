@@ -0,0 +1,221 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/render"
+	"html"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"text/template"
+	"time"
+)
+
+type Options struct {
+	// Delivers the messages unless Dev is set.
+	Sender Sender
+
+	// The sender of the messages that name none.
+	From string
+
+	// Where the templates are, "./view/mail/" if empty: name.html is
+	// rendered with render, inside Layout if set, and name.txt with
+	// text/template.  Both get the funcs of render, msg translating into
+	// the locale of the message.
+	Directory string
+	Layout    string
+	Funcs     map[string]interface{}
+
+	// Log the messages, and write them as .eml files to DevDirectory if
+	// set, instead of sending them.  It is always set under `framework
+	// run`, which sets GOLANGER_DEV.
+	Dev          bool
+	DevDirectory string
+}
+
+// A Mailer renders messages from templates and sends them.  It is safe
+// for concurrent use.
+type Mailer struct {
+	opts   Options
+	render *render.Render
+}
+
+var ErrNoSender = errors.New("mail: no Sender configured")
+
+func New(opts Options) *Mailer {
+	if opts.Directory == "" {
+		opts.Directory = "./view/mail/"
+	}
+
+	if os.Getenv("GOLANGER_DEV") != "" {
+		opts.Dev = true
+	}
+
+	return &Mailer{
+		opts: opts,
+		render: render.New(render.Options{
+			Directory: opts.Directory,
+			Layout:    opts.Layout,
+			Funcs:     opts.Funcs,
+		}),
+	}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Set the bodies of m from the templates of name in locale: the HTML one
+// from name.html and the text one from name.txt, or else from the HTML
+// one stripped of its tags.  At least one of them must exist.
+func (ml *Mailer) Render(m *Message, name, locale string, data interface{}) error {
+	htmlPath := filepath.Join(ml.opts.Directory, name+".html")
+	textPath := filepath.Join(ml.opts.Directory, name+".txt")
+	if !exists(htmlPath) && !exists(textPath) {
+		return errors.New("mail: no template " + name + ".html or " + name + ".txt in " + ml.opts.Directory)
+	}
+
+	if exists(htmlPath) {
+		var buf bytes.Buffer
+		if err := ml.render.ExecuteLocale(&buf, name, locale, data); err != nil {
+			return err
+		}
+
+		m.HTML = buf.String()
+	}
+
+	if exists(textPath) {
+		text, err := ml.renderText(textPath, locale, data)
+		if err != nil {
+			return err
+		}
+
+		m.Text = text
+	} else {
+		m.Text = TextFromHTML(m.HTML)
+	}
+
+	return nil
+}
+
+func (ml *Mailer) renderText(path, locale string, data interface{}) (string, error) {
+	funcs := template.FuncMap{}
+	for k, v := range render.Funcs {
+		funcs[k] = v
+	}
+
+	for k, v := range i18n.Funcs(locale) {
+		funcs[k] = v
+	}
+
+	for k, v := range ml.opts.Funcs {
+		funcs[k] = v
+	}
+
+	t, err := template.New(filepath.Base(path)).Funcs(funcs).ParseFiles(path)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+var (
+	blockEnd  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr|table)>`)
+	link      = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	skipped   = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>`)
+	tag       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankRuns = regexp.MustCompile(`\n\s*\n\s*\n+`)
+)
+
+// Return a plain text version of an HTML body, for clients that do not
+// show HTML: links are followed by their URL and blocks by a new line.
+func TextFromHTML(s string) string {
+	s = skipped.ReplaceAllString(s, "")
+	s = link.ReplaceAllString(s, "$2 ($1)")
+	s = blockEnd.ReplaceAllString(s, "$0\n")
+	s = tag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return strings.TrimSpace(blankRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")) + "\n"
+}
+
+// Send m, from the From of the options if it names no sender, or in Dev
+// mode log it and save it to DevDirectory.
+func (ml *Mailer) Send(ctx context.Context, m *Message) error {
+	if m.From == "" {
+		m.From = ml.opts.From
+	}
+
+	if ml.opts.Dev {
+		return ml.keep(ctx, m)
+	}
+
+	if ml.opts.Sender == nil {
+		return ErrNoSender
+	}
+
+	return ml.opts.Sender.Send(ctx, m)
+}
+
+// Render the template name in locale for m, see Render, and send it.
+func (ml *Mailer) SendTemplate(ctx context.Context, m *Message, name, locale string, data interface{}) error {
+	if err := ml.Render(m, name, locale, data); err != nil {
+		return err
+	}
+
+	return ml.Send(ctx, m)
+}
+
+var devCount uint64
+
+// Log m and write it out as an .eml file, which mail clients open.
+func (ml *Mailer) keep(ctx context.Context, m *Message) error {
+	data, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	l := log.FromContext(ctx).WithFields(log.Fields{
+		"to":      strings.Join(m.To, ", "),
+		"subject": m.Subject,
+	})
+
+	if ml.opts.DevDirectory == "" {
+		l.Info("<mail.Mailer.Send> not sent in development mode:\n", m.Text)
+		return nil
+	}
+
+	if err := os.MkdirAll(ml.opts.DevDirectory, 0755); err != nil {
+		return err
+	}
+
+	name := time.Now().Format("20060102-150405.000") + "-" + strconv.FormatUint(atomic.AddUint64(&devCount, 1), 10) + ".eml"
+	path := filepath.Join(ml.opts.DevDirectory, name)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+
+	l.Info("<mail.Mailer.Send> not sent in development mode, written to ", path)
+
+	return nil
+}
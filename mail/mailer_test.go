@@ -0,0 +1,121 @@
+package mail
+
+import (
+	"context"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+// Write the templates in files, by name, to a new directory.
+func templates(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestRender(t *testing.T) {
+	i18n.Default.SetMessages("fr", map[string]string{"mail.test.hello": "Bonjour %s"})
+	dir := templates(t, map[string]string{
+		"welcome.html": `<p>{{msg "mail.test.hello" .Name}}</p>`,
+		"welcome.txt":  `{{msg "mail.test.hello" .Name}} & bienvenue`,
+		"reset.html":   `<h1>Reset</h1><p>Follow <a href="https://x.test/r?a=1&amp;b=2">this link</a>.</p>`,
+	})
+	ml := New(Options{Directory: dir})
+
+	m := &Message{}
+	if err := ml.Render(m, "welcome", "fr", map[string]string{"Name": "Ann"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.HTML != "<p>Bonjour Ann</p>" || m.Text != "Bonjour Ann & bienvenue" {
+		t.Errorf("rendered %q and %q", m.HTML, m.Text)
+	}
+
+	m = &Message{}
+	if err := ml.Render(m, "reset", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Text != "Reset\nFollow this link (https://x.test/r?a=1&b=2).\n" {
+		t.Errorf("text from HTML %q", m.Text)
+	}
+
+	if err := ml.Render(&Message{}, "missing", "", nil); err == nil {
+		t.Error("no error for a missing template")
+	}
+}
+
+func TestTextFromHTML(t *testing.T) {
+	in := "<html><head><title>T</title><style>p {}</style></head><body>\n" +
+		"<h1>Hi</h1>\n\n\n<p>One<br>two</p><script>x()</script><ul><li>a</li><li>b</li></ul></body></html>"
+	if got := TextFromHTML(in); got != "Hi\n\nOne\ntwo\na\nb\n" {
+		t.Errorf("TextFromHTML = %q", got)
+	}
+}
+
+func TestSend(t *testing.T) {
+	t.Setenv("GOLANGER_DEV", "")
+	var sent *Message
+	ml := New(Options{
+		From:   "shop@example.com",
+		Sender: SenderFunc(func(ctx context.Context, m *Message) error { sent = m; return nil }),
+	})
+
+	m := &Message{To: []string{"ann@example.com"}, Text: "hi"}
+	if err := ml.Send(context.Background(), m); err != nil || sent != m || m.From != "shop@example.com" {
+		t.Errorf("Send = %v, sent %v", err, sent)
+	}
+
+	m = &Message{From: "other@example.com", To: []string{"ann@example.com"}}
+	ml.Send(context.Background(), m)
+	if m.From != "other@example.com" {
+		t.Errorf("From replaced by %s", m.From)
+	}
+
+	if err := New(Options{}).Send(context.Background(), m); err != ErrNoSender {
+		t.Errorf("Send without a Sender: %v", err)
+	}
+}
+
+func TestSendDev(t *testing.T) {
+	t.Setenv("GOLANGER_DEV", "1")
+	dir := filepath.Join(t.TempDir(), "mail")
+	ml := New(Options{
+		From:         "shop@example.com",
+		Sender:       SenderFunc(func(ctx context.Context, m *Message) error { t.Error("sent in Dev mode"); return nil }),
+		DevDirectory: dir,
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := ml.Send(context.Background(), &Message{To: []string{"ann@example.com"}, Subject: "Hi", Text: "hi"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 2 {
+		t.Fatalf("wrote %v", files)
+	}
+
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "Subject: Hi\r\n") {
+		t.Errorf("wrote %q", data)
+	}
+
+	if err := New(Options{}).Send(context.Background(), &Message{From: "a@example.com", Text: "hi"}); err != nil {
+		t.Errorf("Send in Dev mode without DevDirectory: %v", err)
+	}
+}
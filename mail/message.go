@@ -0,0 +1,280 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A file sent with a Message.  An Inline one, such as an image the HTML
+// body shows as <img src="cid:ContentID">, is part of the body rather
+// than an attachment.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+	Inline      bool
+	ContentID   string
+}
+
+// An email.  Addresses may carry a name, as in "Ann <ann@example.com>".
+// Bcc recipients are sent the message but not named in it.
+type Message struct {
+	From        string
+	To          []string
+	Cc          []string
+	Bcc         []string
+	ReplyTo     string
+	Subject     string
+	Text        string
+	HTML        string
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+// Attach a file, with the content type of its extension.
+func (m *Message) Attach(filename string, data []byte) *Message {
+	m.Attachments = append(m.Attachments, Attachment{
+		Filename:    filename,
+		ContentType: contentType(filename),
+		Data:        data,
+	})
+
+	return m
+}
+
+// Attach a file that the HTML body refers to as cid:<filename>.
+func (m *Message) Embed(filename string, data []byte) *Message {
+	m.Attachments = append(m.Attachments, Attachment{
+		Filename:    filename,
+		ContentType: contentType(filename),
+		Data:        data,
+		Inline:      true,
+		ContentID:   filepath.Base(filename),
+	})
+
+	return m
+}
+
+func contentType(filename string) string {
+	if ct := mime.TypeByExtension(filepath.Ext(filename)); ct != "" {
+		return ct
+	}
+
+	return "application/octet-stream"
+}
+
+// Return the addresses of every recipient, without their names.
+func (m *Message) Recipients() ([]string, error) {
+	rcpts := []string{}
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, a := range list {
+			addr, err := mail.ParseAddress(a)
+			if err != nil {
+				return nil, fmt.Errorf("mail: recipient %q: %v", a, err)
+			}
+
+			rcpts = append(rcpts, addr.Address)
+		}
+	}
+
+	return rcpts, nil
+}
+
+// Return the address of the sender, without its name.
+func (m *Message) Sender() (string, error) {
+	addr, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return "", fmt.Errorf("mail: sender %q: %v", m.From, err)
+	}
+
+	return addr.Address, nil
+}
+
+func formatAddresses(list []string) (string, error) {
+	formatted := make([]string, len(list))
+	for i, a := range list {
+		addr, err := mail.ParseAddress(a)
+		if err != nil {
+			return "", fmt.Errorf("mail: address %q: %v", a, err)
+		}
+
+		formatted[i] = addr.String()
+	}
+
+	return strings.Join(formatted, ", "), nil
+}
+
+func boundary() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+
+	return "=_" + hex.EncodeToString(b)
+}
+
+// Write a header, with an ASCII name and a value already encoded.
+func writeHeader(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name + ": " + value + "\r\n")
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, body string) {
+	qp := quotedprintable.NewWriter(buf)
+	qp.Write([]byte(strings.Replace(strings.Replace(body, "\r\n", "\n", -1), "\n", "\r\n", -1)))
+	qp.Close()
+	buf.WriteString("\r\n")
+}
+
+func writeBase64(buf *bytes.Buffer, data []byte) {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		buf.WriteString(enc[:76] + "\r\n")
+		enc = enc[76:]
+	}
+
+	buf.WriteString(enc + "\r\n")
+}
+
+// Write a text part.
+func writeText(buf *bytes.Buffer, contentType, body string) {
+	writeHeader(buf, "Content-Type", contentType+"; charset=utf-8")
+	writeHeader(buf, "Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+	writeQuotedPrintable(buf, body)
+}
+
+// Write the text and HTML bodies, as alternatives if there are both.
+func (m *Message) writeBody(buf *bytes.Buffer) {
+	switch {
+	case m.HTML == "":
+		writeText(buf, "text/plain", m.Text)
+	case m.Text == "":
+		writeText(buf, "text/html", m.HTML)
+	default:
+		b := boundary()
+		writeHeader(buf, "Content-Type", `multipart/alternative; boundary="`+b+`"`)
+		buf.WriteString("\r\n--" + b + "\r\n")
+		writeText(buf, "text/plain", m.Text)
+		buf.WriteString("--" + b + "\r\n")
+		writeText(buf, "text/html", m.HTML)
+		buf.WriteString("--" + b + "--\r\n")
+	}
+}
+
+func writeAttachment(buf *bytes.Buffer, a Attachment) {
+	ct := a.ContentType
+	if ct == "" {
+		ct = contentType(a.Filename)
+	}
+
+	name := mime.QEncoding.Encode("utf-8", strings.NewReplacer(`"`, "_", `\`, "_").Replace(filepath.Base(a.Filename)))
+	disposition := "attachment"
+	if a.Inline {
+		disposition = "inline"
+	}
+
+	writeHeader(buf, "Content-Type", ct+`; name="`+name+`"`)
+	writeHeader(buf, "Content-Disposition", disposition+`; filename="`+name+`"`)
+	if a.ContentID != "" {
+		writeHeader(buf, "Content-ID", "<"+a.ContentID+">")
+	}
+
+	writeHeader(buf, "Content-Transfer-Encoding", "base64")
+	buf.WriteString("\r\n")
+	writeBase64(buf, a.Data)
+}
+
+// Return the message in the MIME format of RFC 5322, with its Date and a
+// new Message-ID unless Headers set them.
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	from, err := formatAddresses([]string{m.From})
+	if err != nil {
+		return nil, err
+	}
+
+	writeHeader(&buf, "From", from)
+	for _, h := range []struct {
+		name string
+		list []string
+	}{{"To", m.To}, {"Cc", m.Cc}} {
+		if len(h.list) > 0 {
+			value, err := formatAddresses(h.list)
+			if err != nil {
+				return nil, err
+			}
+
+			writeHeader(&buf, h.name, value)
+		}
+	}
+
+	if m.ReplyTo != "" {
+		value, err := formatAddresses([]string{m.ReplyTo})
+		if err != nil {
+			return nil, err
+		}
+
+		writeHeader(&buf, "Reply-To", value)
+	}
+
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	headers := map[string]string{
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-ID":   "<" + boundary()[2:] + "@" + domain(m.From) + ">",
+		"MIME-Version": "1.0",
+	}
+
+	for k, v := range m.Headers {
+		headers[k] = v
+	}
+
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+	for _, k := range names {
+		if strings.ContainsAny(k+headers[k], "\r\n") {
+			return nil, fmt.Errorf("mail: header %q has a line break", k)
+		}
+
+		writeHeader(&buf, k, mime.QEncoding.Encode("utf-8", headers[k]))
+	}
+
+	if len(m.Attachments) == 0 {
+		m.writeBody(&buf)
+		return buf.Bytes(), nil
+	}
+
+	b := boundary()
+	writeHeader(&buf, "Content-Type", `multipart/mixed; boundary="`+b+`"`)
+	buf.WriteString("\r\n--" + b + "\r\n")
+	m.writeBody(&buf)
+	for _, a := range m.Attachments {
+		buf.WriteString("--" + b + "\r\n")
+		writeAttachment(&buf, a)
+	}
+
+	buf.WriteString("--" + b + "--\r\n")
+
+	return buf.Bytes(), nil
+}
+
+func domain(address string) string {
+	if addr, err := mail.ParseAddress(address); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			return addr.Address[i+1:]
+		}
+	}
+
+	return "localhost"
+}
@@ -0,0 +1,151 @@
+package mail
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Parse the output of m.Bytes.
+func parse(t *testing.T, m *Message) *mail.Message {
+	data, err := m.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("%v in\n%s", err, data)
+	}
+
+	return msg
+}
+
+func TestBytesText(t *testing.T) {
+	m := &Message{
+		From:    "Shop <shop@example.com>",
+		To:      []string{"Ann <ann@example.com>", "bob@example.com"},
+		Bcc:     []string{"audit@example.com"},
+		ReplyTo: "help@example.com",
+		Subject: "Café ready",
+		Text:    "Hello Ann,\nyour order is ready.",
+		Headers: map[string]string{"X-Order": "42", "Message-ID": "<fixed@example.com>"},
+	}
+
+	msg := parse(t, m)
+	h := msg.Header
+	subject, _ := new(mime.WordDecoder).DecodeHeader(h.Get("Subject"))
+	if h.Get("From") != `"Shop" <shop@example.com>` || h.Get("Reply-To") != "<help@example.com>" ||
+		subject != "Café ready" || h.Get("X-Order") != "42" || h.Get("Message-Id") != "<fixed@example.com>" {
+		t.Errorf("headers %v", h)
+	}
+
+	if to, err := h.AddressList("To"); err != nil || len(to) != 2 || to[0].Name != "Ann" {
+		t.Errorf("To %v, %v", to, err)
+	}
+
+	if h.Get("Bcc") != "" || h.Get("Date") == "" || !strings.HasPrefix(h.Get("Content-Type"), "text/plain") {
+		t.Errorf("headers %v", h)
+	}
+
+	body, _ := io.ReadAll(msg.Body)
+	if string(body) != "Hello Ann,\r\nyour order is ready.\r\n" {
+		t.Errorf("body %q", body)
+	}
+
+	rcpts, err := m.Recipients()
+	if err != nil || !reflect.DeepEqual(rcpts, []string{"ann@example.com", "bob@example.com", "audit@example.com"}) {
+		t.Errorf("Recipients = %v, %v", rcpts, err)
+	}
+
+	if from, err := m.Sender(); err != nil || from != "shop@example.com" {
+		t.Errorf("Sender = %v, %v", from, err)
+	}
+}
+
+func TestBytesMultipart(t *testing.T) {
+	m := &Message{From: "shop@example.com", To: []string{"ann@example.com"}, Text: "text", HTML: `<img src="cid:logo.png">`}
+	m.Attach("invoice.pdf", []byte("%PDF")).Embed("img/logo.png", []byte("png"))
+
+	msg := parse(t, m)
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/mixed" {
+		t.Fatalf("Content-Type %s", mediaType)
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	body, _ := mr.NextPart()
+	mediaType, params, _ = mime.ParseMediaType(body.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("body Content-Type %s", mediaType)
+	}
+
+	alt := multipart.NewReader(body, params["boundary"])
+	for _, want := range []string{"text/plain", "text/html"} {
+		p, err := alt.NextPart()
+		if err != nil || !strings.HasPrefix(p.Header.Get("Content-Type"), want) {
+			t.Errorf("alternative %v, %v, want %s", p, err, want)
+		}
+	}
+
+	cases := []struct {
+		ct, disposition, id, base64 string
+	}{
+		{"application/pdf", `attachment; filename="invoice.pdf"`, "", "JVBERg=="},
+		{"image/png", `inline; filename="logo.png"`, "<logo.png>", "cG5n"},
+	}
+
+	for _, c := range cases {
+		p, err := mr.NextPart()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := io.ReadAll(p)
+		if !strings.HasPrefix(p.Header.Get("Content-Type"), c.ct) || p.Header.Get("Content-Disposition") != c.disposition ||
+			p.Header.Get("Content-Id") != c.id || p.Header.Get("Content-Transfer-Encoding") != "base64" {
+			t.Errorf("attachment %v", p.Header)
+		}
+
+		if strings.TrimSpace(string(data)) != c.base64 {
+			t.Errorf("attachment data %q", data)
+		}
+	}
+
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("extra part: %v", err)
+	}
+}
+
+func TestBytesErrors(t *testing.T) {
+	cases := map[string]*Message{
+		"bad sender":    {From: "not an address", To: []string{"a@example.com"}},
+		"bad recipient": {From: "a@example.com", To: []string{"@"}},
+		"header break":  {From: "a@example.com", Headers: map[string]string{"X-Bad": "a\r\nBcc: evil@example.com"}},
+	}
+
+	for name, m := range cases {
+		if _, err := m.Bytes(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+
+	if _, err := (&Message{From: "a@example.com", Bcc: []string{"nope"}}).Recipients(); err == nil {
+		t.Error("no error for a bad Bcc")
+	}
+}
+
+func TestWriteBase64Wraps(t *testing.T) {
+	var buf bytes.Buffer
+	writeBase64(&buf, bytes.Repeat([]byte("a"), 100))
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("line of %d characters", len(line))
+		}
+	}
+}
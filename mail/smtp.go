@@ -0,0 +1,139 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// A Sender delivers messages, through SMTP or the HTTP API of a provider.
+type Sender interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+// Adapts a function, such as a client of a provider's API, to a Sender.
+type SenderFunc func(ctx context.Context, m *Message) error
+
+func (f SenderFunc) Send(ctx context.Context, m *Message) error {
+	return f(ctx, m)
+}
+
+// Sends through an SMTP server at Addr ("host:port").  The connection is
+// upgraded with STARTTLS when the server offers it, or is TLS from the
+// start with ImplicitTLS (usually port 465), and authenticated with
+// PLAIN if Username is set, which net/smtp only does over TLS or to
+// localhost.
+type SMTPSender struct {
+	Addr        string
+	Username    string
+	Password    string
+	ImplicitTLS bool
+
+	// The TLS configuration, one for the host of Addr if nil.
+	TLSConfig *tls.Config
+
+	// How long connecting and sending may take, 30 seconds if zero, within
+	// the deadline of the context.
+	Timeout time.Duration
+}
+
+func NewSMTPSender(addr, username, password string) *SMTPSender {
+	return &SMTPSender{Addr: addr, Username: username, Password: password}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, m *Message) error {
+	from, err := m.Sender()
+	if err != nil {
+		return err
+	}
+
+	rcpts, err := m.Recipients()
+	if err != nil {
+		return err
+	}
+
+	data, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig := s.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{ServerName: host}
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	if s.ImplicitTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if !s.ImplicitTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+
+	for _, rcpt := range rcpts {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
@@ -0,0 +1,133 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The commands and message an SMTP server received.
+type transcript struct {
+	commands []string
+	data     string
+}
+
+// Serve one SMTP session on a new listener, answering reject to the
+// commands starting with it, and return the address and the transcript,
+// complete once the session ended.
+func smtpServer(t *testing.T, reject string) (string, <-chan transcript) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { l.Close() })
+
+	done := make(chan transcript, 1)
+	go func() {
+		var tr transcript
+		defer func() { done <- tr }()
+
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 test ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			cmd := strings.TrimSpace(line)
+			tr.commands = append(tr.commands, cmd)
+			switch {
+			case reject != "" && strings.HasPrefix(cmd, reject):
+				reply("550 rejected")
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-test\r\n250 8BITMIME")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+
+					data.WriteString(line)
+				}
+
+				tr.data = data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+
+	return l.Addr().String(), done
+}
+
+func TestSMTPSender(t *testing.T) {
+	addr, done := smtpServer(t, "")
+	m := &Message{
+		From:    "Shop <shop@example.com>",
+		To:      []string{"Ann <ann@example.com>"},
+		Bcc:     []string{"audit@example.com"},
+		Subject: "Hi",
+		Text:    "hello",
+	}
+
+	if err := NewSMTPSender(addr, "", "").Send(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := <-done
+	want := []string{"MAIL FROM:<shop@example.com> BODY=8BITMIME", "RCPT TO:<ann@example.com>", "RCPT TO:<audit@example.com>", "DATA", "QUIT"}
+	if len(tr.commands) < len(want) || !reflect.DeepEqual(tr.commands[1:], want) {
+		t.Errorf("commands %q", tr.commands)
+	}
+
+	if !strings.Contains(tr.data, "Subject: Hi\r\n") || strings.Contains(tr.data, "audit@") {
+		t.Errorf("data %q", tr.data)
+	}
+}
+
+func TestSMTPSenderErrors(t *testing.T) {
+	addr, done := smtpServer(t, "RCPT")
+	m := &Message{From: "shop@example.com", To: []string{"ann@example.com"}, Text: "hello"}
+	if err := NewSMTPSender(addr, "", "").Send(context.Background(), m); err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("error %v for a rejected recipient", err)
+	}
+
+	<-done
+
+	if err := NewSMTPSender(addr, "", "").Send(context.Background(), &Message{From: "nope"}); err == nil {
+		t.Error("no error for a bad sender")
+	}
+
+	// A server that accepts connections and never answers.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	s := &SMTPSender{Addr: l.Addr().String(), Timeout: 50 * time.Millisecond}
+	start := time.Now()
+	if err := s.Send(context.Background(), m); err == nil || time.Since(start) > 5*time.Second {
+		t.Errorf("error %v after %v for a silent server", err, time.Since(start))
+	}
+}
@@ -0,0 +1,316 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Metric types, as in the Prometheus exposition format.
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// The buckets of histograms that set none, in seconds, suited to request
+// latencies.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// A Bucket of a histogram Sample: the observations up to UpperBound,
+// cumulative as in Prometheus.
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// The value of one metric for one set of label values.  Counters and
+// gauges have a Value, histograms a Count, Sum and Buckets.
+type Sample struct {
+	Labels  map[string]string
+	Value   float64
+	Count   uint64
+	Sum     float64
+	Buckets []Bucket
+}
+
+// A snapshot of a metric and all its samples.
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// An Exporter sends snapshots of the metrics to a backend other than
+// Prometheus, which scrapes Handler instead, such as StatsD or a log.
+type Exporter interface {
+	Export(families []Family) error
+}
+
+type series struct {
+	values  []string
+	value   float64
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// A metric with labels; each set of label values is a series.
+type metric struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+	mutex   sync.Mutex
+	series  map[string]*series
+}
+
+// Return the series of the label values, which must match the labels,
+// with m.mutex held.
+func (m *metric) get(values []string) *series {
+	if len(values) != len(m.labels) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", m.name, len(m.labels), len(values)))
+	}
+
+	key := strings.Join(values, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		if m.typ == TypeHistogram {
+			s.buckets = make([]uint64, len(m.buckets))
+		}
+
+		m.series[key] = s
+	}
+
+	return s
+}
+
+func (m *metric) add(delta float64, values []string) {
+	m.mutex.Lock()
+	m.get(values).value += delta
+	m.mutex.Unlock()
+}
+
+func (m *metric) snapshot() Family {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	f := Family{Name: m.name, Help: m.help, Type: m.typ, Samples: []Sample{}}
+	keys := make([]string, 0, len(m.series))
+	for k := range m.series {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := m.series[k]
+		sample := Sample{Labels: map[string]string{}, Value: s.value, Count: s.count, Sum: s.sum}
+		for i, l := range m.labels {
+			sample.Labels[l] = s.values[i]
+		}
+
+		if m.typ == TypeHistogram {
+			var cumulative uint64
+			for i, bound := range m.buckets {
+				cumulative += s.buckets[i]
+				sample.Buckets = append(sample.Buckets, Bucket{bound, cumulative})
+			}
+		}
+
+		f.Samples = append(f.Samples, sample)
+	}
+
+	return f
+}
+
+// A Counter only goes up, e.g. the number of requests.
+type Counter struct {
+	m *metric
+}
+
+func (c *Counter) Inc(labelValues ...string) {
+	c.m.add(1, labelValues)
+}
+
+// Add delta, which must not be negative.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic("metrics: counter " + c.m.name + " cannot decrease")
+	}
+
+	c.m.add(delta, labelValues)
+}
+
+// A Gauge goes up and down, e.g. the number of requests in flight.
+type Gauge struct {
+	m *metric
+}
+
+func (g *Gauge) Inc(labelValues ...string) {
+	g.m.add(1, labelValues)
+}
+
+func (g *Gauge) Dec(labelValues ...string) {
+	g.m.add(-1, labelValues)
+}
+
+func (g *Gauge) Add(delta float64, labelValues ...string) {
+	g.m.add(delta, labelValues)
+}
+
+func (g *Gauge) Set(value float64, labelValues ...string) {
+	g.m.mutex.Lock()
+	g.m.get(labelValues).value = value
+	g.m.mutex.Unlock()
+}
+
+// A Histogram counts observations, e.g. latencies, in buckets.
+type Histogram struct {
+	m *metric
+}
+
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	h.m.mutex.Lock()
+	s := h.m.get(labelValues)
+	s.count++
+	s.sum += value
+	for i, bound := range h.m.buckets {
+		if value <= bound {
+			s.buckets[i]++
+			break
+		}
+	}
+	h.m.mutex.Unlock()
+}
+
+// Observe the time elapsed since start, in seconds.
+func (h *Histogram) Since(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// A Registry holds metrics by name.  It is safe for concurrent use.
+type Registry struct {
+	mutex           sync.RWMutex
+	metrics         map[string]*metric
+	validatorHooked bool
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: map[string]*metric{},
+	}
+}
+
+// The Registry of the package-level functions, Handler and Middleware.
+var Default = NewRegistry()
+
+// Return the metric of name, registering it if new.  Registering a name
+// again with another type or labels panics.
+func (r *Registry) register(name, help, typ string, labels []string, buckets []float64) *metric {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if m, ok := r.metrics[name]; ok {
+		if m.typ != typ || strings.Join(m.labels, ",") != strings.Join(labels, ",") {
+			panic("metrics: " + name + " registered again with another type or labels")
+		}
+
+		return m
+	}
+
+	m := &metric{
+		name:    name,
+		help:    help,
+		typ:     typ,
+		labels:  labels,
+		buckets: buckets,
+		series:  map[string]*series{},
+	}
+	r.metrics[name] = m
+
+	return m
+}
+
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(name, help, TypeCounter, labels, nil)}
+}
+
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(name, help, TypeGauge, labels, nil)}
+}
+
+// Return a Histogram with buckets, sorted upper bounds, or DefaultBuckets
+// if nil.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+
+	return &Histogram{r.register(name, help, TypeHistogram, labels, buckets)}
+}
+
+// Return a snapshot of every metric, sorted by name.
+func (r *Registry) Gather() []Family {
+	r.mutex.RLock()
+	metrics := make([]*metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		metrics = append(metrics, m)
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return metrics[i].name < metrics[j].name
+	})
+
+	families := make([]Family, len(metrics))
+	for i, m := range metrics {
+		families[i] = m.snapshot()
+	}
+
+	return families
+}
+
+// Export a snapshot to e every interval until stop is called.  Failures
+// are passed to onError, if not nil.
+func (r *Registry) ExportEvery(e Exporter, interval time.Duration, onError func(error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := e.Export(r.Gather()); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.Counter(name, help, labels...)
+}
+
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.Gauge(name, help, labels...)
+}
+
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.Histogram(name, help, buckets, labels...)
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"golanger.com/framework/middleware"
+	"golanger.com/framework/router"
+	"golanger.com/framework/validator"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+type Options struct {
+	// Default if nil.
+	Registry *Registry
+
+	// Return the route label of a request once served.  By default it is
+	// the pattern of the router route that matched, or else "unmatched"
+	// for a 404 (so that scans do not add a series per path) and the path
+	// for other requests.
+	Route func(r *http.Request, status int) string
+
+	// The buckets of the latency histogram, DefaultBuckets if nil.
+	Buckets []float64
+}
+
+func defaultRoute(r *http.Request, status int) string {
+	if route := router.CurrentRoute(r); route != nil {
+		return route.Pattern
+	}
+
+	if status == http.StatusNotFound {
+		return "unmatched"
+	}
+
+	return r.URL.Path
+}
+
+// Records the status of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("metrics: ResponseWriter does not support Hijack")
+	}
+
+	return h.Hijack()
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Return a middleware recording http_requests_total by method, route and
+// status, http_request_duration_seconds by method and route, and
+// http_requests_in_flight.  It should wrap the router, to see every
+// request, or be used inside it.
+func Middleware(opts ...Options) middleware.Middleware {
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Registry == nil {
+		o.Registry = Default
+	}
+
+	if o.Route == nil {
+		o.Route = defaultRoute
+	}
+
+	requests := o.Registry.Counter("http_requests_total", "Requests served, by method, route and status.", "method", "route", "status")
+	latency := o.Registry.Histogram("http_request_duration_seconds", "Time taken to serve requests, by method and route.", o.Buckets, "method", "route")
+	inFlight := o.Registry.Gauge("http_requests_in_flight", "Requests being served.")
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			r = router.Track(r)
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			route := o.Route(r, status)
+			requests.Inc(r.Method, route, strconv.Itoa(status))
+			latency.Since(start, r.Method, route)
+		})
+	}
+}
+
+// The indexes and map keys of element keys such as "Items[2].SKU".
+var keyIndex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// Return key with its indexes emptied, "Items[].SKU" for "Items[2].SKU",
+// so that the elements of a list share one series and the number of
+// series stays bounded by the fields.
+func failureKey(key string) string {
+	return keyIndex.ReplaceAllString(key, "[]")
+}
+
+// Count the failed checks of every Validation in validation_failures_total
+// by key, with its indexes emptied as in "Items[].SKU", and error code.
+// Calling it again for the same Registry has no effect.
+func (r *Registry) InstrumentValidator() {
+	r.mutex.Lock()
+	if r.validatorHooked {
+		r.mutex.Unlock()
+		return
+	}
+
+	r.validatorHooked = true
+	r.mutex.Unlock()
+
+	failures := r.Counter("validation_failures_total", "Failed validation checks, by key and error code.", "key", "code")
+	validator.OnFailure(func(key, code string) {
+		failures.Inc(failureKey(key), code)
+	})
+}
+
+func InstrumentValidator() {
+	Default.InstrumentValidator()
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"golanger.com/framework/validator"
+	"testing"
+)
+
+func TestFailureKey(t *testing.T) {
+	cases := map[string]string{
+		"Email":             "Email",
+		"Items[2].SKU":      "Items[].SKU",
+		"prices[apple]":     "prices[]",
+		"Rows[1].Cells[10]": "Rows[].Cells[]",
+		"pkg.Handler#42":    "pkg.Handler#42",
+	}
+
+	for key, want := range cases {
+		if got := failureKey(key); got != want {
+			t.Errorf("failureKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+// Return the value of validation_failures_total for key and code.
+func failures(r *Registry, key, code string) float64 {
+	for _, f := range r.Gather() {
+		if f.Name != "validation_failures_total" {
+			continue
+		}
+
+		for _, s := range f.Samples {
+			if s.Labels["key"] == key && s.Labels["code"] == code {
+				return s.Value
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestInstrumentValidator(t *testing.T) {
+	r := NewRegistry()
+	r.InstrumentValidator()
+	r.InstrumentValidator()
+
+	v := &validator.Validation{}
+	v.Required("").Key("Name")
+	v.Error("Taken").Code("validation.taken").Key("Login")
+	v.CheckMerged("Bio", "", validator.Required{})
+	v.Each([]string{"", "x", ""}, validator.Required{}).Key("Tags")
+	v.ValidateMapValues(map[string]int{"a": -1, "b": -2}, validator.Min{Min: 0}).Key("Prices")
+
+	cases := []struct {
+		key, code string
+		want      float64
+	}{
+		{"Name", "validation.required", 1},
+		{"Login", "validation.taken", 1},
+		{"Bio", "validation.required", 1},
+		{"Tags[]", "validation.required", 2},
+		{"Prices[]", "validation.min", 2},
+	}
+
+	for _, c := range cases {
+		if got := failures(r, c.key, c.code); got != c.want {
+			t.Errorf("failures %s %s = %v, want %v", c.key, c.code, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Format labels as {a="1",b="2"}, sorted, with extra appended last, or ""
+// if there are none.
+func formatLabels(labels map[string]string, extra ...string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	parts := []string{}
+	for _, k := range keys {
+		parts = append(parts, k+`="`+labelEscaper.Replace(labels[k])+`"`)
+	}
+
+	for i := 0; i+1 < len(extra); i += 2 {
+		parts = append(parts, extra[i]+`="`+extra[i+1]+`"`)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Write the families in the Prometheus text exposition format.
+func WriteText(buf *bytes.Buffer, families []Family) {
+	for _, f := range families {
+		if f.Help != "" {
+			buf.WriteString("# HELP " + f.Name + " " + strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(f.Help) + "\n")
+		}
+
+		buf.WriteString("# TYPE " + f.Name + " " + f.Type + "\n")
+		for _, s := range f.Samples {
+			if f.Type != TypeHistogram {
+				buf.WriteString(f.Name + formatLabels(s.Labels) + " " + formatFloat(s.Value) + "\n")
+				continue
+			}
+
+			for _, b := range s.Buckets {
+				buf.WriteString(f.Name + "_bucket" + formatLabels(s.Labels, "le", formatFloat(b.UpperBound)) + " " + strconv.FormatUint(b.Count, 10) + "\n")
+			}
+
+			buf.WriteString(f.Name + "_bucket" + formatLabels(s.Labels, "le", "+Inf") + " " + strconv.FormatUint(s.Count, 10) + "\n")
+			buf.WriteString(f.Name + "_sum" + formatLabels(s.Labels) + " " + formatFloat(s.Sum) + "\n")
+			buf.WriteString(f.Name + "_count" + formatLabels(s.Labels) + " " + strconv.FormatUint(s.Count, 10) + "\n")
+		}
+	}
+}
+
+// Return a handler serving the metrics of r for Prometheus to scrape.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		buf := &bytes.Buffer{}
+		WriteText(buf, r.Gather())
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		buf.WriteTo(w)
+	})
+}
+
+// The Handler of Default, to mount at /metrics.
+func Handler() http.Handler {
+	return Default.Handler()
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// A Middleware wraps a handler to run code before and after it, or instead
+// of it.
+type Middleware func(next http.Handler) http.Handler
+
+// A Chain is an ordered list of middleware.  The first one added is the
+// outermost: it sees the request first and the response last.
+type Chain struct {
+	middlewares []Middleware
+}
+
+func New(middlewares ...Middleware) *Chain {
+	return (&Chain{}).Use(middlewares...)
+}
+
+func (c *Chain) Use(middlewares ...Middleware) *Chain {
+	c.middlewares = append(c.middlewares, middlewares...)
+
+	return c
+}
+
+// Return h wrapped by every middleware of the chain.
+func (c *Chain) Then(h http.Handler) http.Handler {
+	return Wrap(h, c.middlewares...)
+}
+
+func (c *Chain) ThenFunc(f func(http.ResponseWriter, *http.Request)) http.Handler {
+	return c.Then(http.HandlerFunc(f))
+}
+
+// Return h wrapped by middlewares, the first being the outermost.
+func Wrap(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+
+	return h
+}
+
+// Return a middleware that calls f before the handler.  The handler is
+// skipped when f returns false, f having written the response itself.
+func Before(f func(w http.ResponseWriter, r *http.Request) bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if f(w, r) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// Return a middleware that calls f after the handler.
+func After(f func(w http.ResponseWriter, r *http.Request)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+			f(w, r)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Return a middleware appending name to the X-Trace header before and
+// after the handler.
+func trace(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", name)
+			next.ServeHTTP(w, r)
+			w.Header().Add("X-Trace", "/"+name)
+		})
+	}
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("X-Trace", "handler")
+}
+
+func run(h http.Handler) string {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	return strings.Join(w.Header()["X-Trace"], " ")
+}
+
+func TestChainOrder(t *testing.T) {
+	c := New(trace("a")).Use(trace("b"), trace("c"))
+	if got := run(c.ThenFunc(handler)); got != "a b c handler /c /b /a" {
+		t.Errorf("trace %q", got)
+	}
+
+	if got := run(New().ThenFunc(handler)); got != "handler" {
+		t.Errorf("empty chain trace %q", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	if got := run(Wrap(http.HandlerFunc(handler), trace("outer"), trace("inner"))); got != "outer inner handler /inner /outer" {
+		t.Errorf("trace %q", got)
+	}
+}
+
+func TestBeforeAndAfter(t *testing.T) {
+	allow := true
+	h := Wrap(http.HandlerFunc(handler),
+		Before(func(w http.ResponseWriter, r *http.Request) bool {
+			w.Header().Add("X-Trace", "before")
+			return allow
+		}),
+		After(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Trace", "after")
+		}),
+	)
+
+	if got := run(h); got != "before handler after" {
+		t.Errorf("trace %q", got)
+	}
+
+	allow = false
+	if got := run(h); got != "before" {
+		t.Errorf("trace %q, want the handler skipped", got)
+	}
+}
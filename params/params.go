@@ -0,0 +1,371 @@
+package params
+
+import (
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/router"
+	"golanger.com/framework/validator"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// The check recorded when a parameter does not parse as its Type,
+// "integer", "number" or "boolean".
+type Syntax struct {
+	Type string
+}
+
+func (s Syntax) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	var err error
+	switch s.Type {
+	case "integer":
+		_, err = strconv.ParseInt(str, 10, 64)
+	case "number":
+		_, err = strconv.ParseFloat(str, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(str)
+	}
+
+	return err == nil
+}
+
+func (s Syntax) DefaultMessage() string {
+	switch s.Type {
+	case "integer":
+		return "Must be a whole number"
+	case "boolean":
+		return "Must be true or false"
+	}
+
+	return "Must be a number"
+}
+
+func (s Syntax) ErrorCode() string {
+	return "validation.type"
+}
+
+// Return the value of a parameter of the route (see router.Param), or
+// else of the query string, and whether it is set and not empty.
+func Lookup(r *http.Request, name string) (string, bool) {
+	if value, ok := router.Params(r)[name]; ok {
+		return value, value != ""
+	}
+
+	value := r.URL.Query().Get(name)
+
+	return value, value != ""
+}
+
+// The state shared by the typed parameters.  A parameter that is missing
+// passes every check but Required, and its value is the default.
+type param struct {
+	validation *validator.Validation
+	name       string
+	raw        string
+	present    bool
+	failed     bool
+}
+
+// Look name up in r, recording failures into the Validation of r (see
+// validator.FromRequest), or else into one of their own.
+func newParam(r *http.Request, name string) param {
+	v := validator.FromRequest(r)
+	if v == nil {
+		v = (&validator.Validation{}).SetLocale(i18n.Locale(r)).SetContext(r.Context())
+	}
+
+	raw, present := Lookup(r, name)
+
+	return param{validation: v, name: name, raw: raw, present: present}
+}
+
+// Apply chk to value unless the parameter is missing or failed already.
+func (p *param) check(value interface{}, checks ...validator.Validator) {
+	if p.failed || !p.present {
+		return
+	}
+
+	if !p.validation.CheckKey(p.name, value, checks...).Ok {
+		p.failed = true
+	}
+}
+
+func (p *param) parse(syntax string) {
+	p.check(p.raw, Syntax{syntax})
+}
+
+func (p *param) required() {
+	if !p.failed && !p.present {
+		p.validation.RequiredKey(p.name, "")
+		p.failed = true
+	}
+}
+
+// Report whether the parameter is set and not empty.
+func (p *param) Present() bool {
+	return p.present
+}
+
+// Report whether the parameter passed its checks, or is missing and not
+// Required.
+func (p *param) Ok() bool {
+	return !p.failed
+}
+
+func (p *param) usable() bool {
+	return p.present && !p.failed
+}
+
+// An integer parameter, e.g. params.Int(r, "page").Min(1).Default(1).Value().
+type IntParam struct {
+	param
+	value, def int
+}
+
+func Int(r *http.Request, name string) *IntParam {
+	p := &IntParam{param: newParam(r, name)}
+	n, err := strconv.Atoi(p.raw)
+	if err != nil {
+		p.parse("integer")
+	}
+
+	p.value = n
+
+	return p
+}
+
+func (p *IntParam) Required() *IntParam {
+	p.required()
+	return p
+}
+
+func (p *IntParam) Min(min int) *IntParam {
+	p.check(p.value, validator.Min{Min: min})
+	return p
+}
+
+func (p *IntParam) Max(max int) *IntParam {
+	p.check(p.value, validator.Max{Max: max})
+	return p
+}
+
+func (p *IntParam) Range(min, max int) *IntParam {
+	p.check(p.value, validator.Range{Min: validator.Min{Min: min}, Max: validator.Max{Max: max}})
+	return p
+}
+
+func (p *IntParam) Check(checks ...validator.Validator) *IntParam {
+	p.check(p.value, checks...)
+	return p
+}
+
+// Set the value of a parameter that is missing or failed a check.
+func (p *IntParam) Default(n int) *IntParam {
+	p.def = n
+	return p
+}
+
+func (p *IntParam) Value() int {
+	if !p.usable() {
+		return p.def
+	}
+
+	return p.value
+}
+
+// A 64-bit integer parameter, such as an ID.
+type Int64Param struct {
+	param
+	value, def int64
+}
+
+func Int64(r *http.Request, name string) *Int64Param {
+	p := &Int64Param{param: newParam(r, name)}
+	n, err := strconv.ParseInt(p.raw, 10, 64)
+	if err != nil {
+		p.parse("integer")
+	}
+
+	p.value = n
+
+	return p
+}
+
+func (p *Int64Param) Required() *Int64Param {
+	p.required()
+	return p
+}
+
+func (p *Int64Param) Min(min int64) *Int64Param {
+	p.check(p.value, validator.MinInt64{Min: min})
+	return p
+}
+
+func (p *Int64Param) Max(max int64) *Int64Param {
+	p.check(p.value, validator.MaxInt64{Max: max})
+	return p
+}
+
+func (p *Int64Param) Check(checks ...validator.Validator) *Int64Param {
+	p.check(p.value, checks...)
+	return p
+}
+
+func (p *Int64Param) Default(n int64) *Int64Param {
+	p.def = n
+	return p
+}
+
+func (p *Int64Param) Value() int64 {
+	if !p.usable() {
+		return p.def
+	}
+
+	return p.value
+}
+
+// A floating point parameter.
+type FloatParam struct {
+	param
+	value, def float64
+}
+
+func Float(r *http.Request, name string) *FloatParam {
+	p := &FloatParam{param: newParam(r, name)}
+	f, err := strconv.ParseFloat(p.raw, 64)
+	if err != nil {
+		p.parse("number")
+	}
+
+	p.value = f
+
+	return p
+}
+
+func (p *FloatParam) Required() *FloatParam {
+	p.required()
+	return p
+}
+
+func (p *FloatParam) Min(min float64) *FloatParam {
+	p.check(p.value, validator.MinFloat{Min: min})
+	return p
+}
+
+func (p *FloatParam) Max(max float64) *FloatParam {
+	p.check(p.value, validator.MaxFloat{Max: max})
+	return p
+}
+
+func (p *FloatParam) Check(checks ...validator.Validator) *FloatParam {
+	p.check(p.value, checks...)
+	return p
+}
+
+func (p *FloatParam) Default(f float64) *FloatParam {
+	p.def = f
+	return p
+}
+
+func (p *FloatParam) Value() float64 {
+	if !p.usable() {
+		return p.def
+	}
+
+	return p.value
+}
+
+// A boolean parameter, in any form strconv.ParseBool accepts.
+type BoolParam struct {
+	param
+	value, def bool
+}
+
+func Bool(r *http.Request, name string) *BoolParam {
+	p := &BoolParam{param: newParam(r, name)}
+	b, err := strconv.ParseBool(p.raw)
+	if err != nil {
+		p.parse("boolean")
+	}
+
+	p.value = b
+
+	return p
+}
+
+func (p *BoolParam) Required() *BoolParam {
+	p.required()
+	return p
+}
+
+func (p *BoolParam) Default(b bool) *BoolParam {
+	p.def = b
+	return p
+}
+
+func (p *BoolParam) Value() bool {
+	if !p.usable() {
+		return p.def
+	}
+
+	return p.value
+}
+
+// A string parameter.
+type StringParam struct {
+	param
+	def string
+}
+
+func String(r *http.Request, name string) *StringParam {
+	return &StringParam{param: newParam(r, name)}
+}
+
+func (p *StringParam) Required() *StringParam {
+	p.required()
+	return p
+}
+
+func (p *StringParam) MinSize(min int) *StringParam {
+	p.check(p.raw, validator.MinSize{Min: min})
+	return p
+}
+
+func (p *StringParam) MaxSize(max int) *StringParam {
+	p.check(p.raw, validator.MaxSize{Max: max})
+	return p
+}
+
+func (p *StringParam) Match(regex *regexp.Regexp) *StringParam {
+	p.check(p.raw, validator.Match{Regexp: regex})
+	return p
+}
+
+// Require one of values, e.g. String(r, "sort").In("name", "date").
+func (p *StringParam) In(values ...string) *StringParam {
+	p.check(p.raw, validator.In{Values: values})
+	return p
+}
+
+func (p *StringParam) Check(checks ...validator.Validator) *StringParam {
+	p.check(p.raw, checks...)
+	return p
+}
+
+func (p *StringParam) Default(s string) *StringParam {
+	p.def = s
+	return p
+}
+
+func (p *StringParam) Value() string {
+	if !p.usable() {
+		return p.def
+	}
+
+	return p.raw
+}
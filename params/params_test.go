@@ -0,0 +1,148 @@
+package params
+
+import (
+	"golanger.com/framework/router"
+	"golanger.com/framework/validator"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+// Return a request for target carrying v.
+func request(target string, v *validator.Validation) *http.Request {
+	return validator.WithValidation(httptest.NewRequest("GET", target, nil), v)
+}
+
+func TestInt(t *testing.T) {
+	v := &validator.Validation{}
+	r := request("/?page=3&per=500&bad=x&neg=-2", v)
+
+	if p := Int(r, "page").Min(1).Default(1); p.Value() != 3 || !p.Ok() || !p.Present() {
+		t.Errorf("page = %d", p.Value())
+	}
+
+	if p := Int(r, "per").Range(1, 100).Default(20); p.Value() != 20 || p.Ok() {
+		t.Errorf("per = %d, ok %v", p.Value(), p.Ok())
+	}
+
+	if p := Int(r, "bad").Min(1000).Default(5); p.Value() != 5 || p.Ok() {
+		t.Errorf("bad = %d", p.Value())
+	}
+
+	if p := Int(r, "neg").Max(-5); p.Value() != 0 {
+		t.Errorf("neg = %d", p.Value())
+	}
+
+	if p := Int(r, "missing").Min(1).Default(7); p.Value() != 7 || !p.Ok() || p.Present() {
+		t.Errorf("missing = %d, ok %v", p.Value(), p.Ok())
+	}
+
+	Int(r, "required").Required()
+
+	errs := v.ErrorMap()
+	if errs["page"] != nil || errs["per"] == nil || errs["neg"] == nil || errs["required"] == nil {
+		t.Errorf("errors %v", errs)
+	}
+
+	// The syntax error is the only one of bad.
+	if e := errs["bad"]; e == nil || e.Code != "validation.type" || e.Message != "Must be a whole number" {
+		t.Errorf("bad: %v", e)
+	}
+}
+
+func TestOtherTypes(t *testing.T) {
+	v := &validator.Validation{}
+	r := request("/?id=9007199254740993&price=9.5&on=true&off=nope&q=golang&sort=size&code=ab1", v)
+
+	if n := Int64(r, "id").Min(1).Required().Value(); n != 9007199254740993 {
+		t.Errorf("id = %d", n)
+	}
+
+	if f := Float(r, "price").Min(0).Max(10).Value(); f != 9.5 {
+		t.Errorf("price = %v", f)
+	}
+
+	if !Bool(r, "on").Value() || Bool(r, "off").Default(true).Value() != true {
+		t.Error("wrong booleans")
+	}
+
+	if s := String(r, "q").MinSize(2).MaxSize(10).Value(); s != "golang" {
+		t.Errorf("q = %s", s)
+	}
+
+	if s := String(r, "sort").In("name", "date").Default("name").Value(); s != "name" {
+		t.Errorf("sort = %s", s)
+	}
+
+	if s := String(r, "code").Match(regexp.MustCompile(`^[a-z]+$`)).Value(); s != "" {
+		t.Errorf("code = %s", s)
+	}
+
+	Float(r, "id").Max(1)
+	Int64(r, "price")
+
+	errs := v.ErrorMap()
+	for _, name := range []string{"off", "sort", "code", "id", "price"} {
+		if errs[name] == nil {
+			t.Errorf("no error for %s in %v", name, errs)
+		}
+	}
+
+	for _, name := range []string{"on", "q"} {
+		if errs[name] != nil {
+			t.Errorf("error for %s: %v", name, errs[name])
+		}
+	}
+}
+
+func TestRouteParamsFirst(t *testing.T) {
+	var id int64
+	var v *validator.Validation
+	rt := router.New()
+	rt.Handle("GET", "/items/:id", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id = Int64(r, "id").Value()
+		Int(r, "page").Min(1)
+		v = validator.FromRequest(r)
+	}))
+
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/42?id=9&page=0", nil))
+	if id != 42 {
+		t.Errorf("id = %d", id)
+	}
+
+	// Without a Validation on the request, failures are only in the
+	// parameter.
+	if v != nil {
+		t.Errorf("Validation %v attached", v)
+	}
+
+	r := httptest.NewRequest("GET", "/?page=0", nil)
+	if p := Int(r, "page").Min(1); p.Ok() {
+		t.Error("Ok after a failed check")
+	}
+}
+
+func TestSyntax(t *testing.T) {
+	cases := []struct {
+		typ, value string
+		want       bool
+	}{
+		{"integer", "-12", true},
+		{"integer", "1.5", false},
+		{"number", "1e3", true},
+		{"number", "one", false},
+		{"boolean", "F", true},
+		{"boolean", "yes", false},
+	}
+
+	for _, c := range cases {
+		if got := (Syntax{c.typ}).IsSatisfied(c.value); got != c.want {
+			t.Errorf("Syntax{%s}(%q) = %v", c.typ, c.value, got)
+		}
+	}
+
+	if (Syntax{"integer"}).IsSatisfied(1) {
+		t.Error("a non-string is valid")
+	}
+}
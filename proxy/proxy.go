@@ -0,0 +1,260 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"golanger.com/framework/log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type Options struct {
+	// The path prefix the proxy is mounted under, removed from the path of
+	// requests and put back into the Location of upstream redirects.
+	Prefix string
+
+	// Keep the Host header of the request instead of sending the host of
+	// the target, for upstreams serving several sites.
+	PreserveHost bool
+
+	// Headers set on the requests sent upstream and on the responses sent
+	// back; an empty value removes the header.
+	RequestHeaders  map[string]string
+	ResponseHeaders map[string]string
+
+	// How long to wait for the response headers of an attempt, 30 seconds
+	// by default.  Bodies stream for as long as they take.
+	Timeout time.Duration
+
+	// How many times to try again a request that failed to connect or was
+	// answered 502, 503 or 504, waiting RetryWait (100ms by default) more
+	// before each retry.  Only requests without a body, or whose body can
+	// be read again, of idempotent methods are retried.
+	Retries   int
+	RetryWait time.Duration
+
+	// How often the response is flushed to the client while it is copied,
+	// 100ms by default, or -1 to flush after every write.  Event streams
+	// are always flushed at once.
+	FlushInterval time.Duration
+
+	// Called on each request before it is sent upstream, e.g. to add
+	// credentials, and on each response before it is sent back.
+	Rewrite        func(r *http.Request)
+	ModifyResponse func(resp *http.Response) error
+
+	// The transport of the upstream requests, a copy of
+	// http.DefaultTransport with Timeout if nil.
+	Transport http.RoundTripper
+}
+
+// A Proxy forwards requests to an upstream server and streams its
+// responses back.  The X-Forwarded-For, -Host and -Proto headers tell the
+// upstream about the client; those the client sent are dropped.
+type Proxy struct {
+	target *url.URL
+	opts   Options
+	rp     *httputil.ReverseProxy
+}
+
+// Return a Proxy to target, an http or https URL whose path, if any, is
+// prepended to that of the requests.  On a router, mount it on a wildcard
+// route of every method:
+//
+//	legacy, err := proxy.New("http://10.0.0.5:8080", proxy.Options{Prefix: "/api/legacy"})
+//	rt.Handle("", "/api/legacy/*path", legacy)
+func New(target string, opts ...Options) (*Proxy, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("proxy: target must be an http or https URL: " + target)
+	}
+
+	p := &Proxy{target: u}
+	if len(opts) > 0 {
+		p.opts = opts[0]
+	}
+
+	if p.opts.Prefix != "" {
+		p.opts.Prefix = "/" + strings.Trim(p.opts.Prefix, "/")
+	}
+
+	if p.opts.Timeout == 0 {
+		p.opts.Timeout = 30 * time.Second
+	}
+
+	if p.opts.RetryWait == 0 {
+		p.opts.RetryWait = 100 * time.Millisecond
+	}
+
+	if p.opts.FlushInterval == 0 {
+		p.opts.FlushInterval = 100 * time.Millisecond
+	}
+
+	transport := p.opts.Transport
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.ResponseHeaderTimeout = p.opts.Timeout
+		transport = t
+	}
+
+	if p.opts.Retries > 0 {
+		transport = &retryTransport{base: transport, retries: p.opts.Retries, wait: p.opts.RetryWait}
+	}
+
+	p.rp = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		Transport:      transport,
+		FlushInterval:  p.opts.FlushInterval,
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.fail,
+	}
+
+	return p, nil
+}
+
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	if p.opts.Prefix != "" {
+		pr.Out.URL.Path = trimPrefix(pr.Out.URL.Path, p.opts.Prefix)
+		pr.Out.URL.RawPath = trimPrefix(pr.Out.URL.RawPath, p.opts.Prefix)
+	}
+
+	pr.SetURL(p.target)
+	pr.SetXForwarded()
+	if p.opts.PreserveHost {
+		pr.Out.Host = pr.In.Host
+	}
+
+	setHeaders(pr.Out.Header, p.opts.RequestHeaders)
+	if p.opts.Rewrite != nil {
+		p.opts.Rewrite(pr.Out)
+	}
+}
+
+// Remove prefix from path if it is a whole number of its segments, so
+// that "/api" is not cut from "/apiary".
+func trimPrefix(path, prefix string) string {
+	if path == prefix || strings.HasPrefix(path, prefix+"/") {
+		return path[len(prefix):]
+	}
+
+	return path
+}
+
+func setHeaders(h http.Header, headers map[string]string) {
+	for k, v := range headers {
+		if v == "" {
+			h.Del(k)
+		} else {
+			h.Set(k, v)
+		}
+	}
+}
+
+// Point the redirects of the upstream to itself back at the proxy.
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+	if location := resp.Header.Get("Location"); location != "" {
+		if u, err := url.Parse(location); err == nil && (u.Host == "" || u.Host == p.target.Host) {
+			path := strings.TrimPrefix(u.Path, strings.TrimSuffix(p.target.Path, "/"))
+			if u.Host != "" || strings.HasPrefix(u.Path, "/") {
+				u.Scheme, u.Host = "", ""
+				u.Path = p.opts.Prefix + path
+				if u.Path == "" {
+					u.Path = "/"
+				}
+
+				u.RawPath = ""
+				resp.Header.Set("Location", u.String())
+			}
+		}
+	}
+
+	setHeaders(resp.Header, p.opts.ResponseHeaders)
+	if p.opts.ModifyResponse != nil {
+		return p.opts.ModifyResponse(resp)
+	}
+
+	return nil
+}
+
+// Answer 504 if the upstream timed out and 502 otherwise.
+func (p *Proxy) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		// The client has gone.
+		return
+	}
+
+	log.FromRequest(r).Error("<proxy.Proxy> ", p.target.Host, " ", err)
+	status := http.StatusBadGateway
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		status = http.StatusGatewayTimeout
+	}
+
+	http.Error(w, http.StatusText(status), status)
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.rp.ServeHTTP(w, r)
+}
+
+// Retries the requests it may send again.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	wait    time.Duration
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+
+	return false
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	replayable := idempotent(req.Method) && (req.Body == nil || req.Body == http.NoBody || req.GetBody != nil)
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		retry := err != nil
+		if err == nil {
+			switch resp.StatusCode {
+			case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				retry = true
+			}
+		}
+
+		if !retry || !replayable || attempt >= t.retries || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		select {
+		case <-time.After(t.wait * time.Duration(attempt+1)):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
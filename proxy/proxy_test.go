@@ -0,0 +1,164 @@
+package proxy
+
+import (
+	"bytes"
+	"golanger.com/framework/log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+// Serve a request for target through p and return the response.
+func through(p *Proxy, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestForward(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Seen", r.Method+" "+r.URL.RequestURI()+" host="+r.Host+
+			" xff="+r.Header.Get("X-Forwarded-For")+" xfh="+r.Header.Get("X-Forwarded-Host")+
+			" key="+r.Header.Get("X-Api-Key")+" cookie="+r.Header.Get("Cookie"))
+		w.Header().Set("Server", "upstream")
+		w.Write([]byte("hello"))
+	}))
+	defer upstream.Close()
+
+	p, err := New(upstream.URL+"/v2", Options{
+		Prefix:          "/api/legacy/",
+		RequestHeaders:  map[string]string{"X-Api-Key": "secret", "Cookie": ""},
+		ResponseHeaders: map[string]string{"Server": ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := through(p, "GET", "http://example.com/api/legacy/users?id=1", map[string]string{
+		"X-Forwarded-For": "6.6.6.6",
+		"Cookie":          "session=x",
+	})
+
+	want := "GET /v2/users?id=1 host=" + upstream.Listener.Addr().String() + " xff=192.0.2.1 xfh=example.com key=secret cookie="
+	if got := w.Header().Get("X-Seen"); got != want {
+		t.Errorf("upstream saw\n%q\nwant\n%q", got, want)
+	}
+
+	if w.Body.String() != "hello" || w.Header().Get("Server") != "" {
+		t.Errorf("response %q, Server %q", w.Body.String(), w.Header().Get("Server"))
+	}
+
+	p, _ = New(upstream.URL, Options{PreserveHost: true, Prefix: "/api"})
+	w = through(p, "GET", "http://example.com/api", nil)
+	if got := w.Header().Get("X-Seen"); !strings.HasPrefix(got, "GET / host=example.com ") {
+		t.Errorf("upstream saw %q", got)
+	}
+
+	w = through(p, "GET", "http://example.com/apiary", nil)
+	if got := w.Header().Get("X-Seen"); !strings.HasPrefix(got, "GET /apiary ") {
+		t.Errorf("prefix cut from another path: upstream saw %q", got)
+	}
+}
+
+func TestRedirectsRewritten(t *testing.T) {
+	var upstream *httptest.Server
+	upstream = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/absolute":
+			w.Header().Set("Location", upstream.URL+"/v2/login?next=%2F")
+		case "/v2/relative":
+			w.Header().Set("Location", "/v2/")
+		case "/v2/elsewhere":
+			w.Header().Set("Location", "https://auth.example.com/login")
+		}
+
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer upstream.Close()
+
+	p, _ := New(upstream.URL+"/v2/", Options{Prefix: "/legacy"})
+	cases := map[string]string{
+		"/legacy/absolute":  "/legacy/login?next=%2F",
+		"/legacy/relative":  "/legacy/",
+		"/legacy/elsewhere": "https://auth.example.com/login",
+	}
+
+	for target, want := range cases {
+		if got := through(p, "GET", target, nil).Header().Get("Location"); got != want {
+			t.Errorf("%s redirected to %q, want %q", target, got, want)
+		}
+	}
+}
+
+func TestRetries(t *testing.T) {
+	var calls int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte("third time"))
+	}))
+	defer upstream.Close()
+
+	p, _ := New(upstream.URL, Options{Retries: 2, RetryWait: time.Millisecond})
+	if w := through(p, "GET", "/", nil); w.Code != 200 || w.Body.String() != "third time" || calls != 3 {
+		t.Errorf("GET %d %q after %d calls", w.Code, w.Body.String(), calls)
+	}
+
+	calls = 0
+	r := httptest.NewRequest("POST", "/", bytes.NewReader([]byte("order")))
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("POST %d after %d calls, want no retry", w.Code, calls)
+	}
+
+	calls = 0
+	p, _ = New(upstream.URL, Options{Retries: 1, RetryWait: time.Millisecond})
+	if w := through(p, "GET", "/", nil); w.Code != http.StatusServiceUnavailable || calls != 2 {
+		t.Errorf("GET %d after %d calls, want the last answer", w.Code, calls)
+	}
+}
+
+func TestUpstreamErrors(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	p, _ := New(down.URL)
+	if w := through(p, "GET", "/", nil); w.Code != http.StatusBadGateway {
+		t.Errorf("down upstream answered %d", w.Code)
+	}
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	p, _ = New(slow.URL, Options{Timeout: 20 * time.Millisecond})
+	if w := through(p, "GET", "/", nil); w.Code != http.StatusGatewayTimeout {
+		t.Errorf("slow upstream answered %d", w.Code)
+	}
+}
+
+func TestNewInvalidTarget(t *testing.T) {
+	for _, target := range []string{"ftp://example.com", "example.com", "http://", "://x"} {
+		if _, err := New(target); err == nil {
+			t.Errorf("no error for target %q", target)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
+	"golanger.com/framework/request"
+	"golanger.com/framework/session"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Limit of Requests per Per, with bursts of up to Burst requests
+// (Requests if zero): a token bucket of Burst tokens refilled at
+// Requests/Per.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+
+	return l.Requests
+}
+
+// Return the time a token takes to refill.
+func (l Limit) interval() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
+
+// The outcome of taking a token: whether there was one, the tokens left,
+// the time until the bucket is full again and, if denied, until the next
+// token.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// A Store keeps the buckets, in memory for one process or in a shared
+// store such as Redis for several.
+type Store interface {
+	Take(key string, limit Limit) (Result, error)
+}
+
+// A KeyFunc returns the bucket of a request, or "" not to limit it.
+type KeyFunc func(r *http.Request) string
+
+// Key requests by client IP, the request.RealIP behind the trusted
+// proxies.  trustProxy trusts the peer as a proxy when none is
+// configured, see request.ClientIP.
+func ByIP(trustProxy bool) KeyFunc {
+	return func(r *http.Request) string {
+		return "ip:" + request.ClientIP(r, trustProxy)
+	}
+}
+
+// Key requests by session, or by IP for those without one yet (which
+// could otherwise get a fresh bucket by dropping the cookie).  The
+// session middleware must run first.
+func BySession(trustProxy bool) KeyFunc {
+	byIP := ByIP(trustProxy)
+	return func(r *http.Request) string {
+		if s := session.FromRequest(r); s != nil && !s.IsNew() {
+			return "session:" + s.ID()
+		}
+
+		return byIP(r)
+	}
+}
+
+type Options struct {
+	// NewMemoryStore() if nil.
+	Store Store
+	// ByIP(false) if nil.
+	Key KeyFunc
+	// Prepended to the keys, to give each limit its own buckets in a
+	// shared store.
+	Prefix string
+	// The methods limited, e.g. "POST" to limit the submissions of a form
+	// but not its display; all if empty.
+	Methods []string
+	// Serves the denied requests, after the headers are set; a plain 429
+	// Too Many Requests if nil.
+	OnLimit http.Handler
+}
+
+// Return a middleware limiting requests to limit per key.  Responses carry
+// the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers,
+// and denied ones Retry-After.  A failing store lets requests through, as
+// locking everyone out is worse than not limiting for a while.
+func New(limit Limit, opts ...Options) middleware.Middleware {
+	if limit.Requests <= 0 || limit.Per <= 0 {
+		panic("ratelimit: Limit needs Requests and Per")
+	}
+
+	var o Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	if o.Store == nil {
+		o.Store = NewMemoryStore()
+	}
+
+	if o.Key == nil {
+		o.Key = ByIP(false)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := o.Key(r)
+			if key == "" || !limited(o.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := o.Store.Take(o.Prefix+key, limit)
+			if err != nil {
+				log.FromRequest(r).Error("<ratelimit.New> ", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.burst()))
+			h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			h.Set("RateLimit-Reset", seconds(result.Reset))
+			if result.Allowed {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Set("Retry-After", seconds(result.RetryAfter))
+			if o.OnLimit != nil {
+				o.OnLimit.ServeHTTP(w, r)
+				return
+			}
+
+			http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		})
+	}
+}
+
+func limited(methods []string, method string) bool {
+	if len(methods) == 0 {
+		return true
+	}
+
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Format d in whole seconds, rounded up so that clients do not retry too
+// early.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newRequest(remoteAddr string, forwardedFor ...string) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = remoteAddr
+	for _, f := range forwardedFor {
+		r.Header.Add("X-Forwarded-For", f)
+	}
+
+	return r
+}
+
+func TestByIPIgnoresForwardedForByDefault(t *testing.T) {
+	key := ByIP(false)
+	if got := key(newRequest("203.0.113.9:4321", "198.51.100.1")); got != "ip:203.0.113.9" {
+		t.Errorf("key %q, want the peer", got)
+	}
+}
+
+// A client behind the proxy may put anything left of the address the proxy
+// appends; its bucket must not change with it.
+func TestByIPForgedForwardedFor(t *testing.T) {
+	key := ByIP(true)
+	want := "ip:198.51.100.7"
+	for _, forged := range []string{"", "1.1.1.1, ", "2.2.2.2, 3.3.3.3, "} {
+		if got := key(newRequest("10.0.0.2:80", forged+"198.51.100.7")); got != want {
+			t.Errorf("forged %q: key %q, want %q", forged, got, want)
+		}
+	}
+
+	if got := key(newRequest("10.0.0.2:80", "1.1.1.1", "198.51.100.7")); got != want {
+		t.Errorf("two headers: key %q, want %q", got, want)
+	}
+}
+
+func TestNewLimits(t *testing.T) {
+	h := New(Limit{Requests: 2, Per: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	codes := []int{}
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest("192.0.2.1:1"))
+		codes = append(codes, w.Code)
+		if w.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("RateLimit-Limit %q", w.Header().Get("RateLimit-Limit"))
+		}
+
+		if i == 2 && w.Header().Get("Retry-After") != "30" {
+			t.Errorf("Retry-After %q, want 30", w.Header().Get("Retry-After"))
+		}
+	}
+
+	if codes[0] != 200 || codes[1] != 200 || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("codes %v, want 200 200 429", codes)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, newRequest("192.0.2.2:1"))
+	if w.Code != 200 {
+		t.Errorf("another client got %d", w.Code)
+	}
+}
+
+func TestNewMethods(t *testing.T) {
+	h := New(Limit{Requests: 1, Per: time.Hour}, Options{Methods: []string{"post"}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest("192.0.2.1:1"))
+		if w.Code != 200 {
+			t.Fatalf("GET limited: %d", w.Code)
+		}
+	}
+
+	for i, want := range []int{200, 429} {
+		w := httptest.NewRecorder()
+		r := newRequest("192.0.2.1:1")
+		r.Method = "POST"
+		h.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Errorf("POST %d: %d, want %d", i, w.Code, want)
+		}
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(key string, limit Limit) (Result, error) {
+	return Result{}, http.ErrServerClosed
+}
+
+func TestNewFailingStoreLetsThrough(t *testing.T) {
+	h := New(Limit{Requests: 1, Per: time.Hour}, Options{Store: failingStore{}})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, newRequest("192.0.2.1:1"))
+		if w.Code != 200 {
+			t.Errorf("request %d: %d", i, w.Code)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The one command RedisStore needs, so any Redis client can be adapted to
+// it: EVAL of a Lua script with its keys and arguments, returning its
+// reply.
+type RedisClient interface {
+	Eval(script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+// Take a token from the bucket of KEYS[1], a hash of its tokens and the
+// time in milliseconds it was last used, given the burst, the refill
+// interval in milliseconds and the time now.  It returns whether a token
+// was taken and the tokens left, as a string since Redis truncates
+// numbers returned by Lua to integers.
+const takeScript = `
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(state[1]) or burst
+local last = tonumber(state[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) / interval)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((burst - tokens) * interval) + 1000)
+return {allowed, tostring(tokens)}
+`
+
+// A Store that keeps the buckets in Redis under prefix+key, so that the
+// processes of an application share them.  The buckets are updated
+// atomically by a script and expire once full.  The clocks of the
+// processes should be in sync.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Take(key string, limit Limit) (Result, error) {
+	interval := float64(limit.interval()) / float64(time.Millisecond)
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	reply, err := s.client.Eval(takeScript, []string{s.prefix + key}, limit.burst(), strconv.FormatFloat(interval, 'f', -1, 64), now)
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+
+	var tokens float64
+	switch t := values[1].(type) {
+	case string:
+		tokens, err = strconv.ParseFloat(t, 64)
+	case []byte:
+		tokens, err = strconv.ParseFloat(string(t), 64)
+	default:
+		err = fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	// The script took the token already; work out the rest from what is
+	// left, as if it were taken again from one more.
+	allowed := fmt.Sprint(values[0]) == "1"
+	if allowed {
+		tokens++
+	}
+
+	_, result := take(tokens, limit)
+
+	return result, nil
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	fullAt time.Time
+}
+
+// A Store that keeps the buckets in process memory.  Buckets that have
+// refilled are dropped every minute or so.
+type MemoryStore struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   map[string]*bucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Refill the bucket for the time elapsed since it was last used.
+func (b *bucket) refill(now time.Time, limit Limit) {
+	b.tokens += float64(now.Sub(b.last)) / float64(limit.interval())
+	if burst := float64(limit.burst()); b.tokens > burst {
+		b.tokens = burst
+	}
+
+	b.last = now
+}
+
+// Work out the Result of taking a token from a bucket, refilled already.
+func take(tokens float64, limit Limit) (float64, Result) {
+	result := Result{}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(limit.interval()))
+	}
+
+	result.Remaining = int(tokens)
+	result.Reset = time.Duration((float64(limit.burst()) - tokens) * float64(limit.interval()))
+
+	return tokens, result
+}
+
+func (s *MemoryStore) Take(key string, limit Limit) (Result, error) {
+	now := time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.burst()), last: now}
+		s.buckets[key] = b
+	}
+
+	b.refill(now, limit)
+	var result Result
+	b.tokens, result = take(b.tokens, limit)
+	b.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// Drop the buckets that would be full by now, which are no different from
+// missing ones.
+func (s *MemoryStore) sweep(now time.Time) {
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreRefills(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Requests: 10, Per: time.Second, Burst: 2}
+	for i, want := range []bool{true, true, false} {
+		result, err := s.Take("k", limit)
+		if err != nil || result.Allowed != want {
+			t.Fatalf("take %d: %+v %v, want allowed %v", i, result, err, want)
+		}
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if result, _ := s.Take("k", limit); !result.Allowed {
+		t.Errorf("not refilled after a token interval: %+v", result)
+	}
+}
+
+func TestMemoryStoreSweeps(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Requests: 1, Per: time.Millisecond}
+	s.Take("k", limit)
+	time.Sleep(5 * time.Millisecond)
+	s.mutex.Lock()
+	s.sweep(time.Now())
+	n := len(s.buckets)
+	s.mutex.Unlock()
+	if n != 0 {
+		t.Errorf("%d full buckets kept", n)
+	}
+}
@@ -0,0 +1,97 @@
+package recovery
+
+import (
+	"fmt"
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
+	"html/template"
+	"net/http"
+	"runtime/debug"
+)
+
+// A Reporter sends a recovered panic, with the stack of the goroutine that
+// panicked, to an error tracking service.
+type Reporter func(r *http.Request, err interface{}, stack []byte)
+
+type Options struct {
+	// Render the panic, its stack and the request, for development.
+	// Never enable it in production: it discloses the source.
+	Debug bool
+
+	// The logger the panic and stack are written to, log.Default() if nil.
+	Logger *log.Logger
+
+	// Called after logging, for each recovered panic.
+	Reporters []Reporter
+
+	// Answers the request in production, with the status already set to
+	// 500.  A plain "Internal Server Error" page is written if nil.
+	ErrorHandler http.Handler
+}
+
+var debugPage = template.Must(template.New("panic").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>panic: {{.Err}}</title></head>
+<body>
+<h1>panic: {{.Err}}</h1>
+<p>{{.Method}} {{.URL}}</p>
+<pre>{{.Stack}}</pre>
+</body>
+</html>
+`))
+
+// Return a middleware that recovers the panics of the handler, logs them
+// with their stack at LEVEL_ERROR, passes them to the reporters, and
+// answers 500 with the debug page or the error handler.
+// http.ErrAbortHandler is let through, as net/http expects.
+func New(opts Options) middleware.Middleware {
+	logger := opts.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+
+				stack := debug.Stack()
+				logger.WithContext(r.Context()).WithFields(log.Fields{
+					"method": r.Method,
+					"path":   r.URL.Path,
+					"stack":  string(stack),
+				}).Error("<recovery.New> ", "panic: ", err)
+
+				for _, report := range opts.Reporters {
+					report(r, err, stack)
+				}
+
+				switch {
+				case opts.Debug:
+					w.Header().Set("Content-Type", "text/html; charset=utf-8")
+					w.WriteHeader(http.StatusInternalServerError)
+					debugPage.Execute(w, map[string]interface{}{
+						"Err":    fmt.Sprint(err),
+						"Method": r.Method,
+						"URL":    r.URL.String(),
+						"Stack":  string(stack),
+					})
+				case opts.ErrorHandler != nil:
+					w.WriteHeader(http.StatusInternalServerError)
+					opts.ErrorHandler.ServeHTTP(w, r)
+				default:
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				}
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package recovery
+
+import (
+	"bytes"
+	"golanger.com/framework/log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func panicking(w http.ResponseWriter, r *http.Request) {
+	panic("<b>boom</b>")
+}
+
+func serve(opts Options, h http.HandlerFunc) (*httptest.ResponseRecorder, string) {
+	var buf bytes.Buffer
+	opts.Logger = log.New(log.NewTextHandler(&buf), log.LEVEL_ALL)
+	w := httptest.NewRecorder()
+	New(opts)(h).ServeHTTP(w, httptest.NewRequest("GET", "/orders?id=1", nil))
+
+	return w, buf.String()
+}
+
+func TestRecover(t *testing.T) {
+	var reported interface{}
+	var stack []byte
+	w, logged := serve(Options{Reporters: []Reporter{func(r *http.Request, err interface{}, s []byte) {
+		reported, stack = err, s
+	}}}, panicking)
+
+	if w.Code != 500 || strings.Contains(w.Body.String(), "boom") {
+		t.Errorf("response %d %q", w.Code, w.Body.String())
+	}
+
+	if !strings.Contains(logged, "[ERROR] <recovery.New> panic: <b>boom</b>") || !strings.Contains(logged, "path=/orders") {
+		t.Errorf("logged %q", logged)
+	}
+
+	if reported != "<b>boom</b>" || !bytes.Contains(stack, []byte("panicking")) {
+		t.Errorf("reported %v with stack\n%s", reported, stack)
+	}
+}
+
+func TestDebugPage(t *testing.T) {
+	w, _ := serve(Options{Debug: true}, panicking)
+	body := w.Body.String()
+	if w.Code != 500 || !strings.Contains(body, "panic: &lt;b&gt;boom&lt;/b&gt;") || !strings.Contains(body, "GET /orders?id=1") {
+		t.Errorf("debug page %d %q", w.Code, body)
+	}
+
+	if strings.Contains(body, "<b>boom") {
+		t.Error("panic value not escaped")
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	w, _ := serve(Options{ErrorHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("sorry"))
+	})}, panicking)
+
+	if w.Code != 500 || w.Body.String() != "sorry" {
+		t.Errorf("response %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestNoPanic(t *testing.T) {
+	w, logged := serve(Options{}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("fine"))
+	})
+
+	if w.Code != 200 || w.Body.String() != "fine" || logged != "" {
+		t.Errorf("response %d %q, logged %q", w.Code, w.Body.String(), logged)
+	}
+}
+
+func TestAbortHandlerRepanics(t *testing.T) {
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", err)
+		}
+	}()
+
+	serve(Options{}, func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+}
@@ -0,0 +1,207 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Options of WithCaching.
+type CachingOptions struct {
+	// Send weak ETags (W/"..."), which only promise equivalent content,
+	// e.g. when a proxy may compress the response.
+	Weak bool
+	// The Cache-Control header of the responses that do not set one,
+	// "no-cache" by default: browsers keep the page but revalidate it on
+	// every use, which costs a 304 instead of the whole page.
+	CacheControl string
+}
+
+// Return a middleware that adds an ETag, a hash of the body, to the 200
+// responses of GET and HEAD requests and answers 304 Not Modified when the
+// If-None-Match header of the request holds it, or, failing that, when a
+// Last-Modified header set by the handler is no later than the
+// If-Modified-Since header.  Handlers may set their own ETag.  Responses
+// are held back until the handler returns, unless it flushes them.
+func WithCaching(opts CachingOptions) func(next http.Handler) http.Handler {
+	if opts.CacheControl == "" {
+		opts.CacheControl = "no-cache"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "GET" && r.Method != "HEAD" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &cachingWriter{ResponseWriter: w}
+			next.ServeHTTP(cw, r)
+			cw.finish(r, opts)
+		})
+	}
+}
+
+// Set the ETag and Last-Modified headers of a response and, if the request
+// already has that version, answer 304 and return true.  Either may be
+// empty or zero.  Handlers call it before building an expensive response:
+//
+//	if render.NotModified(w, r, `"`+strconv.Itoa(post.Version)+`"`, post.Updated) {
+//		return
+//	}
+func NotModified(w http.ResponseWriter, r *http.Request, etag string, modified time.Time) bool {
+	h := w.Header()
+	if etag != "" {
+		h.Set("ETag", etag)
+	}
+
+	if !modified.IsZero() {
+		h.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if (r.Method != "GET" && r.Method != "HEAD") || !fresh(r, h) {
+		return false
+	}
+
+	writeNotModified(w)
+
+	return true
+}
+
+// Report whether the client's copy of a response with header h is
+// current.  If-Modified-Since only counts without If-None-Match.
+func fresh(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := h.Get("ETag")
+		if etag == "" {
+			return false
+		}
+
+		for _, t := range strings.Split(inm, ",") {
+			t = strings.TrimSpace(t)
+			// The weak comparison of RFC 7232: W/ is ignored.
+			if t == "*" || strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(h.Get("Last-Modified"))
+
+	return err == nil && !modified.After(ims)
+}
+
+func writeNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	delete(h, "Content-Type")
+	delete(h, "Content-Length")
+	delete(h, "Content-Encoding")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// A cachingWriter holds back a 200 response to hash its body.
+type cachingWriter struct {
+	http.ResponseWriter
+	status    int
+	buffering bool
+	buf       bytes.Buffer
+}
+
+func (w *cachingWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+
+	w.status = code
+	w.buffering = code == http.StatusOK && w.Header().Get("Content-Encoding") == ""
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *cachingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buffering {
+		return w.buf.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Send what was held back, without an ETag, and stop buffering, so that
+// streamed responses go out as they are written.
+func (w *cachingWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buffering {
+		w.buffering = false
+		w.ResponseWriter.WriteHeader(w.status)
+		w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *cachingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("render: ResponseWriter does not support Hijack")
+	}
+
+	return h.Hijack()
+}
+
+func (w *cachingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Tag the held back response and send it, or 304 if the client has it.
+func (w *cachingWriter) finish(r *http.Request, opts CachingOptions) {
+	if !w.buffering {
+		return
+	}
+
+	h := w.Header()
+	if h.Get("ETag") == "" {
+		sum := sha1.Sum(w.buf.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:]) + `"`
+		if opts.Weak {
+			etag = "W/" + etag
+		}
+
+		h.Set("ETag", etag)
+	}
+
+	if h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", opts.CacheControl)
+	}
+
+	if fresh(r, h) {
+		writeNotModified(w.ResponseWriter)
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	w.ResponseWriter.Write(w.buf.Bytes())
+}
@@ -0,0 +1,138 @@
+package render
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Serve a request with the headers of header through WithCaching(opts)
+// around h.
+func cached(opts CachingOptions, h http.HandlerFunc, method string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/", nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	WithCaching(opts)(h).ServeHTTP(w, r)
+
+	return w
+}
+
+func hello(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte("hello"))
+}
+
+func TestETag(t *testing.T) {
+	w := cached(CachingOptions{}, hello, "GET", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "hello" || len(etag) != 42 || w.Header().Get("Cache-Control") != "no-cache" {
+		t.Fatalf("response %d %q, ETag %q, Cache-Control %q", w.Code, w.Body.String(), etag, w.Header().Get("Cache-Control"))
+	}
+
+	for _, inm := range []string{etag, `"other", ` + etag, "W/" + etag, "*"} {
+		w = cached(CachingOptions{}, hello, "GET", map[string]string{"If-None-Match": inm})
+		if w.Code != 304 || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+			t.Errorf("If-None-Match %s: %d %q", inm, w.Code, w.Body.String())
+		}
+	}
+
+	w = cached(CachingOptions{}, hello, "GET", map[string]string{"If-None-Match": `"stale"`})
+	if w.Code != 200 || w.Body.String() != "hello" {
+		t.Errorf("stale tag answered %d", w.Code)
+	}
+
+	if w := cached(CachingOptions{Weak: true, CacheControl: "private"}, hello, "GET", nil); w.Header().Get("ETag") != "W/"+etag || w.Header().Get("Cache-Control") != "private" {
+		t.Errorf("weak ETag %q, Cache-Control %q", w.Header().Get("ETag"), w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestCachingLeavesAlone(t *testing.T) {
+	if w := cached(CachingOptions{}, hello, "POST", nil); w.Header().Get("ETag") != "" {
+		t.Error("ETag on a POST")
+	}
+
+	missing := func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}
+
+	if w := cached(CachingOptions{}, missing, "GET", nil); w.Code != 404 || w.Header().Get("ETag") != "" {
+		t.Errorf("404 answered %d with ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	own := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v7"`)
+		w.Header().Set("Cache-Control", "max-age=60")
+		hello(w, r)
+	}
+
+	if w := cached(CachingOptions{}, own, "GET", map[string]string{"If-None-Match": `"v7"`}); w.Code != 304 || w.Header().Get("Cache-Control") != "max-age=60" {
+		t.Errorf("own ETag answered %d, Cache-Control %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestIfModifiedSince(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	h := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		hello(w, r)
+	}
+
+	cases := map[string]int{
+		modified.Format(http.TimeFormat):                   304,
+		modified.Add(time.Hour).Format(http.TimeFormat):    304,
+		modified.Add(-time.Second).Format(http.TimeFormat): 200,
+		"garbage": 200,
+	}
+
+	for ims, want := range cases {
+		if w := cached(CachingOptions{}, h, "GET", map[string]string{"If-Modified-Since": ims}); w.Code != want {
+			t.Errorf("If-Modified-Since %s: %d, want %d", ims, w.Code, want)
+		}
+	}
+
+	header := map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat), "If-None-Match": `"stale"`}
+	if w := cached(CachingOptions{}, h, "GET", header); w.Code != 200 {
+		t.Errorf("If-Modified-Since used over a failed If-None-Match: %d", w.Code)
+	}
+}
+
+func TestCachingFlush(t *testing.T) {
+	w := cached(CachingOptions{}, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("a"))
+		w.(http.Flusher).Flush()
+		w.Write([]byte("b"))
+	}, "GET", nil)
+
+	if !w.Flushed || w.Body.String() != "ab" || w.Header().Get("ETag") != "" {
+		t.Errorf("flushed %v, body %q, ETag %q", w.Flushed, w.Body.String(), w.Header().Get("ETag"))
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("If-None-Match", `"3"`)
+	w := httptest.NewRecorder()
+	if !NotModified(w, r, `"3"`, modified) || w.Code != 304 {
+		t.Errorf("NotModified false, %d", w.Code)
+	}
+
+	if w.Header().Get("Last-Modified") != "Thu, 02 Jan 2020 02:04:05 GMT" {
+		t.Errorf("Last-Modified %q", w.Header().Get("Last-Modified"))
+	}
+
+	w = httptest.NewRecorder()
+	if NotModified(w, httptest.NewRequest("GET", "/", nil), `"3"`, time.Time{}) || w.Header().Get("ETag") != `"3"` {
+		t.Error("NotModified without conditional headers")
+	}
+
+	r = httptest.NewRequest("PUT", "/", nil)
+	r.Header.Set("If-None-Match", `"3"`)
+	if NotModified(httptest.NewRecorder(), r, `"3"`, time.Time{}) {
+		t.Error("NotModified for a PUT")
+	}
+}
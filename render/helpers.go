@@ -0,0 +1,105 @@
+package render
+
+import (
+	"fmt"
+	"golanger.com/framework/authz"
+	"golanger.com/framework/csrf"
+	"golanger.com/framework/flash"
+	"golanger.com/framework/forms"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/validator"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+)
+
+// The helper funcs available in every template.  Applications may add
+// their own here or through Options.Funcs.
+var Funcs = template.FuncMap{
+	"errorFor":      ErrorFor,
+	"hasError":      HasError,
+	"fieldValue":    FieldValue,
+	"csrfField":     csrf.TemplateField,
+	"flashMessages": flash.HTML,
+	"msg":           translate,
+	"can":           authz.TemplateCan,
+	"formFor":       forms.For,
+}
+
+// Translate into the default locale of i18n.Default; pages rendered with
+// a locale get a msg of their own.
+func translate(key string, args ...interface{}) string {
+	return i18n.T("", key, args...)
+}
+
+func readFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	return string(b), err
+}
+
+// Return the first error for key from a *Validation, a Validation or an
+// ErrorMap, or nil.
+func errorFor(key string, errs interface{}) *validator.ValidationError {
+	switch e := errs.(type) {
+	case *validator.Validation:
+		if e != nil {
+			return e.ErrorMap()[key]
+		}
+	case validator.Validation:
+		return e.ErrorMap()[key]
+	case map[string]*validator.ValidationError:
+		return e[key]
+	}
+
+	return nil
+}
+
+// Render the message of the first error for key, e.g.
+// {{errorFor "email" .Validation}}, as <span class="error">, or nothing.
+func ErrorFor(key string, errs interface{}) template.HTML {
+	err := errorFor(key, errs)
+	if err == nil {
+		return ""
+	}
+
+	return template.HTML(`<span class="error">` + template.HTMLEscapeString(err.Message) + `</span>`)
+}
+
+func HasError(key string, errs interface{}) bool {
+	return errorFor(key, errs) != nil
+}
+
+// Return the submitted value of a field, e.g. {{fieldValue "email" .Form}},
+// to repopulate a form.  values may be url.Values, a *http.Request, a map
+// of strings or a struct (by field name).
+func FieldValue(name string, values interface{}) string {
+	switch v := values.(type) {
+	case nil:
+		return ""
+	case url.Values:
+		return v.Get(name)
+	case map[string][]string:
+		return url.Values(v).Get(name)
+	case *http.Request:
+		return v.FormValue(name)
+	case map[string]string:
+		return v[name]
+	case map[string]interface{}:
+		if value, ok := v[name]; ok && value != nil {
+			return fmt.Sprint(value)
+		}
+
+		return ""
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(values))
+	if rv.Kind() == reflect.Struct {
+		if f := rv.FieldByName(name); f.IsValid() && f.CanInterface() {
+			return fmt.Sprint(f.Interface())
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,283 @@
+package render
+
+import (
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Encode v as MessagePack.  Struct fields are named, and may be left out
+// or omitted when empty, by their `msgpack` tag or else their `json` tag;
+// times use the timestamp extension and other encoding.TextMarshalers
+// their text.
+func MarshalMsgPack(v interface{}) ([]byte, error) {
+	e := &msgpackEncoder{}
+	if err := e.encode(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
+
+type msgpackEncoder struct {
+	buf []byte
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (e *msgpackEncoder) encode(rv reflect.Value) error {
+	if !rv.IsValid() {
+		e.buf = append(e.buf, 0xc0)
+		return nil
+	}
+
+	nilable := rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface
+	if nilable && !rv.IsNil() && rv.Elem().Type() == timeType {
+		rv = rv.Elem()
+	}
+
+	if rv.Type() == timeType {
+		e.encodeTime(rv.Interface().(time.Time))
+		return nil
+	}
+
+	if rv.Type().Implements(textMarshalerType) && !(nilable && rv.IsNil()) {
+		text, err := rv.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return err
+		}
+
+		e.encodeString(string(text))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+
+		return e.encode(rv.Elem())
+	case reflect.Bool:
+		if rv.Bool() {
+			e.buf = append(e.buf, 0xc3)
+		} else {
+			e.buf = append(e.buf, 0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.encodeInt(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.encodeUint(rv.Uint())
+	case reflect.Float32:
+		e.buf = append(e.buf, 0xca)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, math.Float32bits(float32(rv.Float())))
+	case reflect.Float64:
+		e.buf = append(e.buf, 0xcb)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, math.Float64bits(rv.Float()))
+	case reflect.String:
+		e.encodeString(rv.String())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			e.encodeBin(b)
+			return nil
+		}
+
+		e.encodeLen(rv.Len(), 0x90, 0xdc, 0xdd)
+		for i := 0; i < rv.Len(); i++ {
+			if err := e.encode(rv.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if rv.IsNil() {
+			e.buf = append(e.buf, 0xc0)
+			return nil
+		}
+
+		e.encodeLen(rv.Len(), 0x80, 0xde, 0xdf)
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := e.encode(iter.Key()); err != nil {
+				return err
+			}
+
+			if err := e.encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return e.encodeStruct(rv)
+	default:
+		return fmt.Errorf("render: cannot encode %s as MessagePack", rv.Type())
+	}
+
+	return nil
+}
+
+func (e *msgpackEncoder) encodeInt(n int64) {
+	switch {
+	case n >= 0:
+		e.encodeUint(uint64(n))
+	case n >= -32:
+		e.buf = append(e.buf, byte(n))
+	case n >= math.MinInt8:
+		e.buf = append(e.buf, 0xd0, byte(n))
+	case n >= math.MinInt16:
+		e.buf = append(e.buf, 0xd1)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n >= math.MinInt32:
+		e.buf = append(e.buf, 0xd2)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xd3)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(n))
+	}
+}
+
+func (e *msgpackEncoder) encodeUint(n uint64) {
+	switch {
+	case n <= 0x7f:
+		e.buf = append(e.buf, byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xcc, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xcd)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	case n <= math.MaxUint32:
+		e.buf = append(e.buf, 0xce)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	default:
+		e.buf = append(e.buf, 0xcf)
+		e.buf = binary.BigEndian.AppendUint64(e.buf, n)
+	}
+}
+
+// Write the header of an array or map of n elements: fix, 16 or 32 bit.
+func (e *msgpackEncoder) encodeLen(n int, fix, b16, b32 byte) {
+	switch {
+	case n < 16:
+		e.buf = append(e.buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, b16)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, b32)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+}
+
+func (e *msgpackEncoder) encodeString(s string) {
+	n := len(s)
+	switch {
+	case n < 32:
+		e.buf = append(e.buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xda)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xdb)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+
+	e.buf = append(e.buf, s...)
+}
+
+func (e *msgpackEncoder) encodeBin(b []byte) {
+	n := len(b)
+	switch {
+	case n <= math.MaxUint8:
+		e.buf = append(e.buf, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		e.buf = append(e.buf, 0xc5)
+		e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(n))
+	default:
+		e.buf = append(e.buf, 0xc6)
+		e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(n))
+	}
+
+	e.buf = append(e.buf, b...)
+}
+
+// Write a time as the timestamp extension (type -1) in its 96 bit form,
+// which holds any time.
+func (e *msgpackEncoder) encodeTime(t time.Time) {
+	e.buf = append(e.buf, 0xc7, 12, 0xff)
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(t.Nanosecond()))
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(t.Unix()))
+}
+
+type msgpackField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+func msgpackFields(t reflect.Type) []msgpackField {
+	fields := []msgpackField{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup("msgpack")
+		if !ok {
+			tag = sf.Tag.Get("json")
+		}
+
+		if tag == "-" {
+			continue
+		}
+
+		f := msgpackField{name: sf.Name, index: i}
+		parts := strings.Split(tag, ",")
+		if parts[0] != "" {
+			f.name = parts[0]
+		}
+
+		for _, opt := range parts[1:] {
+			f.omitEmpty = f.omitEmpty || opt == "omitempty"
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+// Write a struct as a map of its fields.
+func (e *msgpackEncoder) encodeStruct(rv reflect.Value) error {
+	fields := []msgpackField{}
+	for _, f := range msgpackFields(rv.Type()) {
+		if !f.omitEmpty || !rv.Field(f.index).IsZero() {
+			fields = append(fields, f)
+		}
+	}
+
+	e.encodeLen(len(fields), 0x80, 0xde, 0xdf)
+	for _, f := range fields {
+		e.encodeString(f.name)
+		if err := e.encode(rv.Field(f.index)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
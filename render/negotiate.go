@@ -0,0 +1,200 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A Marshaler encodes the data of a response, indented if pretty is set
+// and the format has a readable form.
+type Marshaler func(v interface{}, pretty bool) ([]byte, error)
+
+// Indent the responses of Negotiate, which is the default under
+// `framework run`, which sets GOLANGER_DEV.
+var Pretty = os.Getenv("GOLANGER_DEV") != ""
+
+func marshalJSON(v interface{}, pretty bool) ([]byte, error) {
+	if pretty {
+		return json.MarshalIndent(v, "", "  ")
+	}
+
+	return json.Marshal(v)
+}
+
+func marshalXML(v interface{}, pretty bool) ([]byte, error) {
+	var b []byte
+	var err error
+	if pretty {
+		b, err = xml.MarshalIndent(v, "", "  ")
+	} else {
+		b, err = xml.Marshal(v)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
+
+func marshalMsgPack(v interface{}, pretty bool) ([]byte, error) {
+	return MarshalMsgPack(v)
+}
+
+// The media types Negotiate can answer with, in order of preference when
+// the client accepts several equally.
+var marshalers = struct {
+	sync.RWMutex
+	types []string
+	funcs map[string]Marshaler
+}{
+	types: []string{"application/json", "application/xml", "text/xml", "application/msgpack", "application/x-msgpack"},
+	funcs: map[string]Marshaler{
+		"application/json":      marshalJSON,
+		"application/xml":       marshalXML,
+		"text/xml":              marshalXML,
+		"application/msgpack":   marshalMsgPack,
+		"application/x-msgpack": marshalMsgPack,
+	},
+}
+
+// Set the Marshaler of a media type, replacing the built-in one (to use
+// another JSON encoder, say) or adding a format.  f nil removes the type.
+func SetMarshaler(mediaType string, f Marshaler) {
+	mediaType = strings.ToLower(mediaType)
+	marshalers.Lock()
+	defer marshalers.Unlock()
+
+	_, exists := marshalers.funcs[mediaType]
+	if f == nil {
+		delete(marshalers.funcs, mediaType)
+		for i, t := range marshalers.types {
+			if t == mediaType {
+				marshalers.types = append(marshalers.types[:i:i], marshalers.types[i+1:]...)
+				break
+			}
+		}
+
+		return
+	}
+
+	marshalers.funcs[mediaType] = f
+	if !exists {
+		marshalers.types = append(marshalers.types, mediaType)
+	}
+}
+
+type accepted struct {
+	mediaType string
+	q         float64
+}
+
+// Return the media types of an Accept header by decreasing quality, and
+// those it refuses with quality 0.  An empty header accepts anything.
+func parseAccept(header string) ([]accepted, map[string]bool) {
+	refused := map[string]bool{}
+	if strings.TrimSpace(header) == "" {
+		return []accepted{{"*/*", 1}}, refused
+	}
+
+	types := []accepted{}
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if s, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				q = f
+			}
+		}
+
+		if q > 0 {
+			types = append(types, accepted{mediaType, q})
+		} else {
+			refused[mediaType] = true
+		}
+	}
+
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[i].q > types[j].q
+	})
+
+	return types, refused
+}
+
+// Return the media type to answer r with and its Marshaler, or "" if the
+// client accepts none of them.
+func negotiate(r *http.Request) (string, Marshaler) {
+	marshalers.RLock()
+	defer marshalers.RUnlock()
+
+	types, refused := parseAccept(r.Header.Get("Accept"))
+	for _, a := range types {
+		if f, ok := marshalers.funcs[a.mediaType]; ok {
+			return a.mediaType, f
+		}
+
+		if !strings.HasSuffix(a.mediaType, "/*") {
+			continue
+		}
+
+		// "*/*" matches every type, "application/*" those of its prefix,
+		// except those refused by name.
+		prefix := strings.TrimSuffix(a.mediaType, "*")
+		if prefix == "*/" {
+			prefix = ""
+		}
+
+		for _, t := range marshalers.types {
+			if strings.HasPrefix(t, prefix) && !refused[t] {
+				return t, marshalers.funcs[t]
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// Write data with status 200 in the format the Accept header of r prefers
+// among JSON, XML, MessagePack and those added by SetMarshaler, or answer
+// 406 Not Acceptable.  Nothing is written if data fails to encode (as
+// maps do in XML), so the caller can still send an error response.
+func Negotiate(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	return NegotiateStatus(w, r, http.StatusOK, data)
+}
+
+// Negotiate with another status than 200.
+func NegotiateStatus(w http.ResponseWriter, r *http.Request, status int, data interface{}) error {
+	w.Header().Add("Vary", "Accept")
+	mediaType, marshal := negotiate(r)
+	if marshal == nil {
+		http.Error(w, http.StatusText(http.StatusNotAcceptable), http.StatusNotAcceptable)
+		return nil
+	}
+
+	b, err := marshal(data, Pretty)
+	if err != nil {
+		return err
+	}
+
+	contentType := mediaType
+	if strings.HasPrefix(mediaType, "text/") || mediaType == "application/json" || strings.HasSuffix(mediaType, "xml") {
+		contentType += "; charset=utf-8"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err = w.Write(b)
+
+	return err
+}
@@ -0,0 +1,163 @@
+package render
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type item struct {
+	XMLName struct{} `json:"-" xml:"item" msgpack:"-"`
+	ID      int      `json:"id" xml:"id"`
+	Name    string   `json:"name" xml:"name"`
+	Note    string   `json:"note,omitempty" xml:"note,omitempty"`
+}
+
+func negotiated(accept string, data interface{}) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/", nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+
+	w := httptest.NewRecorder()
+	Negotiate(w, r, data)
+
+	return w
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                                      "application/json; charset=utf-8",
+		"*/*":                                   "application/json; charset=utf-8",
+		"application/xml":                       "application/xml; charset=utf-8",
+		"text/html, text/*;q=0.9":               "text/xml; charset=utf-8",
+		"application/json;q=0.5, text/xml":      "text/xml; charset=utf-8",
+		"application/msgpack":                   "application/msgpack",
+		"application/json;q=0, application/*":   "application/xml; charset=utf-8",
+		"text/html, application/x-msgpack;q=.1": "application/x-msgpack",
+	}
+
+	for accept, want := range cases {
+		w := negotiated(accept, item{ID: 1, Name: "pen"})
+		if got := w.Header().Get("Content-Type"); got != want || w.Code != 200 {
+			t.Errorf("Accept %q: %d %q, want %q", accept, w.Code, got, want)
+		}
+
+		if w.Header().Get("Vary") != "Accept" {
+			t.Errorf("Accept %q: Vary %q", accept, w.Header().Get("Vary"))
+		}
+	}
+
+	if w := negotiated("text/html", item{}); w.Code != http.StatusNotAcceptable {
+		t.Errorf("text/html answered %d", w.Code)
+	}
+}
+
+func TestNegotiateBodies(t *testing.T) {
+	data := item{ID: 1, Name: "pen"}
+	if w := negotiated("application/json", data); w.Body.String() != `{"id":1,"name":"pen"}` {
+		t.Errorf("JSON %q", w.Body.String())
+	}
+
+	if w := negotiated("application/xml", data); w.Body.String() != `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<item><id>1</id><name>pen</name></item>` {
+		t.Errorf("XML %q", w.Body.String())
+	}
+
+	if w := negotiated("application/msgpack", data); hex.EncodeToString(w.Body.Bytes()) != "82a2696401a46e616d65a370656e" {
+		t.Errorf("MessagePack %x", w.Body.Bytes())
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/xml")
+	w := httptest.NewRecorder()
+	if err := Negotiate(w, r, map[string]int{"a": 1}); err == nil || w.Body.Len() != 0 || w.Code != 200 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("failed encoding: %v, wrote %d %q", err, w.Code, w.Body.String())
+	}
+}
+
+func TestNegotiatePretty(t *testing.T) {
+	Pretty = true
+	defer func() { Pretty = false }()
+
+	if w := negotiated("application/json", item{ID: 1}); !strings.Contains(w.Body.String(), "\n  \"id\": 1") {
+		t.Errorf("JSON %q not indented", w.Body.String())
+	}
+}
+
+func TestSetMarshaler(t *testing.T) {
+	SetMarshaler("text/CSV", func(v interface{}, pretty bool) ([]byte, error) {
+		return []byte("id,name\n"), nil
+	})
+	defer SetMarshaler("text/csv", nil)
+
+	if w := negotiated("text/csv", nil); w.Body.String() != "id,name\n" || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Errorf("CSV %q %q", w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	SetMarshaler("text/csv", nil)
+	if w := negotiated("text/csv", nil); w.Code != http.StatusNotAcceptable {
+		t.Errorf("removed type answered %d", w.Code)
+	}
+
+	// Putting JSON back appends it, so restore the order as well.
+	types := append([]string{}, marshalers.types...)
+	defer func() {
+		SetMarshaler("application/json", marshalJSON)
+		marshalers.types = types
+	}()
+	SetMarshaler("application/json", nil)
+	if w := negotiated("*/*", item{}); w.Header().Get("Content-Type") != "application/xml; charset=utf-8" {
+		t.Errorf("*/* answered %q without JSON", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestMarshalMsgPack(t *testing.T) {
+	when := time.Unix(1, 2).UTC()
+	cases := []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "c0"},
+		{true, "c3"},
+		{-1, "ff"},
+		{-33, "d0df"},
+		{200, "ccc8"},
+		{-1000, "d1fc18"},
+		{70000, "ce00011170"},
+		{1.5, "cb3ff8000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{"", "a0"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{[]byte{1, 2}, "c4020102"},
+		{[]int{1, 2, 3}, "93010203"},
+		{[]string(nil), "c0"},
+		{map[string]bool{"a": true}, "81a161c3"},
+		{when, "c70cff000000020000000000000001"},
+		{&when, "c70cff000000020000000000000001"},
+		{struct {
+			A int `msgpack:"a,omitempty"`
+			B int `json:"b"`
+			C int `msgpack:"-"`
+		}{B: 2}, "81a16202"},
+	}
+
+	for _, c := range cases {
+		b, err := MarshalMsgPack(c.v)
+		if err != nil || hex.EncodeToString(b) != c.want {
+			t.Errorf("MarshalMsgPack(%#v) = %x, %v, want %s", c.v, b, err, c.want)
+		}
+	}
+
+	long := make([]int, 16)
+	if b, _ := MarshalMsgPack(long); !bytes.HasPrefix(b, []byte{0xdc, 0, 16}) {
+		t.Errorf("array of 16 starts %x", b[:3])
+	}
+
+	if _, err := MarshalMsgPack(make(chan int)); err == nil {
+		t.Error("no error encoding a channel")
+	}
+}
@@ -0,0 +1,205 @@
+package render
+
+import (
+	"bytes"
+	"golanger.com/framework/i18n"
+	"html/template"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Options of a Render.  Empty fields get the defaults of New.
+type Options struct {
+	// Templates are looked up by name as Directory + name + Extension.
+	Directory string
+	Extension string
+	// The default layout, or "" for none.  A layout renders the page with
+	// {{template "content" .}}; pages may also redefine its {{block}}s.
+	Layout string
+	// Files parsed into every template, such as shared partials, as globs
+	// relative to Directory.
+	Partials []string
+	Funcs    template.FuncMap
+	// Parse templates again on every call instead of caching them, for
+	// development.  It is always set under `framework run`, which sets
+	// GOLANGER_DEV.
+	Reload bool
+}
+
+// A Render executes html/template pages, optionally inside a layout, and
+// caches the parsed templates.  It is safe for concurrent use.
+type Render struct {
+	opts  Options
+	mutex sync.RWMutex
+	cache map[string]*template.Template
+}
+
+func New(opts Options) *Render {
+	if opts.Directory == "" {
+		opts.Directory = "./view/"
+	}
+
+	if opts.Extension == "" {
+		opts.Extension = ".html"
+	}
+
+	if os.Getenv("GOLANGER_DEV") != "" {
+		opts.Reload = true
+	}
+
+	return &Render{
+		opts:  opts,
+		cache: map[string]*template.Template{},
+	}
+}
+
+func (r *Render) path(name string) string {
+	return filepath.Join(r.opts.Directory, name+r.opts.Extension)
+}
+
+// Return the funcs of the templates of locale, whose msg translates into
+// it.
+func (r *Render) funcs(locale string) template.FuncMap {
+	funcs := template.FuncMap{}
+	for k, v := range Funcs {
+		funcs[k] = v
+	}
+
+	if locale != "" {
+		for k, v := range i18n.Funcs(locale) {
+			funcs[k] = v
+		}
+	}
+
+	for k, v := range r.opts.Funcs {
+		funcs[k] = v
+	}
+
+	return funcs
+}
+
+func (r *Render) parse(name, layout, locale string) (*template.Template, error) {
+	root := name
+	if layout != "" {
+		root = layout
+	}
+
+	t := template.New(filepath.Base(r.path(root))).Funcs(r.funcs(locale))
+	for _, pattern := range r.opts.Partials {
+		files, err := filepath.Glob(filepath.Join(r.opts.Directory, pattern))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(files) > 0 {
+			if t, err = t.ParseFiles(files...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if layout == "" {
+		return t.ParseFiles(r.path(name))
+	}
+
+	if _, err := t.ParseFiles(r.path(layout)); err != nil {
+		return nil, err
+	}
+
+	content, err := readFile(r.path(name))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := t.New("content").Parse(content); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r *Render) lookup(name, layout, locale string) (*template.Template, error) {
+	key := locale + ":" + layout + ":" + name
+	if !r.opts.Reload {
+		r.mutex.RLock()
+		t, ok := r.cache[key]
+		r.mutex.RUnlock()
+		if ok {
+			return t, nil
+		}
+	}
+
+	t, err := r.parse(name, layout, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.opts.Reload {
+		r.mutex.Lock()
+		r.cache[key] = t
+		r.mutex.Unlock()
+	}
+
+	return t, nil
+}
+
+// Execute the named page inside layout ("" for none) into w.
+func (r *Render) ExecuteLayout(w io.Writer, name, layout string, data interface{}) error {
+	return r.execute(w, name, layout, "", data)
+}
+
+// Execute the named page inside the default layout into w, with msg
+// translating into locale (see i18n.Locale).  The templates are parsed and
+// cached once per locale.
+func (r *Render) ExecuteLocale(w io.Writer, name, locale string, data interface{}) error {
+	return r.execute(w, name, r.opts.Layout, locale, data)
+}
+
+func (r *Render) execute(w io.Writer, name, layout, locale string, data interface{}) error {
+	t, err := r.lookup(name, layout, locale)
+	if err != nil {
+		return err
+	}
+
+	root := name
+	if layout != "" {
+		root = layout
+	}
+
+	return t.ExecuteTemplate(w, filepath.Base(r.path(root)), data)
+}
+
+// Execute the named page inside the default layout into w.
+func (r *Render) Execute(w io.Writer, name string, data interface{}) error {
+	return r.ExecuteLayout(w, name, r.opts.Layout, data)
+}
+
+// Write the named page as an HTML response.  Nothing is written if the
+// template fails, so the caller can still send an error response.
+func (r *Render) HTML(w http.ResponseWriter, status int, name string, data interface{}) error {
+	return r.HTMLLocale(w, status, name, "", data)
+}
+
+// Write the named page as an HTML response in locale, see ExecuteLocale.
+func (r *Render) HTMLLocale(w http.ResponseWriter, status int, name, locale string, data interface{}) error {
+	buf := &bytes.Buffer{}
+	if err := r.ExecuteLocale(buf, name, locale, data); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+// Drop every cached template.
+func (r *Render) ClearCache() {
+	r.mutex.Lock()
+	r.cache = map[string]*template.Template{}
+	r.mutex.Unlock()
+}
@@ -0,0 +1,173 @@
+package render
+
+import (
+	"bytes"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/validator"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Write the templates of files, name then contents, to a new directory.
+func views(t *testing.T, files ...string) string {
+	dir := t.TempDir()
+	for i := 0; i < len(files); i += 2 {
+		path := filepath.Join(dir, files[i])
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(files[i+1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLayoutAndPartials(t *testing.T) {
+	dir := views(t,
+		"layout.html", `<title>{{block "title" .}}Site{{end}}</title>{{template "content" .}}{{template "footer"}}`,
+		"partials/footer.html", `{{define "footer"}}<footer>©</footer>{{end}}`,
+		"posts/show.html", `{{define "title"}}{{.Title}}{{end}}<h1>{{.Title}}</h1>`,
+		"plain.html", `<p>{{.Title}}</p>`,
+	)
+
+	r := New(Options{Directory: dir, Layout: "layout", Partials: []string{"partials/*.html"}})
+	var buf bytes.Buffer
+	if err := r.Execute(&buf, "posts/show", map[string]string{"Title": "<Go>"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := buf.String(); got != "<title>&lt;Go&gt;</title><h1>&lt;Go&gt;</h1><footer>©</footer>" {
+		t.Errorf("page %q", got)
+	}
+
+	buf.Reset()
+	if err := r.ExecuteLayout(&buf, "plain", "", map[string]string{"Title": "x"}); err != nil || buf.String() != "<p>x</p>" {
+		t.Errorf("page %q, %v", buf.String(), err)
+	}
+}
+
+func TestCacheAndReload(t *testing.T) {
+	dir := views(t, "page.html", "one")
+	cached := New(Options{Directory: dir})
+	reloaded := New(Options{Directory: dir, Reload: true})
+	for _, r := range []*Render{cached, reloaded} {
+		r.Execute(&bytes.Buffer{}, "page", nil)
+	}
+
+	os.WriteFile(filepath.Join(dir, "page.html"), []byte("two"), 0644)
+	var a, b bytes.Buffer
+	cached.Execute(&a, "page", nil)
+	reloaded.Execute(&b, "page", nil)
+	if a.String() != "one" || b.String() != "two" {
+		t.Errorf("cached %q, reloaded %q", a.String(), b.String())
+	}
+
+	cached.ClearCache()
+	a.Reset()
+	if cached.Execute(&a, "page", nil); a.String() != "two" {
+		t.Errorf("after ClearCache %q", a.String())
+	}
+}
+
+func TestDevReload(t *testing.T) {
+	t.Setenv("GOLANGER_DEV", "1")
+	dir := views(t, "page.html", "one")
+	r := New(Options{Directory: dir})
+	r.Execute(&bytes.Buffer{}, "page", nil)
+
+	os.WriteFile(filepath.Join(dir, "page.html"), []byte("two"), 0644)
+	var buf bytes.Buffer
+	if r.Execute(&buf, "page", nil); buf.String() != "two" {
+		t.Errorf("cached %q under GOLANGER_DEV", buf.String())
+	}
+}
+
+func TestHTML(t *testing.T) {
+	dir := views(t, "ok.html", "<p>{{.}}</p>", "broken.html", "{{.Missing.Field}}")
+	r := New(Options{Directory: dir})
+
+	w := httptest.NewRecorder()
+	if err := r.HTML(w, 201, "ok", "hi"); err != nil || w.Code != 201 || w.Body.String() != "<p>hi</p>" || w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("HTML %d %q, %v", w.Code, w.Body.String(), err)
+	}
+
+	w = httptest.NewRecorder()
+	if err := r.HTML(w, 200, "broken", 3); err == nil || w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+		t.Errorf("failed template: %v, wrote %q", err, w.Body.String())
+	}
+
+	if err := r.HTML(httptest.NewRecorder(), 200, "missing", nil); err == nil {
+		t.Error("no error for a missing template")
+	}
+}
+
+func TestErrorFor(t *testing.T) {
+	v := &validator.Validation{}
+	v.Error("Must be <b>valid</b>").Key("email")
+
+	for _, errs := range []interface{}{v, *v, v.ErrorMap()} {
+		if got := ErrorFor("email", errs); got != `<span class="error">Must be &lt;b&gt;valid&lt;/b&gt;</span>` {
+			t.Errorf("ErrorFor(%T) = %q", errs, got)
+		}
+
+		if !HasError("email", errs) || HasError("name", errs) {
+			t.Errorf("HasError(%T) wrong", errs)
+		}
+	}
+
+	if ErrorFor("email", nil) != "" || ErrorFor("email", (*validator.Validation)(nil)) != "" {
+		t.Error("error rendered without a Validation")
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	form := url.Values{"email": {"a@example.com"}}
+	r := httptest.NewRequest("POST", "/?email=a@example.com", strings.NewReader(""))
+	type signup struct {
+		Email  string
+		secret string
+	}
+
+	for _, values := range []interface{}{
+		form,
+		map[string][]string(form),
+		r,
+		map[string]string{"email": "a@example.com"},
+		map[string]interface{}{"email": "a@example.com"},
+	} {
+		if got := FieldValue("email", values); got != "a@example.com" {
+			t.Errorf("FieldValue(%T) = %q", values, got)
+		}
+	}
+
+	s := &signup{Email: "b@example.com", secret: "x"}
+	if FieldValue("Email", s) != "b@example.com" || FieldValue("secret", s) != "" || FieldValue("x", nil) != "" {
+		t.Error("FieldValue of a struct")
+	}
+}
+
+func TestExecuteLocale(t *testing.T) {
+	i18n.Default.SetMessages("pt", map[string]string{"render.test.hello": "Olá, %s"})
+	dir := views(t, "hello.html", `{{msg "render.test.hello" .}}`)
+	r := New(Options{Directory: dir})
+
+	for locale, want := range map[string]string{"pt-BR": "Olá, Ana", "": "render.test.hello"} {
+		var buf bytes.Buffer
+		if err := r.ExecuteLocale(&buf, "hello", locale, "Ana"); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != want {
+			t.Errorf("in %q rendered %q, want %q", locale, buf.String(), want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if err := r.HTMLLocale(w, 201, "hello", "pt", "Ana"); err != nil || w.Code != 201 || w.Body.String() != "Olá, Ana" {
+		t.Errorf("HTMLLocale = %v, %d %q", err, w.Code, w.Body)
+	}
+}
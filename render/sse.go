@@ -0,0 +1,198 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How often a Stream sends a comment line to keep idle connections, and
+// the proxies on their way, from timing out.
+var SSEHeartbeat = 15 * time.Second
+
+var ErrStreamClosed = errors.New("render: event stream closed")
+
+// A Stream of server-sent events, as read by the browser's EventSource.
+// Each event is flushed as soon as it is sent.  It is safe for concurrent
+// use.
+type Stream struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	r       *http.Request
+	mutex   sync.Mutex
+	closed  bool
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Start a stream of server-sent events answering r.  The handler sends
+// events until it is done or the client goes away, which Done reports,
+// and must Close the stream before it returns:
+//
+//	s, err := render.SSE(w, r)
+//	if err != nil {
+//		return
+//	}
+//	defer s.Close()
+//
+//	for progress := range updates {
+//		if err := s.Send("progress", progress); err != nil {
+//			return // the client has gone
+//		}
+//	}
+func SSE(w http.ResponseWriter, r *http.Request) (*Stream, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, errors.New("render: ResponseWriter does not support Flush")
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// Tell nginx not to buffer the stream.
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	s := &Stream{
+		w:       w,
+		flusher: flusher,
+		r:       r,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go s.heartbeat()
+
+	return s, nil
+}
+
+func (s *Stream) heartbeat() {
+	defer close(s.stopped)
+
+	ticker := time.NewTicker(SSEHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if s.write(": ping\n\n") != nil {
+				return
+			}
+		case <-s.stop:
+			return
+		case <-s.r.Context().Done():
+			return
+		}
+	}
+}
+
+// Return a channel closed once the client has disconnected.
+func (s *Stream) Done() <-chan struct{} {
+	return s.r.Context().Done()
+}
+
+// Return the ID of the last event the client got before reconnecting, or
+// "", to resume the stream from there.
+func (s *Stream) LastEventID() string {
+	return s.r.Header.Get("Last-Event-ID")
+}
+
+func (s *Stream) write(text string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	if err := s.r.Context().Err(); err != nil {
+		return err
+	}
+
+	if _, err := s.w.Write([]byte(text)); err != nil {
+		return err
+	}
+
+	s.flusher.Flush()
+
+	return nil
+}
+
+// Send an event named event, or a message if event is empty, with data:
+// a string or []byte as is, anything else encoded as JSON.  It fails once
+// the client has gone.
+func (s *Stream) Send(event string, data interface{}) error {
+	return s.SendID("", event, data)
+}
+
+// Send an event with an id, which the client sends back as Last-Event-ID
+// when it reconnects.
+func (s *Stream) SendID(id, event string, data interface{}) error {
+	var payload string
+	switch d := data.(type) {
+	case string:
+		payload = d
+	case []byte:
+		payload = string(d)
+	default:
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		payload = string(b)
+	}
+
+	var buf strings.Builder
+	if id != "" {
+		buf.WriteString("id: " + oneLine(id) + "\n")
+	}
+
+	if event != "" {
+		buf.WriteString("event: " + oneLine(event) + "\n")
+	}
+
+	// EventSource ends lines at "\r" too, which would otherwise let the
+	// data start a field of its own.
+	payload = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(payload)
+	for _, line := range strings.Split(payload, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+
+	buf.WriteString("\n")
+
+	return s.write(buf.String())
+}
+
+// Tell the client how long to wait before reconnecting once the stream
+// ends.
+func (s *Stream) Retry(d time.Duration) error {
+	return s.write("retry: " + strconv.FormatInt(int64(d/time.Millisecond), 10) + "\n\n")
+}
+
+func oneLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// Stop the heartbeat; nothing can be sent afterwards.  The handler must
+// call it before returning.
+func (s *Stream) Close() {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+
+	s.closed = true
+	s.mutex.Unlock()
+
+	close(s.stop)
+	<-s.stopped
+}
@@ -0,0 +1,81 @@
+package render
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSE(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/events", nil)
+	r.Header.Set("Last-Event-ID", "41")
+	s, err := SSE(w, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.LastEventID() != "41" || w.Header().Get("Content-Type") != "text/event-stream" || !w.Flushed {
+		t.Errorf("Last-Event-ID %q, headers %v, flushed %v", s.LastEventID(), w.Header(), w.Flushed)
+	}
+
+	s.Send("", "hello")
+	s.SendID("42", "progress", map[string]int{"done": 3})
+	s.Send("multi", "a\r\nb\nc\rid: 99")
+	s.SendID("4\n3", "x\ny", []byte("raw"))
+	s.Retry(1500 * time.Millisecond)
+	s.Close()
+
+	want := "data: hello\n\n" +
+		"id: 42\nevent: progress\ndata: {\"done\":3}\n\n" +
+		"event: multi\ndata: a\ndata: b\ndata: c\ndata: id: 99\n\n" +
+		"id: 43\nevent: xy\ndata: raw\n\n" +
+		"retry: 1500\n\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("stream\n%q\nwant\n%q", got, want)
+	}
+
+	if err := s.Send("", "late"); err != ErrStreamClosed {
+		t.Errorf("Send after Close: %v", err)
+	}
+
+	s.Close()
+}
+
+func TestSSEClientGone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	s, _ := SSE(httptest.NewRecorder(), r)
+	defer s.Close()
+
+	cancel()
+	<-s.Done()
+	if err := s.Send("", "x"); err == nil {
+		t.Error("no error sending to a client that has gone")
+	}
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	heartbeat := SSEHeartbeat
+	SSEHeartbeat = time.Millisecond
+	defer func() { SSEHeartbeat = heartbeat }()
+
+	w := httptest.NewRecorder()
+	s, _ := SSE(w, httptest.NewRequest("GET", "/events", nil))
+	time.Sleep(20 * time.Millisecond)
+	s.Close()
+
+	if !strings.HasPrefix(w.Body.String(), ": ping\n\n") {
+		t.Errorf("stream %q, want heartbeats", w.Body.String())
+	}
+}
+
+func TestSSEWithoutFlusher(t *testing.T) {
+	w := struct{ http.ResponseWriter }{httptest.NewRecorder()}
+	if _, err := SSE(w, httptest.NewRequest("GET", "/events", nil)); err == nil {
+		t.Error("no error without a Flusher")
+	}
+}
@@ -0,0 +1,201 @@
+package request
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Names that SetTrustedProxies accepts in place of CIDRs.
+var namedRanges = map[string][]string{
+	"loopback":  {"127.0.0.0/8", "::1/128"},
+	"private":   {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+	"linklocal": {"169.254.0.0/16", "fe80::/10"},
+}
+
+// The proxies, such as load balancers, trusted to tell the address of the
+// client they forward a request for.
+type TrustedProxies struct {
+	mutex sync.RWMutex
+	nets  []*net.IPNet
+}
+
+// Return the proxies of cidrs, see Set.
+func NewTrustedProxies(cidrs ...string) (*TrustedProxies, error) {
+	t := &TrustedProxies{}
+	if err := t.Set(cidrs...); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Replace the trusted proxies with cidrs: networks such as "10.0.0.0/8",
+// single addresses, or "loopback", "private" or "linklocal".  Nothing is
+// changed if one is invalid.
+func (t *TrustedProxies) Set(cidrs ...string) error {
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		names, ok := namedRanges[strings.ToLower(cidr)]
+		if !ok {
+			names = []string{cidr}
+		}
+
+		for _, name := range names {
+			if !strings.Contains(name, "/") {
+				ip := net.ParseIP(name)
+				if ip == nil {
+					return &net.ParseError{Type: "CIDR address", Text: name}
+				}
+
+				bits := 128
+				if ip4 := ip.To4(); ip4 != nil {
+					ip, bits = ip4, 32
+				}
+
+				nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+
+			_, n, err := net.ParseCIDR(name)
+			if err != nil {
+				return err
+			}
+
+			nets = append(nets, n)
+		}
+	}
+
+	t.mutex.Lock()
+	t.nets = nets
+	t.mutex.Unlock()
+
+	return nil
+}
+
+// Report whether any proxy is trusted.
+func (t *TrustedProxies) Configured() bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return len(t.nets) > 0
+}
+
+// Report whether ip is that of a trusted proxy.
+func (t *TrustedProxies) Trusted(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	for _, n := range t.nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return the IP of the client of r.  Unless the peer that sent r is a
+// trusted proxy, that is the peer.  Otherwise X-Forwarded-For is read
+// from the right, each proxy having appended the address it got the
+// request from, and the first address that is not a trusted proxy is the
+// client; the ones left of it could have been made up by the client.
+// Without X-Forwarded-For, X-Real-IP is taken.
+func (t *TrustedProxies) RealIP(r *http.Request) string {
+	return t.realIP(r, false)
+}
+
+// Return the IP of the client of r as RealIP does, treating the peer as a
+// trusted proxy if trustPeer is set.
+func (t *TrustedProxies) realIP(r *http.Request, trustPeer bool) string {
+	peer := PeerIP(r)
+	ip := parseIP(peer)
+	if ip == nil || !(trustPeer || t.Trusted(ip)) {
+		return peer
+	}
+
+	hops := []string{}
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	if len(hops) == 0 {
+		if real := parseIP(r.Header.Get("X-Real-IP")); real != nil {
+			return real.String()
+		}
+
+		return peer
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseIP(hops[i])
+		if hop == nil {
+			// A forged or garbled entry: the last good hop is all that
+			// is known.
+			break
+		}
+
+		client = hop.String()
+		if !t.Trusted(hop) {
+			break
+		}
+	}
+
+	return client
+}
+
+// Parse an address of a forwarding header, with or without a port.
+func parseIP(s string) net.IP {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	if i := strings.Index(s, "%"); i != -1 {
+		s = s[:i]
+	}
+
+	return net.ParseIP(s)
+}
+
+// Return the IP of the peer that sent r, from its RemoteAddr.
+func PeerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// The proxies trusted by RealIP, none until SetTrustedProxies is called.
+var Default = &TrustedProxies{}
+
+// Trust the proxies of cidrs, see TrustedProxies.Set.
+func SetTrustedProxies(cidrs ...string) error {
+	return Default.Set(cidrs...)
+}
+
+// Return the IP of the client of r, behind the Default trusted proxies.
+func RealIP(r *http.Request) string {
+	return Default.RealIP(r)
+}
+
+// Return the IP of the client of r as RealIP does, except that if
+// trustPeer is set and no proxy is configured, the peer is trusted as a
+// single proxy in front of the server.  This is what the TrustProxy
+// options of the packages that predate SetTrustedProxies now mean.
+func ClientIP(r *http.Request, trustPeer bool) string {
+	return Default.realIP(r, trustPeer && !Default.Configured())
+}
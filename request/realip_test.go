@@ -0,0 +1,104 @@
+package request
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	proxies, err := NewTrustedProxies("10.0.0.0/8", "192.0.2.7", "loopback")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		peer, xff, realIP, want string
+	}{
+		// Untrusted peers are the client, whatever they claim.
+		{"203.0.113.5:1234", "1.2.3.4", "", "203.0.113.5"},
+		{"203.0.113.5:1234", "", "1.2.3.4", "203.0.113.5"},
+		// Through a trusted proxy.
+		{"10.0.0.1:80", "198.51.100.9", "", "198.51.100.9"},
+		{"10.0.0.1:80", "", "198.51.100.9", "198.51.100.9"},
+		{"10.0.0.1:80", "", "", "10.0.0.1"},
+		// The client prepended a forged entry; the rightmost untrusted
+		// hop is the client.
+		{"10.0.0.1:80", "6.6.6.6, 198.51.100.9", "", "198.51.100.9"},
+		{"10.0.0.1:80", "6.6.6.6, 198.51.100.9, 10.1.1.1, 192.0.2.7", "", "198.51.100.9"},
+		// Only proxies: the leftmost of them.
+		{"10.0.0.1:80", "10.2.2.2, 10.1.1.1", "", "10.2.2.2"},
+		// Garbage stops the walk at the last good hop.
+		{"10.0.0.1:80", "198.51.100.9, bogus, 10.1.1.1", "", "10.1.1.1"},
+		{"10.0.0.1:80", "[2001:db8::1]:443", "", "2001:db8::1"},
+		{"[::1]:80", "198.51.100.9:5000", "", "198.51.100.9"},
+		{"127.0.0.1:80", "fe80::1%eth0", "", "fe80::1"},
+	}
+
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = c.peer
+		if c.xff != "" {
+			r.Header.Set("X-Forwarded-For", c.xff)
+		}
+
+		if c.realIP != "" {
+			r.Header.Set("X-Real-IP", c.realIP)
+		}
+
+		if got := proxies.RealIP(r); got != c.want {
+			t.Errorf("RealIP(peer %s, X-Forwarded-For %q, X-Real-IP %q) = %s, want %s", c.peer, c.xff, c.realIP, got, c.want)
+		}
+	}
+}
+
+func TestRealIPMultipleHeaders(t *testing.T) {
+	proxies, _ := NewTrustedProxies("private")
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:80"
+	r.Header.Add("X-Forwarded-For", "6.6.6.6")
+	r.Header.Add("X-Forwarded-For", "198.51.100.9, 172.16.0.3")
+	if got := proxies.RealIP(r); got != "198.51.100.9" {
+		t.Errorf("RealIP = %s", got)
+	}
+}
+
+func TestSet(t *testing.T) {
+	proxies, _ := NewTrustedProxies("10.0.0.0/8")
+	for _, bad := range []string{"10.0.0.0/33", "nonsense", "300.1.1.1"} {
+		if err := proxies.Set("192.168.0.0/16", bad); err == nil {
+			t.Errorf("Set(%q) accepted", bad)
+		}
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:80"
+	r.Header.Set("X-Forwarded-For", "198.51.100.9")
+	if got := proxies.RealIP(r); got != "198.51.100.9" {
+		t.Errorf("a failed Set changed the proxies: RealIP = %s", got)
+	}
+
+	proxies.Set()
+	if proxies.Configured() || proxies.RealIP(r) != "10.0.0.1" {
+		t.Error("proxies still trusted after Set()")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "203.0.113.5:1234"
+	r.Header.Set("X-Forwarded-For", "6.6.6.6, 198.51.100.9")
+
+	if got := ClientIP(r, false); got != "203.0.113.5" {
+		t.Errorf("ClientIP without trust = %s", got)
+	}
+
+	if got := ClientIP(r, true); got != "198.51.100.9" {
+		t.Errorf("ClientIP trusting the peer = %s, want the hop it appended", got)
+	}
+
+	defer SetTrustedProxies()
+	SetTrustedProxies("192.0.2.0/24")
+	if got := ClientIP(r, true); got != "203.0.113.5" {
+		t.Errorf("ClientIP = %s, trusting the peer though proxies are configured", got)
+	}
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"golanger.com/framework/middleware"
+	"net/http"
+	"strings"
+)
+
+// A RouteGroup registers routes on its router under a path prefix, and
+// optionally a host pattern, with middleware shared by all of them.  The
+// middleware of a group runs inside that of its router and of the groups
+// it is nested in, and outside that of its routes:
+//
+//	admin := rt.Group("/admin", a.RequireLogin)
+//	admin.GET("/", adminIndex)
+//	users := admin.Group("/users", policy.RequirePermission("users.manage"))
+//	users.GET("/:id", adminUser) // GET /admin/users/:id
+type RouteGroup struct {
+	router      *Router
+	parent      *RouteGroup
+	prefix      string
+	hostPattern string
+	middlewares []middleware.Middleware
+}
+
+// Return a group of the routes under prefix, wrapped by middlewares.
+func (rt *Router) Group(prefix string, middlewares ...middleware.Middleware) *RouteGroup {
+	return &RouteGroup{
+		router:      rt,
+		prefix:      joinPath("", prefix),
+		middlewares: middlewares,
+	}
+}
+
+// Return a group nested in g, under the prefix of g followed by prefix.
+func (g *RouteGroup) Group(prefix string, middlewares ...middleware.Middleware) *RouteGroup {
+	return &RouteGroup{
+		router:      g.router,
+		parent:      g,
+		prefix:      joinPath(g.prefix, prefix),
+		hostPattern: g.hostPattern,
+		middlewares: middlewares,
+	}
+}
+
+func joinPath(prefix, pattern string) string {
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		if prefix == "" {
+			return "/"
+		}
+
+		return prefix
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/" + pattern
+}
+
+// Restrict the routes registered on the group from now on, and on the
+// groups nested in it, to a host pattern, see Route.Host.
+func (g *RouteGroup) Host(pattern string) *RouteGroup {
+	g.hostPattern = pattern
+
+	return g
+}
+
+// Add middleware around every route of the group, including those already
+// registered.
+func (g *RouteGroup) Use(middlewares ...middleware.Middleware) *RouteGroup {
+	g.router.mutex.Lock()
+	g.middlewares = append(g.middlewares, middlewares...)
+	g.router.mutex.Unlock()
+
+	return g
+}
+
+// Return the middleware of the groups from the outermost to g.  The caller
+// holds the mutex of the router.
+func (g *RouteGroup) chain() []middleware.Middleware {
+	if g == nil {
+		return nil
+	}
+
+	return append(g.parent.chain(), g.middlewares...)
+}
+
+// Register h for requests with the given method ("" for any) whose path
+// matches the prefix of the group followed by pattern.
+func (g *RouteGroup) Handle(method, pattern string, h http.Handler) *Route {
+	route := g.router.Handle(method, joinPath(g.prefix, pattern), h)
+	g.router.mutex.Lock()
+	route.group = g
+	g.router.mutex.Unlock()
+
+	if g.hostPattern != "" {
+		route.Host(g.hostPattern)
+	}
+
+	return route
+}
+
+func (g *RouteGroup) HandleFunc(method, pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return g.Handle(method, pattern, http.HandlerFunc(f))
+}
+
+func (g *RouteGroup) GET(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return g.HandleFunc("GET", pattern, f)
+}
+
+func (g *RouteGroup) POST(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return g.HandleFunc("POST", pattern, f)
+}
+
+func (g *RouteGroup) PUT(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return g.HandleFunc("PUT", pattern, f)
+}
+
+func (g *RouteGroup) DELETE(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return g.HandleFunc("DELETE", pattern, f)
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+)
+
+// Return a middleware writing name before the handler.
+func tag(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(name + " "))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestGroups(t *testing.T) {
+	rt := New()
+	rt.Use(tag("router"))
+	admin := rt.Group("/admin/", tag("admin"))
+	admin.GET("/", echo("index"))
+	users := admin.Group("users", tag("users"))
+	users.GET("/:id", echo("user")).Use(tag("route"))
+	admin.Use(tag("late"))
+	rt.GET("/admin-not", echo("outside"))
+
+	cases := map[string]string{
+		"/admin":         "router admin late index",
+		"/admin/users/":  "404 page not found\n",
+		"/admin/users/3": "router admin late users route user id=3",
+		"/admin-not":     "router outside",
+	}
+
+	for target, want := range cases {
+		if w := serve(rt, "GET", target); w.Body.String() != want {
+			t.Errorf("GET %s = %q, want %q", target, w.Body.String(), want)
+		}
+	}
+}
+
+func TestGroupHost(t *testing.T) {
+	rt := New()
+	api := rt.Group("/v1").Host("api.example.com")
+	api.GET("/ping", echo("pong")).Named("ping")
+	nested := api.Group("/admin")
+	nested.GET("/", echo("admin"))
+
+	for target, want := range map[string]string{"/v1/ping": "pong", "/v1/admin": "admin"} {
+		r := serveHost(rt, "api.example.com", target)
+		if r != want {
+			t.Errorf("api.example.com%s = %q, want %q", target, r, want)
+		}
+
+		if r := serveHost(rt, "www.example.com", target); r == want {
+			t.Errorf("www.example.com%s served the api route", target)
+		}
+	}
+
+	if got, _ := rt.URLFor("ping"); got != "//api.example.com/v1/ping" {
+		t.Errorf("URLFor = %q", got)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	cases := []struct{ prefix, pattern, want string }{
+		{"", "", "/"},
+		{"", "/", "/"},
+		{"", "admin/", "/admin"},
+		{"/admin", "", "/admin"},
+		{"/admin", "/users", "/admin/users"},
+		{"/", "users", "/users"},
+	}
+
+	for _, c := range cases {
+		if got := joinPath(c.prefix, c.pattern); got != c.want {
+			t.Errorf("joinPath(%q, %q) = %q, want %q", c.prefix, c.pattern, got, c.want)
+		}
+	}
+}
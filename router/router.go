@@ -0,0 +1,442 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"golanger.com/framework/debug"
+	"golanger.com/framework/middleware"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type paramsKey struct{}
+
+type routeKey struct{}
+
+// Where the router records the route it matches, for the middleware of
+// Track running before it.
+type routeSlot struct {
+	route *Route
+}
+
+type slotKey struct{}
+
+type segment struct {
+	literal  string
+	param    string
+	wildcard bool
+}
+
+// A Route is a method and path pattern bound to a handler.  Patterns are
+// made of literal segments, ":name" segments matching one path segment and
+// an optional final "*name" segment matching the rest of the path.  A
+// route may also be restricted to a host pattern, see Host.
+type Route struct {
+	Method      string
+	Pattern     string
+	HostPattern string
+	Name        string
+	Handler     http.Handler
+	segments    []segment
+	host        []segment
+	router      *Router
+	group       *RouteGroup
+	middlewares []middleware.Middleware
+}
+
+// A Router dispatches requests to the first registered Route whose method
+// and pattern match.
+type Router struct {
+	mutex            sync.RWMutex
+	routes           []*Route
+	named            map[string]*Route
+	middlewares      []middleware.Middleware
+	NotFound         http.Handler
+	MethodNotAllowed http.Handler
+}
+
+func New() *Router {
+	return &Router{
+		named: map[string]*Route{},
+	}
+}
+
+func parsePattern(pattern string) []segment {
+	parts := splitPath(pattern)
+	segments := []segment{}
+	for i, s := range parts {
+		switch {
+		case strings.HasPrefix(s, ":"):
+			segments = append(segments, segment{param: s[1:]})
+		case strings.HasPrefix(s, "*"):
+			if i != len(parts)-1 {
+				panic("router: wildcard must be the last segment of " + pattern)
+			}
+
+			segments = append(segments, segment{param: s[1:], wildcard: true})
+		default:
+			segments = append(segments, segment{literal: s})
+		}
+	}
+
+	return segments
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+
+	return strings.Split(path, "/")
+}
+
+// Register h for requests with the given method ("" for any) whose path
+// matches pattern.
+func (rt *Router) Handle(method, pattern string, h http.Handler) *Route {
+	route := &Route{
+		Method:   strings.ToUpper(method),
+		Pattern:  pattern,
+		Handler:  h,
+		segments: parsePattern(pattern),
+		router:   rt,
+	}
+
+	rt.mutex.Lock()
+	rt.routes = append(rt.routes, route)
+	rt.mutex.Unlock()
+
+	return route
+}
+
+func (rt *Router) HandleFunc(method, pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return rt.Handle(method, pattern, http.HandlerFunc(f))
+}
+
+func (rt *Router) GET(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return rt.HandleFunc("GET", pattern, f)
+}
+
+func (rt *Router) POST(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return rt.HandleFunc("POST", pattern, f)
+}
+
+func (rt *Router) PUT(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return rt.HandleFunc("PUT", pattern, f)
+}
+
+func (rt *Router) DELETE(pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return rt.HandleFunc("DELETE", pattern, f)
+}
+
+// Add middleware around every route of the router, outside the routes' own.
+func (rt *Router) Use(middlewares ...middleware.Middleware) *Router {
+	rt.mutex.Lock()
+	rt.middlewares = append(rt.middlewares, middlewares...)
+	rt.mutex.Unlock()
+
+	return rt
+}
+
+// Add middleware around this route only.
+func (r *Route) Use(middlewares ...middleware.Middleware) *Route {
+	r.router.mutex.Lock()
+	r.middlewares = append(r.middlewares, middlewares...)
+	r.router.mutex.Unlock()
+
+	return r
+}
+
+// Name the route for URLFor.
+func (r *Route) Named(name string) *Route {
+	r.router.mutex.Lock()
+	r.Name = name
+	r.router.named[name] = r
+	r.router.mutex.Unlock()
+
+	return r
+}
+
+// Restrict the route to requests for hosts matching pattern, made of
+// literal labels and "{name}" labels matching one label each, e.g.
+// "admin.example.com" or "{tenant}.example.com".  The labels captured are
+// parameters like those of the path.  Hosts are compared without case and
+// without their port unless the pattern has one.
+func (r *Route) Host(pattern string) *Route {
+	r.router.mutex.Lock()
+	r.HostPattern = pattern
+	r.host = parseHost(pattern)
+	r.router.mutex.Unlock()
+
+	return r
+}
+
+func parseHost(pattern string) []segment {
+	labels := []segment{}
+	for _, s := range strings.Split(strings.ToLower(pattern), ".") {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			labels = append(labels, segment{param: s[1 : len(s)-1]})
+		} else {
+			labels = append(labels, segment{literal: s})
+		}
+	}
+
+	return labels
+}
+
+// Capture the parameters of the host pattern from host into params, and
+// report whether it matches.
+func (r *Route) matchHost(host string, params map[string]string) bool {
+	if !strings.Contains(r.HostPattern, ":") {
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+	}
+
+	labels := strings.Split(strings.TrimSuffix(strings.ToLower(host), "."), ".")
+	if len(labels) != len(r.host) {
+		return false
+	}
+
+	for i, seg := range r.host {
+		if seg.param != "" {
+			if labels[i] == "" {
+				return false
+			}
+
+			params[seg.param] = labels[i]
+		} else if seg.literal != labels[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Return the named parameters captured from the host and path, or nil.
+func (r *Route) match(host string, parts []string) map[string]string {
+	params := map[string]string{}
+	if r.host != nil && !r.matchHost(host, params) {
+		return nil
+	}
+
+	for i, seg := range r.segments {
+		if seg.wildcard {
+			params[seg.param] = strings.Join(parts[i:], "/")
+			return params
+		}
+
+		if i >= len(parts) {
+			return nil
+		}
+
+		if seg.param != "" {
+			params[seg.param] = parts[i]
+		} else if seg.literal != parts[i] {
+			return nil
+		}
+	}
+
+	if len(parts) != len(r.segments) {
+		return nil
+	}
+
+	return params
+}
+
+func (r *Route) allows(method string) bool {
+	return r.Method == "" || r.Method == method || (method == "HEAD" && r.Method == "GET")
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	routed := debug.Start(req.Context(), "routing")
+	parts := splitPath(req.URL.Path)
+	allowed := []string{}
+
+	rt.mutex.RLock()
+	routes := rt.routes
+	middlewares := rt.middlewares
+	rt.mutex.RUnlock()
+
+	for _, route := range routes {
+		params := route.match(req.Host, parts)
+		if params == nil {
+			continue
+		}
+
+		if !route.allows(req.Method) {
+			allowed = append(allowed, route.Method)
+			continue
+		}
+
+		rt.mutex.RLock()
+		chain := append(append(append([]middleware.Middleware{}, middlewares...), route.group.chain()...), route.middlewares...)
+		rt.mutex.RUnlock()
+
+		if slot, ok := req.Context().Value(slotKey{}).(*routeSlot); ok {
+			slot.route = route
+		}
+
+		ctx := context.WithValue(req.Context(), paramsKey{}, params)
+		ctx = context.WithValue(ctx, routeKey{}, route)
+		routed()
+		middleware.Wrap(route.Handler, chain...).ServeHTTP(w, req.WithContext(ctx))
+
+		return
+	}
+
+	routed()
+	if len(allowed) > 0 {
+		if rt.MethodNotAllowed != nil {
+			rt.MethodNotAllowed.ServeHTTP(w, req)
+			return
+		}
+
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if rt.NotFound != nil {
+		rt.NotFound.ServeHTTP(w, req)
+		return
+	}
+
+	http.NotFound(w, req)
+}
+
+// Return the parameters captured from the request path by the router.
+func Params(r *http.Request) map[string]string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params
+}
+
+func Param(r *http.Request, name string) string {
+	return Params(r)[name]
+}
+
+// Return the route that matched r, or nil.  A middleware wrapping the
+// router, which sees the request before it is routed, must pass the
+// request of Track on to the router to read it afterwards.
+func CurrentRoute(r *http.Request) *Route {
+	if route, ok := r.Context().Value(routeKey{}).(*Route); ok {
+		return route
+	}
+
+	if slot, ok := r.Context().Value(slotKey{}).(*routeSlot); ok {
+		return slot.route
+	}
+
+	return nil
+}
+
+// Return r with a place for the router to record the route it matches,
+// so that CurrentRoute works on it once the router has served it, e.g. to
+// label metrics by route pattern.
+func Track(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), slotKey{}, &routeSlot{}))
+}
+
+// Build the path of a named route from name/value pairs, e.g.
+// URLFor("user.show", "id", 42).  Pairs that are not parameters of the
+// route are added as the query string.  The URL of a route with a host
+// pattern is scheme-relative, e.g. "//acme.example.com/users/42".
+func (rt *Router) URLFor(name string, pairs ...interface{}) (string, error) {
+	rt.mutex.RLock()
+	route, ok := rt.named[name]
+	rt.mutex.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("router: no route named %q", name)
+	}
+
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("router: odd number of URLFor arguments for %q", name)
+	}
+
+	values := map[string]string{}
+	for i := 0; i < len(pairs); i += 2 {
+		values[fmt.Sprint(pairs[i])] = fmt.Sprint(pairs[i+1])
+	}
+
+	path := ""
+	for _, seg := range route.segments {
+		if seg.param == "" {
+			path += "/" + seg.literal
+			continue
+		}
+
+		value, ok := values[seg.param]
+		if !ok {
+			return "", fmt.Errorf("router: missing parameter %q for %q", seg.param, name)
+		}
+
+		delete(values, seg.param)
+		if seg.wildcard {
+			for _, part := range strings.Split(value, "/") {
+				path += "/" + url.PathEscape(part)
+			}
+		} else {
+			path += "/" + url.PathEscape(value)
+		}
+	}
+
+	if path == "" {
+		path = "/"
+	}
+
+	if route.host != nil {
+		labels := []string{}
+		for _, seg := range route.host {
+			if seg.param == "" {
+				labels = append(labels, seg.literal)
+				continue
+			}
+
+			value, ok := values[seg.param]
+			if !ok {
+				return "", fmt.Errorf("router: missing parameter %q for %q", seg.param, name)
+			}
+
+			delete(values, seg.param)
+			labels = append(labels, value)
+		}
+
+		path = "//" + strings.Join(labels, ".") + path
+	}
+
+	if len(values) > 0 {
+		query := url.Values{}
+		for k, v := range values {
+			query.Set(k, v)
+		}
+
+		path += "?" + query.Encode()
+	}
+
+	return path, nil
+}
+
+var Default = New()
+
+// Return a group of Default, see Router.Group.
+func Group(prefix string, middlewares ...middleware.Middleware) *RouteGroup {
+	return Default.Group(prefix, middlewares...)
+}
+
+func Handle(method, pattern string, h http.Handler) *Route {
+	return Default.Handle(method, pattern, h)
+}
+
+func HandleFunc(method, pattern string, f func(http.ResponseWriter, *http.Request)) *Route {
+	return Default.HandleFunc(method, pattern, f)
+}
+
+func URLFor(name string, pairs ...interface{}) (string, error) {
+	return Default.URLFor(name, pairs...)
+}
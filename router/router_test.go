@@ -0,0 +1,232 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Return a handler writing its name and the parameters of the request.
+func echo(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name))
+		for _, key := range []string{"id", "post", "path", "tenant"} {
+			if value, ok := Params(r)[key]; ok {
+				w.Write([]byte(" " + key + "=" + value))
+			}
+		}
+	}
+}
+
+func serve(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+
+	return w
+}
+
+func TestRouting(t *testing.T) {
+	rt := New()
+	rt.GET("/", echo("index"))
+	rt.GET("/users/new", echo("new"))
+	rt.GET("/users/:id", echo("show"))
+	rt.PUT("/users/:id", echo("update"))
+	rt.GET("/users/:id/posts/:post", echo("post"))
+	rt.GET("/files/*path", echo("files"))
+	rt.HandleFunc("", "/any", echo("any"))
+
+	cases := []struct {
+		method, target, want string
+	}{
+		{"GET", "/", "index"},
+		{"GET", "/users/new", "new"},
+		{"GET", "/users/42", "show id=42"},
+		{"GET", "/users/42/", "show id=42"},
+		{"HEAD", "/users/42", "show id=42"},
+		{"PUT", "/users/42", "update id=42"},
+		{"GET", "/users/42/posts/7", "post id=42 post=7"},
+		{"GET", "/files/a/b/c.txt", "files path=a/b/c.txt"},
+		{"GET", "/files", "files path="},
+		{"DELETE", "/any", "any"},
+	}
+
+	for _, c := range cases {
+		if w := serve(rt, c.method, c.target); w.Code != 200 || w.Body.String() != c.want {
+			t.Errorf("%s %s = %d %q, want %q", c.method, c.target, w.Code, w.Body.String(), c.want)
+		}
+	}
+}
+
+func TestNotFoundAndMethodNotAllowed(t *testing.T) {
+	rt := New()
+	rt.GET("/users/:id", echo("show"))
+	rt.DELETE("/users/:id", echo("delete"))
+
+	if w := serve(rt, "GET", "/users"); w.Code != 404 {
+		t.Errorf("GET /users = %d, want 404", w.Code)
+	}
+
+	if w := serve(rt, "GET", "/users/1/extra"); w.Code != 404 {
+		t.Errorf("GET /users/1/extra = %d, want 404", w.Code)
+	}
+
+	w := serve(rt, "POST", "/users/1")
+	if w.Code != 405 || w.Header().Get("Allow") != "DELETE, GET" {
+		t.Errorf("POST /users/1 = %d, Allow %q", w.Code, w.Header().Get("Allow"))
+	}
+
+	rt.NotFound = echo("custom 404")
+	rt.MethodNotAllowed = echo("custom 405")
+	if w := serve(rt, "GET", "/nope"); w.Body.String() != "custom 404" {
+		t.Errorf("NotFound not used: %q", w.Body.String())
+	}
+
+	if w := serve(rt, "POST", "/users/1"); w.Body.String() != "custom 405" {
+		t.Errorf("MethodNotAllowed not used: %q", w.Body.String())
+	}
+}
+
+func TestFirstRouteWins(t *testing.T) {
+	rt := New()
+	rt.GET("/users/:id", echo("param"))
+	rt.GET("/users/me", echo("literal"))
+
+	if w := serve(rt, "GET", "/users/me"); w.Body.String() != "param id=me" {
+		t.Errorf("GET /users/me = %q, want the first route", w.Body.String())
+	}
+}
+
+func TestWildcardMustBeLast(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a wildcard before the last segment")
+		}
+	}()
+
+	New().GET("/files/*path/edit", echo("files"))
+}
+
+func TestURLFor(t *testing.T) {
+	rt := New()
+	rt.GET("/", echo("index")).Named("index")
+	rt.GET("/users/:id", echo("show")).Named("user.show")
+	rt.GET("/files/*path", echo("files")).Named("files")
+
+	cases := []struct {
+		name  string
+		pairs []interface{}
+		want  string
+	}{
+		{"index", nil, "/"},
+		{"user.show", []interface{}{"id", 42}, "/users/42"},
+		{"user.show", []interface{}{"id", "a b/c", "tab", "posts"}, "/users/a%20b%2Fc?tab=posts"},
+		{"files", []interface{}{"path", "docs/read me.txt"}, "/files/docs/read%20me.txt"},
+	}
+
+	for _, c := range cases {
+		if got, err := rt.URLFor(c.name, c.pairs...); err != nil || got != c.want {
+			t.Errorf("URLFor(%s, %v) = %q, %v, want %q", c.name, c.pairs, got, err, c.want)
+		}
+	}
+
+	for _, bad := range [][]interface{}{{"missing"}, {"user.show"}, {"user.show", "id"}} {
+		if _, err := rt.URLFor(bad[0].(string), bad[1:]...); err == nil {
+			t.Errorf("no error for URLFor%v", bad)
+		}
+	}
+}
+
+func TestCurrentRoute(t *testing.T) {
+	rt := New()
+	var inside *Route
+	route := rt.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		inside = CurrentRoute(r)
+	})
+
+	r := Track(httptest.NewRequest("GET", "/users/1", nil))
+	rt.ServeHTTP(httptest.NewRecorder(), r)
+	if inside != route || CurrentRoute(r) != route {
+		t.Errorf("CurrentRoute %v inside, %v after, want %v", inside, CurrentRoute(r), route)
+	}
+
+	if CurrentRoute(httptest.NewRequest("GET", "/", nil)) != nil {
+		t.Error("CurrentRoute of an unrouted request")
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	trace := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(name + " "))
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	rt := New()
+	rt.Use(trace("router"))
+	rt.GET("/a", echo("a")).Use(trace("route"))
+	rt.GET("/b", echo("b"))
+	rt.Use(trace("late"))
+
+	if w := serve(rt, "GET", "/a"); w.Body.String() != "router late route a" {
+		t.Errorf("GET /a = %q", w.Body.String())
+	}
+
+	if w := serve(rt, "GET", "/b"); w.Body.String() != "router late b" {
+		t.Errorf("GET /b = %q", w.Body.String())
+	}
+
+	if w := serve(rt, "GET", "/c"); w.Body.String() != "404 page not found\n" {
+		t.Errorf("GET /c = %q, want no middleware on unrouted requests", w.Body.String())
+	}
+}
+
+func TestHostRouting(t *testing.T) {
+	rt := New()
+	rt.GET("/", echo("admin")).Host("admin.example.com")
+	rt.GET("/users/:id", echo("tenant")).Host("{tenant}.example.com").Named("tenant.user")
+	rt.GET("/", echo("local")).Host("localhost:8080")
+	rt.GET("/", echo("any"))
+
+	cases := []struct {
+		host, target, want string
+	}{
+		{"admin.example.com", "/", "admin"},
+		{"ADMIN.Example.com:443", "/", "admin"},
+		{"admin.example.com.", "/", "admin"},
+		{"acme.example.com", "/users/7", "tenant id=7 tenant=acme"},
+		{"localhost:8080", "/", "local"},
+		{"localhost:9090", "/", "any"},
+		{"a.b.example.com", "/", "any"},
+		{"example.com", "/", "any"},
+	}
+
+	for _, c := range cases {
+		if got := serveHost(rt, c.host, c.target); got != c.want {
+			t.Errorf("%s%s = %q, want %q", c.host, c.target, got, c.want)
+		}
+	}
+
+	if got := serveHost(rt, ".example.com", "/users/7"); got != "404 page not found\n" {
+		t.Errorf("empty host label matched: %q", got)
+	}
+
+	if got, err := rt.URLFor("tenant.user", "tenant", "acme", "id", 7); err != nil || got != "//acme.example.com/users/7" {
+		t.Errorf("URLFor = %q, %v", got, err)
+	}
+
+	if _, err := rt.URLFor("tenant.user", "id", 7); err == nil {
+		t.Error("no error without the host parameter")
+	}
+}
+
+func serveHost(h http.Handler, host, target string) string {
+	r := httptest.NewRequest("GET", target, nil)
+	r.Host = host
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w.Body.String()
+}
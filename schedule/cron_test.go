@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func at(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+func TestCronNext(t *testing.T) {
+	cases := []struct {
+		expr, from, want string
+	}{
+		{"* * * * *", "2024-01-10 09:30", "2024-01-10 09:31"},
+		{"*/15 * * * *", "2024-01-10 09:30", "2024-01-10 09:45"},
+		{"5/20 * * * *", "2024-01-10 09:30", "2024-01-10 09:45"},
+		{"0 9-17 * * mon-fri", "2024-01-12 17:00", "2024-01-15 09:00"},
+		{"30 2 1 * *", "2024-01-10 09:30", "2024-02-01 02:30"},
+		{"0 0 29 feb *", "2024-03-01 00:00", "2028-02-29 00:00"},
+		{"0 0 * * 7", "2024-01-10 09:30", "2024-01-14 00:00"},
+		{"0 12 1,15 * *", "2024-01-10 09:30", "2024-01-15 12:00"},
+		{"@monthly", "2024-12-31 23:59", "2025-01-01 00:00"},
+		{"@hourly", "2024-01-10 09:30", "2024-01-10 10:00"},
+		{"0 0 1 JAN *", "2024-01-10 09:30", "2025-01-01 00:00"},
+		// Either the day of the month or of the week, as in cron.
+		{"0 0 13 * fri", "2024-01-10 09:30", "2024-01-12 00:00"},
+	}
+
+	for _, c := range cases {
+		s, err := ParseCron(c.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", c.expr, err)
+			continue
+		}
+
+		if got := s.Next(at(c.from)); !got.Equal(at(c.want)) {
+			t.Errorf("%q after %s = %v, want %s", c.expr, c.from, got, c.want)
+		}
+	}
+}
+
+func TestCronNever(t *testing.T) {
+	s, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next := s.Next(at("2024-01-01 00:00")); !next.IsZero() {
+		t.Errorf("February 30th runs at %v", next)
+	}
+}
+
+func TestCronEvery(t *testing.T) {
+	s, err := ParseCron("@every 90s")
+	if err != nil || s != Interval(90*time.Second) {
+		t.Fatalf("ParseCron = %v, %v", s, err)
+	}
+
+	if next := s.Next(at("2024-01-01 00:00")); !next.Equal(at("2024-01-01 00:01").Add(30 * time.Second)) {
+		t.Errorf("Next = %v", next)
+	}
+}
+
+func TestCronErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8",
+		"5-1 * * * *", "*/0 * * * *", "*/x * * * *", "a * * * *", "1-b * * * *", "@every", "@every -1m", "@every soon"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("no error for %q", expr)
+		}
+	}
+}
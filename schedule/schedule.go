@@ -0,0 +1,242 @@
+package schedule
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	mathrand "math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// A Task is a function run on a Schedule.  Its methods configure it and
+// must be called before Do.
+type Task struct {
+	scheduler    *Scheduler
+	schedule     Schedule
+	name         string
+	jitter       time.Duration
+	allowOverlap bool
+	fn           func(ctx context.Context)
+	mutex        sync.Mutex
+	running      bool
+}
+
+// Name the task in the log, after its schedule by default.
+func (t *Task) Name(name string) *Task {
+	t.name = name
+
+	return t
+}
+
+// Delay each run by a random duration up to d, so that the processes of
+// an application do not all run the task at the same instant.
+func (t *Task) Jitter(d time.Duration) *Task {
+	t.jitter = d
+
+	return t
+}
+
+// Start a run even if the previous one has not returned.  By default such
+// a run is skipped.
+func (t *Task) AllowOverlap() *Task {
+	t.allowOverlap = true
+
+	return t
+}
+
+// Run fn on the schedule of the task, once the scheduler is started.  fn's
+// context ends when the scheduler stops.
+func (t *Task) Do(fn func(ctx context.Context)) *Task {
+	t.fn = fn
+	t.scheduler.add(t)
+
+	return t
+}
+
+// Run the task in its own goroutine unless it is still running and
+// overlaps are not allowed.
+func (t *Task) fire(ctx context.Context, wg *sync.WaitGroup) {
+	t.mutex.Lock()
+	if t.running && !t.allowOverlap {
+		t.mutex.Unlock()
+		log.Warn("<schedule.Task.fire> ", t.name, ": skipped, the previous run has not finished")
+		return
+	}
+
+	t.running = true
+	t.mutex.Unlock()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			t.mutex.Lock()
+			t.running = false
+			t.mutex.Unlock()
+		}()
+
+		t.run(ctx)
+	}()
+}
+
+func (t *Task) run(ctx context.Context) {
+	start := time.Now()
+	defer func() {
+		if err := recover(); err != nil {
+			log.WithFields(log.Fields{"stack": string(debug.Stack())}).Error("<schedule.Task.run> ", t.name, ": panic: ", err)
+		}
+	}()
+
+	t.fn(ctx)
+	log.Debug("<schedule.Task.run> ", t.name, ": done in ", time.Since(start))
+}
+
+// Wait for each run time of the task and fire it, until ctx ends.
+func (t *Task) loop(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	now := time.Now()
+	for {
+		next := t.schedule.Next(now)
+		if next.IsZero() {
+			log.Error("<schedule.Task.loop> ", t.name, ": the schedule never runs")
+			return
+		}
+
+		at := next
+		if t.jitter > 0 {
+			at = at.Add(time.Duration(mathrand.Int63n(int64(t.jitter))))
+		}
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		t.fire(ctx, wg)
+		now = next
+		if late := time.Now(); late.Sub(now) > time.Minute {
+			// The process was suspended or the clock jumped: do not run
+			// every missed time at once.
+			now = late
+		}
+	}
+}
+
+// A Scheduler runs tasks.  Tasks may be added before or after Start.
+type Scheduler struct {
+	mutex  sync.Mutex
+	tasks  []*Task
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// The Scheduler of the package-level functions.
+var Default = New()
+
+func (s *Scheduler) add(t *Task) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.tasks = append(s.tasks, t)
+	if s.ctx != nil {
+		s.wg.Add(1)
+		go t.loop(s.ctx, &s.wg)
+	}
+}
+
+// Return a task run on schedule.
+func (s *Scheduler) On(schedule Schedule) *Task {
+	return &Task{scheduler: s, schedule: schedule, name: "task"}
+}
+
+// Return a task run every interval, a duration such as "5m" or "1h30m".
+// It panics if interval is not one, as it is meant to be a constant.
+func (s *Scheduler) Every(interval string) *Task {
+	d, err := time.ParseDuration(interval)
+	if err != nil || d <= 0 {
+		panic("schedule: invalid interval " + interval)
+	}
+
+	return s.On(Interval(d)).Name("every " + interval)
+}
+
+// Return a task run on a cron expression, see ParseCron.  It panics if
+// expr is invalid, as it is meant to be a constant.
+func (s *Scheduler) Cron(expr string) *Task {
+	schedule, err := ParseCron(expr)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return s.On(schedule).Name(expr)
+}
+
+// Start running the tasks.
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.ctx != nil {
+		return
+	}
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	for _, t := range s.tasks {
+		s.wg.Add(1)
+		go t.loop(s.ctx, &s.wg)
+	}
+}
+
+// Stop running the tasks and wait for the running ones to return, or for
+// ctx to end.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.ctx, s.cancel = nil, nil
+	s.mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func Every(interval string) *Task {
+	return Default.Every(interval)
+}
+
+func Cron(expr string) *Task {
+	return Default.Cron(expr)
+}
+
+func On(schedule Schedule) *Task {
+	return Default.On(schedule)
+}
+
+func Start() {
+	Default.Start()
+}
+
+func Stop(ctx context.Context) error {
+	return Default.Stop(ctx)
+}
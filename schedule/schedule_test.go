@@ -0,0 +1,150 @@
+package schedule
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+func stop(t *testing.T, s *Scheduler) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	s := New()
+	var runs int32
+	s.Every("10ms").Do(func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+	})
+
+	s.Start()
+	s.Start()
+	time.Sleep(105 * time.Millisecond)
+	stop(t, s)
+
+	n := atomic.LoadInt32(&runs)
+	if n < 3 || n > 11 {
+		t.Errorf("ran %d times in 105ms every 10ms", n)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if atomic.LoadInt32(&runs) != n {
+		t.Error("ran after Stop")
+	}
+}
+
+func TestAddAfterStart(t *testing.T) {
+	s := New()
+	s.Start()
+	defer stop(t, s)
+
+	ran := make(chan struct{}, 10)
+	s.On(Interval(5 * time.Millisecond)).Do(func(ctx context.Context) {
+		ran <- struct{}{}
+	})
+
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task added after Start not run")
+	}
+}
+
+func TestOverlap(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		s := New()
+		var running, most int32
+		task := s.Every("5ms").Name("slow")
+		if allow {
+			task.AllowOverlap()
+		}
+
+		task.Do(func(ctx context.Context) {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&most)
+				if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(30 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+		})
+
+		s.Start()
+		time.Sleep(80 * time.Millisecond)
+		stop(t, s)
+
+		if most := atomic.LoadInt32(&most); allow != (most > 1) {
+			t.Errorf("AllowOverlap %v: %d runs at once", allow, most)
+		}
+	}
+}
+
+func TestPanicRecovered(t *testing.T) {
+	s := New()
+	var runs int32
+	s.Every("5ms").Do(func(ctx context.Context) {
+		atomic.AddInt32(&runs, 1)
+		panic("boom")
+	})
+
+	s.Start()
+	time.Sleep(50 * time.Millisecond)
+	stop(t, s)
+
+	if atomic.LoadInt32(&runs) < 2 {
+		t.Error("task not run again after a panic")
+	}
+}
+
+func TestStopWaits(t *testing.T) {
+	s := New()
+	started := make(chan struct{})
+	var finished int32
+	s.Every("5ms").Do(func(ctx context.Context) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+
+		<-ctx.Done()
+		atomic.StoreInt32(&finished, 1)
+	})
+
+	s.Start()
+	<-started
+	stop(t, s)
+	if atomic.LoadInt32(&finished) != 1 {
+		t.Error("Stop returned before the running task")
+	}
+}
+
+func TestInvalidPanics(t *testing.T) {
+	for _, f := range []func(){
+		func() { New().Every("soon") },
+		func() { New().Every("-1s") },
+		func() { New().Cron("61 * * * *") },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic")
+				}
+			}()
+
+			f()
+		}()
+	}
+}
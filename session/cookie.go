@@ -0,0 +1,110 @@
+package session
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Browsers drop cookies larger than this.
+const maxCookieSize = 4096
+
+var ErrCookieTooLarge = errors.New("session: values too large for a cookie")
+
+type cookiePayload struct {
+	Values    map[string]interface{} `json:"v"`
+	ExpiresAt int64                  `json:"e,omitempty"`
+}
+
+// A Store that keeps the values in the cookie itself, as JSON signed with
+// HMAC-SHA256.  It signs with the first key and accepts any of them, so keys
+// can be rotated.  The values are readable by the client, and numbers come
+// back as float64.
+type CookieStore struct {
+	keys [][]byte
+}
+
+func NewCookieStore(keys ...[]byte) *CookieStore {
+	if len(keys) == 0 {
+		panic("session: CookieStore needs a key")
+	}
+
+	return &CookieStore{keys: keys}
+}
+
+func sign(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *CookieStore) Load(value string) (map[string]interface{}, error) {
+	i := strings.LastIndex(value, ".")
+	if i < 0 {
+		return nil, nil
+	}
+
+	payload, sig := value[:i], value[i+1:]
+	valid := false
+	for _, key := range s.keys {
+		if hmac.Equal([]byte(sign(key, payload)), []byte(sig)) {
+			valid = true
+			break
+		}
+	}
+
+	if !valid {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, nil
+	}
+
+	var p cookiePayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, nil
+	}
+
+	if p.ExpiresAt > 0 && time.Now().Unix() > p.ExpiresAt {
+		return nil, nil
+	}
+
+	if p.Values == nil {
+		p.Values = map[string]interface{}{}
+	}
+
+	return p.Values, nil
+}
+
+// Encode and sign the values.  The id is not used.
+func (s *CookieStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	p := cookiePayload{Values: values}
+	if maxAge > 0 {
+		p.ExpiresAt = time.Now().Add(maxAge).Unix()
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	value := payload + "." + sign(s.keys[0], payload)
+	if len(value) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+
+	return value, nil
+}
+
+// There is nothing to delete on the server; Manager expires the cookie.
+func (s *CookieStore) Delete(id string) error {
+	return nil
+}
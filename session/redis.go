@@ -0,0 +1,59 @@
+package session
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// The few commands RedisStore needs, so any Redis client can be adapted to
+// it.  Get returns nil, without error, for a missing key.
+type RedisClient interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Del(key string) error
+}
+
+// A Store that keeps each session as JSON under prefix+id, expired by Redis
+// itself.  Numbers come back as float64.
+type RedisStore struct {
+	client RedisClient
+	prefix string
+}
+
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStore) Load(id string) (map[string]interface{}, error) {
+	data, err := s.client.Get(s.prefix + id)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, nil
+	}
+
+	return values, nil
+}
+
+func (s *RedisStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.client.Set(s.prefix+id, data, maxAge); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *RedisStore) Delete(id string) error {
+	return s.client.Del(s.prefix + id)
+}
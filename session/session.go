@@ -0,0 +1,320 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"golanger.com/framework/log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type contextKey struct{}
+
+// A Session holds the values of one client across requests.  It is safe
+// for concurrent use.
+type Session struct {
+	mutex       sync.RWMutex
+	id          string
+	oldID       string
+	values      map[string]interface{}
+	isNew       bool
+	changed     bool
+	regenerated bool
+	destroyed   bool
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("session: no random source: " + err.Error())
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func (s *Session) ID() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.id
+}
+
+// Report whether the session was created by this request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+func (s *Session) Get(key string) interface{} {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.values[key]
+}
+
+func (s *Session) GetString(key string) string {
+	str, _ := s.Get(key).(string)
+	return str
+}
+
+// Return an integer value, also accepting the float64 that stores
+// serializing to JSON decode numbers as.
+func (s *Session) GetInt(key string) int {
+	switch n := s.Get(key).(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	}
+
+	return 0
+}
+
+func (s *Session) GetBool(key string) bool {
+	b, _ := s.Get(key).(bool)
+	return b
+}
+
+func (s *Session) Set(key string, value interface{}) {
+	s.mutex.Lock()
+	s.values[key] = value
+	s.changed = true
+	s.mutex.Unlock()
+}
+
+func (s *Session) Delete(key string) {
+	s.mutex.Lock()
+	delete(s.values, key)
+	s.changed = true
+	s.mutex.Unlock()
+}
+
+// Return a copy of all of the values.
+func (s *Session) Values() map[string]interface{} {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	values := make(map[string]interface{}, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+
+	return values
+}
+
+// Move the session to a new id, keeping its values.  Call it whenever the
+// privilege level changes, such as on login, to prevent session fixation.
+func (s *Session) Regenerate() {
+	s.mutex.Lock()
+	if !s.regenerated {
+		s.oldID = s.id
+	}
+
+	s.id = newID()
+	s.regenerated = true
+	s.changed = true
+	s.mutex.Unlock()
+}
+
+// Remove every value and the session itself when it is saved.
+func (s *Session) Destroy() {
+	s.mutex.Lock()
+	s.values = map[string]interface{}{}
+	s.destroyed = true
+	s.mutex.Unlock()
+}
+
+// A Manager loads sessions from a Store by the id kept in a cookie, and
+// saves them back.
+type Manager struct {
+	CookieName string
+	Path       string
+	Domain     string
+	Secure     bool
+	HttpOnly   bool
+	MaxAge     time.Duration
+	store      Store
+}
+
+func NewManager(store Store, cookieName string, maxAge time.Duration) *Manager {
+	return &Manager{
+		CookieName: cookieName,
+		Path:       "/",
+		HttpOnly:   true,
+		MaxAge:     maxAge,
+		store:      store,
+	}
+}
+
+// Return the session of the request, or a new empty one.
+func (m *Manager) Start(r *http.Request) (*Session, error) {
+	if ck, err := r.Cookie(m.CookieName); err == nil && ck.Value != "" {
+		values, err := m.store.Load(ck.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		if values != nil {
+			return &Session{id: ck.Value, values: values}, nil
+		}
+	}
+
+	return &Session{id: newID(), values: map[string]interface{}{}, isNew: true}, nil
+}
+
+// Store the session and set its cookie.  It must be called before the
+// response is written.  A new session that was never changed is not stored.
+func (m *Manager) Save(w http.ResponseWriter, s *Session) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.destroyed {
+		if !s.isNew {
+			if err := m.store.Delete(s.id); err != nil {
+				return err
+			}
+		}
+
+		if s.oldID != "" {
+			m.store.Delete(s.oldID)
+		}
+
+		m.setCookie(w, "", -1)
+
+		return nil
+	}
+
+	if s.isNew && !s.changed {
+		return nil
+	}
+
+	if s.oldID != "" {
+		if err := m.store.Delete(s.oldID); err != nil {
+			return err
+		}
+
+		s.oldID = ""
+	}
+
+	value, err := m.store.Save(s.id, s.values, m.MaxAge)
+	if err != nil {
+		return err
+	}
+
+	m.setCookie(w, value, int(m.MaxAge/time.Second))
+
+	return nil
+}
+
+func (m *Manager) setCookie(w http.ResponseWriter, value string, maxAge int) {
+	ck := &http.Cookie{
+		Name:     m.CookieName,
+		Value:    value,
+		Path:     m.Path,
+		Domain:   m.Domain,
+		Secure:   m.Secure,
+		HttpOnly: m.HttpOnly,
+		MaxAge:   maxAge,
+	}
+
+	if maxAge > 0 {
+		ck.Expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	}
+
+	http.SetCookie(w, ck)
+}
+
+// Return the session that Middleware attached to the request, or nil.
+func FromRequest(r *http.Request) *Session {
+	s, _ := r.Context().Value(contextKey{}).(*Session)
+	return s
+}
+
+// Return a middleware that starts the session of each request, makes it
+// available through FromRequest, and saves it just before the response
+// headers are written.  If the store fails to save it, the error is
+// logged and a 500 Internal Server Error sent instead of the response,
+// as the client would otherwise take a lost write, such as the new ID of
+// Regenerate on login, for a success.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, err := m.Start(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		sw := &saveWriter{ResponseWriter: w, request: r, manager: m, session: s}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), contextKey{}, s)))
+		sw.save()
+	})
+}
+
+// A ResponseWriter that saves the session once, before the headers go out,
+// and discards the response once saving has failed.
+type saveWriter struct {
+	http.ResponseWriter
+	request *http.Request
+	manager *Manager
+	session *Session
+	saved   bool
+	failed  bool
+}
+
+func (w *saveWriter) save() {
+	if w.saved {
+		return
+	}
+
+	w.saved = true
+	if err := w.manager.Save(w.ResponseWriter, w.session); err != nil {
+		log.FromRequest(w.request).Error("<session.Manager.Middleware> ", err)
+		w.failed = true
+		http.Error(w.ResponseWriter, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func (w *saveWriter) WriteHeader(code int) {
+	w.save()
+	if !w.failed {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *saveWriter) Write(b []byte) (int, error) {
+	w.save()
+	if w.failed {
+		return len(b), nil
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *saveWriter) Flush() {
+	w.save()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Save the session before the connection is taken over, such as by a
+// WebSocket upgrade, which can still send the cookie set on the header.
+func (w *saveWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.save()
+	if w.failed {
+		return nil, nil, errors.New("session: the session could not be saved")
+	}
+
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("session: ResponseWriter does not support Hijack")
+	}
+
+	return h.Hijack()
+}
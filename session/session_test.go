@@ -0,0 +1,161 @@
+package session
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// A Store that cannot save.
+type failingStore struct {
+	*MemoryStore
+}
+
+func (s failingStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	return "", errors.New("disk full")
+}
+
+// Serve one request through the middleware of m, setting a value in the
+// session, and return the response.
+func serve(m *Manager, r *http.Request) *httptest.ResponseRecorder {
+	h := m.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromRequest(r).Set("user", "joe")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("welcome"))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestMiddlewareSaves(t *testing.T) {
+	m := NewManager(NewMemoryStore(), "sid", time.Hour)
+	w := serve(m, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "welcome" {
+		t.Fatalf("response %d %q", w.Code, w.Body.String())
+	}
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "sid" || !cookies[0].HttpOnly {
+		t.Fatalf("cookies %v", cookies)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	s, err := m.Start(r)
+	if err != nil || s.IsNew() || s.GetString("user") != "joe" {
+		t.Errorf("session %+v, %v not loaded from the cookie", s, err)
+	}
+}
+
+func TestMiddlewareFailedSave(t *testing.T) {
+	m := NewManager(failingStore{NewMemoryStore()}, "sid", time.Hour)
+	w := serve(m, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want 500", w.Code)
+	}
+
+	if strings.Contains(w.Body.String(), "welcome") {
+		t.Errorf("body %q sent after the save failed", w.Body.String())
+	}
+
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("cookies %v set after the save failed", w.Result().Cookies())
+	}
+}
+
+func TestRegenerate(t *testing.T) {
+	store := NewMemoryStore()
+	m := NewManager(store, "sid", time.Hour)
+	s, _ := m.Start(httptest.NewRequest("GET", "/", nil))
+	s.Set("user", "joe")
+	m.Save(httptest.NewRecorder(), s)
+	old := s.ID()
+
+	s.Regenerate()
+	if s.ID() == old {
+		t.Fatal("Regenerate kept the id")
+	}
+
+	m.Save(httptest.NewRecorder(), s)
+	if values, _ := store.Load(old); values != nil {
+		t.Errorf("old session %v still stored", values)
+	}
+
+	if values, _ := store.Load(s.ID()); values["user"] != "joe" {
+		t.Errorf("new session %v lost its values", values)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	store := NewMemoryStore()
+	m := NewManager(store, "sid", time.Hour)
+	first := serve(m, httptest.NewRequest("GET", "/", nil))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(first.Result().Cookies()[0])
+	s, _ := m.Start(r)
+
+	s.Destroy()
+	w := httptest.NewRecorder()
+	m.Save(w, s)
+	if values, _ := store.Load(s.ID()); values != nil {
+		t.Errorf("destroyed session %v still stored", values)
+	}
+
+	if cookies := w.Result().Cookies(); len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Errorf("cookies %v, want the cookie expired", cookies)
+	}
+}
+
+func TestCookieStore(t *testing.T) {
+	s := NewCookieStore([]byte("new key"), []byte("old key"))
+	value, err := s.Save("", map[string]interface{}{"user": "joe"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if values, _ := s.Load(value); values["user"] != "joe" {
+		t.Errorf("values %v", values)
+	}
+
+	payload := value[:strings.LastIndex(value, ".")]
+	for _, forged := range []string{
+		payload + "." + sign([]byte("other key"), payload),
+		payload + "x." + sign([]byte("new key"), payload),
+		payload,
+		"",
+	} {
+		if values, _ := s.Load(forged); values != nil {
+			t.Errorf("forged cookie %q accepted: %v", forged, values)
+		}
+	}
+
+	rotated, _ := NewCookieStore([]byte("old key")).Save("", map[string]interface{}{"user": "ann"}, time.Hour)
+	if values, _ := s.Load(rotated); values["user"] != "ann" {
+		t.Errorf("cookie signed with an old key rejected: %v", values)
+	}
+
+	if _, err := s.Save("", map[string]interface{}{"big": strings.Repeat("x", maxCookieSize)}, 0); err != ErrCookieTooLarge {
+		t.Errorf("error %v, want ErrCookieTooLarge", err)
+	}
+}
+
+func TestMemoryStoreExpires(t *testing.T) {
+	s := NewMemoryStore()
+	s.Save("a", map[string]interface{}{"n": 1}, time.Hour)
+	s.Save("b", map[string]interface{}{"n": 2}, time.Nanosecond)
+	time.Sleep(time.Millisecond)
+
+	if values, _ := s.Load("a"); values["n"] != 1 {
+		t.Errorf("values %v", values)
+	}
+
+	if values, _ := s.Load("b"); values != nil {
+		t.Errorf("expired values %v loaded", values)
+	}
+}
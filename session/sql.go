@@ -0,0 +1,95 @@
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"golanger.com/framework/db"
+	"time"
+)
+
+// A Store that keeps each session as JSON in a table of a database:
+//
+//	CREATE TABLE sessions (id VARCHAR(64) PRIMARY KEY, data TEXT NOT NULL, expires_at BIGINT NOT NULL)
+//
+// expires_at is in Unix seconds, 0 for no expiry.  Numbers come back as
+// float64.
+type SQLStore struct {
+	db    *db.DB
+	table string
+}
+
+// Return a SQLStore on table, "sessions" if empty.
+func NewSQLStore(d *db.DB, table string) *SQLStore {
+	if table == "" {
+		table = "sessions"
+	}
+
+	return &SQLStore{db: d, table: table}
+}
+
+func expiresAt(maxAge time.Duration) int64 {
+	if t := expiry(maxAge); !t.IsZero() {
+		return t.Unix()
+	}
+
+	return 0
+}
+
+func (s *SQLStore) Load(id string) (map[string]interface{}, error) {
+	var data string
+	var exp int64
+	err := s.db.QueryRow(s.db.Rebind("SELECT data, expires_at FROM "+s.table+" WHERE id = ?"), id).Scan(&data, &exp)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if exp != 0 && time.Now().Unix() > exp {
+		s.Delete(id)
+		return nil, nil
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(data), &values); err != nil {
+		return nil, nil
+	}
+
+	return values, nil
+}
+
+func (s *SQLStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.db.InTx(func(tx *db.Tx) error {
+		if _, err := tx.Exec(tx.Rebind("DELETE FROM "+s.table+" WHERE id = ?"), id); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(tx.Rebind("INSERT INTO "+s.table+" (id, data, expires_at) VALUES (?, ?, ?)"), id, string(data), expiresAt(maxAge))
+
+		return err
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *SQLStore) Delete(id string) error {
+	_, err := s.db.Exec(s.db.Rebind("DELETE FROM "+s.table+" WHERE id = ?"), id)
+
+	return err
+}
+
+// Delete every expired session.
+func (s *SQLStore) GC() {
+	s.db.Exec(s.db.Rebind("DELETE FROM "+s.table+" WHERE expires_at <> 0 AND expires_at < ?"), time.Now().Unix())
+}
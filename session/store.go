@@ -0,0 +1,213 @@
+package session
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// A Store keeps session values between requests.  Load returns nil values,
+// without error, for an unknown or expired cookie value.  Save returns the
+// value to put in the cookie: the id itself for server-side stores.
+type Store interface {
+	Load(value string) (map[string]interface{}, error)
+	Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error)
+	Delete(id string) error
+}
+
+type memoryEntry struct {
+	values    map[string]interface{}
+	expiresAt time.Time
+}
+
+// A Store that keeps sessions in process memory.  Expired sessions are
+// dropped when loaded or by GC.
+type MemoryStore struct {
+	mutex    sync.Mutex
+	sessions map[string]*memoryEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: map[string]*memoryEntry{},
+	}
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		c[k] = v
+	}
+
+	return c
+}
+
+func expiry(maxAge time.Duration) time.Time {
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+
+	return time.Now().Add(maxAge)
+}
+
+func expired(expiresAt time.Time) bool {
+	return !expiresAt.IsZero() && time.Now().After(expiresAt)
+}
+
+func (s *MemoryStore) Load(id string) (map[string]interface{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+
+	if expired(entry.expiresAt) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+
+	return copyValues(entry.values), nil
+}
+
+func (s *MemoryStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	s.mutex.Lock()
+	s.sessions[id] = &memoryEntry{
+		values:    copyValues(values),
+		expiresAt: expiry(maxAge),
+	}
+	s.mutex.Unlock()
+
+	return id, nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mutex.Lock()
+	delete(s.sessions, id)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// Drop every expired session.
+func (s *MemoryStore) GC() {
+	s.mutex.Lock()
+	for id, entry := range s.sessions {
+		if expired(entry.expiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+	s.mutex.Unlock()
+}
+
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+type fileEntry struct {
+	Values    map[string]interface{} `json:"values"`
+	ExpiresAt time.Time              `json:"expires_at"`
+}
+
+// A Store that keeps each session as a JSON file in a directory.  Values
+// come back as JSON decodes them, so numbers are float64.
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, "sess_"+id)
+}
+
+func (s *FileStore) Load(id string) (map[string]interface{}, error) {
+	if !idPattern.MatchString(id) {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var entry fileEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, nil
+	}
+
+	if expired(entry.ExpiresAt) {
+		os.Remove(s.path(id))
+		return nil, nil
+	}
+
+	if entry.Values == nil {
+		entry.Values = map[string]interface{}{}
+	}
+
+	return entry.Values, nil
+}
+
+func (s *FileStore) Save(id string, values map[string]interface{}, maxAge time.Duration) (string, error) {
+	data, err := json.Marshal(fileEntry{Values: values, ExpiresAt: expiry(maxAge)})
+	if err != nil {
+		return "", err
+	}
+
+	tmp := s.path(id) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp, s.path(id)); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (s *FileStore) Delete(id string) error {
+	if !idPattern.MatchString(id) {
+		return nil
+	}
+
+	if err := os.Remove(s.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Remove the files of every expired session.
+func (s *FileStore) GC() {
+	matches, err := filepath.Glob(filepath.Join(s.dir, "sess_*"))
+	if err != nil {
+		return
+	}
+
+	for _, path := range matches {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var entry fileEntry
+		if json.Unmarshal(data, &entry) == nil && expired(entry.ExpiresAt) {
+			os.Remove(path)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package static
+
+import (
+	"fmt"
+	"html/template"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Options struct {
+	// The Cache-Control header of every file, "public, max-age=3600" by
+	// default.  Fingerprinted assets can use "public, max-age=31536000,
+	// immutable".
+	CacheControl string
+
+	// The file served for a directory, "index.html" by default.
+	Index string
+
+	// List the files of a directory that has no index file, instead of
+	// answering 404.
+	Listing bool
+}
+
+// Serves the files under a directory.  Files and directories whose name
+// starts with "." are never served.
+type Handler struct {
+	dir  string
+	opts Options
+}
+
+// Return a Handler serving dir.  Requests are answered with ETag,
+// Last-Modified and Cache-Control headers and honor conditional and range
+// requests.  When the client accepts them, a file.br or file.gz next to
+// the file is served in its place with the matching Content-Encoding.
+func New(dir string, opts ...Options) *Handler {
+	h := &Handler{dir: dir}
+	if len(opts) > 0 {
+		h.opts = opts[0]
+	}
+
+	if h.opts.CacheControl == "" {
+		h.opts.CacheControl = "public, max-age=3600"
+	}
+
+	if h.opts.Index == "" {
+		h.opts.Index = "index.html"
+	}
+
+	return h
+}
+
+var encodings = []struct {
+	name, ext string
+}{{"br", ".br"}, {"gzip", ".gz"}}
+
+// Report whether an Accept-Encoding header accepts the encoding with a
+// non-zero quality.
+func accepts(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		name := strings.TrimSpace(fields[0])
+		if name != encoding && name != "*" {
+			continue
+		}
+
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil && q == 0 {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "HEAD" {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := path.Clean("/" + r.URL.Path)
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	file := filepath.Join(h.dir, filepath.FromSlash(name))
+	fi, err := os.Stat(file)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if fi.IsDir() {
+		if !strings.HasSuffix(r.URL.Path, "/") {
+			// Relative, as the path may have had a prefix stripped.
+			location := path.Base(r.URL.Path) + "/"
+			if r.URL.RawQuery != "" {
+				location += "?" + r.URL.RawQuery
+			}
+
+			w.Header().Set("Location", location)
+			w.WriteHeader(http.StatusMovedPermanently)
+			return
+		}
+
+		index := filepath.Join(file, h.opts.Index)
+		if ifi, err := os.Stat(index); err == nil && !ifi.IsDir() {
+			file, fi = index, ifi
+		} else if h.opts.Listing {
+			h.list(w, file)
+			return
+		} else {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	h.serveFile(w, r, file, fi)
+}
+
+func (h *Handler) serveFile(w http.ResponseWriter, r *http.Request, file string, fi os.FileInfo) {
+	header := w.Header()
+	served, encoding := file, ""
+	for _, enc := range encodings {
+		efi, err := os.Stat(file + enc.ext)
+		if err != nil || efi.IsDir() {
+			continue
+		}
+
+		header.Set("Vary", "Accept-Encoding")
+		if encoding == "" && accepts(r.Header.Get("Accept-Encoding"), enc.name) {
+			served, encoding, fi = file+enc.ext, enc.name, efi
+		}
+	}
+
+	f, err := os.Open(served)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+
+	if encoding != "" {
+		ctype := mime.TypeByExtension(filepath.Ext(file))
+		if ctype == "" {
+			ctype = "application/octet-stream"
+		}
+
+		header.Set("Content-Type", ctype)
+		header.Set("Content-Encoding", encoding)
+	}
+
+	header.Set("Cache-Control", h.opts.CacheControl)
+	header.Set("ETag", fmt.Sprintf(`"%x-%x"`, fi.ModTime().UnixNano(), fi.Size()))
+	http.ServeContent(w, r, filepath.Base(file), fi.ModTime(), f)
+}
+
+var listing = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"></head>
+<body>
+<pre>
+{{range .}}<a href="{{.URL}}">{{.Name}}</a>
+{{end}}</pre>
+</body>
+</html>
+`))
+
+func (h *Handler) list(w http.ResponseWriter, dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	type item struct {
+		Name, URL string
+	}
+
+	items := []item{}
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if e.IsDir() {
+			name += "/"
+		}
+
+		items = append(items, item{name, (&url.URL{Path: name}).String()})
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	listing.Execute(w, items)
+}
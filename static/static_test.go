@@ -0,0 +1,134 @@
+package static
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Write files, by slash-separated path, to a new directory.
+func tree(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func get(h http.Handler, path string, header map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", path, nil)
+	for k, v := range header {
+		r.Header.Set(k, v)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestServe(t *testing.T) {
+	h := New(tree(t, map[string]string{
+		"app.css":          "body {}",
+		"docs/index.html":  "<h1>Docs</h1>",
+		".env":             "SECRET=1",
+		".git/config":      "[core]",
+		"empty/readme.txt": "hi",
+	}))
+
+	w := get(h, "/app.css", nil)
+	if w.Code != http.StatusOK || w.Body.String() != "body {}" || w.Header().Get("Cache-Control") != "public, max-age=3600" ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "text/css") || w.Header().Get("ETag") == "" {
+		t.Errorf("app.css: %d %v %q", w.Code, w.Header(), w.Body)
+	}
+
+	etag := w.Header().Get("ETag")
+	if w := get(h, "/app.css", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified {
+		t.Errorf("conditional request answered %d", w.Code)
+	}
+
+	if w := get(h, "/app.css", map[string]string{"Range": "bytes=0-3"}); w.Code != http.StatusPartialContent || w.Body.String() != "body" {
+		t.Errorf("range request answered %d %q", w.Code, w.Body)
+	}
+
+	if w := get(h, "/docs/", nil); w.Code != http.StatusOK || w.Body.String() != "<h1>Docs</h1>" {
+		t.Errorf("index answered %d %q", w.Code, w.Body)
+	}
+
+	if w := get(h, "/docs?a=1", nil); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "docs/?a=1" {
+		t.Errorf("directory without a slash answered %d %v", w.Code, w.Header())
+	}
+
+	for _, path := range []string{"/.env", "/.git/config", "/missing.js", "/empty/", "/../static_test.go"} {
+		if w := get(h, path, nil); w.Code != http.StatusNotFound {
+			t.Errorf("%s answered %d", path, w.Code)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/app.css", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("POST answered %d %v", w.Code, w.Header())
+	}
+}
+
+func TestPrecompressed(t *testing.T) {
+	h := New(tree(t, map[string]string{
+		"app.js":    "plain",
+		"app.js.gz": "gzipped",
+		"app.js.br": "brotli",
+		"other.js":  "plain",
+	}), Options{CacheControl: "public, max-age=31536000, immutable"})
+
+	cases := []struct {
+		accept, body, encoding string
+	}{
+		{"", "plain", ""},
+		{"gzip, deflate", "gzipped", "gzip"},
+		{"gzip, br", "brotli", "br"},
+		{"br;q=0, gzip;q=0.5", "gzipped", "gzip"},
+		{"*", "brotli", "br"},
+	}
+
+	for _, c := range cases {
+		w := get(h, "/app.js", map[string]string{"Accept-Encoding": c.accept})
+		if w.Body.String() != c.body || w.Header().Get("Content-Encoding") != c.encoding ||
+			w.Header().Get("Vary") != "Accept-Encoding" || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/javascript") {
+			t.Errorf("Accept-Encoding %q: %v %q", c.accept, w.Header(), w.Body)
+		}
+	}
+
+	w := get(h, "/other.js", map[string]string{"Accept-Encoding": "gzip"})
+	if w.Header().Get("Vary") != "" || w.Header().Get("Cache-Control") != "public, max-age=31536000, immutable" {
+		t.Errorf("other.js: %v", w.Header())
+	}
+}
+
+func TestListing(t *testing.T) {
+	dir := tree(t, map[string]string{"files/b.txt": "b", "files/a b.txt": "a", "files/sub/c.txt": "c", "files/.hidden": "x"})
+	if w := get(New(dir), "/files/", nil); w.Code != http.StatusNotFound {
+		t.Errorf("listed without Listing: %d", w.Code)
+	}
+
+	w := get(New(dir, Options{Listing: true}), "/files/", nil)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-cache" || strings.Contains(body, ".hidden") {
+		t.Fatalf("listing %d %v %s", w.Code, w.Header(), body)
+	}
+
+	a := strings.Index(body, `<a href="a%20b.txt">a b.txt</a>`)
+	b := strings.Index(body, `<a href="b.txt">b.txt</a>`)
+	sub := strings.Index(body, `<a href="sub/">sub/</a>`)
+	if a < 0 || b < a || sub < b {
+		t.Errorf("listing %s", body)
+	}
+}
@@ -0,0 +1,114 @@
+package validatortest
+
+import (
+	"fmt"
+	"golanger.com/framework/validator"
+	"strings"
+	"testing"
+	"time"
+)
+
+// How long one IsSatisfied may take on the pathological inputs of
+// AssertFast and the inputs of Fuzz before it is reported as too slow.
+var MaxCheckTime = 50 * time.Millisecond
+
+// Fail t for each example of validator.Examples that chk gets wrong:
+// a valid one it rejects or an invalid one it accepts.
+func AssertExamples(t testing.TB, chk validator.Validator) {
+	t.Helper()
+	accepted, rejected := validator.CheckExamples(chk)
+	for _, obj := range rejected {
+		t.Errorf("%T rejects valid example %#v", chk, obj)
+	}
+
+	for _, obj := range accepted {
+		t.Errorf("%T accepts invalid example %#v", chk, obj)
+	}
+}
+
+// Return long inputs built to trigger catastrophic backtracking in
+// hand-written matchers and regexp engines other than Go's: long runs of
+// one character, or of a repeated group, ending in one that fails the
+// match.
+func PathologicalInputs() []string {
+	inputs := []string{}
+	for _, n := range []int{1 << 10, 1 << 14} {
+		for _, run := range []string{"a", "aa", "a.", "a-", "1", " ", "ab@", "%"} {
+			inputs = append(inputs, strings.Repeat(run, n/len(run))+"!")
+		}
+
+		inputs = append(inputs, strings.Repeat("a@", n/2)+"@", strings.Repeat("(", n)+strings.Repeat(")", n-1))
+	}
+
+	return inputs
+}
+
+// Fail t if chk panics on input or takes more than MaxCheckTime; a check
+// that times out is left running in the background.
+func check(t testing.TB, chk validator.Validator, input interface{}) {
+	t.Helper()
+	done := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			done <- recover()
+		}()
+
+		chk.IsSatisfied(input)
+	}()
+
+	select {
+	case p := <-done:
+		if p != nil {
+			t.Errorf("%T panics on %s: %v", chk, describeInput(input), p)
+		}
+	case <-time.After(MaxCheckTime):
+		t.Errorf("%T takes more than %s on %s", chk, MaxCheckTime, describeInput(input))
+	}
+}
+
+func describeInput(input interface{}) string {
+	s := fmt.Sprintf("%#v", input)
+	if len(s) > 80 {
+		s = s[:40] + fmt.Sprintf("...(%d bytes)...", len(s)-80) + s[len(s)-40:]
+	}
+
+	return s
+}
+
+// Fail t if chk panics on, or takes more than MaxCheckTime on, any of
+// the PathologicalInputs and the examples.
+func AssertFast(t testing.TB, chk validator.Validator) {
+	t.Helper()
+	valid, invalid := validator.Examples(chk)
+	for _, input := range append(valid, invalid...) {
+		check(t, chk, input)
+	}
+
+	for _, input := range PathologicalInputs() {
+		check(t, chk, input)
+	}
+}
+
+// Fuzz the string inputs of chk, seeded with the string examples and the
+// pathological inputs, failing on a panic or a check slower than
+// MaxCheckTime; call it from a fuzz target:
+//
+//	func FuzzSlug(f *testing.F) {
+//		validatortest.Fuzz(f, Slug{})
+//	}
+func Fuzz(f *testing.F, chk validator.Validator) {
+	valid, invalid := validator.Examples(chk)
+	for _, input := range append(valid, invalid...) {
+		if s, ok := input.(string); ok {
+			f.Add(s)
+		}
+	}
+
+	for _, input := range PathologicalInputs() {
+		f.Add(input)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		check(t, chk, input)
+	})
+}
@@ -0,0 +1,110 @@
+package validatortest
+
+import (
+	"golanger.com/framework/validator"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Describe the errors of v, one per line, for failure messages.
+func describe(v *validator.Validation) string {
+	if len(v.Errors) == 0 {
+		return "no errors"
+	}
+
+	lines := []string{}
+	for _, e := range v.Errors {
+		lines = append(lines, "\t"+e.Key+": "+e.Code+" ("+e.Message+")")
+	}
+
+	return "errors:\n" + strings.Join(lines, "\n")
+}
+
+// Return the errors of v under key.
+func Errors(v *validator.Validation, key string) []*validator.ValidationError {
+	errs := []*validator.ValidationError{}
+	for _, e := range v.Errors {
+		if e.Key == key {
+			errs = append(errs, e)
+		}
+	}
+
+	return errs
+}
+
+// Report whether v has an error under key with code, or with any code if
+// code is "".
+func HasError(v *validator.Validation, key, code string) bool {
+	for _, e := range Errors(v, key) {
+		if code == "" || e.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Fail t unless v has an error under key with code, or with any code if
+// code is "", e.g. AssertError(t, v, "email", "validation.required").
+func AssertError(t testing.TB, v *validator.Validation, key, code string) {
+	t.Helper()
+	if !HasError(v, key, code) {
+		t.Errorf("expected error %s on %q, got %s", code, key, describe(v))
+	}
+}
+
+// Fail t if v has an error under key.
+func AssertNoError(t testing.TB, v *validator.Validation, key string) {
+	t.Helper()
+	if len(Errors(v, key)) > 0 {
+		t.Errorf("expected no error on %q, got %s", key, describe(v))
+	}
+}
+
+// Fail t if v has any error.
+func AssertValid(t testing.TB, v *validator.Validation) {
+	t.Helper()
+	if v.HasErrors() {
+		t.Errorf("expected no errors, got %s", describe(v))
+	}
+}
+
+// Fail t unless the errors of v are exactly those of want, mapping keys to
+// codes.  A key with several errors must be given as many times in want,
+// which is why want pairs up key and code: "email", "validation.required",
+// "age", "validation.min".
+func AssertErrors(t testing.TB, v *validator.Validation, want ...string) {
+	t.Helper()
+	if len(want)%2 != 0 {
+		panic("validatortest: AssertErrors needs key and code pairs")
+	}
+
+	expected := []string{}
+	for i := 0; i < len(want); i += 2 {
+		expected = append(expected, want[i]+" "+want[i+1])
+	}
+
+	got := []string{}
+	for _, e := range v.Errors {
+		got = append(got, e.Key+" "+e.Code)
+	}
+
+	sort.Strings(expected)
+	sort.Strings(got)
+	if strings.Join(expected, "\n") != strings.Join(got, "\n") {
+		t.Errorf("expected errors %v, got %s", expected, describe(v))
+	}
+}
+
+// Fail t unless the error of v under key has message.
+func AssertMessage(t testing.TB, v *validator.Validation, key, message string) {
+	t.Helper()
+	for _, e := range Errors(v, key) {
+		if e.Message == message {
+			return
+		}
+	}
+
+	t.Errorf("expected message %q on %q, got %s", message, key, describe(v))
+}
@@ -0,0 +1,114 @@
+package validatortest
+
+import (
+	"fmt"
+	"golanger.com/framework/validator"
+	"testing"
+	"time"
+)
+
+// A testing.TB that records failures instead of failing.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// Report whether assert fails, and fail t if it should not have.
+func fails(t *testing.T, want bool, assert func(tb testing.TB)) {
+	t.Helper()
+	r := &recorder{TB: t}
+	assert(r)
+	if got := len(r.failures) > 0; got != want {
+		t.Errorf("failed %v, want %v: %v", got, want, r.failures)
+	}
+}
+
+func validation() *validator.Validation {
+	v := &validator.Validation{}
+	v.CheckKey("email", "", validator.Required{})
+	v.CheckKey("age", 12, validator.Min{Min: 18})
+	v.CheckKey("name", "Joe", validator.Required{})
+
+	return v
+}
+
+func TestAssertions(t *testing.T) {
+	v := validation()
+	fails(t, false, func(tb testing.TB) { AssertError(tb, v, "email", "validation.required") })
+	fails(t, false, func(tb testing.TB) { AssertError(tb, v, "age", "") })
+	fails(t, true, func(tb testing.TB) { AssertError(tb, v, "email", "validation.min") })
+	fails(t, true, func(tb testing.TB) { AssertError(tb, v, "name", "") })
+
+	fails(t, false, func(tb testing.TB) { AssertNoError(tb, v, "name") })
+	fails(t, true, func(tb testing.TB) { AssertNoError(tb, v, "age") })
+
+	fails(t, true, func(tb testing.TB) { AssertValid(tb, v) })
+	fails(t, false, func(tb testing.TB) { AssertValid(tb, &validator.Validation{}) })
+
+	fails(t, false, func(tb testing.TB) {
+		AssertErrors(tb, v, "age", "validation.min", "email", "validation.required")
+	})
+	fails(t, true, func(tb testing.TB) { AssertErrors(tb, v, "email", "validation.required") })
+	fails(t, true, func(tb testing.TB) {
+		AssertErrors(tb, v, "age", "validation.min", "email", "validation.required", "name", "validation.required")
+	})
+
+	fails(t, false, func(tb testing.TB) { AssertMessage(tb, v, "email", v.Errors[0].Message) })
+	fails(t, true, func(tb testing.TB) { AssertMessage(tb, v, "email", "Something else") })
+}
+
+func TestAssertErrorsNeedsPairs(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic on an odd number of arguments")
+		}
+	}()
+
+	AssertErrors(t, validation(), "email")
+}
+
+// A validator that accepts what it should not.
+type lax struct{}
+
+func (lax) IsSatisfied(obj interface{}) bool { return true }
+func (lax) DefaultMessage() string           { return "Lax" }
+func (lax) Examples() (valid, invalid []interface{}) {
+	return []interface{}{"a"}, []interface{}{"", nil}
+}
+
+func TestAssertExamples(t *testing.T) {
+	fails(t, false, func(tb testing.TB) { AssertExamples(tb, validator.MinSize{Min: 2}) })
+	r := &recorder{TB: t}
+	AssertExamples(r, lax{})
+	if len(r.failures) != 2 {
+		t.Errorf("failures %v, want one per accepted invalid example", r.failures)
+	}
+}
+
+// A validator that takes too long on long inputs.
+type slow struct{}
+
+func (slow) IsSatisfied(obj interface{}) bool {
+	if s, ok := obj.(string); ok && len(s) > 1000 {
+		time.Sleep(time.Second)
+	}
+
+	return true
+}
+
+func (slow) DefaultMessage() string { return "Slow" }
+
+func TestAssertFast(t *testing.T) {
+	fails(t, false, func(tb testing.TB) { AssertFast(tb, validator.NewEmail()) })
+	fails(t, true, func(tb testing.TB) { AssertFast(tb, slow{}) })
+}
+
+func FuzzEmail(f *testing.F) {
+	Fuzz(f, validator.NewEmail())
+}
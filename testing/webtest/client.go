@@ -0,0 +1,272 @@
+package webtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// A Client sends requests straight to a handler, as a browser would: it
+// keeps the cookies, and with them the session, across requests, and
+// sends back the CSRF token of the last page that had one.
+type Client struct {
+	T       testing.TB
+	Handler http.Handler
+	Jar     http.CookieJar
+
+	// The URL paths are resolved against, https://example.com by default,
+	// so that Secure cookies are kept.
+	BaseURL *url.URL
+
+	// Headers added to every request, e.g. Accept.
+	Header http.Header
+
+	// Where the CSRF token is looked for and sent: the hidden field of the
+	// pages, the cookie it is kept in without a session, and the header
+	// sent on unsafe requests.  The defaults of the csrf package.
+	CSRFField  string
+	CSRFCookie string
+	CSRFHeader string
+
+	// The token last seen, sent on POST, PUT, DELETE and other unsafe
+	// requests.
+	CSRFToken string
+}
+
+func New(t testing.TB, h http.Handler) *Client {
+	jar, _ := cookiejar.New(nil)
+	base, _ := url.Parse("https://example.com")
+
+	return &Client{
+		T:          t,
+		Handler:    h,
+		Jar:        jar,
+		BaseURL:    base,
+		Header:     http.Header{},
+		CSRFField:  "csrf_token",
+		CSRFCookie: "_csrf",
+		CSRFHeader: "X-CSRF-Token",
+	}
+}
+
+// A response, with assertions failing the test of its client.
+type Response struct {
+	*http.Response
+	Body   string
+	client *Client
+}
+
+// Build a request for path, resolved against BaseURL.
+func (c *Client) NewRequest(method, path string, body io.Reader) *http.Request {
+	u, err := c.BaseURL.Parse(path)
+	if err != nil {
+		c.T.Fatalf("webtest: bad path %q: %v", path, err)
+	}
+
+	return httptest.NewRequest(method, u.String(), body)
+}
+
+func safeMethod(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
+	}
+
+	return false
+}
+
+// Serve req with the client's cookies, headers and CSRF token, and keep
+// the cookies and token of the response.
+func (c *Client) Do(req *http.Request) *Response {
+	c.T.Helper()
+	for name, values := range c.Header {
+		if req.Header.Get(name) == "" {
+			req.Header[name] = values
+		}
+	}
+
+	for _, ck := range c.Jar.Cookies(req.URL) {
+		req.AddCookie(ck)
+	}
+
+	if !safeMethod(req.Method) && req.Header.Get(c.CSRFHeader) == "" {
+		if token := c.csrfToken(req.URL); token != "" {
+			req.Header.Set(c.CSRFHeader, token)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c.Handler.ServeHTTP(w, req)
+	resp := w.Result()
+	resp.Request = req
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	c.Jar.SetCookies(req.URL, resp.Cookies())
+	if token := findToken(string(body), c.CSRFField); token != "" {
+		c.CSRFToken = token
+	}
+
+	return &Response{Response: resp, Body: string(body), client: c}
+}
+
+// Return the token to send: the last one on a page, or else that of the
+// CSRF cookie.
+func (c *Client) csrfToken(u *url.URL) string {
+	if c.CSRFToken != "" {
+		return c.CSRFToken
+	}
+
+	for _, ck := range c.Jar.Cookies(u) {
+		if ck.Name == c.CSRFCookie {
+			return ck.Value
+		}
+	}
+
+	return ""
+}
+
+var inputTag = regexp.MustCompile(`<input[^>]*>`)
+var attr = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Return the value of the hidden input named field, or "".
+func findToken(body, field string) string {
+	for _, tag := range inputTag.FindAllString(body, -1) {
+		attrs := map[string]string{}
+		for _, m := range attr.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(m[1])] = m[2]
+		}
+
+		if attrs["name"] == field {
+			return attrs["value"]
+		}
+	}
+
+	return ""
+}
+
+func (c *Client) Get(path string) *Response {
+	c.T.Helper()
+
+	return c.Do(c.NewRequest("GET", path, nil))
+}
+
+// Submit a form, urlencoded or, if it has files, multipart.
+func (c *Client) Submit(method, path string, f *Form) *Response {
+	c.T.Helper()
+	contentType, body := f.Encode()
+	req := c.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", contentType)
+
+	return c.Do(req)
+}
+
+func (c *Client) PostForm(path string, values url.Values) *Response {
+	c.T.Helper()
+
+	return c.Submit("POST", path, &Form{Values: values})
+}
+
+// Send v as a JSON body.
+func (c *Client) JSON(method, path string, v interface{}) *Response {
+	c.T.Helper()
+	body, err := jsonBody(v)
+	if err != nil {
+		c.T.Fatalf("webtest: cannot encode JSON: %v", err)
+	}
+
+	req := c.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	return c.Do(req)
+}
+
+// Return the cookie of the client named name, or nil.
+func (c *Client) Cookie(name string) *http.Cookie {
+	for _, ck := range c.Jar.Cookies(c.BaseURL) {
+		if ck.Name == name {
+			return ck
+		}
+	}
+
+	return nil
+}
+
+// GET the Location of a redirect response.
+func (r *Response) Follow() *Response {
+	r.client.T.Helper()
+	location := r.Header.Get("Location")
+	if location == "" {
+		r.client.T.Fatalf("webtest: %s %s did not redirect (status %d)", r.Request.Method, r.Request.URL.Path, r.StatusCode)
+	}
+
+	return r.client.Do(r.client.NewRequest("GET", r.Request.URL.ResolveReference(mustParse(r.client.T, location)).String(), nil))
+}
+
+func mustParse(t testing.TB, s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		t.Fatalf("webtest: bad URL %q: %v", s, err)
+	}
+
+	return u
+}
+
+func (r *Response) AssertStatus(status int) *Response {
+	r.client.T.Helper()
+	if r.StatusCode != status {
+		r.client.T.Errorf("%s %s: expected status %d, got %d: %s", r.Request.Method, r.Request.URL.Path, status, r.StatusCode, excerpt(r.Body))
+	}
+
+	return r
+}
+
+// Require a redirect to location.
+func (r *Response) AssertRedirect(location string) *Response {
+	r.client.T.Helper()
+	if r.StatusCode/100 != 3 || r.Header.Get("Location") != location {
+		r.client.T.Errorf("%s %s: expected redirect to %s, got %d %s", r.Request.Method, r.Request.URL.Path, location, r.StatusCode, r.Header.Get("Location"))
+	}
+
+	return r
+}
+
+func (r *Response) AssertContains(s string) *Response {
+	r.client.T.Helper()
+	if !strings.Contains(r.Body, s) {
+		r.client.T.Errorf("%s %s: expected body to contain %q: %s", r.Request.Method, r.Request.URL.Path, s, excerpt(r.Body))
+	}
+
+	return r
+}
+
+func (r *Response) AssertNotContains(s string) *Response {
+	r.client.T.Helper()
+	if strings.Contains(r.Body, s) {
+		r.client.T.Errorf("%s %s: expected body not to contain %q", r.Request.Method, r.Request.URL.Path, s)
+	}
+
+	return r
+}
+
+// Decode the body as JSON into v.
+func (r *Response) DecodeJSON(v interface{}) {
+	r.client.T.Helper()
+	if err := decodeJSON(r.Body, v); err != nil {
+		r.client.T.Fatalf("%s %s: bad JSON body: %v: %s", r.Request.Method, r.Request.URL.Path, err, excerpt(r.Body))
+	}
+}
+
+func excerpt(body string) string {
+	if len(body) > 500 {
+		return body[:500] + "..."
+	}
+
+	return body
+}
@@ -0,0 +1,87 @@
+package webtest_test
+
+import (
+	"fmt"
+	"golanger.com/framework/csrf"
+	"golanger.com/framework/session"
+	"golanger.com/framework/testing/webtest"
+	"golanger.com/framework/validator"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// An application with a sign-up form, behind sessions and CSRF
+// protection, that validates what is posted.
+func app() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/signup", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			v := &validator.Validation{}
+			v.CheckKey("email", r.FormValue("email"), validator.Required{}, validator.NewEmail())
+			if !v.HasErrors() {
+				session.FromRequest(r).Set("email", r.FormValue("email"))
+				http.Redirect(w, r, "/welcome", http.StatusSeeOther)
+				return
+			}
+
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, v.Errors[0].Message)
+		}
+
+		fmt.Fprintf(w, `<form method="post">%s<input name="email"></form>`, csrf.TemplateField(r))
+	})
+
+	mux.HandleFunc("/welcome", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Welcome ", session.FromRequest(r).GetString("email"))
+	})
+
+	mux.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 7}`)
+	})
+
+	manager := session.NewManager(session.NewMemoryStore(), "sid", time.Hour)
+
+	return manager.Middleware(csrf.New(csrf.Options{}).Middleware(mux))
+}
+
+func TestClientSession(t *testing.T) {
+	c := webtest.New(t, app())
+	c.Get("/signup").AssertStatus(http.StatusOK).AssertContains(`name="csrf_token"`)
+	if c.CSRFToken == "" {
+		t.Fatal("the CSRF token of the page was not kept")
+	}
+
+	c.Submit("POST", "/signup", webtest.NewForm().Set("email", "nope")).
+		AssertStatus(http.StatusUnprocessableEntity)
+
+	c.Submit("POST", "/signup", webtest.NewForm().Set("email", "joe@example.com")).
+		AssertRedirect("/welcome").
+		Follow().
+		AssertContains("Welcome joe@example.com")
+
+	if c.Cookie("sid") == nil {
+		t.Error("no session cookie kept")
+	}
+}
+
+func TestClientWithoutToken(t *testing.T) {
+	c := webtest.New(t, app())
+	c.Get("/signup")
+	c.CSRFToken = "forged"
+	c.Submit("POST", "/signup", webtest.NewForm().Set("email", "joe@example.com")).
+		AssertStatus(http.StatusForbidden).
+		AssertNotContains("Welcome")
+}
+
+func TestClientJSON(t *testing.T) {
+	c := webtest.New(t, app())
+	c.Get("/signup")
+
+	var user struct{ ID int }
+	c.JSON("POST", "/api/users", map[string]string{"name": "Joe"}).AssertStatus(http.StatusOK).DecodeJSON(&user)
+	if user.ID != 7 {
+		t.Errorf("decoded %+v", user)
+	}
+}
@@ -0,0 +1,81 @@
+package webtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"strings"
+)
+
+type file struct {
+	field, filename string
+	data            []byte
+}
+
+// A Form builds the body of a form submission:
+//
+//	f := webtest.NewForm().Set("title", "Hello").File("cover", "a.png", png)
+//	client.Submit("POST", "/posts", f).AssertRedirect("/posts/1")
+type Form struct {
+	Values url.Values
+	files  []file
+}
+
+func NewForm() *Form {
+	return &Form{Values: url.Values{}}
+}
+
+func (f *Form) Set(key, value string) *Form {
+	f.Values.Set(key, value)
+	return f
+}
+
+func (f *Form) Add(key, value string) *Form {
+	f.Values.Add(key, value)
+	return f
+}
+
+// Attach a file, which makes the form multipart.
+func (f *Form) File(field, filename string, data []byte) *Form {
+	f.files = append(f.files, file{field, filename, data})
+	return f
+}
+
+// Return the content type and body of the form.
+func (f *Form) Encode() (string, io.Reader) {
+	if len(f.files) == 0 {
+		return "application/x-www-form-urlencoded", strings.NewReader(f.Values.Encode())
+	}
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	for key, values := range f.Values {
+		for _, value := range values {
+			w.WriteField(key, value)
+		}
+	}
+
+	for _, file := range f.files {
+		fw, _ := w.CreateFormFile(file.field, file.filename)
+		fw.Write(file.data)
+	}
+
+	w.Close()
+
+	return w.FormDataContentType(), &b
+}
+
+func jsonBody(v interface{}) (io.Reader, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(b), nil
+}
+
+func decodeJSON(body string, v interface{}) error {
+	return json.Unmarshal([]byte(body), v)
+}
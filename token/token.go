@@ -0,0 +1,231 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNoKeys  = errors.New("token: no keys configured")
+	ErrInvalid = errors.New("token: invalid or tampered token")
+	ErrExpired = errors.New("token: token expired")
+)
+
+// A Signer makes and checks expiring signed tokens, for links such as
+// email confirmation, password reset and unsubscribe ones.  A token
+// carries a JSON-encoded payload, readable by whoever holds it but not
+// alterable, and is bound to a purpose, so that a token made for one
+// purpose is refused for another.
+//
+// New tokens are signed with the first key and tokens signed with any of
+// them are accepted, so a new key can be put first and the old one removed
+// once its tokens have expired.  Keys are HMAC-SHA256 keys, best 32 random
+// bytes or more.
+//
+// A token stays valid until it expires, however often it is used.  To
+// make one single-use, put in its payload something its use changes, such
+// as a fingerprint of the password hash of a reset token, and compare it.
+type Signer struct {
+	keys [][]byte
+}
+
+func New(keys ...[]byte) *Signer {
+	return &Signer{keys: keys}
+}
+
+// The Signer of the package-level functions, without keys until
+// Configure is called.
+var Default = New()
+
+func Configure(keys ...[]byte) {
+	Default = New(keys...)
+}
+
+// The content of a token, before its signature.
+type claims struct {
+	Payload json.RawMessage `json:"p,omitempty"`
+	Expires int64           `json:"e"`
+}
+
+func (s *Signer) mac(key []byte, purpose, body string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(purpose + "|" + body))
+
+	return mac.Sum(nil)
+}
+
+// Return a URL-safe token for purpose, carrying payload (which may be
+// nil), that expires after ttl.
+func (s *Signer) Generate(purpose string, payload interface{}, ttl time.Duration) (string, error) {
+	if len(s.keys) == 0 {
+		return "", ErrNoKeys
+	}
+
+	c := claims{Expires: time.Now().Add(ttl).Unix()}
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return "", err
+		}
+
+		c.Payload = b
+	}
+
+	b, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	body := base64.RawURLEncoding.EncodeToString(b)
+
+	return body + "." + base64.RawURLEncoding.EncodeToString(s.mac(s.keys[0], purpose, body)), nil
+}
+
+// Check a token made by Generate for purpose and, unless dst is nil,
+// decode its payload into dst, as with json.Unmarshal.
+func (s *Signer) Verify(purpose, token string, dst interface{}) error {
+	if len(s.keys) == 0 {
+		return ErrNoKeys
+	}
+
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return ErrInvalid
+	}
+
+	body := token[:i]
+	sig, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil {
+		return ErrInvalid
+	}
+
+	valid := false
+	for _, key := range s.keys {
+		if hmac.Equal(s.mac(key, purpose, body), sig) {
+			valid = true
+			break
+		}
+	}
+
+	if !valid {
+		return ErrInvalid
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return ErrInvalid
+	}
+
+	var c claims
+	if err := json.Unmarshal(b, &c); err != nil {
+		return ErrInvalid
+	}
+
+	if time.Now().Unix() >= c.Expires {
+		return ErrExpired
+	}
+
+	if dst != nil && len(c.Payload) > 0 {
+		if err := json.Unmarshal(c.Payload, dst); err != nil {
+			return ErrInvalid
+		}
+	}
+
+	return nil
+}
+
+// The query parameters SignURL adds.
+const (
+	ExpiresParam   = "expires"
+	SignatureParam = "signature"
+)
+
+// The purpose URLs are signed for, which no token body, being base64, can
+// be confused with.
+const urlPurpose = "|url"
+
+// Return rawurl with an expiry and a signature of its path and query
+// added to the query, so that VerifyURL can check the link has not been
+// altered, e.g. for a download link sent by email.
+func (s *Signer) SignURL(rawurl string, ttl time.Duration) (string, error) {
+	if len(s.keys) == 0 {
+		return "", ErrNoKeys
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+
+	q := u.Query()
+	q.Del(SignatureParam)
+	q.Set(ExpiresParam, strconv.FormatInt(time.Now().Add(ttl).Unix(), 10))
+	u.RawQuery = q.Encode()
+	sig := s.mac(s.keys[0], urlPurpose, u.EscapedPath()+"?"+u.RawQuery)
+	q.Set(SignatureParam, base64.RawURLEncoding.EncodeToString(sig))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// Check a URL signed by SignURL, such as the URL of a request; only its
+// path and query are signed.
+func (s *Signer) VerifyURL(u *url.URL) error {
+	if len(s.keys) == 0 {
+		return ErrNoKeys
+	}
+
+	q := u.Query()
+	sig, err := base64.RawURLEncoding.DecodeString(q.Get(SignatureParam))
+	if err != nil || len(sig) == 0 {
+		return ErrInvalid
+	}
+
+	q.Del(SignatureParam)
+	signed := u.EscapedPath() + "?" + q.Encode()
+	valid := false
+	for _, key := range s.keys {
+		if hmac.Equal(s.mac(key, urlPurpose, signed), sig) {
+			valid = true
+			break
+		}
+	}
+
+	if !valid {
+		return ErrInvalid
+	}
+
+	expires, err := strconv.ParseInt(q.Get(ExpiresParam), 10, 64)
+	if err != nil {
+		return ErrInvalid
+	}
+
+	if time.Now().Unix() >= expires {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+func Generate(purpose string, payload interface{}, ttl time.Duration) (string, error) {
+	return Default.Generate(purpose, payload, ttl)
+}
+
+func Verify(purpose, token string, dst interface{}) error {
+	return Default.Verify(purpose, token, dst)
+}
+
+func SignURL(rawurl string, ttl time.Duration) (string, error) {
+	return Default.SignURL(rawurl, ttl)
+}
+
+func VerifyURL(u *url.URL) error {
+	return Default.VerifyURL(u)
+}
@@ -0,0 +1,113 @@
+package token
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var key = []byte("0123456789abcdef0123456789abcdef")
+
+func TestGenerateVerify(t *testing.T) {
+	s := New(key)
+	tok, err := s.Generate("reset", map[string]string{"user": "7"}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload map[string]string
+	if err := s.Verify("reset", tok, &payload); err != nil || payload["user"] != "7" {
+		t.Errorf("Verify = %v, %v", payload, err)
+	}
+
+	if err := s.Verify("confirm", tok, nil); err != ErrInvalid {
+		t.Errorf("token accepted for another purpose: %v", err)
+	}
+
+	if err := New([]byte("another key")).Verify("reset", tok, nil); err != ErrInvalid {
+		t.Errorf("token accepted with another key: %v", err)
+	}
+
+	i := strings.LastIndex(tok, ".")
+	forged, _ := New([]byte("another key")).Generate("reset", map[string]string{"user": "1"}, time.Hour)
+	for _, bad := range []string{forged[:strings.LastIndex(forged, ".")] + tok[i:], tok[:i], tok + "x", ""} {
+		if err := s.Verify("reset", bad, nil); err != ErrInvalid {
+			t.Errorf("Verify(%q) error %v, want ErrInvalid", bad, err)
+		}
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	s := New(key)
+	tok, _ := s.Generate("reset", nil, -time.Second)
+	if err := s.Verify("reset", tok, nil); err != ErrExpired {
+		t.Errorf("error %v, want ErrExpired", err)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	tok, _ := New(key).Generate("reset", nil, time.Hour)
+	if err := New([]byte("new key"), key).Verify("reset", tok, nil); err != nil {
+		t.Errorf("token of the old key rejected: %v", err)
+	}
+}
+
+func TestNoKeys(t *testing.T) {
+	if _, err := New().Generate("reset", nil, time.Hour); err != ErrNoKeys {
+		t.Errorf("Generate error %v", err)
+	}
+
+	if _, err := New().SignURL("/a", time.Hour); err != ErrNoKeys {
+		t.Errorf("SignURL error %v", err)
+	}
+}
+
+func TestSignURL(t *testing.T) {
+	s := New(key)
+	signed, err := s.SignURL("https://example.com/files/a.pdf?user=7", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, _ := url.Parse(signed)
+	if err := s.VerifyURL(u); err != nil {
+		t.Errorf("VerifyURL(%s) = %v", signed, err)
+	}
+
+	// Only the path and query are signed.
+	moved, _ := url.Parse(strings.Replace(signed, "example.com", "cdn.example.com", 1))
+	if err := s.VerifyURL(moved); err != nil {
+		t.Errorf("VerifyURL on another host = %v", err)
+	}
+
+	for _, bad := range []string{
+		strings.Replace(signed, "user=7", "user=8", 1),
+		strings.Replace(signed, "a.pdf", "b.pdf", 1),
+		signed + "&admin=1",
+		strings.Replace(signed, "signature=", "signature=x", 1),
+		"https://example.com/files/a.pdf?user=7",
+	} {
+		u, _ := url.Parse(bad)
+		if err := s.VerifyURL(u); err != ErrInvalid {
+			t.Errorf("VerifyURL(%s) = %v, want ErrInvalid", bad, err)
+		}
+	}
+
+	stale, _ := s.SignURL("/files/a.pdf", -time.Second)
+	u, _ = url.Parse(stale)
+	if err := s.VerifyURL(u); err != ErrExpired {
+		t.Errorf("error %v, want ErrExpired", err)
+	}
+}
+
+func TestURLAndTokenSignaturesDiffer(t *testing.T) {
+	s := New(key)
+	signed, _ := s.SignURL("/a", time.Hour)
+	u, _ := url.Parse(signed)
+	q := u.Query()
+	tok := q.Get(ExpiresParam) + "." + q.Get(SignatureParam)
+	if err := s.Verify("", tok, nil); err != ErrInvalid {
+		t.Errorf("URL signature accepted as a token: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Keeps files under Dir.  If BaseURL is set, such as "/uploads/" when Dir
+// is served there, the URL of a key is BaseURL followed by the key.
+type DiskStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewDiskStorage(dir, baseURL string) *DiskStorage {
+	return &DiskStorage{Dir: dir, BaseURL: baseURL}
+}
+
+var ErrBadKey = errors.New("upload: invalid key")
+
+// Return the path of key, refusing keys that would leave Dir.
+func (d *DiskStorage) path(key string) (string, error) {
+	if key == "" || strings.Contains(key, "\\") || path.Clean("/"+key) != "/"+key {
+		return "", ErrBadKey
+	}
+
+	return filepath.Join(d.Dir, filepath.FromSlash(key)), nil
+}
+
+// Write r to a temporary file beside its destination and rename it there
+// once complete, so that a failed upload leaves nothing behind.
+func (d *DiskStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error) {
+	name, err := d.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return 0, err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (d *DiskStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(name)
+}
+
+func (d *DiskStorage) Delete(ctx context.Context, key string) error {
+	name, err := d.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (d *DiskStorage) URL(key string) string {
+	if d.BaseURL == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(d.BaseURL, "/") + "/" + key
+}
@@ -0,0 +1,343 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keeps files in a bucket of Amazon S3 or a compatible service, such as
+// MinIO, addressed by path: Endpoint/Bucket/Prefix+key.  A file is sent a
+// part of PartSize bytes at a time, so that at most one part is held in
+// memory: in one PutObject if it fits, as a multipart upload otherwise.
+type S3Storage struct {
+	Endpoint  string // e.g. "https://s3.eu-west-1.amazonaws.com"
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Prefix    string
+
+	// The size of the parts of multipart uploads, 5 MB if zero, the
+	// smallest S3 allows.
+	PartSize int
+
+	// The base of public URLs, if the bucket is public.
+	BaseURL string
+
+	Client *http.Client
+}
+
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string) *S3Storage {
+	return &S3Storage{
+		Endpoint:  endpoint,
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+	}
+}
+
+// An error response of the service.
+type S3Error struct {
+	Status  int
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *S3Error) Error() string {
+	return fmt.Sprint("upload: s3: ", e.Status, " ", e.Code, " ", e.Message)
+}
+
+func (s *S3Storage) objectURL(key string, query url.Values) string {
+	u := strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + uriEncode(s.Prefix+key, false)
+	if len(query) > 0 {
+		u += "?" + canonicalQuery(query)
+	}
+
+	return u
+}
+
+// Percent-encode as SigV4 requires: everything but unreserved characters,
+// and slashes too unless in a path.
+func uriEncode(s string, slash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && !slash {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	parts := []string{}
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// Sign req with AWS Signature Version 4, over the host, the range, the
+// content type and the x-amz-* headers.
+func (s *S3Storage) sign(req *http.Request, payloadHash string, now time.Time) {
+	stamp := now.UTC().Format("20060102T150405Z")
+	day := stamp[:8]
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "range" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+
+	signedHeaders := strings.Join(names, ";")
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.AccessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// Send a signed request and return its response, or an *S3Error for a
+// status other than 2xx.
+func (s *S3Storage) do(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key, query), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	req.ContentLength = int64(len(body))
+	s.sign(req, sha256Hex(body), time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		e := &S3Error{Status: resp.StatusCode}
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		xml.Unmarshal(b, e)
+
+		return nil, e
+	}
+
+	return resp, nil
+}
+
+func (s *S3Storage) partSize() int {
+	if s.PartSize < 5<<20 {
+		return 5 << 20
+	}
+
+	return s.PartSize
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error) {
+	buf := make([]byte, s.partSize())
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return 0, err
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	if n < len(buf) {
+		resp, err := s.do(ctx, "PUT", key, nil, buf[:n], header)
+		if err != nil {
+			return 0, err
+		}
+
+		resp.Body.Close()
+
+		return int64(n), nil
+	}
+
+	return s.putMultipart(ctx, key, r, buf, header)
+}
+
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// Upload the part in buf, then the rest of r, as a multipart upload,
+// aborted if anything fails.
+func (s *S3Storage) putMultipart(ctx context.Context, key string, r io.Reader, buf []byte, header http.Header) (int64, error) {
+	resp, err := s.do(ctx, "POST", key, url.Values{"uploads": {""}}, nil, header)
+	if err != nil {
+		return 0, err
+	}
+
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+
+	err = xml.NewDecoder(resp.Body).Decode(&initiated)
+	resp.Body.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	abort := func(err error) (int64, error) {
+		resp, aerr := s.do(context.Background(), "DELETE", key, url.Values{"uploadId": {initiated.UploadID}}, nil, nil)
+		if aerr == nil {
+			resp.Body.Close()
+		}
+
+		return 0, err
+	}
+
+	parts := []completedPart{}
+	size := int64(0)
+	n := len(buf)
+	for n > 0 {
+		number := len(parts) + 1
+		query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {initiated.UploadID}}
+		resp, err := s.do(ctx, "PUT", key, query, buf[:n], nil)
+		if err != nil {
+			return abort(err)
+		}
+
+		resp.Body.Close()
+		parts = append(parts, completedPart{number, resp.Header.Get("ETag")})
+		size += int64(n)
+
+		n, err = io.ReadFull(r, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return abort(err)
+		}
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return abort(err)
+	}
+
+	resp, err = s.do(ctx, "POST", key, url.Values{"uploadId": {initiated.UploadID}}, body, nil)
+	if err != nil {
+		return abort(err)
+	}
+
+	// The service may answer 200 with an error in the body.
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if bytes.Contains(b, []byte("<Error>")) {
+		e := &S3Error{Status: resp.StatusCode}
+		xml.Unmarshal(b, e)
+
+		return abort(e)
+	}
+
+	return size, nil
+}
+
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, "GET", key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, "DELETE", key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	if s.BaseURL == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + uriEncode(s.Prefix+key, false)
+}
@@ -0,0 +1,325 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/validator"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// A Storage keeps uploaded files under keys, slash-separated paths such
+// as "avatars/3f2a.png".  Put streams r, returning the number of bytes it
+// stored; if it fails, nothing is left under key.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// A Storage may implement URLer to give the public URL of a key.
+type URLer interface {
+	URL(key string) string
+}
+
+// The number of bytes of each file read before it is stored, to sniff its
+// type and check it: 64 KB, enough for the header of any image.
+const HeadBytes = 64 << 10
+
+// A file part of a request while it is received.  It is a validator.File,
+// so the file validators check it; until it has been stored, its size is
+// that of its Head.
+type Part struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Header      map[string][]string
+	head        []byte
+	size        int64
+}
+
+func (p *Part) FileSize() int64 {
+	return p.size
+}
+
+func (p *Part) Head() []byte {
+	return p.head
+}
+
+// A stored file, as handed to the handler.
+type Object struct {
+	Field       string
+	Filename    string
+	Key         string
+	ContentType string
+	Size        int64
+	storage     Storage
+}
+
+func (o *Object) Open(ctx context.Context) (io.ReadCloser, error) {
+	return o.storage.Open(ctx, o.Key)
+}
+
+func (o *Object) Delete(ctx context.Context) error {
+	return o.storage.Delete(ctx, o.Key)
+}
+
+// Return the public URL of the object, or "" if its storage has none.
+func (o *Object) URL() string {
+	if u, ok := o.storage.(URLer); ok {
+		return u.URL(o.Key)
+	}
+
+	return ""
+}
+
+type Options struct {
+	Storage Storage
+
+	// The checks of each file field, such as validator.MaxFileSize and
+	// validator.AllowedMIME; a field is required if its checks include
+	// validator.Required.  Files of other fields are refused, unless
+	// Fields is nil.
+	Fields map[string][]validator.Validator
+
+	// Limits of the request: the size of any one file (32 MB if zero,
+	// lowered by a MaxFileSize check), the number of files (10 if zero)
+	// and the size of the other values (1 MB if zero).
+	MaxFileSize  int64
+	MaxFiles     int
+	MaxValueSize int64
+
+	// Return the key of a part, by default random hex with the extension
+	// of the filename under Prefix.
+	Key    func(p *Part) string
+	Prefix string
+}
+
+// The outcome of Parse: the form values, the stored files by field, and
+// the errors of the checks.  If there are errors, no file is kept.
+type Result struct {
+	Values     url.Values
+	Files      map[string][]*Object
+	Validation *validator.Validation
+}
+
+// Return the first file of field, or nil.
+func (r *Result) File(field string) *Object {
+	if files := r.Files[field]; len(files) > 0 {
+		return files[0]
+	}
+
+	return nil
+}
+
+var errTooLarge = errors.New("upload: file too large")
+
+// A reader failing with errTooLarge after n bytes.
+type limitReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, errTooLarge
+	}
+
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, errTooLarge
+	}
+
+	return n, err
+}
+
+func randomKey(p *Part) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b) + strings.ToLower(path.Ext(p.Filename))
+}
+
+// Keep the last element of a client's file name, which may be a Windows
+// path, without control characters.
+func cleanFilename(name string) string {
+	name = path.Base(strings.Replace(name, "\\", "/", -1))
+
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r == 0x7f {
+			return -1
+		}
+
+		return r
+	}, name)
+}
+
+// The smallest of limit and the MaxFileSize checks.
+func sizeLimit(limit int64, checks []validator.Validator) (int64, validator.Validator) {
+	var check validator.Validator = validator.MaxFileSize{Bytes: limit}
+	for _, c := range checks {
+		if m, ok := c.(validator.MaxFileSize); ok && m.Bytes < limit {
+			limit, check = m.Bytes, c
+		}
+	}
+
+	return limit, check
+}
+
+func hasRequired(checks []validator.Validator) bool {
+	for _, c := range checks {
+		if _, ok := c.(validator.Required); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Read a multipart/form-data request part by part, streaming each file to
+// the storage as it arrives instead of buffering it.  Each file is checked
+// on its first bytes, before it is stored, and cut off at its size limit.
+// The error is for a malformed request or a failing storage; the files
+// stored by then are deleted.
+func Parse(r *http.Request, opts Options) (*Result, error) {
+	if opts.MaxFileSize == 0 {
+		opts.MaxFileSize = 32 << 20
+	}
+
+	if opts.MaxFiles == 0 {
+		opts.MaxFiles = 10
+	}
+
+	if opts.MaxValueSize == 0 {
+		opts.MaxValueSize = 1 << 20
+	}
+
+	if opts.Key == nil {
+		opts.Key = randomKey
+	}
+
+	res := &Result{
+		Values:     url.Values{},
+		Files:      map[string][]*Object{},
+		Validation: (&validator.Validation{}).SetLocale(i18n.Locale(r)).SetContext(r.Context()),
+	}
+
+	ctx := r.Context()
+	removeAll := func() {
+		for _, objects := range res.Files {
+			for _, o := range objects {
+				if err := o.Delete(ctx); err != nil {
+					log.FromRequest(r).Error("<upload.Parse> ", err)
+				}
+			}
+		}
+
+		res.Files = map[string][]*Object{}
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	valueBytes, files := int64(0), 0
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		field := part.FormName()
+		if part.FileName() == "" {
+			b, err := io.ReadAll(io.LimitReader(part, opts.MaxValueSize-valueBytes+1))
+			valueBytes += int64(len(b))
+			if err != nil || valueBytes > opts.MaxValueSize {
+				removeAll()
+				return nil, errors.New("upload: form values too large")
+			}
+
+			res.Values.Add(field, string(b))
+			continue
+		}
+
+		checks, ok := opts.Fields[field]
+		if !ok && opts.Fields != nil {
+			log.FromRequest(r).Debug("<upload.Parse> ", "file of unexpected field ", field)
+			continue
+		}
+
+		if files++; files > opts.MaxFiles {
+			res.Validation.Error("At most %d files may be uploaded", opts.MaxFiles).Key(field).Code("upload.too_many")
+			continue
+		}
+
+		p := &Part{Field: field, Filename: cleanFilename(part.FileName()), Header: part.Header}
+		head := make([]byte, HeadBytes)
+		n, err := io.ReadFull(part, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			removeAll()
+			return nil, err
+		}
+
+		p.head, p.size = head[:n], int64(n)
+		p.ContentType = http.DetectContentType(p.head)
+		if !res.Validation.CheckKey(field, p, checks...).Ok {
+			continue
+		}
+
+		limit, limitCheck := sizeLimit(opts.MaxFileSize, checks)
+		body := &limitReader{r: io.MultiReader(bytes.NewReader(p.head), part), n: limit}
+		key := opts.Prefix + opts.Key(p)
+		size, err := opts.Storage.Put(ctx, key, body, p.ContentType)
+		if errors.Is(err, errTooLarge) {
+			p.size = limit + 1
+			res.Validation.CheckKey(field, p, limitCheck)
+			continue
+		}
+
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		p.size = size
+		res.Files[field] = append(res.Files[field], &Object{
+			Field:       field,
+			Filename:    p.Filename,
+			Key:         key,
+			ContentType: p.ContentType,
+			Size:        size,
+			storage:     opts.Storage,
+		})
+	}
+
+	for field, checks := range opts.Fields {
+		if len(res.Files[field]) == 0 && hasRequired(checks) && res.Validation.ErrorMap()[field] == nil {
+			res.Validation.RequiredKey(field, nil)
+		}
+	}
+
+	if res.Validation.HasErrors() {
+		removeAll()
+	}
+
+	return res, nil
+}
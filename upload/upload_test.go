@@ -0,0 +1,242 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"golanger.com/framework/testing/webtest"
+	"golanger.com/framework/validator"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var png = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 92)...)
+
+func request(f *webtest.Form) *http.Request {
+	contentType, body := f.Encode()
+	r := httptest.NewRequest("POST", "/", body)
+	r.Header.Set("Content-Type", contentType)
+
+	return r
+}
+
+// Return the names of the files under dir.
+func stored(t *testing.T, dir string) []string {
+	names := []string{}
+	filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err == nil && !fi.IsDir() {
+			names = append(names, path[len(dir)+1:])
+		}
+
+		return nil
+	})
+
+	return names
+}
+
+func avatars(dir string) Options {
+	return Options{
+		Storage: NewDiskStorage(dir, "/uploads/"),
+		Fields: map[string][]validator.Validator{
+			"avatar": {validator.Required{}, validator.AllowedMIME{Types: []string{"image/*"}}, validator.MaxFileSize{Bytes: 1 << 10}},
+		},
+		Prefix: "avatars/",
+	}
+}
+
+func TestParse(t *testing.T) {
+	dir := t.TempDir()
+	f := webtest.NewForm().Set("name", "Joe").File("avatar", `C:\Users\joe\Me.PNG`, png).File("other", "x.png", png)
+	res, err := Parse(request(f), avatars(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Validation.HasErrors() {
+		t.Fatalf("errors %v", res.Validation.Errors)
+	}
+
+	o := res.File("avatar")
+	if o == nil || res.Values.Get("name") != "Joe" || res.File("other") != nil {
+		t.Fatalf("parsed %v and %v", res.Values, res.Files)
+	}
+
+	if o.Filename != "Me.PNG" || o.Size != int64(len(png)) || o.ContentType != "image/png" ||
+		!strings.HasPrefix(o.Key, "avatars/") || !strings.HasSuffix(o.Key, ".png") {
+		t.Errorf("object %+v", o)
+	}
+
+	if o.URL() != "/uploads/"+o.Key {
+		t.Errorf("URL = %s", o.URL())
+	}
+
+	rc, err := o.Open(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := io.ReadAll(rc)
+	rc.Close()
+	if !bytes.Equal(b, png) {
+		t.Errorf("stored %q", b)
+	}
+
+	if err := o.Delete(context.Background()); err != nil || len(stored(t, dir)) != 0 {
+		t.Errorf("Delete = %v, left %v", err, stored(t, dir))
+	}
+}
+
+func TestParseChecks(t *testing.T) {
+	cases := map[string]*webtest.Form{
+		"not an image": webtest.NewForm().File("avatar", "a.png", []byte("plain text")),
+		"too large":    webtest.NewForm().File("avatar", "a.png", append(png, make([]byte, 1<<10)...)),
+		"missing":      webtest.NewForm().File("other", "a.png", png),
+	}
+
+	for name, f := range cases {
+		dir := t.TempDir()
+		res, err := Parse(request(f), avatars(dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Validation.ErrorMap()["avatar"] == nil || len(res.Files) != 0 || len(stored(t, dir)) != 0 {
+			t.Errorf("%s: errors %v, files %v, stored %v", name, res.Validation.Errors, res.Files, stored(t, dir))
+		}
+	}
+}
+
+// A file passing its checks on its head but too large for MaxFileSize is
+// cut off while stored and leaves nothing.
+func TestParseCutsOff(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Storage: NewDiskStorage(dir, ""), MaxFileSize: HeadBytes + 10}
+	f := webtest.NewForm().File("doc", "a.txt", bytes.Repeat([]byte("a"), HeadBytes+100))
+	res, err := Parse(request(f), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Validation.ErrorMap()["doc"] == nil || len(stored(t, dir)) != 0 {
+		t.Errorf("errors %v, stored %v", res.Validation.Errors, stored(t, dir))
+	}
+
+	f = webtest.NewForm().File("doc", "a.txt", bytes.Repeat([]byte("a"), HeadBytes+10))
+	res, err = Parse(request(f), opts)
+	if err != nil || res.Validation.HasErrors() || res.File("doc").Size != HeadBytes+10 {
+		t.Errorf("at the limit: %v, %v", err, res.Validation.Errors)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	dir := t.TempDir()
+	f := webtest.NewForm().File("doc", "a.txt", []byte("a")).File("doc", "b.txt", []byte("b"))
+	res, err := Parse(request(f), Options{Storage: NewDiskStorage(dir, ""), MaxFiles: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := res.Validation.ErrorMap()["doc"]
+	if e == nil || e.Code != "upload.too_many" || len(stored(t, dir)) != 0 {
+		t.Errorf("errors %v, stored %v", res.Validation.Errors, stored(t, dir))
+	}
+
+	f = webtest.NewForm().Set("a", "123").Set("b", "456").File("doc", "a.txt", []byte("a"))
+	if _, err := Parse(request(f), Options{Storage: NewDiskStorage(dir, ""), MaxValueSize: 5}); err == nil {
+		t.Error("no error for values too large")
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader("a=1"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := Parse(r, Options{Storage: NewDiskStorage(dir, "")}); err == nil {
+		t.Error("no error for a request that is not multipart")
+	}
+}
+
+// A Storage failing once it holds max files.
+type memStorage struct {
+	files map[string][]byte
+	max   int
+}
+
+func (m *memStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) (int64, error) {
+	if len(m.files) >= m.max {
+		return 0, errors.New("full")
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	m.files[key] = b
+
+	return int64(len(b)), nil
+}
+
+func (m *memStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m.files[key])), nil
+}
+
+func (m *memStorage) Delete(ctx context.Context, key string) error {
+	delete(m.files, key)
+	return nil
+}
+
+func TestParseStorageFails(t *testing.T) {
+	m := &memStorage{files: map[string][]byte{}, max: 1}
+	f := webtest.NewForm().File("a", "a.txt", []byte("a")).File("b", "b.txt", []byte("b"))
+	if _, err := Parse(request(f), Options{Storage: m}); err == nil || err.Error() != "full" {
+		t.Errorf("error %v", err)
+	}
+
+	if len(m.files) != 0 {
+		t.Errorf("left %v", m.files)
+	}
+}
+
+func TestCleanFilename(t *testing.T) {
+	cases := map[string]string{
+		"a.png":               "a.png",
+		`C:\docs\cv.pdf`:      "cv.pdf",
+		"../../etc/passwd":    "passwd",
+		"bad\x00\r\nname.txt": "badname.txt",
+	}
+
+	for in, want := range cases {
+		if got := cleanFilename(in); got != want {
+			t.Errorf("cleanFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDiskStorage(t *testing.T) {
+	d := NewDiskStorage(t.TempDir(), "")
+	ctx := context.Background()
+	for _, key := range []string{"", "../x", "a/../../x", "/a", `a\b`, "a//b"} {
+		if _, err := d.Put(ctx, key, strings.NewReader("x"), ""); err != ErrBadKey {
+			t.Errorf("Put(%q) error %v", key, err)
+		}
+	}
+
+	if n, err := d.Put(ctx, "a/b/c.txt", strings.NewReader("hello"), "text/plain"); err != nil || n != 5 {
+		t.Fatalf("Put = %d, %v", n, err)
+	}
+
+	if err := d.Delete(ctx, "a/b/c.txt"); err != nil {
+		t.Error(err)
+	}
+
+	if err := d.Delete(ctx, "a/b/c.txt"); err != nil {
+		t.Errorf("Delete of a missing key: %v", err)
+	}
+
+	if d.URL("a.png") != "" || NewDiskStorage("", "/files").URL("a.png") != "/files/a.png" {
+		t.Error("wrong URL")
+	}
+}
@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"context"
+	"golanger.com/framework/cookie"
+	"golanger.com/framework/log"
+	"strconv"
+	"time"
+)
+
+// The hidden form field holding the signed time a form was rendered at,
+// for MinSubmitTime.
+var SubmitTimeField = "form_time"
+
+// Requires the value of a honeypot field, one hidden from people but
+// filled in by bots, to be empty.  FieldName is the name of the field.
+type Honeypot struct {
+	FieldName string
+}
+
+func (h Honeypot) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	return obj == nil || (ok && str == "")
+}
+
+func (h Honeypot) DefaultMessage() string {
+	return "Submission rejected"
+}
+
+// The name of the field would give the trap away.
+func (h Honeypot) Rules() Rule {
+	return Rule{Name: "honeypot"}
+}
+
+// Return the value of SubmitTimeField for a form rendered now, signed with
+// codec, or cookie.Default if nil, so that it cannot be forged.
+func SubmitTimeStamp(codec *cookie.Codec) (string, error) {
+	if codec == nil {
+		codec = cookie.Default
+	}
+
+	return codec.Sign(SubmitTimeField, strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+}
+
+// Requires a stamp of SubmitTimeStamp to be at least Duration old, since
+// people take a few seconds to fill a form in and bots do not, and no
+// older than MaxAge, if set.  It is verified with Codec, or cookie.Default
+// if nil.
+type MinSubmitTime struct {
+	Duration time.Duration
+	MaxAge   time.Duration
+	Codec    *cookie.Codec
+}
+
+func (m MinSubmitTime) IsSatisfied(obj interface{}) bool {
+	return m.IsSatisfiedCtx(context.Background(), obj)
+}
+
+func (m MinSubmitTime) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	stamp, ok := obj.(string)
+	if !ok || stamp == "" {
+		return false
+	}
+
+	codec := m.Codec
+	if codec == nil {
+		codec = cookie.Default
+	}
+
+	value, err := codec.Verify(SubmitTimeField, stamp)
+	if err != nil {
+		if err == cookie.ErrNoKeys {
+			log.FromContext(ctx).Error("<MinSubmitTime.IsSatisfied> ", err)
+		}
+
+		return false
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	elapsed := time.Since(time.Unix(0, ms*int64(time.Millisecond)))
+
+	return elapsed >= m.Duration && (m.MaxAge == 0 || elapsed <= m.MaxAge)
+}
+
+func (m MinSubmitTime) DefaultMessage() string {
+	return "Form submitted too quickly, please try again"
+}
+
+func (m MinSubmitTime) Rules() Rule {
+	return Rule{Name: "min_submit_time"}
+}
+
+func (v *Validation) Honeypot(value, fieldName string) *ValidationResult {
+	return v.apply(Honeypot{fieldName}, value)
+}
+
+func (v *Validation) MinSubmitTime(stamp string, d time.Duration) *ValidationResult {
+	return v.apply(MinSubmitTime{Duration: d}, stamp)
+}
@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"golanger.com/framework/cookie"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHoneypot(t *testing.T) {
+	h := Honeypot{FieldName: "website"}
+	if !h.IsSatisfied("") || !h.IsSatisfied(nil) || h.IsSatisfied("http://spam.example") || h.IsSatisfied(0) {
+		t.Error("Honeypot accepts the wrong values")
+	}
+
+	if h.Rules().Name != "honeypot" || len(h.Rules().Params) != 0 {
+		t.Errorf("rules %+v give the field away", h.Rules())
+	}
+}
+
+func TestMinSubmitTime(t *testing.T) {
+	codec := cookie.New(cookie.Keys{Hash: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}})
+	stamp, err := SubmitTimeStamp(codec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !(MinSubmitTime{Codec: codec}).IsSatisfied(stamp) {
+		t.Error("fresh stamp refused without a minimum")
+	}
+
+	if (MinSubmitTime{Duration: time.Hour, Codec: codec}).IsSatisfied(stamp) {
+		t.Error("fresh stamp accepted with a minimum of an hour")
+	}
+
+	old := strconv.FormatInt(time.Now().Add(-time.Minute).UnixNano()/int64(time.Millisecond), 10)
+	oldStamp, _ := codec.Sign(SubmitTimeField, old)
+	if !(MinSubmitTime{Duration: 3 * time.Second, Codec: codec}).IsSatisfied(oldStamp) {
+		t.Error("minute-old stamp refused")
+	}
+
+	if (MinSubmitTime{Duration: 3 * time.Second, MaxAge: 30 * time.Second, Codec: codec}).IsSatisfied(oldStamp) {
+		t.Error("minute-old stamp accepted beyond MaxAge")
+	}
+
+	forged, _ := cookie.New(cookie.Keys{Hash: [][]byte{[]byte("another key of thirty-two bytes!")}}).Sign(SubmitTimeField, old)
+	for _, bad := range []interface{}{forged, old, "", nil, 42} {
+		if (MinSubmitTime{Codec: codec}).IsSatisfied(bad) {
+			t.Errorf("stamp %v accepted", bad)
+		}
+	}
+
+	if (MinSubmitTime{}).IsSatisfied(stamp) {
+		t.Error("stamp accepted by cookie.Default without keys")
+	}
+}
+
+func TestAntispamTags(t *testing.T) {
+	v := &Validation{}
+	v.ValidateStruct(struct {
+		Website  string `validate:"honeypot"`
+		FormTime string `validate:"mintime=3s"`
+	}{"spam", ""})
+
+	if errors := v.ErrorMap(); errors["Website"] == nil || errors["FormTime"] == nil {
+		t.Errorf("errors %v", errors)
+	}
+}
@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"context"
+	"sync"
+)
+
+type asyncCheck struct {
+	key    string
+	obj    interface{}
+	checks []Validator
+}
+
+// An AsyncRunner collects slow checks, such as uniqueness queries or DNS
+// lookups, and runs them concurrently on a bounded number of goroutines.
+type AsyncRunner struct {
+	validation *Validation
+	ctx        context.Context
+	workers    int
+	checks     []asyncCheck
+}
+
+// Return a runner whose checks get ctx (see CheckCtx) and run at most
+// workers at a time (1 if workers is less).
+func (v *Validation) Async(ctx context.Context, workers int) *AsyncRunner {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &AsyncRunner{
+		validation: v,
+		ctx:        ctx,
+		workers:    workers,
+	}
+}
+
+// Queue the checks of obj, recorded under key if one fails.
+func (r *AsyncRunner) Check(key string, obj interface{}, checks ...Validator) *AsyncRunner {
+	r.checks = append(r.checks, asyncCheck{key, obj, checks})
+	return r
+}
+
+// Run the queued checks and merge their errors into the Validation in the
+// order they were queued, so the result does not depend on timing.
+func (r *AsyncRunner) Wait() *Validation {
+	children := make([]*Validation, len(r.checks))
+	sem := make(chan struct{}, r.workers)
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		children[i] = r.validation.Child()
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(child *Validation, c asyncCheck) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			child.CheckCtx(r.ctx, c.obj, c.checks...).Key(c.key)
+		}(children[i], c)
+	}
+
+	wg.Wait()
+	r.checks = nil
+	for _, child := range children {
+		r.validation.Merge(child)
+	}
+
+	return r.validation
+}
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Sleeps, counting how many are sleeping at once, then fails on "bad".
+type slow struct {
+	mu            *sync.Mutex
+	running, peak *int
+}
+
+func (s slow) IsSatisfied(obj interface{}) bool { return s.IsSatisfiedCtx(context.Background(), obj) }
+func (s slow) DefaultMessage() string           { return "Slow failure" }
+
+func (s slow) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	s.mu.Lock()
+	*s.running++
+	if *s.running > *s.peak {
+		*s.peak = *s.running
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-time.After(10 * time.Millisecond):
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	*s.running--
+	s.mu.Unlock()
+
+	return obj != "bad"
+}
+
+func TestAsync(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	check := slow{&mu, &running, &peak}
+
+	v := &Validation{}
+	r := v.Async(context.Background(), 2)
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		obj := "good"
+		if key == "b" || key == "e" {
+			obj = "bad"
+		}
+
+		r.Check(key, obj, Required{}, check)
+	}
+	r.Check("f", "", Required{}, check)
+
+	if r.Wait() != v {
+		t.Error("Wait returned another Validation")
+	}
+
+	if peak > 2 {
+		t.Errorf("%d checks at once", peak)
+	}
+
+	keys := []string{}
+	for _, e := range v.Errors {
+		keys = append(keys, e.Key)
+	}
+
+	if len(keys) != 3 || keys[0] != "b" || keys[1] != "e" || keys[2] != "f" || v.Errors[0].Message != "Slow failure" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	// The queue is emptied.
+	if r.Wait(); len(v.Errors) != 3 {
+		t.Errorf("errors %v after a second Wait", v.Errors)
+	}
+}
+
+func TestAsyncCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	v := (&Validation{}).Async(ctx, 0).Check("x", "good", Required{}).Wait()
+	if len(v.Errors) != 1 || v.Errors[0].Code != "validation.cancelled" || v.Errors[0].Key != "x" {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
@@ -0,0 +1,365 @@
+package validator
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"golanger.com/framework/log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// One check recorded by an Audit: the rule that ran on the field of key
+// ("" for a check not made under a key), a hash of its input, whether it
+// passed and how long it took.  The input of a sensitive check is not
+// hashed, and Redacted is set instead.
+type AuditEntry struct {
+	Key       string        `json:"key"`
+	Rule      string        `json:"rule"`
+	InputHash string        `json:"input_hash,omitempty"`
+	Redacted  bool          `json:"redacted,omitempty"`
+	Ok        bool          `json:"ok"`
+	Duration  time.Duration `json:"duration"`
+	At        time.Time     `json:"at"`
+}
+
+// An Audit records every check run by the Validations attached to it,
+// passed or failed, as proof of which rules were enforced.  Inputs are
+// only kept as hashes.  It is safe for concurrent use, so that the
+// children of a Validation share it.
+type Audit struct {
+	mutex     sync.Mutex
+	key       []byte
+	sensitive map[string]bool
+	entries   []AuditEntry
+}
+
+// Return an Audit hashing inputs with HMAC-SHA256 under key, a secret of
+// at least 32 random bytes, so that short inputs such as amounts or
+// account numbers cannot be recovered by hashing guesses.  It panics
+// without a key.
+//
+// Whatever the key, the inputs of sensitive checks are never hashed:
+// those of the password, password_not_common, captcha and token rules,
+// those of the fields whose names hold a word such as password, secret,
+// token, pin or cvv, or are tagged `sensitive:"true"`, those of the keys
+// passed to Sensitive, and those of the checks run before their key is
+// known, such as v.Required(pw) keyed afterwards, as their field cannot
+// be told safe.
+func NewAudit(key []byte) *Audit {
+	if len(key) == 0 {
+		panic("validator: NewAudit needs a key")
+	}
+
+	return &Audit{key: key, sensitive: map[string]bool{}}
+}
+
+// Never hash the inputs of the fields of keys, e.g. "Account.IBAN", nor
+// of their elements.
+func (a *Audit) Sensitive(keys ...string) *Audit {
+	a.mutex.Lock()
+	for _, key := range keys {
+		a.sensitive[key] = true
+	}
+	a.mutex.Unlock()
+
+	return a
+}
+
+// The rules whose inputs are secrets.
+var sensitiveRules = map[string]bool{
+	"password":            true,
+	"password_not_common": true,
+	"captcha":             true,
+	"token":               true,
+}
+
+// The words of the names of the fields that hold secrets.
+var sensitiveWords = map[string]bool{
+	"password": true, "passwd": true, "passphrase": true, "secret": true,
+	"token": true, "captcha": true, "otp": true, "pin": true, "cvv": true,
+	"cvc": true, "ssn": true, "pwd": true,
+}
+
+// Report whether the input of rule on the field of key must not be
+// hashed.  The caller holds the mutex.
+func (a *Audit) isSensitive(key, rule string) bool {
+	if key == "" || sensitiveRules[rule] {
+		return true
+	}
+
+	key = keyIndex.ReplaceAllString(key, "")
+	if a.sensitive[key] {
+		return true
+	}
+
+	name := key[strings.LastIndex(key, ".")+1:]
+	for _, word := range nameWords(name) {
+		if sensitiveWords[word] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// The indexes and map keys of element keys such as "Items[2].SKU".
+var keyIndex = regexp.MustCompile(`\[[^\]]*\]`)
+
+// Split a field name into lower case words: NewPassword, new_password and
+// new-password into new and password, and OTPCode into otp and code.
+func nameWords(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	start := 0
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			if i > start {
+				words = append(words, strings.ToLower(string(runes[start:i])))
+			}
+
+			start = i + 1
+		case i > start && unicode.IsUpper(r) &&
+			(unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			words = append(words, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, strings.ToLower(string(runes[start:])))
+	}
+
+	return words
+}
+
+// Return the checks recorded so far, in the order they ran.
+func (a *Audit) Entries() []AuditEntry {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return append([]AuditEntry{}, a.entries...)
+}
+
+// Return the hash of an input: of the string itself, or else of its JSON
+// encoding.
+func (a *Audit) hash(obj interface{}) string {
+	h := hmac.New(sha256.New, a.key)
+
+	switch o := obj.(type) {
+	case string:
+		h.Write([]byte(o))
+	case []byte:
+		h.Write(o)
+	default:
+		b, err := json.Marshal(obj)
+		if err != nil {
+			b = []byte(fmt.Sprintf("%#v", obj))
+		}
+
+		h.Write(b)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (a *Audit) add(key string, chk Validator, obj interface{}, ok bool, start time.Time) {
+	e := AuditEntry{
+		Key:      key,
+		Rule:     strings.TrimPrefix(errorCode(unwrap(chk)), "validation."),
+		Ok:       ok,
+		Duration: time.Since(start),
+		At:       start,
+	}
+
+	a.mutex.Lock()
+	if a.isSensitive(e.Key, e.Rule) {
+		e.Redacted = true
+	} else {
+		e.InputHash = a.hash(obj)
+	}
+
+	a.entries = append(a.entries, e)
+	a.mutex.Unlock()
+}
+
+type auditKey struct{}
+
+// Return a copy of ctx carrying a, which the Validations given the context,
+// by SetContext or WithValidation, record their checks into.
+func NewAuditContext(ctx context.Context, a *Audit) context.Context {
+	return context.WithValue(ctx, auditKey{}, a)
+}
+
+// Return the Audit of ctx, or nil.
+func AuditFromContext(ctx context.Context) *Audit {
+	a, _ := ctx.Value(auditKey{}).(*Audit)
+	return a
+}
+
+// Record the checks of v, and of the children made from now on, into a.
+func (v *Validation) Audit(a *Audit) *Validation {
+	v.audit = a
+
+	return v
+}
+
+// Run chk, recording it into the audit of v, if any.
+func (v *Validation) audited(key string, chk Validator, obj interface{}) bool {
+	if v.audit == nil {
+		return v.satisfied(chk, obj)
+	}
+
+	start := time.Now()
+	ok := v.satisfied(chk, obj)
+	v.audit.add(key, chk, obj, ok, start)
+
+	return ok
+}
+
+// The checks of one request, as kept by an Auditor.
+type AuditRecord struct {
+	RequestID string       `json:"request_id"`
+	Method    string       `json:"method"`
+	Path      string       `json:"path"`
+	At        time.Time    `json:"at"`
+	Entries   []AuditEntry `json:"entries"`
+}
+
+type AuditOptions struct {
+	// The key of the input hashes, see NewAudit.  It is required.
+	Key []byte
+
+	// The keys of the fields whose inputs are never hashed, besides those
+	// NewAudit leaves out, see Audit.Sensitive.
+	Sensitive []string
+
+	// Audit only the requests for which Filter returns true, e.g. the
+	// submissions of financial forms.  Every request is audited if nil.
+	Filter func(r *http.Request) bool
+
+	// Write each check as a structured record at LEVEL_INFO to the logger
+	// of the request, for production.
+	Log bool
+
+	// Keep the checks of the last Keep requests in memory, to be queried
+	// with Recent or Handler in development.  None are kept if zero.
+	Keep int
+}
+
+// An Auditor gives each request an Audit, see Middleware.
+type Auditor struct {
+	opts    AuditOptions
+	mutex   sync.Mutex
+	records []*AuditRecord
+}
+
+// Return an Auditor, which panics without a Key, see NewAudit.
+func NewAuditor(opts AuditOptions) *Auditor {
+	if len(opts.Key) == 0 {
+		panic("validator: NewAuditor needs a Key")
+	}
+
+	return &Auditor{opts: opts}
+}
+
+// Attach an Audit to the context of each request, so that the Validations
+// of its handler record their checks, and log or keep them once the
+// handler returns.  Requests that ran no check are left out.
+func (a *Auditor) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.opts.Filter != nil && !a.opts.Filter(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		audit := NewAudit(a.opts.Key).Sensitive(a.opts.Sensitive...)
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(NewAuditContext(r.Context(), audit)))
+
+		entries := audit.Entries()
+		if len(entries) == 0 {
+			return
+		}
+
+		rec := &AuditRecord{
+			RequestID: log.RequestIDFromContext(r.Context()),
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			At:        start,
+			Entries:   entries,
+		}
+
+		if a.opts.Log {
+			logger := log.FromRequest(r)
+			for _, e := range entries {
+				fields := map[string]interface{}{
+					"method":      rec.Method,
+					"path":        rec.Path,
+					"key":         e.Key,
+					"rule":        e.Rule,
+					"ok":          e.Ok,
+					"duration_us": e.Duration.Microseconds(),
+				}
+
+				if e.Redacted {
+					fields["redacted"] = true
+				} else {
+					fields["input_hash"] = e.InputHash
+				}
+
+				logger.WithFields(fields).Info("<validator.Auditor> validation check")
+			}
+		}
+
+		if a.opts.Keep > 0 {
+			a.mutex.Lock()
+			a.records = append(a.records, rec)
+			if len(a.records) > a.opts.Keep {
+				a.records = a.records[len(a.records)-a.opts.Keep:]
+			}
+			a.mutex.Unlock()
+		}
+	})
+}
+
+// Return the kept records, the most recent first, those of requestID only
+// if it is not "".
+func (a *Auditor) Recent(requestID string) []*AuditRecord {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	records := []*AuditRecord{}
+	for i := len(a.records) - 1; i >= 0; i-- {
+		if requestID == "" || a.records[i].RequestID == requestID {
+			records = append(records, a.records[i])
+		}
+	}
+
+	return records
+}
+
+// Return a handler serving the kept records as JSON, filtered by the
+// request_id query parameter if any, e.g. on /_debug/validations in
+// development.
+func (a *Auditor) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := json.Marshal(a.Recent(r.URL.Query().Get("request_id")))
+		if err != nil {
+			log.FromRequest(r).Error("<validator.Auditor.Handler> ", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Write(b)
+	})
+}
@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var testAuditKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestNewAuditNeedsKey(t *testing.T) {
+	for _, f := range []func(){
+		func() { NewAudit(nil) },
+		func() { NewAudit([]byte{}) },
+		func() { NewAuditor(AuditOptions{}) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic without a key")
+				}
+			}()
+
+			f()
+		}()
+	}
+}
+
+func TestAuditHashesWithKey(t *testing.T) {
+	a := NewAudit(testAuditKey)
+	v := (&Validation{}).Audit(a)
+	v.CheckKey("Email", "a@example.com", Required{}, NewEmail())
+
+	mac := hmac.New(sha256.New, testAuditKey)
+	mac.Write([]byte("a@example.com"))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	entries := a.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("entries %v, want 2", entries)
+	}
+
+	for _, e := range entries {
+		if e.Key != "Email" || !e.Ok || e.Redacted || e.InputHash != want {
+			t.Errorf("entry %+v, want an HMAC of the input", e)
+		}
+	}
+}
+
+type login struct {
+	Login       string `validate:"required"`
+	NewPassword string `validate:"required,min=8"`
+	PIN         string `validate:"len=4"`
+	IBAN        string `validate:"required" sensitive:"true"`
+	Recovery    string `validate:"required"`
+	Shipping    string `validate:"required"`
+}
+
+func TestAuditRedactsSensitive(t *testing.T) {
+	a := NewAudit(testAuditKey).Sensitive("Recovery")
+	v := (&Validation{}).Audit(a)
+	v.ValidateStruct(login{"joe", "hunter22", "1234", "DE89370400440532013000", "words", "home"})
+	v.Password("hunter22", Password{MinLen: 8}).Key("pw")
+	v.Required("secret").Key("Anything")
+
+	hashed := map[string]bool{}
+	for _, e := range a.Entries() {
+		if e.Redacted != (e.InputHash == "") {
+			t.Errorf("entry %+v both or neither redacted and hashed", e)
+		}
+
+		if !e.Redacted {
+			hashed[e.Key] = true
+		}
+	}
+
+	if len(hashed) != 2 || !hashed["Login"] || !hashed["Shipping"] {
+		t.Errorf("hashed the inputs of %v, want only Login and Shipping", hashed)
+	}
+}
+
+func TestNameWords(t *testing.T) {
+	cases := map[string]string{
+		"NewPassword":  "new password",
+		"new_password": "new password",
+		"OTPCode":      "otp code",
+		"card-cvv":     "card cvv",
+		"Shipping":     "shipping",
+	}
+
+	for name, want := range cases {
+		got := ""
+		for i, w := range nameWords(name) {
+			if i > 0 {
+				got += " "
+			}
+
+			got += w
+		}
+
+		if got != want {
+			t.Errorf("nameWords(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAuditorLogsNoSecretHash(t *testing.T) {
+	auditor := NewAuditor(AuditOptions{Key: testAuditKey, Keep: 1})
+	h := auditor.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := (&Validation{}).SetContext(r.Context())
+		v.CheckKey("Password", "hunter22", Required{})
+		v.CheckKey("Name", "Joe", Required{})
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/login", nil))
+	records := auditor.Recent("")
+	if len(records) != 1 || len(records[0].Entries) != 2 {
+		t.Fatalf("records %+v", records)
+	}
+
+	if e := records[0].Entries[0]; !e.Redacted || e.InputHash != "" {
+		t.Errorf("password entry %+v hashed", e)
+	}
+
+	if e := records[0].Entries[1]; e.Redacted || e.InputHash == "" {
+		t.Errorf("name entry %+v not hashed", e)
+	}
+}
@@ -0,0 +1,146 @@
+package validator
+
+import (
+	"regexp"
+)
+
+// A FieldValidation applies checks to one value and records the first
+// failure under the name of its field, so that templates can find it by
+// a stable key:
+//
+//	v.Field("email", email).Required().Email().MaxSize(255).Message("Too long")
+//
+// The checks run in order and those after a failure are skipped.
+type FieldValidation struct {
+	validation *Validation
+	key        string
+	value      interface{}
+
+	// The result of the first failure, and that of the last check
+	// applied, which Message and Code change if it failed.
+	failed *ValidationResult
+	last   *ValidationResult
+}
+
+// Return a FieldValidation of value keying its errors by name.
+func (v *Validation) Field(name string, value interface{}) *FieldValidation {
+	return &FieldValidation{validation: v, key: name, value: value}
+}
+
+// Apply chk unless a previous check failed.
+func (f *FieldValidation) apply(chk Validator) *FieldValidation {
+	if f.failed != nil {
+		f.last = nil
+		return f
+	}
+
+	f.last = f.validation.applyKey(f.key, chk, f.value)
+	if !f.last.Ok {
+		f.failed = f.last
+	}
+
+	return f
+}
+
+func (f *FieldValidation) Required() *FieldValidation {
+	return f.apply(Required{})
+}
+
+func (f *FieldValidation) Min(min int) *FieldValidation {
+	return f.apply(Min{min})
+}
+
+func (f *FieldValidation) Max(max int) *FieldValidation {
+	return f.apply(Max{max})
+}
+
+func (f *FieldValidation) Range(min, max int) *FieldValidation {
+	return f.apply(Range{Min{min}, Max{max}})
+}
+
+func (f *FieldValidation) MinSize(min int) *FieldValidation {
+	return f.apply(MinSize{min})
+}
+
+func (f *FieldValidation) MaxSize(max int) *FieldValidation {
+	return f.apply(MaxSize{max})
+}
+
+func (f *FieldValidation) Length(n int) *FieldValidation {
+	return f.apply(Length{n})
+}
+
+func (f *FieldValidation) GreaterThan(bound interface{}) *FieldValidation {
+	return f.apply(GreaterThan{Value: bound})
+}
+
+func (f *FieldValidation) LessThan(bound interface{}) *FieldValidation {
+	return f.apply(LessThan{Value: bound})
+}
+
+func (f *FieldValidation) Match(regex *regexp.Regexp) *FieldValidation {
+	return f.apply(Match{regex})
+}
+
+func (f *FieldValidation) Email() *FieldValidation {
+	return f.apply(NewEmail())
+}
+
+func (f *FieldValidation) URL() *FieldValidation {
+	return f.apply(URL{})
+}
+
+func (f *FieldValidation) In(values ...string) *FieldValidation {
+	return f.apply(In{Values: values})
+}
+
+func (f *FieldValidation) Password(policy Password) *FieldValidation {
+	f.apply(policy)
+	if str, ok := f.value.(string); ok && f.last != nil {
+		describeProblems(f.last, policy, str)
+	}
+
+	return f
+}
+
+// Apply a group of validators, in order, as Validation.Check does.
+func (f *FieldValidation) Check(checks ...Validator) *FieldValidation {
+	for _, chk := range checks {
+		f.apply(chk)
+	}
+
+	return f
+}
+
+// Replace the message of the error of the check just applied, if it
+// failed, as ValidationResult.Message does.
+func (f *FieldValidation) Message(message string, args ...interface{}) *FieldValidation {
+	if f.last != nil {
+		f.last.Message(message, args...)
+	}
+
+	return f
+}
+
+// Replace the code of the error of the check just applied, if it failed.
+func (f *FieldValidation) Code(code string) *FieldValidation {
+	if f.last != nil {
+		f.last.Code(code)
+	}
+
+	return f
+}
+
+// Report whether every check applied so far passed.
+func (f *FieldValidation) Ok() bool {
+	return f.failed == nil
+}
+
+// Return the result of the check that failed, or a passing result.
+func (f *FieldValidation) Result() *ValidationResult {
+	if f.failed == nil {
+		return &ValidationResult{Ok: true}
+	}
+
+	return f.failed
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFieldKeys(t *testing.T) {
+	v := (&Validation{}).CallerKeys(true)
+	v.Field("email", "nope").Email()
+	v.Field("name", "").Required()
+	v.Field("code", "ab1").Match(regexp.MustCompile(`^[a-z]+$`))
+	v.Field("site", "example.com").URL()
+	v.Field("pw", "short").Password(Password{MinLen: 8})
+	v.Field("count", 5).Range(1, 4)
+	v.Field("ok", "fine").Required().MinSize(2).MaxSize(10).Length(4)
+
+	errs := v.ErrorMap()
+	for _, key := range []string{"email", "name", "code", "site", "pw", "count"} {
+		if errs[key] == nil {
+			t.Errorf("no error for %s in %v", key, v.Errors)
+		}
+	}
+
+	if len(v.Errors) != 6 {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if errs["pw"].Message != "Password must be at least 8 characters long" {
+		t.Errorf("pw: %s", errs["pw"].Message)
+	}
+}
+
+func TestFieldChain(t *testing.T) {
+	v := &Validation{}
+	email := v.Field("email", "nope").Required().Message("Enter an email").Email().Message("Not an email").Code("signup.email").MaxSize(2).Message("Too long")
+	if email.Ok() || len(v.Errors) != 1 {
+		t.Fatalf("errors %v", v.Errors)
+	}
+
+	// Only the failing check is recorded, with its own message and code;
+	// those after it are skipped.
+	if r := email.Result(); r.Ok || r.Error.Key != "email" || r.Error.Message != "Not an email" || r.Error.Code != "signup.email" {
+		t.Errorf("result %+v", r.Error)
+	}
+
+	age := v.Field("age", 30).Range(18, 99).GreaterThan(17).LessThan(100).Min(18).Max(99).Message("unused")
+	if !age.Ok() || !age.Result().Ok || age.Result().Error != nil {
+		t.Errorf("age failed: %v", v.Errors)
+	}
+
+	color := v.Field("color", "pink").In("red", "blue").Check(Required{}, MaxSize{1})
+	if color.Ok() || color.Result().Error.Message != "Must be one of red, blue" || v.ErrorMap()["color"] == nil {
+		t.Errorf("color result %v", color.Result().Error)
+	}
+
+	if len(v.Errors) != 2 {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
@@ -0,0 +1,204 @@
+package validator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A CacheStore keeps the outcomes of checks for WithCache.  Get reports
+// the outcome cached under key and whether there is one.  A store shared
+// by several processes, such as Redis, can be adapted to it.
+type CacheStore interface {
+	Get(key string) (ok bool, found bool)
+	Set(key string, ok bool, ttl time.Duration)
+}
+
+type cacheEntry struct {
+	ok      bool
+	expires time.Time
+}
+
+// A CacheStore in process memory.  Expired outcomes are dropped when
+// looked up or by Purge.
+type MemoryCache struct {
+	mutex   sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: map[string]cacheEntry{},
+	}
+}
+
+func (c *MemoryCache) Get(key string) (bool, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, found := c.entries[key]
+	if !found {
+		return false, false
+	}
+
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return false, false
+	}
+
+	return e.ok, true
+}
+
+func (c *MemoryCache) Set(key string, ok bool, ttl time.Duration) {
+	c.mutex.Lock()
+	c.entries[key] = cacheEntry{ok, time.Now().Add(ttl)}
+	c.mutex.Unlock()
+}
+
+// Drop the expired outcomes.
+func (c *MemoryCache) Purge() {
+	now := time.Now()
+	c.mutex.Lock()
+	for key, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	c.mutex.Unlock()
+}
+
+// A validator whose outcomes are memoized, see WithCache.  It fails with
+// the code, message and rules of the validator it wraps.
+type Cached struct {
+	check Validator
+	store CacheStore
+	ttl   time.Duration
+}
+
+// Return a function wrapping validators so that their outcome for a value
+// is kept in store for ttl, for expensive checks such as MX lookups,
+// uniqueness queries or remote blacklists that would otherwise run again
+// on every retry:
+//
+//	cached := validator.WithCache(validator.NewMemoryCache(), time.Minute)
+//	v.Check(email, cached(validator.Email{VerifyMX: true}))
+//
+// Outcomes are keyed by the name and parameters of the validator and the
+// value, and by the locale of the Validation for validators given it (see
+// LocaleAwareValidator).  Checks whose context ends, and validators given
+// the data of the Validation (see DataAwareValidator), are not cached.
+func WithCache(store CacheStore, ttl time.Duration) func(chk Validator) Validator {
+	return func(chk Validator) Validator {
+		c := Cached{check: chk, store: store, ttl: ttl}
+		if _, ok := chk.(DataAwareValidator); ok {
+			return cachedData{c}
+		}
+
+		if _, ok := chk.(LocaleAwareValidator); ok {
+			return cachedLocale{c}
+		}
+
+		return c
+	}
+}
+
+// Return the validator wrapped.
+func (c Cached) Unwrap() Validator {
+	return c.check
+}
+
+// Return the cache key of a value checked in a locale: the name of the
+// validator, then a hash of its parameters, the value and the locale.
+func (c Cached) key(obj interface{}, locale string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%#v\x00%T\x00%#v\x00%s", c.check, obj, obj, locale)
+
+	return validatorName(c.check) + ":" + hex.EncodeToString(h.Sum(nil))
+}
+
+func (c Cached) IsSatisfied(obj interface{}) bool {
+	return c.IsSatisfiedCtx(context.Background(), obj)
+}
+
+func (c Cached) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	key := c.key(obj, "")
+	if ok, found := c.store.Get(key); found {
+		return ok
+	}
+
+	var ok bool
+	if cchk, isCtx := c.check.(ValidatorCtx); isCtx {
+		ok = cchk.IsSatisfiedCtx(ctx, obj)
+	} else {
+		ok = c.check.IsSatisfied(obj)
+	}
+
+	if ctx.Err() == nil {
+		c.store.Set(key, ok, c.ttl)
+	}
+
+	return ok
+}
+
+// A Cached data-aware validator, which is checked uncached.  Cached itself
+// is not data-aware, for the Validation to check it with its context.
+type cachedData struct {
+	Cached
+}
+
+func (c cachedData) IsSatisfiedData(obj interface{}, data map[string]interface{}) bool {
+	return c.check.(DataAwareValidator).IsSatisfiedData(obj, data)
+}
+
+// A Cached locale-aware validator, whose outcomes are kept by locale.
+type cachedLocale struct {
+	Cached
+}
+
+func (c cachedLocale) IsSatisfied(obj interface{}) bool {
+	return c.IsSatisfiedLocale(obj, "")
+}
+
+func (c cachedLocale) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	return c.IsSatisfiedLocale(obj, "")
+}
+
+func (c cachedLocale) IsSatisfiedLocale(obj interface{}, locale string) bool {
+	key := c.key(obj, locale)
+	if ok, found := c.store.Get(key); found {
+		return ok
+	}
+
+	ok := c.check.(LocaleAwareValidator).IsSatisfiedLocale(obj, locale)
+	c.store.Set(key, ok, c.ttl)
+
+	return ok
+}
+
+func (c Cached) DefaultMessage() string {
+	return c.check.DefaultMessage()
+}
+
+func (c Cached) ErrorCode() string {
+	return errorCode(c.check)
+}
+
+func (c Cached) Rules() Rule {
+	return DescribeRule(c.check)
+}
+
+// Return the innermost validator of chk, which wrappers such as Cached
+// describe themselves by.
+func unwrap(chk Validator) Validator {
+	for {
+		u, ok := chk.(interface{ Unwrap() Validator })
+		if !ok {
+			return chk
+		}
+
+		chk = u.Unwrap()
+	}
+}
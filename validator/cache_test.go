@@ -0,0 +1,140 @@
+package validator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Requires a number of at least min, counting its calls.
+type costly struct {
+	Min   int
+	Calls *int
+}
+
+func (c costly) IsSatisfied(obj interface{}) bool {
+	*c.Calls++
+
+	return obj.(int) >= c.Min
+}
+
+func (c costly) DefaultMessage() string { return "Must be at least {Min}" }
+
+// Requires its context to be live, counting its calls.
+type live struct{ calls *int }
+
+func (l live) IsSatisfied(obj interface{}) bool { return l.IsSatisfiedCtx(context.Background(), obj) }
+func (l live) DefaultMessage() string           { return "Context ended" }
+
+func (l live) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	*l.calls++
+
+	return ctx.Err() == nil
+}
+
+func TestWithCache(t *testing.T) {
+	calls := 0
+	cached := WithCache(NewMemoryCache(), time.Minute)
+	atLeast3 := cached(costly{3, &calls})
+
+	for _, n := range []int{5, 5, 1, 1, 5} {
+		if got := atLeast3.IsSatisfied(n); got != (n >= 3) {
+			t.Errorf("IsSatisfied(%d) = %v", n, got)
+		}
+	}
+
+	if calls != 2 {
+		t.Errorf("%d calls for two values", calls)
+	}
+
+	// The parameters of the validator are part of the key.
+	if cached(costly{6, &calls}).IsSatisfied(5) || calls != 3 {
+		t.Errorf("outcome of min 3 reused for min 6, %d calls", calls)
+	}
+
+	v := &Validation{}
+	r := v.Check(1, atLeast3).Key("n")
+	if r.Ok || r.Error.Message != "Must be at least 3" || r.Error.Code != "validation.costly" || calls != 3 {
+		t.Errorf("error %+v after %d calls", r.Error, calls)
+	}
+
+	if rule := DescribeRule(atLeast3); rule.Name != "costly" || rule.Params["min"] != 3 {
+		t.Errorf("rule %+v", rule)
+	}
+
+	if attrs := HTML5Attrs(cached(Required{}), cached(MaxSize{8})); attrs["required"] != "required" || attrs["maxlength"] != "8" {
+		t.Errorf("attrs %v", attrs)
+	}
+}
+
+func TestCacheContext(t *testing.T) {
+	calls := 0
+	check := WithCache(NewMemoryCache(), time.Minute)(live{&calls})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The outcome under an ended context is not kept, and the context of
+	// the Validation is the one checked with.
+	v := (&Validation{}).SetContext(ctx)
+	if v.Check("x", check).Ok || v.Check("x", check).Ok || calls != 2 {
+		t.Errorf("satisfied under an ended context, %d calls", calls)
+	}
+
+	if !(&Validation{}).Check("x", check).Ok || !check.IsSatisfied("x") || calls != 3 {
+		t.Errorf("%d calls", calls)
+	}
+
+	// Data-aware validators are not cached.
+	data := WithCache(NewMemoryCache(), time.Minute)(freeSlug{})
+	v = (&Validation{}).WithData("tenant", "acme")
+	if !v.Check("home", data).Ok {
+		t.Error("data not given to a cached data-aware validator")
+	}
+
+	v.WithData("taken", map[string]bool{"home": true})
+	if v.Check("home", data).Ok {
+		t.Error("data-aware outcome cached")
+	}
+}
+
+func TestCacheLocale(t *testing.T) {
+	check := WithCache(NewMemoryCache(), time.Minute)(Numeric{})
+
+	// The outcome in one locale is not the answer in another.
+	de, en := (&Validation{}).SetLocale("de"), (&Validation{}).SetLocale("en")
+	for i := 0; i < 2; i++ {
+		if !de.Check("1,5", check).Ok || en.Check("1,5", check).Ok || check.IsSatisfied("1,5") {
+			t.Errorf("wrong answers for 1,5 in round %d", i)
+		}
+	}
+
+	if !en.Check("1.5", check).Ok || de.Check("1.5", check).Ok {
+		t.Error("wrong answers for 1.5")
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	c := NewMemoryCache()
+	c.Set("a", true, time.Minute)
+	c.Set("b", false, time.Minute)
+	c.Set("old", true, -time.Second)
+
+	if ok, found := c.Get("a"); !ok || !found {
+		t.Errorf("a = %v, %v", ok, found)
+	}
+
+	if ok, found := c.Get("b"); ok || !found {
+		t.Errorf("b = %v, %v", ok, found)
+	}
+
+	c.Set("older", true, -time.Second)
+	if _, found := c.Get("old"); found || len(c.entries) != 3 {
+		t.Errorf("expired outcome found, %d entries", len(c.entries))
+	}
+
+	c.Purge()
+	if len(c.entries) != 2 {
+		t.Errorf("%d entries after Purge", len(c.entries))
+	}
+}
@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"context"
+	"golanger.com/framework/log"
+)
+
+// A CaptchaVerifier checks the token a client got by solving a captcha,
+// e.g. with the siteverify API of its provider.  The captcha package has
+// verifiers for reCAPTCHA, hCaptcha and self-hosted image captchas.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token, remoteIP string) (bool, error)
+}
+
+// The CaptchaVerifier of the Captcha validators that have none of their
+// own, such as those built by Validation.Captcha.
+var DefaultCaptcha CaptchaVerifier
+
+// Requires a string to be the token of a solved captcha, checked by
+// Verifier, or else DefaultCaptcha, for a client at RemoteIP, which may be
+// empty.  A failed verification is logged and treated as unsolved.
+type Captcha struct {
+	RemoteIP string
+	Verifier CaptchaVerifier
+}
+
+func (c Captcha) IsSatisfied(obj interface{}) bool {
+	return c.IsSatisfiedCtx(context.Background(), obj)
+}
+
+func (c Captcha) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	token, ok := obj.(string)
+	if !ok || token == "" {
+		return false
+	}
+
+	verifier := c.Verifier
+	if verifier == nil {
+		verifier = DefaultCaptcha
+	}
+
+	if verifier == nil {
+		log.FromContext(ctx).Error("<Captcha.IsSatisfied> ", "no CaptchaVerifier")
+		return false
+	}
+
+	solved, err := verifier.Verify(ctx, token, c.RemoteIP)
+	if err != nil {
+		log.FromContext(ctx).Error("<Captcha.IsSatisfied> ", err)
+		return false
+	}
+
+	return solved
+}
+
+func (c Captcha) DefaultMessage() string {
+	return "Please prove you are not a robot"
+}
+
+// The token and address are of no use to a client.
+func (c Captcha) Rules() Rule {
+	return Rule{Name: "captcha"}
+}
+
+// Check a captcha token with DefaultCaptcha, e.g.
+// v.Captcha(captcha.Token(r), request.RealIP(r)).Key("captcha").
+func (v *Validation) Captcha(token, remoteIP string) *ValidationResult {
+	return v.apply(Captcha{RemoteIP: remoteIP}, token)
+}
@@ -0,0 +1,144 @@
+package validator
+
+import (
+	"strconv"
+	"strings"
+)
+
+const (
+	BrandVisa       = "visa"
+	BrandMastercard = "mastercard"
+	BrandAmex       = "amex"
+)
+
+// Remove the spaces and dashes a card number is commonly written with.
+func cardDigits(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+func luhnValid(digits string) bool {
+	if len(digits) < 2 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
+
+// Return the brand of a card number by its prefix and length, or "" if it
+// is not a Visa, Mastercard or American Express number.  It does not check
+// the Luhn digit.
+func CardBrand(number string) string {
+	digits := cardDigits(number)
+	n := len(digits)
+	prefix := func(size int) int {
+		if n < size {
+			return -1
+		}
+
+		p, err := strconv.Atoi(digits[:size])
+		if err != nil {
+			return -1
+		}
+
+		return p
+	}
+
+	switch {
+	case strings.HasPrefix(digits, "4") && (n == 13 || n == 16 || n == 19):
+		return BrandVisa
+	case n == 16 && ((prefix(2) >= 51 && prefix(2) <= 55) || (prefix(4) >= 2221 && prefix(4) <= 2720)):
+		return BrandMastercard
+	case n == 15 && (prefix(2) == 34 || prefix(2) == 37):
+		return BrandAmex
+	}
+
+	return ""
+}
+
+// Requires a string of digits, optionally grouped with spaces or dashes,
+// to pass the Luhn checksum.
+type Luhn struct{}
+
+func (l Luhn) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+
+	return ok && luhnValid(cardDigits(str))
+}
+
+func (l Luhn) DefaultMessage() string {
+	return "Must be a valid number"
+}
+
+// Requires a string to be a Visa, Mastercard or American Express card
+// number with a valid Luhn digit, and to be one of Brands if any are given.
+type CreditCard struct {
+	Brands []string
+}
+
+func (c CreditCard) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok || !luhnValid(cardDigits(str)) {
+		return false
+	}
+
+	brand := CardBrand(str)
+	if brand == "" {
+		return false
+	}
+
+	if len(c.Brands) == 0 {
+		return true
+	}
+
+	for _, b := range c.Brands {
+		if b == brand {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c CreditCard) DefaultMessage() string {
+	return "Must be a valid credit card number"
+}
+
+// A CardResult is a ValidationResult that also reports the detected brand.
+type CardResult struct {
+	*ValidationResult
+	Brand string
+}
+
+func (v *Validation) CreditCard(number string, brands ...string) *CardResult {
+	return &CardResult{
+		ValidationResult: v.apply(CreditCard{brands}, number),
+		Brand:            CardBrand(number),
+	}
+}
+
+func (v *Validation) Luhn(number string) *CardResult {
+	return &CardResult{
+		ValidationResult: v.apply(Luhn{}, number),
+		Brand:            CardBrand(number),
+	}
+}
@@ -0,0 +1,62 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestCardBrand(t *testing.T) {
+	cases := map[string]string{
+		"4111 1111 1111 1111": BrandVisa,
+		"4222222222222":       BrandVisa,
+		"5555-5555-5555-4444": BrandMastercard,
+		"2223003122003222":    BrandMastercard,
+		"2721000000000000":    "",
+		"378282246310005":     BrandAmex,
+		"3530111333300000":    "",
+		"4111":                "",
+		"":                    "",
+	}
+
+	for number, want := range cases {
+		if got := CardBrand(number); got != want {
+			t.Errorf("CardBrand(%q) = %q, want %q", number, got, want)
+		}
+	}
+}
+
+func TestCards(t *testing.T) {
+	cases := []struct {
+		check  Validator
+		number string
+		want   bool
+	}{
+		{Luhn{}, "79927398713", true},
+		{Luhn{}, "7992 7398 710", false},
+		{Luhn{}, "0", false},
+		{Luhn{}, "4111x111", false},
+		{CreditCard{}, "4111-1111-1111-1111", true},
+		{CreditCard{}, "4111-1111-1111-1112", false},
+		{CreditCard{}, "3530111333300000", false},
+		{CreditCard{[]string{BrandAmex}}, "378282246310005", true},
+		{CreditCard{[]string{BrandAmex}}, "5555555555554444", false},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.number); got != c.want {
+			t.Errorf("%T%v on %q = %v", c.check, c.check, c.number, got)
+		}
+	}
+
+	v := &Validation{}
+	if r := v.CreditCard("5555 5555 5555 4444", BrandVisa); r.Ok || r.Brand != BrandMastercard {
+		t.Errorf("CreditCard = %v, %s", r.Ok, r.Brand)
+	}
+
+	if r := v.Luhn("4111111111111111"); !r.Ok || r.Brand != BrandVisa {
+		t.Errorf("Luhn = %v, %s", r.Ok, r.Brand)
+	}
+
+	if r := v.CreditCard("4111111111111112").Key("card"); r.Ok || r.Error.Message != "Must be a valid credit card number" {
+		t.Errorf("CreditCard = %v", r.Error)
+	}
+}
@@ -0,0 +1,39 @@
+package validator
+
+import (
+	"unicode"
+)
+
+// A validator may implement ErrorCoder to choose the code of its errors.
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
+// Return the stable, machine-readable code of a failed check: its
+// ErrorCode, or else "validation." followed by the validator type name in
+// snake case, such as "validation.required" or "validation.min_size".
+func errorCode(chk Validator) string {
+	if c, ok := chk.(ErrorCoder); ok {
+		return c.ErrorCode()
+	}
+
+	name := []rune(validatorName(chk))
+	code := []rune("validation.")
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			// Start a word at an upper case letter after a lower case one, or
+			// at the last capital of an acronym followed by a word, so that
+			// CSSColor is css_color but IPv4 stays ipv4.
+			if i > 0 && (unicode.IsLower(name[i-1]) || unicode.IsDigit(name[i-1]) ||
+				(unicode.IsUpper(name[i-1]) && i+2 < len(name) && unicode.IsLower(name[i+1]) && unicode.IsLower(name[i+2]))) {
+				code = append(code, '_')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		code = append(code, r)
+	}
+
+	return string(code)
+}
@@ -0,0 +1,40 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	cases := []struct {
+		check Validator
+		want  string
+	}{
+		{Required{}, "validation.required"},
+		{MinSize{}, "validation.min_size"},
+		{CSSColor{}, "validation.css_color"},
+		{IPv4{}, "validation.ipv4"},
+		{JWTStructure{}, "validation.jwt_structure"},
+		{MinInt64{}, "validation.min_int64"},
+		{&Password{}, "validation.password"},
+		{shout{}, "validation.test_shout"},
+	}
+
+	for _, c := range cases {
+		if got := errorCode(c.check); got != c.want {
+			t.Errorf("errorCode(%T) = %s, want %s", c.check, got, c.want)
+		}
+	}
+
+	v := &Validation{}
+	if r := v.URL("nope").Key("site"); r.Error.Code != "validation.url" {
+		t.Errorf("code %s", r.Error.Code)
+	}
+
+	if r := v.URL("nope").Key("site").Code("site.invalid"); r.Error.Code != "site.invalid" {
+		t.Errorf("code %s after Code", r.Error.Code)
+	}
+
+	if r := v.URL("https://example.com").Code("ignored"); !r.Ok || r.Error != nil {
+		t.Errorf("Code on a passed check: %v", r.Error)
+	}
+}
@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestCSSColor(t *testing.T) {
+	cases := map[string]bool{
+		"#fff":                      true,
+		"#FFFA":                     true,
+		"#00ff00":                   true,
+		"#00ff0080":                 true,
+		"#00ff0":                    false,
+		"#ggg":                      false,
+		"RebeccaPurple":             true,
+		" transparent ":             true,
+		"currentColor":              true,
+		"notacolor":                 false,
+		"rgb(255, 0, 0)":            true,
+		"rgba(255,0,0,.5)":          true,
+		"rgb(100%, 0%, 0%)":         true,
+		"rgba(255, 0, 0, 1.5)":      false,
+		"rgb(255, 0)":               false,
+		"hsl(120deg, 100%, 50%)":    true,
+		"hsla(120, 100%, 50%, 0.3)": true,
+		"hsl(120, 100, 50)":         false,
+		"":                          false,
+	}
+
+	for str, want := range cases {
+		if got := (CSSColor{}).IsSatisfied(str); got != want {
+			t.Errorf("CSSColor on %q = %v", str, got)
+		}
+	}
+
+	if (CSSColor{}).IsSatisfied(0xffffff) {
+		t.Error("an int is a color")
+	}
+
+	v := &Validation{}
+	if v.CSSColor("#abc").Ok != true || v.CSSColor("blurple").Key("color").Ok || len(v.Errors) != 1 {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
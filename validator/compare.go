@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Compare a with b, both numbers of any kind, strings or times, and return
+// -1, 0 or 1, or false if they cannot be compared (such as NaN or a string
+// with a number).
+func compareValues(a, b interface{}) (int, bool) {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		if !ok {
+			return 0, false
+		}
+
+		return order(ta.Before(tb), ta.After(tb)), true
+	}
+
+	// Integers of the same signedness compare exactly, others as floats.
+	if ia, ok := toInt64(a); ok {
+		if ib, ok := toInt64(b); ok {
+			return order(ia < ib, ia > ib), true
+		}
+	}
+
+	if ua, ok := toUint64(a); ok {
+		if ub, ok := toUint64(b); ok {
+			return order(ua < ub, ua > ub), true
+		}
+	}
+
+	if fa, ok := toFloat64(a); ok {
+		fb, ok := toFloat64(b)
+		if !ok || math.IsNaN(fa) || math.IsNaN(fb) {
+			return 0, false
+		}
+
+		return order(fa < fb, fa > fb), true
+	}
+
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if ra.Kind() == reflect.String && rb.Kind() == reflect.String {
+		return strings.Compare(ra.String(), rb.String()), true
+	}
+
+	return 0, false
+}
+
+func order(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+
+	return 0
+}
+
+// Describe a bound: the other field if there is one, or else the value,
+// times in RFC 3339.
+func describeBound(value interface{}, field string) string {
+	if field != "" {
+		return field
+	}
+
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// Requires a number, string or time to be strictly greater than Value
+// (later, for times), typically that of another field named Field, e.g.
+// an end date after the start date.  Strings compare byte-wise.
+type GreaterThan struct {
+	Value interface{}
+	Field string
+}
+
+func (g GreaterThan) IsSatisfied(obj interface{}) bool {
+	c, ok := compareValues(obj, g.Value)
+	return ok && c > 0
+}
+
+func (g GreaterThan) DefaultMessage() string {
+	if _, ok := g.Value.(time.Time); ok {
+		return "Must be after " + describeBound(g.Value, g.Field)
+	}
+
+	return "Must be greater than " + describeBound(g.Value, g.Field)
+}
+
+// Requires a number, string or time to be strictly less than Value
+// (earlier, for times), typically that of another field named Field.
+type LessThan struct {
+	Value interface{}
+	Field string
+}
+
+func (l LessThan) IsSatisfied(obj interface{}) bool {
+	c, ok := compareValues(obj, l.Value)
+	return ok && c < 0
+}
+
+func (l LessThan) DefaultMessage() string {
+	if _, ok := l.Value.(time.Time); ok {
+		return "Must be before " + describeBound(l.Value, l.Field)
+	}
+
+	return "Must be less than " + describeBound(l.Value, l.Field)
+}
+
+func (v *Validation) GreaterThan(obj, value interface{}) *ValidationResult {
+	return v.apply(GreaterThan{Value: value}, obj)
+}
+
+func (v *Validation) LessThan(obj, value interface{}) *ValidationResult {
+	return v.apply(LessThan{Value: value}, obj)
+}
@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompareValues(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		a, b interface{}
+		want int
+		ok   bool
+	}{
+		{int8(3), int64(4), -1, true},
+		{uint(5), uint64(5), 0, true},
+		{int64(math.MaxInt64), int64(math.MaxInt64 - 1), 1, true},
+		{-1, uint(1), -1, true},
+		{2.5, 2, 1, true},
+		{math.NaN(), 1.0, 0, false},
+		{"b", "a", 1, true},
+		{"1", 1, 0, false},
+		{t0, t0.Add(time.Second), -1, true},
+		{t0, "2024-05-01", 0, false},
+		{nil, 1, 0, false},
+	}
+
+	for _, c := range cases {
+		if got, ok := compareValues(c.a, c.b); got != c.want || ok != c.ok {
+			t.Errorf("compareValues(%v, %v) = %d, %v", c.a, c.b, got, ok)
+		}
+	}
+}
+
+func TestGreaterAndLess(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	v := &Validation{}
+	v.GreaterThan(1, 0).Key("pos")
+	v.GreaterThan(0, 0).Key("zero")
+	v.LessThan(t0, t0).Key("before")
+	v.LessThan(2.5, 3).Key("small")
+	v.GreaterThan("1", 0).Key("string")
+
+	errs := v.ErrorMap()
+	if len(errs) != 3 || errs["zero"].Message != "Must be greater than 0" || errs["before"].Message != "Must be before 2024-05-01T00:00:00Z" || errs["string"] == nil {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if msg := (GreaterThan{t0, "Start"}).DefaultMessage(); msg != "Must be after Start" {
+		t.Errorf("message %q", msg)
+	}
+
+	if msg := (LessThan{10, "Limit"}).DefaultMessage(); msg != "Must be less than Limit" {
+		t.Errorf("message %q", msg)
+	}
+
+	cases := []struct {
+		check Validator
+		want  Rule
+	}{
+		{GreaterThan{Value: 0}, Rule{"greater_than", map[string]interface{}{"value": 0}}},
+		{LessThan{Value: 9.5}, Rule{"less_than", map[string]interface{}{"value": 9.5}}},
+		{LessThan{3, "Limit"}, Rule{"less_than", map[string]interface{}{"field": "Limit"}}},
+		{fieldRef{"Start", "gtfield"}, Rule{"greater_than", map[string]interface{}{"field": "Start"}}},
+		{fieldRef{"Limit", "ltfield"}, Rule{"less_than", map[string]interface{}{"field": "Limit"}}},
+	}
+
+	for _, c := range cases {
+		if got := DescribeRule(c.check); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("DescribeRule(%#v) = %+v", c.check, got)
+		}
+	}
+}
+
+func TestBoundTags(t *testing.T) {
+	type booking struct {
+		Guests int       `validate:"gt=0,lt=10"`
+		Rooms  uint8     `validate:"lt=4"`
+		Price  float64   `validate:"gt=0.5"`
+		Code   string    `validate:"gt=2,lt=6"`
+		Tags   []string  `validate:"lt=2"`
+		Start  time.Time `validate:"gt=2024-01-01"`
+		End    time.Time `validate:"gtfield=Start,lt=2030-01-01T00:00:00Z"`
+		Count  int64     `validate:"ltfield=Limit"`
+		Limit  int
+	}
+
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	ok := booking{Guests: 2, Rooms: 3, Price: 1, Code: "abc", Tags: []string{"x"}, Start: start, End: start.Add(time.Hour), Count: 4, Limit: 5}
+	if v := (&Validation{}).ValidateStruct(ok); v.HasErrors() {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	bad := booking{Guests: 10, Rooms: 4, Price: 0.5, Code: "ab", Tags: []string{"x", "y"}, Start: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Count: 5, Limit: 5}
+	v := (&Validation{}).ValidateStruct(bad)
+	errs := v.ErrorMap()
+	if len(errs) != 8 || errs["End"].Message != "Must be after Start" || errs["Count"].Message != "Must be less than Limit" || errs["Code"].Message != "Minimum size is 3" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	invalid := []struct {
+		name, param string
+		typ         reflect.Type
+	}{
+		{"gt", "x", reflect.TypeOf(0)},
+		{"lt", "-1", reflect.TypeOf(uint(0))},
+		{"gt", "soon", reflect.TypeOf(time.Time{})},
+		{"lt", "1", reflect.TypeOf(struct{}{})},
+	}
+
+	for _, c := range invalid {
+		if chk := tagValidator(c.name, c.param, c.typ); chk != nil {
+			t.Errorf("%s=%s on %v = %#v", c.name, c.param, c.typ, chk)
+		}
+	}
+
+	s := SchemaFor(booking{})
+	if p := s.Properties["Guests"]; *p.ExclusiveMinimum != 0 || *p.ExclusiveMaximum != 10 || s.Properties["End"].ExclusiveMinimum != nil {
+		t.Errorf("schema %+v", p)
+	}
+}
@@ -0,0 +1,47 @@
+package validator
+
+// A ConditionalValidation applies its checks only when its condition holds.
+// Skipped checks record nothing and report Ok.
+type ConditionalValidation struct {
+	validation *Validation
+	cond       bool
+}
+
+// Return a ConditionalValidation whose checks apply only if cond is true,
+// e.g. v.When(country == "US").Required(state).
+func (v *Validation) When(cond bool) *ConditionalValidation {
+	return &ConditionalValidation{v, cond}
+}
+
+func (c *ConditionalValidation) Required(obj interface{}) *ValidationResult {
+	if !c.cond {
+		return &ValidationResult{Ok: true}
+	}
+
+	return c.validation.applyCaller(Required{}, obj, 2)
+}
+
+func (c *ConditionalValidation) Check(obj interface{}, checks ...Validator) *ValidationResult {
+	result := &ValidationResult{Ok: true}
+	if !c.cond {
+		return result
+	}
+
+	for _, check := range checks {
+		result = c.validation.applyCaller(check, obj, 2)
+		if !result.Ok {
+			return result
+		}
+	}
+
+	return result
+}
+
+// Test that obj is present whenever other is present.
+func (v *Validation) RequiredIf(obj, other interface{}) *ValidationResult {
+	if !(Required{}).IsSatisfied(other) {
+		return &ValidationResult{Ok: true}
+	}
+
+	return v.apply(Required{}, obj)
+}
@@ -0,0 +1,54 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWhen(t *testing.T) {
+	v := &Validation{}
+	v.When(false).Required("").Key("skipped")
+	v.When(false).Check("", Required{}).Key("skipped check")
+	v.When(true).Required("").Key("state")
+	if r := v.When(true).Check("x", Required{}, MinSize{2}).Key("zip"); r.Ok || r.Error.Message != "Minimum size is 2" {
+		t.Errorf("Check = %v", r.Error)
+	}
+
+	if !v.When(true).Check("xy", Required{}, MinSize{2}).Ok {
+		t.Error("passing checks failed")
+	}
+
+	errs := v.ErrorMap()
+	if len(errs) != 2 || errs["state"] == nil || errs["zip"] == nil {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	v := &Validation{}
+	v.RequiredIf("", "").Key("neither")
+	v.RequiredIf("x", "y").Key("both")
+	v.RequiredIf("", 0).Key("zero")
+	v.RequiredIf("", []string{"a"}).Key("missing")
+
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["missing"].Message != "Required" {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestConditionalCallerKeys(t *testing.T) {
+	v := (&Validation{}).CallerKeys(true)
+	v.When(true).Required("")
+	v.When(true).Check("", Required{})
+	v.RequiredIf("", "x")
+
+	for _, e := range v.Errors {
+		if !strings.HasPrefix(e.Key, "golanger.com/framework/validator.TestConditionalCallerKeys#") {
+			t.Errorf("keyed by %s", e.Key)
+		}
+	}
+
+	if len(v.Errors) != 3 {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
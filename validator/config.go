@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"fmt"
+	"golanger.com/framework/config"
+	"regexp"
+)
+
+// The strictness of the default pattern of Email, see Config.
+const (
+	EmailBuiltin = ""
+	EmailStrict  = "strict"
+	EmailLenient = "lenient"
+)
+
+var (
+	strictEmailPattern  = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+/=?^_`{|}~-]+(?:\\.[A-Za-z0-9!#$%&'*+/=?^_`{|}~-]+)*@(?:[A-Za-z0-9](?:[A-Za-z0-9-]{0,61}[A-Za-z0-9])?\\.)+[A-Za-z]{2,63}$")
+	lenientEmailPattern = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+)
+
+// The framework-wide validation policy, set once at start-up with
+// Configure instead of at every call site.
+type Config struct {
+	// A directory of message files to load with LoadMessages, if set.
+	MessagesDir string
+
+	// The values of MaxBodyBytes and MaxMatchInput, left as they are if
+	// zero.
+	MaxBodyBytes  int64
+	MaxMatchInput int
+
+	// The pattern of Email validators that set none: EmailStrict only
+	// takes ASCII addresses whose domain labels are at most 63 characters
+	// and whose top-level domain is letters, EmailLenient anything of the
+	// form a@b.c, and EmailBuiltin the pattern of NewEmail.
+	Email string
+
+	// The defaults of CallerKeys and StopOnError for every Validation.
+	CallerKeys  bool
+	StopOnError bool
+}
+
+// The defaults set by Configure.
+var defaults struct {
+	email       *regexp.Regexp
+	callerKeys  bool
+	stopOnError bool
+}
+
+// Read a Config from the "validator." keys of c: messages_dir,
+// max_body_bytes, max_match_input, email, caller_keys and stop_on_error,
+// e.g. APP_VALIDATOR_STOP_ON_ERROR=1.
+func ConfigFrom(c *config.Config) Config {
+	return Config{
+		MessagesDir:   c.String("validator.messages_dir", ""),
+		MaxBodyBytes:  c.Int64("validator.max_body_bytes", 0),
+		MaxMatchInput: c.Int("validator.max_match_input", 0),
+		Email:         c.String("validator.email", EmailBuiltin),
+		CallerKeys:    c.Bool("validator.caller_keys", false),
+		StopOnError:   c.Bool("validator.stop_on_error", false),
+	}
+}
+
+// Apply c.  Call it once, before validating anything, as the defaults are
+// not guarded for concurrent use.
+func Configure(c Config) error {
+	var email *regexp.Regexp
+	switch c.Email {
+	case EmailBuiltin:
+	case EmailStrict:
+		email = strictEmailPattern
+	case EmailLenient:
+		email = lenientEmailPattern
+	default:
+		return fmt.Errorf("validator: unknown email strictness %q", c.Email)
+	}
+
+	if c.MessagesDir != "" {
+		if err := LoadMessages(c.MessagesDir); err != nil {
+			return err
+		}
+	}
+
+	if c.MaxBodyBytes > 0 {
+		MaxBodyBytes = c.MaxBodyBytes
+	}
+
+	if c.MaxMatchInput > 0 {
+		MaxMatchInput = c.MaxMatchInput
+	}
+
+	defaults.email = email
+	defaults.callerKeys = c.CallerKeys
+	defaults.stopOnError = c.StopOnError
+
+	return nil
+}
+
+// Return the pattern of Email validators that set none.
+func defaultEmailPattern() *regexp.Regexp {
+	if defaults.email != nil {
+		return defaults.email
+	}
+
+	return emailPattern
+}
+
+// Let config.Validate check settings structs by their `validate` tags,
+// reporting every invalid field.
+func init() {
+	config.SetStructChecker(func(obj interface{}) []config.FieldError {
+		v := (&Validation{}).StopOnError(false)
+		v.ValidateStruct(obj)
+		errs := []config.FieldError{}
+		for _, e := range v.Errors {
+			errs = append(errs, config.FieldError{Field: e.Key, Message: e.Message})
+		}
+
+		return errs
+	})
+}
@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"golanger.com/framework/config"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// Restore the settings Configure changes once a test is done.
+func keepDefaults(t *testing.T) {
+	saved, maxBody, maxMatch := defaults, MaxBodyBytes, MaxMatchInput
+	t.Cleanup(func() {
+		defaults, MaxBodyBytes, MaxMatchInput = saved, maxBody, maxMatch
+	})
+}
+
+func TestConfigFrom(t *testing.T) {
+	c := config.New("TEST")
+	c.Set("validator.max_body_bytes", 2048)
+	c.Set("validator.email", "strict")
+	c.Set("validator.stop_on_error", true)
+
+	got := ConfigFrom(c)
+	want := Config{MaxBodyBytes: 2048, Email: EmailStrict, StopOnError: true}
+	if got != want {
+		t.Errorf("ConfigFrom = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfigureEmail(t *testing.T) {
+	keepDefaults(t)
+	addresses := map[string][3]bool{
+		// builtin, strict, lenient
+		"joe@example.com":   {true, true, true},
+		"jöe@example.com":   {false, false, true},
+		"joe@example.c0m":   {true, false, true},
+		"joe@[127.0.0.1].x": {false, false, true},
+		"joe example@x.com": {false, false, false},
+	}
+
+	for i, strictness := range []string{EmailBuiltin, EmailStrict, EmailLenient} {
+		if err := Configure(Config{Email: strictness}); err != nil {
+			t.Fatal(err)
+		}
+
+		for address, want := range addresses {
+			if got := (Email{}).IsSatisfied(address); got != want[i] {
+				t.Errorf("%q email %q: %v, want %v", strictness, address, got, want[i])
+			}
+
+			if got := NewEmail().IsSatisfied(address); got != want[i] {
+				t.Errorf("%q NewEmail %q: %v, want %v", strictness, address, got, want[i])
+			}
+		}
+	}
+
+	if err := Configure(Config{Email: "paranoid"}); err == nil {
+		t.Error("no error for an unknown strictness")
+	}
+}
+
+func TestConfigureDefaults(t *testing.T) {
+	keepDefaults(t)
+	if err := Configure(Config{StopOnError: true, CallerKeys: true, MaxBodyBytes: 10, MaxMatchInput: 20}); err != nil {
+		t.Fatal(err)
+	}
+
+	if MaxBodyBytes != 10 || MaxMatchInput != 20 {
+		t.Errorf("limits %d, %d", MaxBodyBytes, MaxMatchInput)
+	}
+
+	v := &Validation{}
+	v.Required("").Key("a")
+	v.Required("").Key("b")
+	if len(v.Errors) != 1 {
+		t.Errorf("errors %v, want the first only", v.Errors)
+	}
+
+	v = (&Validation{}).StopOnError(false)
+	v.Required("").Key("a")
+	v.Required("").Key("b")
+	if len(v.Errors) != 2 || !v.usesCallerKeys() || v.CallerKeys(false).usesCallerKeys() {
+		t.Errorf("errors %v, want both", v.Errors)
+	}
+
+	if err := Configure(Config{}); err != nil || MaxBodyBytes != 10 {
+		t.Errorf("zero limit changed MaxBodyBytes to %d: %v", MaxBodyBytes, err)
+	}
+}
+
+func TestConfigureMessages(t *testing.T) {
+	keepDefaults(t)
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "tlh.json"), []byte(`{"Required": "ponglu'"}`), 0644)
+	if err := Configure(Config{MessagesDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	v := (&Validation{}).SetLocale("tlh")
+	if r := v.Required(""); r.Error == nil || r.Error.Message != "ponglu'" {
+		t.Errorf("message %v", r.Error)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{`), 0644)
+	if err := Configure(Config{MessagesDir: dir}); err == nil {
+		t.Error("no error for an invalid message file")
+	}
+}
@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"net/http"
+	"time"
+)
+
+type requestKey struct{}
+
+// Return a copy of r carrying v, so that helpers given only the request,
+// such as those of the params package, record their failures into v.  v
+// takes the context of r unless SetContext gave it one.
+func WithValidation(r *http.Request, v *Validation) *http.Request {
+	if v.ctx == nil {
+		v.SetContext(r.Context())
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), requestKey{}, v))
+}
+
+// Set the context of the checks, typically that of the request: the
+// ValidatorCtx validators run by Check and the like are given it, and the
+// problems of the validation are logged with its logger, see
+// log.FromContext.  The checks are recorded into the Audit of ctx, if any,
+// unless v already has one.
+func (v *Validation) SetContext(ctx context.Context) *Validation {
+	v.ctx = ctx
+	if v.audit == nil {
+		v.audit = AuditFromContext(ctx)
+	}
+
+	return v
+}
+
+// Return the logger of the context of v, or the default logger.
+func (v *Validation) logger() *log.Logger {
+	if v.ctx == nil {
+		return log.Default()
+	}
+
+	return log.FromContext(v.ctx)
+}
+
+// Return the Validation attached to r by WithValidation, or nil.
+func FromRequest(r *http.Request) *Validation {
+	v, _ := r.Context().Value(requestKey{}).(*Validation)
+	return v
+}
+
+// A validator that talks to a database or a remote service may implement
+// ValidatorCtx, so that CheckCtx can pass it the request context and it
+// can honor deadlines and cancellation.
+type ValidatorCtx interface {
+	Validator
+	IsSatisfiedCtx(ctx context.Context, obj interface{}) bool
+}
+
+// The check recorded when the context ends before every check has run.
+type cancelled struct {
+	err error
+}
+
+func (c cancelled) IsSatisfied(obj interface{}) bool {
+	return false
+}
+
+func (c cancelled) DefaultMessage() string {
+	return "Validation did not finish: " + c.err.Error()
+}
+
+func (c cancelled) ErrorCode() string {
+	return "validation.cancelled"
+}
+
+func (v *Validation) satisfiedCtx(ctx context.Context, chk Validator, obj interface{}) bool {
+	cchk, ok := chk.(ValidatorCtx)
+	if !ok {
+		return v.audited("", chk, obj)
+	}
+
+	start := time.Now()
+	ok = cchk.IsSatisfiedCtx(ctx, obj)
+	if v.audit != nil {
+		v.audit.add("", chk, obj, ok, start)
+	}
+
+	return ok
+}
+
+// Like Check, but passes ctx to the validators that implement
+// ValidatorCtx.  If ctx ends before a check runs, the remaining checks are
+// skipped and a validation.cancelled error is recorded instead.
+func (v *Validation) CheckCtx(ctx context.Context, obj interface{}, checks ...Validator) *ValidationResult {
+	for _, check := range checks {
+		if v.stopped() {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return v.fail(cancelled{err}, obj, 2)
+		}
+
+		if v.satisfiedCtx(ctx, check, obj) {
+			continue
+		}
+
+		return v.fail(check, obj, 2)
+	}
+
+	return &ValidationResult{Ok: true}
+}
@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type tenantKey struct{}
+
+// Requires a value to be owned by the tenant of the context, and reports
+// whether it was given one.
+type ownedBy struct {
+	owner string
+	ctxs  *int
+}
+
+func (o ownedBy) IsSatisfied(obj interface{}) bool { return false }
+func (o ownedBy) DefaultMessage() string           { return "Not yours" }
+
+func (o ownedBy) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	*o.ctxs++
+
+	return ctx.Value(tenantKey{}) == o.owner
+}
+
+func TestCheckCtx(t *testing.T) {
+	calls := 0
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	v := &Validation{}
+	if !v.CheckCtx(ctx, "doc", Required{}, ownedBy{"acme", &calls}).Ok {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if r := v.CheckCtx(ctx, "doc", ownedBy{"other", &calls}, Required{}).Key("doc"); r.Ok || r.Error.Message != "Not yours" {
+		t.Errorf("CheckCtx = %v", r.Error)
+	}
+
+	if r := v.CheckCtx(ctx, "", Required{}, ownedBy{"acme", &calls}).Key("empty"); r.Ok || r.Error.Code != "validation.required" {
+		t.Errorf("CheckCtx = %v", r.Error)
+	}
+
+	if calls != 2 {
+		t.Errorf("%d calls with the context", calls)
+	}
+
+	// Check uses the context given to SetContext.
+	v = (&Validation{}).SetContext(ctx)
+	if !v.Check("doc", ownedBy{"acme", &calls}).Ok || calls != 3 {
+		t.Errorf("Check without the context: %v", v.Errors)
+	}
+}
+
+func TestCheckCtxCancelled(t *testing.T) {
+	calls := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	v := &Validation{}
+	r := v.CheckCtx(ctx, "doc", ownedBy{"acme", &calls}).Key("doc")
+	if r.Ok || r.Error.Code != "validation.cancelled" || r.Error.Message != "Validation did not finish: context canceled" || calls != 0 {
+		t.Errorf("CheckCtx = %v after %d calls", r.Error, calls)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if r := v.CheckCtx(ctx, "doc", Required{}).Key("late"); r.Error == nil || r.Error.Message != "Validation did not finish: context deadline exceeded" {
+		t.Errorf("CheckCtx = %v", r.Error)
+	}
+}
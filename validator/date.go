@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+)
+
+// Requires a string to parse as a time with Layout, e.g. "2006-01-02".
+type DateFormat struct {
+	Layout string
+}
+
+func (d DateFormat) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	_, err := time.Parse(d.Layout, str)
+
+	return err == nil
+}
+
+func (d DateFormat) DefaultMessage() string {
+	return fmt.Sprint("Must be a date in the format ", d.Layout)
+}
+
+// Requires a time.Time to be strictly before Time.
+type Before struct {
+	Time time.Time
+}
+
+func (b Before) IsSatisfied(obj interface{}) bool {
+	t, ok := obj.(time.Time)
+
+	return ok && !t.IsZero() && t.Before(b.Time)
+}
+
+func (b Before) DefaultMessage() string {
+	return fmt.Sprint("Must be before ", b.Time.Format("2006-01-02 15:04:05"))
+}
+
+// Requires a time.Time to be strictly after Time.
+type After struct {
+	Time time.Time
+}
+
+func (a After) IsSatisfied(obj interface{}) bool {
+	t, ok := obj.(time.Time)
+
+	return ok && !t.IsZero() && t.After(a.Time)
+}
+
+func (a After) DefaultMessage() string {
+	return fmt.Sprint("Must be after ", a.Time.Format("2006-01-02 15:04:05"))
+}
+
+// Requires a date of birth (a time.Time) to be at least Min years ago
+// today, and at most Max if Max is set.
+type Age struct {
+	Min, Max int
+}
+
+// Return the age in whole years on now of someone born on birth.
+func yearsSince(birth, now time.Time) int {
+	years := now.Year() - birth.Year()
+	if now.Month() < birth.Month() || (now.Month() == birth.Month() && now.Day() < birth.Day()) {
+		years--
+	}
+
+	return years
+}
+
+func (a Age) IsSatisfied(obj interface{}) bool {
+	birth, ok := obj.(time.Time)
+	if !ok || birth.IsZero() {
+		return false
+	}
+
+	now := time.Now().In(birth.Location())
+	if birth.After(now) {
+		return false
+	}
+
+	age := yearsSince(birth, now)
+
+	return age >= a.Min && (a.Max == 0 || age <= a.Max)
+}
+
+func (a Age) DefaultMessage() string {
+	if a.Max > 0 {
+		return fmt.Sprint("Age must be between ", a.Min, " and ", a.Max)
+	}
+
+	return fmt.Sprint("Must be at least ", a.Min, " years old")
+}
+
+func (v *Validation) DateFormat(str, layout string) *ValidationResult {
+	return v.apply(DateFormat{layout}, str)
+}
+
+func (v *Validation) Before(t, limit time.Time) *ValidationResult {
+	return v.apply(Before{limit}, t)
+}
+
+func (v *Validation) After(t, limit time.Time) *ValidationResult {
+	return v.apply(After{limit}, t)
+}
+
+func (v *Validation) Age(birth time.Time, min int) *ValidationResult {
+	return v.apply(Age{Min: min}, birth)
+}
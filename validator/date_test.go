@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDateFormat(t *testing.T) {
+	d := DateFormat{"2006-01-02"}
+	if !d.IsSatisfied("2024-02-29") || d.IsSatisfied("2023-02-29") || d.IsSatisfied("29/02/2024") || d.IsSatisfied(time.Now()) {
+		t.Error("DateFormat accepts the wrong dates")
+	}
+
+	if d.DefaultMessage() != "Must be a date in the format 2006-01-02" {
+		t.Errorf("message %q", d.DefaultMessage())
+	}
+}
+
+func TestBeforeAfter(t *testing.T) {
+	limit := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	earlier, later := limit.Add(-time.Second), limit.Add(time.Second)
+
+	if !(Before{limit}).IsSatisfied(earlier) || (Before{limit}).IsSatisfied(limit) || (Before{limit}).IsSatisfied(time.Time{}) {
+		t.Error("Before accepts the wrong times")
+	}
+
+	if !(After{limit}).IsSatisfied(later) || (After{limit}).IsSatisfied(limit) || (After{limit}).IsSatisfied("2025-01-01") {
+		t.Error("After accepts the wrong times")
+	}
+
+	if m := (Before{limit}).DefaultMessage(); m != "Must be before 2024-06-01 12:00:00" {
+		t.Errorf("message %q", m)
+	}
+}
+
+func TestYearsSince(t *testing.T) {
+	birth := time.Date(2000, 3, 15, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		now  time.Time
+		want int
+	}{
+		{time.Date(2018, 3, 14, 0, 0, 0, 0, time.UTC), 17},
+		{time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC), 18},
+		{time.Date(2018, 2, 20, 0, 0, 0, 0, time.UTC), 17},
+		{time.Date(2018, 12, 1, 0, 0, 0, 0, time.UTC), 18},
+	}
+
+	for _, c := range cases {
+		if got := yearsSince(birth, c.now); got != c.want {
+			t.Errorf("yearsSince(%v) = %d, want %d", c.now, got, c.want)
+		}
+	}
+}
+
+func TestAge(t *testing.T) {
+	now := time.Now()
+	adult := now.AddDate(-30, 0, 0)
+	child := now.AddDate(-10, 0, 0)
+
+	if !(Age{Min: 18}).IsSatisfied(adult) || (Age{Min: 18}).IsSatisfied(child) {
+		t.Error("Age{Min: 18} accepts the wrong dates of birth")
+	}
+
+	if (Age{Min: 18, Max: 25}).IsSatisfied(adult) || !(Age{Min: 5, Max: 12}).IsSatisfied(child) {
+		t.Error("Age with a Max accepts the wrong dates of birth")
+	}
+
+	if (Age{}).IsSatisfied(now.AddDate(0, 0, 1)) || (Age{}).IsSatisfied(time.Time{}) {
+		t.Error("Age accepts a future or zero date of birth")
+	}
+
+	if m := (Age{Min: 18, Max: 65}).DefaultMessage(); m != "Age must be between 18 and 65" {
+		t.Errorf("message %q", m)
+	}
+}
+
+func TestDateTags(t *testing.T) {
+	v := &Validation{}
+	v.ValidateStruct(struct {
+		Day   string    `validate:"dateformat=2006-01-02"`
+		Birth time.Time `validate:"age=18"`
+	}{"2024-13-01", time.Now().AddDate(-17, 0, 0)})
+
+	if errors := v.ErrorMap(); errors["Day"] == nil || errors["Birth"] == nil {
+		t.Errorf("errors %v", errors)
+	}
+}
@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// An EachResult holds the errors of the elements that failed a check
+// applied by Each or ValidateMapValues, each keyed base[index].
+type EachResult struct {
+	Ok      bool
+	Errors  []*ValidationError
+	results []*ValidationResult
+	indexes []string
+}
+
+// Key the element errors name[index], e.g. Key("tags") gives "tags[2]",
+// as ValidationResult.Key does.
+func (r *EachResult) Key(name string) *EachResult {
+	for i, result := range r.results {
+		result.Key(name + "[" + r.indexes[i] + "]")
+	}
+
+	return r
+}
+
+// Apply the checks, in order, to one element and record its first failure
+// under [index], after the key of the caller of Each or ValidateMapValues
+// with CallerKeys.  Without CallerKeys the failure is passed to the hooks
+// once the EachResult is given its key.
+func (v *Validation) checkElement(r *EachResult, index string, elem interface{}, checks []Validator) {
+	for _, check := range checks {
+		if v.stopped() || v.audited("", check, elem) {
+			continue
+		}
+
+		// callerKey, checkElement, Each or ValidateMapValues, the caller.
+		key := v.callerKey(3)
+		result := v.failure(key+"["+index+"]", check, elem)
+		if key != "" {
+			result.report()
+		}
+
+		r.Ok = false
+		r.Errors = append(r.Errors, result.Error)
+		r.results = append(r.results, result)
+		r.indexes = append(r.indexes, index)
+
+		return
+	}
+}
+
+// Apply the checks to every element of a slice or array, e.g.
+// v.Each(emails, Required{}, NewEmail()).Key("emails").
+func (v *Validation) Each(slice interface{}, checks ...Validator) *EachResult {
+	r := &EachResult{Ok: true}
+	rv := reflect.ValueOf(slice)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return r
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		v.checkElement(r, fmt.Sprint(i), rv.Index(i).Interface(), checks)
+	}
+
+	return r
+}
+
+// Apply the checks to every value of a map, in the order of its sorted
+// keys, keying the errors by the map keys, e.g. "prices[apple]".
+func (v *Validation) ValidateMapValues(m interface{}, checks ...Validator) *EachResult {
+	r := &EachResult{Ok: true}
+	rv := reflect.ValueOf(m)
+	if rv.Kind() != reflect.Map {
+		return r
+	}
+
+	keys := make([]string, 0, rv.Len())
+	values := map[string]reflect.Value{}
+	for _, key := range rv.MapKeys() {
+		name := fmt.Sprint(key.Interface())
+		keys = append(keys, name)
+		values[name] = rv.MapIndex(key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		v.checkElement(r, key, values[key].Interface(), checks)
+	}
+
+	return r
+}
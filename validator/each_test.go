@@ -0,0 +1,41 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	v := &Validation{}
+	r := v.Each([]string{"a@example.com", "", "nope"}, Required{}, NewEmail()).Key("emails")
+	if r.Ok || len(r.Errors) != 2 {
+		t.Fatalf("Each = %+v", r)
+	}
+
+	if r.Errors[0].Key != "emails[1]" || r.Errors[0].Message != "Required" || r.Errors[1].Key != "emails[2]" || r.Errors[1].Code != "validation.email" {
+		t.Errorf("errors %v", r.Errors)
+	}
+
+	if !v.Each([2]int{1, 2}, Min{1}).Ok || !v.Each(nil, Required{}).Ok || !v.Each("not a slice", Required{}).Ok {
+		t.Error("failed without a failing element")
+	}
+
+	if len(v.Errors) != 2 {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestValidateMapValues(t *testing.T) {
+	v := &Validation{}
+	r := v.ValidateMapValues(map[string]int{"pear": 0, "apple": -1, "fig": 3}, Min{1}).Key("prices")
+	if r.Ok || len(r.Errors) != 2 || r.Errors[0].Key != "prices[apple]" || r.Errors[1].Key != "prices[pear]" {
+		t.Errorf("errors %v", r.Errors)
+	}
+
+	if r := v.ValidateMapValues(map[int]string{2: ""}, Required{}).Key("names"); r.Errors[0].Key != "names[2]" {
+		t.Errorf("errors %v", r.Errors)
+	}
+
+	if !v.ValidateMapValues([]int{0}, Min{1}).Ok {
+		t.Error("a slice was checked as a map")
+	}
+}
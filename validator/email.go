@@ -0,0 +1,119 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Looks up the MX records of a domain.  *net.Resolver, and so
+// net.DefaultResolver, implement it; tests can substitute a fake.
+type MXResolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// The default timeout of an MX lookup, and how long its answer is cached.
+var (
+	MXTimeout  = 3 * time.Second
+	MXCacheTTL = 10 * time.Minute
+)
+
+type mxEntry struct {
+	ok      bool
+	expires time.Time
+}
+
+var mxCache = struct {
+	sync.Mutex
+	entries map[string]mxEntry
+}{entries: map[string]mxEntry{}}
+
+// Forget the cached MX lookups.
+func ClearMXCache() {
+	mxCache.Lock()
+	mxCache.entries = map[string]mxEntry{}
+	mxCache.Unlock()
+}
+
+func (e Email) IsSatisfied(obj interface{}) bool {
+	return e.IsSatisfiedCtx(context.Background(), obj)
+}
+
+// Check the address, then, with VerifyMX, that its domain has MX records
+// other than the "." of a domain that accepts no mail.  Answers are cached
+// for MXCacheTTL.  A lookup that fails for any reason but the domain not
+// existing, such as a timeout, lets the address pass and is not cached,
+// so that a DNS outage does not reject valid addresses.
+func (e Email) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	match := e.Match
+	if match.Regexp == nil {
+		match = Match{defaultEmailPattern()}
+	}
+
+	if !match.IsSatisfied(obj) {
+		return false
+	}
+
+	if !e.VerifyMX {
+		return true
+	}
+
+	str := obj.(string)
+	domain := strings.ToLower(str[strings.LastIndex(str, "@")+1:])
+
+	mxCache.Lock()
+	entry, cached := mxCache.entries[domain]
+	mxCache.Unlock()
+	if cached && time.Now().Before(entry.expires) {
+		return entry.ok
+	}
+
+	ok, err := e.lookupMX(ctx, domain)
+	if err != nil {
+		return true
+	}
+
+	mxCache.Lock()
+	mxCache.entries[domain] = mxEntry{ok, time.Now().Add(MXCacheTTL)}
+	mxCache.Unlock()
+
+	return ok
+}
+
+// Report whether domain receives mail.  The error is nil if the answer is
+// definite.
+func (e Email) lookupMX(ctx context.Context, domain string) (bool, error) {
+	resolver := e.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = MXTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	records, err := resolver.LookupMX(ctx, domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	for _, mx := range records {
+		if mx.Host != "." && mx.Host != "" {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
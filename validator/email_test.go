@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// An MXResolver answering from a map of domains, counting its lookups.
+type fakeMX struct {
+	mu      sync.Mutex
+	lookups int
+	records map[string][]*net.MX
+	block   bool
+}
+
+func (f *fakeMX) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	f.mu.Lock()
+	f.lookups++
+	f.mu.Unlock()
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	records, ok := f.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+
+	if records == nil {
+		return nil, errors.New("server misbehaving")
+	}
+
+	return records, nil
+}
+
+func TestEmailVerifyMX(t *testing.T) {
+	ClearMXCache()
+	defer ClearMXCache()
+
+	resolver := &fakeMX{records: map[string][]*net.MX{
+		"example.com": {{Host: "mx.example.com.", Pref: 10}},
+		"nomail.com":  {{Host: ".", Pref: 0}},
+		"flaky.com":   nil,
+	}}
+	email := Email{VerifyMX: true, Resolver: resolver}
+
+	cases := map[string]bool{
+		"ann@example.com": true,
+		"ann@EXAMPLE.com": true,
+		"ann@nomail.com":  false,
+		"ann@missing.com": false,
+		"ann@flaky.com":   true,
+		"not an address":  false,
+	}
+
+	for address, want := range cases {
+		if got := email.IsSatisfied(address); got != want {
+			t.Errorf("%s = %v", address, got)
+		}
+	}
+
+	// example.com, nomail.com and missing.com are cached, flaky.com is not.
+	lookups := resolver.lookups
+	for address := range cases {
+		email.IsSatisfied(address)
+	}
+
+	if resolver.lookups != lookups+1 {
+		t.Errorf("%d lookups after %d", resolver.lookups, lookups)
+	}
+
+	if msg := email.DefaultMessage(); msg != "Must be a valid email address with a domain that receives mail" {
+		t.Errorf("message %q", msg)
+	}
+}
+
+func TestEmailVerifyMXTimeout(t *testing.T) {
+	ClearMXCache()
+	defer ClearMXCache()
+
+	email := Email{VerifyMX: true, Resolver: &fakeMX{block: true}, Timeout: 10 * time.Millisecond}
+	start := time.Now()
+	if !email.IsSatisfied("ann@slow.com") || time.Since(start) > time.Second {
+		t.Errorf("rejected after %v", time.Since(start))
+	}
+}
+
+func TestEmailTag(t *testing.T) {
+	if chk, ok := tagValidator("emailmx", "", reflect.TypeOf("")).(Email); !ok || !chk.VerifyMX {
+		t.Errorf("emailmx = %#v", chk)
+	}
+
+	if !(Email{}).IsSatisfied("ann@example.com") || (Email{}).IsSatisfied("ann@") {
+		t.Error("an Email without a pattern does not use the default one")
+	}
+}
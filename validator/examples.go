@@ -0,0 +1,631 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"golanger.com/framework/i18n"
+	"math"
+	"math/big"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// A validator may implement ExampleProvider to give values it accepts and
+// values it rejects, for property tests and fuzzing seeds; Examples uses
+// them in preference to its own.
+type ExampleProvider interface {
+	Examples() (valid, invalid []interface{})
+}
+
+// Return values chk accepts and values it rejects, derived from its
+// parameters for the built-in validators, e.g. for Min{5} the valid 5 and
+// 6 and the invalid 4, "5" and nil.  Either list may be empty for a
+// validator whose values cannot be guessed, such as Unique.
+func Examples(chk Validator) (valid, invalid []interface{}) {
+	if p, ok := chk.(ExampleProvider); ok {
+		return p.Examples()
+	}
+
+	now := time.Now()
+	switch c := unwrap(chk).(type) {
+	case Required:
+		return []interface{}{"x", 1, true, now, []int{1}},
+			[]interface{}{nil, "", 0, false, time.Time{}, []int{}}
+	case Min:
+		return []interface{}{c.Min, c.Min + 1},
+			append(below(c.Min), "0", nil)
+	case Max:
+		return []interface{}{c.Max, c.Max - 1},
+			append(above(c.Max), "0", nil)
+	case Range:
+		valid = []interface{}{c.Min.Min, c.Max.Max}
+		return valid, append(append(below(c.Min.Min), above(c.Max.Max)...), nil)
+	case MinSize:
+		return []interface{}{strings.Repeat("a", c.Min), make([]int, c.Min+1)},
+			append(sizedBelow(c.Min), 1, nil)
+	case MaxSize:
+		return []interface{}{strings.Repeat("a", c.Max), make([]int, c.Max)},
+			[]interface{}{strings.Repeat("a", c.Max+1), make([]int, c.Max+1), 1, nil}
+	case Length:
+		return []interface{}{strings.Repeat("a", c.N), make([]int, c.N)},
+			append(append(sizedBelow(c.N), strings.Repeat("a", c.N+1)), 1, nil)
+	case MinInt64:
+		valid = []interface{}{c.Min, int64(math.MaxInt64)}
+		if c.Min > math.MinInt64 {
+			invalid = append(invalid, c.Min-1)
+		}
+
+		return valid, append(invalid, uint(1), "0", nil)
+	case MaxInt64:
+		valid = []interface{}{c.Max, int64(math.MinInt64)}
+		if c.Max < math.MaxInt64 {
+			invalid = append(invalid, c.Max+1)
+		}
+
+		return valid, append(invalid, uint(1), "0", nil)
+	case RangeInt64:
+		v1, i1 := Examples(c.MinInt64)
+		v2, i2 := Examples(c.MaxInt64)
+		return intersect(c, append(v1, v2...)), append(i1, i2...)
+	case MinUint:
+		valid = []interface{}{c.Min, uint64(math.MaxUint64)}
+		if c.Min > 0 {
+			invalid = append(invalid, c.Min-1)
+		}
+
+		return valid, append(invalid, -1, nil)
+	case MaxUint:
+		valid = []interface{}{c.Max, uint8(0)}
+		if c.Max < math.MaxUint64 {
+			invalid = append(invalid, c.Max+1)
+		}
+
+		return valid, append(invalid, -1, nil)
+	case RangeUint:
+		v1, i1 := Examples(c.MinUint)
+		v2, i2 := Examples(c.MaxUint)
+		return intersect(c, append(v1, v2...)), append(i1, i2...)
+	case MinFloat:
+		return []interface{}{c.Min, c.Min + 1, math.Inf(1)},
+			[]interface{}{c.Min - 1, math.Inf(-1), math.NaN(), "0", nil}
+	case MaxFloat:
+		return []interface{}{c.Max, c.Max - 1, math.Inf(-1)},
+			[]interface{}{c.Max + 1, math.Inf(1), math.NaN(), "0", nil}
+	case RangeFloat:
+		return []interface{}{c.MinFloat.Min, c.MaxFloat.Max, (c.MinFloat.Min + c.MaxFloat.Max) / 2},
+			[]interface{}{c.MinFloat.Min - 1, c.MaxFloat.Max + 1, math.NaN(), nil}
+	case GreaterThan:
+		return boundExamples(c.Value, 1)
+	case LessThan:
+		return boundExamples(c.Value, -1)
+	case AtLeastSum:
+		return []interface{}{c.sum(), c.sum() + 1}, []interface{}{c.sum() - 1, 1, nil}
+	case AtLeastProduct:
+		return []interface{}{c.product(), c.product() + 1}, []interface{}{c.product() - 1, 1, nil}
+	case Email:
+		if c.VerifyMX {
+			return nil, []interface{}{"", "user@", nil}
+		}
+
+		if c.Match.Regexp != nil && c.Match.Regexp != emailPattern && c.Match.Regexp != strictEmailPattern {
+			return matchExamples(c.Match.Regexp)
+		}
+
+		return []interface{}{"user@example.com", "first.last+tag@mail.example.co.uk", "o'brien@example.org"},
+			[]interface{}{"", "user", "user@", "@example.com", "user@example", "us er@example.com",
+				"user@@example.com", "user.@example.com", "user@-example.com", 1, nil}
+	case Mask:
+		return maskExamples(c.Mask)
+	case UUID:
+		return []interface{}{"123e4567-e89b-12d3-a456-426614174000", "123E4567-E89B-12D3-A456-426614174000"},
+			[]interface{}{"", "123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g",
+				"{123e4567-e89b-12d3-a456-426614174000}", 1, nil}
+	case Match:
+		if c.Regexp == nil {
+			return nil, nil
+		}
+
+		return matchExamples(c.Regexp)
+	case SafeMatch:
+		re, err := CompilePattern(c.Pattern)
+		if err != nil {
+			return nil, []interface{}{"", "x"}
+		}
+
+		valid, invalid = matchExamples(re)
+		return valid, append(invalid, strings.Repeat("a", MaxMatchInput+1))
+	case SafeHTML:
+		valid = []interface{}{"plain text", "1 < 2 &amp; 3 > 2", ""}
+		invalid = []interface{}{`<script>alert(1)</script>`, `<img src=x onerror=alert(1)>`,
+			`<a href="javascript:alert(1)">x</a>`, `<!-- x -->`, `<iframe src="//evil"></iframe>`, 1, nil}
+		if len(c.AllowedTags) == 0 {
+			valid = append(valid, `<p>Hi <b>there</b>, <a href="https://example.com" title="x">link</a></p>`)
+			invalid = append(invalid, `<a href="https://example.com" onclick="x()">x</a>`, `<p style="color:red">x</p>`)
+		}
+
+		return valid, invalid
+	case ValidJSON:
+		return []interface{}{`{"a":[1,2.5,"x",null,true]}`, []byte("[]"), json.RawMessage(`"s"`), "0"},
+			[]interface{}{"", "{", `{"a":}`, "[1,]", "{'a':1}", "nul", 1, nil}
+	case OneOf:
+		return c.Allowed, []interface{}{"\x00" + fmt.Sprint(c.Allowed...), struct{}{}, nil}
+	case In:
+		valid = []interface{}{}
+		for _, value := range c.Values {
+			valid = append(valid, value)
+			if c.CaseInsensitive {
+				valid = append(valid, strings.ToUpper(value))
+			}
+		}
+
+		invalid = []interface{}{"\x00" + strings.Join(c.Values, ""), 1, nil}
+		if !c.CaseInsensitive && len(c.Values) > 0 && strings.ToUpper(c.Values[0]) != c.Values[0] {
+			invalid = append(invalid, strings.ToUpper(c.Values[0]))
+		}
+
+		return valid, invalid
+	case Numeric:
+		return append(numberExamples(c.Locale, "1234", "-0.5", ".5", "+7"), groupedThousand(c.Locale)),
+			[]interface{}{"", "abc", "1e5", "1..2", "5" + i18n.NumberFormatFor(c.Locale).Decimal, "12,34,567", 1, nil}
+	case Integer:
+		return append(numberExamples(c.Locale, "0", "-12"), groupedThousand(c.Locale)),
+			append(numberExamples(c.Locale, "1.5"), "", "1e3", "abc", 1, nil)
+	case Decimal:
+		valid = numberExamples(c.Locale, "3", "-2.5")
+		if c.MaxFractionDigits > 0 {
+			valid = append(valid, numberExamples(c.Locale, "1."+strings.Repeat("9", c.MaxFractionDigits))...)
+			invalid = numberExamples(c.Locale, "1."+strings.Repeat("9", c.MaxFractionDigits+1))
+		}
+
+		return valid, append(invalid, "", "abc", 1, nil)
+	case InRangeString:
+		return rangeStringExamples(c)
+	case URL:
+		return []interface{}{"https://example.com", "http://example.com:8080/a/b?c=d#e", "ftp://files.example.com/x"},
+			[]interface{}{"", "example.com", "/path", "https://", "mailto:user@example.com", 1, nil}
+	case IPv4:
+		return []interface{}{"192.168.0.1", "0.0.0.0", "255.255.255.255"},
+			[]interface{}{"", "256.0.0.1", "1.2.3", "::ffff:1.2.3.4", "::1", 1, nil}
+	case IPv6:
+		return []interface{}{"::1", "2001:db8::8a2e:370:7334", "::ffff:1.2.3.4"},
+			[]interface{}{"", "192.168.0.1", "2001:db8::g", ":::", 1, nil}
+	case Phone:
+		return phoneExamples(c.Region)
+	case FitsInt:
+		bits := c.BitSize
+		if bits <= 0 || bits > 64 {
+			bits = 64
+		}
+
+		max := int64(math.MaxInt64)
+		if bits < 64 {
+			max = 1<<(bits-1) - 1
+		}
+
+		valid = []interface{}{"0", "-1", strconv.FormatInt(max, 10)}
+		invalid = []interface{}{"", "1.5", "abc", " 1", "99999999999999999999", 1, nil}
+		if bits < 64 {
+			invalid = append(invalid, strconv.FormatInt(max+1, 10))
+		}
+
+		return valid, invalid
+	case JWTStructure:
+		return []interface{}{"eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln"},
+			[]interface{}{"", "a.b", "a.b.c", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0", "eyJhbGciOiJIUzI1NiJ9.bm90IGpzb24.c2ln", 1, nil}
+	case CSSColor:
+		return []interface{}{"#fff", "#A1B2C3", "#a1b2c3d4", "red", "rgb(0, 128, 255)", "rgba(0,0,0,0.5)", "hsl(120, 50%, 50%)"},
+			[]interface{}{"", "#ggg", "#12345", "notacolor", "rgb(0,0)", 1, nil}
+	case Luhn:
+		return []interface{}{"79927398713", "4111 1111 1111 1111"},
+			[]interface{}{"", "79927398710", "1", "abcd", 1, nil}
+	case CreditCard:
+		return cardExamples(c)
+	case DateFormat:
+		sample := time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC).Format(c.Layout)
+		return []interface{}{sample}, []interface{}{"", sample + "x", "not a date", 1, nil}
+	case Before:
+		return []interface{}{c.Time.Add(-time.Second), c.Time.AddDate(-1, 0, 0)},
+			[]interface{}{c.Time, c.Time.Add(time.Second), time.Time{}, c.Time.String(), nil}
+	case After:
+		return []interface{}{c.Time.Add(time.Second), c.Time.AddDate(1, 0, 0)},
+			[]interface{}{c.Time, c.Time.Add(-time.Second), time.Time{}, c.Time.String(), nil}
+	case DateRange:
+		if c.Start.IsZero() {
+			return nil, []interface{}{now, time.Time{}, nil}
+		}
+
+		valid = []interface{}{c.Start.Add(time.Hour)}
+		if !c.Strict {
+			valid = append(valid, c.Start)
+		} else {
+			invalid = append(invalid, c.Start)
+		}
+
+		return valid, append(invalid, c.Start.Add(-time.Hour), time.Time{}, nil)
+	case Age:
+		born := now.AddDate(-c.Min, 0, -1)
+		invalid = []interface{}{now.AddDate(-c.Min, 0, 1), now.AddDate(1, 0, 0), time.Time{}, nil}
+		if c.Max > 0 {
+			invalid = append(invalid, now.AddDate(-c.Max-2, 0, 0))
+		}
+
+		return []interface{}{born}, invalid
+	case BusinessHours:
+		day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		if len(c.Weekdays) > 0 {
+			day = day.AddDate(0, 0, (int(c.Weekdays[0])-int(day.Weekday())+7)%7)
+		}
+
+		if c.StartHour >= c.EndHour {
+			return nil, []interface{}{day, nil}
+		}
+
+		invalid = []interface{}{day.Add(time.Duration(c.EndHour) * time.Hour), "09:00", nil}
+		if c.StartHour > 0 {
+			invalid = append(invalid, day.Add(time.Duration(c.StartHour-1)*time.Hour))
+		}
+
+		return []interface{}{day.Add(time.Duration(c.StartHour) * time.Hour)}, invalid
+	case NoNilElements:
+		var nilPtr *int
+		return []interface{}{[]int{1, 2}, []interface{}{1, "a"}, []string{}},
+			[]interface{}{[]interface{}{1, nil}, []*int{nilPtr}, []interface{}{nilPtr}, "ab", nil}
+	case PasswordNotCommon:
+		valid = []interface{}{"correct horse battery staple 9!"}
+		for word := range c.List {
+			invalid = append(invalid, word, strings.ToUpper(word))
+			break
+		}
+
+		return valid, append(invalid, 1, nil)
+	case Password:
+		return passwordExamples(c)
+	}
+
+	return nil, nil
+}
+
+func below(n int) []interface{} {
+	if n == math.MinInt {
+		return nil
+	}
+
+	return []interface{}{n - 1}
+}
+
+func above(n int) []interface{} {
+	if n == math.MaxInt {
+		return nil
+	}
+
+	return []interface{}{n + 1}
+}
+
+func sizedBelow(n int) []interface{} {
+	if n <= 0 {
+		return nil
+	}
+
+	return []interface{}{strings.Repeat("a", n-1), make([]int, n-1)}
+}
+
+// Keep the candidates of a range that satisfy all of it.
+func intersect(chk Validator, candidates []interface{}) []interface{} {
+	valid := []interface{}{}
+	for _, c := range candidates {
+		if chk.IsSatisfied(c) {
+			valid = append(valid, c)
+		}
+	}
+
+	return valid
+}
+
+// Return a short string matching re, built from its syntax tree, and
+// whether it does match: the tree ignores lookaround-free subtleties such
+// as anchors in the middle of a pattern.
+func sampleMatch(re *regexp.Regexp) (string, bool) {
+	tree, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+
+	var b strings.Builder
+	var walk func(t *syntax.Regexp)
+	walk = func(t *syntax.Regexp) {
+		switch t.Op {
+		case syntax.OpLiteral:
+			b.WriteString(string(t.Rune))
+		case syntax.OpCharClass:
+			if len(t.Rune) > 0 {
+				b.WriteRune(t.Rune[0])
+			}
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			b.WriteByte('a')
+		case syntax.OpCapture:
+			walk(t.Sub[0])
+		case syntax.OpPlus:
+			walk(t.Sub[0])
+		case syntax.OpRepeat:
+			for i := 0; i < t.Min; i++ {
+				walk(t.Sub[0])
+			}
+		case syntax.OpConcat:
+			for _, sub := range t.Sub {
+				walk(sub)
+			}
+		case syntax.OpAlternate:
+			walk(t.Sub[0])
+		}
+	}
+
+	walk(tree)
+	sample := b.String()
+
+	return sample, re.MatchString(sample)
+}
+
+func matchExamples(re *regexp.Regexp) (valid, invalid []interface{}) {
+	sample, ok := sampleMatch(re)
+	if ok {
+		valid = append(valid, sample)
+	}
+
+	for _, s := range []string{"", " ", "\x00", sample + "\n!", "!" + sample} {
+		if !re.MatchString(s) {
+			invalid = append(invalid, s)
+		}
+	}
+
+	return valid, append(invalid, 1, nil)
+}
+
+func maskExamples(mask string) (valid, invalid []interface{}) {
+	var sample, wrong strings.Builder
+	swapped := false
+	for _, c := range mask {
+		switch c {
+		case '#':
+			sample.WriteByte('7')
+		case 'A':
+			sample.WriteByte('k')
+		case '*':
+			sample.WriteByte('Q')
+		default:
+			sample.WriteRune(c)
+			wrong.WriteRune(c)
+			continue
+		}
+
+		if !swapped {
+			wrong.WriteByte('~')
+			swapped = true
+		} else {
+			wrong.WriteString(sample.String()[sample.Len()-1:])
+		}
+	}
+
+	invalid = []interface{}{sample.String() + "7", 1, nil}
+	if swapped {
+		invalid = append(invalid, wrong.String())
+	}
+
+	if mask != "" {
+		invalid = append(invalid, "")
+	}
+
+	return []interface{}{sample.String()}, invalid
+}
+
+func phoneExamples(region string) (valid, invalid []interface{}) {
+	invalid = []interface{}{"", "12", "phone", "+0123456789", 1, nil}
+	switch strings.ToUpper(region) {
+	case "US":
+		return []interface{}{"(415) 555-0100", "415-555-0100", "+1 415 555 0100"}, invalid
+	case "CN":
+		return []interface{}{"13812345678", "+86 13812345678", "010-12345678"}, invalid
+	case "GB":
+		return []interface{}{"020 7946 0958", "+44 20 7946 0958", "07700900123"}, invalid
+	}
+
+	return []interface{}{"+14155550100", "+8613812345678"}, invalid
+}
+
+var brandCards = map[string]string{
+	BrandVisa:       "4111111111111111",
+	BrandMastercard: "5555555555554444",
+	BrandAmex:       "378282246310005",
+}
+
+func cardExamples(c CreditCard) (valid, invalid []interface{}) {
+	brands := map[string]bool{}
+	for _, b := range c.Brands {
+		brands[b] = true
+	}
+
+	for _, brand := range []string{BrandVisa, BrandMastercard, BrandAmex} {
+		if len(brands) == 0 || brands[brand] {
+			valid = append(valid, brandCards[brand])
+		} else {
+			invalid = append(invalid, brandCards[brand])
+		}
+	}
+
+	return valid, append(invalid, "", "4111111111111112", "79927398713", "card", 1, nil)
+}
+
+// Build a password satisfying the policy, cycling through the character
+// classes until it is long enough, and passwords breaking each rule.
+func passwordExamples(p Password) (valid, invalid []interface{}) {
+	n := p.MinLen
+	if n < 8 {
+		n = 8
+	}
+
+	if need := int(p.MinEntropy/math.Log2(95)) + 1; need > n {
+		n = need
+	}
+
+	const cycle = "Kx7#mQ2$vR9&tZ4@"
+	var b strings.Builder
+	for b.Len() < n {
+		b.WriteString(cycle)
+	}
+
+	sample := b.String()[:n]
+	for _, word := range p.Blacklist {
+		if strings.EqualFold(word, sample) {
+			sample += "!"
+		}
+	}
+
+	valid = []interface{}{sample}
+	if p.MinLen > 0 {
+		invalid = append(invalid, sample[:p.MinLen-1])
+	}
+
+	if p.RequireUpper {
+		invalid = append(invalid, strings.ToLower(sample))
+	}
+
+	if p.RequireDigit {
+		invalid = append(invalid, strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return 'd'
+			}
+
+			return r
+		}, sample))
+	}
+
+	if p.RequireSymbol {
+		invalid = append(invalid, strings.Map(func(r rune) rune {
+			if isSymbol(r) {
+				return 's'
+			}
+
+			return r
+		}, sample))
+	}
+
+	if len(p.Blacklist) > 0 {
+		invalid = append(invalid, p.Blacklist[0])
+	}
+
+	if p.MinEntropy > 0 {
+		invalid = append(invalid, "a")
+	}
+
+	return valid, append(invalid, 1, nil)
+}
+
+// Check that chk accepts each valid example and rejects each invalid one,
+// returning the examples it got wrong.  Custom validators implementing
+// ExampleProvider are checked the same way.
+func CheckExamples(chk Validator) (accepted, rejected []interface{}) {
+	valid, invalid := Examples(chk)
+	for _, obj := range valid {
+		if !chk.IsSatisfied(obj) {
+			rejected = append(rejected, obj)
+		}
+	}
+
+	for _, obj := range invalid {
+		if chk.IsSatisfied(obj) {
+			accepted = append(accepted, obj)
+		}
+	}
+
+	return accepted, rejected
+}
+
+// Write numbers given in Go syntax in the format of locale.
+func numberExamples(locale string, numbers ...string) []interface{} {
+	f := i18n.NumberFormatFor(locale)
+	examples := []interface{}{}
+	for _, n := range numbers {
+		examples = append(examples, strings.Replace(n, ".", f.Decimal, 1))
+	}
+
+	return examples
+}
+
+func groupedThousand(locale string) string {
+	sep, _ := utf8.DecodeRuneInString(i18n.NumberFormatFor(locale).Group)
+	return "1" + string(sep) + "000"
+}
+
+func rangeStringExamples(c InRangeString) (valid, invalid []interface{}) {
+	min, minOk := ratOf(c.Min)
+	max, maxOk := ratOf(c.Max)
+	one := big.NewRat(1, 1)
+	switch {
+	case minOk && maxOk:
+		if min.Cmp(max) > 0 {
+			return nil, []interface{}{c.Min, c.Max, nil}
+		}
+
+		mid := new(big.Rat).Add(min, max)
+		mid.Quo(mid, big.NewRat(2, 1))
+		valid = numberExamples(c.Locale, c.Min, c.Max, mid.FloatString(3))
+	case minOk:
+		valid = numberExamples(c.Locale, c.Min, new(big.Rat).Add(min, one).FloatString(0))
+	case maxOk:
+		valid = numberExamples(c.Locale, c.Max, new(big.Rat).Sub(max, one).FloatString(0))
+	}
+
+	if minOk {
+		invalid = append(invalid, numberExamples(c.Locale, new(big.Rat).Sub(min, big.NewRat(1, 1000)).FloatString(3))...)
+	}
+
+	if maxOk {
+		invalid = append(invalid, numberExamples(c.Locale, new(big.Rat).Add(max, big.NewRat(1, 1000)).FloatString(3))...)
+	}
+
+	return valid, append(invalid, "", "abc", 1, nil)
+}
+
+// Return values on the side dir of an exclusive bound (1 above, -1 below),
+// and the bound itself and values on the other side.
+func boundExamples(bound interface{}, dir int) (valid, invalid []interface{}) {
+	switch b := bound.(type) {
+	case time.Time:
+		step := time.Duration(dir) * time.Second
+		return []interface{}{b.Add(step), b.Add(24 * step)}, []interface{}{b, b.Add(-step), nil}
+	case string:
+		if dir > 0 {
+			return []interface{}{b + "a", b + "\xff"}, []interface{}{b, nil}
+		}
+
+		if b == "" {
+			return nil, []interface{}{"", "a", nil}
+		}
+
+		return []interface{}{b[:len(b)-1], ""}, []interface{}{b, b + "a", nil}
+	}
+
+	if n, ok := toInt64(bound); ok {
+		return []interface{}{n + int64(dir)}, []interface{}{n, n - int64(dir), "1", nil}
+	}
+
+	if n, ok := toUint64(bound); ok {
+		switch {
+		case dir > 0:
+			return []interface{}{n + 1}, []interface{}{n, "1", nil}
+		case n > 0:
+			return []interface{}{n - 1}, []interface{}{n, n + 1, "1", nil}
+		}
+
+		return nil, []interface{}{n, n + 1, nil}
+	}
+
+	if f, ok := toFloat64(bound); ok {
+		return []interface{}{f + float64(dir)/2}, []interface{}{f, f - float64(dir), math.NaN(), nil}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,79 @@
+package validator_test
+
+import (
+	"golanger.com/framework/testing/validatortest"
+	"golanger.com/framework/validator"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// Built-in validators with typical parameters.
+func builtins() []validator.Validator {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	return []validator.Validator{
+		validator.Required{},
+		validator.Min{Min: 5},
+		validator.Max{Max: 5},
+		validator.Range{Min: validator.Min{Min: 1}, Max: validator.Max{Max: 9}},
+		validator.MinSize{Min: 3},
+		validator.MaxSize{Max: 3},
+		validator.Length{N: 4},
+		validator.MinInt64{Min: -1 << 40},
+		validator.MaxInt64{Max: 1 << 40},
+		validator.RangeInt64{MinInt64: validator.MinInt64{Min: -5}, MaxInt64: validator.MaxInt64{Max: 5}},
+		validator.MinUint{Min: 3},
+		validator.MaxUint{Max: 1 << 63},
+		validator.RangeUint{MinUint: validator.MinUint{Min: 1}, MaxUint: validator.MaxUint{Max: 10}},
+		validator.MinFloat{Min: 0.5},
+		validator.MaxFloat{Max: 2.5},
+		validator.RangeFloat{MinFloat: validator.MinFloat{Min: -1.5}, MaxFloat: validator.MaxFloat{Max: 1.5}},
+		validator.GreaterThan{Value: 10},
+		validator.LessThan{Value: 10},
+		validator.AtLeastSum{Parts: []float64{1, 2.5}},
+		validator.AtLeastProduct{Factors: []float64{2, 3}},
+		validator.NewEmail(),
+		validator.NewMask("999-aaa"),
+		validator.Match{Regexp: regexp.MustCompile(`^[a-z]+[0-9]*$`)},
+		validator.SafeMatch{Pattern: `^(a+)+$`},
+		validator.SafeHTML{},
+		validator.ValidJSON{},
+		validator.OneOf{Allowed: []interface{}{"red", "green", 3}},
+		validator.In{Values: []string{"a", "B"}, CaseInsensitive: true},
+		validator.Numeric{},
+		validator.Numeric{Locale: "de"},
+		validator.Integer{},
+		validator.Decimal{MaxFractionDigits: 2},
+		validator.InRangeString{Min: "1.5", Max: "10"},
+		validator.URL{},
+		validator.IPv4{},
+		validator.IPv6{},
+		validator.Phone{},
+		validator.Phone{Region: "US"},
+		validator.FitsInt{BitSize: 32},
+		validator.JWTStructure{},
+		validator.CSSColor{},
+		validator.Luhn{},
+		validator.CreditCard{},
+		validator.DateFormat{Layout: "2006-01-02"},
+		validator.Before{Time: at},
+		validator.After{Time: at},
+		validator.Age{Min: 18},
+		validator.BusinessHours{StartHour: 9, EndHour: 17},
+		validator.NoNilElements{},
+		validator.Password{MinLen: 10, RequireUpper: true, RequireDigit: true},
+	}
+}
+
+func TestBuiltinExamples(t *testing.T) {
+	for _, chk := range builtins() {
+		validatortest.AssertExamples(t, chk)
+	}
+}
+
+func TestBuiltinsFast(t *testing.T) {
+	for _, chk := range builtins() {
+		validatortest.AssertFast(t, chk)
+	}
+}
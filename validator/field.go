@@ -0,0 +1,103 @@
+package validator
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"reflect"
+)
+
+// Requires a value to equal Value, typically that of another field named
+// Field, as for a password confirmation.
+type EqualTo struct {
+	Value interface{}
+	Field string
+}
+
+func (e EqualTo) IsSatisfied(obj interface{}) bool {
+	return reflect.DeepEqual(obj, e.Value)
+}
+
+func (e EqualTo) DefaultMessage() string {
+	if e.Field != "" {
+		return "Must match " + e.Field
+	}
+
+	return "Does not match"
+}
+
+// Requires a value to differ from Value, typically that of another field
+// named Field, as for a new password.
+type DifferentFrom struct {
+	Value interface{}
+	Field string
+}
+
+func (d DifferentFrom) IsSatisfied(obj interface{}) bool {
+	return !reflect.DeepEqual(obj, d.Value)
+}
+
+func (d DifferentFrom) DefaultMessage() string {
+	if d.Field != "" {
+		return "Must be different from " + d.Field
+	}
+
+	return "Must be different"
+}
+
+func (v *Validation) EqualField(a, b interface{}) *ValidationResult {
+	return v.apply(EqualTo{Value: b}, a)
+}
+
+func (v *Validation) DifferentField(a, b interface{}) *ValidationResult {
+	return v.apply(DifferentFrom{Value: b}, a)
+}
+
+// The validator of an eqfield, nefield, gtfield or ltfield tag, replaced
+// by EqualTo, DifferentFrom, GreaterThan or LessThan once the struct
+// holding the other field is known.
+type fieldRef struct {
+	Field string
+	tag   string
+}
+
+func (f fieldRef) IsSatisfied(obj interface{}) bool {
+	return f.IsSatisfiedCtx(context.Background(), obj)
+}
+
+func (f fieldRef) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	log.FromContext(ctx).Error("<Validation.ValidateStruct> ", "no field ", f.Field, " to compare with")
+	return false
+}
+
+func (f fieldRef) DefaultMessage() string {
+	return "Must be compared with " + f.Field
+}
+
+// Replace the fieldRefs among checks with comparisons to the fields of the
+// struct parent.
+func resolveFieldRefs(checks []Validator, parent reflect.Value) []Validator {
+	for i, chk := range checks {
+		ref, ok := chk.(fieldRef)
+		if !ok {
+			continue
+		}
+
+		other := parent.FieldByName(ref.Field)
+		if !other.IsValid() || !other.CanInterface() {
+			continue
+		}
+
+		switch value := fieldValue(other); ref.tag {
+		case "eqfield":
+			checks[i] = EqualTo{value, ref.Field}
+		case "nefield":
+			checks[i] = DifferentFrom{value, ref.Field}
+		case "gtfield":
+			checks[i] = GreaterThan{value, ref.Field}
+		case "ltfield":
+			checks[i] = LessThan{value, ref.Field}
+		}
+	}
+
+	return checks
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"golanger.com/framework/log"
+	"strings"
+	"testing"
+)
+
+func TestEqualAndDifferent(t *testing.T) {
+	v := &Validation{}
+	v.EqualField("secret", "secret").Key("same")
+	v.EqualField("secret", "Secret").Key("confirm")
+	v.DifferentField("old", "old").Key("new")
+	v.EqualField([]int{1, 2}, []int{1, 2}).Key("slices")
+	v.EqualField(1, int64(1)).Key("kinds")
+
+	errs := v.ErrorMap()
+	if len(errs) != 3 || errs["confirm"].Message != "Does not match" || errs["new"].Message != "Must be different" || errs["kinds"] == nil {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestFieldTags(t *testing.T) {
+	type change struct {
+		Old     string
+		New     string `validate:"required,nefield=Old"`
+		Confirm string `validate:"eqfield=New"`
+	}
+
+	v := &Validation{}
+	v.ValidateStruct(change{Old: "a", New: "a", Confirm: "b"})
+	errs := v.ErrorMap()
+	if len(errs) != 2 || errs["New"].Message != "Must be different from Old" || errs["Confirm"].Message != "Must match New" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	v = &Validation{}
+	v.ValidateStruct(change{Old: "a", New: "b", Confirm: "b"})
+	if v.HasErrors() {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestFieldTagMissingField(t *testing.T) {
+	var buf bytes.Buffer
+	v := (&Validation{}).SetContext(log.NewContext(context.Background(), log.New(log.NewTextHandler(&buf), log.LEVEL_ALL)))
+	v.ValidateStruct(struct {
+		Confirm string `validate:"eqfield=Nope"`
+	}{})
+
+	if r := v.ErrorMap()["Confirm"]; r == nil || r.Message != "Must be compared with Nope" || !strings.Contains(buf.String(), "no field Nope to compare with") {
+		t.Errorf("errors %v, logged %q", v.Errors, buf.String())
+	}
+}
@@ -0,0 +1,188 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+func fileHeader(obj interface{}) *multipart.FileHeader {
+	fh, _ := obj.(*multipart.FileHeader)
+	return fh
+}
+
+// An uploaded file the file validators accept besides a
+// *multipart.FileHeader, such as a part being streamed to storage: its
+// size, or the bytes read so far, and its first bytes, enough to sniff its
+// type and read its image dimensions.
+type File interface {
+	FileSize() int64
+	Head() []byte
+}
+
+// Return the first bytes, up to n, of an uploaded file.
+func readHead(obj interface{}, n int) ([]byte, error) {
+	if f, ok := obj.(File); ok {
+		return f.Head(), nil
+	}
+
+	fh := fileHeader(obj)
+	if fh == nil {
+		return nil, fmt.Errorf("not an uploaded file")
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, n)
+	read, err := io.ReadFull(f, buf)
+	if err != nil && read == 0 {
+		return nil, err
+	}
+
+	return buf[:read], nil
+}
+
+// Requires an uploaded file to be at most Bytes long.
+type MaxFileSize struct {
+	Bytes int64
+}
+
+func (m MaxFileSize) IsSatisfied(obj interface{}) bool {
+	if f, ok := obj.(File); ok {
+		return f.FileSize() <= m.Bytes
+	}
+
+	fh := fileHeader(obj)
+
+	return fh != nil && fh.Size <= m.Bytes
+}
+
+func (m MaxFileSize) DefaultMessage() string {
+	return fmt.Sprint("File must be at most ", formatBytes(m.Bytes))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprint(n>>20, " MB")
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprint(n>>10, " KB")
+	}
+
+	return fmt.Sprint(n, " bytes")
+}
+
+// Return the MIME type sniffed from the first bytes of an uploaded file,
+// ignoring its name and the type the client claimed.
+func SniffMIME(fh *multipart.FileHeader) (string, error) {
+	return sniff(fh)
+}
+
+func sniff(obj interface{}) (string, error) {
+	head, err := readHead(obj, 512)
+	if err != nil {
+		return "", err
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+
+	return mediaType, err
+}
+
+// Requires the content of an uploaded file to sniff as one of Types, such
+// as "image/png" or "image/*".
+type AllowedMIME struct {
+	Types []string
+}
+
+func (a AllowedMIME) IsSatisfied(obj interface{}) bool {
+	mediaType, err := sniff(obj)
+	if err != nil {
+		return false
+	}
+
+	for _, t := range a.Types {
+		if t == mediaType || (strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a AllowedMIME) DefaultMessage() string {
+	return fmt.Sprint("File must be of type ", strings.Join(a.Types, ", "))
+}
+
+// Requires an uploaded GIF, JPEG or PNG image to be at most MaxW by MaxH
+// pixels.  A zero limit is not checked.  The dimensions of a File are read
+// from its Head.
+type ImageDimensions struct {
+	MaxW, MaxH int
+}
+
+func (d ImageDimensions) IsSatisfied(obj interface{}) bool {
+	var cfg image.Config
+	var err error
+	if f, ok := obj.(File); ok {
+		cfg, _, err = image.DecodeConfig(bytes.NewReader(f.Head()))
+	} else if fh := fileHeader(obj); fh != nil {
+		var file multipart.File
+		if file, err = fh.Open(); err != nil {
+			return false
+		}
+
+		cfg, _, err = image.DecodeConfig(file)
+		file.Close()
+	} else {
+		return false
+	}
+
+	if err != nil {
+		return false
+	}
+
+	return (d.MaxW == 0 || cfg.Width <= d.MaxW) && (d.MaxH == 0 || cfg.Height <= d.MaxH)
+}
+
+func (d ImageDimensions) DefaultMessage() string {
+	switch {
+	case d.MaxH == 0:
+		return fmt.Sprintf("Must be an image at most %d pixels wide", d.MaxW)
+	case d.MaxW == 0:
+		return fmt.Sprintf("Must be an image at most %d pixels high", d.MaxH)
+	}
+
+	return fmt.Sprintf("Must be an image of at most %dx%d pixels", d.MaxW, d.MaxH)
+}
+
+// Apply a group of validators to an uploaded file, in order, as Check does.
+// A missing file fails the first check.
+func (v *Validation) File(fh *multipart.FileHeader, checks ...Validator) *ValidationResult {
+	result := &ValidationResult{Ok: true}
+	for _, check := range checks {
+		var obj interface{}
+		if fh != nil {
+			obj = fh
+		}
+
+		result = v.applyCaller(check, obj, 2)
+		if !result.Ok {
+			return result
+		}
+	}
+
+	return result
+}
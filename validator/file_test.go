@@ -0,0 +1,130 @@
+package validator
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"testing"
+)
+
+// Return the headers of files uploaded in a multipart form, by name.
+func uploaded(t *testing.T, files map[string][]byte) map[string]*multipart.FileHeader {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, content := range files {
+		part, _ := w.CreateFormFile(name, name+".bin")
+		part.Write(content)
+	}
+	w.Close()
+
+	form, err := multipart.NewReader(&body, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { form.RemoveAll() })
+
+	headers := map[string]*multipart.FileHeader{}
+	for name, fhs := range form.File {
+		headers[name] = fhs[0]
+	}
+
+	return headers
+}
+
+func pngOf(w, h int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h)))
+
+	return buf.Bytes()
+}
+
+type streamed struct {
+	size int64
+	head []byte
+}
+
+func (s streamed) FileSize() int64 { return s.size }
+func (s streamed) Head() []byte    { return s.head }
+
+func TestFileValidators(t *testing.T) {
+	fh := uploaded(t, map[string][]byte{
+		"avatar": pngOf(40, 20),
+		"notes":  []byte("plain words"),
+	})
+
+	cases := []struct {
+		check Validator
+		obj   interface{}
+		want  bool
+	}{
+		{MaxFileSize{1 << 10}, fh["avatar"], true},
+		{MaxFileSize{10}, fh["avatar"], false},
+		{MaxFileSize{10}, streamed{size: 11}, false},
+		{MaxFileSize{10}, "not a file", false},
+		{AllowedMIME{[]string{"image/png"}}, fh["avatar"], true},
+		{AllowedMIME{[]string{"image/*"}}, fh["avatar"], true},
+		{AllowedMIME{[]string{"image/*"}}, fh["notes"], false},
+		{AllowedMIME{[]string{"text/plain"}}, fh["notes"], true},
+		{AllowedMIME{[]string{"image/gif"}}, streamed{head: []byte("GIF89a")}, true},
+		{ImageDimensions{MaxW: 40, MaxH: 20}, fh["avatar"], true},
+		{ImageDimensions{MaxW: 39}, fh["avatar"], false},
+		{ImageDimensions{MaxH: 19}, fh["avatar"], false},
+		{ImageDimensions{MaxW: 100}, fh["notes"], false},
+		{ImageDimensions{MaxW: 100}, streamed{head: pngOf(200, 1)}, false},
+		{ImageDimensions{MaxW: 100}, nil, false},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.obj); got != c.want {
+			t.Errorf("%#v on %T = %v, want %v", c.check, c.obj, got, c.want)
+		}
+	}
+
+	if mediaType, err := SniffMIME(fh["notes"]); err != nil || mediaType != "text/plain" {
+		t.Errorf("SniffMIME = %s, %v", mediaType, err)
+	}
+}
+
+func TestFileMessages(t *testing.T) {
+	cases := []struct {
+		check Validator
+		want  string
+	}{
+		{MaxFileSize{2 << 20}, "File must be at most 2 MB"},
+		{MaxFileSize{3 << 10}, "File must be at most 3 KB"},
+		{MaxFileSize{1500}, "File must be at most 1500 bytes"},
+		{AllowedMIME{[]string{"image/png", "image/gif"}}, "File must be of type image/png, image/gif"},
+		{ImageDimensions{MaxW: 100}, "Must be an image at most 100 pixels wide"},
+		{ImageDimensions{MaxH: 50}, "Must be an image at most 50 pixels high"},
+		{ImageDimensions{100, 50}, "Must be an image of at most 100x50 pixels"},
+	}
+
+	for _, c := range cases {
+		if got := c.check.DefaultMessage(); got != c.want {
+			t.Errorf("%#v message = %q", c.check, got)
+		}
+	}
+}
+
+func TestValidationFile(t *testing.T) {
+	fh := uploaded(t, map[string][]byte{"avatar": pngOf(40, 20)})
+
+	v := &Validation{}
+	if !v.File(fh["avatar"], MaxFileSize{1 << 10}, AllowedMIME{[]string{"image/*"}}, ImageDimensions{64, 64}).Key("avatar").Ok {
+		t.Errorf("valid avatar: %v", v.Errors)
+	}
+
+	// The first failing check is the one recorded.
+	if r := v.File(fh["avatar"], AllowedMIME{[]string{"image/png"}}, MaxFileSize{10}, ImageDimensions{1, 1}).Key("big"); r.Ok || r.Error.Message != "File must be at most 10 bytes" {
+		t.Errorf("big avatar: %v", r.Error)
+	}
+
+	if r := v.File(nil, Required{}, MaxFileSize{10}).Key("missing"); r.Ok || r.Error.Message != "Required" {
+		t.Errorf("missing avatar: %v", r.Error)
+	}
+
+	if len(v.Errors) != 2 {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
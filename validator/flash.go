@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"encoding/json"
+	"golanger.com/framework/cookie"
+	"golanger.com/framework/flash"
+	"net/http"
+)
+
+// The session key and cookie name under which kept errors are carried to
+// the next request.
+const (
+	FlashSessionKey = "__VALIDATION"
+	FlashCookieName = "GOLANGER_VALIDATION"
+)
+
+// Report whether Keep was called, so the errors should survive a redirect.
+func (v *Validation) Kept() bool {
+	return v.keep
+}
+
+// Store the errors in session if Keep was called.
+func (v *Validation) SaveTo(session map[string]interface{}) {
+	if session == nil || !v.keep || !v.HasErrors() {
+		return
+	}
+
+	if b, err := json.Marshal(v.Errors); err == nil {
+		session[FlashSessionKey] = string(b)
+	}
+}
+
+// Load the errors kept by the previous request out of session and remove
+// them from it, so they are shown only once.
+func (v *Validation) RestoreFrom(session map[string]interface{}) {
+	if session == nil {
+		return
+	}
+
+	if s, ok := session[FlashSessionKey].(string); ok {
+		v.restore([]byte(s))
+	}
+
+	delete(session, FlashSessionKey)
+}
+
+// Write the errors to a flash cookie if Keep was called, signed once
+// cookie.Configure has been given keys.  The cookie is a response header,
+// so this must run before anything is written to w.
+func (v *Validation) SetCookie(w http.ResponseWriter) {
+	if !v.keep || !v.HasErrors() {
+		return
+	}
+
+	if b, err := json.Marshal(v.Errors); err == nil {
+		cookie.Set(w, &http.Cookie{
+			Name:     FlashCookieName,
+			Value:    string(b),
+			Path:     "/",
+			HttpOnly: true,
+		})
+	}
+}
+
+// Load the errors kept by the previous request from the flash cookie and
+// expire it.  Like SetCookie, this must run before anything is written.
+func (v *Validation) LoadCookie(w http.ResponseWriter, r *http.Request) {
+	value, err := cookie.Get(r, FlashCookieName)
+	if err == http.ErrNoCookie {
+		return
+	}
+
+	if err == nil {
+		v.restore([]byte(value))
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:   FlashCookieName,
+		Path:   "/",
+		MaxAge: -1,
+	})
+}
+
+// Carry the errors to the next request in f if Keep was called.
+func (v *Validation) SaveToFlash(f *flash.Flash) {
+	if f == nil || !v.keep || !v.HasErrors() {
+		return
+	}
+
+	if b, err := json.Marshal(v.Errors); err == nil {
+		f.SetData(FlashSessionKey, string(b))
+	}
+}
+
+// Load the errors kept by the previous request out of its flash.
+func (v *Validation) RestoreFromFlash(f *flash.Flash) {
+	if s := f.Data(FlashSessionKey); s != "" {
+		v.restore([]byte(s))
+	}
+}
+
+func (v *Validation) restore(b []byte) {
+	errs := []*ValidationError{}
+	if err := json.Unmarshal(b, &errs); err == nil {
+		v.Errors = append(v.Errors, errs...)
+	}
+}
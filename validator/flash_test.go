@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"golanger.com/framework/flash"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func failed() *Validation {
+	v := &Validation{}
+	v.Required("").Key("name")
+	v.Error("Taken").Key("email").Code("validation.taken")
+
+	return v
+}
+
+func TestSessionKeep(t *testing.T) {
+	session := map[string]interface{}{}
+	failed().SaveTo(session)
+	if len(session) != 0 {
+		t.Error("errors saved without Keep")
+	}
+
+	v := failed()
+	v.Keep()
+	v.SaveTo(session)
+	if !v.Kept() || session[FlashSessionKey] == nil {
+		t.Fatal("kept errors not saved")
+	}
+
+	next := &Validation{}
+	next.RestoreFrom(session)
+	errors := next.ErrorMap()
+	if len(next.Errors) != 2 || errors["name"].Message != "Required" || errors["email"].Code != "validation.taken" {
+		t.Errorf("restored %v", next.Errors)
+	}
+
+	if _, ok := session[FlashSessionKey]; ok {
+		t.Error("errors left in the session once restored")
+	}
+
+	next.RestoreFrom(nil)
+}
+
+func TestCookieKeep(t *testing.T) {
+	v := failed()
+	v.Keep()
+	w := httptest.NewRecorder()
+	v.SetCookie(w)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != FlashCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("cookies %v", cookies)
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	next := &Validation{}
+	next.LoadCookie(w, r)
+	if next.ErrorMap()["email"] == nil {
+		t.Errorf("restored %v", next.Errors)
+	}
+
+	if expired := w.Result().Cookies(); len(expired) != 1 || expired[0].MaxAge >= 0 {
+		t.Errorf("cookie not expired: %v", expired)
+	}
+
+	w = httptest.NewRecorder()
+	(&Validation{}).LoadCookie(w, httptest.NewRequest("GET", "/", nil))
+	if len(w.Result().Cookies()) != 0 {
+		t.Error("cookie expired without one")
+	}
+}
+
+func TestFlashKeep(t *testing.T) {
+	var got *Validation
+	h := flash.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = &Validation{}
+		got.RestoreFromFlash(flash.FromRequest(r))
+		if r.URL.Path == "/save" {
+			v := failed()
+			v.Keep()
+			v.SaveToFlash(flash.FromRequest(r))
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/save", nil))
+
+	r := httptest.NewRequest("GET", "/form", nil)
+	for _, ck := range w.Result().Cookies() {
+		r.AddCookie(ck)
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if len(got.Errors) != 2 || got.ErrorMap()["name"] == nil {
+		t.Errorf("restored %v", got.Errors)
+	}
+
+	failed().SaveToFlash(nil)
+	got.RestoreFromFlash(nil)
+}
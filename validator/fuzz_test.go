@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The field types validate tags are fuzzed on, with a value of each.
+var fuzzFields = []interface{}{"", "joe@example.com", 0, 42, 1.5, []string{"a", ""}, map[string]int{"a": 1}, (*string)(nil)}
+
+func FuzzParseTag(f *testing.F) {
+	for _, tag := range []string{
+		"required,min=3,max=10",
+		"required|scenario=create;update,email",
+		"len=4,numeric,oneof=a b c",
+		"dive,required,url",
+		"regexp=^[a-z]+$,between=1;5",
+		"eqfield=Other,gt=0,,=,min=",
+		",|scenario=,  ,max=x",
+	} {
+		f.Add(tag)
+	}
+
+	// Invalid rules are logged; the fuzzer makes plenty.
+	ctx := log.NewContext(context.Background(), log.New(log.NewTextHandler(io.Discard), log.LEVEL_DISABLE))
+	f.Fuzz(func(t *testing.T, tag string) {
+		rules := 0
+		for _, rule := range strings.Split(tag, ",") {
+			if strings.TrimSpace(rule) != "" {
+				rules++
+			}
+		}
+
+		for _, value := range fuzzFields {
+			ft := reflect.TypeOf(value)
+			checks := (&Validation{}).SetContext(ctx).Scenario("create").parseTag(ft, tag)
+			if len(checks) > rules {
+				t.Fatalf("%d checks from %d rules of %q", len(checks), rules, tag)
+			}
+
+			for _, chk := range checks {
+				if chk == nil {
+					t.Fatalf("nil check from %q", tag)
+				}
+			}
+
+			st := reflect.StructOf([]reflect.StructField{
+				{Name: "Field", Type: ft, Tag: reflect.StructTag(`validate:"` + strings.NewReplacer(`"`, `'`, "\\", "/").Replace(tag) + `"`)},
+				{Name: "Other", Type: ft},
+			})
+
+			obj := reflect.New(st).Elem()
+			obj.Field(0).Set(reflect.ValueOf(value))
+			v := (&Validation{}).SetContext(ctx)
+			v.ValidateStruct(obj.Interface())
+			for _, e := range v.Errors {
+				if e.Key != "Field" && !strings.HasPrefix(e.Key, "Field[") && !strings.HasPrefix(e.Key, "Field.") {
+					t.Fatalf("error keyed %q for %q on %T", e.Key, tag, value)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,253 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"golanger.com/framework/i18n"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A validation error in the format of the "errors" of a GraphQL response:
+// its path runs from the argument to the field, by the names of the
+// schema, e.g. ["input", "items", 0, "price"], and its extensions hold its
+// error code and key.  Resolvers may return it as is: gqlgen and
+// graphql-go use the Extensions method.
+type GraphQLError struct {
+	Message string
+	Path    []interface{}
+	Code    string
+	Key     string
+}
+
+func (e *GraphQLError) Error() string {
+	return e.Message
+}
+
+func (e *GraphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code, "field": e.Key}
+}
+
+func (e *GraphQLError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"message":    e.Message,
+		"path":       e.Path,
+		"extensions": e.Extensions(),
+	})
+}
+
+// The errors of a validation.  As a single error of a resolver, its
+// message is that of the first one and its extensions list them all under
+// "errors"; WriteGraphQLErrors writes them as separate errors.
+type GraphQLErrors []*GraphQLError
+
+func (errs GraphQLErrors) Error() string {
+	if len(errs) == 0 {
+		return ""
+	}
+
+	return errs[0].Message
+}
+
+func (errs GraphQLErrors) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "validation.failed", "errors": []*GraphQLError(errs)}
+}
+
+// Return the GraphQL name of a field: that of its json tag, which gqlgen
+// generates, or else its Go name.
+func graphqlName(sf reflect.StructField) string {
+	if name := strings.Split(sf.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+
+	return sf.Name
+}
+
+// Turn a key of ValidateStruct, e.g. "Items[0].Price", into a path of the
+// names nameOf gives the fields of schema type t, after prefix.
+func schemaPath(t reflect.Type, key string, prefix []interface{}, nameOf func(sf reflect.StructField) string) []interface{} {
+	path := append([]interface{}{}, prefix...)
+	for key != "" {
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if strings.HasPrefix(key, "[") {
+			end := strings.Index(key, "]")
+			if end < 0 {
+				break
+			}
+
+			index := key[1:end]
+			if n, err := strconv.Atoi(index); err == nil && t != nil && t.Kind() != reflect.Map {
+				path = append(path, n)
+			} else {
+				path = append(path, index)
+			}
+
+			if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map) {
+				t = t.Elem()
+			}
+
+			key = strings.TrimPrefix(key[end+1:], ".")
+			continue
+		}
+
+		end := strings.IndexAny(key, ".[")
+		if end < 0 {
+			end = len(key)
+		}
+
+		name := key[:end]
+		if t != nil && t.Kind() == reflect.Struct {
+			if sf, ok := t.FieldByName(name); ok {
+				name, t = nameOf(sf), sf.Type
+			} else {
+				t = nil
+			}
+		}
+
+		path = append(path, name)
+		key = strings.TrimPrefix(key[end:], ".")
+	}
+
+	return path
+}
+
+// Return the errors of v, which validated a value of the type of schema,
+// as GraphQL errors whose paths start with prefix, e.g. the name of the
+// argument.
+func (v *Validation) GraphQLErrors(schema interface{}, prefix ...interface{}) GraphQLErrors {
+	t, ok := schema.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(schema)
+	}
+
+	errs := GraphQLErrors{}
+	for _, e := range v.Errors {
+		errs = append(errs, &GraphQLError{
+			Message: e.Message,
+			Path:    schemaPath(t, e.Key, prefix, graphqlName),
+			Code:    e.Code,
+			Key:     e.Key,
+		})
+	}
+
+	return errs
+}
+
+// Check input, a struct or pointer to struct, by its `validate` tags, the
+// rules of the HTML forms, with the locale and context of ctx.  Return
+// nil, or the GraphQLErrors with paths starting at the argument arg.
+func ValidateGraphQL(ctx context.Context, arg string, input interface{}) error {
+	v := (&Validation{}).SetLocale(i18n.FromContext(ctx)).SetContext(ctx)
+	v.ValidateStruct(input)
+	if !v.HasErrors() {
+		return nil
+	}
+
+	return v.GraphQLErrors(input, arg)
+}
+
+// A resolver middleware: it is given the arguments of a field and runs
+// next, the resolver, or returns an error instead.
+type GraphQLFieldMiddleware func(ctx context.Context, args map[string]interface{}, next func(ctx context.Context) (interface{}, error)) (interface{}, error)
+
+// Return a resolver middleware checking every argument that is an input
+// of the type of schema, or a map of its fields as GraphQL servers decode
+// variables, by the `validate` tags of schema, and answering the
+// GraphQLErrors instead of resolving the field if any fails.  With gqlgen:
+//
+//	check := validator.GraphQLMiddleware(model.TransferInput{})
+//	srv.AroundFields(func(ctx context.Context, next graphql.Resolver) (interface{}, error) {
+//		return check(ctx, graphql.GetFieldContext(ctx).Args, next)
+//	})
+func GraphQLMiddleware(schema interface{}) GraphQLFieldMiddleware {
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("validator: GraphQLMiddleware needs a struct schema")
+	}
+
+	return func(ctx context.Context, args map[string]interface{}, next func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+		names := []string{}
+		for name := range args {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		errs := GraphQLErrors{}
+		for _, name := range names {
+			input := graphqlInput(t, args[name])
+			if input == nil {
+				continue
+			}
+
+			if err := ValidateGraphQL(ctx, name, input); err != nil {
+				errs = append(errs, err.(GraphQLErrors)...)
+			}
+		}
+
+		if len(errs) > 0 {
+			return nil, errs
+		}
+
+		return next(ctx)
+	}
+}
+
+// Return arg as a value of struct type t to validate, or nil if it is of
+// another type.
+func graphqlInput(t reflect.Type, arg interface{}) interface{} {
+	rv := reflect.ValueOf(arg)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.IsValid() && rv.Type() == t {
+		return rv.Interface()
+	}
+
+	if m, ok := arg.(map[string]interface{}); ok {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return nil
+		}
+
+		// A map with fields t does not have is the input of another type.
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		dst := reflect.New(t).Interface()
+		if dec.Decode(dst) != nil {
+			return nil
+		}
+
+		return dst
+	}
+
+	return nil
+}
+
+// Answer a GraphQL request that failed validation before any resolver
+// ran, as {"data": null, "errors": [...]}.  GraphQL over HTTP answers 200
+// whatever the errors.
+func WriteGraphQLErrors(w http.ResponseWriter, errs GraphQLErrors) error {
+	b, err := json.Marshal(map[string]interface{}{"data": nil, "errors": []*GraphQLError(errs)})
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(b)
+
+	return err
+}
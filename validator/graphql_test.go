@@ -0,0 +1,127 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"golanger.com/framework/i18n"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type lineInput struct {
+	SKU   string  `json:"sku" validate:"required"`
+	Price float64 `json:"price" validate:"gt=0"`
+}
+
+type orderInput struct {
+	Customer string            `json:"customer" validate:"required"`
+	Lines    []lineInput       `json:"lines" validate:"required,dive"`
+	Notes    map[string]string `json:"notes" validate:"dive,max=3"`
+	Coupon   string            `validate:"max=4"`
+}
+
+func TestGraphQLErrors(t *testing.T) {
+	v := (&Validation{}).ValidateStruct(orderInput{
+		Lines:  []lineInput{{SKU: "a", Price: 1}, {Price: -1}},
+		Notes:  map[string]string{"gift": "long note"},
+		Coupon: "TOOLONG",
+	})
+
+	want := map[string][]interface{}{
+		"Customer":       {"input", "customer"},
+		"Lines[1].SKU":   {"input", "lines", 1, "sku"},
+		"Lines[1].Price": {"input", "lines", 1, "price"},
+		"Notes[gift]":    {"input", "notes", "gift"},
+		"Coupon":         {"input", "Coupon"},
+	}
+
+	errs := v.GraphQLErrors(&orderInput{}, "input")
+	if len(errs) != len(want) {
+		t.Fatalf("errors %v", v.Errors)
+	}
+
+	for _, e := range errs {
+		if !reflect.DeepEqual(e.Path, want[e.Key]) {
+			t.Errorf("path of %s = %v", e.Key, e.Path)
+		}
+	}
+
+	b, _ := json.Marshal(errs[0])
+	if string(b) != `{"extensions":{"code":"validation.required","field":"Customer"},"message":"Required","path":["input","customer"]}` {
+		t.Errorf("JSON %s", b)
+	}
+
+	if errs.Error() != "Required" || len(errs.Extensions()["errors"].([]*GraphQLError)) != 5 || (GraphQLErrors{}).Error() != "" {
+		t.Errorf("Error %q, extensions %v", errs.Error(), errs.Extensions())
+	}
+}
+
+func TestValidateGraphQL(t *testing.T) {
+	i18n.Default.SetMessages("xg", map[string]string{"validation.required": "requis"})
+
+	var ctx context.Context
+	i18n.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/graphql?lang=xg", nil))
+
+	if err := ValidateGraphQL(ctx, "input", orderInput{Customer: "ann", Lines: []lineInput{{"a", 1}}}); err != nil {
+		t.Errorf("error %v", err)
+	}
+
+	err := ValidateGraphQL(ctx, "order", &orderInput{Lines: []lineInput{{"a", 1}}})
+	var errs GraphQLErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Message != "requis" || errs[0].Path[0] != "order" {
+		t.Errorf("error %v", err)
+	}
+}
+
+func TestGraphQLMiddleware(t *testing.T) {
+	check := GraphQLMiddleware(orderInput{})
+	resolved := 0
+	next := func(ctx context.Context) (interface{}, error) {
+		resolved++
+		return "ok", nil
+	}
+
+	args := map[string]interface{}{
+		"id":    7,
+		"input": map[string]interface{}{"customer": "ann", "lines": []interface{}{map[string]interface{}{"sku": "a", "price": 2}}},
+		"other": map[string]interface{}{"unknown": true},
+	}
+	if res, err := check(context.Background(), args, next); res != "ok" || err != nil || resolved != 1 {
+		t.Errorf("resolved %v, %v", res, err)
+	}
+
+	args = map[string]interface{}{
+		"a": &orderInput{Customer: "ann"},
+		"b": map[string]interface{}{"lines": []interface{}{}},
+	}
+	res, err := check(context.Background(), args, next)
+	errs, _ := err.(GraphQLErrors)
+	if res != nil || resolved != 1 || len(errs) != 3 || errs[0].Path[0] != "a" || errs[1].Path[0] != "b" {
+		t.Errorf("resolved %v, %v", res, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a schema that is not a struct")
+		}
+	}()
+	GraphQLMiddleware("input")
+}
+
+func TestWriteGraphQLErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+	errs := GraphQLErrors{{Message: "Required", Path: []interface{}{"input", "sku"}, Code: "validation.required", Key: "SKU"}}
+	if err := WriteGraphQLErrors(w, errs); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json; charset=utf-8" ||
+		w.Body.String() != `{"data":null,"errors":[{"extensions":{"code":"validation.required","field":"SKU"},"message":"Required","path":["input","sku"]}]}` {
+		t.Errorf("answered %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,14 @@
+// Package grpcvalidator checks gRPC messages by the same `validate` tags
+// and registered rules as the HTTP layer, in server interceptors that
+// answer an invalid request with INVALID_ARGUMENT and its field
+// violations.
+//
+// The package needs google.golang.org/grpc and google.golang.org/genproto,
+// which the rest of the framework does not, so it is only built with the
+// grpc build tag:
+//
+//	go get -tags grpc golanger.com/framework/validator/grpcvalidator
+//	go build -tags grpc
+//
+// Without the tag the package is empty.
+package grpcvalidator
@@ -0,0 +1,80 @@
+//go:build grpc
+// +build grpc
+
+package grpcvalidator
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"golanger.com/framework/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Check a message by the `validate` tags or registered rules of its type,
+// see validator.RegisterRules, as the HTTP layer checks the same types.
+// Return nil, or an INVALID_ARGUMENT status error whose message is that of
+// the first failure and whose google.rpc.BadRequest details list them all.
+func Validate(ctx context.Context, msg interface{}) error {
+	v := (&validator.Validation{}).SetContext(ctx)
+	v.ValidateStruct(msg)
+	if !v.HasErrors() {
+		return nil
+	}
+
+	br := &errdetails.BadRequest{}
+	for _, fv := range v.FieldViolations(msg) {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fv.Field,
+			Description: fv.Description,
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, v.Errors[0].Message)
+	detailed, err := st.WithDetails(br)
+	if err != nil {
+		log.FromContext(ctx).Error("<grpcvalidator.Validate> ", err)
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// Return an interceptor validating the request of every unary call before
+// its handler runs:
+//
+//	grpc.NewServer(
+//		grpc.UnaryInterceptor(grpcvalidator.UnaryServerInterceptor()),
+//		grpc.StreamInterceptor(grpcvalidator.StreamServerInterceptor()),
+//	)
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := Validate(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Return an interceptor validating every message a stream receives; the
+// handler gets the error of the first invalid one from RecvMsg.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatingStream{ss})
+	}
+}
+
+type validatingStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatingStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return Validate(s.Context(), m)
+}
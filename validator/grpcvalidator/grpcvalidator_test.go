@@ -0,0 +1,63 @@
+//go:build grpc
+// +build grpc
+
+package grpcvalidator
+
+import (
+	"context"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+type createUser struct {
+	UserName string `protobuf:"bytes,1,opt,name=user_name,json=userName" validate:"required"`
+	Email    string `protobuf:"bytes,2,opt,name=email" validate:"required,email"`
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(context.Background(), &createUser{"joe", "joe@example.com"}); err != nil {
+		t.Fatalf("error %v for a valid message", err)
+	}
+
+	st := status.Convert(Validate(context.Background(), &createUser{"", "nope"}))
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code %v, want InvalidArgument", st.Code())
+	}
+
+	if len(st.Details()) != 1 {
+		t.Fatalf("details %v", st.Details())
+	}
+
+	br, ok := st.Details()[0].(*errdetails.BadRequest)
+	if !ok || len(br.FieldViolations) != 2 {
+		t.Fatalf("details %v, want a BadRequest with two violations", st.Details())
+	}
+
+	if f := br.FieldViolations[0].Field; f != "user_name" {
+		t.Errorf("field %q, want the proto name user_name", f)
+	}
+
+	if st.Message() != br.FieldViolations[0].Description {
+		t.Errorf("message %q is not that of the first violation", st.Message())
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return req, nil
+	}
+
+	intercept := UnaryServerInterceptor()
+	if _, err := intercept(context.Background(), &createUser{}, &grpc.UnaryServerInfo{}, handler); err == nil || called {
+		t.Errorf("invalid request passed to the handler, error %v", err)
+	}
+
+	if _, err := intercept(context.Background(), &createUser{"joe", "joe@example.com"}, &grpc.UnaryServerInfo{}, handler); err != nil || !called {
+		t.Errorf("valid request not handled, error %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"sync"
+)
+
+var failureHooks = struct {
+	sync.RWMutex
+	list   []func(key, code string)
+	errors []func(e *ValidationError)
+}{}
+
+// Call f with the key and error code of every failed check, e.g. to count
+// how often each rule rejects users.  A check without a key yet is passed
+// once Key is called on its result, and never if it is not.  f must be
+// safe for concurrent use.
+func OnFailure(f func(key, code string)) {
+	failureHooks.Lock()
+	failureHooks.list = append(failureHooks.list, f)
+	failureHooks.Unlock()
+}
+
+// Call f with the error of every failed check of every Validation, e.g.
+// to write an audit log, at the same time as the OnFailure hooks.  f must
+// be safe for concurrent use and must not modify the error.
+func OnError(f func(e *ValidationError)) {
+	failureHooks.Lock()
+	failureHooks.errors = append(failureHooks.errors, f)
+	failureHooks.Unlock()
+}
+
+// Call f with the error of every failed check of v, after the package
+// hooks.  The children of v call it too, from their own goroutines.
+func (v *Validation) OnError(f func(e *ValidationError)) *Validation {
+	v.errorHooks = append(v.errorHooks, f)
+
+	return v
+}
+
+func (v *Validation) reportFailure(e *ValidationError) {
+	failureHooks.RLock()
+	list, errs := failureHooks.list, failureHooks.errors
+	failureHooks.RUnlock()
+
+	for _, f := range list {
+		f(e.Key, e.Code)
+	}
+
+	for _, f := range errs {
+		f(e)
+	}
+
+	for _, f := range v.errorHooks {
+		f(e)
+	}
+}
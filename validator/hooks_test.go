@@ -0,0 +1,139 @@
+package validator
+
+import (
+	"sync"
+	"testing"
+)
+
+// Collect the errors passed to the hooks of a new Validation.
+func hooked() (*Validation, *[]ValidationError) {
+	reported := []ValidationError{}
+	v := (&Validation{}).OnError(func(e *ValidationError) {
+		reported = append(reported, *e)
+	})
+
+	return v, &reported
+}
+
+func TestErrorReportedOnKey(t *testing.T) {
+	v, reported := hooked()
+	result := v.Error("{field} is taken")
+	if len(*reported) != 0 {
+		t.Fatalf("reported before Key: %v", *reported)
+	}
+
+	result.Key("email").Code("validation.taken")
+	if len(*reported) != 1 || (*reported)[0].Key != "email" {
+		t.Fatalf("reported %v, want one error keyed email", *reported)
+	}
+
+	if msg := v.Errors[0].Message; msg != "email is taken" {
+		t.Errorf("message %q not rendered with the key", msg)
+	}
+
+	result.Key("login")
+	if len(*reported) != 1 {
+		t.Errorf("reported again on a second Key: %v", *reported)
+	}
+}
+
+func TestCheckMergedReported(t *testing.T) {
+	v, reported := hooked()
+	v.CheckMerged("name", "", Required{}, MinSize{3})
+	if len(*reported) != 1 {
+		t.Fatalf("reported %v, want one merged error", *reported)
+	}
+
+	e := (*reported)[0]
+	if e.Key != "name" || e.Code != "validation.required" {
+		t.Errorf("reported %+v, want key name and the code of the first failed check", e)
+	}
+}
+
+func TestEachReportedOnKey(t *testing.T) {
+	v, reported := hooked()
+	r := v.Each([]string{"a@example.com", "nope", ""}, Required{}, NewEmail())
+	if len(*reported) != 0 {
+		t.Fatalf("reported before Key: %v", *reported)
+	}
+
+	r.Key("emails")
+	keys := []string{}
+	for _, e := range *reported {
+		keys = append(keys, e.Key)
+	}
+
+	if len(keys) != 2 || keys[0] != "emails[1]" || keys[1] != "emails[2]" {
+		t.Errorf("reported keys %v, want [emails[1] emails[2]]", keys)
+	}
+
+	if got := v.ErrorMap(); got["emails[1]"] == nil || got["emails[2]"] == nil {
+		t.Errorf("errors %v not keyed by element", got)
+	}
+}
+
+func TestEachMessageRenderedWithKey(t *testing.T) {
+	v := &Validation{}
+	v.Each([]int{5}, Min{10}).Key("counts")
+	r := v.Errors[0]
+	if r.Key != "counts[0]" {
+		t.Fatalf("key %q", r.Key)
+	}
+
+	SetMessages("x-hooks", map[string]string{"Min": "{field} must be at least {min}"})
+	v = (&Validation{}).SetLocale("x-hooks")
+	v.Each([]int{5}, Min{10}).Key("counts")
+	if msg := v.Errors[0].Message; msg != "counts[0] must be at least 10" {
+		t.Errorf("message %q not rendered with the element key", msg)
+	}
+}
+
+func TestValidateMapValuesReportedOnKey(t *testing.T) {
+	v, reported := hooked()
+	r := v.ValidateMapValues(map[string]int{"apple": -1, "pear": 3, "fig": -2}, Min{0})
+	if r.Ok || len(*reported) != 0 {
+		t.Fatalf("ok %v, reported before Key: %v", r.Ok, *reported)
+	}
+
+	r.Key("prices")
+	if len(*reported) != 2 || (*reported)[0].Key != "prices[apple]" || (*reported)[1].Key != "prices[fig]" {
+		t.Errorf("reported %v, want prices[apple] and prices[fig]", *reported)
+	}
+}
+
+func TestEachCallerKeysReportedOnce(t *testing.T) {
+	v, reported := hooked()
+	v.CallerKeys(true)
+	v.Each([]string{""}, Required{}).Key("tags")
+	if len(*reported) != 1 {
+		t.Fatalf("reported %v, want once", *reported)
+	}
+
+	if v.Errors[0].Key != "tags[0]" {
+		t.Errorf("key %q, want tags[0]", v.Errors[0].Key)
+	}
+}
+
+func TestOnFailureGetsKeyAndCode(t *testing.T) {
+	var mutex sync.Mutex
+	seen := map[string]string{}
+	OnFailure(func(key, code string) {
+		mutex.Lock()
+		seen[key] = code
+		mutex.Unlock()
+	})
+
+	v := &Validation{}
+	v.Error("Bad").Code("validation.custom").Key("TestOnFailureGetsKeyAndCode")
+	v.CheckMerged("TestOnFailureGetsKeyAndCode.merged", "", Required{})
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if seen["TestOnFailureGetsKeyAndCode"] != "validation.custom" {
+		t.Errorf("OnFailure got %v", seen)
+	}
+
+	if seen["TestOnFailureGetsKeyAndCode.merged"] != "validation.required" {
+		t.Errorf("OnFailure got %v for CheckMerged", seen)
+	}
+}
@@ -0,0 +1,41 @@
+package validator
+
+import (
+	"encoding/json"
+)
+
+type jsonError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+func (e ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonError{
+		Field:   e.Key,
+		Message: e.Message,
+		Code:    e.Code,
+	})
+}
+
+func (e *ValidationError) UnmarshalJSON(b []byte) error {
+	var je jsonError
+	if err := json.Unmarshal(b, &je); err != nil {
+		return err
+	}
+
+	e.Key, e.Message, e.Code = je.Field, je.Message, je.Code
+
+	return nil
+}
+
+// Encode the errors as a JSON array of {"field", "message", "code"}
+// objects, for API responses.
+func (v *Validation) ToJSON() ([]byte, error) {
+	errs := v.Errors
+	if errs == nil {
+		errs = []*ValidationError{}
+	}
+
+	return json.Marshal(errs)
+}
@@ -0,0 +1,36 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToJSON(t *testing.T) {
+	v := &Validation{}
+	if b, err := v.ToJSON(); err != nil || string(b) != "[]" {
+		t.Errorf("ToJSON = %s, %v", b, err)
+	}
+
+	v.Required("").Key("name")
+	v.Error("Taken").Code("user.taken").Key("email")
+	b, err := v.ToJSON()
+	want := `[{"field":"name","message":"Required","code":"validation.required"},{"field":"email","message":"Taken","code":"user.taken"}]`
+	if err != nil || string(b) != want {
+		t.Errorf("ToJSON = %s, %v", b, err)
+	}
+
+	var errs []*ValidationError
+	if err := json.Unmarshal(b, &errs); err != nil || !reflect.DeepEqual(errs, v.Errors) {
+		t.Errorf("decoded %v, %v", errs, err)
+	}
+
+	// A ValidationError encodes the same by value.
+	if b, _ := json.Marshal(*v.Errors[0]); string(b) != `{"field":"name","message":"Required","code":"validation.required"}` {
+		t.Errorf("encoded %s", b)
+	}
+
+	if err := json.Unmarshal([]byte(`{"field": 1}`), &ValidationError{}); err == nil {
+		t.Error("no error decoding a numeric field")
+	}
+}
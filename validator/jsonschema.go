@@ -0,0 +1,514 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Return the JSON document of a string, []byte or json.RawMessage.
+func jsonBytes(obj interface{}) ([]byte, bool) {
+	switch b := obj.(type) {
+	case string:
+		return []byte(b), true
+	case []byte:
+		return b, true
+	case json.RawMessage:
+		return b, true
+	}
+
+	return nil, false
+}
+
+// Requires a string, []byte or json.RawMessage to be well-formed JSON.
+type ValidJSON struct{}
+
+func (j ValidJSON) IsSatisfied(obj interface{}) bool {
+	b, ok := jsonBytes(obj)
+
+	return ok && json.Valid(b)
+}
+
+func (j ValidJSON) DefaultMessage() string {
+	return "Must be valid JSON"
+}
+
+// Requires a string, []byte or json.RawMessage to be JSON matching Schema,
+// a JSON Schema such as SchemaFor(x).JSON() returns.  The keywords
+// understood are type, enum, const, properties, required,
+// additionalProperties, items, minItems, maxItems, uniqueItems, minLength,
+// maxLength, pattern (checked as SafeMatch), minimum, maximum,
+// exclusiveMinimum, exclusiveMaximum, multipleOf, format (email, uri,
+// ipv4, ipv6, uuid, date, date-time), allOf, anyOf, oneOf, not, and $ref
+// to "#/definitions/..." or "#/$defs/..."; others are ignored.  Each
+// schema is parsed once.
+type JSONSchema struct {
+	Schema []byte
+}
+
+var schemaCache = struct {
+	sync.Mutex
+	entries map[string]schemaEntry
+}{entries: map[string]schemaEntry{}}
+
+type schemaEntry struct {
+	root interface{}
+	err  error
+}
+
+func parseSchema(schema []byte) (interface{}, error) {
+	key := string(schema)
+	schemaCache.Lock()
+	e, found := schemaCache.entries[key]
+	schemaCache.Unlock()
+	if found {
+		return e.root, e.err
+	}
+
+	e.err = json.Unmarshal(schema, &e.root)
+	schemaCache.Lock()
+	if len(schemaCache.entries) >= PatternCacheSize {
+		schemaCache.entries = map[string]schemaEntry{}
+	}
+
+	schemaCache.entries[key] = e
+	schemaCache.Unlock()
+
+	return e.root, e.err
+}
+
+// Return a description of each way the document breaks the schema, such
+// as "/tags/2: must be a string", or nil if it matches.  A document that
+// is not JSON, or a schema that is not, is a problem too.
+func (j JSONSchema) Problems(obj interface{}) []string {
+	root, err := parseSchema(j.Schema)
+	if err != nil {
+		return []string{"invalid schema: " + err.Error()}
+	}
+
+	b, ok := jsonBytes(obj)
+	if !ok {
+		return []string{"not a JSON document"}
+	}
+
+	var doc interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(&doc); err != nil || d.More() {
+		return []string{"invalid JSON"}
+	}
+
+	s := &schemaCheck{root: root}
+	s.check(root, doc, "")
+	if len(s.problems) == 0 {
+		return nil
+	}
+
+	return s.problems
+}
+
+func (j JSONSchema) IsSatisfied(obj interface{}) bool {
+	return j.Problems(obj) == nil
+}
+
+func (j JSONSchema) DefaultMessage() string {
+	return "Must be JSON of the expected structure"
+}
+
+// The schema is of no use to a client as a parameter.
+func (j JSONSchema) Rules() Rule {
+	return Rule{Name: "json_schema"}
+}
+
+// Check a JSON document against a JSON Schema.  The message names the
+// problems found, as in "Must be JSON of the expected structure: /name:
+// required", unless a translation of JSONSchema replaces it.
+func (v *Validation) JSONSchema(doc interface{}, schema []byte) *ValidationResult {
+	check := JSONSchema{schema}
+	result := v.apply(check, doc)
+	if !result.Ok && result.Error.Message == check.DefaultMessage() {
+		if problems := check.Problems(doc); problems != nil {
+			result.Message("%s: %s", check.DefaultMessage(), strings.Join(problems, "; "))
+		}
+	}
+
+	return result
+}
+
+type schemaCheck struct {
+	root     interface{}
+	problems []string
+	depth    int
+}
+
+func (s *schemaCheck) fail(path, format string, args ...interface{}) {
+	if path == "" {
+		path = "/"
+	}
+
+	s.problems = append(s.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+// Report whether doc matches schema, without recording problems.
+func (s *schemaCheck) matches(schema, doc interface{}) bool {
+	sub := &schemaCheck{root: s.root, depth: s.depth}
+	sub.check(schema, doc, "")
+
+	return len(sub.problems) == 0
+}
+
+func (s *schemaCheck) resolve(ref string) (interface{}, bool) {
+	if ref == "#" {
+		return s.root, true
+	}
+
+	node := s.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if node, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+
+	return node, true
+}
+
+func jsonType(doc interface{}) string {
+	switch d := doc.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := d.Float64(); err == nil && f == math.Trunc(f) {
+			return "integer"
+		}
+
+		return "number"
+	case []interface{}:
+		return "array"
+	}
+
+	return "object"
+}
+
+func hasType(doc interface{}, typ string) bool {
+	t := jsonType(doc)
+
+	return t == typ || typ == "number" && t == "integer"
+}
+
+func schemaNumber(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func docNumber(doc interface{}) (float64, bool) {
+	n, ok := doc.(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	f, err := n.Float64()
+
+	return f, err == nil
+}
+
+// Compare JSON values as JSON does: numbers by value, whatever their
+// decoding.
+func jsonEqual(a, b interface{}) bool {
+	fa, aNum := docNumber(a)
+	if !aNum {
+		fa, aNum = schemaNumber(a)
+	}
+
+	fb, bNum := docNumber(b)
+	if !bNum {
+		fb, bNum = schemaNumber(b)
+	}
+
+	if aNum || bNum {
+		return aNum && bNum && fa == fb
+	}
+
+	switch av := a.(type) {
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k, x := range av {
+			if y, ok := bv[k]; !ok || !jsonEqual(x, y) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return a == b
+}
+
+var jsonFormats = map[string]Validator{
+	"email": NewEmail(),
+	"uri":   URL{},
+	"ipv4":  IPv4{},
+	"ipv6":  IPv6{},
+	"uuid":  UUID{},
+	"date":  DateFormat{"2006-01-02"},
+}
+
+func (s *schemaCheck) check(schema, doc interface{}, path string) {
+	if b, ok := schema.(bool); ok {
+		if !b {
+			s.fail(path, "not allowed")
+		}
+
+		return
+	}
+
+	sch, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if ref, ok := sch["$ref"].(string); ok {
+		target, found := s.resolve(ref)
+		if !found {
+			s.fail(path, "unresolved $ref %s", ref)
+			return
+		}
+
+		if s.depth++; s.depth > 100 {
+			s.fail(path, "$ref nested too deep")
+			return
+		}
+
+		s.check(target, doc, path)
+		s.depth--
+	}
+
+	switch t := sch["type"].(type) {
+	case string:
+		if !hasType(doc, t) {
+			s.fail(path, "must be of type %s", t)
+			return
+		}
+	case []interface{}:
+		types := []string{}
+		matched := false
+		for _, typ := range t {
+			if name, ok := typ.(string); ok {
+				types = append(types, name)
+				matched = matched || hasType(doc, name)
+			}
+		}
+
+		if !matched {
+			s.fail(path, "must be of type %s", strings.Join(types, " or "))
+			return
+		}
+	}
+
+	if enum, ok := sch["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			found = found || jsonEqual(e, doc)
+		}
+
+		if !found {
+			s.fail(path, "must be one of the allowed values")
+		}
+	}
+
+	if c, ok := sch["const"]; ok && !jsonEqual(c, doc) {
+		s.fail(path, "must be %v", c)
+	}
+
+	switch d := doc.(type) {
+	case string:
+		s.checkString(sch, d, path)
+	case json.Number:
+		s.checkNumber(sch, d, path)
+	case []interface{}:
+		s.checkArray(sch, d, path)
+	case map[string]interface{}:
+		s.checkObject(sch, d, path)
+	}
+
+	s.checkCombinations(sch, doc, path)
+}
+
+func (s *schemaCheck) checkString(sch map[string]interface{}, d, path string) {
+	n := utf8.RuneCountInString(d)
+	if min, ok := schemaNumber(sch["minLength"]); ok && float64(n) < min {
+		s.fail(path, "must be at least %v characters long", min)
+	}
+
+	if max, ok := schemaNumber(sch["maxLength"]); ok && float64(n) > max {
+		s.fail(path, "must be at most %v characters long", max)
+	}
+
+	if pattern, ok := sch["pattern"].(string); ok {
+		if _, err := CompilePattern(pattern); err != nil {
+			s.fail(path, "unusable pattern %s: %v", pattern, err)
+		} else if !(SafeMatch{Pattern: pattern}).IsSatisfied(d) {
+			s.fail(path, "must match %s", pattern)
+		}
+	}
+
+	if format, ok := sch["format"].(string); ok {
+		if format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, d); err != nil {
+				s.fail(path, "must be a date-time")
+			}
+		} else if chk, known := jsonFormats[format]; known && !chk.IsSatisfied(d) {
+			s.fail(path, "must be a valid %s", format)
+		}
+	}
+}
+
+func (s *schemaCheck) checkNumber(sch map[string]interface{}, d json.Number, path string) {
+	f, _ := d.Float64()
+	if min, ok := schemaNumber(sch["minimum"]); ok && f < min {
+		s.fail(path, "must be at least %v", min)
+	}
+
+	if max, ok := schemaNumber(sch["maximum"]); ok && f > max {
+		s.fail(path, "must be at most %v", max)
+	}
+
+	if min, ok := schemaNumber(sch["exclusiveMinimum"]); ok && f <= min {
+		s.fail(path, "must be greater than %v", min)
+	}
+
+	if max, ok := schemaNumber(sch["exclusiveMaximum"]); ok && f >= max {
+		s.fail(path, "must be less than %v", max)
+	}
+
+	if m, ok := schemaNumber(sch["multipleOf"]); ok && m > 0 {
+		if q := f / m; math.Abs(q-math.Round(q)) > floatEpsilon {
+			s.fail(path, "must be a multiple of %v", m)
+		}
+	}
+}
+
+func (s *schemaCheck) checkArray(sch map[string]interface{}, d []interface{}, path string) {
+	if min, ok := schemaNumber(sch["minItems"]); ok && float64(len(d)) < min {
+		s.fail(path, "must have at least %v items", min)
+	}
+
+	if max, ok := schemaNumber(sch["maxItems"]); ok && float64(len(d)) > max {
+		s.fail(path, "must have at most %v items", max)
+	}
+
+	if unique, _ := sch["uniqueItems"].(bool); unique {
+		for i := 1; i < len(d); i++ {
+			for j := 0; j < i; j++ {
+				if jsonEqual(d[i], d[j]) {
+					s.fail(fmt.Sprint(path, "/", i), "duplicates item %d", j)
+				}
+			}
+		}
+	}
+
+	if items, ok := sch["items"]; ok {
+		for i, item := range d {
+			s.check(items, item, fmt.Sprint(path, "/", i))
+		}
+	}
+}
+
+func (s *schemaCheck) checkObject(sch map[string]interface{}, d map[string]interface{}, path string) {
+	if required, ok := sch["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := d[key]; !present {
+					s.fail(path+"/"+key, "required")
+				}
+			}
+		}
+	}
+
+	props, _ := sch["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(d))
+	for k := range d {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		if prop, ok := props[k]; ok {
+			s.check(prop, d[k], path+"/"+k)
+		} else if extra, ok := sch["additionalProperties"]; ok {
+			if b, isBool := extra.(bool); isBool && !b {
+				s.fail(path+"/"+k, "unknown property")
+			} else {
+				s.check(extra, d[k], path+"/"+k)
+			}
+		}
+	}
+}
+
+func (s *schemaCheck) checkCombinations(sch map[string]interface{}, doc interface{}, path string) {
+	if all, ok := sch["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			s.check(sub, doc, path)
+		}
+	}
+
+	if anyOf, ok := sch["anyOf"].([]interface{}); ok {
+		matched := false
+		for _, sub := range anyOf {
+			matched = matched || s.matches(sub, doc)
+		}
+
+		if !matched {
+			s.fail(path, "must match one of the anyOf schemas")
+		}
+	}
+
+	if one, ok := sch["oneOf"].([]interface{}); ok {
+		n := 0
+		for _, sub := range one {
+			if s.matches(sub, doc) {
+				n++
+			}
+		}
+
+		if n != 1 {
+			s.fail(path, "must match exactly one of the oneOf schemas, matches %d", n)
+		}
+	}
+
+	if not, ok := sch["not"]; ok && s.matches(not, doc) {
+		s.fail(path, "must not match the not schema")
+	}
+}
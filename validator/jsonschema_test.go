@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidJSON(t *testing.T) {
+	cases := []struct {
+		obj  interface{}
+		want bool
+	}{
+		{`{"a": [1, true, null]}`, true},
+		{[]byte(`"x"`), true},
+		{json.RawMessage(`3`), true},
+		{`{"a": }`, false},
+		{``, false},
+		{3, false},
+	}
+
+	for _, c := range cases {
+		if got := (ValidJSON{}).IsSatisfied(c.obj); got != c.want {
+			t.Errorf("ValidJSON(%v) = %v", c.obj, got)
+		}
+	}
+
+	type doc struct {
+		Body string `validate:"json"`
+	}
+	if v := (&Validation{}).ValidateStruct(doc{"{"}); v.ErrorMap()["Body"] == nil {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+const userSchema = `{
+	"type": "object",
+	"required": ["name", "tags"],
+	"additionalProperties": false,
+	"properties": {
+		"name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+		"age": {"type": "integer", "minimum": 0, "exclusiveMaximum": 150},
+		"email": {"type": "string", "format": "email"},
+		"born": {"type": "string", "format": "date-time"},
+		"price": {"type": "number", "multipleOf": 0.01},
+		"role": {"enum": ["admin", "user"]},
+		"tags": {"type": "array", "maxItems": 3, "uniqueItems": true, "items": {"$ref": "#/definitions/tag"}},
+		"id": {"oneOf": [{"type": "integer"}, {"type": "string", "format": "uuid"}]},
+		"note": {"type": ["string", "null"], "not": {"const": "secret"}}
+	},
+	"definitions": {
+		"tag": {"type": "string", "maxLength": 5}
+	}
+}`
+
+func TestJSONSchemaProblems(t *testing.T) {
+	cases := []struct {
+		doc  string
+		want []string
+	}{
+		{`{"name": "ann", "tags": [], "age": 30, "price": 9.99, "role": "admin", "id": 7, "note": null, "born": "2020-01-02T03:04:05Z"}`, nil},
+		{`{"tags": ["a"]}`, []string{"/name: required"}},
+		{`{"name": "A", "tags": "x"}`, []string{"/name: must be at least 2 characters long", "/name: must match ^[a-z]+$", "/tags: must be of type array"}},
+		{`{"name": "ann", "tags": ["a", "b", "a", "toolong"]}`, []string{"/tags: must have at most 3 items", "/tags/2: duplicates item 0", "/tags/3: must be at most 5 characters long"}},
+		{`{"name": "ann", "tags": [], "age": 150, "price": 1.005}`, []string{"/age: must be less than 150", "/price: must be a multiple of 0.01"}},
+		{`{"name": "ann", "tags": [], "age": 1.5, "email": "nope", "born": "yesterday"}`, []string{"/age: must be of type integer", "/born: must be a date-time", "/email: must be a valid email"}},
+		{`{"name": "ann", "tags": [], "role": "root", "id": "x", "note": "secret", "extra": 1}`, []string{"/extra: unknown property", "/id: must match exactly one of the oneOf schemas, matches 0", "/note: must not match the not schema", "/role: must be one of the allowed values"}},
+		{`[1]`, []string{"/: must be of type object"}},
+		{`{"name": "ann"} {}`, []string{"invalid JSON"}},
+	}
+
+	check := JSONSchema{[]byte(userSchema)}
+	for _, c := range cases {
+		if got := check.Problems(c.doc); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Problems(%s) = %q, want %q", c.doc, got, c.want)
+		}
+	}
+
+	if got := check.Problems(1); !reflect.DeepEqual(got, []string{"not a JSON document"}) {
+		t.Errorf("Problems(1) = %q", got)
+	}
+
+	if got := (JSONSchema{[]byte(`{`)}).Problems(`{}`); len(got) != 1 || !strings.HasPrefix(got[0], "invalid schema: ") {
+		t.Errorf("Problems with an invalid schema = %q", got)
+	}
+
+	cycle := JSONSchema{[]byte(`{"$defs": {"a": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`)}
+	if got := cycle.Problems(`1`); !reflect.DeepEqual(got, []string{"/: $ref nested too deep"}) {
+		t.Errorf("Problems of a cycle = %q", got)
+	}
+
+	if got := (JSONSchema{[]byte(`{"$ref": "#/nowhere"}`)}).Problems(`1`); !reflect.DeepEqual(got, []string{"/: unresolved $ref #/nowhere"}) {
+		t.Errorf("Problems of a missing $ref = %q", got)
+	}
+}
+
+func TestValidationJSONSchema(t *testing.T) {
+	v := &Validation{}
+	schema := []byte(userSchema)
+	if !v.JSONSchema(`{"name": "ann", "tags": []}`, schema).Key("ok").Ok {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if r := v.JSONSchema(`{"tags": [1]}`, schema).Key("user"); r.Ok || r.Error.Message != "Must be JSON of the expected structure: /name: required; /tags/0: must be of type string" {
+		t.Errorf("error %v", r.Error)
+	}
+
+	// A schema generated by SchemaFor checks documents of its struct.
+	type item struct {
+		SKU string `json:"sku" validate:"required,max=8"`
+		Qty int    `json:"qty" validate:"min=1"`
+	}
+
+	generated, err := SchemaFor(item{}).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := JSONSchema{generated}
+	if !check.IsSatisfied(`{"sku": "A1", "qty": 2}`) || check.IsSatisfied(`{"sku": "", "qty": 0}`) {
+		t.Errorf("wrong answers from %s", generated)
+	}
+}
@@ -0,0 +1,47 @@
+package validator
+
+import (
+	"golanger.com/framework/i18n"
+	"regexp"
+)
+
+// Set the human-readable label of a key, such as "Date of birth" for
+// "dob", for the {label} placeholder of messages:
+//
+//	v.SetLabel("dob", "Date of birth")
+//	v.Required(dob).Key("dob").Message("{label} is required")
+//
+// ValidateStruct sets the labels of the `label` tags of fields, unless
+// already set.
+func (v *Validation) SetLabel(key, label string) *Validation {
+	if v.labels == nil {
+		v.labels = map[string]string{}
+	}
+
+	v.labels[key] = label
+
+	return v
+}
+
+var indexPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// Return the label of a key: its translation under "label." and the key
+// (e.g. "label.dob") in the locale of the Validation, else the label set
+// with SetLabel, else the key itself.  A key with indexes, such as
+// "Items[2].Name", falls back to the label of "Items[].Name" and then of
+// "Items.Name".
+func (v *Validation) Label(key string) string {
+	for _, k := range []string{key, indexPattern.ReplaceAllString(key, "[]"), indexPattern.ReplaceAllString(key, "")} {
+		if v.locale != "" {
+			if label, ok := i18n.Default.Lookup(v.locale, "label."+k); ok {
+				return label
+			}
+		}
+
+		if label, ok := v.labels[k]; ok {
+			return label
+		}
+	}
+
+	return key
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"golanger.com/framework/i18n"
+	"testing"
+)
+
+func TestLabels(t *testing.T) {
+	i18n.Default.SetMessages("xl", map[string]string{"label.dob": "Data de nascimento"})
+	v := (&Validation{}).SetLabel("dob", "Date of birth").SetLabel("Items.Name", "Item name").SetLabel("Items[].SKU", "Item SKU")
+
+	cases := []struct {
+		key, want string
+	}{
+		{"dob", "Date of birth"},
+		{"Items[2].Name", "Item name"},
+		{"Items[0].SKU", "Item SKU"},
+		{"Items[0].Qty", "Items[0].Qty"},
+	}
+
+	for _, c := range cases {
+		if got := v.Label(c.key); got != c.want {
+			t.Errorf("Label(%q) = %q", c.key, got)
+		}
+	}
+
+	if r := v.Required("").Key("dob").Message("{label} ({field}) is required"); r.Error.Message != "Date of birth (dob) is required" {
+		t.Errorf("message %q", r.Error.Message)
+	}
+
+	// The translation of the label wins in the locale of the Validation,
+	// and a Child shares the labels.
+	v.SetLocale("xl")
+	if got := v.Label("dob"); got != "Data de nascimento" {
+		t.Errorf("translated label %q", got)
+	}
+
+	if r := v.Child().SetLocale("").Error("{label} is wrong").Key("Items[1].Name"); r.Error.Message != "Item name is wrong" {
+		t.Errorf("message of a Child %q", r.Error.Message)
+	}
+}
+
+func TestLabelTags(t *testing.T) {
+	type person struct {
+		Name string `validate:"required" label:"Full name"`
+		DOB  string `validate:"required" label:"Date of birth"`
+	}
+
+	SetMessages("xr", map[string]string{"Required": "{label} is required"})
+	v := (&Validation{}).SetLocale("xr").SetLabel("DOB", "Birthday").ValidateStruct(person{})
+
+	errs := v.ErrorMap()
+	if errs["Name"].Message != "Full name is required" || errs["DOB"].Message != "Birthday is required" {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
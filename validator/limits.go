@@ -0,0 +1,225 @@
+package validator
+
+import (
+	"bytes"
+	"errors"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// The limits LimitBody enforces.  A zero field takes its default.
+type Limits struct {
+	// The largest JSON or urlencoded body, MaxBodyBytes by default, and
+	// the largest multipart one, 32 MB by default.
+	MaxBodyBytes      int64
+	MaxMultipartBytes int64
+
+	// The most parts of a multipart body, 100 by default.
+	MaxParts int
+
+	// The deepest nesting of objects and arrays of a JSON body, 32 by
+	// default.
+	MaxDepth int
+
+	// The most fields of a urlencoded body and query string together,
+	// 1000 by default.
+	MaxFields int
+}
+
+var errTooManyParts = errors.New("validator: too many multipart parts")
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxBodyBytes == 0 {
+		l.MaxBodyBytes = MaxBodyBytes
+	}
+
+	if l.MaxMultipartBytes == 0 {
+		l.MaxMultipartBytes = 32 << 20
+	}
+
+	if l.MaxParts == 0 {
+		l.MaxParts = 100
+	}
+
+	if l.MaxDepth == 0 {
+		l.MaxDepth = 32
+	}
+
+	if l.MaxFields == 0 {
+		l.MaxFields = 1000
+	}
+
+	return l
+}
+
+// Return a middleware that rejects request bodies beyond limits before a
+// handler binds them, answering 413 Request Entity Too Large for a body
+// too large and 400 Bad Request for one too deep or with too many fields,
+// with the JSON errors of Middleware.
+//
+// JSON and urlencoded bodies are read and checked up front.  Multipart
+// bodies, which may be large uploads, are streamed: one whose
+// Content-Length is too large is rejected up front, and otherwise reading
+// fails, in the handler, once it grows too large or has too many parts.
+func LimitBody(limits Limits) middleware.Middleware {
+	l := limits.withDefaults()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v := (&Validation{}).SetLocale(i18n.Locale(r)).SetContext(r.Context())
+			if status := l.check(w, r, v); status != 0 {
+				log.FromRequest(r).Debug("<validator.LimitBody> ", r.Method, " ", r.URL.Path, ": ", v.Errors[0].Code)
+				writeErrors(w, r, status, v)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Check r against the limits, replacing its body by one that can be read
+// again, and return the status to answer with, or 0 if it is within them.
+func (l Limits) check(w http.ResponseWriter, r *http.Request, v *Validation) int {
+	tooLarge := func() int {
+		v.Error("Request body too large").Code("validation.body_too_large")
+		return http.StatusRequestEntityTooLarge
+	}
+
+	fields := 0
+	if r.URL.RawQuery != "" {
+		fields = strings.Count(r.URL.RawQuery, "&") + 1
+	}
+
+	if fields > l.MaxFields {
+		v.Error("Too many fields").Code("validation.too_many_fields")
+		return http.StatusBadRequest
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return 0
+	}
+
+	ct, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if ct == "multipart/form-data" {
+		if r.ContentLength > l.MaxMultipartBytes {
+			return tooLarge()
+		}
+
+		delim := []byte("--" + params["boundary"])
+		r.Body = &partCounter{
+			ReadCloser: http.MaxBytesReader(w, r.Body, l.MaxMultipartBytes),
+			delim:      delim,
+			max:        l.MaxParts + 1,
+		}
+
+		return 0
+	}
+
+	if r.ContentLength > l.MaxBodyBytes {
+		return tooLarge()
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, l.MaxBodyBytes+1))
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		v.Error("Invalid request body").Code("validation.body")
+		return http.StatusBadRequest
+	}
+
+	if int64(len(body)) > l.MaxBodyBytes {
+		return tooLarge()
+	}
+
+	switch {
+	case ct == "application/x-www-form-urlencoded":
+		if len(body) > 0 {
+			fields += bytes.Count(body, []byte("&")) + 1
+		}
+
+		if fields > l.MaxFields {
+			v.Error("Too many fields").Code("validation.too_many_fields")
+			return http.StatusBadRequest
+		}
+	case ct == "application/json" || strings.HasSuffix(ct, "+json"):
+		if jsonDepth(body) > l.MaxDepth {
+			v.Error("JSON body nested too deeply").Code("validation.too_deep")
+			return http.StatusBadRequest
+		}
+	}
+
+	return 0
+}
+
+// Return the deepest nesting of objects and arrays in a JSON document,
+// which need not be valid.
+func jsonDepth(b []byte) int {
+	depth, deepest := 0, 0
+	inString, escaped := false, false
+	for _, c := range b {
+		switch {
+		case inString:
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			if depth > deepest {
+				deepest = depth
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+
+	return deepest
+}
+
+// A multipart body failing once it has more than max delimiters, that is
+// more than max-1 parts.
+type partCounter struct {
+	io.ReadCloser
+	delim []byte
+	max   int
+	count int
+	tail  []byte
+}
+
+func (p *partCounter) Read(b []byte) (int, error) {
+	if p.count > p.max {
+		return 0, errTooManyParts
+	}
+
+	n, err := p.ReadCloser.Read(b)
+
+	// Count the delimiters of what was read, including those straddling
+	// the previous read.
+	window := append(p.tail, b[:n]...)
+	p.count += bytes.Count(window, p.delim) - bytes.Count(p.tail, p.delim)
+	if keep := len(p.delim) - 1; len(window) > keep {
+		p.tail = append([]byte{}, window[len(window)-keep:]...)
+	} else {
+		p.tail = window
+	}
+
+	// Drop what was read, so that its parts are not parsed either.
+	if p.count > p.max {
+		return 0, errTooManyParts
+	}
+
+	return n, err
+}
@@ -0,0 +1,183 @@
+package validator
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Serve a request with body of type ct through LimitBody(limits) to a
+// handler reading the whole body, and return the recorder and whether the
+// handler was reached and read the body without error.
+func limited(limits Limits, target, ct string, body []byte) (*httptest.ResponseRecorder, bool) {
+	read := false
+	h := LimitBody(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err == nil {
+			read = true
+		}
+	}))
+
+	r := httptest.NewRequest("POST", target, bytes.NewReader(body))
+	r.Header.Set("Content-Type", ct)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w, read
+}
+
+func TestLimitBodySize(t *testing.T) {
+	limits := Limits{MaxBodyBytes: 10}
+	if w, read := limited(limits, "/", "application/json", []byte(`{"a":1}`)); w.Code != 200 || !read {
+		t.Errorf("small body answered %d, read %v", w.Code, read)
+	}
+
+	w, read := limited(limits, "/", "application/json", []byte(`{"a":"0123456789"}`))
+	if w.Code != http.StatusRequestEntityTooLarge || read {
+		t.Errorf("large body answered %d, read %v", w.Code, read)
+	}
+
+	if !strings.Contains(w.Body.String(), "validation.body_too_large") {
+		t.Errorf("body %s", w.Body)
+	}
+
+	// Without a Content-Length the body is only found too large once read.
+	h := LimitBody(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	r := httptest.NewRequest("POST", "/", strings.NewReader(strings.Repeat("x", 11)))
+	r.ContentLength = -1
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("chunked large body answered %d", w.Code)
+	}
+}
+
+func TestLimitBodyKeepsBody(t *testing.T) {
+	var got string
+	h := LimitBody(Limits{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		got = r.PostForm.Get("name")
+	}))
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader("name=Joe&age=3"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if got != "Joe" {
+		t.Errorf("handler read name %q", got)
+	}
+}
+
+func TestLimitBodyFields(t *testing.T) {
+	limits := Limits{MaxFields: 3}
+	ct := "application/x-www-form-urlencoded"
+	if w, _ := limited(limits, "/?a=1", ct, []byte("b=2&c=3")); w.Code != 200 {
+		t.Errorf("3 fields answered %d", w.Code)
+	}
+
+	w, read := limited(limits, "/?a=1&d=4", ct, []byte("b=2&c=3"))
+	if w.Code != http.StatusBadRequest || read || !strings.Contains(w.Body.String(), "validation.too_many_fields") {
+		t.Errorf("4 fields answered %d, read %v: %s", w.Code, read, w.Body)
+	}
+
+	if w, _ := limited(limits, "/?a&b&c&d", "application/json", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("4 query fields answered %d", w.Code)
+	}
+}
+
+func TestLimitBodyDepth(t *testing.T) {
+	limits := Limits{MaxDepth: 3}
+	if w, _ := limited(limits, "/", "application/json", []byte(`{"a":[{"b":"[[[[{{{{"}]}`)); w.Code != 200 {
+		t.Errorf("depth 3 answered %d", w.Code)
+	}
+
+	w, _ := limited(limits, "/", "application/vnd.api+json", []byte(`{"a":[{"b":["\"]"]}]}`))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "validation.too_deep") {
+		t.Errorf("depth 4 answered %d: %s", w.Code, w.Body)
+	}
+
+	if w, _ := limited(limits, "/", "text/plain", []byte(`[[[[[[`)); w.Code != 200 {
+		t.Errorf("text body checked for depth: %d", w.Code)
+	}
+}
+
+func TestJSONDepth(t *testing.T) {
+	cases := map[string]int{
+		``:                   0,
+		`"[{"`:               0,
+		`[]`:                 1,
+		`{"a":{"b":[1]}}`:    3,
+		`[[],[[]]]`:          3,
+		`["\\", [1]]`:        2,
+		`{"a\"[":[{"b":2}]}`: 3,
+	}
+
+	for in, want := range cases {
+		if got := jsonDepth([]byte(in)); got != want {
+			t.Errorf("jsonDepth(%s) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func multipartBody(parts int) ([]byte, string) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	for i := 0; i < parts; i++ {
+		fw, _ := mw.CreateFormField("field")
+		fw.Write([]byte(strings.Repeat("v", 5000)))
+	}
+	mw.Close()
+
+	return buf.Bytes(), mw.FormDataContentType()
+}
+
+func TestLimitBodyParts(t *testing.T) {
+	limits := Limits{MaxParts: 3}
+	body, ct := multipartBody(3)
+	if w, read := limited(limits, "/", ct, body); w.Code != 200 || !read {
+		t.Errorf("3 parts answered %d, read %v", w.Code, read)
+	}
+
+	body, ct = multipartBody(4)
+	if _, read := limited(limits, "/", ct, body); read {
+		t.Error("4 parts read without error")
+	}
+
+	var err error
+	h := LimitBody(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = r.ParseMultipartForm(1 << 20)
+	}))
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	r.Header.Set("Content-Type", ct)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if err == nil {
+		t.Error("4 parts parsed without error")
+	}
+}
+
+func TestLimitBodyMultipartSize(t *testing.T) {
+	body, ct := multipartBody(2)
+	limits := Limits{MaxBodyBytes: 10, MaxMultipartBytes: int64(len(body))}
+	if w, read := limited(limits, "/", ct, body); w.Code != 200 || !read {
+		t.Errorf("multipart body within its limit answered %d, read %v", w.Code, read)
+	}
+
+	limits.MaxMultipartBytes--
+	if w, read := limited(limits, "/", ct, body); w.Code != http.StatusRequestEntityTooLarge || read {
+		t.Errorf("large multipart body answered %d, read %v", w.Code, read)
+	}
+
+	h := LimitBody(limits)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ioutil.ReadAll(r.Body); err == nil {
+			t.Error("large chunked multipart body read without error")
+		}
+	}))
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	r.Header.Set("Content-Type", ct)
+	r.ContentLength = -1
+	h.ServeHTTP(httptest.NewRecorder(), r)
+}
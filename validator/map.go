@@ -0,0 +1,63 @@
+package validator
+
+import (
+	"sort"
+	"strings"
+)
+
+// Validate a dynamic payload, such as a decoded JSON object, by rules
+// mapping each key to its validators, applied in order and stopping at the
+// first failure, which is recorded under the key.  Keys are checked in
+// sorted order, and a dotted key, e.g. "address.city", looks into nested
+// maps.
+//
+// A key missing from data, or nil, fails its Required rule if it has one
+// and skips the others, so that optional fields may be left out.  Note
+// that encoding/json decodes numbers as float64, to be checked with
+// MinFloat and MaxFloat rather than Min and Max.
+func (v *Validation) ValidateMap(data map[string]interface{}, rules map[string][]Validator) *Validation {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := lookupPath(data, key)
+		if ok && value != nil {
+			v.CheckKey(key, value, rules[key]...)
+			continue
+		}
+
+		for _, chk := range rules[key] {
+			if _, required := unwrap(chk).(Required); required {
+				v.applyKey(key, chk, nil)
+				break
+			}
+		}
+	}
+
+	return v
+}
+
+// Return the value of a dotted key in nested maps, and whether it is set.
+func lookupPath(data map[string]interface{}, key string) (interface{}, bool) {
+	if value, ok := data[key]; ok {
+		return value, true
+	}
+
+	parts := strings.SplitN(key, ".", 2)
+	if len(parts) < 2 {
+		return nil, false
+	}
+
+	switch nested := data[parts[0]].(type) {
+	case map[string]interface{}:
+		return lookupPath(nested, parts[1])
+	case map[string]string:
+		value, ok := nested[parts[1]]
+		return value, ok
+	}
+
+	return nil, false
+}
@@ -0,0 +1,60 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateMap(t *testing.T) {
+	var data map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"name": "ab",
+		"age": 17,
+		"email": null,
+		"address": {"city": "", "zip": "1234"},
+		"labels": {"color": "red"},
+		"dotted.key": "x"
+	}`), &data)
+
+	v := (&Validation{}).ValidateMap(data, map[string][]Validator{
+		"name":          {Required{}, MinSize{3}, MaxSize{1}},
+		"age":           {MinFloat{18}},
+		"email":         {NewEmail()},
+		"phone":         {Required{}, MinSize{7}},
+		"nick":          {MinSize{2}},
+		"address.city":  {Required{}},
+		"address.zip":   {Length{5}},
+		"address.state": {WithCache(NewMemoryCache(), 0)(Required{})},
+		"labels.color":  {In{Values: []string{"blue"}}},
+		"dotted.key":    {MaxSize{0}},
+	})
+
+	want := []string{"address.city", "address.state", "address.zip", "age", "dotted.key", "labels.color", "name", "phone"}
+	if len(v.Errors) != len(want) {
+		t.Fatalf("errors %v", v.Errors)
+	}
+
+	for i, key := range want {
+		if v.Errors[i].Key != key {
+			t.Errorf("error %d under %q, want %q", i, v.Errors[i].Key, key)
+		}
+	}
+
+	// Only the first failing rule of a key is recorded.
+	if errs := v.ErrorMap(); errs["name"].Message != "Minimum size is 3" || errs["phone"].Message != "Required" {
+		t.Errorf("errors %v", errs)
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	data := map[string]interface{}{"headers": map[string]string{"host": "example.com"}, "n": 1}
+	if value, ok := lookupPath(data, "headers.host"); !ok || value != "example.com" {
+		t.Errorf("headers.host = %v, %v", value, ok)
+	}
+
+	for _, key := range []string{"headers.accept", "n.x", "missing"} {
+		if value, ok := lookupPath(data, key); ok {
+			t.Errorf("%s = %v", key, value)
+		}
+	}
+}
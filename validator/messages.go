@@ -0,0 +1,161 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"golanger.com/framework/i18n"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var catalog = struct {
+	sync.RWMutex
+	messages map[string]map[string]string
+}{
+	messages: map[string]map[string]string{},
+}
+
+// Load the translated messages of every locale file in dir.  Each file is
+// a JSON object named after its locale (e.g. zh-cn.json) mapping validator
+// type names to message templates, where {Field} is replaced by that
+// exported field of the validator:
+//
+//	{"Required": "必填", "MinSize": "最小长度为{Min}"}
+func LoadMessages(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		messages := map[string]string{}
+		if err := json.Unmarshal(b, &messages); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+
+		locale := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		SetMessages(locale, messages)
+	}
+
+	return nil
+}
+
+// Add or replace the message templates of a locale.
+func SetMessages(locale string, messages map[string]string) {
+	locale = strings.ToLower(locale)
+	catalog.Lock()
+	if catalog.messages[locale] == nil {
+		catalog.messages[locale] = map[string]string{}
+	}
+
+	for name, tmpl := range messages {
+		catalog.messages[locale][name] = tmpl
+	}
+	catalog.Unlock()
+}
+
+// Set the locale used to translate the default messages of failed checks.
+func (v *Validation) SetLocale(locale string) *Validation {
+	v.locale = strings.ToLower(locale)
+
+	return v
+}
+
+// Return the message template for a failed check: the message of i18n
+// keyed by the error code of the validator (e.g. "validation.required")
+// in the locale, else the template of the catalog for the validator in
+// the locale, else the message of i18n in its default locale, else the
+// default message of the validator.
+func (v *Validation) messageTemplate(chk Validator) string {
+	code := errorCode(chk)
+	if v.locale != "" {
+		if tmpl, ok := i18n.Default.Lookup(v.locale, code); ok {
+			return tmpl
+		}
+
+		catalog.RLock()
+		tmpl, ok := catalog.messages[v.locale][validatorName(chk)]
+		catalog.RUnlock()
+		if ok {
+			return tmpl
+		}
+	}
+
+	if tmpl, ok := i18n.Default.Lookup(i18n.Default.DefaultLocale, code); ok {
+		return tmpl
+	}
+
+	return chk.DefaultMessage()
+}
+
+// Return the message for a failed check, translated into the locale if the
+// catalog has a template for the validator.
+func (v *Validation) message(chk Validator) string {
+	return formatMessage(v.messageTemplate(chk), chk)
+}
+
+func validatorName(chk Validator) string {
+	t := reflect.TypeOf(chk)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// Replace each {Field} in tmpl with the exported field of the validator,
+// including the fields of embedded structs.  The field may also be named
+// in lower case, {min} for Min, except for {field}, {label} and {value},
+// which ValidationResult reserves for the key, its label and the checked
+// value.
+func formatMessage(tmpl string, chk Validator) string {
+	if !strings.Contains(tmpl, "{") {
+		return tmpl
+	}
+
+	params := []string{}
+	var collect func(rv reflect.Value)
+	collect = func(rv reflect.Value) {
+		for rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return
+			}
+
+			rv = rv.Elem()
+		}
+
+		if rv.Kind() != reflect.Struct {
+			return
+		}
+
+		for i := 0; i < rv.NumField(); i++ {
+			sf := rv.Type().Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			if sf.Anonymous && rv.Field(i).Kind() == reflect.Struct {
+				collect(rv.Field(i))
+				continue
+			}
+
+			value := fmt.Sprint(rv.Field(i).Interface())
+			params = append(params, "{"+sf.Name+"}", value)
+			if lower := strings.ToLower(sf.Name); lower != sf.Name && lower != "field" && lower != "label" && lower != "value" {
+				params = append(params, "{"+lower+"}", value)
+			}
+		}
+	}
+
+	collect(reflect.ValueOf(chk))
+
+	return strings.NewReplacer(params...).Replace(tmpl)
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"golanger.com/framework/i18n"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+type shout struct{}
+
+func (shout) IsSatisfied(obj interface{}) bool { return false }
+func (shout) DefaultMessage() string           { return "Too quiet" }
+func (shout) ErrorCode() string                { return "validation.test_shout" }
+
+type whisper struct{ shout }
+
+func (whisper) ErrorCode() string { return "validation.test_whisper" }
+
+func TestI18nMessages(t *testing.T) {
+	i18n.Default.SetMessages("xq", map[string]string{"validation.required": "obligatorio"})
+	i18n.Default.SetMessages(i18n.Default.DefaultLocale, map[string]string{"validation.test_shout": "Louder"})
+	SetMessages("xq", map[string]string{"Required": "from the catalog", "whisper": "susurro"})
+
+	cases := []struct {
+		locale string
+		check  Validator
+		want   string
+	}{
+		{"xq", Required{}, "obligatorio"},
+		{"XQ", whisper{}, "susurro"},
+		{"xq", shout{}, "Louder"},
+		{"", shout{}, "Louder"},
+		{"", whisper{}, "Too quiet"},
+	}
+
+	for _, c := range cases {
+		v := (&Validation{}).SetLocale(c.locale)
+		if got := v.messageTemplate(c.check); got != c.want {
+			t.Errorf("%s message of %T = %q, want %q", c.locale, c.check, got, c.want)
+		}
+	}
+}
+
+func TestLoadMessages(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "xz-CN.json"), []byte(`{"Required": "必填", "MinSize": "最小长度为{Min}"}`), 0644)
+	if err := LoadMessages(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	v := (&Validation{}).SetLocale("XZ-cn")
+	v.Required("").Key("name")
+	v.MinSize("ab", 3).Key("code")
+	v.Length("ab", 3).Key("pin")
+
+	errs := v.ErrorMap()
+	if errs["name"].Message != "必填" || errs["code"].Message != "最小长度为3" || errs["pin"].Message != "Required length is 3" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if (&Validation{}).Required("").Key("name").Error.Message != "Required" {
+		t.Error("translated without a locale")
+	}
+
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"Required": 1}`), 0644)
+	if err := LoadMessages(dir); err == nil {
+		t.Error("no error loading bad.json")
+	}
+}
+
+func TestPlaceholders(t *testing.T) {
+	v := &Validation{}
+	cases := []struct {
+		result *ValidationResult
+		want   string
+	}{
+		{v.MinSize("ab", 3).Key("Name").Message("{field} must be at least {min} characters"), "Name must be at least 3 characters"},
+		{v.Range(12, 1, 10).Message("{value} is not within {Min} and {max}").Key("Count"), "12 is not within 1 and 10"},
+		{v.Required("").Message("%s: {field}", "Oops").Key("Title"), "Oops: Title"},
+		{v.Required(nil).Message("[{value}] {unknown}").Key("x"), "[] {unknown}"},
+		{v.Match("A", regexp.MustCompile(`^a$`)).Message("{field}").Key("first").Key("second"), "second"},
+	}
+
+	for _, c := range cases {
+		if c.result.Error.Message != c.want {
+			t.Errorf("message %q, want %q", c.result.Error.Message, c.want)
+		}
+	}
+
+	// A translation may use the placeholders too.
+	SetMessages("xp", map[string]string{"MaxSize": "no more than {max} in {field}"})
+	if r := (&Validation{}).SetLocale("xp").MaxSize("abcd", 2).Key("Code"); r.Error.Message != "no more than 2 in Code" {
+		t.Errorf("translated message %q", r.Error.Message)
+	}
+}
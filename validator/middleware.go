@@ -0,0 +1,89 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"golanger.com/framework/i18n"
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
+	"golanger.com/framework/validator/sanitize"
+	"net/http"
+	"reflect"
+)
+
+// The largest JSON body Middleware reads.
+var MaxBodyBytes int64 = 1 << 20
+
+type bodyKey struct{}
+
+// Return the body that Middleware decoded and validated, a pointer to a
+// value of the schema type, or nil.
+func Body(r *http.Request) interface{} {
+	return r.Context().Value(bodyKey{})
+}
+
+// Return a middleware that decodes the JSON request body into a new value
+// of the type of schema (a struct or pointer to struct), normalizes it by
+// its `sanitize` tags and checks it by its `validate` tags, before the
+// handler runs.  The handler gets the decoded value through Body.
+//
+// A body that is not JSON is answered 400 Bad Request, and one that fails
+// validation 422 Unprocessable Entity, either with a JSON object of the
+// form {"errors": {"Email": {"field", "message", "code"}}} holding the
+// first error of each key.
+func Middleware(schema interface{}) middleware.Middleware {
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("validator: Middleware needs a struct schema")
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			dst := reflect.New(t).Interface()
+			v := (&Validation{}).SetLocale(i18n.Locale(r)).SetContext(r.Context())
+			status := http.StatusUnprocessableEntity
+
+			if r.Body == nil || r.Body == http.NoBody {
+				v.Error("Missing JSON body")
+				status = http.StatusBadRequest
+			} else {
+				err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodyBytes)).Decode(dst)
+				switch e := err.(type) {
+				case nil:
+					sanitize.Struct(dst)
+					v.ValidateStruct(dst)
+				case *json.UnmarshalTypeError:
+					v.Error("Must be of type %s", e.Type).Key(e.Field).Code("validation.type")
+				default:
+					v.Error("Invalid JSON body")
+					status = http.StatusBadRequest
+				}
+			}
+
+			if v.HasErrors() {
+				log.FromRequest(r).Debug("<validator.Middleware> ", r.Method, " ", r.URL.Path, ": ", len(v.Errors), " validation errors")
+				writeErrors(w, r, status, v)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), bodyKey{}, dst)))
+		})
+	}
+}
+
+func writeErrors(w http.ResponseWriter, r *http.Request, status int, v *Validation) {
+	b, err := json.Marshal(map[string]interface{}{"errors": v.ErrorMap()})
+	if err != nil {
+		log.FromRequest(r).Error("<validator.Middleware> ", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(b)
+}
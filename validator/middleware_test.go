@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type newUser struct {
+	Email string `json:"email" sanitize:"trim,lower" validate:"required,email"`
+	Age   int    `json:"age" validate:"min=13"`
+}
+
+// Serve the decoded body as JSON, or the errors of the middleware.
+func serveBody(t *testing.T, body string) (*httptest.ResponseRecorder, map[string]map[string]*ValidationError) {
+	h := Middleware(newUser{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Body(r))
+	}))
+
+	var r *http.Request
+	if body == "" {
+		r = httptest.NewRequest("POST", "/users", nil)
+	} else {
+		r = httptest.NewRequest("POST", "/users", strings.NewReader(body))
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var errs map[string]map[string]*ValidationError
+	if w.Code != http.StatusOK {
+		if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("Content-Type %s", ct)
+		}
+
+		if err := json.Unmarshal(w.Body.Bytes(), &errs); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return w, errs
+}
+
+func TestMiddleware(t *testing.T) {
+	w, _ := serveBody(t, `{"email": " Ann@Example.COM ", "age": 30}`)
+	if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != `{"email":"ann@example.com","age":30}` {
+		t.Errorf("%d %s", w.Code, w.Body)
+	}
+
+	w, errs := serveBody(t, `{"email": "nope", "age": 3}`)
+	if w.Code != http.StatusUnprocessableEntity || errs["errors"]["Email"].Code != "validation.email" || errs["errors"]["Age"].Message != "Minimum is 13" {
+		t.Errorf("%d %s", w.Code, w.Body)
+	}
+
+	w, errs = serveBody(t, `{"email": "a@example.com", "age": "old"}`)
+	if w.Code != http.StatusUnprocessableEntity || errs["errors"]["age"].Code != "validation.type" || errs["errors"]["age"].Message != "Must be of type int" {
+		t.Errorf("%d %s", w.Code, w.Body)
+	}
+
+	for _, body := range []string{"", `{"email": `} {
+		if w, errs := serveBody(t, body); w.Code != http.StatusBadRequest || errs["errors"][""] == nil {
+			t.Errorf("%q: %d %s", body, w.Code, w.Body)
+		}
+	}
+}
+
+func TestMiddlewareBodyLimit(t *testing.T) {
+	saved := MaxBodyBytes
+	MaxBodyBytes = 16
+	defer func() { MaxBodyBytes = saved }()
+
+	if w, errs := serveBody(t, `{"email": "a@example.com"}`); w.Code != http.StatusBadRequest || errs["errors"][""].Message != "Invalid JSON body" {
+		t.Errorf("%d %s", w.Code, w.Body)
+	}
+}
+
+func TestMiddlewareSchema(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("no panic for a string schema")
+		}
+	}()
+
+	Middleware("not a struct")
+}
@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"fmt"
+	"golanger.com/framework/decimal"
+	"strings"
+)
+
+// The currencies whose minor unit is not a hundredth, by ISO 4217 code.
+var minorUnits = map[string]int{
+	"BHD": 3, "BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3, "ISK": 0,
+	"JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3, "OMR": 3,
+	"PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0,
+	"XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// Return the number of decimal places of the amounts of a currency, 2 for
+// those not listed.
+func MinorUnits(currency string) int {
+	if n, ok := minorUnits[strings.ToUpper(currency)]; ok {
+		return n
+	}
+
+	return 2
+}
+
+// Requires an amount of money: a decimal.Decimal, or a string written as
+// Numeric, with at most MaxScale digits after the point, by default the
+// minor units of Currency (2 for most, 0 for JPY), and within Min and Max
+// inclusive, written in Go syntax, either of which may be empty for no
+// bound.  The checks are exact, so "0.001" is refused in EUR and "0.10"
+// is not below "0.1".
+type Money struct {
+	Currency string
+	MaxScale int
+	Min, Max string
+	Locale   string
+}
+
+func (m Money) scale() int {
+	if m.MaxScale > 0 {
+		return m.MaxScale
+	}
+
+	return MinorUnits(m.Currency)
+}
+
+func (m Money) IsSatisfied(obj interface{}) bool {
+	return m.IsSatisfiedLocale(obj, "")
+}
+
+func (m Money) IsSatisfiedLocale(obj interface{}, locale string) bool {
+	var d decimal.Decimal
+	switch o := obj.(type) {
+	case decimal.Decimal:
+		d = o
+	case *decimal.Decimal:
+		if o == nil {
+			return false
+		}
+
+		d = *o
+	case string:
+		num, ok := NormalizeNumber(o, numberLocale(m.Locale, locale))
+		if !ok {
+			return false
+		}
+
+		var err error
+		if d, err = decimal.Parse(num); err != nil {
+			return false
+		}
+	default:
+		return false
+	}
+
+	if _, exact := d.Units(m.scale()); !exact {
+		return false
+	}
+
+	if m.Min != "" {
+		min, err := decimal.Parse(m.Min)
+		if err != nil || d.Cmp(min) < 0 {
+			return false
+		}
+	}
+
+	if m.Max != "" {
+		max, err := decimal.Parse(m.Max)
+		if err != nil || d.Cmp(max) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (m Money) DefaultMessage() string {
+	msg := "Must be an amount"
+	if m.scale() == 0 {
+		msg += " without decimal places"
+	} else {
+		msg += fmt.Sprint(" with at most ", m.scale(), " decimal places")
+	}
+
+	switch {
+	case m.Min != "" && m.Max != "":
+		msg += fmt.Sprint(" from ", m.Min, " to ", m.Max)
+	case m.Min != "":
+		msg += fmt.Sprint(" of at least ", m.Min)
+	case m.Max != "":
+		msg += fmt.Sprint(" of at most ", m.Max)
+	}
+
+	if m.Currency != "" {
+		msg += " " + strings.ToUpper(m.Currency)
+	}
+
+	return msg
+}
+
+// Check an amount of money, see Money.
+func (v *Validation) Money(obj interface{}, money Money) *ValidationResult {
+	return v.apply(money, obj)
+}
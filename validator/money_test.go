@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"golanger.com/framework/decimal"
+	"testing"
+)
+
+func TestMoney(t *testing.T) {
+	eur := Money{Currency: "EUR", Min: "0.01", Max: "1000"}
+	for _, ok := range []interface{}{"0.01", "0.10", "1000.00", "999.9", decimal.MustParse("12.30"), ptr(decimal.MustParse("1"))} {
+		if !eur.IsSatisfied(ok) {
+			t.Errorf("%v refused in EUR", ok)
+		}
+	}
+
+	for _, bad := range []interface{}{"0.001", "0", "1000.01", "-5", "abc", "", 12.3, (*decimal.Decimal)(nil)} {
+		if eur.IsSatisfied(bad) {
+			t.Errorf("%#v accepted in EUR", bad)
+		}
+	}
+
+	if !(Money{Currency: "jpy"}).IsSatisfied("1500") || (Money{Currency: "JPY"}).IsSatisfied("15.5") {
+		t.Error("JPY amounts not checked without decimal places")
+	}
+
+	if !(Money{Currency: "KWD"}).IsSatisfied("1.125") || !(Money{MaxScale: 4}).IsSatisfied("0.0001") {
+		t.Error("minor units not followed")
+	}
+}
+
+func ptr(d decimal.Decimal) *decimal.Decimal {
+	return &d
+}
+
+func TestMoneyLocale(t *testing.T) {
+	m := Money{Currency: "EUR"}
+	if !m.IsSatisfiedLocale("1.234,50", "de") || m.IsSatisfiedLocale("1.234,50", "en") {
+		t.Error("amount not read in the locale of the Validation")
+	}
+
+	if !(Money{Currency: "EUR", Locale: "de"}).IsSatisfiedLocale("0,5", "en") {
+		t.Error("own Locale not preferred")
+	}
+}
+
+func TestMoneyMessage(t *testing.T) {
+	cases := map[string]Money{
+		"Must be an amount with at most 2 decimal places from 1 to 5 EUR": {Currency: "eur", Min: "1", Max: "5"},
+		"Must be an amount without decimal places of at least 100 JPY":    {Currency: "JPY", Min: "100"},
+		"Must be an amount with at most 3 decimal places":                 {MaxScale: 3},
+	}
+
+	for want, m := range cases {
+		if got := m.DefaultMessage(); got != want {
+			t.Errorf("message %q, want %q", got, want)
+		}
+	}
+}
+
+func TestMoneyTag(t *testing.T) {
+	v := &Validation{}
+	v.ValidateStruct(struct {
+		Price string `validate:"money=EUR;0.01;100"`
+	}{"100.001"})
+	if v.ErrorMap()["Price"] == nil {
+		t.Error("money tag not checked")
+	}
+}
@@ -0,0 +1,209 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func (v *Validation) MinInt64(n int64, min int64) *ValidationResult {
+	return v.apply(MinInt64{min}, n)
+}
+
+func (v *Validation) MaxInt64(n int64, max int64) *ValidationResult {
+	return v.apply(MaxInt64{max}, n)
+}
+
+func (v *Validation) RangeInt64(n, min, max int64) *ValidationResult {
+	return v.apply(RangeInt64{MinInt64{min}, MaxInt64{max}}, n)
+}
+
+func (v *Validation) MinUint(n uint64, min uint64) *ValidationResult {
+	return v.apply(MinUint{min}, n)
+}
+
+func (v *Validation) MaxUint(n uint64, max uint64) *ValidationResult {
+	return v.apply(MaxUint{max}, n)
+}
+
+func (v *Validation) RangeUint(n, min, max uint64) *ValidationResult {
+	return v.apply(RangeUint{MinUint{min}, MaxUint{max}}, n)
+}
+
+func (v *Validation) MinFloat(n float64, min float64) *ValidationResult {
+	return v.apply(MinFloat{min}, n)
+}
+
+func (v *Validation) MaxFloat(n float64, max float64) *ValidationResult {
+	return v.apply(MaxFloat{max}, n)
+}
+
+func (v *Validation) RangeFloat(n, min, max float64) *ValidationResult {
+	return v.apply(RangeFloat{MinFloat{min}, MaxFloat{max}}, n)
+}
+
+// Return obj as an int64 if it is of any signed integer kind.
+func toInt64(obj interface{}) (int64, bool) {
+	rv := reflect.ValueOf(obj)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	}
+
+	return 0, false
+}
+
+// Return obj as a uint64 if it is of any unsigned integer kind.
+func toUint64(obj interface{}) (uint64, bool) {
+	rv := reflect.ValueOf(obj)
+	switch rv.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return rv.Uint(), true
+	}
+
+	return 0, false
+}
+
+// Return obj as a float64 if it is of any integer or float kind.
+func toFloat64(obj interface{}) (float64, bool) {
+	if n, ok := toInt64(obj); ok {
+		return float64(n), true
+	}
+
+	if n, ok := toUint64(obj); ok {
+		return float64(n), true
+	}
+
+	rv := reflect.ValueOf(obj)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+
+	return 0, false
+}
+
+// Requires any signed integer to be at least Min.
+type MinInt64 struct {
+	Min int64
+}
+
+func (m MinInt64) IsSatisfied(obj interface{}) bool {
+	n, ok := toInt64(obj)
+	return ok && n >= m.Min
+}
+
+func (m MinInt64) DefaultMessage() string {
+	return fmt.Sprint("Minimum is ", m.Min)
+}
+
+// Requires any signed integer to be at most Max.
+type MaxInt64 struct {
+	Max int64
+}
+
+func (m MaxInt64) IsSatisfied(obj interface{}) bool {
+	n, ok := toInt64(obj)
+	return ok && n <= m.Max
+}
+
+func (m MaxInt64) DefaultMessage() string {
+	return fmt.Sprint("Maximum is ", m.Max)
+}
+
+// Requires any signed integer to be within Min, Max inclusive.
+type RangeInt64 struct {
+	MinInt64
+	MaxInt64
+}
+
+func (r RangeInt64) IsSatisfied(obj interface{}) bool {
+	return r.MinInt64.IsSatisfied(obj) && r.MaxInt64.IsSatisfied(obj)
+}
+
+func (r RangeInt64) DefaultMessage() string {
+	return fmt.Sprint("Range is ", r.MinInt64.Min, " to ", r.MaxInt64.Max)
+}
+
+// Requires any unsigned integer to be at least Min.
+type MinUint struct {
+	Min uint64
+}
+
+func (m MinUint) IsSatisfied(obj interface{}) bool {
+	n, ok := toUint64(obj)
+	return ok && n >= m.Min
+}
+
+func (m MinUint) DefaultMessage() string {
+	return fmt.Sprint("Minimum is ", m.Min)
+}
+
+// Requires any unsigned integer to be at most Max.
+type MaxUint struct {
+	Max uint64
+}
+
+func (m MaxUint) IsSatisfied(obj interface{}) bool {
+	n, ok := toUint64(obj)
+	return ok && n <= m.Max
+}
+
+func (m MaxUint) DefaultMessage() string {
+	return fmt.Sprint("Maximum is ", m.Max)
+}
+
+// Requires any unsigned integer to be within Min, Max inclusive.
+type RangeUint struct {
+	MinUint
+	MaxUint
+}
+
+func (r RangeUint) IsSatisfied(obj interface{}) bool {
+	return r.MinUint.IsSatisfied(obj) && r.MaxUint.IsSatisfied(obj)
+}
+
+func (r RangeUint) DefaultMessage() string {
+	return fmt.Sprint("Range is ", r.MinUint.Min, " to ", r.MaxUint.Max)
+}
+
+// Requires any integer or float to be at least Min.
+type MinFloat struct {
+	Min float64
+}
+
+func (m MinFloat) IsSatisfied(obj interface{}) bool {
+	n, ok := toFloat64(obj)
+	return ok && n >= m.Min
+}
+
+func (m MinFloat) DefaultMessage() string {
+	return fmt.Sprint("Minimum is ", m.Min)
+}
+
+// Requires any integer or float to be at most Max.
+type MaxFloat struct {
+	Max float64
+}
+
+func (m MaxFloat) IsSatisfied(obj interface{}) bool {
+	n, ok := toFloat64(obj)
+	return ok && n <= m.Max
+}
+
+func (m MaxFloat) DefaultMessage() string {
+	return fmt.Sprint("Maximum is ", m.Max)
+}
+
+// Requires any integer or float to be within Min, Max inclusive.
+type RangeFloat struct {
+	MinFloat
+	MaxFloat
+}
+
+func (r RangeFloat) IsSatisfied(obj interface{}) bool {
+	return r.MinFloat.IsSatisfied(obj) && r.MaxFloat.IsSatisfied(obj)
+}
+
+func (r RangeFloat) DefaultMessage() string {
+	return fmt.Sprint("Range is ", r.MinFloat.Min, " to ", r.MaxFloat.Max)
+}
@@ -0,0 +1,75 @@
+package validator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumericBounds(t *testing.T) {
+	cases := []struct {
+		check Validator
+		obj   interface{}
+		want  bool
+	}{
+		{MinInt64{-5}, int8(-5), true},
+		{MinInt64{math.MaxInt32 + 1}, int64(math.MaxInt32), false},
+		{MinInt64{0}, uint(1), false},
+		{MaxInt64{10}, int32(11), false},
+		{RangeInt64{MinInt64{1}, MaxInt64{math.MaxInt64}}, int64(math.MaxInt64), true},
+		{MinUint{1}, uint8(1), true},
+		{MinUint{1}, 1, false},
+		{MaxUint{math.MaxUint64 - 1}, uint64(math.MaxUint64), false},
+		{RangeUint{MinUint{2}, MaxUint{4}}, uint16(5), false},
+		{MinFloat{0.5}, float32(0.5), true},
+		{MinFloat{0.5}, 1, true},
+		{MinFloat{0.5}, uint(0), false},
+		{MaxFloat{9.99}, 9.991, false},
+		{RangeFloat{MinFloat{-1}, MaxFloat{1}}, -0.25, true},
+		{RangeFloat{MinFloat{-1}, MaxFloat{1}}, "0", false},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.obj); got != c.want {
+			t.Errorf("%+v on %T(%v) = %v", c.check, c.obj, c.obj, got)
+		}
+	}
+
+	v := &Validation{}
+	v.MinInt64(3, 5).Key("a")
+	v.RangeUint(7, 1, 6).Key("b")
+	v.MaxFloat(2.5, 2.25).Key("c")
+	v.RangeFloat(0.5, 0, 1).Key("ok")
+
+	errs := v.ErrorMap()
+	if len(errs) != 3 || errs["a"].Message != "Minimum is 5" || errs["b"].Message != "Range is 1 to 6" || errs["c"].Message != "Maximum is 2.25" {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestRequiredNumbers(t *testing.T) {
+	for _, obj := range []interface{}{int64(0), uint8(0), 0.0, float32(0)} {
+		if (Required{}).IsSatisfied(obj) {
+			t.Errorf("zero %T is present", obj)
+		}
+	}
+
+	for _, obj := range []interface{}{int64(-1), uint8(1), 0.1} {
+		if !(Required{}).IsSatisfied(obj) {
+			t.Errorf("%T(%v) is missing", obj, obj)
+		}
+	}
+}
+
+func TestFloatTags(t *testing.T) {
+	v := &Validation{}
+	v.ValidateStruct(struct {
+		Price  float64 `validate:"min=0.01,max=99.99"`
+		Weight float32 `validate:"max=2.5"`
+		Count  int64   `validate:"min=1"`
+	}{0.001, 2.5, 0})
+
+	errs := v.ErrorMap()
+	if len(errs) != 2 || errs["Price"].Message != "Minimum is 0.01" || errs["Count"] == nil {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
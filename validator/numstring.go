@@ -0,0 +1,254 @@
+package validator
+
+import (
+	"fmt"
+	"golanger.com/framework/i18n"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+// Return a number written in the format of locale, such as "1.234,5" in
+// German, in Go syntax ("1234.5"), and whether it is a number at all.  It
+// may have a sign, and group separators between every three digits of its
+// integer part.
+func NormalizeNumber(str, locale string) (string, bool) {
+	f := i18n.NumberFormatFor(locale)
+	str = strings.TrimSpace(str)
+	sign := ""
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		sign, str = str[:1], str[1:]
+		if sign == "+" {
+			sign = ""
+		}
+	}
+
+	intPart, frac := str, ""
+	if i := strings.Index(str, f.Decimal); i >= 0 {
+		intPart, frac = str[:i], str[i+len(f.Decimal):]
+		if frac == "" || !allDigits(frac) {
+			return "", false
+		}
+	}
+
+	digits, ok := ungroup(intPart, f.Group)
+	if !ok || digits == "" && frac == "" {
+		return "", false
+	}
+
+	if digits == "" {
+		digits = "0"
+	}
+
+	if frac != "" {
+		return sign + digits + "." + frac, true
+	}
+
+	return sign + digits, true
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Remove the group separators, any of the characters of group, from the
+// integer part of a number, which must then have groups of three digits
+// after the first one.  The same separator must be used throughout.
+func ungroup(s, group string) (string, bool) {
+	if allDigits(s) {
+		return s, true
+	}
+
+	var sep rune
+	digits := []string{}
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+		if i == -1 {
+			digits = append(digits, s)
+			break
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if !strings.ContainsRune(group, r) || sep != 0 && r != sep {
+			return "", false
+		}
+
+		sep = r
+		digits = append(digits, s[:i])
+		s = s[i+size:]
+		if s == "" {
+			return "", false
+		}
+	}
+
+	for i, d := range digits {
+		if i == 0 && (len(d) < 1 || len(d) > 3) || i > 0 && len(d) != 3 {
+			return "", false
+		}
+	}
+
+	return strings.Join(digits, ""), true
+}
+
+// The locale of Locale, or that of the Validation.
+func numberLocale(own, locale string) string {
+	if own != "" {
+		return own
+	}
+
+	return locale
+}
+
+// Requires a string to be a number, an integer or a decimal, in the format
+// of Locale or else the locale of the Validation, English by default; see
+// NormalizeNumber.  The numeric validators check form input before it is
+// bound, which is why they take strings.
+type Numeric struct {
+	Locale string
+}
+
+func (n Numeric) IsSatisfied(obj interface{}) bool {
+	return n.IsSatisfiedLocale(obj, "")
+}
+
+func (n Numeric) IsSatisfiedLocale(obj interface{}, locale string) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	_, ok = NormalizeNumber(str, numberLocale(n.Locale, locale))
+
+	return ok
+}
+
+func (n Numeric) DefaultMessage() string {
+	return "Must be a number"
+}
+
+// Requires a string to be an integer, with an optional sign and group
+// separators, as Numeric.
+type Integer struct {
+	Locale string
+}
+
+func (n Integer) IsSatisfied(obj interface{}) bool {
+	return n.IsSatisfiedLocale(obj, "")
+}
+
+func (n Integer) IsSatisfiedLocale(obj interface{}, locale string) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	num, ok := NormalizeNumber(str, numberLocale(n.Locale, locale))
+
+	return ok && !strings.Contains(num, ".")
+}
+
+func (n Integer) DefaultMessage() string {
+	return "Must be a whole number"
+}
+
+// Requires a string to be a number with at most MaxFractionDigits digits
+// after the decimal separator, any number of them if zero, as Numeric.
+type Decimal struct {
+	MaxFractionDigits int
+	Locale            string
+}
+
+func (d Decimal) IsSatisfied(obj interface{}) bool {
+	return d.IsSatisfiedLocale(obj, "")
+}
+
+func (d Decimal) IsSatisfiedLocale(obj interface{}, locale string) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	num, ok := NormalizeNumber(str, numberLocale(d.Locale, locale))
+	if !ok {
+		return false
+	}
+
+	if d.MaxFractionDigits == 0 {
+		return true
+	}
+
+	i := strings.Index(num, ".")
+
+	return i == -1 || len(num)-i-1 <= d.MaxFractionDigits
+}
+
+func (d Decimal) DefaultMessage() string {
+	if d.MaxFractionDigits == 0 {
+		return "Must be a decimal number"
+	}
+
+	return fmt.Sprint("Must be a number with at most ", d.MaxFractionDigits, " decimal places")
+}
+
+// Requires a string to be a number, as Numeric, within Min and Max
+// inclusive, which are written in Go syntax, such as "0" and "99.95", and
+// either of which may be empty for no bound.  The comparison is exact, so
+// that "0.1" is not above "0.1" however many digits either has.
+type InRangeString struct {
+	Min, Max string
+	Locale   string
+}
+
+func ratOf(s string) (*big.Rat, bool) {
+	return new(big.Rat).SetString(s)
+}
+
+func (r InRangeString) IsSatisfied(obj interface{}) bool {
+	return r.IsSatisfiedLocale(obj, "")
+}
+
+func (r InRangeString) IsSatisfiedLocale(obj interface{}, locale string) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	num, ok := NormalizeNumber(str, numberLocale(r.Locale, locale))
+	if !ok {
+		return false
+	}
+
+	n, _ := ratOf(num)
+	if r.Min != "" {
+		min, ok := ratOf(r.Min)
+		if !ok || n.Cmp(min) < 0 {
+			return false
+		}
+	}
+
+	if r.Max != "" {
+		max, ok := ratOf(r.Max)
+		if !ok || n.Cmp(max) > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r InRangeString) DefaultMessage() string {
+	switch {
+	case r.Min == "":
+		return fmt.Sprint("Maximum is ", r.Max)
+	case r.Max == "":
+		return fmt.Sprint("Minimum is ", r.Min)
+	}
+
+	return fmt.Sprint("Range is ", r.Min, " to ", r.Max)
+}
@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"testing"
+)
+
+func TestNormalizeNumber(t *testing.T) {
+	cases := []struct {
+		str, locale, want string
+	}{
+		{"1,234.5", "en", "1234.5"},
+		{" -12 ", "en", "-12"},
+		{"+.5", "en", "0.5"},
+		{"1.234,5", "de", "1234.5"},
+		{"1.234.567", "pt-BR", "1234567"},
+		{"1 234,5", "fr", "1234.5"},
+		{"1 234", "fr", "1234"},
+		{"1'234.5", "de-CH", "1234.5"},
+		{"1,234.5", "de", ""},
+		{"12,34", "en", ""},
+		{"1,234 567", "en", ""},
+		{"1.234 567", "ru", ""},
+		{"1,", "en", ""},
+		{"5.", "en", ""},
+		{"1.5e3", "en", ""},
+		{"-", "en", ""},
+		{"", "en", ""},
+	}
+
+	for _, c := range cases {
+		got, ok := NormalizeNumber(c.str, c.locale)
+		if got != c.want || ok != (c.want != "") {
+			t.Errorf("NormalizeNumber(%q, %s) = %q, %v", c.str, c.locale, got, ok)
+		}
+	}
+}
+
+func TestNumericValidators(t *testing.T) {
+	cases := []struct {
+		check Validator
+		obj   interface{}
+		want  bool
+	}{
+		{Numeric{}, "1,000.25", true},
+		{Numeric{}, 12, false},
+		{Numeric{Locale: "de"}, "1.000,25", true},
+		{Integer{}, "-1,000", true},
+		{Integer{}, "1.0", false},
+		{Integer{Locale: "de"}, "1.000", true},
+		{Decimal{MaxFractionDigits: 2}, "9.95", true},
+		{Decimal{MaxFractionDigits: 2}, "9.955", false},
+		{Decimal{}, "9.955", true},
+		{Decimal{MaxFractionDigits: 2, Locale: "fr"}, "9,95", true},
+		{InRangeString{Min: "0", Max: "99.95"}, "99.95", true},
+		{InRangeString{Min: "0", Max: "99.95"}, "99.950000000000000001", false},
+		{InRangeString{Min: "0.1"}, "0.10", true},
+		{InRangeString{Min: "0"}, "-0.0001", false},
+		{InRangeString{Max: "10"}, "-1,000", true},
+		{InRangeString{Max: "ten"}, "1", false},
+		{InRangeString{Max: "10", Locale: "de"}, "9,5", true},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.obj); got != c.want {
+			t.Errorf("%#v on %#v = %v, want %v", c.check, c.obj, got, c.want)
+		}
+	}
+}
+
+func TestNumericLocale(t *testing.T) {
+	v := (&Validation{}).SetLocale("de")
+	v.Check("1.234,5", Numeric{}).Key("price")
+	v.Check("1,234.5", Numeric{}).Key("english")
+	v.Check("1,234.5", Numeric{Locale: "en"}).Key("own")
+	v.Check("1.234", Integer{}, InRangeString{Min: "1", Max: "2000"}).Key("count")
+
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["english"] == nil {
+		t.Errorf("errors %v", errs)
+	}
+}
+
+func TestNumericMessages(t *testing.T) {
+	cases := []struct {
+		check Validator
+		want  string
+	}{
+		{Numeric{}, "Must be a number"},
+		{Integer{}, "Must be a whole number"},
+		{Decimal{}, "Must be a decimal number"},
+		{Decimal{MaxFractionDigits: 2}, "Must be a number with at most 2 decimal places"},
+		{InRangeString{Min: "1"}, "Minimum is 1"},
+		{InRangeString{Max: "9.5"}, "Maximum is 9.5"},
+		{InRangeString{Min: "1", Max: "9.5"}, "Range is 1 to 9.5"},
+	}
+
+	for _, c := range cases {
+		if got := c.check.DefaultMessage(); got != c.want {
+			t.Errorf("%#v message = %q", c.check, got)
+		}
+	}
+}
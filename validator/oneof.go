@@ -0,0 +1,93 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Requires a value to be one of Allowed, such as an option of a select
+// box.  Numbers of different types are equal if their values are, so
+// that int64(2) is one of []interface{}{1, 2}.
+type OneOf struct {
+	Allowed []interface{}
+}
+
+func equalValues(a, b interface{}) bool {
+	if fa, ok := toFloat64(a); ok {
+		fb, ok := toFloat64(b)
+		return ok && fa == fb
+	}
+
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	ta := reflect.TypeOf(a)
+	if ta.Comparable() && ta == reflect.TypeOf(b) {
+		return a == b
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func (o OneOf) IsSatisfied(obj interface{}) bool {
+	if obj == nil {
+		return false
+	}
+
+	for _, allowed := range o.Allowed {
+		if equalValues(obj, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (o OneOf) DefaultMessage() string {
+	values := make([]string, len(o.Allowed))
+	for i, v := range o.Allowed {
+		values[i] = fmt.Sprint(v)
+	}
+
+	return "Must be one of " + strings.Join(values, ", ")
+}
+
+func (o OneOf) Rules() Rule {
+	return Rule{Name: "one_of", Params: map[string]interface{}{"allowed": o.Allowed}}
+}
+
+// Requires a string to be one of Values, ignoring case if CaseInsensitive
+// is set.
+type In struct {
+	Values          []string
+	CaseInsensitive bool
+}
+
+func (in In) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	for _, v := range in.Values {
+		if str == v || in.CaseInsensitive && strings.EqualFold(str, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (in In) DefaultMessage() string {
+	return "Must be one of " + strings.Join(in.Values, ", ")
+}
+
+func (v *Validation) OneOf(obj interface{}, allowed ...interface{}) *ValidationResult {
+	return v.apply(OneOf{allowed}, obj)
+}
+
+func (v *Validation) In(str string, values ...string) *ValidationResult {
+	return v.apply(In{Values: values}, str)
+}
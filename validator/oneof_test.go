@@ -0,0 +1,67 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOneOf(t *testing.T) {
+	cases := []struct {
+		check Validator
+		obj   interface{}
+		want  bool
+	}{
+		{OneOf{[]interface{}{1, 2, "three"}}, int64(2), true},
+		{OneOf{[]interface{}{1, 2, "three"}}, 2.0, true},
+		{OneOf{[]interface{}{1, 2, "three"}}, uint8(3), false},
+		{OneOf{[]interface{}{1, 2, "three"}}, "three", true},
+		{OneOf{[]interface{}{1, 2, "three"}}, "2", false},
+		{OneOf{[]interface{}{[]string{"a"}}}, []string{"a"}, true},
+		{OneOf{[]interface{}{nil}}, nil, false},
+		{In{Values: []string{"red", "green"}}, "green", true},
+		{In{Values: []string{"red", "green"}}, "Green", false},
+		{In{Values: []string{"red", "green"}, CaseInsensitive: true}, "Green", true},
+		{In{Values: []string{"1"}}, 1, false},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.obj); got != c.want {
+			t.Errorf("%#v(%#v) = %v", c.check, c.obj, got)
+		}
+	}
+
+	v := &Validation{}
+	if r := v.OneOf(5, 1, 2.5).Key("n"); r.Ok || r.Error.Message != "Must be one of 1, 2.5" {
+		t.Errorf("OneOf error %v", r.Error)
+	}
+
+	if r := v.In("pink", "red", "green").Key("color"); r.Ok || r.Error.Message != "Must be one of red, green" {
+		t.Errorf("In error %v", r.Error)
+	}
+
+	if rule := DescribeRule(OneOf{[]interface{}{1}}); rule.Name != "one_of" || len(rule.Params["allowed"].([]interface{})) != 1 {
+		t.Errorf("rule %+v", rule)
+	}
+}
+
+func TestOneOfTag(t *testing.T) {
+	type shirt struct {
+		Color string  `validate:"oneof=red green blue"`
+		Size  int     `validate:"oneof=38 40 42"`
+		Fit   float64 `validate:"oneof=0.5 1"`
+	}
+
+	v := (&Validation{}).ValidateStruct(shirt{Color: "green", Size: 40, Fit: 0.5})
+	if v.HasErrors() {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	v = (&Validation{}).ValidateStruct(shirt{Color: "Green", Size: 39, Fit: 2})
+	if errs := v.ErrorMap(); len(errs) != 3 || errs["Color"].Message != "Must be one of red, green, blue" || errs["Size"].Message != "Must be one of 38, 40, 42" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if chk := tagValidator("oneof", "1 x", reflect.TypeOf(0)); chk != nil {
+		t.Errorf("oneof of a non-number on an int = %#v", chk)
+	}
+}
@@ -0,0 +1,174 @@
+package validator
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Requires a string to satisfy a password policy.  Blacklist is matched
+// case-insensitively, and MinEntropy, if set, is a minimum number of bits
+// as estimated by Entropy.
+type Password struct {
+	MinLen        int
+	RequireUpper  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Blacklist     []string
+	MinEntropy    float64
+}
+
+func isSymbol(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// Estimate the entropy of a password in bits, as its length times the
+// log2 of the size of the character classes it draws from.
+func Entropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r < utf8.RuneSelf && unicode.IsLower(r):
+			lower = true
+		case r < utf8.RuneSelf && unicode.IsUpper(r):
+			upper = true
+		case r < utf8.RuneSelf && unicode.IsDigit(r):
+			digit = true
+		case r < utf8.RuneSelf && (isSymbol(r) || r == ' '):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+
+	if pool == 0 {
+		return 0
+	}
+
+	return float64(utf8.RuneCountInString(password)) * math.Log2(float64(pool))
+}
+
+// Return a description of each rule of the policy the password breaks, in
+// the form of DefaultMessage, or nil if it satisfies them all.
+func (p Password) Problems(password string) []string {
+	problems := []string{}
+	if utf8.RuneCountInString(password) < p.MinLen {
+		problems = append(problems, "be at least "+strconv.Itoa(p.MinLen)+" characters long")
+	}
+
+	var upper, digit, symbol bool
+	for _, r := range password {
+		upper = upper || unicode.IsUpper(r)
+		digit = digit || unicode.IsDigit(r)
+		symbol = symbol || isSymbol(r)
+	}
+
+	if p.RequireUpper && !upper {
+		problems = append(problems, "contain an upper case letter")
+	}
+
+	if p.RequireDigit && !digit {
+		problems = append(problems, "contain a digit")
+	}
+
+	if p.RequireSymbol && !symbol {
+		problems = append(problems, "contain a symbol")
+	}
+
+	for _, word := range p.Blacklist {
+		if strings.EqualFold(password, word) {
+			problems = append(problems, "not be a commonly used password")
+			break
+		}
+	}
+
+	if p.MinEntropy > 0 && Entropy(password) < p.MinEntropy {
+		problems = append(problems, "be harder to guess")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return problems
+}
+
+func (p Password) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	return p.Problems(str) == nil
+}
+
+// Describe the whole policy, e.g. "Password must be at least 8 characters
+// long, contain an upper case letter and contain a digit".
+func (p Password) DefaultMessage() string {
+	rules := []string{}
+	if p.MinLen > 0 {
+		rules = append(rules, "be at least "+strconv.Itoa(p.MinLen)+" characters long")
+	}
+
+	if p.RequireUpper {
+		rules = append(rules, "contain an upper case letter")
+	}
+
+	if p.RequireDigit {
+		rules = append(rules, "contain a digit")
+	}
+
+	if p.RequireSymbol {
+		rules = append(rules, "contain a symbol")
+	}
+
+	if len(p.Blacklist) > 0 {
+		rules = append(rules, "not be a commonly used password")
+	}
+
+	if p.MinEntropy > 0 {
+		rules = append(rules, "be hard to guess")
+	}
+
+	return "Password must " + joinRules(rules)
+}
+
+func joinRules(rules []string) string {
+	switch len(rules) {
+	case 0:
+		return "be valid"
+	case 1:
+		return rules[0]
+	}
+
+	return strings.Join(rules[:len(rules)-1], ", ") + " and " + rules[len(rules)-1]
+}
+
+// Check a password against a policy.  The error message names only the
+// rules the password breaks, unless a translation of Password replaces it.
+func (v *Validation) Password(str string, policy Password) *ValidationResult {
+	result := v.apply(policy, str)
+	describeProblems(result, policy, str)
+
+	return result
+}
+
+func describeProblems(result *ValidationResult, policy Password, str string) {
+	if !result.Ok && result.Error.Message == policy.DefaultMessage() {
+		if problems := policy.Problems(str); problems != nil {
+			result.Message("Password must %s", joinRules(problems))
+		}
+	}
+}
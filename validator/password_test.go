@@ -0,0 +1,69 @@
+package validator
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestEntropy(t *testing.T) {
+	cases := map[string]float64{
+		"":          0,
+		"abc":       3 * math.Log2(26),
+		"aA1!":      4 * math.Log2(95),
+		"pass word": 9 * math.Log2(59),
+		"ção":       3 * math.Log2(126),
+	}
+
+	for password, want := range cases {
+		if got := Entropy(password); math.Abs(got-want) > 1e-9 {
+			t.Errorf("Entropy(%q) = %v, want %v", password, got, want)
+		}
+	}
+}
+
+func TestPasswordProblems(t *testing.T) {
+	policy := Password{MinLen: 8, RequireUpper: true, RequireDigit: true, RequireSymbol: true, Blacklist: []string{"Passw0rd!"}, MinEntropy: 40}
+	cases := map[string][]string{
+		"Tr0ub4dor&3": nil,
+		"PASSW0RD!":   {"not be a commonly used password"},
+		"short":       {"be at least 8 characters long", "contain an upper case letter", "contain a digit", "contain a symbol", "be harder to guess"},
+		"Ünïcödé1€":   nil,
+		"Aaaaaaa1!":   nil,
+	}
+
+	for password, want := range cases {
+		if got := policy.Problems(password); !reflect.DeepEqual(got, want) {
+			t.Errorf("Problems(%q) = %q, want %q", password, got, want)
+		}
+	}
+
+	if policy.IsSatisfied([]byte("Tr0ub4dor&3")) {
+		t.Error("a []byte satisfies the policy")
+	}
+}
+
+func TestPasswordMessages(t *testing.T) {
+	policy := Password{MinLen: 8, RequireUpper: true, RequireDigit: true}
+	if msg := policy.DefaultMessage(); msg != "Password must be at least 8 characters long, contain an upper case letter and contain a digit" {
+		t.Errorf("message %q", msg)
+	}
+
+	if msg := (Password{}).DefaultMessage(); msg != "Password must be valid" {
+		t.Errorf("message %q", msg)
+	}
+
+	v := &Validation{}
+	if r := v.Password("longenough", policy).Key("pw"); r.Ok || r.Error.Message != "Password must contain an upper case letter and contain a digit" {
+		t.Errorf("Password = %v", r.Error)
+	}
+
+	if r := v.Password("abc", Password{MinEntropy: 30}).Key("pw"); r.Error.Message != "Password must be harder to guess" {
+		t.Errorf("Password = %v", r.Error)
+	}
+
+	// A message of the caller's is kept.
+	if r := v.Password("x", policy).Message("Too weak").Key("pw"); r.Error.Message != "Too weak" {
+		t.Errorf("Password = %v", r.Error)
+	}
+}
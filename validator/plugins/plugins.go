@@ -0,0 +1,113 @@
+package plugins
+
+import (
+	"fmt"
+	"golanger.com/framework/validator"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sync"
+)
+
+// Plugins are opened one at a time, so that the validators each one
+// registers can be told apart.
+var mutex sync.Mutex
+
+// Open a Go plugin (a package built with -buildmode=plugin) whose init
+// registers validators with validator.Register or RegisterDefinition, and
+// return the names it registered.  A plugin may instead, or as well,
+// export a func Validators() []validator.Definition paired with a func
+// Factory(name, param string) validator.Validator, which are registered
+// here.  The plugin must be built against the same version of this
+// package as the program, as the plugin package requires.  Opening a
+// plugin again registers nothing new.
+func Load(path string) ([]string, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	before := map[string]validator.Definition{}
+	for _, def := range validator.Registered() {
+		before[def.Name] = def
+	}
+
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := register(p, path); err != nil {
+		return nil, fmt.Errorf("validator/plugins: %s: %v", path, err)
+	}
+
+	names := []string{}
+	for _, def := range validator.Registered() {
+		if old, ok := before[def.Name]; !ok || old.Builtin != def.Builtin || old.Package != def.Package {
+			names = append(names, def.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// Register the validators a plugin exports, if any, as from the plugin
+// file unless they name their package.
+func register(p *plugin.Plugin, path string) error {
+	sym, err := p.Lookup("Validators")
+	if err != nil {
+		return nil
+	}
+
+	list, ok := sym.(func() []validator.Definition)
+	if !ok {
+		return fmt.Errorf("Validators is %T, not func() []validator.Definition", sym)
+	}
+
+	sym, err = p.Lookup("Factory")
+	if err != nil {
+		return fmt.Errorf("Validators without Factory")
+	}
+
+	factory, ok := sym.(func(name, param string) validator.Validator)
+	if !ok {
+		return fmt.Errorf("Factory is %T, not func(name, param string) validator.Validator", sym)
+	}
+
+	for _, def := range list() {
+		name := def.Name
+		if def.Package == "" {
+			def.Package = path
+		}
+
+		validator.RegisterDefinition(def, func(param string) validator.Validator {
+			return factory(name, param)
+		})
+	}
+
+	return nil
+}
+
+// Load every plugin, a file named *.so, of a directory, in name order,
+// and return the names of the validators they registered.  Loading stops
+// at the first plugin that fails.
+func LoadDir(dir string) ([]string, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, path := range paths {
+		loaded, err := Load(path)
+		if err != nil {
+			return names, err
+		}
+
+		names = append(names, loaded...)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,24 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDir(t *testing.T) {
+	if _, err := LoadDir(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("no error for a missing directory")
+	}
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "README"), []byte("not a plugin"), 0644)
+	if names, err := LoadDir(dir); err != nil || len(names) != 0 {
+		t.Errorf("LoadDir of a directory without plugins = %v, %v", names, err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "bad.so"), []byte("not a plugin"), 0644)
+	if _, err := LoadDir(dir); err == nil {
+		t.Error("no error for a file that is not a plugin")
+	}
+}
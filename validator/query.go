@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"net/url"
+	"sort"
+)
+
+// Validate the parameters of a query string against a set of rules.  A
+// missing parameter is checked as the empty string so that Required
+// still triggers, and its errors are keyed by the parameter name.  A
+// parameter given several times has every value checked, with the errors
+// keyed by name and index, as in "tag[1]".
+func (v *Validation) ValidateQuery(values url.Values, rules map[string][]Validator) *Validation {
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		if len(values[name]) > 1 {
+			v.Each(values[name], rules[name]...).Key(name)
+		} else {
+			v.CheckKey(name, values.Get(name), rules[name]...)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,37 @@
+package validator
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestValidateQuery(t *testing.T) {
+	values, _ := url.ParseQuery("page=2&sort=name&tag=go&tag=&tag=web")
+	v := (&Validation{}).ValidateQuery(values, map[string][]Validator{
+		"page":  {Required{}, Integer{}},
+		"sort":  {OneOf{Allowed: []interface{}{"name", "date"}}},
+		"q":     {Required{}},
+		"tag":   {Required{}},
+		"limit": {Integer{}},
+	})
+
+	errs := v.ErrorMap()
+	for _, key := range []string{"q", "tag[1]", "limit"} {
+		if errs[key] == nil {
+			t.Errorf("no error for %s in %v", key, errs)
+		}
+	}
+
+	if len(errs) != 3 {
+		t.Errorf("errors %v, want q, tag[1] and limit", errs)
+	}
+}
+
+func TestValidateQueryReportsKeyed(t *testing.T) {
+	v, reported := hooked()
+	values, _ := url.ParseQuery("id=x")
+	v.ValidateQuery(values, map[string][]Validator{"id": {Integer{}}})
+	if len(*reported) != 1 || (*reported)[0].Key != "id" {
+		t.Errorf("reported %v, want one error keyed id", *reported)
+	}
+}
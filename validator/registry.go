@@ -0,0 +1,205 @@
+package validator
+
+import (
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var registry = struct {
+	sync.RWMutex
+	factories   map[string]func(param string) Validator
+	definitions map[string]Definition
+}{
+	factories:   map[string]func(param string) Validator{},
+	definitions: map[string]Definition{},
+}
+
+// A parameter of a validator, in the order it is given after "=" in a
+// tag.  Type is one of "string", "int", "number", "duration", "time",
+// "regexp" or "list", for a list of Separator-separated strings.
+type Param struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Optional    bool   `json:"optional,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// The description of a validator available in `validate` tags: its
+// parameters, separated by Separator if there are several, and the
+// package that registered it, or "" for a built-in tag.
+type Definition struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Params      []Param `json:"params,omitempty"`
+	Separator   string  `json:"separator,omitempty"`
+	Package     string  `json:"package,omitempty"`
+	Builtin     bool    `json:"builtin,omitempty"`
+}
+
+// Register a named validator for use in `validate` struct tags and with
+// Named.  The factory is given the text after "=" in the tag (empty if
+// there is none).  A registered name takes precedence over a built-in tag
+// of the same name.
+//
+// Packages of shareable rules usually register them in init, so that a
+// blank import makes them available:
+//
+//	import _ "example.com/rules/iban"
+func Register(name string, factory func(param string) Validator) {
+	RegisterDefinition(Definition{Name: name}, factory)
+}
+
+// Register a named validator as Register does, with the description
+// Registered lists.  The Package of def defaults to that of factory.
+func RegisterDefinition(def Definition, factory func(param string) Validator) {
+	if def.Name == "" || factory == nil {
+		panic("validator: RegisterDefinition needs a name and a factory")
+	}
+
+	if strings.ContainsAny(def.Name, ",=|") {
+		panic("validator: invalid validator name " + def.Name)
+	}
+
+	if def.Package == "" {
+		def.Package = funcPackage(factory)
+	}
+
+	def.Builtin = false
+	registry.Lock()
+	registry.factories[def.Name] = factory
+	registry.definitions[def.Name] = def
+	registry.Unlock()
+}
+
+// Return the registered validator name built with param, or nil if no
+// validator is registered under that name.
+func Named(name, param string) Validator {
+	registry.RLock()
+	factory, ok := registry.factories[name]
+	registry.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	return factory(param)
+}
+
+// List the validators available in `validate` tags, built-in and
+// registered, by name.  A registered validator is listed instead of the
+// built-in tag it overrides.
+func Registered() []Definition {
+	defs := map[string]Definition{}
+	for _, def := range builtinDefinitions {
+		def.Builtin = true
+		defs[def.Name] = def
+	}
+
+	registry.RLock()
+	for name, def := range registry.definitions {
+		defs[name] = def
+	}
+	registry.RUnlock()
+
+	list := make([]Definition, 0, len(defs))
+	for _, def := range defs {
+		list = append(list, def)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// Return the definition of the validator name, as Registered lists it.
+func Lookup(name string) (Definition, bool) {
+	registry.RLock()
+	def, ok := registry.definitions[name]
+	registry.RUnlock()
+	if ok {
+		return def, true
+	}
+
+	for _, def := range builtinDefinitions {
+		if def.Name == name {
+			def.Builtin = true
+			return def, true
+		}
+	}
+
+	return Definition{}, false
+}
+
+// Return the import path of the package declaring the function f.
+func funcPackage(f interface{}) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer())
+	if fn == nil {
+		return ""
+	}
+
+	// e.g. example.com/rules/iban.init.func1 or example.com/rules.(*T).New
+	name := fn.Name()
+	slash := strings.LastIndex(name, "/")
+	if dot := strings.Index(name[slash+1:], "."); dot != -1 {
+		return name[:slash+1+dot]
+	}
+
+	return name
+}
+
+// The tags tagValidator understands.
+var builtinDefinitions = []Definition{
+	{Name: "required", Description: "Required"},
+	{Name: "email", Description: "Email"},
+	{Name: "emailmx", Description: "Email with VerifyMX"},
+	{Name: "url", Description: "URL"},
+	{Name: "ipv4", Description: "IPv4"},
+	{Name: "ipv6", Description: "IPv6"},
+	{Name: "uuid", Description: "UUID"},
+	{Name: "phone", Description: "Phone", Params: []Param{
+		{Name: "region", Type: "string", Optional: true},
+	}},
+	{Name: "creditcard", Description: "CreditCard"},
+	{Name: "luhn", Description: "Luhn"},
+	{Name: "eqfield", Description: "Equal to another field", Params: []Param{{Name: "field", Type: "string"}}},
+	{Name: "nefield", Description: "Different from another field", Params: []Param{{Name: "field", Type: "string"}}},
+	{Name: "gtfield", Description: "Greater than another field", Params: []Param{{Name: "field", Type: "string"}}},
+	{Name: "ltfield", Description: "Less than another field", Params: []Param{{Name: "field", Type: "string"}}},
+	{Name: "gt", Description: "GreaterThan", Params: []Param{
+		{Name: "value", Type: "number", Description: "or a time for a time.Time field"},
+	}},
+	{Name: "lt", Description: "LessThan", Params: []Param{
+		{Name: "value", Type: "number", Description: "or a time for a time.Time field"},
+	}},
+	{Name: "dateformat", Description: "DateFormat", Params: []Param{{Name: "layout", Type: "string"}}},
+	{Name: "age", Description: "Age", Params: []Param{{Name: "min", Type: "int"}}},
+	{Name: "match", Description: "Match", Params: []Param{{Name: "pattern", Type: "regexp"}}},
+	{Name: "safematch", Description: "SafeMatch", Params: []Param{{Name: "pattern", Type: "regexp"}}},
+	{Name: "safehtml", Description: "SafeHTML", Separator: " ", Params: []Param{
+		{Name: "tags", Type: "list", Optional: true},
+	}},
+	{Name: "json", Description: "ValidJSON"},
+	{Name: "honeypot", Description: "Honeypot"},
+	{Name: "mintime", Description: "MinSubmitTime", Params: []Param{{Name: "duration", Type: "duration"}}},
+	{Name: "token", Description: "Token", Params: []Param{{Name: "purpose", Type: "string", Optional: true}}},
+	{Name: "oneof", Description: "OneOf", Separator: " ", Params: []Param{{Name: "values", Type: "list"}}},
+	{Name: "numeric", Description: "Numeric"},
+	{Name: "integer", Description: "Integer"},
+	{Name: "decimal", Description: "Decimal", Params: []Param{
+		{Name: "places", Type: "int", Optional: true},
+	}},
+	{Name: "money", Description: "Money", Separator: ";", Params: []Param{
+		{Name: "currency", Type: "string"},
+		{Name: "min", Type: "number", Optional: true},
+		{Name: "max", Type: "number", Optional: true},
+	}},
+	{Name: "numrange", Description: "InRangeString", Separator: ";", Params: []Param{
+		{Name: "min", Type: "number"},
+		{Name: "max", Type: "number"},
+	}},
+	{Name: "min", Description: "Min, MinSize or MinFloat by the field type", Params: []Param{{Name: "min", Type: "number"}}},
+	{Name: "max", Description: "Max, MaxSize or MaxFloat by the field type", Params: []Param{{Name: "max", Type: "number"}}},
+	{Name: "len", Description: "Length", Params: []Param{{Name: "n", Type: "int"}}},
+}
@@ -0,0 +1,208 @@
+package validator
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A parameter of each type Param lists, as a tag would give it.
+var sampleParams = map[string]string{
+	"string":   "field",
+	"int":      "3",
+	"number":   "1.5",
+	"duration": "2s",
+	"time":     "2020-01-01",
+	"regexp":   "^a+$",
+	"list":     "a",
+}
+
+// Requires an even int of at least min.
+type even struct {
+	min int
+}
+
+func (e even) IsSatisfied(obj interface{}) bool {
+	n, ok := obj.(int)
+	return ok && n%2 == 0 && n >= e.min
+}
+
+func (e even) DefaultMessage() string {
+	return "Must be even"
+}
+
+func TestBuiltinDefinitionsParse(t *testing.T) {
+	for _, def := range builtinDefinitions {
+		params := []string{}
+		field := reflect.TypeOf("")
+		for _, p := range def.Params {
+			params = append(params, sampleParams[p.Type])
+			if p.Type == "number" {
+				field = reflect.TypeOf(0.0)
+			}
+		}
+
+		param := ""
+		for i, p := range params {
+			if i > 0 {
+				param += def.Separator
+			}
+
+			param += p
+		}
+
+		if def.Name == "dateformat" {
+			param = "2006-01-02"
+		}
+
+		if tagValidator(def.Name, param, field) == nil {
+			t.Errorf("listed tag %s=%s not understood", def.Name, param)
+		}
+	}
+}
+
+func TestRegisterAndRegistered(t *testing.T) {
+	RegisterDefinition(Definition{
+		Name:        "test_even",
+		Description: "Even",
+		Params:      []Param{{Name: "min", Type: "int", Optional: true}},
+	}, func(param string) Validator {
+		min, _ := strconv.Atoi(param)
+		return even{min}
+	})
+
+	def, ok := Lookup("test_even")
+	if !ok || def.Builtin || def.Package != "golanger.com/framework/validator" || def.Params[0].Name != "min" {
+		t.Errorf("definition %+v, %v", def, ok)
+	}
+
+	if !Named("test_even", "").IsSatisfied(4) || Named("test_even", "6").IsSatisfied(4) {
+		t.Error("Named does not build with the parameter")
+	}
+
+	if Named("test_missing", "") != nil {
+		t.Error("Named built an unregistered validator")
+	}
+
+	v := &Validation{}
+	v.ValidateStruct(struct {
+		N int `validate:"test_even=2"`
+	}{3})
+	if v.ErrorMap()["N"] == nil {
+		t.Error("registered tag not checked")
+	}
+
+	list := Registered()
+	if !sort.SliceIsSorted(list, func(i, j int) bool { return list[i].Name < list[j].Name }) {
+		t.Error("Registered not sorted by name")
+	}
+
+	names := map[string]Definition{}
+	for _, def := range list {
+		names[def.Name] = def
+	}
+
+	if !names["required"].Builtin || names["test_even"].Builtin || len(names) != len(list) {
+		t.Errorf("Registered lists %v", names)
+	}
+}
+
+func TestRegisterOverridesBuiltin(t *testing.T) {
+	Register("test_override", func(string) Validator { return Required{} })
+	builtinDefinitions = append(builtinDefinitions, Definition{Name: "test_override"})
+	defer func() { builtinDefinitions = builtinDefinitions[:len(builtinDefinitions)-1] }()
+
+	count := 0
+	for _, def := range Registered() {
+		if def.Name == "test_override" {
+			count++
+			if def.Builtin {
+				t.Error("overridden tag listed as built-in")
+			}
+		}
+	}
+
+	if count != 1 {
+		t.Errorf("test_override listed %d times", count)
+	}
+}
+
+func TestRegisteredTagChecked(t *testing.T) {
+	Register("uuid", func(param string) Validator { return even{} })
+	defer func() {
+		registry.Lock()
+		delete(registry.factories, "uuid")
+		delete(registry.definitions, "uuid")
+		registry.Unlock()
+	}()
+
+	v := &Validation{}
+	v.ValidateStruct(struct {
+		ID    int `validate:"uuid"`
+		Other int `validate:"uuid"`
+	}{4, 5})
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["Other"].Message != "Must be even" {
+		t.Errorf("errors %v", errs)
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	for _, f := range []func(){
+		func() { Register("", func(string) Validator { return Required{} }) },
+		func() { Register("test_nil", nil) },
+		func() { Register("a,b", func(string) Validator { return Required{} }) },
+		func() { Register("a=b", func(string) Validator { return Required{} }) },
+		func() { Register("a|b", func(string) Validator { return Required{} }) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic for an invalid registration")
+				}
+			}()
+
+			f()
+		}()
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		name := "test_concurrent" + strconv.Itoa(i)
+		go func() {
+			defer wg.Done()
+			Register(name, func(string) Validator { return Required{} })
+		}()
+
+		go func() {
+			defer wg.Done()
+			v := &Validation{}
+			v.ValidateStruct(struct {
+				S string `validate:"required"`
+			}{"x"})
+			Registered()
+		}()
+	}
+
+	wg.Wait()
+	for i := 0; i < 8; i++ {
+		if _, ok := Lookup("test_concurrent" + strconv.Itoa(i)); !ok {
+			t.Errorf("test_concurrent%d not registered", i)
+		}
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	if got := funcPackage(time.Now); got != "time" {
+		t.Errorf("funcPackage(time.Now) = %q", got)
+	}
+
+	if got := funcPackage((*Validation).Required); got != "golanger.com/framework/validator" {
+		t.Errorf("funcPackage of a method = %q", got)
+	}
+}
@@ -0,0 +1,293 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A machine-readable description of a validator, for exporting the rules
+// of a struct to client-side validation.
+type Rule struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// A validator may implement RuleDescriber to describe itself; otherwise
+// DescribeRule derives the description from its type and fields.
+type RuleDescriber interface {
+	Rules() Rule
+}
+
+// Describe a validator: its Rules if it implements RuleDescriber, or else
+// its error code without the "validation." prefix and its exported fields
+// of simple types, by lower case name.
+func DescribeRule(chk Validator) Rule {
+	if d, ok := chk.(RuleDescriber); ok {
+		return d.Rules()
+	}
+
+	rule := Rule{Name: strings.TrimPrefix(errorCode(chk), "validation.")}
+	params := map[string]interface{}{}
+	var collect func(rv reflect.Value)
+	collect = func(rv reflect.Value) {
+		for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+			if re, ok := rv.Interface().(*regexp.Regexp); ok {
+				params["pattern"] = re.String()
+				return
+			}
+
+			rv = rv.Elem()
+		}
+
+		if rv.Kind() != reflect.Struct {
+			return
+		}
+
+		for i := 0; i < rv.NumField(); i++ {
+			sf := rv.Type().Field(i)
+			f := rv.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			if sf.Anonymous {
+				collect(f)
+				continue
+			}
+
+			if re, ok := f.Interface().(*regexp.Regexp); ok && re != nil {
+				params["pattern"] = re.String()
+			} else if simpleParam(f) {
+				params[strings.ToLower(sf.Name)] = f.Interface()
+			}
+		}
+	}
+
+	collect(reflect.ValueOf(chk))
+	if len(params) > 0 {
+		rule.Params = params
+	}
+
+	return rule
+}
+
+// Report whether a parameter can be exported as is: numbers, strings,
+// booleans, times and slices of them.
+func simpleParam(f reflect.Value) bool {
+	switch f.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice, reflect.Array:
+		return f.Type().Elem().Kind() != reflect.Interface && simpleParam(reflect.Zero(f.Type().Elem()))
+	case reflect.Struct:
+		return f.Type() == reflect.TypeOf(time.Time{})
+	}
+
+	return false
+}
+
+// Describe the other field compared with, not its value.
+func (e EqualTo) Rules() Rule {
+	return Rule{Name: "equal_to", Params: map[string]interface{}{"field": e.Field}}
+}
+
+func (d DifferentFrom) Rules() Rule {
+	return Rule{Name: "different_from", Params: map[string]interface{}{"field": d.Field}}
+}
+
+func (f fieldRef) Rules() Rule {
+	names := map[string]string{
+		"eqfield": "equal_to",
+		"nefield": "different_from",
+		"gtfield": "greater_than",
+		"ltfield": "less_than",
+	}
+
+	return Rule{Name: names[f.tag], Params: map[string]interface{}{"field": f.Field}}
+}
+
+// Describe the other field compared with, or else the bound.
+func (g GreaterThan) Rules() Rule {
+	return boundRule("greater_than", g.Value, g.Field)
+}
+
+func (l LessThan) Rules() Rule {
+	return boundRule("less_than", l.Value, l.Field)
+}
+
+func boundRule(name string, value interface{}, field string) Rule {
+	if field != "" {
+		return Rule{Name: name, Params: map[string]interface{}{"field": field}}
+	}
+
+	return Rule{Name: name, Params: map[string]interface{}{"value": value}}
+}
+
+// The list could be large and is of no use to a client.
+func (p PasswordNotCommon) Rules() Rule {
+	return Rule{Name: "password_not_common"}
+}
+
+// Uniqueness can only be checked on the server.
+func (u Unique) Rules() Rule {
+	return Rule{Name: "unique"}
+}
+
+// The rules of one field, and the HTML5 input attributes equivalent to
+// those of them that have one.
+type FieldRules struct {
+	Rules []Rule            `json:"rules"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+}
+
+// Return the HTML5 input attributes equivalent to checks, e.g. required
+// and maxlength="40" for Required{} and MaxSize{40}, for the browser to
+// enforce the rules the server checks.
+func HTML5Attrs(checks ...Validator) map[string]string {
+	attrs := map[string]string{}
+	for _, chk := range checks {
+		html5Attrs(chk, attrs)
+	}
+
+	return attrs
+}
+
+// Add the HTML5 input attributes equivalent to a validator to attrs.
+func html5Attrs(chk Validator, attrs map[string]string) {
+	switch c := unwrap(chk).(type) {
+	case Required:
+		attrs["required"] = "required"
+	case Email:
+		attrs["type"] = "email"
+	case URL:
+		attrs["type"] = "url"
+	case Match:
+		if c.Regexp != nil {
+			attrs["pattern"] = c.Regexp.String()
+		}
+	case SafeMatch:
+		if CheckPattern(c.Pattern) == nil {
+			attrs["pattern"] = c.Pattern
+		}
+	case Integer:
+		attrs["inputmode"] = "numeric"
+	case Numeric, Decimal, InRangeString:
+		attrs["inputmode"] = "decimal"
+	case Money:
+		attrs["inputmode"] = "decimal"
+		attrs["step"] = "1"
+		if c.scale() > 0 {
+			attrs["step"] = "0." + strings.Repeat("0", c.scale()-1) + "1"
+		}
+
+		if c.Min != "" {
+			attrs["min"] = c.Min
+		}
+
+		if c.Max != "" {
+			attrs["max"] = c.Max
+		}
+	case MinSize:
+		attrs["minlength"] = fmt.Sprint(c.Min)
+	case MaxSize:
+		attrs["maxlength"] = fmt.Sprint(c.Max)
+	case Length:
+		attrs["minlength"] = fmt.Sprint(c.N)
+		attrs["maxlength"] = fmt.Sprint(c.N)
+	case Min:
+		attrs["min"] = fmt.Sprint(c.Min)
+	case Max:
+		attrs["max"] = fmt.Sprint(c.Max)
+	case Range:
+		attrs["min"] = fmt.Sprint(c.Min.Min)
+		attrs["max"] = fmt.Sprint(c.Max.Max)
+	case MinFloat:
+		attrs["min"] = fmt.Sprint(c.Min)
+	case MaxFloat:
+		attrs["max"] = fmt.Sprint(c.Max)
+	}
+}
+
+// Export the rules of the `validate` tags of a struct type (or a value or
+// pointer of it) as JSON mapping each field key, as ValidateStruct would
+// record it, to its FieldRules.  Rules of other scenarios are left out.
+func (v *Validation) ExportRules(structType interface{}) ([]byte, error) {
+	rules, err := v.StructRules(structType)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rules)
+}
+
+// Return the rules ExportRules encodes, mapped by field key.
+func (v *Validation) StructRules(structType interface{}) (map[string]*FieldRules, error) {
+	t, ok := structType.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(structType)
+	}
+
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("validator: StructRules needs a struct type")
+	}
+
+	rules := map[string]*FieldRules{}
+	v.exportRules(t, "", rules, map[reflect.Type]bool{})
+
+	return rules, nil
+}
+
+func (v *Validation) exportRules(t reflect.Type, prefix string, rules map[string]*FieldRules, visited map[reflect.Type]bool) {
+	if visited[t] {
+		return
+	}
+
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := fieldRules(t, sf)
+		if tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if d := diveIndex(tag); d >= 0 {
+			tag = tag[:d]
+		}
+
+		checks := v.parseTag(sf.Type, tag)
+		if len(checks) > 0 {
+			fr := &FieldRules{Rules: []Rule{}, Attrs: map[string]string{}}
+			for _, chk := range checks {
+				fr.Rules = append(fr.Rules, DescribeRule(chk))
+				html5Attrs(chk, fr.Attrs)
+			}
+
+			rules[prefix+sf.Name] = fr
+		}
+
+		if ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}) {
+			if sf.Anonymous {
+				v.exportRules(ft, prefix, rules, visited)
+			} else {
+				v.exportRules(ft, prefix+sf.Name+".", rules, visited)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestHTML5Attrs(t *testing.T) {
+	cases := []struct {
+		checks []Validator
+		want   map[string]string
+	}{
+		{[]Validator{Required{}, MaxSize{40}}, map[string]string{"required": "required", "maxlength": "40"}},
+		{[]Validator{NewEmail()}, map[string]string{"type": "email"}},
+		{[]Validator{Length{5}}, map[string]string{"minlength": "5", "maxlength": "5"}},
+		{[]Validator{Range{Min{1}, Max{9}}}, map[string]string{"min": "1", "max": "9"}},
+		{[]Validator{Match{regexp.MustCompile("^[a-z]+$")}}, map[string]string{"pattern": "^[a-z]+$"}},
+		{[]Validator{Luhn{}}, map[string]string{}},
+	}
+
+	for _, c := range cases {
+		got := HTML5Attrs(c.checks...)
+		if len(got) != len(c.want) {
+			t.Errorf("HTML5Attrs(%v) = %v, want %v", c.checks, got, c.want)
+			continue
+		}
+
+		for k, v := range c.want {
+			if got[k] != v {
+				t.Errorf("HTML5Attrs(%v) = %v, want %v", c.checks, got, c.want)
+			}
+		}
+	}
+}
+
+func TestStructRulesAttrs(t *testing.T) {
+	rules, err := (&Validation{}).StructRules(struct {
+		Name string `validate:"required,min=2"`
+		Note string
+	}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rules["Name"] == nil || rules["Name"].Attrs["required"] != "required" {
+		t.Errorf("rules %v", rules["Name"])
+	}
+
+	if rules["Note"] != nil {
+		t.Errorf("rules for a field without tags: %v", rules["Note"])
+	}
+}
+
+func TestDescribeRule(t *testing.T) {
+	cases := []struct {
+		check Validator
+		want  Rule
+	}{
+		{Required{}, Rule{Name: "required"}},
+		{MinSize{3}, Rule{Name: "min_size", Params: map[string]interface{}{"min": 3}}},
+		{Range{Min{1}, Max{9}}, Rule{Name: "range", Params: map[string]interface{}{"min": 1, "max": 9}}},
+		{Match{regexp.MustCompile(`^\d+$`)}, Rule{Name: "match", Params: map[string]interface{}{"pattern": `^\d+$`}}},
+		{Phone{"US"}, Rule{Name: "phone", Params: map[string]interface{}{"region": "US"}}},
+		{Password{MinLen: 8, Blacklist: []string{"x"}}, Rule{Name: "password", Params: map[string]interface{}{
+			"minlen": 8, "requireupper": false, "requiredigit": false, "requiresymbol": false, "blacklist": []string{"x"}, "minentropy": 0.0,
+		}}},
+		{EqualTo{Value: "secret", Field: "Password"}, Rule{Name: "equal_to", Params: map[string]interface{}{"field": "Password"}}},
+		{fieldRef{"Old", "nefield"}, Rule{Name: "different_from", Params: map[string]interface{}{"field": "Old"}}},
+		{PasswordNotCommon{map[string]bool{"123456": true}}, Rule{Name: "password_not_common"}},
+		{Unique{Table: "users", Column: "email"}, Rule{Name: "unique"}},
+		{&Password{}, DescribeRule(Password{})},
+	}
+
+	for _, c := range cases {
+		if got := DescribeRule(c.check); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("DescribeRule(%#v) = %#v, want %#v", c.check, got, c.want)
+		}
+	}
+}
+
+func TestExportRules(t *testing.T) {
+	type address struct {
+		City string `validate:"required"`
+	}
+
+	type form struct {
+		Name    string   `validate:"required,max=40"`
+		Code    string   `validate:"required|scenario=admin"`
+		Tags    []string `validate:"max=3,dive,required"`
+		Address *address
+		Secret  string `validate:"-"`
+	}
+
+	b, err := (&Validation{}).ExportRules(&form{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got map[string]*FieldRules
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 3 || got["Address.City"] == nil || got["Code"] != nil {
+		t.Errorf("exported %s", b)
+	}
+
+	if name := got["Name"]; len(name.Rules) != 2 || name.Rules[1].Name != "max_size" || name.Rules[1].Params["max"] != 40.0 || name.Attrs["maxlength"] != "40" {
+		t.Errorf("Name %+v", name)
+	}
+
+	if tags := got["Tags"]; len(tags.Rules) != 1 || tags.Rules[0].Name != "max_size" {
+		t.Errorf("Tags %+v", tags)
+	}
+
+	admin, _ := (&Validation{}).Scenario("admin").StructRules(reflect.TypeOf(form{}))
+	if admin["Code"] == nil {
+		t.Error("rules of the scenario left out")
+	}
+
+	if _, err := (&Validation{}).StructRules("not a struct"); err == nil {
+		t.Error("no error for a string")
+	}
+}
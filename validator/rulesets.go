@@ -0,0 +1,109 @@
+package validator
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var rulesets = struct {
+	sync.RWMutex
+	types map[reflect.Type]map[string]string
+}{
+	types: map[reflect.Type]map[string]string{},
+}
+
+// Register the rules of the fields of a struct type, for types whose
+// fields cannot carry `validate` tags, such as generated protobuf
+// messages.  rules maps field names to rules in the syntax of the tag:
+//
+//	validator.RegisterRules(&pb.TransferRequest{}, map[string]string{
+//		"AccountId": "required",
+//		"Amount":    "min=1",
+//		"Items":     "required,dive",
+//	})
+//
+// A `validate` tag takes precedence over the registered rules of its field.
+func RegisterRules(schema interface{}, rules map[string]string) {
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		panic("validator: RegisterRules needs a struct type")
+	}
+
+	copied := map[string]string{}
+	for k, v := range rules {
+		copied[k] = v
+	}
+
+	rulesets.Lock()
+	rulesets.types[t] = copied
+	rulesets.Unlock()
+}
+
+// Return the rules of field sf of struct type t: its `validate` tag, or
+// else those registered for it.
+func fieldRules(t reflect.Type, sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("validate"); ok {
+		return tag
+	}
+
+	rulesets.RLock()
+	defer rulesets.RUnlock()
+
+	return rulesets.types[t][sf.Name]
+}
+
+// A failed check in the form of a google.rpc.BadRequest field violation:
+// the path of the field by its protobuf names, e.g. "items[1].price".
+type FieldViolation struct {
+	Field       string
+	Description string
+	Code        string
+}
+
+// Return the name of a field in protobuf field paths: the name of its
+// protobuf tag, or else of its json tag, or else its Go name.
+func protoName(sf reflect.StructField) string {
+	for _, part := range strings.Split(sf.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(part, "name=") {
+			return part[len("name="):]
+		}
+	}
+
+	return graphqlName(sf)
+}
+
+// Return the errors of v, which validated a value of the type of schema,
+// as field violations.
+func (v *Validation) FieldViolations(schema interface{}) []FieldViolation {
+	t, ok := schema.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(schema)
+	}
+
+	violations := []FieldViolation{}
+	for _, e := range v.Errors {
+		field := ""
+		for _, p := range schemaPath(t, e.Key, nil, protoName) {
+			switch p := p.(type) {
+			case int:
+				field += "[" + strconv.Itoa(p) + "]"
+			default:
+				if field != "" {
+					field += "."
+				}
+
+				field += p.(string)
+			}
+		}
+
+		violations = append(violations, FieldViolation{Field: field, Description: e.Message, Code: e.Code})
+	}
+
+	return violations
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"golanger.com/framework/validator/sanitize"
+	"strings"
+)
+
+// Requires a string of rich text, such as a comment or a bio, to hold no
+// HTML but the AllowedTags, sanitize.DefaultHTMLTags if none, with their
+// safe attributes: no scripts, event handlers, javascript: links or other
+// tags.  See sanitize.HTMLPolicy, whose Sanitize cleans a value instead of
+// rejecting it.
+type SafeHTML struct {
+	AllowedTags []string
+}
+
+func (s SafeHTML) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	_, removed := sanitize.NewHTMLPolicy(s.AllowedTags...).Clean(str)
+
+	return !removed
+}
+
+func (s SafeHTML) DefaultMessage() string {
+	tags := s.AllowedTags
+	if len(tags) == 0 {
+		tags = sanitize.DefaultHTMLTags
+	}
+
+	return "Must contain only the HTML tags " + strings.Join(tags, ", ")
+}
+
+// Return str sanitized with the policy of s.
+func (s SafeHTML) Sanitize(str string) string {
+	return sanitize.NewHTMLPolicy(s.AllowedTags...).Sanitize(str)
+}
+
+// An HTMLResult is a ValidationResult that also holds the sanitized value,
+// safe to store and to output unescaped whether or not the check passed.
+type HTMLResult struct {
+	*ValidationResult
+	Clean string
+}
+
+// Check rich text against an allowlist of tags, sanitize.DefaultHTMLTags
+// if none, and return it cleaned too, so that a handler may either reject
+// it or keep the clean value.
+func (v *Validation) SafeHTML(str string, allowedTags ...string) *HTMLResult {
+	check := SafeHTML{allowedTags}
+
+	return &HTMLResult{v.apply(check, str), check.Sanitize(str)}
+}
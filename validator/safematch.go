@@ -0,0 +1,189 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golanger.com/framework/log"
+	"regexp"
+	"regexp/syntax"
+	"sync"
+	"time"
+)
+
+// The limits of SafeMatch: the default time a match may take, the longest
+// pattern and input accepted, the largest compiled program, and the
+// number of patterns kept compiled.
+var (
+	SafeMatchTimeout = 100 * time.Millisecond
+	MaxPatternLen    = 1000
+	MaxMatchInput    = 64 << 10
+	MaxPatternInsts  = 5000
+	PatternCacheSize = 1000
+)
+
+var ErrUnsafePattern = errors.New("validator: unsafe pattern")
+
+var patternCache = struct {
+	sync.Mutex
+	entries map[string]patternEntry
+}{entries: map[string]patternEntry{}}
+
+type patternEntry struct {
+	re  *regexp.Regexp
+	err error
+}
+
+// Report whether t holds a quantifier that repeats without bound.
+func hasUnboundedRepeat(t *syntax.Regexp) bool {
+	switch t.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		if t.Max == -1 {
+			return true
+		}
+	}
+
+	for _, sub := range t.Sub {
+		if hasUnboundedRepeat(sub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return an error for the constructs that blow up backtracking engines,
+// or that make large programs even for Go's linear-time one: nested
+// unbounded quantifiers such as (a+)+, and patterns too long or compiling
+// to more than MaxPatternInsts instructions, such as ((ab|cd|ef){30}){30}.
+// The pattern must also be valid Go syntax.
+func CheckPattern(pattern string) error {
+	if len(pattern) > MaxPatternLen {
+		return fmt.Errorf("%w: longer than %d bytes", ErrUnsafePattern, MaxPatternLen)
+	}
+
+	tree, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return err
+	}
+
+	var nested func(t *syntax.Regexp) bool
+	nested = func(t *syntax.Regexp) bool {
+		switch t.Op {
+		case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+			if (t.Op != syntax.OpRepeat || t.Max == -1 || t.Max > 1) && hasUnboundedRepeat(t.Sub[0]) {
+				return true
+			}
+		}
+
+		for _, sub := range t.Sub {
+			if nested(sub) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if nested(tree) {
+		return fmt.Errorf("%w: nested quantifiers", ErrUnsafePattern)
+	}
+
+	prog, err := syntax.Compile(tree.Simplify())
+	if err != nil {
+		return err
+	}
+
+	if len(prog.Inst) > MaxPatternInsts {
+		return fmt.Errorf("%w: compiles to %d instructions", ErrUnsafePattern, len(prog.Inst))
+	}
+
+	return nil
+}
+
+// Return pattern compiled, once, after checking it with CheckPattern.
+// Patterns and their errors are cached, up to PatternCacheSize of them.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	patternCache.Lock()
+	e, found := patternCache.entries[pattern]
+	patternCache.Unlock()
+	if found {
+		return e.re, e.err
+	}
+
+	e.err = CheckPattern(pattern)
+	if e.err == nil {
+		e.re, e.err = regexp.Compile(pattern)
+	}
+
+	patternCache.Lock()
+	if len(patternCache.entries) >= PatternCacheSize {
+		patternCache.entries = map[string]patternEntry{}
+	}
+
+	patternCache.entries[pattern] = e
+	patternCache.Unlock()
+
+	return e.re, e.err
+}
+
+// Requires a string to match Pattern, a regular expression that may come
+// from configuration rather than code, such as the field rules an admin
+// defines.  The pattern is checked with CheckPattern and compiled once; an
+// unsafe or invalid pattern fails every value.  Inputs longer than
+// MaxMatchInput fail, and so does a match taking longer than Timeout, by
+// default SafeMatchTimeout, or than the context of CheckCtx allows.
+type SafeMatch struct {
+	Pattern string
+	Timeout time.Duration
+}
+
+func (s SafeMatch) IsSatisfied(obj interface{}) bool {
+	return s.IsSatisfiedCtx(context.Background(), obj)
+}
+
+func (s SafeMatch) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok || len(str) > MaxMatchInput {
+		return false
+	}
+
+	re, err := CompilePattern(s.Pattern)
+	if err != nil {
+		log.FromContext(ctx).Error("<validator.SafeMatch> ", "pattern ", s.Pattern, ": ", err)
+		return false
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = SafeMatchTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// A Go regexp cannot be interrupted, but runs in linear time, so a
+	// match left behind by the timeout finishes soon after.
+	done := make(chan bool, 1)
+	go func() {
+		done <- re.MatchString(str)
+	}()
+
+	select {
+	case matched := <-done:
+		return matched
+	case <-ctx.Done():
+		log.FromContext(ctx).Error("<validator.SafeMatch> ", "pattern ", s.Pattern, ": ", ctx.Err())
+		return false
+	}
+}
+
+func (s SafeMatch) DefaultMessage() string {
+	return "Must match the required format"
+}
+
+func (s SafeMatch) Rules() Rule {
+	return Rule{Name: "match", Params: map[string]interface{}{"pattern": s.Pattern}}
+}
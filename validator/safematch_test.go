@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"golanger.com/framework/log"
+	"strings"
+	"testing"
+)
+
+func TestCheckPattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		unsafe  bool
+	}{
+		{`^[a-z0-9-]+$`, false},
+		{`^(ab|cd){2,5}x*$`, false},
+		{`^(a+)?$`, false},
+		{`^(a{1,3}){1}$`, false},
+		{`^(a+)+$`, true},
+		{`^(a*b?)*$`, true},
+		{`^(x{2,}){3}$`, true},
+		{`((ab|cd|ef){30}){30}`, true},
+		{strings.Repeat("a", MaxPatternLen+1), true},
+	}
+
+	for _, c := range cases {
+		if err := CheckPattern(c.pattern); errors.Is(err, ErrUnsafePattern) != c.unsafe {
+			t.Errorf("CheckPattern(%.20q) = %v", c.pattern, err)
+		}
+	}
+
+	if err := CheckPattern(`(`); err == nil || errors.Is(err, ErrUnsafePattern) {
+		t.Errorf("CheckPattern of invalid syntax = %v", err)
+	}
+}
+
+func TestCompilePattern(t *testing.T) {
+	a, err := CompilePattern(`^\d{4}$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, _ := CompilePattern(`^\d{4}$`); a != b {
+		t.Error("pattern compiled twice")
+	}
+
+	if re, err := CompilePattern(`^(a+)+$`); re != nil || !errors.Is(err, ErrUnsafePattern) {
+		t.Errorf("CompilePattern = %v, %v", re, err)
+	}
+}
+
+func TestSafeMatch(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := log.NewContext(context.Background(), log.New(log.NewTextHandler(&buf), log.LEVEL_ALL))
+	zip := SafeMatch{Pattern: `^\d{5}$`}
+
+	cases := []struct {
+		check SafeMatch
+		obj   interface{}
+		want  bool
+	}{
+		{zip, "12345", true},
+		{zip, "1234", false},
+		{zip, 12345, false},
+		{SafeMatch{Pattern: `^a*$`}, strings.Repeat("a", MaxMatchInput), true},
+		{SafeMatch{Pattern: `^a*$`}, strings.Repeat("a", MaxMatchInput+1), false},
+		{SafeMatch{Pattern: `^(a+)+$`}, "aaa", false},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfiedCtx(ctx, c.obj); got != c.want {
+			t.Errorf("%q on %.10v = %v", c.check.Pattern, c.obj, got)
+		}
+	}
+
+	if !strings.Contains(buf.String(), "pattern ^(a+)+$: validator: unsafe pattern: nested quantifiers") {
+		t.Errorf("logged %q", buf.String())
+	}
+
+	buf.Reset()
+	ended, cancel := context.WithCancel(ctx)
+	cancel()
+	if (SafeMatch{Pattern: `^[a-z]*[0-9]$`}).IsSatisfiedCtx(ended, strings.Repeat("a", MaxMatchInput-1)+"1") || !strings.Contains(buf.String(), "context canceled") {
+		t.Errorf("matched under an ended context, logged %q", buf.String())
+	}
+}
+
+func TestSafeMatchTag(t *testing.T) {
+	type code struct {
+		Code string `validate:"safematch=^[A-Z]{3}$"`
+		Bad  string `validate:"safematch=^(a+)+$"`
+	}
+
+	v := (&Validation{}).ValidateStruct(code{Code: "ABC", Bad: "a"})
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["Bad"] == nil || errs["Bad"].Message != "Must match the required format" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if attrs := HTML5Attrs(SafeMatch{Pattern: `^[A-Z]{3}$`}, SafeMatch{Pattern: `^(a+)+$`}); attrs["pattern"] != `^[A-Z]{3}$` {
+		t.Errorf("attrs %v", attrs)
+	}
+
+	if attrs := HTML5Attrs(SafeMatch{Pattern: `^(a+)+$`}); len(attrs) != 0 {
+		t.Errorf("attrs of an unsafe pattern %v", attrs)
+	}
+
+	if rule := DescribeRule(SafeMatch{Pattern: "^x$"}); rule.Name != "match" || rule.Params["pattern"] != "^x$" {
+		t.Errorf("rule %+v", rule)
+	}
+}
@@ -0,0 +1,71 @@
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// The tags of sanitized HTML, which the policy writes in one form only.
+var outputTag = regexp.MustCompile(`<(/?)([a-z][a-z0-9:-]*)((?: [a-z:-]+="[^"<>]*")*)>`)
+
+var outputAttr = regexp.MustCompile(` ([a-z:-]+)="([^"]*)"`)
+
+func FuzzHTMLPolicyClean(f *testing.F) {
+	for _, s := range []string{
+		"",
+		"plain & <simple> text",
+		`<p onclick="x()">hi <b>there</p>`,
+		`<a href="javascript:alert(1)">x</a><a href=" jav&#x09;ascript:1">y</a>`,
+		`<a href='/ok' title=t>ok</a>`,
+		"<script>alert(1)</script><SCRIPT>x</SCRIPT >",
+		"<style>p{}</style><!-- c --><!doctype html><?xml?>",
+		`<img src=x onerror=alert(1)>`,
+		"<svg><script>1</script></svg>",
+		"1 < 2 > 0 <",
+		"<a href=\"x",
+		"<p><ul><li>a</ul>",
+	} {
+		f.Add(s)
+	}
+
+	p := NewHTMLPolicy()
+	f.Fuzz(func(t *testing.T, s string) {
+		clean, _ := p.Clean(s)
+		if again, removed := p.Clean(clean); again != clean || removed {
+			t.Fatalf("Clean(%q) = %q, not stable: %q, removed %v", s, clean, again, removed)
+		}
+
+		tags := outputTag.FindAllStringSubmatch(clean, -1)
+		if len(tags) != strings.Count(clean, "<") || strings.Count(clean, ">") != len(tags) {
+			t.Fatalf("Clean(%q) = %q has a stray < or >", s, clean)
+		}
+
+		depth := 0
+		for _, tag := range tags {
+			if !p.tags[tag[2]] {
+				t.Fatalf("Clean(%q) = %q keeps <%s>", s, clean, tag[2])
+			}
+
+			if tag[1] == "/" {
+				depth--
+			} else if !voidElements[tag[2]] {
+				depth++
+			}
+
+			for _, attr := range outputAttr.FindAllStringSubmatch(tag[3], -1) {
+				if !contains(HTMLAttributes[tag[2]], attr[1]) {
+					t.Fatalf("Clean(%q) = %q keeps %s on <%s>", s, clean, attr[1], tag[2])
+				}
+
+				if (attr[1] == "href" || attr[1] == "src") && !safeURL(attr[2]) {
+					t.Fatalf("Clean(%q) = %q keeps the URL %q", s, clean, attr[2])
+				}
+			}
+		}
+
+		if depth != 0 {
+			t.Fatalf("Clean(%q) = %q leaves tags open", s, clean)
+		}
+	})
+}
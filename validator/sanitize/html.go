@@ -0,0 +1,347 @@
+package sanitize
+
+import (
+	"html"
+	"strings"
+)
+
+// The tags kept by an HTMLPolicy built without any: basic formatting,
+// lists, quotes, code and links.
+var DefaultHTMLTags = []string{
+	"a", "b", "blockquote", "br", "code", "em", "i", "li", "ol", "p", "pre", "strong", "u", "ul",
+}
+
+// The attributes kept on the tags that have any; every other attribute,
+// including style and the on* event handlers, is dropped.
+var HTMLAttributes = map[string][]string{
+	"a":    {"href", "title"},
+	"img":  {"src", "alt", "title", "width", "height"},
+	"abbr": {"title"},
+	"td":   {"colspan", "rowspan"},
+	"th":   {"colspan", "rowspan"},
+	"ol":   {"start"},
+}
+
+// The schemes a link or image may use; URLs without a scheme, relative to
+// the page, are kept too.
+var URLSchemes = []string{"http", "https", "mailto"}
+
+// Elements removed with their content, whatever the policy, because their
+// content is code or not meant to be shown.
+var rawElements = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "textarea": true, "title": true, "xmp": true,
+	"noembed": true, "noframes": true, "plaintext": true, "svg": true, "math": true,
+}
+
+var voidElements = map[string]bool{
+	"br": true, "hr": true, "img": true, "wbr": true,
+}
+
+// An HTMLPolicy is an allowlist of tags.  Sanitizing with it keeps the
+// allowed tags with their safe attributes, drops every other tag but keeps
+// its text, removes scripts, styles and comments entirely, escapes the
+// text and closes the tags left open, so that the result can be output
+// unescaped.
+type HTMLPolicy struct {
+	tags map[string]bool
+}
+
+func NewHTMLPolicy(tags ...string) *HTMLPolicy {
+	if len(tags) == 0 {
+		tags = DefaultHTMLTags
+	}
+
+	p := &HTMLPolicy{tags: map[string]bool{}}
+	for _, tag := range tags {
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && !rawElements[tag] {
+			p.tags[tag] = true
+		}
+	}
+
+	return p
+}
+
+func (p *HTMLPolicy) Sanitize(s string) string {
+	clean, _ := p.Clean(s)
+	return clean
+}
+
+// Sanitize s, and report whether anything unsafe or not allowed, a tag,
+// an attribute or a comment, had to be removed; escaping text and closing
+// tags do not count.
+func (p *HTMLPolicy) Clean(s string) (string, bool) {
+	var b strings.Builder
+	removed := false
+	open := []string{}
+	text := func(t string) {
+		b.WriteString(html.EscapeString(html.UnescapeString(t)))
+	}
+
+	for s != "" {
+		i := strings.IndexByte(s, '<')
+		if i == -1 {
+			text(s)
+			break
+		}
+
+		text(s[:i])
+		s = s[i:]
+		t, rest, ok := parseHTMLTag(s)
+		if !ok {
+			// Not markup: a lone "<", as in "1 < 2".
+			text("<")
+			s = s[1:]
+			continue
+		}
+
+		s = rest
+		switch {
+		case t.markup:
+			removed = true
+		case t.truncated:
+			removed = true
+			s = ""
+		case rawElements[t.name] && !t.closing:
+			removed = true
+			s = skipRawText(s, t.name)
+		case !p.tags[t.name]:
+			removed = true
+		case t.closing:
+			for k := len(open) - 1; k >= 0; k-- {
+				if open[k] == t.name {
+					for _, name := range reversed(open[k:]) {
+						b.WriteString("</" + name + ">")
+					}
+
+					open = open[:k]
+					break
+				}
+			}
+		default:
+			b.WriteString("<" + t.name)
+			allowed := HTMLAttributes[t.name]
+			for _, a := range t.attrs {
+				if !contains(allowed, a.name) || (a.name == "href" || a.name == "src") && !safeURL(a.value) {
+					removed = true
+					continue
+				}
+
+				b.WriteString(" " + a.name + `="` + html.EscapeString(a.value) + `"`)
+			}
+
+			b.WriteString(">")
+			if !voidElements[t.name] {
+				open = append(open, t.name)
+			}
+		}
+	}
+
+	for _, name := range reversed(open) {
+		b.WriteString("</" + name + ">")
+	}
+
+	return b.String(), removed
+}
+
+func reversed(names []string) []string {
+	r := make([]string, len(names))
+	for i, name := range names {
+		r[len(names)-1-i] = name
+	}
+
+	return r
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Report whether a URL, once its entities are decoded, is relative or uses
+// one of URLSchemes, rather than javascript: and the like.
+func safeURL(u string) bool {
+	u = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+
+		return r
+	}, u)
+
+	end := strings.IndexAny(u, "/?#")
+	if end == -1 {
+		end = len(u)
+	}
+
+	colon := strings.IndexByte(u[:end], ':')
+	if colon == -1 {
+		return true
+	}
+
+	for _, scheme := range URLSchemes {
+		if strings.EqualFold(u[:colon], scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type htmlAttr struct {
+	name, value string
+}
+
+type htmlTag struct {
+	name      string
+	closing   bool
+	markup    bool // a comment, doctype or processing instruction
+	truncated bool // a tag the input ends inside of
+	attrs     []htmlAttr
+}
+
+func isTagNameChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == ':'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// Parse the tag s starts with, returning it and what follows it, or false
+// if s does not start with markup.
+func parseHTMLTag(s string) (htmlTag, string, bool) {
+	var t htmlTag
+	switch {
+	case strings.HasPrefix(s, "<!--"):
+		t.markup = true
+		if end := strings.Index(s[4:], "-->"); end != -1 {
+			return t, s[4+end+3:], true
+		}
+
+		return t, "", true
+	case strings.HasPrefix(s, "<!") || strings.HasPrefix(s, "<?"):
+		t.markup = true
+		if end := strings.IndexByte(s, '>'); end != -1 {
+			return t, s[end+1:], true
+		}
+
+		return t, "", true
+	}
+
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		t.closing = true
+		i++
+	}
+
+	start := i
+	if i >= len(s) || !('a' <= s[i] && s[i] <= 'z' || 'A' <= s[i] && s[i] <= 'Z') {
+		return t, s, false
+	}
+
+	for i < len(s) && isTagNameChar(s[i]) {
+		i++
+	}
+
+	t.name = strings.ToLower(s[start:i])
+	for {
+		for i < len(s) && (isSpace(s[i]) || s[i] == '/') {
+			i++
+		}
+
+		if i >= len(s) {
+			t.truncated = true
+			return t, "", true
+		}
+
+		if s[i] == '>' {
+			return t, s[i+1:], true
+		}
+
+		start = i
+		for i < len(s) && !isSpace(s[i]) && s[i] != '=' && s[i] != '>' && s[i] != '/' {
+			i++
+		}
+
+		a := htmlAttr{name: strings.ToLower(s[start:i])}
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+
+		if i < len(s) && s[i] == '=' {
+			i++
+			for i < len(s) && isSpace(s[i]) {
+				i++
+			}
+
+			if i < len(s) && (s[i] == '"' || s[i] == '\'') {
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end == -1 {
+					t.truncated = true
+					return t, "", true
+				}
+
+				a.value = s[i+1 : i+1+end]
+				i += end + 2
+			} else {
+				start = i
+				for i < len(s) && !isSpace(s[i]) && s[i] != '>' {
+					i++
+				}
+
+				a.value = s[start:i]
+			}
+
+			a.value = html.UnescapeString(a.value)
+		}
+
+		t.attrs = append(t.attrs, a)
+	}
+}
+
+// Skip the content of a raw text element up to and including its end tag.
+func skipRawText(s, name string) string {
+	lower := asciiLower(s)
+	for from := 0; ; {
+		i := strings.Index(lower[from:], "</"+name)
+		if i == -1 {
+			return ""
+		}
+
+		i += from + 2 + len(name)
+		if i == len(s) || !isTagNameChar(s[i]) {
+			if end := strings.IndexByte(s[i:], '>'); end != -1 {
+				return s[i+end+1:]
+			}
+
+			return ""
+		}
+
+		from = i
+	}
+}
+
+// Return s with its ASCII letters lowercased, byte for byte, unlike
+// strings.ToLower, which replaces invalid UTF-8 and so moves the offsets.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+
+	return string(b)
+}
+
+// Return a Sanitizer keeping only the given tags, DefaultHTMLTags if
+// none; see HTMLPolicy.
+func HTML(tags ...string) Sanitizer {
+	return NewHTMLPolicy(tags...).Sanitize
+}
@@ -0,0 +1,46 @@
+package sanitize
+
+import "testing"
+
+func TestHTMLPolicyClean(t *testing.T) {
+	cases := []struct {
+		in, out string
+		removed bool
+	}{
+		{"plain & simple", "plain &amp; simple", false},
+		{"<b>bold</b> <em>em</em>", "<b>bold</b> <em>em</em>", false},
+		{"1 < 2", "1 &lt; 2", false},
+		{"<p>open", "<p>open</p>", false},
+		{"<ul><li>a</ul>", "<ul><li>a</li></ul>", false},
+		{`<p onclick="x()" style="color:red">hi</p>`, "<p>hi</p>", true},
+		{`<a href="https://example.com/" title="t">x</a>`, `<a href="https://example.com/" title="t">x</a>`, false},
+		{`<a href="javascript:alert(1)">x</a>`, "<a>x</a>", true},
+		{`<a href="jav&#x09;ascript:alert(1)">x</a>`, "<a>x</a>", true},
+		{`<a href=" JAVASCRIPT:alert(1)">x</a>`, "<a>x</a>", true},
+		{`<a href="/relative?q=1">x</a>`, `<a href="/relative?q=1">x</a>`, false},
+		{"<script>alert(1)</script>ok", "ok", true},
+		{"<SCRIPT>alert(1)</SCRIPT >ok", "ok", true},
+		{"<script>a</scripts>b</script>ok", "ok", true},
+		{"<svg><script>1</script></svg>after", "after", true},
+		{"<!-- hidden -->shown", "shown", true},
+		{"<div>kept text</div>", "kept text", true},
+		{`<img src=x onerror=alert(1)>`, "", true},
+		{`<a href="x`, "", true},
+		{"<sCript>\xba</SCRIPT", "", true},
+	}
+
+	p := NewHTMLPolicy()
+	for _, c := range cases {
+		out, removed := p.Clean(c.in)
+		if out != c.out || removed != c.removed {
+			t.Errorf("Clean(%q) = %q, %v, want %q, %v", c.in, out, removed, c.out, c.removed)
+		}
+	}
+}
+
+func TestHTMLPolicyTags(t *testing.T) {
+	p := NewHTMLPolicy("img", "script", " B ")
+	if out := p.Sanitize(`<img src="/a.png" alt="a"><b>b</b><script>x</script>`); out != `<img src="/a.png" alt="a"><b>b</b>` {
+		t.Errorf("Sanitize = %q", out)
+	}
+}
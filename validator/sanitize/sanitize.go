@@ -0,0 +1,184 @@
+package sanitize
+
+import (
+	"golanger.com/framework/log"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// A Sanitizer normalizes a string before it is validated.
+type Sanitizer func(string) string
+
+func Trim(s string) string {
+	return strings.TrimSpace(s)
+}
+
+func Lowercase(s string) string {
+	return strings.ToLower(s)
+}
+
+func Uppercase(s string) string {
+	return strings.ToUpper(s)
+}
+
+var tagPattern = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+
+// Remove HTML tags and comments, leaving their text.  It does not make the
+// result safe to output unescaped.
+func StripTags(s string) string {
+	return tagPattern.ReplaceAllString(s, "")
+}
+
+// Trim s and collapse every run of white space inside it to one space.
+func NormalizeWhitespace(s string) string {
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+// Return a Sanitizer that keeps at most n characters.
+func Truncate(n int) Sanitizer {
+	return func(s string) string {
+		runes := []rune(s)
+		if len(runes) <= n {
+			return s
+		}
+
+		return string(runes[:n])
+	}
+}
+
+// Return a Sanitizer applying each of sanitizers in order.
+func Chain(sanitizers ...Sanitizer) Sanitizer {
+	return func(s string) string {
+		return Apply(s, sanitizers...)
+	}
+}
+
+func Apply(s string, sanitizers ...Sanitizer) string {
+	for _, sanitizer := range sanitizers {
+		s = sanitizer(s)
+	}
+
+	return s
+}
+
+var registry = struct {
+	sync.RWMutex
+	factories map[string]func(param string) Sanitizer
+}{
+	factories: map[string]func(param string) Sanitizer{
+		"trim":       func(string) Sanitizer { return Trim },
+		"lower":      func(string) Sanitizer { return Lowercase },
+		"upper":      func(string) Sanitizer { return Uppercase },
+		"striptags":  func(string) Sanitizer { return StripTags },
+		"whitespace": func(string) Sanitizer { return NormalizeWhitespace },
+		"html": func(param string) Sanitizer {
+			return HTML(strings.Fields(param)...)
+		},
+		"truncate": func(param string) Sanitizer {
+			n, err := strconv.Atoi(param)
+			if err != nil || n < 0 {
+				return nil
+			}
+
+			return Truncate(n)
+		},
+	},
+}
+
+// Register a named sanitizer for use in `sanitize` struct tags, replacing
+// any of the same name.  The factory is given the text after "=" in the
+// tag and returns nil if it is invalid.
+func Register(name string, factory func(param string) Sanitizer) {
+	registry.Lock()
+	registry.factories[name] = factory
+	registry.Unlock()
+}
+
+// Build the sanitizers of a tag such as "trim,lower,truncate=40".
+func parseTag(tag string) []Sanitizer {
+	sanitizers := []Sanitizer{}
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i != -1 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		registry.RLock()
+		factory, ok := registry.factories[name]
+		registry.RUnlock()
+
+		var sanitizer Sanitizer
+		if ok {
+			sanitizer = factory(param)
+		}
+
+		if sanitizer == nil {
+			log.Error("<sanitize.Struct> ", "invalid sanitize tag:", rule)
+			continue
+		}
+
+		sanitizers = append(sanitizers, sanitizer)
+	}
+
+	return sanitizers
+}
+
+// Sanitize the string fields of the struct pointed to by ptr in place by
+// their `sanitize` tags, e.g. `sanitize:"trim,lower"`.  The tags are trim,
+// lower, upper, striptags, whitespace (NormalizeWhitespace), truncate=N,
+// html (HTML) or html=b i a with the tags to keep, and the registered
+// names.  Tagged []string and *string fields are sanitized too, and
+// nested structs are walked.  Run it before validating, as
+// binder.Bind does.
+func Struct(ptr interface{}) {
+	rv := reflect.ValueOf(ptr)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return
+	}
+
+	sanitizeValue(rv.Elem(), nil, map[uintptr]bool{})
+}
+
+func sanitizeValue(rv reflect.Value, sanitizers []Sanitizer, visited map[uintptr]bool) {
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() || visited[rv.Pointer()] {
+			return
+		}
+
+		visited[rv.Pointer()] = true
+		sanitizeValue(rv.Elem(), sanitizers, visited)
+	case reflect.String:
+		if len(sanitizers) > 0 && rv.CanSet() {
+			rv.SetString(Apply(rv.String(), sanitizers...))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			sanitizeValue(rv.Index(i), sanitizers, visited)
+		}
+	case reflect.Struct:
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			sf := rt.Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+
+			tag := sf.Tag.Get("sanitize")
+			if tag == "-" {
+				continue
+			}
+
+			sanitizeValue(rv.Field(i), parseTag(tag), visited)
+		}
+	}
+}
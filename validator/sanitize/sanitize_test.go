@@ -0,0 +1,110 @@
+package sanitize
+
+import (
+	"golanger.com/framework/log"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+func TestSanitizers(t *testing.T) {
+	cases := []struct {
+		sanitizer Sanitizer
+		in, out   string
+	}{
+		{Trim, " \tJo \n", "Jo"},
+		{Lowercase, "ÀB", "àb"},
+		{Uppercase, "ação", "AÇÃO"},
+		{StripTags, `<p class="x">Hi <!-- secret <b>--> <i>there</i></p>`, "Hi  there"},
+		{NormalizeWhitespace, "  a \t b\n\nc ", "a b c"},
+		{Truncate(3), "héllo", "hél"},
+		{Truncate(10), "short", "short"},
+		{Chain(Trim, Lowercase, Truncate(4)), "  HELLO ", "hell"},
+		{Chain(), "as is", "as is"},
+	}
+
+	for _, c := range cases {
+		if got := c.sanitizer(c.in); got != c.out {
+			t.Errorf("sanitized %q to %q, want %q", c.in, got, c.out)
+		}
+	}
+}
+
+type profile struct {
+	Name     string   `sanitize:"whitespace,truncate=8"`
+	Email    string   `sanitize:"trim,lower"`
+	Tags     []string `sanitize:"trim,upper"`
+	Nick     *string  `sanitize:"trim"`
+	Raw      string   `sanitize:"-"`
+	Untagged string
+	Bad      string `sanitize:"nosuch,truncate=x,trim"`
+	Address  struct {
+		City string `sanitize:"trim"`
+	}
+	Self *profile
+	note string
+}
+
+func TestStruct(t *testing.T) {
+	nick := "  jo "
+	p := &profile{
+		Name:     "  Ann   Marie Smith ",
+		Email:    " Ann@Example.COM ",
+		Tags:     []string{" a ", "b "},
+		Nick:     &nick,
+		Raw:      " raw ",
+		Untagged: " as is ",
+		Bad:      " x ",
+		note:     " private ",
+	}
+	p.Address.City = " Lisbon "
+	p.Self = p
+
+	Struct(p)
+	want := profile{
+		Name:     "Ann Mari",
+		Email:    "ann@example.com",
+		Tags:     []string{"A", "B"},
+		Raw:      " raw ",
+		Untagged: " as is ",
+		Bad:      "x",
+		note:     " private ",
+	}
+	want.Address.City = "Lisbon"
+
+	got := *p
+	got.Nick, got.Self = nil, nil
+	if !reflect.DeepEqual(got, want) || nick != "jo" {
+		t.Errorf("sanitized to %+v, nick %q", got, nick)
+	}
+
+	// Only pointers are sanitized in place.
+	Struct(*p)
+	Struct((*profile)(nil))
+}
+
+func TestRegister(t *testing.T) {
+	Register("test_digits", func(param string) Sanitizer {
+		return func(s string) string {
+			return strings.Map(func(r rune) rune {
+				if strings.ContainsRune(param+"0123456789", r) {
+					return r
+				}
+
+				return -1
+			}, s)
+		}
+	})
+
+	s := struct {
+		Phone string `sanitize:"test_digits=+"`
+	}{"+1 (415) 555-0100"}
+	Struct(&s)
+	if s.Phone != "+14155550100" {
+		t.Errorf("sanitized to %q", s.Phone)
+	}
+}
@@ -0,0 +1,255 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// A JSON Schema, as far as the `validate` tags can describe one.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+// An OpenAPI parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// Return the JSON Schema of a struct (or pointer to struct) reflecting the
+// required, min, max, len, email, url, uuid and similar rules of its
+// `validate` tags.  Properties are named by their `json` tag, as the body
+// encoding/json decodes, and rules of scenarios are left out.
+func SchemaFor(obj interface{}) *Schema {
+	t, ok := obj.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(obj)
+	}
+
+	if t == nil {
+		return &Schema{}
+	}
+
+	return typeSchema(t, map[reflect.Type]bool{})
+}
+
+// Encode s as indented JSON.
+func (s *Schema) JSON() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Return the OpenAPI requestBody of a JSON body with schema s.
+func (s *Schema) RequestBody() map[string]interface{} {
+	return map[string]interface{}{
+		"required": true,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": s},
+		},
+	}
+}
+
+// Return the properties of s as OpenAPI parameters in in, such as "query"
+// or "path", sorted by name.
+func (s *Schema) Parameters(in string) []Parameter {
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	params := []Parameter{}
+	for _, name := range names {
+		params = append(params, Parameter{
+			Name:     name,
+			In:       in,
+			Required: required[name] || in == "path",
+			Schema:   s.Properties[name],
+		})
+	}
+
+	return params
+}
+
+func typeSchema(t reflect.Type, visited map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: typeSchema(t.Elem(), visited)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), visited)}
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		if visited[t] {
+			return s
+		}
+
+		visited[t] = true
+		defer delete(visited, t)
+		structSchema(t, s, visited)
+
+		return s
+	}
+
+	return &Schema{}
+}
+
+// Add the properties of the fields of struct type t to s.
+func structSchema(t reflect.Type, s *Schema, visited map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := fieldRules(t, sf)
+		if sf.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		name := sf.Name
+		if jsonName := strings.Split(sf.Tag.Get("json"), ",")[0]; jsonName == "-" {
+			continue
+		} else if jsonName != "" {
+			name = jsonName
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if sf.Anonymous && ft.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			structSchema(ft, s, visited)
+			continue
+		}
+
+		rules, elemRules := tag, ""
+		if d := diveIndex(tag); d >= 0 {
+			rules, elemRules = tag[:d], strings.TrimPrefix(tag[d+len("dive"):], ",")
+		}
+
+		prop := typeSchema(ft, visited)
+		if constrain(prop, (&Validation{}).parseTag(sf.Type, rules)) {
+			s.Required = append(s.Required, name)
+		}
+
+		if elemRules != "" {
+			if elem := prop.Items; elem != nil {
+				constrain(elem, (&Validation{}).parseTag(ft.Elem(), elemRules))
+			} else if elem := prop.AdditionalProperties; elem != nil {
+				constrain(elem, (&Validation{}).parseTag(ft.Elem(), elemRules))
+			}
+		}
+
+		s.Properties[name] = prop
+	}
+}
+
+// Add the constraints of checks to s and report whether one of them is
+// Required.
+func constrain(s *Schema, checks []Validator) bool {
+	required := false
+	intp := func(n int) *int { return &n }
+	floatp := func(f float64) *float64 { return &f }
+	for _, chk := range checks {
+		switch c := unwrap(chk).(type) {
+		case Required:
+			required = true
+			if s.Type == "string" && s.MinLength == nil {
+				s.MinLength = intp(1)
+			}
+		case MinSize:
+			if s.Type == "array" {
+				s.MinItems = intp(c.Min)
+			} else {
+				s.MinLength = intp(c.Min)
+			}
+		case MaxSize:
+			if s.Type == "array" {
+				s.MaxItems = intp(c.Max)
+			} else {
+				s.MaxLength = intp(c.Max)
+			}
+		case Length:
+			if s.Type == "array" {
+				s.MinItems, s.MaxItems = intp(c.N), intp(c.N)
+			} else {
+				s.MinLength, s.MaxLength = intp(c.N), intp(c.N)
+			}
+		case Min:
+			s.Minimum = floatp(float64(c.Min))
+		case Max:
+			s.Maximum = floatp(float64(c.Max))
+		case Range:
+			s.Minimum, s.Maximum = floatp(float64(c.Min.Min)), floatp(float64(c.Max.Max))
+		case MinFloat:
+			s.Minimum = floatp(c.Min)
+		case MaxFloat:
+			s.Maximum = floatp(c.Max)
+		case GreaterThan:
+			if f, ok := toFloat64(c.Value); ok && c.Field == "" {
+				s.ExclusiveMinimum = floatp(f)
+			}
+		case LessThan:
+			if f, ok := toFloat64(c.Value); ok && c.Field == "" {
+				s.ExclusiveMaximum = floatp(f)
+			}
+		case Email:
+			s.Format = "email"
+		case URL:
+			s.Format = "uri"
+		case IPv4:
+			s.Format = "ipv4"
+		case IPv6:
+			s.Format = "ipv6"
+		case UUID:
+			s.Format = "uuid"
+		case Match:
+			if c.Regexp != nil {
+				s.Pattern = c.Regexp.String()
+			}
+		}
+	}
+
+	return required
+}
@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type Timestamps struct {
+	Created time.Time `json:"created"`
+}
+
+type invoice struct {
+	Timestamps
+	ID       string            `json:"id" validate:"required,uuid"`
+	Email    string            `json:"email" validate:"email,max=255"`
+	Qty      int               `json:"qty" validate:"min=1,max=99"`
+	Price    float64           `json:"price" validate:"min=0.5"`
+	Tags     []string          `json:"tags" validate:"max=5,dive,min=2"`
+	Notes    map[string]string `json:"notes" validate:"dive,max=10"`
+	Code     string            `validate:"len=4,match=^[A-Z]+$"`
+	Blob     []byte            `json:"blob"`
+	Admin    string            `json:"admin" validate:"required|scenario=admin"`
+	Internal string            `json:"-"`
+	Skipped  string            `validate:"-"`
+	Parent   *invoice          `json:"parent"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	s := SchemaFor(&invoice{})
+	if s.Type != "object" || !reflect.DeepEqual(s.Required, []string{"id"}) {
+		t.Errorf("schema %+v", s)
+	}
+
+	names := []string{}
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+
+	if len(names) != 11 || s.Properties["Internal"] != nil || s.Properties["Skipped"] != nil {
+		t.Errorf("properties %v", names)
+	}
+
+	encode := func(name string) string {
+		b, _ := json.Marshal(s.Properties[name])
+		return string(b)
+	}
+
+	cases := map[string]string{
+		"created": `{"type":"string","format":"date-time"}`,
+		"id":      `{"type":"string","format":"uuid","minLength":1}`,
+		"qty":     `{"type":"integer","minimum":1,"maximum":99}`,
+		"price":   `{"type":"number","minimum":0.5}`,
+		"tags":    `{"type":"array","maxItems":5,"items":{"type":"string","minLength":2}}`,
+		"notes":   `{"type":"object","additionalProperties":{"type":"string","maxLength":10}}`,
+		"Code":    `{"type":"string","pattern":"^[A-Z]+$","minLength":4,"maxLength":4}`,
+		"blob":    `{"type":"string","format":"byte"}`,
+		"admin":   `{"type":"string"}`,
+		"parent":  `{"type":"object"}`,
+	}
+
+	for name, want := range cases {
+		if got := encode(name); got != want {
+			t.Errorf("%s: %s, want %s", name, got, want)
+		}
+	}
+
+	if s.Properties["email"].Format != "email" || *s.Properties["email"].MaxLength != 255 {
+		t.Errorf("email: %s", encode("email"))
+	}
+
+	if got := SchemaFor(nil); !reflect.DeepEqual(got, &Schema{}) {
+		t.Errorf("SchemaFor(nil) = %+v", got)
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	type query struct {
+		Page int    `json:"page" validate:"min=1"`
+		Q    string `json:"q" validate:"required"`
+	}
+
+	s := SchemaFor(reflect.TypeOf(query{}))
+	params := s.Parameters("query")
+	if len(params) != 2 || params[0].Name != "page" || params[0].Required || params[1].Name != "q" || !params[1].Required || params[1].In != "query" {
+		t.Errorf("parameters %+v", params)
+	}
+
+	if params := s.Parameters("path"); !params[0].Required {
+		t.Errorf("path parameters %+v", params)
+	}
+
+	b, _ := json.Marshal(s.RequestBody())
+	if string(b) != `{"content":{"application/json":{"schema":{"type":"object","properties":{"page":{"type":"integer","minimum":1},"q":{"type":"string","minLength":1}},"required":["q"]}}},"required":true}` {
+		t.Errorf("request body %s", b)
+	}
+
+	if b, err := s.JSON(); err != nil || b[0] != '{' || b[1] != '\n' {
+		t.Errorf("JSON = %s, %v", b, err)
+	}
+}
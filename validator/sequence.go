@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A SequenceValidator checks that successive values strictly increase (or
+// strictly decrease).  Unlike the other validators it is stateful: each
+// call to Next compares against the previous value and then remembers the
+// new one, whether or not the check passed.  The first value always
+// passes.  Next may be called from several goroutines, but the parent
+// Validation it records errors on is not safe for concurrent use.
+type SequenceValidator struct {
+	mutex      sync.Mutex
+	validation *Validation
+	increasing bool
+	started    bool
+	prev       int
+}
+
+func (v *Validation) NewSequence(increasing bool) *SequenceValidator {
+	return &SequenceValidator{
+		validation: v,
+		increasing: increasing,
+	}
+}
+
+func (s *SequenceValidator) Next(n int) *ValidationResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	chk := Monotonic{s.increasing, s.started, s.prev}
+	s.started = true
+	s.prev = n
+
+	return s.validation.apply(chk, n)
+}
+
+// Requires an integer to be strictly after Prev in the direction of the
+// sequence.  It is always satisfied when Started is false.
+type Monotonic struct {
+	Increasing bool
+	Started    bool
+	Prev       int
+}
+
+func (m Monotonic) IsSatisfied(obj interface{}) bool {
+	n, ok := obj.(int)
+	if !ok {
+		return false
+	}
+
+	if !m.Started {
+		return true
+	}
+
+	if m.Increasing {
+		return n > m.Prev
+	}
+
+	return n < m.Prev
+}
+
+func (m Monotonic) DefaultMessage() string {
+	if m.Increasing {
+		return fmt.Sprint("Must be greater than ", m.Prev)
+	}
+
+	return fmt.Sprint("Must be less than ", m.Prev)
+}
@@ -0,0 +1,42 @@
+package validator
+
+import (
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestSequence(t *testing.T) {
+	v := &Validation{}
+	up := v.NewSequence(true)
+	for i, n := range []int{5, 6, 6, 4, 10} {
+		up.Next(n).Key(string(rune('a' + i)))
+	}
+
+	down := v.NewSequence(false)
+	down.Next(3).Key("x")
+	if r := down.Next(3).Key("y"); r.Ok || r.Error.Message != "Must be less than 3" {
+		t.Errorf("Next = %v", r.Error)
+	}
+
+	errs := v.ErrorMap()
+	if len(errs) != 3 || errs["c"].Message != "Must be greater than 6" || errs["d"].Message != "Must be greater than 6" {
+		t.Errorf("errors %v", errs)
+	}
+
+	// 10 follows 4, which failed but is remembered.
+	if errs["e"] != nil {
+		t.Errorf("10 after 4: %v", errs["e"])
+	}
+}
+
+func TestSequenceCallerKeys(t *testing.T) {
+	v := (&Validation{}).CallerKeys(true)
+	seq := v.NewSequence(true)
+	seq.Next(2)
+	_, _, line, _ := runtime.Caller(0)
+	r := seq.Next(1)
+	if want := "golanger.com/framework/validator.TestSequenceCallerKeys#" + strconv.Itoa(line+1); r.MessageKey() != want {
+		t.Errorf("keyed %q, want %q", r.MessageKey(), want)
+	}
+}
@@ -0,0 +1,527 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate the exported fields of a struct (or pointer to struct) by their
+// `validate` tags, e.g. `validate:"required,min=3,max=40,email"`.  Each
+// field is checked in tag order, stopping at its first failure, and the
+// error is keyed by the field name.
+//
+// min, max and len compare the length of strings, slices, maps and arrays
+// and the value of integers and floats.
+//
+// Nested structs, and non-nil pointers to them, are validated too, with
+// their errors keyed by the dotted path, e.g. "Address.City".  The fields
+// of embedded structs are keyed as if they were declared in the outer
+// struct.  In a slice, array or map field, "dive" applies the rules after
+// it to every element, or validates every struct element, keyed by index:
+// `validate:"required,dive"` gives "Items[2].SKU" and
+// `validate:"dive,email"` gives "Emails[1]".  A tag of "-" skips a field.
+//
+// eqfield=Other, nefield=Other, gtfield=Other and ltfield=Other compare a
+// field with another field of the same struct, e.g.
+// `validate:"eqfield=Password"` or `validate:"gtfield=Start"`.  gt and lt
+// are the exclusive bounds of min and max, taking an RFC 3339 time or a
+// date for a time.Time field: `validate:"gt=0"`.  emailmx is email
+// with VerifyMX, json is ValidJSON, oneof=red green blue lists the allowed
+// values, safematch=pattern is SafeMatch, and safehtml or safehtml=b i a
+// is SafeHTML.  match=pattern and safematch=pattern take the rest of the
+// tag as the pattern, commas included, so they come last:
+// `validate:"required,match=^[a-z]{2,8}$"`.  numeric, integer, decimal=2
+// and numrange=0;99.5 check numbers in strings, in the locale of the
+// Validation.  honeypot is Honeypot, mintime=3s is MinSubmitTime and
+// token=reset is Token for the purpose "reset".  money=EUR is Money in
+// euros, and money=EUR;0.01;1000 bounds the amount too.  Validators
+// added with Register are used by name as well; Registered lists them all.
+//
+// Types whose fields cannot be tagged may register their rules instead,
+// see RegisterRules.
+//
+// A `label` tag, e.g. `label:"Date of birth"`, sets the label of the
+// field for the {label} placeholder of messages; see SetLabel.
+// A `sensitive:"true"` tag keeps an Audit from hashing the input of the
+// field; see NewAudit.
+//
+// A rule followed by "|scenario=" and a ";"-separated list applies only
+// while the Validation is in one of those scenarios, so one struct can be
+// validated differently on create and update:
+// `validate:"required|scenario=create,min=3"`.
+func (v *Validation) ValidateStruct(obj interface{}) *Validation {
+	rv := reflect.ValueOf(obj)
+	if !rv.IsValid() {
+		return v
+	}
+
+	t := rv.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		v.logger().Error("<Validation.ValidateStruct> ", "not a struct:", t.Kind())
+		return v
+	}
+
+	v.validateNested(rv, "", map[uintptr]bool{})
+
+	return v
+}
+
+// Validate the struct rv holds, if any, following pointers.  visited holds
+// the pointers on the current path, to stop at cycles.
+func (v *Validation) validateNested(rv reflect.Value, prefix string, visited map[uintptr]bool) {
+	ptrs := []uintptr{}
+	defer func() {
+		for _, p := range ptrs {
+			delete(visited, p)
+		}
+	}()
+
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+
+		if rv.Kind() == reflect.Ptr {
+			if visited[rv.Pointer()] {
+				return
+			}
+
+			visited[rv.Pointer()] = true
+			ptrs = append(ptrs, rv.Pointer())
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Struct {
+		v.validateStruct(rv, prefix, visited)
+	}
+}
+
+func (v *Validation) validateStruct(rv reflect.Value, prefix string, visited map[uintptr]bool) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField() && !v.stopped(); i++ {
+		sf := rt.Field(i)
+		tag := fieldRules(rt, sf)
+		if tag == "-" || sf.PkgPath != "" {
+			continue
+		}
+
+		f := rv.Field(i)
+		if sf.Anonymous {
+			v.validateNested(f, prefix, visited)
+		}
+
+		key := prefix + sf.Name
+		if v.audit != nil && sf.Tag.Get("sensitive") == "true" {
+			v.audit.Sensitive(key)
+		}
+
+		if label := sf.Tag.Get("label"); label != "" {
+			if _, ok := v.labels[key]; !ok {
+				v.SetLabel(key, label)
+			}
+		}
+
+		rules, elemRules, dive := tag, "", false
+		if d := diveIndex(tag); d >= 0 {
+			rules, elemRules, dive = tag[:d], strings.TrimPrefix(tag[d+len("dive"):], ","), true
+		}
+
+		if checks := resolveFieldRefs(v.parseTag(sf.Type, rules), rv); len(checks) > 0 {
+			if result := v.CheckKey(key, fieldValue(f), checks...); !result.Ok {
+				continue
+			}
+		}
+
+		if dive {
+			v.validateElements(f, key, elemRules, visited)
+		} else if !sf.Anonymous {
+			v.validateNested(f, key+".", visited)
+		}
+	}
+}
+
+// Return the position of the "dive" rule in a tag, or -1.
+func diveIndex(tag string) int {
+	pos := 0
+	for _, rule := range splitRules(tag) {
+		if strings.TrimSpace(rule) == "dive" {
+			return pos + strings.Index(rule, "dive")
+		}
+
+		pos += len(rule) + 1
+	}
+
+	return -1
+}
+
+// Apply the rules to, or else validate as structs, the elements of a slice,
+// array or map, keyed key[index].
+func (v *Validation) validateElements(f reflect.Value, key, rules string, visited map[uintptr]bool) {
+	for f.Kind() == reflect.Ptr && !f.IsNil() {
+		f = f.Elem()
+	}
+
+	elems := map[string]reflect.Value{}
+	indexes := []string{}
+	switch f.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < f.Len(); i++ {
+			index := strconv.Itoa(i)
+			indexes = append(indexes, index)
+			elems[index] = f.Index(i)
+		}
+	case reflect.Map:
+		for _, k := range f.MapKeys() {
+			index := fmt.Sprint(k.Interface())
+			indexes = append(indexes, index)
+			elems[index] = f.MapIndex(k)
+		}
+
+		sort.Strings(indexes)
+	default:
+		v.logger().Error("<Validation.ValidateStruct> ", "dive on a field that is not a slice, array or map:", key)
+		return
+	}
+
+	for _, index := range indexes {
+		if v.stopped() {
+			return
+		}
+
+		elem := elems[index]
+		elemKey := key + "[" + index + "]"
+		if rules != "" {
+			if checks := v.parseTag(elem.Type(), rules); len(checks) > 0 {
+				if result := v.CheckKey(elemKey, fieldValue(elem), checks...); !result.Ok {
+					continue
+				}
+			}
+		}
+
+		v.validateNested(elem, elemKey+".", visited)
+	}
+}
+
+// Split a tag into its rules at the commas, except that a match or
+// safematch rule takes the rest of the tag, commas included, as its
+// pattern.
+func splitRules(tag string) []string {
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if strings.HasPrefix(rule, "match=") || strings.HasPrefix(rule, "safematch=") {
+			return append(rules[:i], strings.Join(rules[i:], ","))
+		}
+	}
+
+	return rules
+}
+
+// Build the validators named by a `validate` tag for a field of type t.
+// A rule ending in "|scenario=a;b" is kept only in the scenario of v.  An
+// invalid rule is logged to the logger of the context of v and skipped.
+func (v *Validation) parseTag(t reflect.Type, tag string) []Validator {
+	checks := []Validator{}
+	for _, rule := range splitRules(tag) {
+		rule = strings.TrimSpace(rule)
+		if i := strings.LastIndex(rule, "|scenario="); i != -1 {
+			if !inScenario(rule[i+len("|scenario="):], v.scenario) {
+				continue
+			}
+
+			rule = rule[:i]
+		}
+
+		if rule == "" {
+			continue
+		}
+
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i != -1 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		if chk := tagValidator(name, param, t); chk != nil {
+			checks = append(checks, chk)
+		} else {
+			v.logger().Error("<Validation.ValidateStruct> ", "invalid validate tag:", rule)
+		}
+	}
+
+	return checks
+}
+
+// Report whether scenario is one of the ";"-separated scenarios.
+func inScenario(scenarios, scenario string) bool {
+	if scenario == "" {
+		return false
+	}
+
+	for _, s := range strings.Split(scenarios, ";") {
+		if strings.TrimSpace(s) == scenario {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tagValidator(name, param string, t reflect.Type) Validator {
+	if chk := Named(name, param); chk != nil {
+		return chk
+	}
+
+	sized := isSized(t)
+	switch name {
+	case "required":
+		return Required{}
+	case "email":
+		return NewEmail()
+	case "emailmx":
+		return Email{VerifyMX: true}
+	case "url":
+		return URL{}
+	case "ipv4":
+		return IPv4{}
+	case "ipv6":
+		return IPv6{}
+	case "uuid":
+		return UUID{}
+	case "phone":
+		return Phone{param}
+	case "creditcard":
+		return CreditCard{}
+	case "luhn":
+		return Luhn{}
+	case "eqfield", "nefield", "gtfield", "ltfield":
+		if param != "" {
+			return fieldRef{param, name}
+		}
+	case "gt", "lt":
+		return boundTag(name, param, t)
+	case "dateformat":
+		if param != "" {
+			return DateFormat{param}
+		}
+	case "age":
+		if n, err := strconv.Atoi(param); err == nil {
+			return Age{Min: n}
+		}
+	case "match":
+		if re, err := regexp.Compile(param); err == nil {
+			return Match{re}
+		}
+	case "safehtml":
+		return SafeHTML{strings.Fields(param)}
+	case "json":
+		return ValidJSON{}
+	case "honeypot":
+		return Honeypot{}
+	case "mintime":
+		if d, err := time.ParseDuration(param); err == nil {
+			return MinSubmitTime{Duration: d}
+		}
+	case "token":
+		return Token{Purpose: param}
+	case "oneof":
+		return oneOfTag(param, t)
+	case "numeric":
+		return Numeric{}
+	case "integer":
+		return Integer{}
+	case "decimal":
+		n, err := strconv.Atoi(param)
+		if param == "" || err == nil {
+			return Decimal{MaxFractionDigits: n}
+		}
+	case "money":
+		parts := strings.Split(param, ";")
+		m := Money{Currency: parts[0]}
+		if len(parts) > 1 {
+			m.Min = parts[1]
+		}
+
+		if len(parts) > 2 {
+			m.Max = parts[2]
+		}
+
+		return m
+	case "numrange":
+		if i := strings.Index(param, ";"); i != -1 {
+			return InRangeString{Min: param[:i], Max: param[i+1:]}
+		}
+	case "safematch":
+		if param != "" {
+			return SafeMatch{Pattern: param}
+		}
+	case "min", "max":
+		if k := t.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+			f, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil
+			}
+
+			if name == "min" {
+				return MinFloat{f}
+			}
+
+			return MaxFloat{f}
+		}
+
+		fallthrough
+	case "len":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return nil
+		}
+
+		switch {
+		case name == "min" && sized:
+			return MinSize{n}
+		case name == "min":
+			return Min{n}
+		case name == "max" && sized:
+			return MaxSize{n}
+		case name == "max":
+			return Max{n}
+		default:
+			return Length{n}
+		}
+	}
+
+	return nil
+}
+
+// Build oneof=red green blue: In for strings, or OneOf with the values
+// parsed as numbers for numeric fields.
+func oneOfTag(param string, t reflect.Type) Validator {
+	values := strings.Fields(param)
+	if len(values) == 0 {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return In{Values: values}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		allowed := []interface{}{}
+		for _, value := range values {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil
+			}
+
+			allowed = append(allowed, f)
+		}
+
+		return OneOf{allowed}
+	}
+
+	return nil
+}
+
+// Build gt=N or lt=N: GreaterThan or LessThan of the value of numbers, or
+// of a time in RFC 3339 or as a date for times, or the exclusive bound of
+// the length of strings, slices, maps and arrays.
+func boundTag(name, param string, t reflect.Type) Validator {
+	var bound interface{}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil
+		}
+
+		bound = n
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return nil
+		}
+
+		bound = n
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil
+		}
+
+		bound = f
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return nil
+		}
+
+		if name == "gt" {
+			return MinSize{n + 1}
+		}
+
+		return MaxSize{n - 1}
+	case reflect.Struct:
+		if t != reflect.TypeOf(time.Time{}) {
+			return nil
+		}
+
+		tm, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			if tm, err = time.Parse("2006-01-02", param); err != nil {
+				return nil
+			}
+		}
+
+		bound = tm
+	default:
+		return nil
+	}
+
+	if name == "gt" {
+		return GreaterThan{Value: bound}
+	}
+
+	return LessThan{Value: bound}
+}
+
+func isSized(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	}
+
+	return false
+}
+
+// Return the field as the value the built-in validators expect: integers
+// as int, strings as string and everything else unchanged.  An integer
+// beyond the range of int is left unchanged too, rather than wrapped, so
+// that min and max, which compare ints, fail it.
+func fieldValue(f reflect.Value) interface{} {
+	switch f.Kind() {
+	case reflect.String:
+		return f.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := f.Int(); n >= math.MinInt && n <= math.MaxInt {
+			return int(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n := f.Uint(); n <= math.MaxInt {
+			return int(n)
+		}
+	}
+
+	return f.Interface()
+}
@@ -0,0 +1,199 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"golanger.com/framework/log"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type signup struct {
+	Name    string   `validate:"required,min=3"`
+	Email   string   `validate:"required,email"`
+	Age     int      `validate:"min=18|scenario=adult"`
+	Tags    []string `validate:"dive,required"`
+	Address struct {
+		City string `validate:"required"`
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	s := signup{Name: "Jo", Email: "nope", Age: 12, Tags: []string{"a", ""}}
+	v := &Validation{}
+	v.ValidateStruct(s)
+
+	got := v.ErrorMap()
+	for _, key := range []string{"Name", "Email", "Tags[1]", "Address.City"} {
+		if got[key] == nil {
+			t.Errorf("no error for %s in %v", key, got)
+		}
+	}
+
+	if got["Age"] != nil {
+		t.Errorf("Age checked outside its scenario")
+	}
+
+	v = (&Validation{}).Scenario("adult")
+	v.ValidateStruct(s)
+	if v.ErrorMap()["Age"] == nil {
+		t.Errorf("Age not checked in its scenario")
+	}
+}
+
+func TestInvalidTagLoggedToContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(log.NewTextHandler(&buf), log.LEVEL_ALL)
+	v := (&Validation{}).SetContext(log.NewContext(context.Background(), logger))
+
+	v.ValidateStruct(struct {
+		Name string `validate:"required,nosuchrule=1"`
+	}{"Joe"})
+
+	if !strings.Contains(buf.String(), "invalid validate tag:nosuchrule=1") {
+		t.Errorf("log %q does not name the invalid rule", buf.String())
+	}
+
+	if v.HasErrors() {
+		t.Errorf("errors %v for an invalid rule", v.Errors)
+	}
+}
+
+type item struct {
+	SKU string `validate:"required"`
+}
+
+type node struct {
+	Name string `validate:"required"`
+	Next *node
+}
+
+type Audited struct {
+	By string `validate:"required"`
+}
+
+func TestValidateNested(t *testing.T) {
+	loop := &node{Name: "a"}
+	loop.Next = &node{Next: loop}
+	s := struct {
+		Audited
+		Items   []item           `validate:"required,dive"`
+		Ptrs    map[string]*item `validate:"dive"`
+		Emails  []string         `validate:"dive,email"`
+		Skipped item             `validate:"-"`
+		Nil     *item
+		List    *node
+	}{
+		Items:  []item{{"a"}, {}, {"c"}},
+		Ptrs:   map[string]*item{"x": {}, "y": nil},
+		Emails: []string{"a@example.com", "nope"},
+		List:   loop,
+	}
+
+	v := &Validation{}
+	v.ValidateStruct(&s)
+
+	keys := map[string]bool{}
+	for _, e := range v.Errors {
+		keys[e.Key] = true
+	}
+
+	want := []string{"By", "Items[1].SKU", "Ptrs[x].SKU", "Emails[1]", "List.Next.Name"}
+	for _, key := range want {
+		if !keys[key] {
+			t.Errorf("no error for %s in %v", key, v.Errors)
+		}
+	}
+
+	if len(v.Errors) != len(want) {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	v = &Validation{}
+	v.ValidateStruct(struct {
+		Items []item `validate:"required,dive"`
+	}{})
+	if v.ErrorMap()["Items"] == nil || len(v.Errors) != 1 {
+		t.Errorf("errors %v for no items", v.Errors)
+	}
+}
+
+func TestScenarios(t *testing.T) {
+	type account struct {
+		ID       int    `validate:"required|scenario=update;admin"`
+		Password string `validate:"required|scenario=create,min=8"`
+		Role     string `validate:"required|scenario=admin"`
+	}
+
+	cases := map[string][]string{
+		"":       {"Password"},
+		"create": {"Password"},
+		"update": {"ID", "Password"},
+		"admin":  {"ID", "Password", "Role"},
+	}
+
+	for scenario, want := range cases {
+		v := (&Validation{}).Scenario(scenario)
+		v.ValidateStruct(account{Password: "short"})
+		errs := v.ErrorMap()
+		if len(errs) != len(want) {
+			t.Errorf("%q: errors %v", scenario, v.Errors)
+		}
+
+		for _, key := range want {
+			if errs[key] == nil {
+				t.Errorf("%q: no error for %s", scenario, key)
+			}
+		}
+	}
+
+	v := (&Validation{}).Scenario("create")
+	if errs := v.Child().ValidateStruct(account{}).ErrorMap(); errs["Password"] == nil || errs["Password"].Message != "Required" {
+		t.Errorf("the scenario is not shared with a Child: %v", errs)
+	}
+}
+
+func TestPatternTags(t *testing.T) {
+	type code struct {
+		Short string   `validate:"required,match=^[a-z]{2,3}$"`
+		Safe  string   `validate:"safematch=^[0-9]{1,2}(,[0-9]{3})*$"`
+		Tags  []string `validate:"max=2,dive,match=^[a-z]{1,4}$"`
+	}
+
+	var buf bytes.Buffer
+	ctx := log.NewContext(context.Background(), log.New(log.NewTextHandler(&buf), log.LEVEL_ALL))
+	v := (&Validation{}).SetContext(ctx).ValidateStruct(code{Short: "abc", Safe: "12,345", Tags: []string{"go", "web"}})
+	if v.HasErrors() || buf.Len() != 0 {
+		t.Errorf("errors %v, logged %q", v.Errors, buf.String())
+	}
+
+	v = (&Validation{}).SetContext(ctx).ValidateStruct(code{Short: "abcd", Safe: "12,34", Tags: []string{"golang"}})
+	if errs := v.ErrorMap(); len(errs) != 3 || errs["Short"] == nil || errs["Safe"] == nil || errs["Tags[0]"] == nil || buf.Len() != 0 {
+		t.Errorf("errors %v, logged %q", v.Errors, buf.String())
+	}
+}
+
+func TestLargeUnsigned(t *testing.T) {
+	type counter struct {
+		Small uint8  `validate:"min=1,max=9"`
+		Big   uint64 `validate:"max=10"`
+		Bound uint64 `validate:"gt=10"`
+	}
+
+	v := (&Validation{}).ValidateStruct(counter{Small: 5, Big: 10, Bound: math.MaxUint64})
+	if v.HasErrors() {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	// Beyond the range of int the value no longer wraps negative.
+	v = (&Validation{}).ValidateStruct(counter{Small: 5, Big: math.MaxUint64, Bound: 1 << 63})
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["Big"] == nil {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	if got := fieldValue(reflect.ValueOf(uint64(1 << 63))); got != uint64(1<<63) {
+		t.Errorf("fieldValue = %#v", got)
+	}
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"golanger.com/framework/token"
+)
+
+// Requires a string to be an unexpired token of the token package made
+// for Purpose, verified with Signer, or token.Default if nil.  Its payload
+// is read with token.Verify once the check passes.
+type Token struct {
+	Purpose string
+	Signer  *token.Signer
+}
+
+func (t Token) verify(obj interface{}) error {
+	str, ok := obj.(string)
+	if !ok || str == "" {
+		return token.ErrInvalid
+	}
+
+	signer := t.Signer
+	if signer == nil {
+		signer = token.Default
+	}
+
+	return signer.Verify(t.Purpose, str, nil)
+}
+
+func (t Token) IsSatisfied(obj interface{}) bool {
+	return t.IsSatisfiedCtx(context.Background(), obj)
+}
+
+func (t Token) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	err := t.verify(obj)
+	if err == token.ErrNoKeys {
+		log.FromContext(ctx).Error("<Token.IsSatisfied> ", err)
+	}
+
+	return err == nil
+}
+
+func (t Token) DefaultMessage() string {
+	return "Invalid link"
+}
+
+// The purpose is of no use to a client.
+func (t Token) Rules() Rule {
+	return Rule{Name: "token"}
+}
+
+// Check a token made for purpose by token.Default, e.g. that of a password
+// reset link.  An expired token is reported as such, with the code
+// validation.token_expired, unless a translation of Token replaces the
+// message.
+func (v *Validation) Token(str, purpose string) *ValidationResult {
+	chk := Token{Purpose: purpose}
+	result := v.apply(chk, str)
+	if !result.Ok && result.Error.Message == chk.DefaultMessage() && chk.verify(str) == token.ErrExpired {
+		result.Message("This link has expired").Code("validation.token_expired")
+	}
+
+	return result
+}
@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"golanger.com/framework/log"
+	"regexp"
+)
+
+// A Querier reports whether value already exists in column of table.  It
+// lets Unique run against an ORM or any store other than database/sql.
+type Querier interface {
+	Exists(table, column string, value interface{}) (bool, error)
+}
+
+// A Querier that can also give up when a context ends.  Unique uses it
+// from CheckCtx.
+type QuerierCtx interface {
+	Querier
+	ExistsCtx(ctx context.Context, table, column string, value interface{}) (bool, error)
+}
+
+// The Querier used by Unique validators that have neither a Querier nor a
+// DB of their own, such as those built by Validation.Unique.  A *db.DB is
+// one, using the placeholders of its driver.
+var DefaultQuerier Querier
+
+var sqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// A Querier for database/sql.  Placeholder is the driver's bind parameter
+// ("?" if empty, "$1" for PostgreSQL).
+type SQLQuerier struct {
+	DB          *sql.DB
+	Placeholder string
+}
+
+func (q SQLQuerier) Exists(table, column string, value interface{}) (bool, error) {
+	return q.ExistsCtx(context.Background(), table, column, value)
+}
+
+func (q SQLQuerier) ExistsCtx(ctx context.Context, table, column string, value interface{}) (bool, error) {
+	if !sqlIdentifierPattern.MatchString(table) || !sqlIdentifierPattern.MatchString(column) {
+		return false, fmt.Errorf("invalid table or column name: %s.%s", table, column)
+	}
+
+	placeholder := q.Placeholder
+	if placeholder == "" {
+		placeholder = "?"
+	}
+
+	var one int
+	err := q.DB.QueryRowContext(ctx, "SELECT 1 FROM "+table+" WHERE "+column+" = "+placeholder+" LIMIT 1", value).Scan(&one)
+	switch err {
+	case nil:
+		return true, nil
+	case sql.ErrNoRows:
+		return false, nil
+	}
+
+	return false, err
+}
+
+// Requires a value not to exist yet in Column of Table, looked up through
+// Querier, or DB, or else DefaultQuerier.  A failed lookup is logged and
+// treated as not unique.
+type Unique struct {
+	Table, Column string
+	DB            *sql.DB
+	Querier       Querier
+}
+
+func (u Unique) querier() Querier {
+	switch {
+	case u.Querier != nil:
+		return u.Querier
+	case u.DB != nil:
+		return SQLQuerier{DB: u.DB}
+	}
+
+	return DefaultQuerier
+}
+
+func (u Unique) IsSatisfied(obj interface{}) bool {
+	return u.IsSatisfiedCtx(context.Background(), obj)
+}
+
+func (u Unique) IsSatisfiedCtx(ctx context.Context, obj interface{}) bool {
+	q := u.querier()
+	if q == nil {
+		log.FromContext(ctx).Error("<Unique.IsSatisfied> ", "no Querier for ", u.Table, ".", u.Column)
+		return false
+	}
+
+	var exists bool
+	var err error
+	if qc, ok := q.(QuerierCtx); ok {
+		exists, err = qc.ExistsCtx(ctx, u.Table, u.Column, obj)
+	} else {
+		exists, err = q.Exists(u.Table, u.Column, obj)
+	}
+
+	if err != nil {
+		log.FromContext(ctx).Error("<Unique.IsSatisfied> ", err)
+		return false
+	}
+
+	return !exists
+}
+
+func (u Unique) DefaultMessage() string {
+	return "Already taken"
+}
+
+func (v *Validation) Unique(obj interface{}, table, column string) *ValidationResult {
+	return v.apply(Unique{Table: table, Column: column}, obj)
+}
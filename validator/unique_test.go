@@ -0,0 +1,155 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"golanger.com/framework/log"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// A database/sql driver whose tables hold the names taken, and which
+// remembers the last query it was given.
+type takenDriver struct{}
+
+var taken = struct {
+	sync.Mutex
+	names map[interface{}]bool
+	query string
+}{names: map[interface{}]bool{"ann": true}}
+
+func init() {
+	sql.Register("validator_taken", takenDriver{})
+}
+
+func (takenDriver) Open(dsn string) (driver.Conn, error) { return takenConn{}, nil }
+
+type takenConn struct{}
+
+func (takenConn) Prepare(query string) (driver.Stmt, error) { return takenStmt(query), nil }
+func (takenConn) Close() error                              { return nil }
+func (takenConn) Begin() (driver.Tx, error)                 { return nil, errors.New("no transactions") }
+
+type takenStmt string
+
+func (s takenStmt) Close() error  { return nil }
+func (s takenStmt) NumInput() int { return -1 }
+
+func (s takenStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("read only")
+}
+
+func (s takenStmt) Query(args []driver.Value) (driver.Rows, error) {
+	taken.Lock()
+	defer taken.Unlock()
+	taken.query = string(s)
+
+	rows := &takenRows{}
+	if taken.names[args[0]] {
+		rows.left = 1
+	}
+
+	return rows, nil
+}
+
+type takenRows struct {
+	left int
+}
+
+func (r *takenRows) Columns() []string { return []string{"1"} }
+func (r *takenRows) Close() error      { return nil }
+
+func (r *takenRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		return io.EOF
+	}
+
+	r.left--
+	dest[0] = int64(1)
+
+	return nil
+}
+
+// A Querier of a map of table.column to the values taken.
+type mapQuerier map[string][]interface{}
+
+func (q mapQuerier) Exists(table, column string, value interface{}) (bool, error) {
+	values, ok := q[table+"."+column]
+	if !ok {
+		return false, errors.New("no such column")
+	}
+
+	for _, v := range values {
+		if v == value {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func TestSQLQuerier(t *testing.T) {
+	db, err := sql.Open("validator_taken", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	u := Unique{Table: "users", Column: "name", DB: db}
+	if u.IsSatisfied("ann") || !u.IsSatisfied("bob") {
+		t.Error("wrong answers from the database")
+	}
+
+	if taken.query != "SELECT 1 FROM users WHERE name = ? LIMIT 1" {
+		t.Errorf("query %q", taken.query)
+	}
+
+	q := SQLQuerier{DB: db, Placeholder: "$1"}
+	if exists, err := q.Exists("public.users", "name", "ann"); !exists || err != nil || !strings.HasSuffix(taken.query, "name = $1 LIMIT 1") {
+		t.Errorf("Exists = %v, %v for %q", exists, err, taken.query)
+	}
+
+	for _, name := range [][2]string{{"users; DROP TABLE users", "name"}, {"users", "name = name OR 1"}} {
+		if _, err := q.Exists(name[0], name[1], "x"); err == nil {
+			t.Errorf("no error for %s.%s", name[0], name[1])
+		}
+	}
+}
+
+func TestUniqueQueriers(t *testing.T) {
+	saved := DefaultQuerier
+	defer func() { DefaultQuerier = saved }()
+
+	var buf bytes.Buffer
+	ctx := log.NewContext(context.Background(), log.New(log.NewTextHandler(&buf), log.LEVEL_ALL))
+	u := Unique{Table: "users", Column: "email"}
+
+	DefaultQuerier = nil
+	if u.IsSatisfiedCtx(ctx, "a@example.com") || !strings.Contains(buf.String(), "no Querier for users.email") {
+		t.Errorf("satisfied without a Querier, logged %q", buf.String())
+	}
+
+	DefaultQuerier = mapQuerier{"users.email": {"a@example.com"}}
+	v := &Validation{}
+	v.Unique("a@example.com", "users", "email").Key("taken")
+	v.Unique("b@example.com", "users", "email").Key("free")
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["taken"].Message != "Already taken" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	// The Querier of the validator wins over DefaultQuerier.
+	own := Unique{Table: "users", Column: "email", Querier: mapQuerier{"users.email": {"b@example.com"}}}
+	if !own.IsSatisfied("a@example.com") || own.IsSatisfied("b@example.com") {
+		t.Error("DefaultQuerier used instead of the Querier of the validator")
+	}
+
+	buf.Reset()
+	if (Unique{Table: "users", Column: "phone"}).IsSatisfiedCtx(ctx, "1") || !strings.Contains(buf.String(), "no such column") {
+		t.Errorf("satisfied after a failed lookup, logged %q", buf.String())
+	}
+}
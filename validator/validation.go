@@ -0,0 +1,623 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ValidationError struct {
+	Message, Key string
+	Code         string
+}
+
+// Returns the Message.
+func (e *ValidationError) String() string {
+	if e == nil {
+		return ""
+	}
+
+	return e.Message
+}
+
+// A Validation context manages data validation and error messages.
+type Validation struct {
+	Errors     []*ValidationError
+	Warnings   []*ValidationError
+	keep       bool
+	stop       bool
+	callerKeys bool
+	stopSet    bool
+	keysSet    bool
+	scenario   string
+	data       map[string]interface{}
+	locale     string
+	labels     map[string]string
+	errorHooks []func(e *ValidationError)
+	ctx        context.Context
+	audit      *Audit
+}
+
+func (v *Validation) Keep() {
+	v.keep = true
+}
+
+// Choose whether checks stop once an error has been recorded.  By default,
+// unless Config sets otherwise, every check runs and every failure is
+// collected, as forms want; with stop set, the checks after the first
+// failure record nothing and report Ok, for endpoints that only need to
+// fail fast.
+func (v *Validation) StopOnError(stop bool) *Validation {
+	v.stop = stop
+	v.stopSet = true
+
+	return v
+}
+
+// Choose whether an error recorded without a key is keyed by the call site
+// of the check, as "package.Func#line".  This is a compatibility mode for
+// code written against the original API, which found errors by those keys:
+// looking up the caller costs a stack walk on every failed check and the
+// key changes whenever the code moves.  Without it, such errors have an
+// empty key until ValidationResult.Key sets one; prefer CheckKey,
+// RequiredKey and ValidateStruct, which take the key up front.
+func (v *Validation) CallerKeys(on bool) *Validation {
+	v.callerKeys = on
+	v.keysSet = true
+
+	return v
+}
+
+// Return the settings of StopOnError and CallerKeys, or else those of
+// Config.
+func (v *Validation) stopOnError() bool {
+	if v.stopSet {
+		return v.stop
+	}
+
+	return defaults.stopOnError
+}
+
+func (v *Validation) usesCallerKeys() bool {
+	if v.keysSet {
+		return v.callerKeys
+	}
+
+	return defaults.callerKeys
+}
+
+// Select the scenario, such as "create" or "update", whose scenario-only
+// struct tag rules apply.  See ValidateStruct.
+func (v *Validation) Scenario(scenario string) *Validation {
+	v.scenario = scenario
+
+	return v
+}
+
+// Report whether checks are skipped because StopOnError is set and an
+// error has been recorded.
+func (v *Validation) stopped() bool {
+	return v.stopOnError() && len(v.Errors) > 0
+}
+
+// Stash contextual data (e.g. the current tenant) for DataAwareValidators.
+func (v *Validation) WithData(key string, value interface{}) *Validation {
+	if v.data == nil {
+		v.data = map[string]interface{}{}
+	}
+
+	v.data[key] = value
+
+	return v
+}
+
+// Return a new Validation sharing v's data, labels, hooks and locale, for
+// validating part of a request in another goroutine.  A Validation is not
+// safe for concurrent use, so each goroutine works on its own Child and the
+// owner of v merges them back once they are done.
+func (v *Validation) Child() *Validation {
+	child := &Validation{locale: v.locale, scenario: v.scenario, ctx: v.ctx, audit: v.audit}
+	child.StopOnError(v.stopOnError()).CallerKeys(v.usesCallerKeys())
+	child.errorHooks = append(child.errorHooks, v.errorHooks...)
+	for key, label := range v.labels {
+		child.SetLabel(key, label)
+	}
+
+	if v.data != nil {
+		child.data = map[string]interface{}{}
+		for key, value := range v.data {
+			child.data[key] = value
+		}
+	}
+
+	return child
+}
+
+// Append the errors and warnings of other, typically a finished Child, to v.
+func (v *Validation) Merge(other *Validation) *Validation {
+	if other != nil {
+		v.Errors = append(v.Errors, other.Errors...)
+		v.Warnings = append(v.Warnings, other.Warnings...)
+	}
+
+	return v
+}
+
+func (v *Validation) Clear() {
+	v.Errors = []*ValidationError{}
+	v.Warnings = []*ValidationError{}
+}
+
+func (v *Validation) HasErrors() bool {
+	return len(v.Errors) > 0
+}
+
+// Report whether any failure was downgraded to a warning with Warn.
+func (v *Validation) HasWarnings() bool {
+	return len(v.Warnings) > 0
+}
+
+// Return the warnings mapped by key, the first one for a key winning as
+// in ErrorMap.
+func (v *Validation) WarningMap() map[string]*ValidationError {
+	m := map[string]*ValidationError{}
+	for _, e := range v.Warnings {
+		if _, ok := m[e.Key]; !ok {
+			m[e.Key] = e
+		}
+	}
+
+	return m
+}
+
+// Return the errors mapped by key.
+// If there are multiple validation errors associated with a single key, the
+// first one "wins".  (Typically the first validation will be the more basic).
+func (v *Validation) ErrorMap() map[string]*ValidationError {
+	m := map[string]*ValidationError{}
+	for _, e := range v.Errors {
+		if _, ok := m[e.Key]; !ok {
+			m[e.Key] = e
+		}
+	}
+
+	return m
+}
+
+// Return all of the errors mapped by key, in the order they were recorded.
+func (v *Validation) ErrorsByKey() map[string][]*ValidationError {
+	m := map[string][]*ValidationError{}
+	for _, e := range v.Errors {
+		m[e.Key] = append(m[e.Key], e)
+	}
+
+	return m
+}
+
+// Return a copy of the errors whose key starts with prefix.
+// An empty prefix returns all of the errors.
+func (v *Validation) ErrorsWithPrefix(prefix string) []*ValidationError {
+	errs := []*ValidationError{}
+	for _, e := range v.Errors {
+		if strings.HasPrefix(e.Key, prefix) {
+			errs = append(errs, e)
+		}
+	}
+
+	return errs
+}
+
+// Add an error to the validation context.  It is passed to the failure
+// hooks once Key is called on the result.
+func (v *Validation) Error(message string, args ...interface{}) *ValidationResult {
+	result := (&ValidationResult{
+		Ok:         false,
+		Error:      &ValidationError{},
+		validation: v,
+		unreported: true,
+	}).Message(message, args...)
+	v.Errors = append(v.Errors, result.Error)
+
+	return result
+}
+
+// A ValidationResult is returned from every validation method.
+// It provides an indication of success, and a pointer to the Error (if any).
+type ValidationResult struct {
+	Error *ValidationError
+	Ok    bool
+
+	// The message template of the error, and the check and value it
+	// describes, rendered again whenever the key or template change.
+	template   string
+	check      Validator
+	value      interface{}
+	validation *Validation
+
+	// The failure awaits its key to be passed to the failure hooks.
+	unreported bool
+}
+
+// Set Error.Message from the template, replacing {field} with the key,
+// {label} with its label, {value} with the checked value and the other
+// placeholders with the parameters of the validator (see formatMessage).
+func (r *ValidationResult) render() {
+	msg := r.template
+	if r.check != nil {
+		msg = formatMessage(msg, r.check)
+	}
+
+	value := ""
+	if r.value != nil {
+		value = fmt.Sprint(r.value)
+	}
+
+	label := r.Error.Key
+	if r.validation != nil && strings.Contains(msg, "{label}") {
+		label = r.validation.Label(r.Error.Key)
+	}
+
+	r.Error.Message = strings.NewReplacer("{field}", r.Error.Key, "{label}", label, "{value}", value).Replace(msg)
+}
+
+func (r *ValidationResult) Key(key string) *ValidationResult {
+	if r.Error != nil {
+		r.Error.Key = key
+		r.render()
+		r.report()
+	}
+
+	return r
+}
+
+// Pass the failure to the hooks, unless it was already.
+func (r *ValidationResult) report() {
+	if r.unreported {
+		r.unreported = false
+		if r.validation != nil {
+			r.validation.reportFailure(r.Error)
+		}
+	}
+}
+
+func (r *ValidationResult) Code(code string) *ValidationResult {
+	if r.Error != nil {
+		r.Error.Code = code
+	}
+
+	return r
+}
+
+// Downgrade a failure to a warning: the error is moved from Errors to
+// Warnings, so that it no longer fails the validation but can still be
+// shown, e.g. "Password is weak".  Ok stays false.
+//
+//	v.Password(pw, strong).Key("Password").Warn()
+func (r *ValidationResult) Warn() *ValidationResult {
+	v := r.validation
+	if r.Error == nil || v == nil {
+		return r
+	}
+
+	for i, e := range v.Errors {
+		if e == r.Error {
+			v.Errors = append(v.Errors[:i], v.Errors[i+1:]...)
+			v.Warnings = append(v.Warnings, r.Error)
+			break
+		}
+	}
+
+	return r
+}
+
+// Return the key the error is recorded under, or "" if the check passed.
+func (r *ValidationResult) MessageKey() string {
+	if r.Error == nil {
+		return ""
+	}
+
+	return r.Error.Key
+}
+
+// Replace the message of the error.  With args, message is a Sprintf
+// format; without, it is used as is.  Either way it may hold named
+// placeholders: {field} for the key, {label} for its label (see
+// SetLabel), {value} for the checked value, and the lower case name of
+// any parameter of the validator, such as {min}:
+//
+//	v.MinSize(name, 3).Key("Name").Message("{field} must be at least {min} characters")
+func (r *ValidationResult) Message(message string, args ...interface{}) *ValidationResult {
+	if r.Error != nil {
+		if len(args) == 0 {
+			r.template = message
+		} else {
+			r.template = fmt.Sprintf(message, args...)
+		}
+
+		r.render()
+	}
+
+	return r
+}
+
+// Test that the argument is non-nil and non-empty (if string or list)
+func (v *Validation) Required(obj interface{}) *ValidationResult {
+	return v.apply(Required{}, obj)
+}
+
+func (v *Validation) Min(n int, min int) *ValidationResult {
+	return v.apply(Min{min}, n)
+}
+
+func (v *Validation) Max(n int, max int) *ValidationResult {
+	return v.apply(Max{max}, n)
+}
+
+func (v *Validation) Range(n, min, max int) *ValidationResult {
+	return v.apply(Range{Min{min}, Max{max}}, n)
+}
+
+func (v *Validation) MinSize(obj interface{}, min int) *ValidationResult {
+	return v.apply(MinSize{min}, obj)
+}
+
+func (v *Validation) MaxSize(obj interface{}, max int) *ValidationResult {
+	return v.apply(MaxSize{max}, obj)
+}
+
+func (v *Validation) Length(obj interface{}, n int) *ValidationResult {
+	return v.apply(Length{n}, obj)
+}
+
+func (v *Validation) Match(str string, regex *regexp.Regexp) *ValidationResult {
+	return v.apply(Match{regex}, str)
+}
+
+func (v *Validation) Email(str string) *ValidationResult {
+	return v.apply(NewEmail(), str)
+}
+
+func (v *Validation) Mask(str, mask string) *ValidationResult {
+	return v.apply(NewMask(mask), str)
+}
+
+func (v *Validation) AtLeastSumOf(key string, total float64, parts ...float64) *ValidationResult {
+	return v.apply(AtLeastSum{parts}, total).Key(key)
+}
+
+func (v *Validation) AtLeastProductOf(key string, total float64, factors ...float64) *ValidationResult {
+	return v.apply(AtLeastProduct{factors}, total).Key(key)
+}
+
+func (v *Validation) BusinessHours(t time.Time, startHour, endHour int, weekdays ...time.Weekday) *ValidationResult {
+	return v.apply(BusinessHours{startHour, endHour, weekdays}, t)
+}
+
+func (v *Validation) SchemaVersion(obj interface{}, field string, supported ...string) *ValidationResult {
+	return v.apply(SchemaVersion{field, supported}, obj).Key(field)
+}
+
+func (v *Validation) NoNilElements(slice interface{}) *ValidationResult {
+	result := v.apply(NoNilElements{}, slice)
+	if i := firstNilElement(slice); !result.Ok && i >= 0 {
+		result.Message("Element %d must not be nil", i)
+	}
+
+	return result
+}
+
+func (v *Validation) FitsInt64(str string) *ValidationResult {
+	return v.apply(FitsInt{64}, str)
+}
+
+func (v *Validation) FitsInt32(str string) *ValidationResult {
+	return v.apply(FitsInt{32}, str)
+}
+
+func (v *Validation) CSSColor(str string) *ValidationResult {
+	return v.apply(CSSColor{}, str)
+}
+
+func (v *Validation) JWTStructure(str string) *ValidationResult {
+	return v.apply(JWTStructure{}, str)
+}
+
+func (v *Validation) DateRange(key string, start, end time.Time) *ValidationResult {
+	return v.apply(DateRange{start, false}, end).Key(key)
+}
+
+func (v *Validation) StrictDateRange(key string, start, end time.Time) *ValidationResult {
+	return v.apply(DateRange{start, true}, end).Key(key)
+}
+
+func (v *Validation) PasswordNotCommon(str string, list map[string]bool) *ValidationResult {
+	return v.apply(PasswordNotCommon{list}, str)
+}
+
+func (v *Validation) URL(str string) *ValidationResult {
+	return v.apply(URL{}, str)
+}
+
+func (v *Validation) IPv4(str string) *ValidationResult {
+	return v.apply(IPv4{}, str)
+}
+
+func (v *Validation) IPv6(str string) *ValidationResult {
+	return v.apply(IPv6{}, str)
+}
+
+func (v *Validation) UUID(str string) *ValidationResult {
+	return v.apply(UUID{}, str)
+}
+
+func (v *Validation) Phone(str, region string) *ValidationResult {
+	return v.apply(Phone{region}, str)
+}
+
+func (v *Validation) apply(chk Validator, obj interface{}) *ValidationResult {
+	return v.applyCaller(chk, obj, 3)
+}
+
+// Like apply, but skip is the number of stack frames to ascend from
+// applyCaller, as for runtime.Caller, to the call site used as the
+// default key.
+func (v *Validation) applyCaller(chk Validator, obj interface{}, skip int) *ValidationResult {
+	if v.stopped() || v.audited("", chk, obj) {
+		return &ValidationResult{Ok: true}
+	}
+
+	return v.fail(chk, obj, skip+1)
+}
+
+// Like apply, but records a failure under key without looking up the
+// caller.
+func (v *Validation) applyKey(key string, chk Validator, obj interface{}) *ValidationResult {
+	if v.stopped() || v.audited(key, chk, obj) {
+		return &ValidationResult{Ok: true}
+	}
+
+	return v.record(key, chk, obj)
+}
+
+// Record that obj failed chk.  With CallerKeys, the error is keyed by the
+// call site skip frames above fail.
+func (v *Validation) fail(chk Validator, obj interface{}, skip int) *ValidationResult {
+	return v.record(v.callerKey(skip+1), chk, obj)
+}
+
+// Return the default key of a failure with CallerKeys: the call site skip
+// frames above callerKey.  It is "" without CallerKeys.
+func (v *Validation) callerKey(skip int) string {
+	if !v.usesCallerKeys() {
+		return ""
+	}
+
+	pc, _, line, ok := runtime.Caller(skip)
+	if !ok {
+		v.logger().Info("<Validation.apply> ", "Failed to get Caller information to look up Validation key")
+		return ""
+	}
+
+	if f := runtime.FuncForPC(pc); f != nil {
+		return f.Name() + "#" + strconv.Itoa(line)
+	}
+
+	return ""
+}
+
+// Record that obj failed chk under key, passing the failure to the hooks
+// if the key is known.
+func (v *Validation) record(key string, chk Validator, obj interface{}) *ValidationResult {
+	result := v.failure(key, chk, obj)
+	if key != "" {
+		result.report()
+	}
+
+	return result
+}
+
+// Record that obj failed chk under key, without passing the failure to
+// the hooks yet.
+func (v *Validation) failure(key string, chk Validator, obj interface{}) *ValidationResult {
+	chk = unwrap(chk)
+
+	// Add the error to the validation context.
+	err := &ValidationError{
+		Key:  key,
+		Code: errorCode(chk),
+	}
+	v.Errors = append(v.Errors, err)
+
+	// Also return it in the result.
+	result := &ValidationResult{
+		Ok:         false,
+		Error:      err,
+		template:   v.messageTemplate(chk),
+		check:      chk,
+		value:      obj,
+		validation: v,
+		unreported: true,
+	}
+	result.render()
+
+	return result
+}
+
+func (v *Validation) satisfied(chk Validator, obj interface{}) bool {
+	if dchk, ok := chk.(DataAwareValidator); ok {
+		return dchk.IsSatisfiedData(obj, v.data)
+	}
+
+	if lchk, ok := chk.(LocaleAwareValidator); ok {
+		return lchk.IsSatisfiedLocale(obj, v.locale)
+	}
+
+	if cchk, ok := chk.(ValidatorCtx); ok && v.ctx != nil {
+		return cchk.IsSatisfiedCtx(v.ctx, obj)
+	}
+
+	return chk.IsSatisfied(obj)
+}
+
+// Apply a group of validators to a field, in order, and return the
+// ValidationResult from the first one that fails, or the last one that
+// succeeds.
+func (v *Validation) Check(obj interface{}, checks ...Validator) *ValidationResult {
+	var result *ValidationResult
+	for _, check := range checks {
+		result = v.apply(check, obj)
+		if !result.Ok {
+			return result
+		}
+	}
+
+	return result
+}
+
+// Like Check, but records the first failure under key, e.g.
+// v.CheckKey("email", email, Required{}, NewEmail()).
+func (v *Validation) CheckKey(key string, obj interface{}, checks ...Validator) *ValidationResult {
+	result := &ValidationResult{Ok: true}
+	for _, check := range checks {
+		result = v.applyKey(key, check, obj)
+		if !result.Ok {
+			return result
+		}
+	}
+
+	return result
+}
+
+func (v *Validation) RequiredKey(key string, obj interface{}) *ValidationResult {
+	return v.applyKey(key, Required{}, obj)
+}
+
+// Apply every validator to a field and record a single error under key
+// whose message joins the default messages of all the checks that failed
+// and whose code is that of the first one.
+func (v *Validation) CheckMerged(key string, obj interface{}, checks ...Validator) *ValidationResult {
+	if v.stopped() {
+		return &ValidationResult{Ok: true}
+	}
+
+	messages := []string{}
+	code := ""
+	for _, check := range checks {
+		if !v.audited(key, check, obj) {
+			messages = append(messages, v.message(check))
+			if code == "" {
+				code = errorCode(check)
+			}
+		}
+	}
+
+	if len(messages) == 0 {
+		return &ValidationResult{Ok: true}
+	}
+
+	return v.Error("%s", strings.Join(messages, ", ")).Code(code).Key(key)
+}
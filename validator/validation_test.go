@@ -0,0 +1,251 @@
+package validator
+
+import (
+	"regexp"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Requires a slug to be free in the tenant stashed with WithData.
+type freeSlug struct{}
+
+func (freeSlug) IsSatisfied(obj interface{}) bool { return false }
+func (freeSlug) DefaultMessage() string           { return "Slug is taken" }
+
+func (freeSlug) IsSatisfiedData(obj interface{}, data map[string]interface{}) bool {
+	taken, _ := data["taken"].(map[string]bool)
+
+	return data["tenant"] != nil && !taken[obj.(string)]
+}
+
+func TestWithData(t *testing.T) {
+	v := &Validation{}
+	v.CheckKey("none", "home", freeSlug{})
+
+	v.WithData("tenant", "acme").WithData("taken", map[string]bool{"about": true})
+	v.CheckKey("home", "home", freeSlug{})
+	v.CheckKey("about", "about", freeSlug{})
+
+	errs := v.ErrorMap()
+	if len(errs) != 2 || errs["none"] == nil || errs["about"] == nil {
+		t.Errorf("errors %v", errs)
+	}
+
+	if child := v.Child(); !child.CheckKey("home", "home", freeSlug{}).Ok {
+		t.Error("the data is not shared with a Child")
+	}
+}
+
+func TestErrorsWithPrefix(t *testing.T) {
+	v := &Validation{}
+	for _, key := range []string{"address.street", "address.city", "addresses", "name"} {
+		v.Error("Bad").Key(key)
+	}
+
+	keys := func(errs []*ValidationError) (keys []string) {
+		for _, e := range errs {
+			keys = append(keys, e.Key)
+		}
+
+		return keys
+	}
+
+	if got := keys(v.ErrorsWithPrefix("address.")); len(got) != 2 || got[0] != "address.street" || got[1] != "address.city" {
+		t.Errorf("ErrorsWithPrefix(address.) = %v", got)
+	}
+
+	if got := v.ErrorsWithPrefix(""); len(got) != 4 {
+		t.Errorf("ErrorsWithPrefix() = %v", keys(got))
+	}
+
+	if got := v.ErrorsWithPrefix("email"); got == nil || len(got) != 0 {
+		t.Errorf("ErrorsWithPrefix(email) = %v", got)
+	}
+
+	// The result is a copy.
+	v.ErrorsWithPrefix("")[0] = nil
+	if v.Errors[0] == nil {
+		t.Error("ErrorsWithPrefix shares the errors")
+	}
+}
+
+func TestCheckMerged(t *testing.T) {
+	v := &Validation{}
+	r := v.CheckMerged("name", "x", Required{}, MinSize{3}, Length{5})
+	if r.Ok || r.Error.Message != "Minimum size is 3, Required length is 5" || r.Error.Code != "validation.min_size" {
+		t.Errorf("CheckMerged = %+v", r.Error)
+	}
+
+	if !v.CheckMerged("ok", "hello", Required{}, MinSize{3}).Ok {
+		t.Error("passing checks failed")
+	}
+
+	v.StopOnError(true)
+	if !v.CheckMerged("skipped", "", Required{}).Ok {
+		t.Error("checked after an error with StopOnError")
+	}
+
+	if len(v.Errors) != 1 || v.Errors[0].Key != "name" {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestErrorsByKey(t *testing.T) {
+	v := &Validation{}
+	v.MinSize("ab", 3).Key("name")
+	v.Match("ab", regexp.MustCompile(`^[A-Z]`)).Key("name")
+	v.Email("nope").Key("email")
+
+	byKey := v.ErrorsByKey()
+	if len(byKey) != 2 || len(byKey["name"]) != 2 || byKey["name"][0].Code != "validation.min_size" || len(byKey["email"]) != 1 {
+		t.Errorf("ErrorsByKey = %v", byKey)
+	}
+
+	if got := v.Required("x").MessageKey(); got != "" {
+		t.Errorf("MessageKey of a passed check = %q", got)
+	}
+
+	if got := v.Required("").Key("title").MessageKey(); got != "title" {
+		t.Errorf("MessageKey = %q", got)
+	}
+}
+
+func TestChildMerge(t *testing.T) {
+	var mu sync.Mutex
+	var hooked []string
+	v := (&Validation{}).SetLocale("xx").StopOnError(true)
+	v.SetLabel("address.city", "City")
+	v.OnError(func(e *ValidationError) {
+		mu.Lock()
+		hooked = append(hooked, e.Key)
+		mu.Unlock()
+	})
+
+	children := make([]*Validation, 3)
+	var wg sync.WaitGroup
+	for i := range children {
+		children[i] = v.Child()
+		wg.Add(1)
+		go func(c *Validation, i int) {
+			defer wg.Done()
+			if i > 0 {
+				c.Required("").Key("address.city").Message("{label} is missing")
+				c.Required("").Key("address.zip")
+			}
+		}(children[i], i)
+	}
+	wg.Wait()
+
+	for _, c := range children {
+		v.Merge(c)
+	}
+	v.Merge(nil)
+
+	if len(v.Errors) != 2 || v.Errors[0].Message != "City is missing" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	// The hooks and StopOnError are shared: the zip code was not checked.
+	if len(hooked) != 2 {
+		t.Errorf("hooked %v", hooked)
+	}
+
+	child := v.Child()
+	if child.locale != "xx" || !child.stopOnError() || len(child.Errors) != 0 {
+		t.Errorf("child %+v", child)
+	}
+
+	child.WithData("tenant", "acme")
+	if v.data["tenant"] != nil {
+		t.Error("the data of a Child is shared with its parent")
+	}
+}
+
+func TestStopOnError(t *testing.T) {
+	v := (&Validation{}).StopOnError(true)
+	if !v.Required("x").Ok || v.stopped() {
+		t.Error("stopped before an error")
+	}
+
+	v.Required("").Key("first")
+	if r := v.Email("nope").Key("second"); !r.Ok || r.Error != nil {
+		t.Errorf("checked after StopOnError: %v", r.Error)
+	}
+
+	v.Each([]string{""}, Required{}).Key("tags")
+	v.ValidateStruct(struct {
+		Name string `validate:"required"`
+	}{})
+
+	if len(v.Errors) != 1 || v.Errors[0].Key != "first" {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	// Turned off, every failure is collected again.
+	v.StopOnError(false).Required("").Key("third")
+	if len(v.Errors) != 2 {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	v := &Validation{}
+	if r := v.Required(""); r.MessageKey() != "" {
+		t.Errorf("keyed %q without CallerKeys", r.MessageKey())
+	}
+
+	v.CheckKey("email", "nope", Required{}, NewEmail())
+	v.CheckKey("name", "Jo", Required{}, MinSize{2})
+	v.RequiredKey("title", "")
+	if errs := v.ErrorMap(); len(errs) != 3 || errs["email"].Code != "validation.email" || errs["title"] == nil {
+		t.Errorf("errors %v", v.Errors)
+	}
+
+	v = (&Validation{}).CallerKeys(true)
+	_, _, line, _ := runtime.Caller(0)
+	r := v.Required("")
+	if want := "golanger.com/framework/validator.TestKeys#" + strconv.Itoa(line+1); r.MessageKey() != want {
+		t.Errorf("keyed %q, want %q", r.MessageKey(), want)
+	}
+
+	// An explicit key wins over the caller.
+	if r := v.CheckKey("k", "", Required{}); r.MessageKey() != "k" {
+		t.Errorf("keyed %q", r.MessageKey())
+	}
+}
+
+func TestWarn(t *testing.T) {
+	v := &Validation{}
+	if r := v.MinSize("abc", 8).Key("Password").Warn(); r.Ok || r.Error.Message != "Minimum size is 8" {
+		t.Errorf("warned result %+v", r)
+	}
+
+	v.Required("").Key("Name")
+	if r := v.Required("ann").Key("Nick").Warn(); !r.Ok {
+		t.Error("Warn failed a passing check")
+	}
+
+	if len(v.Errors) != 1 || v.ErrorMap()["Name"] == nil || !v.HasWarnings() || len(v.Warnings) != 1 {
+		t.Errorf("errors %v, warnings %v", v.Errors, v.Warnings)
+	}
+
+	v.Clear()
+	v.MaxSize("abcd", 2).Key("Code").Warn()
+	v.MaxSize("abc", 2).Key("Code").Warn()
+	if v.HasErrors() || len(v.Warnings) != 2 || v.WarningMap()["Code"].Message != "Maximum size is 2" || len(v.WarningMap()) != 1 {
+		t.Errorf("warnings %v", v.Warnings)
+	}
+
+	child := v.Child()
+	child.Required(nil).Key("Title").Warn()
+	if v.Merge(child); len(v.Warnings) != 3 || v.WarningMap()["Title"] == nil || v.HasErrors() {
+		t.Errorf("warnings after Merge %v", v.Warnings)
+	}
+
+	v.Clear()
+	if v.HasWarnings() {
+		t.Errorf("warnings after Clear %v", v.Warnings)
+	}
+}
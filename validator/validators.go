@@ -0,0 +1,653 @@
+package validator
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Validator interface {
+	IsSatisfied(interface{}) bool
+	DefaultMessage() string
+}
+
+// A DataAwareValidator is given the data stashed on the Validation with
+// WithData, and is checked with IsSatisfiedData instead of IsSatisfied.
+type DataAwareValidator interface {
+	Validator
+	IsSatisfiedData(obj interface{}, data map[string]interface{}) bool
+}
+
+// A LocaleAwareValidator is given the locale of the Validation, see
+// SetLocale, and is checked with IsSatisfiedLocale instead of IsSatisfied.
+type LocaleAwareValidator interface {
+	Validator
+	IsSatisfiedLocale(obj interface{}, locale string) bool
+}
+
+type Required struct{}
+
+func (r Required) IsSatisfied(obj interface{}) bool {
+	if obj == nil {
+		return false
+	}
+
+	if str, ok := obj.(string); ok {
+		return len(str) > 0
+	}
+
+	if b, ok := obj.(bool); ok {
+		return b
+	}
+
+	if i, ok := obj.(int); ok {
+		return i != 0
+	}
+
+	if t, ok := obj.(time.Time); ok {
+		return !t.IsZero()
+	}
+
+	if n, ok := toFloat64(obj); ok {
+		return n != 0
+	}
+
+	v := reflect.ValueOf(obj)
+	if v.Kind() == reflect.Slice {
+		return v.Len() > 0
+	}
+
+	return true
+}
+
+func (r Required) DefaultMessage() string {
+	return "Required"
+}
+
+type Min struct {
+	Min int
+}
+
+func (m Min) IsSatisfied(obj interface{}) bool {
+	num, ok := obj.(int)
+	if ok {
+		return num >= m.Min
+	}
+
+	return false
+}
+
+func (m Min) DefaultMessage() string {
+	return fmt.Sprint("Minimum is ", m.Min)
+}
+
+type Max struct {
+	Max int
+}
+
+func (m Max) IsSatisfied(obj interface{}) bool {
+	num, ok := obj.(int)
+	if ok {
+		return num <= m.Max
+	}
+
+	return false
+}
+
+func (m Max) DefaultMessage() string {
+	return fmt.Sprint("Maximum is ", m.Max)
+}
+
+// Requires an integer to be within Min, Max inclusive.
+type Range struct {
+	Min
+	Max
+}
+
+func (r Range) IsSatisfied(obj interface{}) bool {
+	return r.Min.IsSatisfied(obj) && r.Max.IsSatisfied(obj)
+}
+
+func (r Range) DefaultMessage() string {
+	return fmt.Sprint("Range is ", r.Min.Min, " to ", r.Max.Max)
+}
+
+// Requires an array, slice, map or string to be at least a given length.
+type MinSize struct {
+	Min int
+}
+
+func (m MinSize) IsSatisfied(obj interface{}) bool {
+	if str, ok := obj.(string); ok {
+		return len(str) >= m.Min
+	}
+
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() >= m.Min
+	}
+
+	return false
+}
+
+func (m MinSize) DefaultMessage() string {
+	return fmt.Sprint("Minimum size is ", m.Min)
+}
+
+// Requires an array, slice, map or string to be at most a given length.
+type MaxSize struct {
+	Max int
+}
+
+func (m MaxSize) IsSatisfied(obj interface{}) bool {
+	if str, ok := obj.(string); ok {
+		return len(str) <= m.Max
+	}
+
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() <= m.Max
+	}
+
+	return false
+}
+
+func (m MaxSize) DefaultMessage() string {
+	return fmt.Sprint("Maximum size is ", m.Max)
+}
+
+// Requires an array, slice, map or string to be exactly a given length.
+type Length struct {
+	N int
+}
+
+func (s Length) IsSatisfied(obj interface{}) bool {
+	if str, ok := obj.(string); ok {
+		return len(str) == s.N
+	}
+
+	v := reflect.ValueOf(obj)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == s.N
+	}
+
+	return false
+}
+
+func (s Length) DefaultMessage() string {
+	return fmt.Sprint("Required length is ", s.N)
+}
+
+// Requires a string to match a given regex.  Without one, every value
+// fails.
+type Match struct {
+	Regexp *regexp.Regexp
+}
+
+func (m Match) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if ok && m.Regexp != nil {
+		return m.Regexp.MatchString(str)
+	}
+
+	return false
+}
+
+func (m Match) DefaultMessage() string {
+	return fmt.Sprint("Must match ", m.Regexp)
+}
+
+var emailPattern = regexp.MustCompile("^[\\w!#$%&'*+/=?^_`{|}~-]+(?:\\.[\\w!#$%&'*+/=?^_`{|}~-]+)*@(?:[\\w](?:[\\w-]*[\\w])?\\.)+[a-zA-Z0-9](?:[\\w-]*[\\w])?$")
+
+// Requires a string to be an email address, by the default pattern (see
+// Config) unless Match is set.  With VerifyMX the domain must also be able
+// to receive mail; see IsSatisfiedCtx.
+type Email struct {
+	Match
+	VerifyMX bool
+	Resolver MXResolver
+	Timeout  time.Duration
+}
+
+func NewEmail() Email {
+	return Email{Match: Match{defaultEmailPattern()}}
+}
+
+func (e Email) DefaultMessage() string {
+	if e.VerifyMX {
+		return "Must be a valid email address with a domain that receives mail"
+	}
+
+	return "Must be a valid email address"
+}
+
+// Requires a string to match a fixed-format mask, where '#' is a digit,
+// 'A' is a letter, '*' is a letter or digit and any other character must
+// appear literally.  Build it with NewMask; the zero value fails every
+// value.
+type Mask struct {
+	Match
+	Mask string
+}
+
+func NewMask(mask string) Mask {
+	pattern := "^"
+	for _, c := range mask {
+		switch c {
+		case '#':
+			pattern += "[0-9]"
+		case 'A':
+			pattern += "[A-Za-z]"
+		case '*':
+			pattern += "[A-Za-z0-9]"
+		default:
+			pattern += regexp.QuoteMeta(string(c))
+		}
+	}
+
+	return Mask{Match{regexp.MustCompile(pattern + "$")}, mask}
+}
+
+func (m Mask) DefaultMessage() string {
+	return fmt.Sprint("Must match the format ", m.Mask)
+}
+
+// Tolerance used when comparing derived float totals.
+const floatEpsilon = 1e-9
+
+// Requires a float64 to be at least the sum of the given parts.
+type AtLeastSum struct {
+	Parts []float64
+}
+
+func (a AtLeastSum) sum() float64 {
+	var sum float64
+	for _, part := range a.Parts {
+		sum += part
+	}
+
+	return sum
+}
+
+func (a AtLeastSum) IsSatisfied(obj interface{}) bool {
+	total, ok := obj.(float64)
+	if ok {
+		return total >= a.sum()-floatEpsilon
+	}
+
+	return false
+}
+
+func (a AtLeastSum) DefaultMessage() string {
+	return fmt.Sprint("Minimum is ", a.sum())
+}
+
+// Requires a float64 to be at least the product of the given factors.
+type AtLeastProduct struct {
+	Factors []float64
+}
+
+func (a AtLeastProduct) product() float64 {
+	product := 1.0
+	for _, factor := range a.Factors {
+		product *= factor
+	}
+
+	return product
+}
+
+func (a AtLeastProduct) IsSatisfied(obj interface{}) bool {
+	total, ok := obj.(float64)
+	if ok {
+		return total >= a.product()-floatEpsilon
+	}
+
+	return false
+}
+
+func (a AtLeastProduct) DefaultMessage() string {
+	return fmt.Sprint("Minimum is ", a.product())
+}
+
+// Requires a time.Time to fall within [StartHour, EndHour) on one of the
+// allowed weekdays, in the time's own location.  No weekdays means every
+// day is allowed.
+type BusinessHours struct {
+	StartHour, EndHour int
+	Weekdays           []time.Weekday
+}
+
+func (b BusinessHours) IsSatisfied(obj interface{}) bool {
+	t, ok := obj.(time.Time)
+	if !ok || t.Hour() < b.StartHour || t.Hour() >= b.EndHour {
+		return false
+	}
+
+	if len(b.Weekdays) == 0 {
+		return true
+	}
+
+	for _, day := range b.Weekdays {
+		if t.Weekday() == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b BusinessHours) DefaultMessage() string {
+	msg := fmt.Sprintf("Must be between %02d:00 and %02d:00", b.StartHour, b.EndHour)
+	if len(b.Weekdays) > 0 {
+		days := make([]string, len(b.Weekdays))
+		for i, day := range b.Weekdays {
+			days[i] = day.String()
+		}
+
+		msg += " on " + strings.Join(days, ", ")
+	}
+
+	return msg
+}
+
+// Requires the named field of a struct (or pointer to struct) to hold one
+// of the supported versions.
+type SchemaVersion struct {
+	Field     string
+	Supported []string
+}
+
+func (s SchemaVersion) IsSatisfied(obj interface{}) bool {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	f := v.FieldByName(s.Field)
+	if !f.IsValid() || !f.CanInterface() {
+		return false
+	}
+
+	version := fmt.Sprint(f.Interface())
+	for _, supported := range s.Supported {
+		if version == supported {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s SchemaVersion) DefaultMessage() string {
+	return fmt.Sprint("Supported versions are ", strings.Join(s.Supported, ", "))
+}
+
+// Requires a slice or array to contain no nil elements, including typed
+// nils stored in interface elements.
+type NoNilElements struct{}
+
+func (n NoNilElements) IsSatisfied(obj interface{}) bool {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return false
+	}
+
+	return firstNilElement(obj) == -1
+}
+
+func (n NoNilElements) DefaultMessage() string {
+	return "Must not contain nil elements"
+}
+
+// Returns the index of the first nil element of a slice or array, or -1
+// if there is none.
+func firstNilElement(obj interface{}) int {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return -1
+	}
+
+	for i := 0; i < v.Len(); i++ {
+		if isNilValue(v.Index(i)) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return true
+		}
+
+		return isNilValue(v.Elem())
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// Requires a numeric string to parse as a signed integer of BitSize bits.
+type FitsInt struct {
+	BitSize int
+}
+
+func (f FitsInt) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if ok {
+		_, err := strconv.ParseInt(str, 10, f.BitSize)
+		return err == nil
+	}
+
+	return false
+}
+
+func (f FitsInt) DefaultMessage() string {
+	return fmt.Sprintf("Must be an integer within the %d-bit range", f.BitSize)
+}
+
+// Requires a string to be a structurally valid JWT: three base64url
+// segments whose header and payload decode to JSON objects.  The
+// signature is not verified.
+type JWTStructure struct{}
+
+func (j JWTStructure) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	segments := strings.Split(str, ".")
+	if len(segments) != 3 {
+		return false
+	}
+
+	for i, segment := range segments {
+		b, err := base64.RawURLEncoding.DecodeString(segment)
+		if err != nil {
+			return false
+		}
+
+		if i < 2 {
+			var m map[string]interface{}
+			if json.Unmarshal(b, &m) != nil {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (j JWTStructure) DefaultMessage() string {
+	return "Must be a well-formed token"
+}
+
+// Requires an end time.Time to be on or after Start, or strictly after it
+// when Strict is set.  A zero start or end is an incomplete range.
+type DateRange struct {
+	Start  time.Time
+	Strict bool
+}
+
+func (d DateRange) IsSatisfied(obj interface{}) bool {
+	end, ok := obj.(time.Time)
+	if !ok || end.IsZero() || d.Start.IsZero() {
+		return false
+	}
+
+	if d.Strict {
+		return end.After(d.Start)
+	}
+
+	return !end.Before(d.Start)
+}
+
+func (d DateRange) DefaultMessage() string {
+	return "End must be after start"
+}
+
+// Requires a password not to appear, lowercased, in a set of common
+// passwords.
+type PasswordNotCommon struct {
+	List map[string]bool
+}
+
+func (p PasswordNotCommon) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if ok {
+		return !p.List[strings.ToLower(str)]
+	}
+
+	return false
+}
+
+func (p PasswordNotCommon) DefaultMessage() string {
+	return "Password is too common"
+}
+
+// Requires a string to be an absolute URL with a scheme and host.
+type URL struct{}
+
+func (u URL) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	pu, err := url.ParseRequestURI(str)
+
+	return err == nil && pu.Scheme != "" && pu.Host != ""
+}
+
+func (u URL) DefaultMessage() string {
+	return "Must be a valid URL"
+}
+
+// Requires a string to be a dotted IPv4 address.
+type IPv4 struct{}
+
+func (i IPv4) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok || strings.Contains(str, ":") {
+		return false
+	}
+
+	ip := net.ParseIP(str)
+
+	return ip != nil && ip.To4() != nil
+}
+
+func (i IPv4) DefaultMessage() string {
+	return "Must be a valid IPv4 address"
+}
+
+// Requires a string to be an IPv6 address.
+type IPv6 struct{}
+
+func (i IPv6) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok || !strings.Contains(str, ":") {
+		return false
+	}
+
+	return net.ParseIP(str) != nil
+}
+
+func (i IPv6) DefaultMessage() string {
+	return "Must be a valid IPv6 address"
+}
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// Requires a string to be a hyphenated UUID, or to match Match if set.
+type UUID struct {
+	Match
+}
+
+func (u UUID) IsSatisfied(obj interface{}) bool {
+	match := u.Match
+	if match.Regexp == nil {
+		match = Match{uuidPattern}
+	}
+
+	return match.IsSatisfied(obj)
+}
+
+func (u UUID) DefaultMessage() string {
+	return "Must be a valid UUID"
+}
+
+var phonePatterns = map[string]*regexp.Regexp{
+	"":   regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`),
+	"US": regexp.MustCompile(`^(?:\+?1[ .-]?)?\(?[2-9][0-9]{2}\)?[ .-]?[2-9][0-9]{2}[ .-]?[0-9]{4}$`),
+	"CN": regexp.MustCompile(`^(?:\+?86[ -]?)?(?:1[3-9][0-9]{9}|0[0-9]{2,3}-?[0-9]{7,8})$`),
+	"GB": regexp.MustCompile(`^(?:\+?44[ ]?|0)[1-9][0-9 ]{8,11}$`),
+}
+
+// Requires a string to be a phone number of the given region ("US", "CN",
+// "GB"), or an E.164 number such as +14155550100 when Region is empty or
+// unknown.
+type Phone struct {
+	Region string
+}
+
+func (p Phone) IsSatisfied(obj interface{}) bool {
+	str, ok := obj.(string)
+	if !ok {
+		return false
+	}
+
+	re, found := phonePatterns[strings.ToUpper(p.Region)]
+	if !found {
+		re = phonePatterns[""]
+	}
+
+	return re.MatchString(strings.TrimSpace(str))
+}
+
+func (p Phone) DefaultMessage() string {
+	return "Must be a valid phone number"
+}
@@ -0,0 +1,350 @@
+package validator
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestMask(t *testing.T) {
+	cases := []struct {
+		mask, str string
+		want      bool
+	}{
+		{"(###) ###-####", "(555) 123-4567", true},
+		{"(###) ###-####", "555 123-4567", false},
+		{"AA-####", "ab-1234", true},
+		{"AA-####", "a1-1234", false},
+		{"***.#", "a9Z.0", true},
+		{"***.#", "a9Z-0", false},
+		{"###", "1234", false},
+	}
+
+	for _, c := range cases {
+		if got := NewMask(c.mask).IsSatisfied(c.str); got != c.want {
+			t.Errorf("Mask %q on %q = %v", c.mask, c.str, got)
+		}
+	}
+
+	v := &Validation{}
+	if r := v.Mask("12345", "#####-###").Key("zip"); r.Ok || r.Error.Message != "Must match the format #####-###" {
+		t.Errorf("Mask = %v", r.Error)
+	}
+
+	// Without a pattern, every value fails instead of panicking.
+	for _, chk := range []Validator{Match{}, Mask{}} {
+		if chk.IsSatisfied("") || chk.IsSatisfied("abc") {
+			t.Errorf("%#v satisfied", chk)
+		}
+	}
+
+	if r := v.Check("abc", Match{}).Key("zero"); r.Ok {
+		t.Error("zero Match passed")
+	}
+}
+
+func TestAtLeastSumAndProduct(t *testing.T) {
+	cases := []struct {
+		check Validator
+		obj   interface{}
+		want  bool
+	}{
+		{AtLeastSum{[]float64{0.1, 0.2}}, 0.3, true},
+		{AtLeastSum{[]float64{10, 5}}, 14.99, false},
+		{AtLeastSum{}, 0.0, true},
+		{AtLeastSum{[]float64{1}}, 1, false},
+		{AtLeastProduct{[]float64{3, 2.5}}, 7.5, true},
+		{AtLeastProduct{[]float64{3, 2.5}}, 7.4, false},
+		{AtLeastProduct{}, 1.0, true},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.obj); got != c.want {
+			t.Errorf("%#v on %#v = %v", c.check, c.obj, got)
+		}
+	}
+
+	v := &Validation{}
+	v.AtLeastSumOf("total", 29.5, 10, 20)
+	v.AtLeastProductOf("area", 12, 3, 4)
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["total"].Message != "Minimum is 30" {
+		t.Errorf("errors %v", errs)
+	}
+}
+
+func TestBusinessHours(t *testing.T) {
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	tokyo := time.FixedZone("JST", 9*3600)
+	cases := []struct {
+		check BusinessHours
+		t     time.Time
+		want  bool
+	}{
+		// 14 October 2026 is a Wednesday.
+		{BusinessHours{9, 17, weekdays}, time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), true},
+		{BusinessHours{9, 17, weekdays}, time.Date(2026, 10, 14, 16, 59, 0, 0, time.UTC), true},
+		{BusinessHours{9, 17, weekdays}, time.Date(2026, 10, 14, 17, 0, 0, 0, time.UTC), false},
+		{BusinessHours{9, 17, weekdays}, time.Date(2026, 10, 14, 8, 59, 0, 0, time.UTC), false},
+		{BusinessHours{9, 17, weekdays}, time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC), false},
+		{BusinessHours{9, 17, nil}, time.Date(2026, 10, 17, 10, 0, 0, 0, time.UTC), true},
+		{BusinessHours{9, 17, nil}, time.Date(2026, 10, 14, 1, 0, 0, 0, time.UTC).In(tokyo), true},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.t); got != c.want {
+			t.Errorf("%v on %v = %v", c.check, c.t, got)
+		}
+	}
+
+	if (BusinessHours{0, 24, nil}).IsSatisfied("09:00") {
+		t.Error("a string is within business hours")
+	}
+
+	v := &Validation{}
+	r := v.BusinessHours(time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC), 9, 17, time.Monday, time.Friday).Key("at")
+	if r.Ok || r.Error.Message != "Must be between 09:00 and 17:00 on Monday, Friday" {
+		t.Errorf("BusinessHours = %v", r.Error)
+	}
+
+	if msg := (BusinessHours{8, 12, nil}).DefaultMessage(); msg != "Must be between 08:00 and 12:00" {
+		t.Errorf("message %q", msg)
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	type payload struct {
+		Version int
+		Kind    string
+		secret  string
+	}
+
+	check := SchemaVersion{"Version", []string{"1", "2"}}
+	cases := []struct {
+		obj  interface{}
+		want bool
+	}{
+		{payload{Version: 2}, true},
+		{&payload{Version: 1}, true},
+		{payload{Version: 3}, false},
+		{(*payload)(nil), false},
+		{interface{}(nil), false},
+		{map[string]int{"Version": 1}, false},
+	}
+
+	for _, c := range cases {
+		if got := check.IsSatisfied(c.obj); got != c.want {
+			t.Errorf("SchemaVersion on %#v = %v", c.obj, got)
+		}
+	}
+
+	for _, field := range []string{"Missing", "secret"} {
+		if (SchemaVersion{field, []string{""}}).IsSatisfied(payload{}) {
+			t.Errorf("field %s is supported", field)
+		}
+	}
+
+	v := &Validation{}
+	if r := v.SchemaVersion(payload{Kind: "v3"}, "Kind", "v1", "v2"); r.Ok || r.MessageKey() != "Kind" || r.Error.Message != "Supported versions are v1, v2" {
+		t.Errorf("SchemaVersion = %v", r.Error)
+	}
+}
+
+func TestNoNilElements(t *testing.T) {
+	var nilPtr *int
+	one := 1
+	cases := []struct {
+		obj  interface{}
+		want bool
+	}{
+		{[]*int{&one, &one}, true},
+		{[]*int{}, true},
+		{[]*int{&one, nil}, false},
+		{[]interface{}{1, nilPtr}, false},
+		{[]interface{}{1, nil}, false},
+		{[2]map[string]int{{}, nil}, false},
+		{[]int{0, 0}, true},
+		{"not a slice", false},
+	}
+
+	for _, c := range cases {
+		if got := (NoNilElements{}).IsSatisfied(c.obj); got != c.want {
+			t.Errorf("NoNilElements on %#v = %v", c.obj, got)
+		}
+	}
+
+	v := &Validation{}
+	if r := v.NoNilElements([]error{nil}).Key("errs"); r.Ok || r.Error.Message != "Element 0 must not be nil" {
+		t.Errorf("NoNilElements = %v", r.Error)
+	}
+
+	if r := v.NoNilElements(3).Key("n"); r.Ok || r.Error.Message != "Must not contain nil elements" {
+		t.Errorf("NoNilElements = %v", r.Error)
+	}
+}
+
+func TestFitsInt(t *testing.T) {
+	cases := []struct {
+		bits int
+		str  string
+		want bool
+	}{
+		{64, "9223372036854775807", true},
+		{64, "9223372036854775808", false},
+		{64, "-9223372036854775808", true},
+		{32, "2147483647", true},
+		{32, "2147483648", false},
+		{32, "-2147483648", true},
+		{32, "12.5", false},
+		{32, "", false},
+	}
+
+	for _, c := range cases {
+		if got := (FitsInt{c.bits}).IsSatisfied(c.str); got != c.want {
+			t.Errorf("FitsInt{%d} on %q = %v", c.bits, c.str, got)
+		}
+	}
+
+	v := &Validation{}
+	v.FitsInt64("4294967296").Key("big")
+	if r := v.FitsInt32("4294967296").Key("small"); r.Ok || r.Error.Message != "Must be an integer within the 32-bit range" {
+		t.Errorf("FitsInt32 = %v", r.Error)
+	}
+
+	if len(v.Errors) != 1 {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
+
+func TestJWTStructure(t *testing.T) {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1234567890"}`))
+	cases := map[string]bool{
+		header + "." + payload + ".c2lnbmF0dXJl": true,
+		header + "." + payload + ".":             true,
+		header + "." + payload:                   false,
+		header + "." + payload + ".sig.extra":    false,
+		header + "." + payload + ".c2ln+/==":     false,
+		header + ".WzEsMl0.c2ln":                 false,
+		"bm90IGpzb24." + payload + ".c2ln":       false,
+		"":                                       false,
+	}
+
+	for str, want := range cases {
+		if got := (JWTStructure{}).IsSatisfied(str); got != want {
+			t.Errorf("JWTStructure on %q = %v", str, got)
+		}
+	}
+
+	v := &Validation{}
+	if r := v.JWTStructure("a.b.c").Key("token"); r.Ok || r.Error.Message != "Must be a well-formed token" {
+		t.Errorf("JWTStructure = %v", r.Error)
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	start := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		check DateRange
+		end   interface{}
+		want  bool
+	}{
+		{DateRange{start, false}, start.Add(time.Hour), true},
+		{DateRange{start, false}, start, true},
+		{DateRange{start, true}, start, false},
+		{DateRange{start, true}, start.Add(time.Nanosecond), true},
+		{DateRange{start, false}, start.Add(-time.Hour), false},
+		{DateRange{start, false}, time.Time{}, false},
+		{DateRange{time.Time{}, false}, start, false},
+		{DateRange{start, false}, "2026-10-15", false},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.end); got != c.want {
+			t.Errorf("%+v on %v = %v", c.check, c.end, got)
+		}
+	}
+
+	v := &Validation{}
+	v.DateRange("same", start, start)
+	v.StrictDateRange("strict", start, start)
+	if errs := v.ErrorMap(); len(errs) != 1 || errs["strict"].Message != "End must be after start" {
+		t.Errorf("errors %v", errs)
+	}
+}
+
+func TestPasswordNotCommon(t *testing.T) {
+	common := map[string]bool{"password": true, "123456": true}
+	v := &Validation{}
+	v.PasswordNotCommon("PassWord", common).Key("upper")
+	v.PasswordNotCommon("123456", common).Key("digits")
+	v.PasswordNotCommon("correct horse", common).Key("fine")
+	v.PasswordNotCommon("anything", nil).Key("no list")
+
+	if errs := v.ErrorMap(); len(errs) != 2 || errs["upper"].Message != "Password is too common" || errs["digits"] == nil {
+		t.Errorf("errors %v", errs)
+	}
+
+	if (PasswordNotCommon{common}).IsSatisfied([]byte("fine")) {
+		t.Error("a []byte passes")
+	}
+}
+
+func TestFormats(t *testing.T) {
+	cases := []struct {
+		check Validator
+		str   string
+		want  bool
+	}{
+		{URL{}, "https://example.com/a?b=c", true},
+		{URL{}, "mailto:someone@example.com", false},
+		{URL{}, "/relative/path", false},
+		{URL{}, "example.com", false},
+		{IPv4{}, "192.168.0.1", true},
+		{IPv4{}, "256.1.1.1", false},
+		{IPv4{}, "::ffff:192.168.0.1", false},
+		{IPv6{}, "2001:db8::1", true},
+		{IPv6{}, "::ffff:192.168.0.1", true},
+		{IPv6{}, "192.168.0.1", false},
+		{IPv6{}, "2001:db8:::1", false},
+		{UUID{}, "123e4567-E89B-12d3-a456-426614174000", true},
+		{UUID{}, "123e4567e89b12d3a456426614174000", false},
+		{UUID{}, "", false},
+		{UUID{Match{regexp.MustCompile(`^[0-9a-f]{32}$`)}}, "123e4567e89b12d3a456426614174000", true},
+		{Phone{}, "+14155550100", true},
+		{Phone{}, "4155550100", false},
+		{Phone{"us"}, "(415) 555-0100", true},
+		{Phone{"US"}, "+1 415.555.0100", true},
+		{Phone{"US"}, "(115) 555-0100", false},
+		{Phone{"CN"}, "+86 13812345678", true},
+		{Phone{"CN"}, "010-12345678", true},
+		{Phone{"CN"}, "12812345678", false},
+		{Phone{"GB"}, "020 7946 0958", true},
+		{Phone{"GB"}, "+44 20 7946 0958", true},
+		{Phone{"ZZ"}, "+442079460958", true},
+		{Phone{"ZZ"}, "020 7946 0958", false},
+	}
+
+	for _, c := range cases {
+		if got := c.check.IsSatisfied(c.str); got != c.want {
+			t.Errorf("%T%+v on %q = %v", c.check, c.check, c.str, got)
+		}
+	}
+
+	v := &Validation{}
+	if v.Check("x", UUID{}).Key("id").Ok || !v.Check("123e4567-e89b-12d3-a456-426614174000", UUID{}).Ok {
+		t.Errorf("wrong answers from UUID{}: %v", v.Errors)
+	}
+	v.Clear()
+
+	v.URL("ftp://files.example.com").Key("url")
+	v.IPv4("10.0.0.256").Key("ipv4")
+	v.IPv6("fe80::1").Key("ipv6")
+	v.UUID("not-a-uuid").Key("uuid")
+	v.Phone(" +14155550100 ", "").Key("phone")
+
+	errs := v.ErrorMap()
+	if len(errs) != 2 || errs["ipv4"].Message != "Must be a valid IPv4 address" || errs["uuid"].Message != "Must be a valid UUID" {
+		t.Errorf("errors %v", v.Errors)
+	}
+}
@@ -2,7 +2,7 @@ package web
 
 import (
 	"golanger.com/config"
-	"golanger.com/log"
+	"golanger.com/framework/log"
 	"os"
 )
 
@@ -47,6 +47,7 @@ type Config struct {
 	Environment               map[string]string      `json:"Environment"`
 	Database                  map[string]string      `json:"Database"`
 	UrlManageRule             []string               `json:"UrlManageRule"`
+	TrustedProxies            []string               `json:"TrustedProxies"`
 	M                         map[string]interface{} `json:"Custom"`
 	configDir                 string
 	configLastModTime         int64
@@ -0,0 +1,98 @@
+package web
+
+import (
+	"context"
+	"golanger.com/framework/log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// The environment variable telling a restarted server which of its file
+// descriptors is the listening socket it inherited.
+const listenFDEnv = "GOLANGER_LISTEN_FD"
+
+// Listen on addr, or on the socket inherited from the process that
+// restarted this one.
+func listen(addr string) (net.Listener, error) {
+	if v := os.Getenv(listenFDEnv); v != "" {
+		os.Unsetenv(listenFDEnv)
+		fd, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+
+		f := os.NewFile(uintptr(fd), "listener")
+		defer f.Close()
+
+		return net.FileListener(f)
+	}
+
+	lc := net.ListenConfig{Control: reusePort}
+
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// Like ListenAndServe, but on SIGTERM or an interrupt stops accepting
+// connections and waits up to ShutdownTimeout for the requests in flight
+// before returning.
+//
+// On Unix the socket is opened with SO_REUSEPORT, so that a new version of
+// the server can start on the same address before the old one stops, and
+// SIGUSR2 restarts the server in place: the executable is started again
+// with the listening socket passed to it, and this process shuts down
+// gracefully once it has started, so that no connection is refused.
+func (p *Page) RunGraceful(addr string, i interface{}) {
+	p.mount(i)
+
+	ln, err := listen(addr)
+	if err != nil {
+		log.Fatal("<Page.RunGraceful> ", err)
+	}
+
+	srv := &http.Server{}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, append([]os.Signal{os.Interrupt, syscall.SIGTERM}, restartSignals...)...)
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case err := <-errc:
+			if err != http.ErrServerClosed {
+				log.Fatal("<Page.RunGraceful> ", err)
+			}
+
+			return
+		case sig := <-sigs:
+			if sig != os.Interrupt && sig != syscall.SIGTERM {
+				if err := restart(ln); err != nil {
+					log.Error("<Page.RunGraceful> ", "restart failed:", err)
+					continue
+				}
+
+				log.Info("<Page.RunGraceful> ", "restarted, draining requests")
+			} else {
+				log.Info("<Page.RunGraceful> ", sig, ", draining requests")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), p.ShutdownTimeout)
+			if err := srv.Shutdown(ctx); err != nil {
+				log.Error("<Page.RunGraceful> ", "shutdown:", err)
+				srv.Close()
+			}
+
+			cancel()
+			log.Flush()
+
+			return
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package web
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+var restartSignals = []os.Signal{}
+
+var reusePort func(network, address string, c syscall.RawConn) error
+
+func restart(ln net.Listener) error {
+	return errors.New("web: restart is not supported on this platform")
+}
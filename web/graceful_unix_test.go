@@ -0,0 +1,82 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package web
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestListenReusesPort(t *testing.T) {
+	old, err := listen("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+
+	// The next version of the server starts while the old one still
+	// listens on the address.
+	ln, err := listen(old.Addr().String())
+	if err != nil {
+		t.Fatalf("second listener on %s: %v", old.Addr(), err)
+	}
+
+	ln.Close()
+}
+
+func TestListenInherited(t *testing.T) {
+	parent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer parent.Close()
+
+	f, err := parent.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// listen closes the descriptor it is given, so give it a copy.
+	fd, err := syscall.Dup(int(f.Fd()))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(listenFDEnv, strconv.Itoa(fd))
+	ln, err := listen("127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	if ln.Addr().String() != parent.Addr().String() {
+		t.Errorf("listening on %s, not the inherited %s", ln.Addr(), parent.Addr())
+	}
+
+	if os.Getenv(listenFDEnv) != "" {
+		t.Error("the variable is passed on to children")
+	}
+
+	go func() {
+		if c, err := net.Dial("tcp", ln.Addr().String()); err == nil {
+			c.Close()
+		}
+	}()
+
+	c, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Close()
+
+	t.Setenv(listenFDEnv, "x")
+	if _, err := listen("127.0.0.1:0"); err == nil {
+		t.Error("no error for a bad descriptor number")
+	}
+}
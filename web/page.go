@@ -3,13 +3,18 @@ package web
 import (
 	"bytes"
 	"fmt"
+	"golanger.com/framework/health"
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
+	"golanger.com/framework/proxy"
+	"golanger.com/framework/request"
+	"golanger.com/framework/static"
+	"golanger.com/framework/validator"
 	"golanger.com/i18n"
-	"golanger.com/log"
 	"golanger.com/session/cookiesession"
 	"golanger.com/session/filesession"
 	"golanger.com/session/memorysession"
 	"golanger.com/urlmanage"
-	"golanger.com/validate"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -45,8 +50,9 @@ type Page struct {
 	FileSession         *filesession.SessionManager
 	CookieSession       *cookiesession.SessionManager
 	I18n                *i18n.I18nManager
-	Validation          validate.Validation
+	Validation          validator.Validation
 	UrlManage           *urlmanage.UrlManage
+	ShutdownTimeout     time.Duration
 	currentPath         string
 	currentFileName     string
 }
@@ -60,6 +66,7 @@ type PageParam struct {
 	I18nName          string
 	Expires           int
 	TimerDuration     string
+	ShutdownTimeout   time.Duration
 }
 
 func NewPage(param PageParam) Page {
@@ -67,6 +74,10 @@ func NewPage(param PageParam) Page {
 		param.MaxFormSize = 2 << 20 // 2MB => 2的20次方 乘以 2 =》 2 * 1024 * 1024
 	}
 
+	if param.ShutdownTimeout <= 0 {
+		param.ShutdownTimeout = 30 * time.Second
+	}
+
 	return Page{
 		site: &site{
 			base: &base{
@@ -85,12 +96,13 @@ func NewPage(param PageParam) Page {
 			Img:  map[string]string{},
 			Func: template.FuncMap{},
 		},
-		MAX_FORM_SIZE: param.MaxFormSize,
-		MemorySession: memorysession.New(param.CookieName, param.Expires, param.TimerDuration),
-		FileSession:   filesession.New(param.CookieName, param.Expires, param.SessionDir, param.TimerDuration),
-		CookieSession: cookiesession.New(param.CookieSessionName, param.CookieSessionKey),
-		I18n:          i18n.New(param.I18nName),
-		UrlManage:     urlmanage.New(),
+		MAX_FORM_SIZE:   param.MaxFormSize,
+		MemorySession:   memorysession.New(param.CookieName, param.Expires, param.TimerDuration),
+		FileSession:     filesession.New(param.CookieName, param.Expires, param.SessionDir, param.TimerDuration),
+		CookieSession:   cookiesession.New(param.CookieSessionName, param.CookieSessionKey),
+		I18n:            i18n.New(param.I18nName),
+		UrlManage:       urlmanage.New(),
+		ShutdownTimeout: param.ShutdownTimeout,
 	}
 }
 
@@ -124,6 +136,8 @@ func (p *Page) initModule(w http.ResponseWriter, r *http.Request) {
 			delete(p.SESSION, "__ONCE")
 			log.Debug("<Page.Init> ", `p.SESSION["__ONCE"] to delete:`, p.SESSION["__ONCE"])
 		}
+
+		p.Validation.RestoreFrom(p.SESSION)
 	}
 
 	if p.site.supportCookieSession {
@@ -243,6 +257,9 @@ func (p *Page) setGlobalTpl(globalTplModTime int64, reset bool) {
 func (p *Page) reset(update bool) {
 	p.setLog(p.Config.SupportLog, p.Config.LogWriteTo, p.Config.LogLevel)
 	p.setUrlManage(p.Config.SupportUrlManage, p.Config.SupportUrlManageWithCache, p.Config.UrlManageRule)
+	if err := request.SetTrustedProxies(p.Config.TrustedProxies...); err != nil {
+		log.Error("<Page.reset> ", "TrustedProxies:", err)
+	}
 
 	if update {
 		if p.site.supportSession != p.Config.SupportSession {
@@ -354,8 +371,11 @@ func (p *Page) setLog(hasLog bool, writeTo, level string) {
 		}
 
 		switch writeTo {
+		case "json":
+			log.SetHandler(log.NewJSONHandler(os.Stderr))
 		default:
 			//包括console
+			log.SetHandler(log.NewConsoleHandler(false))
 		}
 	}
 }
@@ -573,6 +593,8 @@ func (p *Page) routeController(i interface{}, w http.ResponseWriter, r *http.Req
 	}
 
 	if ppc.site.supportSession {
+		ppc.Validation.SaveTo(ppc.SESSION)
+
 		switch ppc.Config.SessionType {
 		case "file":
 			ppc.FileSession.Set(ppc.SESSION, w, r)
@@ -698,6 +720,8 @@ func (p *Page) routeTemplate(w http.ResponseWriter, r *http.Request) {
 				"CS":       p.COOKIE_SESSION,
 				"D":        p.Document,
 				"L":        p.LANG,
+				"V":        p.Validation.ErrorMap(),
+				"W":        p.Validation.WarningMap(),
 				"Config":   p.Config.M,
 				"Template": p.Template,
 			}
@@ -800,10 +824,76 @@ func (p *Page) checkHtmlDoWrite(tplFi, htmlFi os.FileInfo, htmlErr error) bool {
 	return doWrite
 }
 
+// Add middleware around every handler the page serves, in order, the first
+// being the outermost.  It must be called before ListenAndServe.
+func (p *Page) Use(middlewares ...middleware.Middleware) *Page {
+	p.site.middlewares = append(p.site.middlewares, middlewares...)
+
+	return p
+}
+
+// Serve the files of dir under the URL path prefix, e.g.
+// p.Static("/assets", "./assets"), with the caching headers and compressed
+// variants of the static package.  It must be called before ListenAndServe.
+func (p *Page) Static(prefix, dir string, opts ...static.Options) *Page {
+	if p.site.statics == nil {
+		p.site.statics = map[string]http.Handler{}
+	}
+
+	prefix = "/" + strings.Trim(prefix, "/")
+	p.site.statics[prefix] = http.StripPrefix(prefix, static.New(dir, opts...))
+
+	return p
+}
+
+// Serve the JSON report of the health checks at path, e.g.
+// p.HealthCheck("/healthz") for liveness and p.HealthCheck("/readyz",
+// health.DB("db", db)) for readiness.  The probes bypass the middleware
+// of Use, so that sessions, CSRF or rate limits do not get in their way.
+// It must be called before ListenAndServe.
+func (p *Page) HealthCheck(path string, checks ...health.Check) *Page {
+	if p.site.probes == nil {
+		p.site.probes = map[string]http.Handler{}
+	}
+
+	p.site.probes["/"+strings.Trim(path, "/")] = health.Handler(checks...)
+
+	return p
+}
+
+// Forward the requests under the URL path prefix, without it, to the
+// target URL, e.g. p.Proxy("/api/legacy", "http://10.0.0.5:8080"), through
+// the middleware of Use.  It must be called before ListenAndServe.
+func (p *Page) Proxy(prefix, target string, opts ...proxy.Options) *Page {
+	if p.site.proxies == nil {
+		p.site.proxies = map[string]http.Handler{}
+	}
+
+	var o proxy.Options
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+
+	prefix = "/" + strings.Trim(prefix, "/")
+	o.Prefix = prefix
+	h, err := proxy.New(target, o)
+	if err != nil {
+		log.Panic("<Page.Proxy> ", err)
+	}
+
+	p.site.proxies[prefix] = h
+
+	return p
+}
+
+func (p *Page) handleFunc(pattern string, f func(http.ResponseWriter, *http.Request)) {
+	http.Handle(pattern, middleware.Wrap(http.HandlerFunc(f), p.site.middlewares...))
+}
+
 func (p *Page) handleRootStatic(files string) {
 	aFile := strings.Split(files, ",")
 	for _, file := range aFile {
-		http.HandleFunc(p.site.Root+file, func(w http.ResponseWriter, r *http.Request) {
+		p.handleFunc(p.site.Root+file, func(w http.ResponseWriter, r *http.Request) {
 			staticPath := p.Config.AssetsDirectory + file
 			log.Debug("<Page.handleRootStatic> ", "staticPath:", staticPath)
 			http.ServeFile(w, r, staticPath)
@@ -812,7 +902,7 @@ func (p *Page) handleRootStatic(files string) {
 }
 
 func (p *Page) handleStatic() {
-	http.HandleFunc(p.Document.Static, func(w http.ResponseWriter, r *http.Request) {
+	p.handleFunc(p.Document.Static, func(w http.ResponseWriter, r *http.Request) {
 		if p.UrlManage.Manage() {
 			newUrl := p.UrlManage.ReWrite(w, r)
 			if newUrl == "redirect" {
@@ -831,7 +921,7 @@ func (p *Page) handleStatic() {
 
 func (p *Page) handleStaticHtml() {
 	StaticHtmlDir := p.Config.SiteRoot + p.Config.HtmlDirectory
-	http.HandleFunc(StaticHtmlDir, func(w http.ResponseWriter, r *http.Request) {
+	p.handleFunc(StaticHtmlDir, func(w http.ResponseWriter, r *http.Request) {
 		if p.UrlManage.Manage() {
 			newUrl := p.UrlManage.ReWrite(w, r)
 			if newUrl == "redirect" {
@@ -853,7 +943,7 @@ func (p *Page) handleStaticHtml() {
 }
 
 func (p *Page) handleRoute(i interface{}) {
-	http.HandleFunc(p.site.Root, func(w http.ResponseWriter, r *http.Request) {
+	p.handleFunc(p.site.Root, func(w http.ResponseWriter, r *http.Request) {
 		p.site.base.mutex.Lock()
 		if p.Config.Reload() {
 			p.reset(true)
@@ -886,7 +976,8 @@ func (p *Page) handleRoute(i interface{}) {
 	})
 }
 
-func (p *Page) ListenAndServe(addr string, i interface{}) {
+// Register the handlers of the page on http.DefaultServeMux.
+func (p *Page) mount(i interface{}) {
 	if p.Config.SupportStatic {
 		p.handleRootStatic(p.Config.RootStaticFiles)
 		p.handleStatic()
@@ -896,7 +987,24 @@ func (p *Page) ListenAndServe(addr string, i interface{}) {
 		p.handleStaticHtml()
 	}
 
+	for prefix, h := range p.site.statics {
+		p.handleFunc(prefix+"/", h.ServeHTTP)
+	}
+
+	for prefix, h := range p.site.proxies {
+		p.handleFunc(prefix, h.ServeHTTP)
+		p.handleFunc(prefix+"/", h.ServeHTTP)
+	}
+
+	for path, h := range p.site.probes {
+		http.Handle(path, h)
+	}
+
 	p.handleRoute(i)
+}
+
+func (p *Page) ListenAndServe(addr string, i interface{}) {
+	p.mount(i)
 
 	err := http.ListenAndServe(addr, nil)
 	if err != nil {
@@ -0,0 +1,10 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd || (linux && !386 && !amd64 && !arm)
+// +build darwin dragonfly freebsd netbsd openbsd linux,!386,!amd64,!arm
+
+package web
+
+import (
+	"syscall"
+)
+
+const soReusePort = syscall.SO_REUSEPORT
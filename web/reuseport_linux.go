@@ -0,0 +1,8 @@
+//go:build linux && (386 || amd64 || arm)
+// +build linux
+// +build 386 amd64 arm
+
+package web
+
+// syscall does not define SO_REUSEPORT on these.
+const soReusePort = 0xf
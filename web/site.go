@@ -1,7 +1,8 @@
 package web
 
 import (
-	"golanger.com/log"
+	"golanger.com/framework/log"
+	"golanger.com/framework/middleware"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -21,6 +22,10 @@ type site struct {
 	templateFunc         template.FuncMap
 	templateCache        map[string]templateCache
 	globalTemplate       *template.Template
+	middlewares          []middleware.Middleware
+	statics              map[string]http.Handler
+	probes               map[string]http.Handler
+	proxies              map[string]http.Handler
 	Root                 string
 	Version              string
 }
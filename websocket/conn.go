@@ -0,0 +1,270 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"golanger.com/framework/session"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Message types, the opcodes of RFC 6455.
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	CloseMessage  = 8
+	PingMessage   = 9
+	PongMessage   = 10
+)
+
+// Close codes.
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+	continuationFrame    = 0
+	maxControlPayloadLen = 125
+)
+
+var (
+	ErrClosed    = errors.New("websocket: connection closed")
+	ErrReadLimit = errors.New("websocket: message exceeds the read limit")
+)
+
+// The error ReadMessage returns once the peer has closed the connection.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return "websocket: closed with code " + strconv.Itoa(e.Code) + " " + e.Text
+}
+
+// A Conn is a WebSocket connection.  One goroutine may read from it while
+// another writes to it.
+type Conn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	wmutex      sync.Mutex
+	closeSent   bool
+	readLimit   int64
+	onPong      func()
+	Request     *http.Request
+	Session     *session.Session
+	Subprotocol string
+}
+
+// Set the largest message ReadMessage accepts.  A longer one closes the
+// connection with CloseMessageTooBig.
+func (c *Conn) SetReadLimit(limit int64) {
+	c.readLimit = limit
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
+
+// Call f, from ReadMessage, whenever a pong arrives.
+func (c *Conn) SetPongHandler(f func()) {
+	c.onPong = f
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
+
+// Read one frame, unmasking its payload.
+func (c *Conn) readFrame() (fin bool, op int, payload []byte, err error) {
+	var h [2]byte
+	if _, err = io.ReadFull(c.br, h[:]); err != nil {
+		return
+	}
+
+	fin = h[0]&0x80 != 0
+	op = int(h[0] & 0x0f)
+	if h[0]&0x70 != 0 || h[1]&0x80 == 0 {
+		// Extensions are not negotiated, and clients must mask.
+		return fin, op, nil, c.fail(CloseProtocolError, "bad frame header")
+	}
+
+	n := int64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		if _, err = io.ReadFull(c.br, b[:]); err != nil {
+			return
+		}
+
+		n = int64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		if _, err = io.ReadFull(c.br, b[:]); err != nil {
+			return
+		}
+
+		n = int64(binary.BigEndian.Uint64(b[:]))
+		if n < 0 {
+			return fin, op, nil, c.fail(CloseProtocolError, "bad frame length")
+		}
+	}
+
+	if op >= CloseMessage && (!fin || n > maxControlPayloadLen) {
+		return fin, op, nil, c.fail(CloseProtocolError, "bad control frame")
+	}
+
+	if c.readLimit > 0 && n > c.readLimit {
+		return fin, op, nil, c.fail(CloseMessageTooBig, "")
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, op, payload, nil
+}
+
+// Send a close frame with code and return an error describing it.
+func (c *Conn) fail(code int, text string) error {
+	c.WriteClose(code, text)
+	if code == CloseMessageTooBig {
+		return ErrReadLimit
+	}
+
+	return errors.New("websocket: protocol error: " + text)
+}
+
+// Read the next text or binary message.  Pings are answered and pongs
+// handed to the pong handler on the way.  Once the peer closes the
+// connection, the close is answered and a *CloseError returned.
+func (c *Conn) ReadMessage() (messageType int, data []byte, err error) {
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case PingMessage:
+			if err := c.WriteMessage(PongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+
+			continue
+		case PongMessage:
+			if c.onPong != nil {
+				c.onPong()
+			}
+
+			continue
+		case CloseMessage:
+			ce := &CloseError{Code: CloseNoStatus}
+			if len(payload) >= 2 {
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Text = string(payload[2:])
+			}
+
+			c.WriteClose(CloseNormal, "")
+
+			return 0, nil, ce
+		case continuationFrame:
+			if messageType == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		case TextMessage, BinaryMessage:
+			if messageType != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unfinished message")
+			}
+
+			messageType = op
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		data = append(data, payload...)
+		if c.readLimit > 0 && int64(len(data)) > c.readLimit {
+			return 0, nil, c.fail(CloseMessageTooBig, "")
+		}
+
+		if fin {
+			if messageType == TextMessage && !utf8.Valid(data) {
+				return 0, nil, c.fail(CloseInvalidPayload, "invalid UTF-8")
+			}
+
+			return messageType, data, nil
+		}
+	}
+}
+
+// Write a message in one frame.  Nothing can be written after a close
+// frame.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	c.wmutex.Lock()
+	defer c.wmutex.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+
+	if messageType == CloseMessage {
+		c.closeSent = true
+	}
+
+	n := len(data)
+	frame := make([]byte, 0, n+10)
+	frame = append(frame, 0x80|byte(messageType))
+	switch {
+	case n <= 125:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	_, err := c.conn.Write(append(frame, data...))
+
+	return err
+}
+
+// Send a close frame, once.
+func (c *Conn) WriteClose(code int, text string) error {
+	payload := binary.BigEndian.AppendUint16(nil, uint16(code))
+	if len(text) > maxControlPayloadLen-2 {
+		text = text[:maxControlPayloadLen-2]
+	}
+
+	return c.WriteMessage(CloseMessage, append(payload, text...))
+}
+
+// Send a normal close frame, if none was sent, and close the connection.
+func (c *Conn) Close() error {
+	c.SetWriteDeadline(time.Now().Add(time.Second))
+	c.WriteClose(CloseNormal, "")
+
+	return c.conn.Close()
+}
@@ -0,0 +1,223 @@
+package websocket
+
+import (
+	"golanger.com/framework/log"
+	"golanger.com/framework/session"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The timing of the pumps: how long a write may take, how long a client
+// may stay silent before it is dropped, and how often it is pinged (more
+// often than PongWait, so that a live client always answers in time).
+var (
+	WriteWait  = 10 * time.Second
+	PongWait   = 60 * time.Second
+	PingPeriod = PongWait * 9 / 10
+
+	// The messages queued for a client before it is considered too slow
+	// and disconnected.
+	SendQueue = 256
+)
+
+type message struct {
+	typ  int
+	data []byte
+}
+
+// A Client is a connection served by a Hub, with a queue of messages
+// written out by its write pump.
+type Client struct {
+	Conn   *Conn
+	hub    *Hub
+	mutex  sync.Mutex
+	send   chan message
+	closed bool
+}
+
+// Return the session the connection was opened with, or nil.
+func (c *Client) Session() *session.Session {
+	return c.Conn.Session
+}
+
+// Queue a message for the client.  A client whose queue is full is
+// disconnected and false returned.
+func (c *Client) Send(messageType int, data []byte) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.closed {
+		return false
+	}
+
+	select {
+	case c.send <- message{messageType, data}:
+		return true
+	default:
+		c.closeLocked()
+		return false
+	}
+}
+
+// Stop the write pump, which then closes the connection.
+func (c *Client) Close() {
+	c.mutex.Lock()
+	c.closeLocked()
+	c.mutex.Unlock()
+}
+
+func (c *Client) closeLocked() {
+	if !c.closed {
+		c.closed = true
+		close(c.send)
+	}
+}
+
+// Read messages until the connection fails or closes, handing them to the
+// hub.  Every message or pong extends the deadline by PongWait.
+func (c *Client) readPump() {
+	c.Conn.SetReadDeadline(time.Now().Add(PongWait))
+	c.Conn.SetPongHandler(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(PongWait))
+	})
+
+	for {
+		typ, data, err := c.Conn.ReadMessage()
+		if err != nil {
+			if _, ok := err.(*CloseError); !ok {
+				log.FromRequest(c.Conn.Request).Debug("<websocket.Client.readPump> ", err)
+			}
+
+			return
+		}
+
+		c.Conn.SetReadDeadline(time.Now().Add(PongWait))
+		if c.hub.OnMessage != nil {
+			c.hub.OnMessage(c, typ, data)
+		}
+	}
+}
+
+// Write the queued messages, pinging the client every PingPeriod, until
+// the queue is closed.
+func (c *Client) writePump() {
+	ticker := time.NewTicker(PingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			if !ok {
+				return
+			}
+
+			c.Conn.SetWriteDeadline(time.Now().Add(WriteWait))
+			if err := c.Conn.WriteMessage(msg.typ, msg.data); err != nil {
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(WriteWait))
+			if err := c.Conn.WriteMessage(PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+		}
+	}
+}
+
+// A Hub keeps track of the connected clients, so that messages can be
+// broadcast to all or some of them.
+type Hub struct {
+	mutex   sync.RWMutex
+	clients map[*Client]bool
+
+	// Called when a client connects, before its messages are read, and
+	// when it has gone.
+	OnConnect    func(c *Client)
+	OnDisconnect func(c *Client)
+
+	// Called, from the read pump of the client, for each message.
+	OnMessage func(c *Client, messageType int, data []byte)
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		clients: map[*Client]bool{},
+	}
+}
+
+// Serve a connection until it closes: register it as a Client, start its
+// write pump and run its read pump.
+func (h *Hub) Serve(conn *Conn) {
+	c := &Client{Conn: conn, hub: h, send: make(chan message, SendQueue)}
+	h.mutex.Lock()
+	h.clients[c] = true
+	h.mutex.Unlock()
+
+	go c.writePump()
+	if h.OnConnect != nil {
+		h.OnConnect(c)
+	}
+
+	c.readPump()
+
+	h.mutex.Lock()
+	delete(h.clients, c)
+	h.mutex.Unlock()
+	c.Close()
+	if h.OnDisconnect != nil {
+		h.OnDisconnect(c)
+	}
+}
+
+// Return a handler that upgrades requests with u, or DefaultUpgrader if
+// u is nil, and serves them.
+func (h *Hub) Handler(u *Upgrader) http.Handler {
+	if u == nil {
+		u = DefaultUpgrader
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := u.Upgrade(w, r)
+		if err != nil {
+			log.FromRequest(r).Debug("<websocket.Hub.Handler> ", err)
+			return
+		}
+
+		h.Serve(conn)
+	})
+}
+
+// Queue a message for every client.
+func (h *Hub) Broadcast(messageType int, data []byte) {
+	h.BroadcastFilter(nil, messageType, data)
+}
+
+// Queue a message for the clients for which filter returns true, or all
+// of them if filter is nil, e.g. those of the sessions of one user.
+func (h *Hub) BroadcastFilter(filter func(c *Client) bool, messageType int, data []byte) {
+	h.mutex.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for c := range h.clients {
+		if filter == nil || filter(c) {
+			clients = append(clients, c)
+		}
+	}
+	h.mutex.RUnlock()
+
+	for _, c := range clients {
+		c.Send(messageType, data)
+	}
+}
+
+// Return the number of connected clients.
+func (h *Hub) Len() int {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return len(h.clients)
+}
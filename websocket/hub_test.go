@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Wait for cond, failing after a second.
+func eventually(t *testing.T, what string, cond func() bool) {
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHub(t *testing.T) {
+	h := NewHub()
+	connected, gone := make(chan *Client, 2), make(chan *Client, 2)
+	h.OnConnect = func(c *Client) { connected <- c }
+	h.OnDisconnect = func(c *Client) { gone <- c }
+	h.OnMessage = func(c *Client, typ int, data []byte) { h.Broadcast(typ, data) }
+
+	server := httptest.NewServer(h.Handler(nil))
+	defer server.Close()
+
+	a := dial(t, server, nil)
+	ca := <-connected
+	b := dial(t, server, nil)
+	<-connected
+	if h.Len() != 2 || ca.Session() != nil {
+		t.Fatalf("%d clients", h.Len())
+	}
+
+	a.write(true, TextMessage, []byte("hi all"))
+	for _, c := range []*client{a, b} {
+		if op, payload := c.read(); op != TextMessage || string(payload) != "hi all" {
+			t.Errorf("read %d %q", op, payload)
+		}
+	}
+
+	h.BroadcastFilter(func(c *Client) bool { return c == ca }, BinaryMessage, []byte("only a"))
+	if op, payload := a.read(); op != BinaryMessage || string(payload) != "only a" {
+		t.Errorf("read %d %q", op, payload)
+	}
+
+	b.write(true, CloseMessage, []byte{0x03, 0xe8})
+	if code := b.readClose(); code != CloseNormal {
+		t.Errorf("close answered with %d", code)
+	}
+
+	if c := <-gone; c == ca {
+		t.Error("the wrong client is gone")
+	}
+
+	eventually(t, "one client", func() bool { return h.Len() == 1 })
+
+	ca.Close()
+	if code := a.readClose(); code != CloseNormal {
+		t.Errorf("Client.Close sent %d", code)
+	}
+
+	<-gone
+	eventually(t, "no client", func() bool { return h.Len() == 0 })
+}
+
+func TestHubPings(t *testing.T) {
+	defer func(period time.Duration) { PingPeriod = period }(PingPeriod)
+	PingPeriod = 10 * time.Millisecond
+
+	h := NewHub()
+	server := httptest.NewServer(h.Handler(nil))
+	defer server.Close()
+
+	c := dial(t, server, nil)
+	if op, _ := c.read(); op != PingMessage {
+		t.Errorf("read %d, want a ping", op)
+	}
+}
+
+func TestClientSendQueueFull(t *testing.T) {
+	c := &Client{send: make(chan message, 1)}
+	if !c.Send(TextMessage, []byte("a")) {
+		t.Fatal("first message not queued")
+	}
+
+	if c.Send(TextMessage, []byte("b")) || !c.closed {
+		t.Error("slow client not disconnected")
+	}
+
+	if c.Send(TextMessage, []byte("c")) {
+		t.Error("message queued after close")
+	}
+
+	c.Close()
+}
@@ -0,0 +1,162 @@
+package websocket
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"golanger.com/framework/session"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// An Upgrader turns HTTP requests into WebSocket connections.
+type Upgrader struct {
+	// Report whether a request from a browser may connect.  By default
+	// the Origin header, if any, must name the host of the request, so
+	// that other sites cannot open sockets with the user's cookies.
+	CheckOrigin func(r *http.Request) bool
+
+	// Report whether the client of the session, which is nil unless the
+	// session middleware ran, may connect; it is answered 401 otherwise.
+	// Every client may connect if nil.
+	Authorize func(r *http.Request, s *session.Session) bool
+
+	// The subprotocols the server speaks, in order of preference.
+	Subprotocols []string
+
+	// The largest message a connection accepts, 1 MB if zero.
+	ReadLimit int64
+}
+
+var DefaultUpgrader = &Upgrader{}
+
+// Upgrade with DefaultUpgrader.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	return DefaultUpgrader.Upgrade(w, r)
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// Report whether a comma-separated header of r holds token.
+func headerHas(r *http.Request, name, token string) bool {
+	for _, v := range r.Header[name] {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// Complete the WebSocket handshake of r and take over its connection.  On
+// failure, the error response has been written.  The headers already set
+// on w, such as the session cookie, are sent with the handshake.
+func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	fail := func(status int, msg string) (*Conn, error) {
+		http.Error(w, http.StatusText(status), status)
+		return nil, errors.New("websocket: " + msg)
+	}
+
+	if r.Method != "GET" || !headerHas(r, "Connection", "upgrade") || !headerHas(r, "Upgrade", "websocket") {
+		return fail(http.StatusBadRequest, "not a websocket handshake")
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return fail(http.StatusUpgradeRequired, "unsupported version")
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil || len(b) != 16 {
+		return fail(http.StatusBadRequest, "bad Sec-WebSocket-Key")
+	}
+
+	checkOrigin := u.CheckOrigin
+	if checkOrigin == nil {
+		checkOrigin = sameOrigin
+	}
+
+	if !checkOrigin(r) {
+		return fail(http.StatusForbidden, "origin not allowed")
+	}
+
+	s := session.FromRequest(r)
+	if u.Authorize != nil && !u.Authorize(r, s) {
+		return fail(http.StatusUnauthorized, "not authorized")
+	}
+
+	subprotocol := ""
+	for _, p := range u.Subprotocols {
+		if headerHas(r, "Sec-Websocket-Protocol", p) {
+			subprotocol = p
+			break
+		}
+	}
+
+	h, ok := w.(http.Hijacker)
+	if !ok {
+		return fail(http.StatusInternalServerError, "ResponseWriter does not support Hijack")
+	}
+
+	netConn, brw, err := h.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	// Read after Hijack, which is when the session middleware saves.
+	cookies := w.Header()["Set-Cookie"]
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n"
+	if subprotocol != "" {
+		resp += "Sec-WebSocket-Protocol: " + subprotocol + "\r\n"
+	}
+
+	for _, c := range cookies {
+		resp += "Set-Cookie: " + c + "\r\n"
+	}
+
+	netConn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := netConn.Write([]byte(resp + "\r\n")); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	netConn.SetDeadline(time.Time{})
+
+	readLimit := u.ReadLimit
+	if readLimit == 0 {
+		readLimit = 1 << 20
+	}
+
+	return &Conn{
+		conn:        netConn,
+		br:          brw.Reader,
+		readLimit:   readLimit,
+		Request:     r,
+		Session:     s,
+		Subprotocol: subprotocol,
+	}, nil
+}
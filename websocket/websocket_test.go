@@ -0,0 +1,306 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"golanger.com/framework/log"
+	"golanger.com/framework/session"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	log.SetLevel(log.LEVEL_DISABLE)
+}
+
+// The client end of a connection.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+	resp *http.Response
+}
+
+// Open a WebSocket connection to server, sending header with the
+// handshake.
+func dial(t *testing.T, server *httptest.Server, header map[string]string) *client {
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := "GET /ws HTTP/1.1\r\nHost: " + conn.RemoteAddr().String() + "\r\n" +
+		"Connection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"
+	for k, v := range header {
+		req += k + ": " + v + "\r\n"
+	}
+
+	if _, err := conn.Write([]byte(req + "\r\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &client{t, conn, br, resp}
+}
+
+// Write a masked frame, as clients must.
+func (c *client) write(fin bool, op int, payload []byte) {
+	b0 := byte(op)
+	if fin {
+		b0 |= 0x80
+	}
+
+	frame := []byte{b0}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// Read a frame of the server, which is not masked.
+func (c *client) read() (op int, payload []byte) {
+	var h [2]byte
+	if _, err := io.ReadFull(c.br, h[:]); err != nil {
+		c.t.Fatal(err)
+	}
+
+	n := uint64(h[1] & 0x7f)
+	switch n {
+	case 126:
+		var b [2]byte
+		io.ReadFull(c.br, b[:])
+		n = uint64(binary.BigEndian.Uint16(b[:]))
+	case 127:
+		var b [8]byte
+		io.ReadFull(c.br, b[:])
+		n = binary.BigEndian.Uint64(b[:])
+	}
+
+	payload = make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatal(err)
+	}
+
+	return int(h[0] & 0x0f), payload
+}
+
+// Read a close frame and return its code.
+func (c *client) readClose() int {
+	op, payload := c.read()
+	if op != CloseMessage || len(payload) < 2 {
+		c.t.Fatalf("read %d %q, want a close frame", op, payload)
+	}
+
+	return int(binary.BigEndian.Uint16(payload))
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example of RFC 6455.
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey = %s", got)
+	}
+}
+
+func TestUpgradeRefuses(t *testing.T) {
+	handshake := func() *http.Request {
+		r := httptest.NewRequest("GET", "http://example.com/ws", nil)
+		r.Header.Set("Connection", "keep-alive, Upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+		return r
+	}
+
+	cases := []struct {
+		name   string
+		change func(r *http.Request)
+		u      *Upgrader
+		status int
+	}{
+		{"POST", func(r *http.Request) { r.Method = "POST" }, nil, http.StatusBadRequest},
+		{"no upgrade", func(r *http.Request) { r.Header.Del("Upgrade") }, nil, http.StatusBadRequest},
+		{"version", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Version", "8") }, nil, http.StatusUpgradeRequired},
+		{"key", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Key", "c2hvcnQ=") }, nil, http.StatusBadRequest},
+		{"origin", func(r *http.Request) { r.Header.Set("Origin", "https://evil.example") }, nil, http.StatusForbidden},
+		{"authorize", func(r *http.Request) {}, &Upgrader{Authorize: func(r *http.Request, s *session.Session) bool { return s != nil }}, http.StatusUnauthorized},
+		{"hijack", func(r *http.Request) { r.Header.Set("Origin", "http://EXAMPLE.com") }, nil, http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		r := handshake()
+		c.change(r)
+		u := c.u
+		if u == nil {
+			u = &Upgrader{}
+		}
+
+		w := httptest.NewRecorder()
+		if conn, err := u.Upgrade(w, r); conn != nil || err == nil || w.Code != c.status {
+			t.Errorf("%s: answered %d, %v", c.name, w.Code, err)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := handshake()
+	r.Header.Set("Sec-WebSocket-Version", "8")
+	Upgrade(w, r)
+	if w.Header().Get("Sec-WebSocket-Version") != "13" {
+		t.Errorf("426 without the supported version: %v", w.Header())
+	}
+}
+
+// Serve an upgrader echoing messages, and report how each connection
+// ended on the returned channel.
+func echo(t *testing.T, u *Upgrader) (*httptest.Server, <-chan error) {
+	ended := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "sid=1")
+		conn, err := u.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				ended <- err
+				return
+			}
+
+			conn.WriteMessage(typ, data)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, ended
+}
+
+func TestEcho(t *testing.T) {
+	server, ended := echo(t, &Upgrader{Subprotocols: []string{"chat", "v2"}})
+	c := dial(t, server, map[string]string{"Sec-WebSocket-Protocol": "v2, chat"})
+	h := c.resp.Header
+	if c.resp.StatusCode != http.StatusSwitchingProtocols || h.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" ||
+		h.Get("Sec-WebSocket-Protocol") != "chat" || h.Get("Set-Cookie") != "sid=1" {
+		t.Fatalf("handshake %d %v", c.resp.StatusCode, h)
+	}
+
+	c.write(false, TextMessage, []byte("hel"))
+	c.write(true, PingMessage, []byte("p"))
+	c.write(true, continuationFrame, []byte("lo"))
+	if op, payload := c.read(); op != PongMessage || string(payload) != "p" {
+		t.Errorf("read %d %q, want the pong", op, payload)
+	}
+
+	if op, payload := c.read(); op != TextMessage || string(payload) != "hello" {
+		t.Errorf("read %d %q, want the echo", op, payload)
+	}
+
+	big := []byte(strings.Repeat("x", 70000))
+	c.write(true, BinaryMessage, big)
+	if op, payload := c.read(); op != BinaryMessage || len(payload) != len(big) {
+		t.Errorf("read %d of %d bytes", op, len(payload))
+	}
+
+	c.write(true, CloseMessage, append([]byte{0x03, 0xe8}, "bye"...))
+	if code := c.readClose(); code != CloseNormal {
+		t.Errorf("close answered with %d", code)
+	}
+
+	err := <-ended
+	if ce, ok := err.(*CloseError); !ok || ce.Code != CloseNormal || ce.Text != "bye" {
+		t.Errorf("ReadMessage error %v", err)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	cases := []struct {
+		name  string
+		write func(c *client)
+		code  int
+	}{
+		{"unmasked", func(c *client) { c.conn.Write([]byte{0x81, 0x01, 'a'}) }, CloseProtocolError},
+		{"too big", func(c *client) { c.write(true, BinaryMessage, make([]byte, 11)) }, CloseMessageTooBig},
+		{"too big in parts", func(c *client) {
+			c.write(false, BinaryMessage, make([]byte, 6))
+			c.write(true, continuationFrame, make([]byte, 6))
+		}, CloseMessageTooBig},
+		{"invalid UTF-8", func(c *client) { c.write(true, TextMessage, []byte{0xff, 0xfe}) }, CloseInvalidPayload},
+		{"continuation first", func(c *client) { c.write(true, continuationFrame, []byte("a")) }, CloseProtocolError},
+		{"unfinished", func(c *client) {
+			c.write(false, TextMessage, []byte("a"))
+			c.write(true, TextMessage, []byte("b"))
+		}, CloseProtocolError},
+		{"fragmented ping", func(c *client) { c.write(false, PingMessage, nil) }, CloseProtocolError},
+		{"opcode", func(c *client) { c.write(true, 3, nil) }, CloseProtocolError},
+	}
+
+	for _, tc := range cases {
+		server, ended := echo(t, &Upgrader{ReadLimit: 10})
+		c := dial(t, server, nil)
+		tc.write(c)
+		if code := c.readClose(); code != tc.code {
+			t.Errorf("%s: closed with %d, want %d", tc.name, code, tc.code)
+		}
+
+		err := <-ended
+		if tc.code == CloseMessageTooBig && err != ErrReadLimit {
+			t.Errorf("%s: error %v", tc.name, err)
+		}
+	}
+}
+
+func TestWriteAfterClose(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	c := &Conn{conn: server}
+	go func() {
+		b := make([]byte, 64)
+		for {
+			if _, err := client.Read(b); err != nil {
+				return
+			}
+		}
+	}()
+
+	if err := c.WriteClose(CloseGoingAway, strings.Repeat("x", 200)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.WriteMessage(TextMessage, []byte("late")); err != ErrClosed {
+		t.Errorf("write after close: %v", err)
+	}
+
+	c.Close()
+}